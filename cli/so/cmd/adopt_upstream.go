@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var adoptUpstreamCmd = &cobra.Command{
+	Use:   "adopt-upstream <pr>",
+	Short: "Check out a colleague's stack of pull requests as local tracked branches",
+	Long: `Materializes someone else's stacked pull requests locally, given the top PR
+of their stack as a number, '#<number>' or URL.
+
+Starting from that PR, socle follows the base branches downward through the
+open PRs whose head is the base of the PR above, until it reaches a base
+without a PR (usually the trunk). It then fetches every head branch from
+'origin', creates local branches tracking them and records parent, base and
+PR number, so 'so log', 'so up'/'so down' and 'so restack' work on the chain.
+
+Existing local branches are reused when they point at the same commit as the
+PR head. Branches that differ are left alone and the command fails, unless
+--force resets them to the PR head. PRs from forks cannot be adopted.
+
+  so adopt-upstream 123
+  so adopt-upstream https://github.com/acme/app/pull/123`,
+	Args: cobra.ExactArgs(1),
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+
+		runner := &adoptUpstreamCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			force:  force,
+		}
+		return runner.run(cmd.Context(), args[0])
+	}),
+}
+
+func init() {
+	AddCommand(adoptUpstreamCmd)
+	adoptUpstreamCmd.Flags().Bool("force", false, "Reset existing local branches that differ from the PR heads")
+}
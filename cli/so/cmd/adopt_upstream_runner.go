@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/google/go-github/v71/github"
+)
+
+// maxAdoptDepth stops following base links in case of a cycle or a runaway chain.
+const maxAdoptDepth = 50
+
+type adoptUpstreamCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	force bool
+}
+
+var prReferenceRegex = regexp.MustCompile(`^(?:#?(\d+)|https?://[^/]+/([^/]+)/([^/]+)/pull/(\d+)(?:[/?#].*)?)$`)
+
+func (r *adoptUpstreamCmdRunner) run(ctx context.Context, reference string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	remoteName := git.GetRemoteName()
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return fmt.Errorf("cannot get remote URL for '%s': %w", remoteName, err)
+	}
+	owner, repoName, err := git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", remoteName, remoteURL, err)
+	}
+	topNumber, err := parsePRReference(reference, owner, repoName)
+	if err != nil {
+		return err
+	}
+
+	ghClient, err := gh.CreateClient(ctx, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	chain, trunk, err := r.followChain(ghClient, topNumber, owner+"/"+repoName)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "Found a stack of %d PR(s) on '%s':\n", len(chain), trunk)
+	for i := len(chain) - 1; i >= 0; i-- {
+		pr := chain[i]
+		_, _ = fmt.Fprintf(r.stdout, "  %s %s\n", pr.GetHead().GetRef(), ui.Colors.MutedStyle.Render(fmt.Sprintf("#%d %s", pr.GetNumber(), pr.GetTitle())))
+	}
+
+	heads := make([]string, 0, len(chain)+1) // Trunk first, then bottom to top
+	heads = append(heads, trunk)
+	for i := len(chain) - 1; i >= 0; i-- {
+		heads = append(heads, chain[i].GetHead().GetRef())
+	}
+	if err := git.FetchRemoteBranches(remoteName, heads); err != nil {
+		return err
+	}
+
+	// Decide what happens to every branch before changing anything.
+	currentBranch, _ := git.GetCurrentBranch()
+	create := make(map[string]bool)
+	for _, branch := range heads {
+		exists, err := git.BranchExists(branch)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			create[branch] = true
+			continue
+		}
+		if branch == trunk {
+			continue // Leave the local trunk alone; 'so sync' updates it
+		}
+		localOID, err := git.GetCurrentBranchCommit(branch)
+		if err != nil {
+			return err
+		}
+		remoteOID, err := git.GetRemoteBranchCommit(branch, remoteName)
+		if err != nil {
+			return err
+		}
+		if localOID == remoteOID {
+			continue
+		}
+		if !r.force {
+			return fmt.Errorf("local branch '%s' differs from '%s/%s'; rerun with --force to reset it to the PR head", branch, remoteName, branch)
+		}
+		if branch == currentBranch {
+			return fmt.Errorf("cannot reset '%s' while it is checked out; switch to another branch first", branch)
+		}
+		create[branch] = true
+	}
+
+	parent := trunk
+	for _, branch := range heads {
+		if create[branch] {
+			if err := git.CreateTrackingBranch(branch, remoteName, r.force); err != nil {
+				return err
+			}
+		}
+		if branch == trunk {
+			continue
+		}
+		// Replace, not add to, what an earlier adoption or 'so track' stored
+		for name, value := range map[string]string{"socle-parent": parent, "socle-base": trunk} {
+			key := git.BranchConfigKey(branch, name)
+			if err := git.UnsetGitConfig(key); err != nil {
+				return fmt.Errorf("failed to reset %s config for '%s': %w", name, branch, err)
+			}
+			if err := git.SetGitConfig(key, value); err != nil {
+				return fmt.Errorf("failed to set %s config for '%s': %w", name, branch, err)
+			}
+		}
+		parent = branch
+	}
+	for _, pr := range chain {
+		if err := git.UnsetStoredPRNumber(pr.GetHead().GetRef()); err != nil {
+			return fmt.Errorf("failed to reset PR number for '%s': %w", pr.GetHead().GetRef(), err)
+		}
+		if err := git.SetStoredPRNumber(pr.GetHead().GetRef(), pr.GetNumber()); err != nil {
+			return fmt.Errorf("failed to store PR number for '%s': %w", pr.GetHead().GetRef(), err)
+		}
+	}
+
+	top := chain[0].GetHead().GetRef()
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Adopted %d branch(es) on top of '%s'.", len(chain), trunk)))
+	_, _ = fmt.Fprintf(r.stdout, "Run 'git checkout %s' and 'so log' to see the stack.\n", top)
+	return nil
+}
+
+// followChain walks from the top PR down through the PRs whose head is the
+// base of the PR above. It returns the chain top first and the base branch the
+// bottom PR targets.
+func (r *adoptUpstreamCmdRunner) followChain(client gh.ClientInterface, topNumber int, repoFullName string) ([]*github.PullRequest, string, error) {
+	pr, err := client.GetPullRequest(topNumber)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var chain []*github.PullRequest
+	seen := make(map[string]bool)
+	for {
+		head := pr.GetHead().GetRef()
+		if headRepo := pr.GetHead().GetRepo().GetFullName(); headRepo != "" && !strings.EqualFold(headRepo, repoFullName) {
+			return nil, "", fmt.Errorf("PR #%d comes from the fork '%s'; only branches of '%s' can be adopted", pr.GetNumber(), headRepo, repoFullName)
+		}
+		if pr.GetState() != "" && pr.GetState() != "open" {
+			return nil, "", fmt.Errorf("PR #%d ('%s') is %s; only open PRs can be adopted", pr.GetNumber(), head, pr.GetState())
+		}
+		if seen[head] || len(chain) >= maxAdoptDepth {
+			return nil, "", fmt.Errorf("stopped following PR bases at #%d: the chain is cyclic or longer than %d PRs", pr.GetNumber(), maxAdoptDepth)
+		}
+		seen[head] = true
+		chain = append(chain, pr)
+
+		base := pr.GetBase().GetRef()
+		r.logger.Debug("Following PR base", "pr", pr.GetNumber(), "head", head, "base", base)
+		below, err := client.FindPullRequestByHead(base)
+		if err != nil {
+			return nil, "", err
+		}
+		if below == nil {
+			return chain, base, nil
+		}
+		pr = below
+	}
+}
+
+// parsePRReference accepts '123', '#123' or a pull request URL of owner/repo.
+func parsePRReference(reference, owner, repo string) (int, error) {
+	m := prReferenceRegex.FindStringSubmatch(strings.TrimSpace(reference))
+	if m == nil {
+		return 0, fmt.Errorf("'%s' is not a pull request number or URL", reference)
+	}
+	number := m[1]
+	if number == "" {
+		if !strings.EqualFold(m[2], owner) || !strings.EqualFold(m[3], repo) {
+			return 0, fmt.Errorf("'%s' belongs to %s/%s, but 'origin' is %s/%s", reference, m[2], m[3], owner, repo)
+		}
+		number = m[4]
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("'%s' is not a valid pull request number", reference)
+	}
+	return n, nil
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptUpstreamCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, adoptUpstreamCmd, "force")
+
+	// A colleague pushed feat-1 (on main) and feat-2 (on feat-1) to origin;
+	// the remote's path ends in test-owner/test-repo.git so owner/repo parse.
+	setup := func(t *testing.T) string {
+		resetCommandFlags(t, adoptUpstreamCmd, "force")
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		t.Cleanup(cleanup)
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--quiet", "--bare", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "main")
+		for _, b := range []string{"feat-1", "feat-2"} {
+			testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", b)
+			writeFile(t, repoPath, b+".txt", b)
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "Add "+b)
+			testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", b)
+		}
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		testutils.RunCommand(t, repoPath, "git", "branch", "-q", "-D", "feat-1", "feat-2")
+		testutils.RunCommand(t, repoPath, "git", "update-ref", "-d", "refs/remotes/origin/feat-1")
+		testutils.RunCommand(t, repoPath, "git", "update-ref", "-d", "refs/remotes/origin/feat-2")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			assert.Equal(t, "test-owner", owner)
+			assert.Equal(t, "test-repo", repo)
+			return mockClient, nil
+		}
+		pr := func(number int, head, base string) *github.PullRequest {
+			return &github.PullRequest{
+				Number: github.Ptr(number),
+				State:  github.Ptr("open"),
+				Title:  github.Ptr("Add " + head),
+				Head:   &github.PullRequestBranch{Ref: github.Ptr(head), Repo: &github.Repository{FullName: github.Ptr("test-owner/test-repo")}},
+				Base:   &github.PullRequestBranch{Ref: github.Ptr(base)},
+			}
+		}
+		mockClient.On("GetPullRequest", 12).Return(pr(12, "feat-2", "feat-1"), nil)
+		mockClient.On("FindPullRequestByHead", "feat-1").Return(pr(11, "feat-1", "main"), nil)
+		mockClient.On("FindPullRequestByHead", "main").Return(nil, nil)
+		return repoPath
+	}
+
+	t.Run("Creates tracked branches for the whole chain", func(t *testing.T) {
+		setup(t)
+
+		stdout, _, err := runSoCommandWithOutput(t, "adopt-upstream", "https://github.com/test-owner/test-repo/pull/12")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "Found a stack of 2 PR(s) on 'main'")
+		assert.Contains(t, out, "✓ Adopted 2 branch(es) on top of 'main'.")
+
+		for branch, want := range map[string][2]string{"feat-1": {"main", "11"}, "feat-2": {"feat-1", "12"}} {
+			exists, _ := git.BranchExists(branch)
+			require.True(t, exists, branch)
+			parent, _ := git.GetGitConfig("branch." + branch + ".socle-parent")
+			base, _ := git.GetGitConfig("branch." + branch + ".socle-base")
+			prNumber, _ := git.GetGitConfig("branch." + branch + ".socle-pr-number")
+			upstream, _ := git.GetGitConfig("branch." + branch + ".merge")
+			assert.Equal(t, want[0], parent, branch)
+			assert.Equal(t, "main", base, branch)
+			assert.Equal(t, want[1], prNumber, branch)
+			assert.Equal(t, "refs/heads/"+branch, upstream, branch)
+		}
+
+		// Adopting again replaces the metadata instead of adding to it
+		_, _, err = runSoCommandWithOutput(t, "adopt-upstream", "12")
+		require.NoError(t, err)
+		for _, key := range []string{"socle-parent", "socle-base", "socle-pr-number"} {
+			values := testutils.RunCommand(t, ".", "git", "config", "--get-all", "branch.feat-2."+key)
+			assert.Len(t, strings.Fields(values), 1, key)
+		}
+
+		testutils.RunCommand(t, ".", "git", "checkout", "-q", "feat-2")
+		stdout, _, err = runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "feat-1")
+		assert.Contains(t, stdout, "feat-2")
+	})
+
+	t.Run("Refuses to overwrite a diverged local branch without --force", func(t *testing.T) {
+		repoPath := setup(t)
+		testutils.RunCommand(t, repoPath, "git", "branch", "feat-1", "main")
+
+		_, _, err := runSoCommandWithOutput(t, "adopt-upstream", "#12")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "local branch 'feat-1' differs from 'origin/feat-1'")
+		exists, _ := git.BranchExists("feat-2")
+		assert.False(t, exists, "nothing is created when a branch conflicts")
+
+		_, _, err = runSoCommandWithOutput(t, "adopt-upstream", "12", "--force")
+		require.NoError(t, err)
+		local, _ := git.GetCurrentBranchCommit("feat-1")
+		remote, _ := git.GetRemoteBranchCommit("feat-1", "origin")
+		assert.Equal(t, remote, local)
+	})
+
+	t.Run("Rejects URLs of other repositories", func(t *testing.T) {
+		setup(t)
+		_, _, err := runSoCommandWithOutput(t, "adopt-upstream", "https://github.com/other/repo/pull/12")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "belongs to other/repo")
+	})
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var amendCmd = &cobra.Command{
+	Use:     "amend",
+	Aliases: []string{"modify"},
+	Short:   "Amend the current branch's commit and restack the branches above it",
+	Long: `Stages all changes ('git add .') and folds them into the tip commit of the
+current branch, keeping its message. With -m the changes go into a new commit
+with that message instead; a branch without commits of its own needs -m.
+--patch stages interactively ('git add -p') instead of staging everything.
+
+Then every branch stacked above the current one is rebased onto the new
+commit, replaying only its own commits, as 'so restack --upstack' would.
+Nothing is fetched or pushed; run 'so submit' to update the PRs. On a
+conflict, resolve it and run 'so continue'.`,
+	Args: cobra.NoArgs,
+	RunE: withNextStepHint(guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		onConflict, err := conflictPolicy(cmd)
+		if err != nil {
+			return err
+		}
+
+		runner := &amendCmdRunner{
+			logger:         slog.Default(),
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          os.Stdin,
+			nonInteractive: nonInteractive,
+			message:        cmd.Flag("message").Value.String(),
+			patch:          cmd.Flag("patch").Changed,
+			onConflict:     onConflict,
+		}
+		return runner.run(cmd)
+	})),
+}
+
+func init() {
+	AddCommand(amendCmd)
+	amendCmd.Flags().StringP("message", "m", "", "Commit the changes as a new commit with this message instead of amending")
+	amendCmd.Flags().BoolP("patch", "p", false, "Choose the changes to stage interactively ('git add -p')")
+	amendCmd.Flags().String("stop-on-conflict", conflictHalt, "On a conflict, halt for you to resolve it or skip the branch and its descendants: halt|skip (default from socle.stopOnConflict)")
+}
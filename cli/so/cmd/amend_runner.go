@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+type amendCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+
+	nonInteractive bool
+
+	message    string // Commit as a new commit with this message instead of amending
+	patch      bool   // Stage with 'git add -p' instead of everything
+	onConflict string // conflictHalt or conflictSkip
+}
+
+func (r *amendCmdRunner) run(cmd *cobra.Command) error {
+	if git.IsRebaseInProgress() {
+		return socleerr.New(socleerr.Conflict, "a rebase is in progress. Finish it with 'so continue' or 'git rebase --continue' first")
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parent, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-parent"))
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", branch)
+		}
+		return fmt.Errorf("failed to check tracking status for branch '%s': %w", branch, err)
+	}
+
+	// Amending a branch without commits of its own would rewrite its parent's.
+	if r.message == "" {
+		own, err := git.GetCommits(parent, branch)
+		if err != nil {
+			return fmt.Errorf("failed to list the commits of '%s': %w", branch, err)
+		}
+		if len(own) == 0 {
+			return fmt.Errorf("'%s' has no commits of its own to amend. Pass -m to commit the changes as its first commit", branch)
+		}
+	}
+
+	hasChanges, err := git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if !hasChanges {
+		return fmt.Errorf("no changes to amend '%s' with", branch)
+	}
+	if r.patch {
+		if err := git.StageInteractively(); err != nil {
+			return err
+		}
+	} else if err := git.StageAllChanges(); err != nil {
+		return err
+	}
+	staged, err := git.HasStagedChanges()
+	if err != nil {
+		return err
+	}
+	if !staged {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.InfoStyle.Render("No changes were staged; nothing to amend."))
+		return nil
+	}
+
+	// The branches above still hold the old tip; each replays only the
+	// commits after its parent's current tip.
+	above, err := recordUpstackStarts(branch)
+	if err != nil {
+		return err
+	}
+
+	if r.message != "" {
+		if err := git.CommitChanges(r.message); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Committed the changes onto '%s'.", branch)))
+	} else {
+		if err := git.AmendCommit(); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Amended the tip commit of '%s'.", branch)))
+	}
+
+	if above == 0 {
+		return nil
+	}
+	remaining, err := git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if remaining {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render("Changes that were not staged remain, so the branches above were not restacked. Commit or stash them and run 'so restack'."))
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, "\nRestacking the branches above...")
+	restack := &restackCmdRunner{
+		logger:         r.logger,
+		stdout:         r.stdout,
+		stderr:         r.stderr,
+		stdin:          r.stdin,
+		nonInteractive: r.nonInteractive,
+		noFetch:        true,
+		noPush:         true,
+		scope:          scopeUpstack,
+		onConflict:     r.onConflict,
+	}
+	return restack.run(cmd)
+}
+
+// recordUpstackStarts records, for every branch stacked above branch, the
+// current tip of its parent as where its own commits start, so a restack
+// after branch is rewritten replays only those. It returns how many branches
+// are above.
+func recordUpstackStarts(branch string) (int, error) {
+	parentMap, err := git.GetAllSocleParents()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read stack relationships: %w", err)
+	}
+	childMap := git.BuildChildMap(parentMap)
+	above := 0
+	var record func(parent string) error
+	record = func(parent string) error {
+		for _, child := range childMap[parent] {
+			above++
+			if err := recordUpstream(child, parent); err != nil {
+				return err
+			}
+			if err := record(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := record(branch); err != nil {
+		return 0, err
+	}
+	return above, nil
+}
+
+// recordUpstream records the current tip of parent as where branch's own
+// commits start, unless an unfinished move already recorded one.
+func recordUpstream(branch, parent string) error {
+	if git.GetRestackUpstream(branch) != "" {
+		return nil
+	}
+	oid, err := git.GetCurrentBranchCommit(parent)
+	if err != nil {
+		return fmt.Errorf("cannot get current commit of '%s': %w", parent, err)
+	}
+	if err := git.SetRestackUpstream(branch, oid); err != nil {
+		return fmt.Errorf("failed to record where '%s' starts: %w", branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmendCommand(t *testing.T) {
+	t.Run("Amends the tip commit and restacks the branches above", func(t *testing.T) {
+		resetCommandFlags(t, amendCmd, "message")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+		oldA, err := git.GetCurrentBranchCommit("feature-a")
+		require.NoError(t, err)
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+		writeFile(t, repoPath, "feature-a.txt", "feature-a amended")
+		writeFile(t, repoPath, "extra.txt", "extra")
+
+		stdout, _, err := runSoCommandWithOutput(t, "amend")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Amended the tip commit of 'feature-a'.")
+
+		current, err := git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature-a", current)
+		commits, err := git.GetCommits("main", "feature-a")
+		require.NoError(t, err)
+		require.Len(t, commits, 1, "the changes go into the existing commit")
+		assert.Equal(t, "feat: commit on feature-a", commits[0].Subject)
+		newA, err := git.GetCurrentBranchCommit("feature-a")
+		require.NoError(t, err)
+		assert.NotEqual(t, oldA, newA)
+
+		for _, step := range [][2]string{{"feature-a", "feature-b"}, {"feature-b", "feature-c"}} {
+			onParent, err := git.IsAncestor(step[0], step[1])
+			require.NoError(t, err)
+			assert.True(t, onParent, "%s is restacked onto %s", step[1], step[0])
+			own, err := git.GetCommits(step[0], step[1])
+			require.NoError(t, err)
+			assert.Len(t, own, 1, "only the commit of %s is replayed", step[1])
+		}
+		assert.Equal(t, "feature-a amended", testutils.RunCommand(t, repoPath, "git", "show", "feature-c:feature-a.txt"))
+		assert.Empty(t, git.GetRestackUpstream("feature-b"), "the recorded start is cleared")
+	})
+
+	t.Run("With -m commits the changes as a new commit", func(t *testing.T) {
+		resetCommandFlags(t, amendCmd, "message")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+		writeFile(t, repoPath, "extra.txt", "extra")
+		require.NoError(t, runSoCommand(t, "amend", "-m", "feat: follow-up"))
+
+		commits, err := git.GetCommits("main", "feature-a")
+		require.NoError(t, err)
+		require.Len(t, commits, 2)
+		assert.Equal(t, "feat: follow-up", commits[1].Subject)
+		onParent, err := git.IsAncestor("feature-a", "feature-b")
+		require.NoError(t, err)
+		assert.True(t, onParent)
+	})
+
+	t.Run("A branch without commits of its own needs -m", func(t *testing.T) {
+		resetCommandFlags(t, amendCmd, "message")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature-b")
+		require.NoError(t, runSoCommand(t, "track", "--test-parent=feature-a"))
+		writeFile(t, repoPath, "extra.txt", "extra")
+
+		err := runSoCommand(t, "amend")
+		require.ErrorContains(t, err, "'feature-b' has no commits of its own to amend")
+	})
+}
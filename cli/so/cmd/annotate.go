@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <branch> <note>",
+	Short: "Attach a short note to a branch, shown in 'so log'",
+	Long: `Attaches a free-form note to a tracked branch, e.g. "waiting on infra" or
+"blocked by #123". 'so log' shows it dimmed after the branch's status, which
+makes it a lightweight way to track what each branch of a stack is waiting for.
+
+Words after the branch name are joined, so quoting the note is optional.
+Annotating again replaces the note; 'so annotate --clear [branch]' removes it
+(defaulting to the current branch).
+
+The note is stored in git config as branch.<name>.socle-note.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if clear, _ := cmd.Flags().GetBool("clear"); clear {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("requires a branch and a note (or --clear)")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clear, _ := cmd.Flags().GetBool("clear")
+
+		runner := &annotateCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			clear:  clear,
+		}
+		if len(args) > 0 {
+			runner.branch = args[0]
+		}
+		if len(args) > 1 {
+			runner.note = strings.Join(args[1:], " ")
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	AddCommand(annotateCmd)
+	annotateCmd.Flags().Bool("clear", false, "Remove the note from the branch")
+}
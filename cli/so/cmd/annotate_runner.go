@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type annotateCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	branch string
+	note   string
+	clear  bool
+}
+
+func (r *annotateCmdRunner) run() error {
+	branch := r.branch
+	if branch == "" {
+		current, err := git.GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+		branch = current
+	}
+
+	if _, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-parent")); err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", branch)
+		}
+		return fmt.Errorf("failed to check tracking status for branch '%s': %w", branch, err)
+	}
+
+	if r.clear {
+		if err := git.SetBranchNote(branch, ""); err != nil {
+			return fmt.Errorf("failed to clear note on '%s': %w", branch, err)
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Cleared the note on '%s'.", branch)))
+		return nil
+	}
+
+	note := strings.TrimSpace(r.note)
+	if note == "" {
+		return fmt.Errorf("note is empty; use 'so annotate --clear %s' to remove a note", branch)
+	}
+	if strings.ContainsAny(note, "\r\n") {
+		return fmt.Errorf("notes must fit on a single line")
+	}
+	if err := git.SetBranchNote(branch, note); err != nil {
+		return fmt.Errorf("failed to save note on '%s': %w", branch, err)
+	}
+	r.logger.Debug("Annotated branch", "branch", branch, "note", note)
+	_, _ = fmt.Fprintf(r.stdout, "%s %s\n", ui.Colors.SuccessStyle.Render(fmt.Sprintf("Annotated '%s':", branch)), note)
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotateCommand(t *testing.T) {
+	resetCommandFlags(t, annotateCmd, "clear")
+
+	t.Run("Shows the note in log and clears it", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		stdout, _, err := runSoCommandWithOutput(t, "annotate", "feature-a", "waiting", "on", "infra")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Annotated 'feature-a': waiting on infra")
+
+		stdout, _, err = runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "feature-a (up-to-date, no PR submitted) waiting on infra")
+		assert.Equal(t, 1, strings.Count(out, "waiting on infra"))
+
+		// Annotating again replaces the note
+		require.NoError(t, runSoCommand(t, "annotate", "feature-a", "blocked by #123"))
+		note, err := git.GetBranchNote("feature-a")
+		require.NoError(t, err)
+		assert.Equal(t, "blocked by #123", note)
+
+		// --clear defaults to the current branch
+		require.NoError(t, runSoCommand(t, "annotate", "feature-b", "next"))
+		stdout, _, err = runSoCommandWithOutput(t, "annotate", "--clear")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Cleared the note on 'feature-b'.")
+		note, err = git.GetBranchNote("feature-b")
+		require.NoError(t, err)
+		assert.Empty(t, note)
+	})
+
+	t.Run("Note is part of the JSON stack records", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		require.NoError(t, git.SetBranchNote("feature-a", "needs design review"))
+
+		runner := &logCmdRunner{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), stderr: io.Discard, all: true}
+		stackInfo, err := git.GetStackInfo()
+		require.NoError(t, err)
+		records := runner.collectStackRecords(t.Context(), stackInfo, "feature-a")
+		require.Len(t, records, 1)
+		data, err := json.Marshal(records[0][0])
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"note":"needs design review"`)
+	})
+
+	t.Run("Rejects untracked branches and missing notes", func(t *testing.T) {
+		resetCommandFlags(t, annotateCmd, "clear")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "branch", "loose")
+
+		err := runSoCommand(t, "annotate", "loose", "hello")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not tracked by socle")
+
+		err = runSoCommand(t, "annotate", "feature-a")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires a branch and a note")
+	})
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var backportCmd = &cobra.Command{
+	Use:   "backport [branch|pr] --to <base>",
+	Short: "Cherry-pick a branch or PR onto a release branch and open a backport PR",
+	Long: `Copies the commits of the current branch (or the named branch, or a PR given
+as a number, '#<number>' or URL) onto a release base and opens a backport PR.
+
+socle creates 'backport/<base>/<branch>' (or --branch) from the latest
+'<base>', tracks it with '<base>' as parent, cherry-picks the branch's own
+commits with 'git cherry-pick -x' (with --stack, every commit from the stack's
+base up to the branch), pushes it and opens a PR against '<base>'. A PR without
+a local branch is picked from its head on GitHub, so merged PRs whose branch
+was deleted can still be backported.
+
+If a commit does not apply, the cherry-pick pauses like a restack does:
+resolve the conflicts, run 'git cherry-pick --continue' and then the same
+'so backport' command again to push and open the PR.
+
+The PR title comes from --title, socle.backportTitle or
+'[{base}] {title} (#{number})', where {title} is the original PR's title (or
+the commit subject) and {number} its PR number.
+
+  so backport --to release/1.2
+  so backport 123 --to release/1.2`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, _ := cmd.Flags().GetString("to")
+		branch, _ := cmd.Flags().GetString("branch")
+		title, _ := cmd.Flags().GetString("title")
+		stack, _ := cmd.Flags().GetBool("stack")
+		draft, _ := cmd.Flags().GetBool("draft")
+		noPush, _ := cmd.Flags().GetBool("no-push")
+		noFetch, _ := cmd.Flags().GetBool("no-fetch")
+		if !noFetch {
+			var err error
+			if noFetch, err = git.GetSocleConfigBool("socle.noFetch", false); err != nil {
+				return err
+			}
+		}
+
+		runner := &backportCmdRunner{
+			logger:  slog.Default(),
+			stdout:  cmd.OutOrStdout(),
+			stderr:  cmd.ErrOrStderr(),
+			to:      to,
+			branch:  branch,
+			title:   title,
+			stack:   stack,
+			draft:   draft,
+			noPush:  noPush,
+			noFetch: noFetch,
+		}
+		reference := ""
+		if len(args) > 0 {
+			reference = args[0]
+		}
+		return runner.run(cmd.Context(), reference)
+	},
+}
+
+func init() {
+	AddCommand(backportCmd)
+	backportCmd.Flags().String("to", "", "Release branch to backport onto (required)")
+	backportCmd.Flags().String("branch", "", "Name of the backport branch (default backport/<base>/<branch>)")
+	backportCmd.Flags().String("title", "", "PR title template with {base}, {title} and {number} (default from socle.backportTitle)")
+	backportCmd.Flags().Bool("stack", false, "Backport every commit from the stack's base up to the branch, not just the branch's own")
+	backportCmd.Flags().Bool("draft", false, "Open the backport PR as a draft")
+	backportCmd.Flags().Bool("no-push", false, "Only create the backport branch locally; do not push or open a PR")
+	backportCmd.Flags().Bool("no-fetch", false, "Do not fetch the release branch first (default from socle.noFetch)")
+	_ = backportCmd.MarkFlagRequired("to")
+}
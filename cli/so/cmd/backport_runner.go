@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+// defaultBackportTitle is used when neither --title nor socle.backportTitle is
+// set. " (#{number})" is dropped when the original has no PR.
+const defaultBackportTitle = "[{base}] {title} (#{number})"
+
+type backportCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	to      string // Release base the commits are picked onto
+	branch  string // Backport branch name; derived from the source if empty
+	title   string // PR title template; socle.backportTitle if empty
+	stack   bool   // Pick everything from the stack's base, not just the branch
+	draft   bool
+	noPush  bool
+	noFetch bool
+
+	client gh.ClientInterface // Created on first use
+}
+
+// backportSource is what gets backported: the commits in from..to.
+type backportSource struct {
+	name     string // Branch (or PR head) the commits come from
+	from     string
+	to       string
+	prNumber int
+	prTitle  string // Known up front when backporting a PR that has no local branch
+}
+
+func (r *backportCmdRunner) run(ctx context.Context, reference string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if git.IsCherryPickInProgress() {
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Git cherry-pick already in progress."))
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Resolve conflicts and run 'git cherry-pick --continue' or cancel with 'git cherry-pick --abort'."))
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Once the cherry-pick is finished, run the same 'so backport' command again to push and open the PR."))
+		return socleerr.New(socleerr.Conflict, "a cherry-pick is already in progress")
+	}
+	hasChanges, err := git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if hasChanges {
+		return socleerr.New(socleerr.DirtyWorktree, "uncommitted changes detected. Please commit or stash them before backporting")
+	}
+
+	remoteName := git.GetRemoteName()
+	src, err := r.resolveSource(ctx, reference, remoteName)
+	if err != nil {
+		return err
+	}
+	commits, err := git.GetCommits(src.from, src.to)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("'%s' has no commits on top of '%s' to backport", src.name, src.from)
+	}
+
+	startOID, err := r.prepareBase(remoteName)
+	if err != nil {
+		return err
+	}
+
+	name := r.branch
+	if name == "" {
+		name = fmt.Sprintf("backport/%s/%s", r.to, src.name)
+	}
+	if err := git.IsValidBranchName(name); err != nil {
+		return fmt.Errorf("cannot use '%s' as the backport branch: %w (try --branch)", name, err)
+	}
+
+	originalBranch, _ := git.GetCurrentBranch()
+	exists, err := git.BranchExists(name)
+	if err != nil {
+		return err
+	}
+	picked := 0
+	if exists {
+		onBranch, err := git.GetCommits(startOID, name)
+		if err != nil {
+			return err
+		}
+		picked = len(onBranch)
+	} else {
+		if err := git.CreateBranch(name, startOID); err != nil {
+			return err
+		}
+		if err := git.SetGitConfig(git.BranchConfigKey(name, "socle-parent"), r.to); err != nil {
+			return fmt.Errorf("failed to set socle-parent config for '%s': %w", name, err)
+		}
+		if err := git.SetGitConfig(git.BranchConfigKey(name, "socle-base"), r.to); err != nil {
+			return fmt.Errorf("failed to set socle-base config for '%s': %w", name, err)
+		}
+	}
+
+	if picked > 0 {
+		_, _ = fmt.Fprintf(r.stdout, "'%s' already has %d backported commit(s); continuing.\n", name, picked)
+	} else {
+		_, _ = fmt.Fprintf(r.stdout, "Cherry-picking %d commit(s) from '%s' onto '%s' as '%s':\n", len(commits), src.name, r.to, name)
+		for _, c := range commits {
+			_, _ = fmt.Fprintf(r.stdout, "  %s %s\n", ui.Colors.FaintStyle.Render(c.OID[:8]), c.Subject)
+		}
+		if err := git.CheckoutBranch(name); err != nil {
+			return err
+		}
+		if err := git.CherryPickRange(src.from, src.to); err != nil {
+			if errors.Is(err, git.ErrCherryPickConflict) {
+				r.printConflictHelp(name, r.resumeCommand(reference, src))
+				return socleerr.New(socleerr.Conflict, "backport paused on a conflict in '%s'", name) // The user needs to use Git
+			}
+			return err
+		}
+		if originalBranch != "" && originalBranch != name {
+			if err := git.CheckoutBranch(originalBranch); err != nil {
+				_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: Failed to checkout original branch '%s': %v", originalBranch, err)))
+			}
+		}
+	}
+
+	if r.noPush {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Backported '%s' to '%s' on '%s'.", src.name, r.to, name)))
+		_, _ = fmt.Fprintln(r.stdout, "Skipping push and PR (--no-push).")
+		return nil
+	}
+	return r.pushAndOpenPR(ctx, name, remoteName, src, commits)
+}
+
+// resolveSource finds the commits to backport: those of the current branch or
+// the named branch (all of its stack with --stack), or the head of a PR.
+func (r *backportCmdRunner) resolveSource(ctx context.Context, reference, remoteName string) (backportSource, error) {
+	branch := reference
+	if branch == "" {
+		current, err := git.GetCurrentBranch()
+		if err != nil {
+			return backportSource{}, fmt.Errorf("failed to get current branch: %w", err)
+		}
+		branch = current
+	} else if exists, err := git.BranchExists(reference); err != nil {
+		return backportSource{}, err
+	} else if !exists {
+		return r.resolvePRSource(ctx, reference, remoteName)
+	}
+
+	key := "socle-parent"
+	if r.stack {
+		key = "socle-base"
+	}
+	from, err := git.GetGitConfig(git.BranchConfigKey(branch, key))
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return backportSource{}, socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", branch)
+		}
+		return backportSource{}, fmt.Errorf("failed to read %s of '%s': %w", key, branch, err)
+	}
+	src := backportSource{name: branch, from: from, to: branch}
+	if n, err := git.GetStoredPRNumber(branch); err == nil && n > 0 {
+		src.prNumber = n
+	}
+	return src, nil
+}
+
+// resolvePRSource backports a PR by number or URL. A local branch carrying the
+// PR is used like any branch; otherwise the PR head is fetched from GitHub,
+// which keeps it after the branch is deleted on merge.
+func (r *backportCmdRunner) resolvePRSource(ctx context.Context, reference, remoteName string) (backportSource, error) {
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return backportSource{}, fmt.Errorf("'%s' is not a local branch, and cannot get remote URL for '%s': %w", reference, remoteName, err)
+	}
+	owner, repoName, err := git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return backportSource{}, fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", remoteName, remoteURL, err)
+	}
+	number, err := parsePRReference(reference, owner, repoName)
+	if err != nil {
+		return backportSource{}, fmt.Errorf("'%s' is neither a local branch nor a pull request: %w", reference, err)
+	}
+
+	parents, err := git.GetAllSocleParents()
+	if err != nil {
+		return backportSource{}, err
+	}
+	for branch := range parents {
+		if n, errPR := git.GetStoredPRNumber(branch); errPR == nil && n == number {
+			r.logger.Debug("PR has a local branch", "pr", number, "branch", branch)
+			return r.resolveSource(ctx, branch, remoteName)
+		}
+	}
+	if r.stack {
+		return backportSource{}, fmt.Errorf("--stack needs a local branch, but PR #%d has none", number)
+	}
+
+	client, err := r.githubClient(ctx)
+	if err != nil {
+		return backportSource{}, err
+	}
+	pr, err := client.GetPullRequest(number)
+	if err != nil {
+		return backportSource{}, fmt.Errorf("failed to get PR #%d: %w", number, err)
+	}
+	if err := git.FetchRemoteBranches(remoteName, []string{pr.GetBase().GetRef()}); err != nil {
+		return backportSource{}, err
+	}
+	head, err := git.FetchPullRequestHead(remoteName, number)
+	if err != nil {
+		return backportSource{}, err
+	}
+	return backportSource{
+		name:     pr.GetHead().GetRef(),
+		from:     pr.GetBase().GetSHA(),
+		to:       head,
+		prNumber: number,
+		prTitle:  pr.GetTitle(),
+	}, nil
+}
+
+// prepareBase fetches the release base and makes sure a local branch exists
+// for it, so the backport branch can be tracked on top of it. Returns the
+// commit the backport branch starts from.
+func (r *backportCmdRunner) prepareBase(remoteName string) (string, error) {
+	return prepareReleaseBase(r.logger, r.to, remoteName, r.noFetch)
+}
+
+// prepareReleaseBase is prepareBase for any release base; 'so mirror' uses it
+// too. The remote's tip wins over a stale local branch.
+func prepareReleaseBase(logger *slog.Logger, base, remoteName string, noFetch bool) (string, error) {
+	hasRemote := false
+	if _, err := git.GetRemoteURL(remoteName); err == nil {
+		hasRemote = true
+	}
+	if hasRemote && !noFetch {
+		if err := git.FetchRemoteBranches(remoteName, []string{base}); err != nil {
+			logger.Debug("Fetching the release base failed", "base", base, "error", err)
+		}
+	}
+
+	remoteOID := ""
+	if hasRemote {
+		remoteOID, _ = git.GetRemoteBranchCommit(base, remoteName)
+	}
+	exists, err := git.BranchExists(base)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case !exists && remoteOID == "":
+		return "", fmt.Errorf("release base '%s' exists neither locally nor on '%s'", base, remoteName)
+	case !exists:
+		if err := git.CreateTrackingBranch(base, remoteName, false); err != nil {
+			return "", err
+		}
+	}
+	if remoteOID != "" {
+		return remoteOID, nil
+	}
+	return git.GetCurrentBranchCommit(base)
+}
+
+func (r *backportCmdRunner) pushAndOpenPR(ctx context.Context, name, remoteName string, src backportSource, commits []git.Commit) error {
+	pushCfg, err := git.LoadPushConfig()
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(r.stdout, "Pushing '%s' to '%s'...\n", name, remoteName)
+	if err := git.PushBranch(name, remoteName, false, pushCfg); err != nil {
+		return err
+	}
+
+	if n, err := git.GetStoredPRNumber(name); err == nil && n > 0 {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Updated backport PR #%d.", n)))
+		return nil
+	}
+
+	client, err := r.githubClient(ctx)
+	if err != nil {
+		return err
+	}
+	title, err := r.renderTitle(client, src, commits)
+	if err != nil {
+		return err
+	}
+	pr, err := client.CreatePullRequest(name, r.to, title, backportBody(src, r.to, commits), r.draft)
+	if err != nil {
+		return fmt.Errorf("failed to create backport PR for '%s': %w", name, err)
+	}
+	if err := git.SetStoredPRNumber(name, pr.GetNumber()); err != nil {
+		return fmt.Errorf("failed to store PR number for '%s': %w", name, err)
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Opened backport PR #%d: %s", pr.GetNumber(), pr.GetHTMLURL())))
+	return nil
+}
+
+// renderTitle fills the title template's {base}, {title} and {number}. The
+// original title is the PR's, else the subject of a single commit, else the
+// source branch name.
+func (r *backportCmdRunner) renderTitle(client gh.ClientInterface, src backportSource, commits []git.Commit) (string, error) {
+	tmpl := r.title
+	if tmpl == "" {
+		configured, err := git.GetSocleConfig("socle.backportTitle")
+		if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
+			return "", err
+		}
+		tmpl = defaultBackportTitle
+		if strings.TrimSpace(configured) != "" {
+			tmpl = configured
+		}
+	}
+
+	original := src.prTitle
+	if original == "" && src.prNumber > 0 {
+		if pr, err := client.GetPullRequest(src.prNumber); err == nil {
+			original = pr.GetTitle()
+		} else {
+			r.logger.Debug("Could not read the original PR title", "pr", src.prNumber, "error", err)
+		}
+	}
+	if original == "" {
+		original = src.name
+		if len(commits) == 1 {
+			original = commits[0].Subject
+		}
+	}
+
+	number := ""
+	if src.prNumber > 0 {
+		number = strconv.Itoa(src.prNumber)
+	} else {
+		tmpl = strings.ReplaceAll(tmpl, " (#{number})", "")
+	}
+	return strings.NewReplacer("{base}", r.to, "{title}", original, "{number}", number).Replace(tmpl), nil
+}
+
+func backportBody(src backportSource, base string, commits []git.Commit) string {
+	var b strings.Builder
+	if src.prNumber > 0 {
+		fmt.Fprintf(&b, "Backport of #%d to `%s`.\n", src.prNumber, base)
+	} else {
+		fmt.Fprintf(&b, "Backport of `%s` to `%s`.\n", src.name, base)
+	}
+	b.WriteString("\nCherry-picked commits:\n")
+	for _, c := range commits {
+		fmt.Fprintf(&b, "- %s %s\n", c.OID[:8], c.Subject)
+	}
+	return b.String()
+}
+
+func (r *backportCmdRunner) githubClient(ctx context.Context) (gh.ClientInterface, error) {
+	if r.client == nil {
+		client, err := newOriginGitHubClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.client = client
+	}
+	return r.client, nil
+}
+
+// resumeCommand is the command that continues this backport once the user has
+// finished the cherry-pick; the backport branch is checked out by then, so the
+// source must be named explicitly.
+func (r *backportCmdRunner) resumeCommand(reference string, src backportSource) string {
+	if reference == "" {
+		reference = src.name
+	}
+	parts := []string{"so backport", reference, "--to", r.to}
+	if r.branch != "" {
+		parts = append(parts, "--branch", r.branch)
+	}
+	if r.stack {
+		parts = append(parts, "--stack")
+	}
+	if r.noPush {
+		parts = append(parts, "--no-push")
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r *backportCmdRunner) printConflictHelp(branch, resume string) {
+	_, _ = fmt.Fprintln(r.stderr, "")
+	_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render("⚠️ Backport paused due to conflicts."))
+	_, _ = fmt.Fprintf(r.stderr, "Please resolve the conflicts in branch '%s' and then run:\n", branch)
+	_, _ = fmt.Fprintln(r.stderr, "  1. Run 'git add <resolved-files...>'.")
+	_, _ = fmt.Fprintln(r.stderr, "  2. Run 'git cherry-pick --continue' ('git cherry-pick --skip' drops a commit the base already has).")
+	_, _ = fmt.Fprintln(r.stderr, "   (To cancel, run 'git cherry-pick --abort')")
+	_, _ = fmt.Fprintf(r.stderr, "   Once the cherry-pick is complete, run '%s' again.\n", resume)
+}
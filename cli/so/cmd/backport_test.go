@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackportCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, backportCmd, "to", "branch", "title", "stack", "draft", "no-push", "no-fetch")
+
+	// main -> feature-a -> feature-b, and release/1.2 cut from main before
+	// either was written; origin's path ends in test-owner/test-repo.git.
+	setup := func(t *testing.T) (string, string, *gh.MockClient) {
+		resetCommandFlags(t, backportCmd, "to", "branch", "title", "stack", "draft", "no-push", "no-fetch")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		t.Cleanup(cleanup)
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--quiet", "--bare", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "main", "main:refs/heads/release/1.2")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		return repoPath, remotePath, mockClient
+	}
+	fileExists := func(ref, path string) bool {
+		_, err := git.RunGitCommand("cat-file", "-e", ref+":"+path)
+		return err == nil
+	}
+
+	t.Run("Cherry-picks the branch and opens a PR against the release base", func(t *testing.T) {
+		_, remotePath, mockClient := setup(t)
+		require.NoError(t, git.SetStoredPRNumber("feature-b", 7))
+		mockClient.On("GetPullRequest", 7).Return(&github.PullRequest{Number: github.Ptr(7), Title: github.Ptr("Add feature b")}, nil)
+		mockClient.On("CreatePullRequest", "backport/release/1.2/feature-b", "release/1.2", "[release/1.2] Add feature b (#7)", mock.MatchedBy(func(body string) bool {
+			return strings.HasPrefix(body, "Backport of #7 to `release/1.2`.") && strings.Contains(body, "feat: commit on feature-b")
+		}), false).Return(&github.PullRequest{Number: github.Ptr(42), HTMLURL: github.Ptr("https://github.com/test-owner/test-repo/pull/42")}, nil)
+
+		stdout, _, err := runSoCommandWithOutput(t, "backport", "--to", "release/1.2")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "Cherry-picking 1 commit(s) from 'feature-b' onto 'release/1.2' as 'backport/release/1.2/feature-b'")
+		assert.Contains(t, out, "✓ Opened backport PR #42")
+		mockClient.AssertExpectations(t)
+
+		backport := "backport/release/1.2/feature-b"
+		assert.True(t, fileExists(backport, "feature-b.txt"))
+		assert.False(t, fileExists(backport, "feature-a.txt"), "only the branch's own commits are picked")
+		message, _ := git.RunGitCommand("log", "-1", "--format=%B", backport)
+		assert.Contains(t, message, "cherry picked from commit")
+
+		parent, _ := git.GetGitConfig(git.BranchConfigKey(backport, "socle-parent"))
+		base, _ := git.GetGitConfig(git.BranchConfigKey(backport, "socle-base"))
+		assert.Equal(t, "release/1.2", parent)
+		assert.Equal(t, "release/1.2", base)
+		prNumber, _ := git.GetStoredPRNumber(backport)
+		assert.Equal(t, 42, prNumber)
+		exists, _ := git.BranchExists("release/1.2")
+		assert.True(t, exists, "a local release branch is created to track the backport on")
+		assert.NotEmpty(t, testutils.RunCommand(t, remotePath, "git", "rev-parse", "--verify", "refs/heads/"+backport))
+
+		current, _ := git.GetCurrentBranch()
+		assert.Equal(t, "feature-b", current)
+	})
+
+	t.Run("--stack picks every commit above the base", func(t *testing.T) {
+		setup(t)
+
+		stdout, _, err := runSoCommandWithOutput(t, "backport", "--to", "release/1.2", "--stack", "--no-push", "--branch", "rel-1.2-stack")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Cherry-picking 2 commit(s)")
+		assert.True(t, fileExists("rel-1.2-stack", "feature-a.txt"))
+		assert.True(t, fileExists("rel-1.2-stack", "feature-b.txt"))
+	})
+
+	t.Run("Pauses on conflicts and continues when run again", func(t *testing.T) {
+		repoPath, _, _ := setup(t)
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", "release/1.2", "origin/release/1.2")
+		writeFile(t, repoPath, "feature-a.txt", "already different on the release branch")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "Release-only change")
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "release/1.2")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+
+		_, stderr, err := runSoCommandWithOutput(t, "backport", "--to", "release/1.2", "--no-push")
+		require.Error(t, err)
+		assert.Equal(t, socleerr.Conflict, socleerr.KindOf(err))
+		errOut := stripAnsi(stderr)
+		assert.Contains(t, errOut, "Backport paused due to conflicts.")
+		assert.Contains(t, errOut, "run 'so backport feature-a --to release/1.2 --no-push' again")
+		assert.True(t, git.IsCherryPickInProgress())
+
+		_, stderr, err = runSoCommandWithOutput(t, "backport", "feature-a", "--to", "release/1.2", "--no-push")
+		assert.Equal(t, socleerr.Conflict, socleerr.KindOf(err))
+		assert.Contains(t, stripAnsi(stderr), "Git cherry-pick already in progress.")
+
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, "feature-a.txt"), []byte("resolved"), 0o644))
+		testutils.RunCommand(t, repoPath, "git", "add", "feature-a.txt")
+		testutils.RunCommand(t, repoPath, "git", "-c", "core.editor=true", "cherry-pick", "--continue")
+
+		stdout, _, err := runSoCommandWithOutput(t, "backport", "feature-a", "--to", "release/1.2", "--no-push")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "'backport/release/1.2/feature-a' already has 1 backported commit(s); continuing.")
+		assert.Contains(t, out, "✓ Backported 'feature-a' to 'release/1.2'")
+	})
+
+	t.Run("Backports a merged PR whose branch is gone", func(t *testing.T) {
+		repoPath, _, mockClient := setup(t)
+		mainOID := testutils.RunCommand(t, repoPath, "git", "rev-parse", "main")
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "feature-a:refs/pull/9/head")
+		mockClient.On("GetPullRequest", 9).Return(&github.PullRequest{
+			Number: github.Ptr(9),
+			Title:  github.Ptr("Fix the thing"),
+			Head:   &github.PullRequestBranch{Ref: github.Ptr("fix-thing")},
+			Base:   &github.PullRequestBranch{Ref: github.Ptr("main"), SHA: github.Ptr(strings.TrimSpace(mainOID))},
+		}, nil)
+		mockClient.On("CreatePullRequest", "backport/release/1.2/fix-thing", "release/1.2", "Backport #9: Fix the thing", mock.Anything, true).
+			Return(&github.PullRequest{Number: github.Ptr(43)}, nil)
+
+		stdout, _, err := runSoCommandWithOutput(t, "backport", "#9", "--to", "release/1.2", "--draft", "--title", "Backport #{number}: {title}")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "✓ Opened backport PR #43")
+		assert.True(t, fileExists("backport/release/1.2/fix-thing", "feature-a.txt"))
+		mockClient.AssertExpectations(t)
+	})
+}
@@ -22,7 +22,7 @@ type bottomCmdRunner struct {
 }
 
 func (r *bottomCmdRunner) run() error {
-	stackInfo, err := git.GetStackInfo()
+	stackInfo, err := git.GetNavigationStackInfo()
 	if err != nil {
 		_, _ = fmt.Fprintf(r.stdout, "Error getting stack info: %s\n", err)
 		return nil
@@ -94,7 +94,7 @@ func (r *bottomCmdRunner) promptSelectStack(baseBranch string, purpose cmdutils.
 	}
 	var selectedOption string
 	prompt := &survey.Select{Message: fmt.Sprintf("Multiple stacks available from '%s'. Select a stack:", baseBranch), Options: options}
-	err = survey.AskOne(prompt, &selectedOption, survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File)))
+	err = ui.AskOne(prompt, &selectedOption, survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File)))
 	if err != nil {
 		return "", true, ui.HandleSurveyInterrupt(err, "Navigation cancelled.")
 	}
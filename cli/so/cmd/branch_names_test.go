@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Branch names become git config subsections (branch.<name>.socle-*), so
+// dots, slashes, unicode and length must survive being written and parsed.
+func TestUnusualBranchNames(t *testing.T) {
+	longName := "feat/" + strings.Repeat("very-long-segment.", 12) + "end"
+	names := []string{
+		"feat/login",
+		"feat/login.v2",
+		"Team.UI/ünïcødé-日本",
+		"socle-parent.socle-base",
+		`fix/say-"hi"`,
+		longName,
+	}
+	stack := append([]string{"main"}, names...)
+
+	t.Run("Track, log and navigation keep every parent", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, stack)
+		defer cleanup()
+
+		parents, err := git.GetAllSocleParents()
+		require.NoError(t, err)
+		for i, name := range names {
+			assert.Equal(t, stack[i], parents[name], "parent of %q", name)
+		}
+
+		stdout, _, err := runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		for _, name := range names {
+			assert.Contains(t, out, name+" (up-to-date")
+		}
+
+		require.NoError(t, runSoCommand(t, "bottom"))
+		current, err := git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feat/login", current)
+
+		violations, err := git.CheckStackInvariants()
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("Metadata values with spaces and newlines are read intact", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feat/login.v2"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feat/login.v2.socle-milestone", "Q3 release\nsecond line")
+
+		meta, err := git.ReadSocleMetadata()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"main"}, meta["branch.feat/login.v2.socle-parent"])
+		assert.Equal(t, []string{"Q3 release\nsecond line"}, meta["branch.feat/login.v2.socle-milestone"])
+	})
+
+	t.Run("Create stacks a slashed branch on a unicode one", func(t *testing.T) {
+		resetCommandFlags(t, createCmd, "message", "test-branch-name", "test-stage-choice")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "Team.UI/ünïcødé-日本"})
+		defer cleanup()
+
+		writeFile(t, repoPath, "next.txt", "next")
+		err := runSoCommand(t, "create", "--test-branch-name=fix/ünïcødé.follow-up", "--test-stage-choice=add-all", "-m", "Follow up")
+		require.NoError(t, err)
+
+		parent, err := git.GetGitConfig("branch.fix/ünïcødé.follow-up.socle-parent")
+		require.NoError(t, err)
+		assert.Equal(t, "Team.UI/ünïcødé-日本", parent)
+		base, err := git.GetGitConfig("branch.fix/ünïcødé.follow-up.socle-base")
+		require.NoError(t, err)
+		assert.Equal(t, "main", base)
+	})
+
+	t.Run("Submit stores PR numbers under the right branch", func(t *testing.T) {
+		originalCreateGHClient := gh.CreateClient
+		t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feat/login", "feat/login.v2"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("CreatePullRequest", "feat/login", "main", "T", "B", false).Return(&github.PullRequest{Number: github.Ptr(11)}, nil).Once()
+		mockClient.On("CreatePullRequest", "feat/login.v2", "feat/login", "T", "B", false).Return(&github.PullRequest{Number: github.Ptr(12)}, nil).Once()
+		mockClient.On("GetMergeReadiness", mock.AnythingOfType("int")).Return(&gh.MergeReadiness{State: "OPEN"}, nil).Maybe()
+		mockClient.On("FindCommentWithMarker", mock.AnythingOfType("int"), stackCommentMarker).Return(int64(0), nil).Twice()
+		mockClient.On("CreateComment", 11, mock.Anything).Return(&github.IssueComment{ID: github.Ptr(int64(21))}, nil).Once()
+		mockClient.On("CreateComment", 12, mock.Anything).Return(&github.IssueComment{ID: github.Ptr(int64(22))}, nil).Once()
+
+		err := runSoCommand(t, "submit", "--no-push", "--no-draft", "--test-title=T", "--test-body=B")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+
+		for branch, want := range map[string]int{"feat/login": 11, "feat/login.v2": 12} {
+			got, err := git.GetStoredPRNumber(branch)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, "PR number of %q", branch)
+		}
+	})
+}
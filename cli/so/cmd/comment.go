@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var commentCmd = &cobra.Command{
+	Use:   "comment",
+	Short: "Manage the stack overview comments on the stack's pull requests",
+	Long: `Groups commands that operate on the stack overview comment socle keeps on
+every pull request of the current stack.`,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	AddCommand(commentCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var commentRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Update the stack overview comment on every PR with current review and CI state",
+	Long: `Rewrites the stack overview comment on each pull request of the current stack
+without pushing or touching the pull requests themselves, so reviewers landing
+on any PR see the health of the whole chain:
+
+  ✅  approved
+  🔄  changes requested
+  ❌  checks failing
+
+'so submit' refreshes the comments as well; use this after reviews or CI runs
+finish. Branches without a submitted PR are listed as coming soon.
+
+On deep stacks each comment lists only the open PRs within
+socle.commentNeighbors (default 5) of its own, counts the rest, collapses
+merged PRs into one line and keeps the full list in a fold-out. Set it to 0
+to always list every branch.
+
+socle.commentVerbosity picks how much each line says: 'compact' (default)
+shows PR numbers only, 'labeled' adds the branch names and 'verbose' also
+explains the order and the 👈 marker in a legend.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &commentRefreshCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	commentCmd.AddCommand(commentRefreshCmd)
+}
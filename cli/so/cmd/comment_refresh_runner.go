@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type commentRefreshCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (r *commentRefreshCmdRunner) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	if len(stackInfo.FullStack) <= 1 {
+		return fmt.Errorf("no stack to refresh: check out a tracked branch of the stack first")
+	}
+	stack := stackInfo.FullStack
+
+	prInfoMap := make(map[string]submittedPrInfo)
+	for _, branch := range stack[1:] {
+		prNumber, err := git.GetStoredPRNumber(branch)
+		if err != nil {
+			return fmt.Errorf("failed to read PR number for '%s': %w", branch, err)
+		}
+		if prNumber > 0 {
+			prInfoMap[branch] = submittedPrInfo{Number: prNumber}
+		}
+	}
+	if len(prInfoMap) == 0 {
+		_, _ = fmt.Fprintln(r.stdout, "No pull requests found in the current stack. Run 'so submit' first.")
+		return nil
+	}
+
+	remoteName := git.GetRemoteName()
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return fmt.Errorf("cannot get remote URL for '%s': %w", remoteName, err)
+	}
+	owner, repoName, err := git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", remoteName, remoteURL, err)
+	}
+	ghClient, err := gh.CreateClient(ctx, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	fillStackHealth(ghClient, prInfoMap, r.logger)
+	fillDescriptionSummaries(prInfoMap, r.logger)
+	fillMirrorLinks(stack, prInfoMap, r.logger)
+	layout := readStackCommentLayout(r.logger)
+	errs := ensureStackComments(ctx, ghClient, stack, prInfoMap, layout, nil)
+	shape := stackShape(stack, prInfoMap, layout)
+
+	var failed []string
+	for i, branch := range stack {
+		prInfo, ok := prInfoMap[branch]
+		if i == 0 || !ok {
+			continue
+		}
+		if err := errs[i]; err != nil {
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("%s (#%d): %v", branch, prInfo.Number, err)))
+			failed = append(failed, branch)
+			continue
+		}
+		recordCommentShape(branch, shape, r.logger)
+		health := prInfo.Health
+		if health == "" {
+			health = ui.Colors.MutedStyle.Render("no review or CI signal")
+		}
+		_, _ = fmt.Fprintf(r.stdout, "%s (#%d): %s\n", branch, prInfo.Number, health)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to refresh the stack comment for %d branch(es): %s", len(failed), strings.Join(failed, ", "))
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("✓ Stack comments refreshed."))
+	return nil
+}
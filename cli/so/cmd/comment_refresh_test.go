@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentRefreshCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	t.Run("Rewrites every stack comment with review and CI state", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-comment-id", "5001")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "OPEN", ReviewDecision: "APPROVED"}, nil).Once()
+		mockClient.On("GetMergeReadiness", 102).Return(&gh.MergeReadiness{Number: 102, State: "OPEN", ReviewDecision: "CHANGES_REQUESTED", ChecksState: "FAILURE"}, nil).Once()
+
+		expectedBody101 := "**Stack Overview:**\n\n* `feature-c` (Coming soon 🤞)\n* **#102** 🔄 ❌ \n* **#101** ✅  👈\n* `main` (base)\n\nStacked PRs created with [Socle](https://github.com/benekuehn/socle). <!-- socle-stack-overview -->\n"
+		mockClient.On("GetIssueComment", int64(5001)).Return(&github.IssueComment{ID: github.Ptr(int64(5001)), Body: github.Ptr("old " + stackCommentMarker)}, nil).Once()
+		mockClient.On("UpdateComment", int64(5001), expectedBody101).Return(&github.IssueComment{ID: github.Ptr(int64(5001))}, nil).Once()
+
+		mockClient.On("FindCommentWithMarker", 102, stackCommentMarker).Return(int64(0), nil).Once()
+		mockClient.On("CreateComment", 102, mock.MatchedBy(func(body string) bool {
+			return assert.Contains(t, body, "* **#102** 🔄 ❌  👈\n")
+		})).Return(&github.IssueComment{ID: github.Ptr(int64(5002))}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "comment", "refresh")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "feature-a (#101): ✅")
+		assert.Contains(t, out, "feature-b (#102): 🔄 ❌")
+		assert.Contains(t, out, "Stack comments refreshed.")
+		commentID, _ := git.GetGitConfig("branch.feature-b.socle-comment-id")
+		assert.Equal(t, "5002", commentID)
+	})
+
+	t.Run("Missing review state leaves the PR without an emoji", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("GetMergeReadiness", 101).Return(nil, errors.New("graphql unavailable")).Once()
+		mockClient.On("FindCommentWithMarker", 101, stackCommentMarker).Return(int64(0), nil).Once()
+		mockClient.On("CreateComment", 101, mock.MatchedBy(func(body string) bool {
+			return assert.Contains(t, body, "* **#101**  👈\n")
+		})).Return(&github.IssueComment{ID: github.Ptr(int64(7))}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "comment", "refresh")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+		assert.Contains(t, stripAnsi(stdout), "feature-a (#101): no review or CI signal")
+	})
+
+	t.Run("Unchanged comments are neither searched for nor edited", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		for i, branch := range []string{"feature-a", "feature-b", "feature-c"} {
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+branch+".socle-pr-number", fmt.Sprint(101+i))
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+branch+".socle-comment-id", fmt.Sprint(5001+i))
+		}
+		prInfoMap := map[string]submittedPrInfo{"feature-a": {Number: 101}, "feature-b": {Number: 102}, "feature-c": {Number: 103}}
+		stack := []string{"main", "feature-a", "feature-b", "feature-c"}
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("GetMergeReadiness", mock.AnythingOfType("int")).Return(nil, errors.New("graphql unavailable"))
+		for i, branch := range stack[1:] {
+			body := renderStackCommentBody(stack, branch, stackCommentMarker, prInfoMap, stackCommentLayout{neighbors: defaultCommentNeighbors, verbosity: commentCompact})
+			if branch == "feature-b" {
+				body = "stale " + stackCommentMarker
+				mockClient.On("UpdateComment", int64(5002), mock.AnythingOfType("string")).Return(&github.IssueComment{ID: github.Ptr(int64(5002))}, nil).Once()
+			}
+			mockClient.On("GetIssueComment", int64(5001+i)).Return(&github.IssueComment{ID: github.Ptr(int64(5001 + i)), Body: github.Ptr(body)}, nil).Once()
+		}
+
+		_, _, err := runSoCommandWithOutput(t, "comment", "refresh")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "FindCommentWithMarker", mock.Anything, mock.Anything)
+		mockClient.AssertNumberOfCalls(t, "UpdateComment", 1)
+	})
+
+	t.Run("Stack without PRs points to submit", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		stdout, _, err := runSoCommandWithOutput(t, "comment", "refresh")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "Run 'so submit' first.")
+	})
+}
+
+func TestRenderStackCommentTruncation(t *testing.T) {
+	stack := []string{"main"}
+	prInfoMap := make(map[string]submittedPrInfo)
+	for i := 1; i <= 30; i++ {
+		branch := fmt.Sprintf("b%02d", i)
+		stack = append(stack, branch)
+		prInfoMap[branch] = submittedPrInfo{Number: 100 + i, Merged: i <= 4}
+	}
+
+	body := renderStackCommentBody(stack, "b15", "<!-- marker -->", prInfoMap, stackCommentLayout{neighbors: 3, verbosity: commentCompact})
+	overview, details, found := strings.Cut(body, "<details>")
+	require.True(t, found, "the full list folds out")
+
+	assert.Contains(t, overview, "* … 12 more above\n")
+	assert.Contains(t, overview, "* **#118**")
+	assert.NotContains(t, overview, "* **#119**")
+	assert.Contains(t, overview, "* **#115**  👈\n")
+	assert.Contains(t, overview, "* **#112**")
+	assert.NotContains(t, overview, "* **#111**")
+	assert.Contains(t, overview, "* … 7 more below\n")
+	assert.Contains(t, overview, "* ✔️ 4 merged: #101, #102, #103, #104\n")
+	assert.Contains(t, overview, "* `main` (base)\n")
+
+	assert.Contains(t, details, "<summary>Full stack (30 branches)</summary>")
+	assert.Contains(t, details, "* **#130**")
+	assert.Contains(t, details, "* **#101**")
+
+	short := renderStackCommentBody(stack[:6], "b03", "<!-- marker -->", prInfoMap, stackCommentLayout{neighbors: 3, verbosity: commentCompact})
+	assert.NotContains(t, short, "<details>", "short stacks are listed in full")
+	assert.Contains(t, short, "* **#101**")
+
+	all := renderStackCommentBody(stack, "b15", "<!-- marker -->", prInfoMap, stackCommentLayout{neighbors: 0, verbosity: commentCompact})
+	assert.NotContains(t, all, "<details>", "0 neighbors never truncates")
+}
+
+func TestRenderStackCommentVerbosity(t *testing.T) {
+	stack := []string{"main", "feature-a", "feature-b"}
+	prInfoMap := map[string]submittedPrInfo{"feature-a": {Number: 101}, "feature-b": {Number: 102}}
+
+	compact := renderStackCommentBody(stack, "feature-a", "<!-- marker -->", prInfoMap, stackCommentLayout{verbosity: commentCompact})
+	assert.Contains(t, compact, "* **#102** \n* **#101**  👈\n")
+	assert.NotContains(t, compact, stackCommentLegend)
+
+	labeled := renderStackCommentBody(stack, "feature-a", "<!-- marker -->", prInfoMap, stackCommentLayout{verbosity: commentLabeled})
+	assert.Contains(t, labeled, "* **#102** `feature-b` \n* **#101** `feature-a`  👈\n")
+	assert.NotContains(t, labeled, stackCommentLegend)
+
+	verbose := renderStackCommentBody(stack, "feature-a", "<!-- marker -->", prInfoMap, stackCommentLayout{verbosity: commentVerbose})
+	assert.Contains(t, verbose, "**Stack Overview:**\n\n"+stackCommentLegend+"* **#102** `feature-b` \n")
+}
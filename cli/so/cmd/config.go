@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show the effective socle settings and where they come from",
+	Long: `Lists every socle.* setting with the value socle uses and its source: 'env'
+with the variable name, the git config scope it was read from (local, global,
+system, worktree) with the file, or 'default' when it is not set anywhere.
+
+Settings are ordinary git config keys, so change them with git config, e.g.:
+
+  git config socle.submit.draft false          (this repository)
+  git config --global socle.submit.draft false (all repositories)
+
+Most settings can also be given as SOCLE_* environment variables, which win
+over git config. Useful per shell or in CI:
+
+  SOCLE_REMOTE                      socle.remote (default origin)
+  SOCLE_BASE_BRANCHES               socle.baseBranches (main, master, develop)
+  SOCLE_NO_FETCH                    socle.noFetch (restack and sync skip fetching)
+  SOCLE_DRAFT                       socle.submit.draft
+  SOCLE_NO_PUSH                     socle.submit.noPush
+  SOCLE_ASSIGN_REVIEWERS            socle.submit.assignReviewers
+  SOCLE_STACK_NAME                  socle.submit.stackName
+  SOCLE_DELETE_BRANCH_ON_MERGE      socle.submit.deleteBranchOnMerge (new PRs delete their head branch once merged)
+  SOCLE_AUTHOR                      socle.author
+  SOCLE_PUSH_OPTIONS                socle.pushOptions
+  SOCLE_SIGNED_PUSH                 socle.signedPush
+  SOCLE_COMMIT_TRAILERS             socle.commitTrailers
+  SOCLE_SPARSE_SAFE                 socle.sparseSafe
+  SOCLE_IGNORE_BRANCHES             socle.ignoreBranches (branch globs never tracked or listed, like .socle-ignore)
+  SOCLE_REVIEWERS                   socle.reviewers
+  SOCLE_REVIEWER_STRATEGY           socle.reviewerStrategy
+  SOCLE_REBASE_MERGES               socle.rebaseMerges
+  SOCLE_STOP_ON_CONFLICT            socle.stopOnConflict (halt, or skip a conflicting branch and its descendants)
+  SOCLE_REQUIRED_SECTIONS           socle.requiredSections
+  SOCLE_BACKPORT_TITLE              socle.backportTitle
+  SOCLE_CHANGELOG                   socle.changelog (off, file, issue or both: where 'so sync' records a merged stack)
+  SOCLE_CHANGELOG_DIR               socle.changelogDir (directory of the changelog files, default changelog.d)
+  SOCLE_CHANGELOG_TEMPLATE          socle.changelogTemplate (line per PR; {title} {number} {url} {branch} {summary} {body})
+  SOCLE_PR_TITLE                    socle.prTitle (first-commit, newest-commit, branch or pattern: default title of new PRs)
+  SOCLE_PR_TITLE_PATTERN            socle.prTitlePattern ({branch} {words} {first} {newest} {count})
+  SOCLE_PR_TEMPLATE_PATH            socle.prTemplatePath (PR template used instead of the repository's; see 'so submit')
+  SOCLE_PR_STATUS_CACHE_TTL         socle.prStatusCacheTTL (seconds log and sync reuse PR statuses; 0 turns the cache off)
+  SOCLE_SECRET_SCAN                 socle.secretScan
+  SOCLE_SECRET_SCAN_COMMAND         socle.secretScanCommand
+  SOCLE_TOMBSTONE_DAYS              socle.tombstoneDays (days deleted branches can be restored)
+  SOCLE_COMMENT_NEIGHBORS           socle.commentNeighbors (open PRs listed around each PR in the stack comment)
+  SOCLE_COMMENT_VERBOSITY           socle.commentVerbosity (compact, labeled or verbose stack comments)
+  SOCLE_HINTS                       socle.hints (print a next step after restack, submit, sync and create)
+  SOCLE_AUTH                        socle.auth (token, or app to authenticate as a GitHub App)
+  SOCLE_GITHUB_APP_ID               socle.githubApp.id
+  SOCLE_GITHUB_APP_INSTALLATION_ID  socle.githubApp.installationId (looked up when unset)
+  SOCLE_GITHUB_APP_PRIVATE_KEY      socle.githubApp.privateKey (PEM file, or the PEM itself, which is not shown)
+  SOCLE_GITHUB_APP_EXCHANGE_URL     socle.githubApp.tokenExchangeUrl (OIDC exchange in CI)
+  SOCLE_CA_BUNDLE                   socle.caBundle (PEM file of extra CAs for GitHub, e.g. of a proxy or GHE server)
+  SOCLE_INSECURE_SKIP_VERIFY        socle.insecureSkipVerify (skip TLS certificate checks; last resort)
+  SOCLE_CLOSE_TRACKING_ISSUE        socle.closeTrackingIssue (close the stack's tracking issue when 'so ship' or 'so merge' merges its last PR)
+  SOCLE_MESSAGE_GENERATOR           socle.messageGenerator (command drafting commit messages and PR text)
+  SOCLE_DEFAULT_COMMIT_MESSAGE      socle.defaultCommitMessage ('so create' pre-fills a message summarizing the changed files)
+  SOCLE_LOG_AUTOFETCH_INTERVAL      socle.log.autofetchInterval (minutes; 'so log' fetches when the last fetch is older)
+
+Branch metadata (parents, PR numbers, ...) lives in the repository's config
+and is shared by every worktree. To let each worktree track stacks of its own,
+enable worktree config and set socle.metadataScope in that worktree:
+
+  git config extensions.worktreeConfig true
+  git config --worktree socle.metadataScope worktree
+
+socle then writes metadata to the worktree's config.worktree. It reads both
+files either way, the worktree's value winning, so existing stacks stay
+visible. socle.metadataScope has no environment variable. The last line of
+the list says which scope is in effect.
+
+Multi-valued settings take a comma-separated list. Values are resolved as:
+command-line flag > environment > repository config > user config > default.
+
+GitHub requests go through the proxy named by HTTPS_PROXY (or HTTP_PROXY),
+except for hosts in NO_PROXY.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &configCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	AddCommand(configCmd)
+}
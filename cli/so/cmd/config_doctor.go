@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check whether socle's metadata needs upgrading from an older layout",
+	Long: `Reports the version of socle's metadata in this repository (socle.metaVersion)
+and the upgrades a newer socle would make to it, e.g. keys that were renamed.
+
+Upgrades normally run by themselves the first time a newer socle runs in the
+repository. With --fix-legacy they are applied now; add --dry-run to list every
+change without making it. Upgrades are idempotent, so running them again, or
+after an interrupted run, is safe.
+
+Version history:
+  0  the current layout; no upgrades exist yet`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationNoMigrate: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fixLegacy, _ := cmd.Flags().GetBool("fix-legacy")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		runner := &configDoctorCmdRunner{
+			logger:    slog.Default(),
+			stdout:    cmd.OutOrStdout(),
+			stderr:    cmd.ErrOrStderr(),
+			fixLegacy: fixLegacy,
+			dryRun:    dryRun,
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configDoctorCmd)
+	configDoctorCmd.Flags().Bool("fix-legacy", false, "Upgrade metadata written by older socle versions")
+	configDoctorCmd.Flags().Bool("dry-run", false, "With --fix-legacy, list the changes without making them")
+}
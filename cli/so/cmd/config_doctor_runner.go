@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+type configDoctorCmdRunner struct {
+	logger    *slog.Logger
+	stdout    io.Writer
+	stderr    io.Writer
+	fixLegacy bool
+	dryRun    bool
+}
+
+func (r *configDoctorCmdRunner) run() error {
+	version, err := git.RepoMetaVersion()
+	if err != nil {
+		return err
+	}
+	pending, err := git.PlanMigrations()
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "Metadata version: %d (this socle writes %d)\n", version, git.CurrentMetaVersion())
+	if !hasMetadataChanges(pending) {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("✓ Metadata is up to date."))
+		return nil
+	}
+
+	for _, m := range pending {
+		_, _ = fmt.Fprintf(r.stdout, "\nVersion %d: %s\n", m.Version, m.Summary)
+		if len(m.Changes) == 0 {
+			_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("  nothing to change"))
+		}
+		for _, change := range m.Changes {
+			_, _ = fmt.Fprintf(r.stdout, "  - %s\n", change.Description)
+		}
+	}
+
+	if !r.fixLegacy || r.dryRun {
+		_, _ = fmt.Fprintln(r.stdout)
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("Run 'so config doctor --fix-legacy' to apply these changes."))
+		return nil
+	}
+	if err := git.ApplyMigrations(pending); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(r.stdout)
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Upgraded metadata to version %d.", git.CurrentMetaVersion())))
+	return nil
+}
+
+// migrateMetadata brings the repository's metadata up to the version this
+// socle writes before a command runs. It says so only when something moved.
+func migrateMetadata(cmd *cobra.Command) error {
+	if cmd.Annotations[annotationNoMigrate] == "true" {
+		return nil
+	}
+	pending, err := git.PlanMigrations()
+	if err != nil || !hasMetadataChanges(pending) {
+		return err
+	}
+	if err := git.ApplyMigrations(pending); err != nil {
+		return err
+	}
+	for _, m := range pending {
+		if len(m.Changes) > 0 {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Upgraded socle metadata to version %d (%s).\n", m.Version, m.Summary)
+		}
+	}
+	return nil
+}
+
+// hasMetadataChanges reports whether any pending migration would edit the
+// repository.
+func hasMetadataChanges(pending []git.PendingMigration) bool {
+	for _, m := range pending {
+		if len(m.Changes) > 0 {
+			return true
+		}
+	}
+	return false
+}
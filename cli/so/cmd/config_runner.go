@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type configCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (r *configCmdRunner) run() error {
+	settings, err := git.EffectiveSocleConfig()
+	if err != nil {
+		return err
+	}
+
+	width := 0
+	for _, s := range settings {
+		width = max(width, len(s.Key))
+	}
+	for _, s := range settings {
+		value := s.Value
+		if value == "" {
+			value = ui.Colors.MutedStyle.Render("(unset)")
+		}
+		source := s.Source
+		if s.Origin != "" {
+			source = fmt.Sprintf("%s: %s", s.Source, s.Origin)
+		}
+		_, _ = fmt.Fprintf(r.stdout, "%-*s  %s  %s\n", width, s.Key, value, ui.Colors.MutedStyle.Render("("+source+")"))
+	}
+	_, _ = fmt.Fprintf(r.stdout, "\nBranch metadata is written to %s.\n", git.DescribeMetadataScope())
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var continueCmd = &cobra.Command{
+	Use:   "continue",
+	Short: "Finish a restack that stopped on a conflict or was interrupted",
+	Long: `Picks up a restack (or the restack of 'so sync') that stopped because a rebase
+conflicted. Resolve the conflicts and 'git add' the files first; 'so continue'
+then runs 'git rebase --continue' for the branch that stopped, keeping its
+commit messages, and restacks the rest of the stack the way the interrupted
+restack would have: with the same flags, folding emptied branches and asking
+whether to push at the end. If another conflict stops it, resolve it and run
+'so continue' again.
+
+A restack interrupted with Ctrl+C stops between two branches; 'so continue'
+restacks the branches it had not got to yet.
+
+The restack remembers where it stopped in the git directory of the worktree
+(socle/restack.json). If you already ran 'git rebase --continue' yourself,
+'so continue' carries on from there. After 'git rebase --abort', start over
+with 'so restack'.`,
+	Args: cobra.NoArgs,
+	RunE: withNextStepHint(guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		runner := &continueCmdRunner{
+			logger:         slog.Default(),
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          os.Stdin, // Needed for push prompt
+			nonInteractive: nonInteractive,
+		}
+		return runner.run(cmd)
+	})),
+}
+
+func init() {
+	AddCommand(continueCmd)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/events"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/spf13/cobra"
+)
+
+type continueCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader // For push prompt
+
+	nonInteractive bool
+}
+
+func (r *continueCmdRunner) run(cmd *cobra.Command) error {
+	p, err := loadRestackProgress()
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		if git.IsRebaseInProgress() {
+			return fmt.Errorf("the rebase in progress was not started by 'so restack'; finish it with 'git rebase --continue'")
+		}
+		return fmt.Errorf("nothing to continue: no restack stopped on a conflict or was interrupted")
+	}
+
+	restack := (&restackCmdRunner{
+		logger:         r.logger,
+		stdout:         r.stdout,
+		stderr:         r.stderr,
+		stdin:          r.stdin,
+		events:         events.New(events.NewTextRenderer(r.stdout, r.stderr)),
+		nonInteractive: r.nonInteractive,
+	}).withOptions(p.Options)
+
+	step := p.Steps[p.Next]
+	if git.IsRebaseInProgress() && !p.ownsRebase() {
+		return fmt.Errorf("the rebase in progress is not the one 'so restack' paused on '%s'; finish it with 'git rebase --continue' or 'git rebase --abort' first", step.branch)
+	}
+	if p.Interrupted && !git.IsRebaseInProgress() {
+		// Ctrl+C stopped the restack between two steps; step Next is still
+		// to do, or was finished by a rebase that outlived socle.
+		r.logger.Debug("Resuming interrupted restack", "branch", step.branch, "parent", step.parent)
+		p.Interrupted = false
+		return restack.rebaseSteps(cmd, p)
+	}
+	p.Interrupted = false
+	if git.IsRebaseInProgress() {
+		r.logger.Debug("Continuing rebase", "branch", step.branch, "parent", step.parent)
+		if err := git.ContinueRebase(); err != nil {
+			if errors.Is(err, git.ErrRebaseConflict) {
+				restack.events.Emit(restack.conflictEvent(step.branch, step.parent, p.Next+1, len(p.Steps)))
+				cmd.SilenceUsage = true
+				return socleerr.New(socleerr.Conflict, "'%s' still has unresolved conflicts", step.branch) // Still the user's turn
+			}
+			return err
+		}
+	} else {
+		// The rebase was finished or aborted with git directly
+		done, err := git.IsAncestor(step.parent, step.branch)
+		if err != nil {
+			return err
+		}
+		if !done {
+			if err := clearRestackProgress(); err != nil {
+				return err
+			}
+			return fmt.Errorf("the rebase of '%s' onto '%s' was aborted; run 'so restack' to start over", step.branch, step.parent)
+		}
+	}
+
+	p.Rebased = append(p.Rebased, step.branch)
+	restack.finishMove(step.branch, git.GetRestackUpstream(step.branch))
+	if p.PausedMerged {
+		p.Merged = append(p.Merged, step)
+	}
+	restack.events.Emit(events.BranchRebased{Branch: step.branch, Parent: step.parent})
+	p.Next++
+	return restack.rebaseSteps(cmd, p)
+}
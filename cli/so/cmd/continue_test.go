@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContinueCommand(t *testing.T) {
+	resetCommandFlags(t, restackCmd, "no-fetch", "no-push")
+
+	// setupConflict leaves the restack of main/feature-a/feature-b/feature-c
+	// paused on feature-a, which changes shared.txt as main does.
+	setupConflict := func(t *testing.T) string {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		t.Cleanup(cleanup)
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		writeFile(t, repoPath, "shared.txt", "feature-a\n")
+		testutils.RunCommand(t, repoPath, "git", "add", "shared.txt")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "shared on feature-a")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		writeFile(t, repoPath, "shared.txt", "main\n")
+		testutils.RunCommand(t, repoPath, "git", "add", "shared.txt")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "shared on main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-c")
+
+		stdout, stderr, err := runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push")
+		assert.Equal(t, socleerr.Conflict, socleerr.KindOf(err))
+		require.True(t, git.IsRebaseInProgress())
+		assert.Contains(t, stripAnsi(stderr), "Run 'so continue'")
+		assert.Contains(t, stripAnsi(stderr), "shared.txt  (conflicts with changes from 'main')")
+		assert.Contains(t, stripAnsi(stdout), "next: resolve the conflicts, 'git add' them and run 'so continue'")
+		assert.True(t, restackPaused())
+		return repoPath
+	}
+	assertRestacked := func(t *testing.T) {
+		t.Helper()
+		assert.False(t, git.IsRebaseInProgress())
+		assert.False(t, restackPaused(), "the progress is forgotten once done")
+		for _, step := range [][2]string{{"main", "feature-a"}, {"feature-a", "feature-b"}, {"feature-b", "feature-c"}} {
+			onParent, err := git.IsAncestor(step[0], step[1])
+			require.NoError(t, err)
+			assert.True(t, onParent, "'%s' sits on '%s'", step[1], step[0])
+		}
+		current, err := git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature-c", current, "the original branch is checked out again")
+	}
+
+	t.Run("resolves and restacks the rest of the stack", func(t *testing.T) {
+		repoPath := setupConflict(t)
+
+		// Not resolved yet: the rebase stays paused
+		_, stderr, err := runSoCommandWithOutput(t, "continue")
+		assert.Equal(t, socleerr.Conflict, socleerr.KindOf(err))
+		assert.True(t, git.IsRebaseInProgress())
+		assert.Contains(t, stripAnsi(stderr), "Branch:  feature-a (1/3)")
+
+		writeFile(t, repoPath, "shared.txt", "resolved\n")
+		testutils.RunCommand(t, repoPath, "git", "add", "shared.txt")
+		stdout, stderr, err := runSoCommandWithOutput(t, "continue")
+		require.NoError(t, err)
+		assertRestacked(t)
+		assert.NotContains(t, stripAnsi(stderr), "Force push", "--no-push carries over")
+		assert.Contains(t, stripAnsi(stdout), "Stack Rebase Completed Successfully")
+
+		content, err := os.ReadFile("shared.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "resolved\n", string(content))
+		subject := testutils.RunCommand(t, repoPath, "git", "log", "-1", "--format=%s", "feature-a")
+		assert.Equal(t, "shared on feature-a", strings.TrimSpace(subject), "the commit message is kept")
+	})
+
+	t.Run("picks up after git rebase --continue", func(t *testing.T) {
+		repoPath := setupConflict(t)
+		writeFile(t, repoPath, "shared.txt", "resolved\n")
+		testutils.RunCommand(t, repoPath, "git", "add", "shared.txt")
+		testutils.RunCommand(t, repoPath, "git", "-c", "core.editor=true", "rebase", "--continue")
+
+		_, _, err := runSoCommandWithOutput(t, "continue")
+		require.NoError(t, err)
+		assertRestacked(t)
+	})
+
+	t.Run("aborted rebase", func(t *testing.T) {
+		repoPath := setupConflict(t)
+		testutils.RunCommand(t, repoPath, "git", "rebase", "--abort")
+
+		_, _, err := runSoCommandWithOutput(t, "continue")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "was aborted; run 'so restack' to start over")
+		assert.False(t, restackPaused())
+
+		_, _, err = runSoCommandWithOutput(t, "continue")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nothing to continue")
+	})
+	t.Run("leaves a rebase started with git alone", func(t *testing.T) {
+		repoPath := setupConflict(t)
+		testutils.RunCommand(t, repoPath, "git", "rebase", "--abort")
+		// A copy of feature-a conflicts with main as feature-a does.
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", "experiment", "feature-a")
+		_, err := git.RunGitCommand("rebase", "main")
+		require.Error(t, err)
+		require.True(t, git.IsRebaseInProgress())
+
+		_, _, err = runSoCommandWithOutput(t, "continue")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not the one 'so restack' paused on 'feature-a'")
+		assert.Contains(t, nextStepHint(slog.Default()), "'git rebase --continue', then 'so restack'")
+		assert.True(t, git.IsRebaseInProgress(), "the user's rebase is not touched")
+		testutils.RunCommand(t, repoPath, "git", "rebase", "--abort")
+	})
+
+	t.Run("resumes a restack interrupted with Ctrl+C", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		t.Cleanup(cleanup)
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		writeFile(t, repoPath, "main.txt", "main\n")
+		testutils.RunCommand(t, repoPath, "git", "add", "main.txt")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "new on main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-c")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel() // As the signal handler does on Ctrl+C
+		cmd := &cobra.Command{}
+		cmd.SetContext(ctx)
+		runner := &restackCmdRunner{
+			logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+			stdout:  io.Discard,
+			stderr:  io.Discard,
+			noFetch: true,
+			noPush:  true,
+		}
+		err := runner.run(cmd)
+		assert.Equal(t, socleerr.Interrupted, socleerr.KindOf(err))
+		assert.ErrorContains(t, err, "run 'so continue'")
+		assert.True(t, restackPaused())
+		onMain, err := git.IsAncestor("main", "feature-a")
+		require.NoError(t, err)
+		assert.False(t, onMain, "no branch is rebased after the interruption")
+		current, err := git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature-c", current)
+
+		_, _, err = runSoCommandWithOutput(t, "continue")
+		require.NoError(t, err)
+		assertRestacked(t)
+	})
+}
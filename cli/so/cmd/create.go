@@ -16,9 +16,17 @@ If a [branch-name] is not provided, you will be prompted for one.
 
 If there are uncommitted changes in the working directory:
   - They will be staged and committed onto the *new* branch.
-  - You must provide a commit message via the -m flag, or you will be prompted.`,
+  - You must provide a commit message via the -m flag, or you will be prompted.
+
+With 'socle.messageGenerator' set to a shell command, the commit message
+prompt first offers to generate the message. The command gets the diff of the
+uncommitted changes, untracked files included, on stdin, with SOCLE_MESSAGE_KIND=commit,
+SOCLE_BRANCH and SOCLE_PARENT set, and its output pre-fills the prompt.
+Otherwise the prompt is pre-filled with a summary of the changed files, such
+as "update auth handlers and tests", to accept with enter. Set
+'socle.defaultCommitMessage' to false for an empty prompt.`,
 	Args: cobra.MaximumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: withNextStepHint(guardStackInvariants(func(cmd *cobra.Command, args []string) error {
 		logger := slog.Default()
 
 		branchNameArg := ""
@@ -44,7 +52,7 @@ If there are uncommitted changes in the working directory:
 		}
 
 		return runner.run()
-	},
+	})),
 }
 
 func init() {
@@ -5,9 +5,13 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/msggen"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/testmode"
 	"github.com/benekuehn/socle/cli/so/internal/ui"
 	"github.com/mattn/go-isatty"
 )
@@ -48,14 +52,14 @@ func (r *createCmdRunner) run() error {
 	}
 
 	// 2. Check if parent branch is tracked
-	parentParentKey := fmt.Sprintf("branch.%s.socle-parent", parentBranch)
-	parentBaseKey := fmt.Sprintf("branch.%s.socle-base", parentBranch)
+	parentParentKey := git.BranchConfigKey(parentBranch, "socle-parent")
+	parentBaseKey := git.BranchConfigKey(parentBranch, "socle-base")
 	_, errParent := git.GetGitConfig(parentParentKey)
 	parentBase, errBase := git.GetGitConfig(parentBaseKey)
 
 	// Check if parent is tracked (needs both keys, essentially)
 	// Allow creating off a known base branch directly
-	knownBases := map[string]bool{"main": true, "master": true, "develop": true} // TODO: Configurable
+	knownBases := git.KnownBaseBranchSet()
 	isParentBase := knownBases[parentBranch]
 	isParentTracked := (errParent == nil && errBase == nil) || isParentBase
 
@@ -65,7 +69,7 @@ func (r *createCmdRunner) run() error {
 			// If creating off a base, implicitly determine base
 			parentBase = parentBranch
 		} else {
-			return fmt.Errorf("current branch '%s' is not tracked by socle and is not a known base branch.\nRun 'so track' on this branch first before creating a child branch", parentBranch)
+			return socleerr.New(socleerr.NotTracked, "current branch '%s' is not tracked by socle and is not a known base branch.\nRun 'so track' on this branch first before creating a child branch", parentBranch)
 		}
 	} else if isParentBase {
 		// Set parentBase explicitly if we are on a base branch
@@ -103,7 +107,7 @@ func (r *createCmdRunner) run() error {
 	} else {
 		prompt := &survey.Input{Message: "Enter name for the new branch:"}
 		surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
-		err := survey.AskOne(prompt, &newBranchName, survey.WithValidator(survey.Required), surveyOpts)
+		err := ui.AskOne(prompt, &newBranchName, survey.WithValidator(survey.Required), surveyOpts)
 		if err != nil {
 			return ui.HandleSurveyInterrupt(err, "Create cancelled.")
 		}
@@ -113,6 +117,13 @@ func (r *createCmdRunner) run() error {
 	if err := git.IsValidBranchName(newBranchName); err != nil {
 		return fmt.Errorf("invalid branch name '%s': %w", newBranchName, err)
 	}
+	ignore, err := git.LoadBranchIgnoreRules()
+	if err != nil {
+		return err
+	}
+	if pattern, ignored := ignore.Match(newBranchName); ignored {
+		return fmt.Errorf("branch name '%s' matches the ignore rule '%s' (%s or socle.ignoreBranches); socle would not track it", newBranchName, pattern, git.BranchIgnoreFile)
+	}
 	exists, err := git.BranchExists(newBranchName)
 	if err != nil {
 		return fmt.Errorf("failed to check if branch '%s' exists: %w", newBranchName, err)
@@ -135,11 +146,9 @@ func (r *createCmdRunner) run() error {
 		} else if effectiveNonInteractive {
 			return fmt.Errorf("commit message is required in non-interactive mode when uncommitted changes exist; pass -m")
 		} else {
-			prompt := &survey.Input{Message: "Enter commit message for current changes:"}
-			surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
-			err := survey.AskOne(prompt, &commitMsg, survey.WithValidator(survey.Required), surveyOpts)
+			commitMsg, err = r.promptCommitMessage(newBranchName, parentBranch)
 			if err != nil {
-				return ui.HandleSurveyInterrupt(err, "Create cancelled.")
+				return err
 			}
 		}
 	}
@@ -190,7 +199,7 @@ func (r *createCmdRunner) run() error {
 			}
 
 			surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
-			err := survey.AskOne(prompt, &stageChoice, surveyOpts)
+			err := ui.AskOne(prompt, &stageChoice, surveyOpts)
 			if err != nil {
 				return ui.HandleSurveyInterrupt(err, "Create cancelled.")
 			}
@@ -258,8 +267,8 @@ func (r *createCmdRunner) run() error {
 
 	// 4. Update metadata
 	r.logger.Debug("Updating socle tracking information...")
-	newParentKey := fmt.Sprintf("branch.%s.socle-parent", newBranchName)
-	newBaseKey := fmt.Sprintf("branch.%s.socle-base", newBranchName)
+	newParentKey := git.BranchConfigKey(newBranchName, "socle-parent")
+	newBaseKey := git.BranchConfigKey(newBranchName, "socle-base")
 
 	if err := git.SetGitConfig(newParentKey, parentBranch); err != nil {
 		return fmt.Errorf("failed to set socle-parent config for '%s': %w", newBranchName, err)
@@ -283,6 +292,61 @@ func (r *createCmdRunner) run() error {
 	return nil
 }
 
+// promptCommitMessage asks for the message of the new branch's first commit.
+// With socle.messageGenerator set it first offers to draft the message from
+// the diff of the uncommitted changes; a multi-line draft opens in the editor.
+// Otherwise the prompt is pre-filled with a summary of the changed files,
+// unless socle.defaultCommitMessage is off.
+func (r *createCmdRunner) promptCommitMessage(branch, parent string) (string, error) {
+	surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
+	generated := ""
+	if command := msggen.Command(); command != "" {
+		generate := true
+		prompt := &survey.Confirm{Message: fmt.Sprintf("Generate the commit message with '%s'?", command), Default: true}
+		if err := ui.AskOne(prompt, &generate, surveyOpts); err != nil {
+			return "", ui.HandleSurveyInterrupt(err, "Create cancelled.")
+		}
+		if generate {
+			diff, err := git.WorkingTreeDiff()
+			if err == nil {
+				generated, err = msggen.Generate(command, msggen.KindCommit, diff, branch, parent)
+			}
+			if err != nil {
+				_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: %v", err)))
+			}
+		}
+	}
+
+	if generated == "" {
+		generated = r.defaultCommitMessage()
+	}
+
+	commitMsg := ""
+	var prompt survey.Prompt = &survey.Input{Message: "Enter commit message for current changes:", Default: generated}
+	if strings.Contains(generated, "\n") {
+		prompt = &survey.Editor{Message: "Commit message:", FileName: "COMMIT_EDITMSG", Default: generated, HideDefault: true, AppendDefault: true}
+	}
+	if err := ui.AskOne(prompt, &commitMsg, survey.WithValidator(survey.Required), surveyOpts); err != nil {
+		return "", ui.HandleSurveyInterrupt(err, "Create cancelled.")
+	}
+	return strings.TrimSpace(commitMsg), nil
+}
+
+// defaultCommitMessage summarizes the uncommitted changes as a commit
+// subject, or returns "" if socle.defaultCommitMessage is off.
+func (r *createCmdRunner) defaultCommitMessage() string {
+	enabled, err := git.GetSocleConfigBool("socle.defaultCommitMessage", true)
+	if err != nil || !enabled {
+		return ""
+	}
+	changes, err := git.UncommittedChanges()
+	if err != nil {
+		r.logger.Debug("Could not list the changes for a default commit message", "error", err)
+		return ""
+	}
+	return msggen.Summarize(changes)
+}
+
 func hasInteractiveSurveyTerminal(stdin io.Reader, stderr io.Writer) bool {
 	stdinFile, ok := stdin.(*os.File)
 	if !ok {
@@ -293,5 +357,6 @@ func hasInteractiveSurveyTerminal(stdin io.Reader, stderr io.Writer) bool {
 		return false
 	}
 
-	return isatty.IsTerminal(stdinFile.Fd()) && isatty.IsTerminal(stderrFile.Fd())
+	// Test mode answers prompts from a script, so it needs no terminal.
+	return testmode.Enabled() || isatty.IsTerminal(stdinFile.Fd()) && isatty.IsTerminal(stderrFile.Fd())
 }
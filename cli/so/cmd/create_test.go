@@ -4,8 +4,10 @@ package cmd
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/benekuehn/socle/cli/so/internal/gh"
 	"github.com/benekuehn/socle/cli/so/internal/git"
 	"github.com/benekuehn/socle/cli/so/internal/testutils"
 	"github.com/stretchr/testify/assert"  // Using testify for assertions
@@ -89,6 +91,83 @@ func TestCreateCommand(t *testing.T) {
 		assert.Equal(t, "content for b", content)
 	})
 
+	t.Run("Commit message prompt defaults to a summary of the changes", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/a")
+		require.NoError(t, runSoCommand(t, "track", "--test-parent=main"))
+		require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "internal", "auth"), 0o755))
+		writeFile(t, repoPath, "internal/auth/handlers.go", "package auth\n")
+		writeFile(t, repoPath, "internal/auth/handlers_test.go", "package auth\n")
+
+		resetCommandFlags(t, createCmd, "message")
+		// Test mode answers the prompt, and swaps in its fake GitHub.
+		originalCreateGHClient, originalStatusCachePath := gh.CreateClient, gh.StatusCachePath
+		t.Cleanup(func() { gh.CreateClient, gh.StatusCachePath = originalCreateGHClient, originalStatusCachePath })
+		keepGitEnv(t)
+		answers := filepath.Join(t.TempDir(), "answers")
+		// An empty line accepts the default message.
+		require.NoError(t, os.WriteFile(answers, []byte("\n"), 0o644))
+		t.Setenv("SOCLE_TEST_MODE", "1")
+		t.Setenv("SOCLE_TEST_ANSWERS", answers)
+
+		require.NoError(t, runSoCommand(t, "create", "feature/b", "--test-stage-choice=add-all"))
+
+		commitMsg, err := git.GetFirstCommitSubject("feature/a", "feature/b")
+		require.NoError(t, err)
+		assert.Equal(t, "add auth handlers and tests", commitMsg)
+	})
+
+	t.Run("Generated commit message sees untracked files", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/a")
+		require.NoError(t, runSoCommand(t, "track", "--test-parent=main"))
+		writeFile(t, repoPath, "README.md", "changed readme")
+		writeFile(t, repoPath, "newfile.txt", "brand new content\n")
+
+		resetCommandFlags(t, createCmd, "message")
+		originalCreateGHClient, originalStatusCachePath := gh.CreateClient, gh.StatusCachePath
+		t.Cleanup(func() { gh.CreateClient, gh.StatusCachePath = originalCreateGHClient, originalStatusCachePath })
+		keepGitEnv(t)
+		diffPath := filepath.Join(t.TempDir(), "diff")
+		testutils.RunCommand(t, repoPath, "git", "config", "socle.messageGenerator", "cat > '"+diffPath+"'; echo 'feat: generated'")
+		answers := filepath.Join(t.TempDir(), "answers")
+		// Accept generating, then accept the generated message.
+		require.NoError(t, os.WriteFile(answers, []byte("\n\n"), 0o644))
+		t.Setenv("SOCLE_TEST_MODE", "1")
+		t.Setenv("SOCLE_TEST_ANSWERS", answers)
+
+		require.NoError(t, runSoCommand(t, "create", "feature/b", "--test-stage-choice=add-all"))
+
+		commitMsg, err := git.GetFirstCommitSubject("feature/a", "feature/b")
+		require.NoError(t, err)
+		assert.Equal(t, "feat: generated", commitMsg)
+		diff := readFile(t, filepath.Dir(diffPath), "diff")
+		assert.Contains(t, diff, "+changed readme")
+		assert.Contains(t, diff, "+++ b/newfile.txt")
+		assert.Contains(t, diff, "+brand new content")
+	})
+
+	t.Run("Working tree diff before the first commit", func(t *testing.T) {
+		_, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+
+		dir := t.TempDir()
+		testutils.RunCommand(t, dir, "git", "init")
+		writeFile(t, dir, "staged.txt", "staged\n")
+		testutils.RunCommand(t, dir, "git", "add", "staged.txt")
+		writeFile(t, dir, "untracked.txt", "untracked\n")
+		require.NoError(t, os.Chdir(dir)) // cleanup returns to the original directory
+
+		diff, err := git.WorkingTreeDiff()
+		require.NoError(t, err)
+		assert.Contains(t, diff, "+staged")
+		assert.Contains(t, diff, "+untracked")
+	})
+
 	t.Run("Create branch fails if parent not tracked", func(t *testing.T) {
 		repoPath, cleanup := testutils.SetupGitRepo(t)
 		defer cleanup()
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe [description]",
+	Short: "Describe what the current branch changes, for its PR and the stack comment",
+	Long: `Attaches a description, typically one paragraph, to the current branch.
+
+'so submit' uses it as the body of the branch's new PR, above the PR template
+if there is one, and the stack comment shows its first line next to the PR.
+This keeps the prose reviewers read separate from commit messages.
+
+  so describe Cache tokens per installation so webhooks stop hitting the API
+  so describe --edit     # Write it in $EDITOR
+  so describe            # Show it
+  so describe --clear
+
+Words are joined, so quoting is optional. The description is stored in git
+config as branch.<name>.socle-description and travels with the branch through
+snapshots and 'so restore'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clear, _ := cmd.Flags().GetBool("clear")
+		edit, _ := cmd.Flags().GetBool("edit")
+
+		runner := &describeCmdRunner{
+			logger:      slog.Default(),
+			stdout:      cmd.OutOrStdout(),
+			stderr:      cmd.ErrOrStderr(),
+			description: strings.Join(args, " "),
+			clear:       clear,
+			edit:        edit,
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	AddCommand(describeCmd)
+	describeCmd.Flags().Bool("clear", false, "Remove the branch's description")
+	describeCmd.Flags().Bool("edit", false, "Write the description in your editor")
+	describeCmd.MarkFlagsMutuallyExclusive("clear", "edit")
+}
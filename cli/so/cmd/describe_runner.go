@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+// descriptionSummaryLength caps the summary shown in the stack comment.
+const descriptionSummaryLength = 80
+
+type describeCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	description string
+	clear       bool
+	edit        bool
+}
+
+func (r *describeCmdRunner) run() error {
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if _, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-parent")); err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", branch)
+		}
+		return fmt.Errorf("failed to check tracking status for branch '%s': %w", branch, err)
+	}
+
+	if r.clear {
+		if err := git.SetBranchDescription(branch, ""); err != nil {
+			return fmt.Errorf("failed to clear the description of '%s': %w", branch, err)
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Cleared the description of '%s'.", branch)))
+		return nil
+	}
+
+	current, err := git.GetBranchDescription(branch)
+	if err != nil {
+		return fmt.Errorf("failed to read the description of '%s': %w", branch, err)
+	}
+
+	description := strings.TrimSpace(r.description)
+	if r.edit {
+		if nonInteractive {
+			return fmt.Errorf("--edit needs an interactive terminal; pass the description as arguments instead")
+		}
+		initial := current
+		if description != "" {
+			initial = description
+		}
+		prompt := &survey.Editor{Message: fmt.Sprintf("Description of '%s':", branch), FileName: "*.md", Default: initial, HideDefault: true, AppendDefault: true}
+		if err := ui.AskOne(prompt, &description, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr)); err != nil {
+			return fmt.Errorf("failed to edit the description: %w", err)
+		}
+		description = strings.TrimSpace(description)
+		if description == "" {
+			return fmt.Errorf("description is empty; use 'so describe --clear' to remove it")
+		}
+	}
+
+	if description == "" {
+		if current == "" {
+			_, _ = fmt.Fprintf(r.stdout, "'%s' has no description. Add one with 'so describe <text>' or 'so describe --edit'.\n", branch)
+			return nil
+		}
+		_, _ = fmt.Fprintln(r.stdout, current)
+		return nil
+	}
+
+	if err := git.SetBranchDescription(branch, description); err != nil {
+		return fmt.Errorf("failed to save the description of '%s': %w", branch, err)
+	}
+	r.logger.Debug("Described branch", "branch", branch, "length", len(description))
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Described '%s'.", branch)))
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("'so submit' uses it as the body of new PRs and in the stack comment."))
+	return nil
+}
+
+// summarizeDescription returns the first line of a description without
+// Markdown heading marks, shortened to fit a stack comment line.
+func summarizeDescription(description string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	line = strings.TrimSpace(strings.TrimLeft(line, "# "))
+	if runes := []rune(line); len(runes) > descriptionSummaryLength {
+		line = strings.TrimSpace(string(runes[:descriptionSummaryLength-1])) + "…"
+	}
+	return line
+}
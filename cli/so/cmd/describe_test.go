@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeCommand(t *testing.T) {
+	t.Run("Sets, shows and clears the description of the current branch", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+
+		stdout, _, err := runSoCommandWithOutput(t, "describe")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "'feature-a' has no description.")
+
+		_, _, err = runSoCommandWithOutput(t, "describe", "Cache tokens", "per installation")
+		require.NoError(t, err)
+		description, _ := git.GetBranchDescription("feature-a")
+		assert.Equal(t, "Cache tokens per installation", description)
+
+		_, _, err = runSoCommandWithOutput(t, "describe", "Cache tokens per app installation")
+		require.NoError(t, err)
+		stdout, _, err = runSoCommandWithOutput(t, "describe")
+		require.NoError(t, err)
+		assert.Equal(t, "Cache tokens per app installation\n", stdout, "describing again replaces the description")
+
+		_, _, err = runSoCommandWithOutput(t, "describe", "--clear")
+		require.NoError(t, err)
+		description, _ = git.GetBranchDescription("feature-a")
+		assert.Empty(t, description)
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		_, _, err = runSoCommandWithOutput(t, "describe", "Trunk")
+		assert.ErrorContains(t, err, "branch 'main' is not tracked by socle")
+	})
+
+	t.Run("Submit uses it as the PR body and summarizes it in the stack comment", func(t *testing.T) {
+		originalCreateGHClient := gh.CreateClient
+		t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+		resetCommandFlags(t, submitCmd, "no-push", "draft", "no-draft", "test-title", "test-body")
+
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+		require.NoError(t, git.SetBranchDescription("feature-a", "Cache tokens per installation\n\nWebhooks no longer hit the token endpoint on every delivery."))
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("CreatePullRequest", "feature-a", "main", "A", "Cache tokens per installation\n\nWebhooks no longer hit the token endpoint on every delivery.", true).Return(
+			&github.PullRequest{Number: github.Ptr(101), HTMLURL: github.Ptr("url-a")}, nil,
+		).Once()
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "OPEN"}, nil).Once()
+		mockClient.On("FindCommentWithMarker", 101, stackCommentMarker).Return(int64(0), nil).Once()
+		mockClient.On("CreateComment", 101, mock.MatchedBy(func(body string) bool {
+			return assert.Contains(t, body, "* **#101** Cache tokens per installation  👈\n")
+		})).Return(&github.IssueComment{ID: github.Ptr(int64(5001))}, nil).Once()
+
+		_, _, err := runSoCommandWithOutput(t, "submit", "--no-push", "--test-title=A", "--non-interactive")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestSummarizeDescription(t *testing.T) {
+	assert.Equal(t, "Cache tokens", summarizeDescription("## Cache tokens\n\nDetails"))
+	long := summarizeDescription("This description is long enough that the stack comment would wrap on a narrow screen")
+	assert.Len(t, []rune(long), descriptionSummaryLength)
+	assert.Equal(t, "…", string([]rune(long)[descriptionSummaryLength-1:]))
+}
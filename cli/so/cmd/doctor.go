@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the repository setup for slow or missing git settings",
+	Long: `Reports git settings that make socle slow on large repositories. Most of
+socle's time goes into ancestry and merge-base queries, which git answers
+from the commit-graph when one exists and is kept current:
+
+  core.commitGraph        Lets git read the commit-graph (on by default)
+  fetch.writeCommitGraph  Adds fetched commits to the commit-graph
+  commit-graph file       Whether a commit-graph has been written at all
+  git maintenance         Whether background maintenance keeps it current
+
+With --apply, the settings are enabled in the repository's local config, the
+commit-graph is written immediately and the repository is registered for
+'git maintenance' with a scheduled background job ('git maintenance start').
+Use --no-schedule to register without installing a scheduler entry.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apply, _ := cmd.Flags().GetBool("apply")
+		noSchedule, _ := cmd.Flags().GetBool("no-schedule")
+
+		runner := &doctorCmdRunner{
+			logger:   slog.Default(),
+			stdout:   cmd.OutOrStdout(),
+			stderr:   cmd.ErrOrStderr(),
+			apply:    apply,
+			schedule: !noSchedule,
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("apply", false, "Enable the recommended settings and register git maintenance")
+	doctorCmd.Flags().Bool("no-schedule", false, "With --apply, register for git maintenance without scheduling it")
+}
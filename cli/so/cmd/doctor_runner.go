@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type doctorCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	apply    bool
+	schedule bool
+}
+
+func (r *doctorCmdRunner) run() error {
+	checks, err := git.CheckMaintenance()
+	if err != nil {
+		return err
+	}
+	failing := r.printChecks("Git performance settings:", checks)
+
+	if failing == 0 {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Everything looks good."))
+		return nil
+	}
+	if !r.apply {
+		_, _ = fmt.Fprintln(r.stdout, "Run 'so doctor --apply' to enable the recommended settings.")
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, "\nApplying recommended settings...")
+	if err := git.ApplyMaintenance(r.schedule); err != nil {
+		if !errors.Is(err, git.ErrMaintenanceNotScheduled) {
+			return err
+		}
+		r.logger.Debug("Scheduling git maintenance failed", "error", err)
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: %v", err)))
+		_, _ = fmt.Fprintln(r.stderr, "Background maintenance only runs when git triggers it; run 'git maintenance start' to schedule it.")
+	}
+
+	checks, err = git.CheckMaintenance()
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(r.stdout)
+	if r.printChecks("After applying:", checks) > 0 {
+		return fmt.Errorf("some settings could not be applied")
+	}
+	return nil
+}
+
+// printChecks prints one line per check and returns how many are failing.
+func (r *doctorCmdRunner) printChecks(title string, checks []git.MaintenanceCheck) int {
+	_, _ = fmt.Fprintln(r.stdout, title)
+	failing := 0
+	for _, check := range checks {
+		mark := ui.Colors.SuccessStyle.Render("✓")
+		if !check.OK {
+			mark = ui.Colors.FailureStyle.Render("✗")
+			failing++
+		}
+		_, _ = fmt.Fprintf(r.stdout, "  %s %-24s %s\n", mark, check.Name, ui.Colors.MutedStyle.Render(check.Detail))
+	}
+	return failing
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorCommand(t *testing.T) {
+	resetCommandFlags(t, doctorCmd, "apply", "no-schedule")
+
+	// git maintenance registers repositories in the global config; keep that
+	// out of the real one.
+	isolateGlobalConfig := func(t *testing.T) string {
+		globalConfig := filepath.Join(t.TempDir(), "gitconfig")
+		require.NoError(t, os.WriteFile(globalConfig, nil, 0o644))
+		t.Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+		return globalConfig
+	}
+
+	t.Run("Reports missing settings and applies them", func(t *testing.T) {
+		globalConfig := isolateGlobalConfig(t)
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "core.commitGraph", "false")
+
+		stdout, _, err := runSoCommandWithOutput(t, "doctor")
+		require.NoError(t, err)
+		stdout = stripAnsi(stdout)
+		assert.Contains(t, stdout, "✗ core.commitGraph")
+		assert.Contains(t, stdout, "✗ fetch.writeCommitGraph")
+		assert.Contains(t, stdout, "✗ commit-graph file")
+		assert.Contains(t, stdout, "✗ git maintenance")
+		assert.Contains(t, stdout, "Run 'so doctor --apply'")
+
+		stdout, _, err = runSoCommandWithOutput(t, "doctor", "--apply", "--no-schedule")
+		require.NoError(t, err)
+		after := stripAnsi(stdout)[strings.Index(stripAnsi(stdout), "After applying:"):]
+		assert.NotContains(t, after, "✗")
+		assert.Equal(t, 4, strings.Count(after, "✓"))
+
+		assert.Equal(t, "true", strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "--local", "fetch.writeCommitGraph")))
+		assert.Equal(t, "true", strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "--local", "core.commitGraph")))
+		global, err := os.ReadFile(globalConfig)
+		require.NoError(t, err)
+		assert.Contains(t, string(global), "[maintenance]")
+
+		stdout, _, err = runSoCommandWithOutput(t, "doctor", "--apply=false")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Everything looks good.")
+	})
+}
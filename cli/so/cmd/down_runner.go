@@ -16,7 +16,7 @@ type downCmdRunner struct {
 }
 
 func (r *downCmdRunner) run() error {
-	stackInfo, err := git.GetStackInfo()
+	stackInfo, err := git.GetNavigationStackInfo()
 	if err != nil {
 		_, _ = fmt.Fprintf(r.stdout, "Error getting stack info: %s\n", err)
 		return nil
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
 	"github.com/benekuehn/socle/cli/so/internal/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -102,4 +104,49 @@ func TestDownCommand(t *testing.T) {
 		require.NoError(t, gitErr)
 		assert.Equal(t, "untracked-feat", currentBranch)
 	})
+
+	t.Run("Checkout parent branch above a fork", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithForkedStack(t)
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feat-d")
+		for _, want := range []string{"feat-c", "feat-a"} {
+			_, stderr, err := runSoCommandWithOutput(t, "down")
+			require.NoError(t, err)
+			assert.Empty(t, stderr)
+			currentBranch, gitErr := git.GetCurrentBranch()
+			require.NoError(t, gitErr)
+			assert.Equal(t, want, currentBranch)
+		}
+
+		_, _, err := runSoCommandWithOutput(t, "bottom")
+		require.NoError(t, err)
+		currentBranch, gitErr := git.GetCurrentBranch()
+		require.NoError(t, gitErr)
+		assert.Equal(t, "feat-a", currentBranch)
+	})
+
+	t.Run("Local changes in the way", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		// Only feature-b has its file, so checking out feature-a would lose the edit.
+		writeFile(t, repoPath, "feature-b.txt", "edited\n")
+		_, _, err := runSoCommandWithOutput(t, "down")
+		require.Error(t, err)
+		assert.Equal(t, socleerr.DirtyWorktree, socleerr.KindOf(err))
+		assert.Contains(t, err.Error(), "uncommitted changes detected in 'feature-b'")
+
+		// Other failures on a dirty tree are reported as they are.
+		err = git.CheckoutBranch("no-such-branch")
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, git.ErrLocalChanges))
+
+		// An untracked file the target branch has is in the way too.
+		testutils.RunCommand(t, repoPath, "git", "checkout", "--", "feature-b.txt")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+		writeFile(t, repoPath, "feature-b.txt", "untracked\n")
+		err = git.CheckoutBranch("feature-b")
+		assert.ErrorIs(t, err, git.ErrLocalChanges)
+	})
 }
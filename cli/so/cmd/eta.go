@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var etaCmd = &cobra.Command{
+	Use:   "eta",
+	Short: "Estimate which PRs of the current stack can land today and how long each has waited",
+	Long: `Gives a quick health view of the current stack's pull requests, bottom to
+top, from their review and CI state and the times they were opened and last
+updated.
+
+A PR can land today when nothing stands in its way but things that resolve
+on their own (checks still running, a branch that only needs updating) and
+every PR below it can land today too. Anything else, such as a missing
+review, requested changes, failing checks or conflicts, marks it blocked,
+and the PRs above it wait on it.
+
+For every open PR that is not approved yet, the report shows how long it
+has waited in review since it was opened.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &etaCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	AddCommand(etaCmd)
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type etaCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// etaEntry is one branch of the projection report.
+type etaEntry struct {
+	branch    string
+	prNumber  int
+	merged    bool
+	today     bool
+	reasons   []string
+	readiness *gh.MergeReadiness
+}
+
+func (r *etaCmdRunner) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	if stackInfo.FullStack == nil || len(stackInfo.FullStack) <= 1 {
+		return fmt.Errorf("no stack to report on: check out a tracked branch of the stack first")
+	}
+	stack := stackInfo.FullStack
+
+	client, err := newOriginGitHubClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	steps, err := buildLandingPlan(r.logger, client, stack[1:])
+	if err != nil {
+		return err
+	}
+	r.printReport(stack[0], project(steps), time.Now())
+	return nil
+}
+
+// project classifies the landing plan's steps. Transient blockers clear
+// without anyone acting on the PR, so they do not keep it from landing today;
+// a PR can only land today when every unmerged PR below it can too.
+func project(steps []landStep) []etaEntry {
+	entries := make([]etaEntry, 0, len(steps))
+	waitingOn := "" // Lowest PR that cannot land today
+
+	for _, step := range steps {
+		entry := etaEntry{branch: step.branch, prNumber: step.prNumber, merged: step.merged, readiness: step.readiness}
+		if !entry.merged {
+			entry.reasons = append(entry.reasons, step.problems...)
+			for _, b := range step.blockers {
+				if !b.Transient() {
+					entry.reasons = append(entry.reasons, string(b))
+				}
+			}
+			if waitingOn != "" {
+				entry.reasons = append([]string{"waits for " + waitingOn}, entry.reasons...)
+			}
+			entry.today = len(entry.reasons) == 0
+			if !entry.today && waitingOn == "" {
+				if entry.prNumber > 0 {
+					waitingOn = fmt.Sprintf("#%d", entry.prNumber)
+				} else {
+					waitingOn = fmt.Sprintf("'%s'", step.branch)
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// reviewWait returns how long an open, unapproved PR has waited in review.
+// Drafts and PRs without a creation time are not waiting.
+func reviewWait(m *gh.MergeReadiness, now time.Time) (time.Duration, bool) {
+	if m == nil || m.State != "OPEN" || m.IsDraft || m.ReviewDecision == "APPROVED" || m.CreatedAt.IsZero() {
+		return 0, false
+	}
+	return now.Sub(m.CreatedAt), true
+}
+
+func (r *etaCmdRunner) printReport(baseBranch string, entries []etaEntry, now time.Time) {
+	_, _ = fmt.Fprintf(r.stdout, "Projection for the stack on '%s' (bottom to top):\n\n", baseBranch)
+
+	width := 0
+	for _, e := range entries {
+		if len(e.branch) > width {
+			width = len(e.branch)
+		}
+	}
+
+	todayCount, blockedCount := 0, 0
+	longest, longestPR := time.Duration(0), 0
+	for _, e := range entries {
+		pr := "  -  "
+		if e.prNumber > 0 {
+			pr = fmt.Sprintf("#%-4d", e.prNumber)
+		}
+
+		var marker, detail string
+		switch {
+		case e.merged:
+			marker = ui.Colors.SuccessStyle.Render("✓")
+			detail = mutedStyle.Render("already merged")
+		case e.today:
+			todayCount++
+			marker = ui.Colors.SuccessStyle.Render("●")
+			detail = ui.Colors.SuccessStyle.Render("can land today")
+		default:
+			blockedCount++
+			marker = ui.Colors.WarningStyle.Render("○")
+			detail = ui.Colors.WarningStyle.Render("blocked: ") + strings.Join(e.reasons, ", ")
+		}
+
+		var timing []string
+		if wait, ok := reviewWait(e.readiness, now); ok {
+			timing = append(timing, "in review for "+formatAge(wait))
+			if wait > longest {
+				longest, longestPR = wait, e.prNumber
+			}
+		} else if e.readiness != nil && !e.readiness.CreatedAt.IsZero() {
+			timing = append(timing, "open for "+formatAge(now.Sub(e.readiness.CreatedAt)))
+		}
+		if e.readiness != nil && !e.readiness.UpdatedAt.IsZero() {
+			timing = append(timing, "updated "+formatAge(now.Sub(e.readiness.UpdatedAt))+" ago")
+		}
+		if len(timing) > 0 {
+			detail += "  " + mutedStyle.Render("("+strings.Join(timing, ", ")+")")
+		}
+
+		_, _ = fmt.Fprintf(r.stdout, "  %s %-*s  %s  %s\n", marker, width, e.branch, pr, detail)
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "\n%d can land today, %d blocked.\n", todayCount, blockedCount)
+	if longestPR > 0 {
+		_, _ = fmt.Fprintf(r.stdout, "Longest wait in review: #%d (%s).\n", longestPR, formatAge(longest))
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtaCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	t.Run("Reports what can land today and how long PRs wait in review", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c", "feature-d"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-c.socle-pr-number", "103")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-d.socle-pr-number", "104")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		now := time.Now()
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "MERGED"}, nil).Once()
+		// Approved with checks still running: lands today.
+		mockClient.On("GetMergeReadiness", 102).Return(&gh.MergeReadiness{
+			Number: 102, State: "OPEN", MergeStateStatus: "BLOCKED", ReviewDecision: "APPROVED", ChecksState: "PENDING",
+			CreatedAt: now.Add(-50 * time.Hour), UpdatedAt: now.Add(-3 * time.Hour),
+		}, nil).Once()
+		mockClient.On("GetMergeReadiness", 103).Return(&gh.MergeReadiness{
+			Number: 103, State: "OPEN", MergeStateStatus: "BLOCKED", ReviewDecision: "REVIEW_REQUIRED", ChecksState: "SUCCESS",
+			CreatedAt: now.Add(-4*24*time.Hour - time.Hour), UpdatedAt: now.Add(-30 * time.Minute),
+		}, nil).Once()
+		mockClient.On("GetMergeReadiness", 104).Return(&gh.MergeReadiness{
+			Number: 104, State: "OPEN", MergeStateStatus: "CLEAN", ReviewDecision: "APPROVED", ChecksState: "SUCCESS",
+			CreatedAt: now.Add(-5 * time.Hour), UpdatedAt: now.Add(-5 * time.Hour),
+		}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "eta")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "Projection for the stack on 'main' (bottom to top):")
+		assert.Regexp(t, `✓ feature-a\s+#101\s+already merged`, out)
+		assert.Regexp(t, `● feature-b\s+#102\s+can land today  \(open for 2 days, updated 3 hours ago\)`, out)
+		assert.Regexp(t, `○ feature-c\s+#103\s+blocked: review required  \(in review for 4 days, updated 30 minutes ago\)`, out)
+		assert.Regexp(t, `○ feature-d\s+#104\s+blocked: waits for #103  \(open for 5 hours, updated 5 hours ago\)`, out)
+		assert.Contains(t, out, "1 can land today, 2 blocked.")
+		assert.Contains(t, out, "Longest wait in review: #103 (4 days).")
+	})
+
+	t.Run("Branch without a PR blocks everything above it", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("GetMergeReadiness", 102).Return(&gh.MergeReadiness{
+			Number: 102, State: "OPEN", IsDraft: true, MergeStateStatus: "DRAFT",
+		}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "eta")
+		require.NoError(t, err)
+
+		out := stripAnsi(stdout)
+		assert.Regexp(t, `○ feature-a\s+-\s+blocked: no PR submitted`, out)
+		assert.Regexp(t, `○ feature-b\s+#102\s+blocked: waits for 'feature-a', PR is a draft`, out)
+		assert.Contains(t, out, "0 can land today, 2 blocked.")
+		assert.NotContains(t, out, "Longest wait in review")
+	})
+}
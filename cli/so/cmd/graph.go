@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Visualize the stack graph",
+	Long: `Groups commands that present the structure of the tracked stacks outside
+the terminal.`,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	AddCommand(graphCmd)
+}
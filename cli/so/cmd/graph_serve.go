@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+)
+
+var graphServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a read-only web page showing the stack graph",
+	Long: `Starts a small local web server rendering every stack from the current base
+as a graph, with restack, WIP and PR status and links to the pull requests.
+The page refreshes itself, so it stays current while you restack, submit or
+switch branches in another terminal; handy for screen-sharing stack structure
+during reviews.
+
+The page is read-only and reads the same data as 'so log --porcelain', which
+is also available as JSON at /api/stacks. The server listens on localhost
+only unless --addr says otherwise. Stop it with Ctrl+C.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		refresh, _ := cmd.Flags().GetDuration("refresh")
+		everyone, _ := cmd.Flags().GetBool("everyone")
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		runner := &graphServeCmdRunner{
+			logger:   slog.Default(),
+			stdout:   cmd.OutOrStdout(),
+			stderr:   cmd.ErrOrStderr(),
+			addr:     addr,
+			refresh:  refresh,
+			everyone: everyone,
+		}
+		return runner.run(ctx)
+	},
+}
+
+func init() {
+	graphCmd.AddCommand(graphServeCmd)
+	graphServeCmd.Flags().String("addr", "127.0.0.1:7878", "Address to listen on")
+	graphServeCmd.Flags().Duration("refresh", defaultGraphRefresh, "How often the page reloads the stack data")
+	graphServeCmd.Flags().Bool("everyone", false, "Show stacks from all authors")
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+const defaultGraphRefresh = 5 * time.Second
+
+type graphServeCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	addr     string
+	refresh  time.Duration
+	everyone bool
+}
+
+// graphSnapshot is the payload of /api/stacks.
+type graphSnapshot struct {
+	Base          string          `json:"base,omitempty"`
+	CurrentBranch string          `json:"currentBranch,omitempty"`
+	Stacks        [][]stackRecord `json:"stacks"`
+	Message       string          `json:"message,omitempty"` // Why no stacks are shown
+	GeneratedAt   time.Time       `json:"generatedAt"`
+}
+
+func (r *graphServeCmdRunner) run(ctx context.Context) error {
+	if r.refresh <= 0 {
+		r.refresh = defaultGraphRefresh
+	}
+
+	listener, err := net.Listen("tcp", r.addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on '%s': %w", r.addr, err)
+	}
+
+	server := &http.Server{Handler: r.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	_, _ = fmt.Fprintf(r.stdout, "Serving the stack graph at %s\n", ui.Colors.InfoStyle.Render("http://"+listener.Addr().String()))
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("Press Ctrl+C to stop."))
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("graph server failed: %w", err)
+	}
+	return nil
+}
+
+// handler serves the page at / and the stack data at /api/stacks.
+func (r *graphServeCmdRunner) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := graphPageTemplate.Execute(w, struct{ RefreshMillis int64 }{r.refresh.Milliseconds()}); err != nil {
+			r.logger.Debug("Failed to render graph page", "error", err)
+		}
+	})
+	mux.HandleFunc("/api/stacks", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := json.NewEncoder(w).Encode(r.snapshot(req.Context())); err != nil {
+			r.logger.Debug("Failed to write stack data", "error", err)
+		}
+	})
+	return mux
+}
+
+// snapshot reads the current stack state. It is recomputed on every request so
+// restacks and branch switches show up on the next refresh.
+func (r *graphServeCmdRunner) snapshot(ctx context.Context) graphSnapshot {
+	snap := graphSnapshot{Stacks: [][]stackRecord{}, GeneratedAt: time.Now().UTC()}
+
+	currentBranch, _ := git.GetCurrentBranch()
+	snap.CurrentBranch = currentBranch
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		if errors.Is(err, git.ErrNotTracked) {
+			snap.Message = fmt.Sprintf("Branch '%s' is not tracked by socle. Check out a tracked branch or its base.", currentBranch)
+		} else {
+			snap.Message = err.Error()
+		}
+		return snap
+	}
+	snap.Base = stackInfo.BaseBranch
+
+	logRunner := &logCmdRunner{
+		logger:   r.logger,
+		stdout:   io.Discard,
+		stderr:   io.Discard, // Per-branch warnings surface as "?" states instead
+		all:      true,
+		everyone: r.everyone,
+	}
+	if stacks := logRunner.collectStackRecords(ctx, stackInfo, currentBranch); len(stacks) > 0 {
+		snap.Stacks = stacks
+	} else {
+		snap.Message = fmt.Sprintf("No stacks found starting from base branch '%s'.", stackInfo.BaseBranch)
+	}
+	return snap
+}
+
+var graphPageTemplate = template.Must(template.New("graph").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>socle stacks</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #1f2328; background: #fff; }
+  h1 { font-size: 1.25rem; margin: 0 0 .25rem; }
+  .meta { color: #656d76; font-size: .85rem; margin-bottom: 1.5rem; }
+  .stack { border: 1px solid #d0d7de; border-radius: 6px; padding: 1rem 1.25rem; margin-bottom: 1.25rem; }
+  .node { display: flex; align-items: center; gap: .5rem; position: relative; padding: .35rem 0 .35rem 1.5rem; }
+  .node::before { content: ""; position: absolute; left: .45rem; top: 0; bottom: 0; border-left: 2px solid #d0d7de; }
+  .node:first-child::before { top: 50%; }
+  .node:last-child::before { bottom: 50%; }
+  .node::after { content: ""; position: absolute; left: .2rem; top: calc(50% - .3rem); width: .6rem; height: .6rem; border-radius: 50%; background: #1a7f37; }
+  .node.restack::after { background: #bf8700; }
+  .node.unknown::after { background: #cf222e; }
+  .node.base::after { background: #8c959f; }
+  .node.base { color: #656d76; }
+  .branch { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-weight: 600; }
+  .current .branch::after { content: " \2190 current"; font-weight: normal; color: #0969da; }
+  .badge { font-size: .75rem; border-radius: 2em; padding: 0 .5rem; border: 1px solid #d0d7de; color: #656d76; }
+  .badge.open { border-color: #1a7f37; color: #1a7f37; }
+  .badge.draft { border-color: #8c959f; }
+  .badge.merged { border-color: #8250df; color: #8250df; }
+  .badge.closed, .badge.error { border-color: #cf222e; color: #cf222e; }
+  .badge.restack, .badge.wip { border-color: #bf8700; color: #bf8700; }
+  .badge.conflicts { border-color: #cf222e; color: #cf222e; }
+  .note { color: #656d76; font-size: .85rem; }
+  a { color: inherit; }
+  .message { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>socle stacks</h1>
+<div class="meta" id="meta">Loading…</div>
+<div id="stacks"></div>
+<script>
+const refreshMillis = {{.RefreshMillis}};
+
+function el(tag, cls, text) {
+  const e = document.createElement(tag);
+  if (cls) e.className = cls;
+  if (text !== undefined) e.textContent = text;
+  return e;
+}
+
+function badge(cls, text, href) {
+  const b = el(href ? "a" : "span", "badge " + cls, text);
+  if (href) { b.href = href; b.target = "_blank"; b.rel = "noopener"; }
+  return b;
+}
+
+function render(data) {
+  const meta = document.getElementById("meta");
+  meta.textContent = (data.base ? "Base " + data.base + " · " : "") +
+    "updated " + new Date(data.generatedAt).toLocaleTimeString();
+
+  const container = document.getElementById("stacks");
+  container.replaceChildren();
+  if (data.message) container.appendChild(el("p", "message", data.message));
+
+  for (const stack of data.stacks) {
+    const box = el("div", "stack");
+    // Records are bottom first; draw the top of the stack first like 'so log'.
+    for (const r of [...stack].reverse()) {
+      const node = el("div", "node");
+      if (r.needsRestack === "1") node.classList.add("restack");
+      if (r.needsRestack === "?") node.classList.add("unknown");
+      if (r.current) node.classList.add("current");
+      node.appendChild(el("span", "branch", r.branch));
+      if (r.wip) node.appendChild(badge("wip", "WIP"));
+      if (r.needsRestack === "1") node.appendChild(badge("restack", "needs restack"));
+      if (r.prState !== "none") {
+        const label = (r.prNumber ? "#" + r.prNumber + " " : "") + r.prState;
+        node.appendChild(badge(r.prState, label, r.prURL));
+      }
+      if (r.conflictsWith) node.appendChild(badge("conflicts", "conflicts with " + r.conflictsWith));
+      if (r.note) node.appendChild(el("span", "note", r.note));
+      box.appendChild(node);
+    }
+    const base = el("div", "node base");
+    base.appendChild(el("span", "branch", stack[0].base));
+    base.appendChild(el("span", null, "(base)"));
+    box.appendChild(base);
+    container.appendChild(box);
+  }
+}
+
+async function refresh() {
+  try {
+    const res = await fetch("/api/stacks", { cache: "no-store" });
+    render(await res.json());
+  } catch (e) {
+    document.getElementById("meta").textContent = "Cannot reach 'so graph serve': " + e;
+  }
+}
+
+refresh();
+setInterval(refresh, refreshMillis);
+</script>
+</body>
+</html>
+`))
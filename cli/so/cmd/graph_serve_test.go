@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphServe(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, logCmd, "porcelain")
+
+	newRunner := func() *graphServeCmdRunner {
+		return &graphServeCmdRunner{
+			logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+			stdout:   io.Discard,
+			stderr:   io.Discard,
+			refresh:  defaultGraphRefresh,
+			everyone: true,
+		}
+	}
+
+	get := func(t *testing.T, handler http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("Serves stack records matching the porcelain layer", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		require.NoError(t, git.SetStoredPRNumber("feature-a", 7))
+
+		mockClient := gh.NewMockClient()
+		mockClient.PRStatuses[7] = gh.PRStatusOpen
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+
+		rec := get(t, newRunner().handler(), "/api/stacks")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var snap graphSnapshot
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snap))
+		assert.Equal(t, "main", snap.Base)
+		assert.Equal(t, "feature-b", snap.CurrentBranch)
+		require.Len(t, snap.Stacks, 1)
+		require.Len(t, snap.Stacks[0], 2)
+
+		bottom, top := snap.Stacks[0][0], snap.Stacks[0][1]
+		assert.Equal(t, stackRecord{
+			Branch: "feature-a", Parent: "main", Base: "main", NeedsRestack: "0",
+			PRNumber: 7, PRState: "open", PRURL: "https://github.com/mock/mock/pull/7",
+		}, bottom)
+		assert.Equal(t, "feature-b", top.Branch)
+		assert.Equal(t, "none", top.PRState)
+		assert.True(t, top.Current)
+
+		// The same records drive --porcelain=v1
+		stdout, _, err := runSoCommandWithOutput(t, "log", "--porcelain")
+		require.NoError(t, err)
+		assert.Equal(t, porcelainLine(bottom)+"\n"+porcelainLine(top)+"\n", stdout)
+	})
+
+	t.Run("Serves the page and reflects branch switches", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return gh.NewMockClient(), nil
+		}
+		handler := newRunner().handler()
+
+		page := get(t, handler, "/")
+		require.Equal(t, http.StatusOK, page.Code)
+		assert.Contains(t, page.Body.String(), "/api/stacks")
+		assert.Contains(t, page.Body.String(), "const refreshMillis =  5000 ;")
+		assert.Equal(t, http.StatusNotFound, get(t, handler, "/favicon.ico").Code)
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", "scratch")
+		var snap graphSnapshot
+		require.NoError(t, json.Unmarshal(get(t, handler, "/api/stacks").Body.Bytes(), &snap))
+		assert.Empty(t, snap.Stacks)
+		assert.Contains(t, snap.Message, "'scratch' is not tracked by socle")
+	})
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// withNextStepHint wraps the RunE of a command that leaves the stack in a new
+// state and, when it succeeds or stops on a conflict, prints what to do
+// next. Hints are off with --non-interactive or 'git config socle.hints false'.
+func withNextStepHint(run runEFunc) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		runErr := run(cmd, args)
+		if runErr != nil && socleerr.KindOf(runErr) != socleerr.Conflict {
+			return runErr
+		}
+		if nonInteractive {
+			return runErr
+		}
+		enabled, err := git.GetSocleConfigBool("socle.hints", true)
+		if err != nil || !enabled {
+			return runErr
+		}
+		if hint := nextStepHint(slog.Default()); hint != "" {
+			printNextStep(cmd.OutOrStdout(), hint)
+		}
+		return runErr
+	}
+}
+
+func printNextStep(w io.Writer, hint string) {
+	_, _ = fmt.Fprintln(w, ui.Colors.MutedStyle.Render("\nnext: "+hint))
+}
+
+// nextStepHint inspects the repository and returns the most useful next
+// command for the current branch's stack, or "" when there is nothing to
+// suggest. Problems reading state are logged and produce no hint.
+func nextStepHint(logger *slog.Logger) string {
+	if git.IsRebaseInProgress() {
+		if p, err := loadRestackProgress(); err == nil && p != nil && p.ownsRebase() {
+			return "resolve the conflicts, 'git add' them and run 'so continue'"
+		}
+		return "resolve the conflicts, 'git add' them and run 'git rebase --continue', then 'so restack'"
+	}
+	if git.IsCherryPickInProgress() {
+		return "resolve the conflicts, 'git add' them and run 'git cherry-pick --continue', then run the so command again"
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		logger.Debug("No next-step hint: could not read the stack", "error", err)
+		return ""
+	}
+	stack := stackInfo.FullStack
+	if len(stack) <= 1 {
+		if git.IsKnownBaseBranch(stackInfo.CurrentBranch) {
+			return "'so create <branch>' to start a stack"
+		}
+		return ""
+	}
+
+	current, parent := stackInfo.CurrentBranch, ""
+	for i := 1; i < len(stack); i++ {
+		if stack[i] == current {
+			parent = stack[i-1]
+		}
+	}
+	if parent != "" {
+		if dirty, err := git.HasUncommittedChanges(); err == nil && dirty {
+			return "commit your changes ('git commit', or 'so create <branch>' for a new branch), then 'so submit'"
+		}
+		if commits, err := git.GetCommits(parent, current); err == nil && len(commits) == 0 {
+			return "commit changes, then 'so submit'"
+		}
+	}
+
+	remote := git.GetRemoteName()
+	var unsubmitted, unpushed int
+	for i := 1; i < len(stack); i++ {
+		branch := stack[i]
+		if stale, err := git.NeedsRestack(stack[i-1], branch); err == nil && stale {
+			return fmt.Sprintf("'so restack' to rebase '%s' onto '%s'", branch, stack[i-1])
+		}
+		if number, err := git.GetStoredPRNumber(branch); err != nil || number == 0 {
+			unsubmitted++
+			continue
+		}
+		local, errLocal := git.GetCurrentBranchCommit(branch)
+		pushed, errRemote := git.GetRemoteBranchCommit(branch, remote)
+		if errLocal == nil && (errRemote != nil || local != pushed) {
+			unpushed++
+		}
+	}
+	switch {
+	case unsubmitted > 0:
+		return fmt.Sprintf("'so submit' to open PRs for %d branch(es)", unsubmitted)
+	case unpushed > 0:
+		return fmt.Sprintf("'so submit' to push %d updated branch(es)", unpushed)
+	}
+	return "once reviews and checks pass, 'so ship' merges the bottom PR"
+}
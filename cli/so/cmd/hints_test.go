@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextStepHint(t *testing.T) {
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	t.Cleanup(cleanup)
+	logger := slog.Default()
+	resetCommandFlags(t, restackCmd, "no-fetch", "no-push")
+
+	testutils.RunCommand(t, repoPath, "git", "checkout", "--quiet", "feature-b")
+	assert.Equal(t, "'so submit' to open PRs for 2 branch(es)", nextStepHint(logger))
+
+	writeFile(t, repoPath, "wip.txt", "wip\n")
+	assert.Contains(t, nextStepHint(logger), "commit your changes")
+	testutils.RunCommand(t, repoPath, "git", "stash", "--include-untracked", "--quiet")
+
+	require.NoError(t, git.SetStoredPRNumber("feature-a", 1))
+	require.NoError(t, git.SetStoredPRNumber("feature-b", 2))
+	assert.Equal(t, "'so submit' to push 2 updated branch(es)", nextStepHint(logger), "nothing was pushed yet")
+
+	testutils.RunCommand(t, repoPath, "git", "checkout", "--quiet", "main")
+	writeFile(t, repoPath, "main.txt", "moved on\n")
+	testutils.RunCommand(t, repoPath, "git", "add", "main.txt")
+	testutils.RunCommand(t, repoPath, "git", "commit", "--quiet", "-m", "main moves on")
+	testutils.RunCommand(t, repoPath, "git", "checkout", "--quiet", "feature-a")
+	assert.Equal(t, "'so restack' to rebase 'feature-a' onto 'main'", nextStepHint(logger))
+
+	stdout, _, err := runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push")
+	require.NoError(t, err)
+	assert.Contains(t, stripAnsi(stdout), "next: 'so submit' to push 2 updated branch(es)")
+
+	testutils.RunCommand(t, repoPath, "git", "config", "socle.hints", "false")
+	stdout, _, err = runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push")
+	require.NoError(t, err)
+	assert.NotContains(t, stdout, "next:")
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// ErrStackInvariantViolated is returned when a mutating command would have left
+// the socle metadata inconsistent. The metadata is rolled back before returning.
+var ErrStackInvariantViolated = errors.New("stack metadata invariants violated")
+
+type runEFunc func(cmd *cobra.Command, args []string) error
+
+// guardStackInvariants wraps the RunE of a command that mutates socle metadata.
+// It snapshots the metadata and records existing violations before the command
+// runs, then re-checks afterwards. Pre-existing problems only produce a warning;
+// any violation introduced by the command restores the snapshot and fails.
+func guardStackInvariants(run runEFunc) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		logger := slog.Default()
+		stderr := cmd.ErrOrStderr()
+
+		snapshot, err := git.ReadSocleMetadata()
+		if err != nil {
+			logger.Debug("Skipping invariant guard: could not snapshot metadata", "error", err)
+			return run(cmd, args)
+		}
+		before, err := git.CheckStackInvariants()
+		if err != nil {
+			logger.Debug("Skipping invariant guard: pre-check failed", "error", err)
+			return run(cmd, args)
+		}
+		if len(before) > 0 {
+			_, _ = fmt.Fprintln(stderr, ui.Colors.WarningStyle.Render("Warning: existing stack metadata is inconsistent:"))
+			for _, v := range before {
+				_, _ = fmt.Fprintf(stderr, "  - %s\n", v)
+			}
+		}
+
+		runErr := run(cmd, args)
+
+		after, err := git.CheckStackInvariants()
+		if err != nil {
+			logger.Debug("Invariant post-check failed", "error", err)
+			return runErr
+		}
+		introduced := newViolations(before, after)
+		if len(introduced) == 0 {
+			return runErr
+		}
+
+		lines := make([]string, 0, len(introduced))
+		for _, v := range introduced {
+			lines = append(lines, "  - "+v.String())
+		}
+		diagnostic := strings.Join(lines, "\n")
+
+		if restoreErr := git.RestoreSocleMetadata(snapshot); restoreErr != nil {
+			return errors.Join(runErr, fmt.Errorf("%w after '%s' and rollback failed (%v):\n%s",
+				ErrStackInvariantViolated, cmd.Name(), restoreErr, diagnostic))
+		}
+		logger.Debug("Rolled back socle metadata after invariant violation", "command", cmd.Name(), "count", len(introduced))
+		return errors.Join(runErr, fmt.Errorf("%w after '%s'; metadata changes were rolled back:\n%s",
+			ErrStackInvariantViolated, cmd.Name(), diagnostic))
+	}
+}
+
+// newViolations returns the entries of after that were not already in before.
+func newViolations(before, after []git.StackViolation) []git.StackViolation {
+	seen := make(map[git.StackViolation]bool, len(before))
+	for _, v := range before {
+		seen[v] = true
+	}
+	var introduced []git.StackViolation
+	for _, v := range after {
+		if !seen[v] {
+			introduced = append(introduced, v)
+		}
+	}
+	return introduced
+}
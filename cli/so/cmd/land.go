@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var landCmd = &cobra.Command{
+	Use:   "land",
+	Short: "Show the order in which the current stack's PRs can land",
+	Long: `Checks every pull request in the current stack against GitHub's merge
+requirements (required status checks, review decision and merge state) and
+prints a landing plan in stack order: which PR can merge now, and which are
+blocked and why.
+
+PRs higher in the stack can only land after the PRs below them, so they are
+listed as waiting on the lowest unmerged PR in addition to their own blockers.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plan, _ := cmd.Flags().GetBool("plan")
+		if !plan {
+			return fmt.Errorf("'so land' currently only supports --plan")
+		}
+
+		runner := &landCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	AddCommand(landCmd)
+	landCmd.Flags().Bool("plan", false, "Print the landing plan without merging anything")
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type landCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// landStep is one branch of the landing plan.
+type landStep struct {
+	branch    string
+	prNumber  int
+	merged    bool
+	ready     bool
+	waitsFor  string             // The lowest unlanded PR below, e.g. "#12"
+	problems  []string           // Why the PR's state is unknown: none submitted, query failed
+	blockers  []gh.Blocker       // What GitHub reports blocking the PR itself
+	readiness *gh.MergeReadiness // nil when problems is set
+}
+
+// reasons lists why the step cannot merge now, what it waits for first.
+func (s landStep) reasons() []string {
+	var reasons []string
+	if s.waitsFor != "" {
+		reasons = append(reasons, "waits for "+s.waitsFor)
+	}
+	reasons = append(reasons, s.problems...)
+	return append(reasons, blockerReasons(s.blockers)...)
+}
+
+// blockerReasons returns the human-readable form of blockers.
+func blockerReasons(blockers []gh.Blocker) []string {
+	reasons := make([]string, 0, len(blockers))
+	for _, b := range blockers {
+		reasons = append(reasons, string(b))
+	}
+	return reasons
+}
+
+func (r *landCmdRunner) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	if stackInfo.FullStack == nil || len(stackInfo.FullStack) <= 1 {
+		return fmt.Errorf("no stack to land: check out a tracked branch of the stack first")
+	}
+	stack := stackInfo.FullStack
+
+	remoteName := git.GetRemoteName()
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return fmt.Errorf("cannot get remote URL for '%s': %w", remoteName, err)
+	}
+	owner, repoName, err := git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", remoteName, remoteURL, err)
+	}
+	ghClient, err := gh.CreateClient(ctx, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	steps, err := buildLandingPlan(r.logger, ghClient, stack[1:])
+	if err != nil {
+		return err
+	}
+	r.printPlan(stack[0], steps)
+	return nil
+}
+
+// buildLandingPlan evaluates branches bottom to top. Only the lowest unmerged
+// PR can be ready; everything above it waits on it (or on whatever blocks it).
+func buildLandingPlan(logger *slog.Logger, client gh.ClientInterface, branches []string) ([]landStep, error) {
+	steps := make([]landStep, 0, len(branches))
+	waitingOn := "" // Describes the lowest unlanded step blocking everything above
+
+	for _, branch := range branches {
+		step := landStep{branch: branch}
+
+		prNumber, err := git.GetStoredPRNumber(branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PR number for '%s': %w", branch, err)
+		}
+		step.prNumber = prNumber
+
+		if prNumber == 0 {
+			step.problems = append(step.problems, "no PR submitted")
+		} else {
+			readiness, err := client.GetMergeReadiness(prNumber)
+			if err != nil {
+				logger.Debug("Failed to get merge readiness", "branch", branch, "pr", prNumber, "error", err)
+				step.problems = append(step.problems, fmt.Sprintf("could not query PR: %v", err))
+			} else if readiness.State == "MERGED" {
+				step.merged = true
+			} else {
+				step.readiness = readiness
+				step.blockers = readiness.Blockers()
+			}
+		}
+
+		if !step.merged {
+			if waitingOn != "" {
+				step.waitsFor = waitingOn
+			} else {
+				step.ready = len(step.problems) == 0 && len(step.blockers) == 0
+				if step.prNumber > 0 {
+					waitingOn = fmt.Sprintf("#%d", step.prNumber)
+				} else {
+					waitingOn = fmt.Sprintf("'%s'", branch)
+				}
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (r *landCmdRunner) printPlan(baseBranch string, steps []landStep) {
+	_, _ = fmt.Fprintf(r.stdout, "Landing plan into '%s' (bottom to top):\n\n", baseBranch)
+
+	width := 0
+	for _, s := range steps {
+		if len(s.branch) > width {
+			width = len(s.branch)
+		}
+	}
+
+	readyCount, blockedCount := 0, 0
+	for _, s := range steps {
+		pr := "  -  "
+		if s.prNumber > 0 {
+			pr = fmt.Sprintf("#%-4d", s.prNumber)
+		}
+
+		var marker, detail string
+		switch {
+		case s.merged:
+			marker = ui.Colors.SuccessStyle.Render("✓")
+			detail = mutedStyle.Render("already merged")
+		case s.ready:
+			readyCount++
+			marker = ui.Colors.SuccessStyle.Render("●")
+			detail = ui.Colors.SuccessStyle.Render("can merge now")
+		default:
+			blockedCount++
+			marker = ui.Colors.WarningStyle.Render("○")
+			detail = ui.Colors.WarningStyle.Render("blocked: ") + strings.Join(s.reasons(), ", ")
+		}
+		_, _ = fmt.Fprintf(r.stdout, "  %s %-*s  %s  %s\n", marker, width, s.branch, pr, detail)
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "\n%d ready to merge, %d blocked.\n", readyCount, blockedCount)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLandCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	t.Run("Plan shows which PR can merge now and why others are blocked", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c", "feature-d"})
+		defer cleanup()
+		resetCommandFlags(t, landCmd, "plan")
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-c.socle-pr-number", "103")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-b")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "MERGED"}, nil).Once()
+		mockClient.On("GetMergeReadiness", 102).Return(&gh.MergeReadiness{
+			Number: 102, State: "OPEN", MergeStateStatus: "CLEAN", ReviewDecision: "APPROVED", ChecksState: "SUCCESS",
+		}, nil).Once()
+		mockClient.On("GetMergeReadiness", 103).Return(&gh.MergeReadiness{
+			Number: 103, State: "OPEN", MergeStateStatus: "BLOCKED", ReviewDecision: "REVIEW_REQUIRED", ChecksState: "FAILURE",
+		}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "land", "--plan")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "Landing plan into 'main' (bottom to top):")
+		assert.Regexp(t, `✓ feature-a\s+#101\s+already merged`, out)
+		assert.Regexp(t, `● feature-b\s+#102\s+can merge now`, out)
+		assert.Regexp(t, `○ feature-c\s+#103\s+blocked: waits for #102, review required, checks failing`, out)
+		assert.Regexp(t, `○ feature-d\s+-\s+blocked: waits for #102, no PR submitted`, out)
+		assert.Contains(t, out, "1 ready to merge, 2 blocked.")
+	})
+
+	t.Run("Bottom PR with conflicts blocks the stack", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		resetCommandFlags(t, landCmd, "plan")
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "OPEN", MergeStateStatus: "DIRTY"}, nil).Once()
+		mockClient.On("GetMergeReadiness", 102).Return(nil, errors.New("boom")).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "land", "--plan")
+		require.NoError(t, err)
+
+		out := stripAnsi(stdout)
+		assert.Regexp(t, `○ feature-a\s+#101\s+blocked: merge conflicts`, out)
+		assert.Regexp(t, `○ feature-b\s+#102\s+blocked: waits for #101, could not query PR: boom`, out)
+		assert.Contains(t, out, "0 ready to merge, 2 blocked.")
+	})
+
+	t.Run("Requires --plan", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		err := runSoCommand(t, "land")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only supports --plan")
+	})
+}
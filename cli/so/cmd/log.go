@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
+	"github.com/benekuehn/socle/cli/so/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -12,12 +14,88 @@ var logCmd = &cobra.Command{
 	Short: "Display the current tracked stack of branches",
 	Long: `Shows the sequence of tracked branches leading from the stack's base
 branch to the current branch, based on metadata set by 'socle track'.
-Includes status indicating if a branch needs rebasing onto its parent.`,
+Includes status indicating if a branch needs rebasing onto its parent.
+The status is an ancestry check only, so it never reads file contents; in
+partial clones (or with 'socle.sparseSafe' set to true) socle also avoids blob
+reads elsewhere, e.g. comparing trees instead of diffing on submit.
+
+The first line compares the base branch with its remote-tracking branch: how
+many commits (and how many days of history) it is behind, and when the remote
+was last fetched. If the base is far behind or the fetch is old, the statuses
+below are stale; run 'so sync' or 'git fetch' first, or pass --fetch to have
+log run 'git fetch --prune' itself. With 'socle.log.autofetchInterval' set to
+N, log fetches on its own whenever the last fetch is more than N minutes old.
+
+PR statuses are cached for a minute (socle.prStatusCacheTTL, in seconds; 0
+turns the cache off), so running log again right away costs no API calls.
+Pass --no-cache to ask GitHub for every status. Merging with 'so merge' or
+'so ship' drops the merged PR's cached status.
+
+Open PRs that GitHub cannot merge into their base without resolving conflicts
+are marked '[conflicts with <base>]'. A branch can be up to date with its
+parent locally and still conflict with trunk once the PRs below it merge.
+
+When several stacks share a base (on the base branch itself, or with --all),
+only stacks you authored are listed by default. A stack is yours if the tip
+commit of any of its branches was authored by you, where "you" is the value of
+'socle.author' (a name or email) or, if unset, your git user.email.
+Use --everyone to list every stack.
+
+--verify additionally checks the current stack's metadata against GitHub
+and the remote, which takes a few requests per branch: that each stored PR
+is the branch's own, that open PRs target the branch's parent, that their
+stack comment lists the stack in its current order, and that each remote
+branch is still at the tip socle last pushed. Every mismatch is listed with
+the command that fixes it, such as 'so submit --stack-only <branch>' or
+'so comment refresh', and log exits with an error.
+
+On a terminal too narrow for a line, the note, then the branch name and then
+the status are shortened with '…' so the status dots stay aligned. Output
+that is not a terminal is never shortened; --no-truncate or --porcelain
+always prints full names.
+
+--porcelain=v1 prints a stable, line-oriented format for scripts and editor
+integrations. Each tracked branch is one line, bottom of the stack first, with
+space-separated fields ("-" when empty):
+
+  <branch> <parent> <base> <needs-restack> <pr-number> <pr-state> <current> <wip>
+
+  needs-restack  1 if the branch must be restacked onto its parent, 0 if not,
+                 ? if it could not be determined
+  pr-state       none, open, draft, merged, closed or error
+  current        1 for the checked-out branch, 0 otherwise
+  wip            1 if the branch is marked work-in-progress ('so wip'), 0 otherwise
+
+The v1 layout will not change. Later v1 output may append fields after
+<wip>, so parsers should ignore any extra fields. Nothing is printed for
+untracked branches.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		mine, _ := cmd.Flags().GetBool("mine")
+		everyone, _ := cmd.Flags().GetBool("everyone")
+		porcelain, _ := cmd.Flags().GetString("porcelain")
+		fetch, _ := cmd.Flags().GetBool("fetch")
+		noTruncate, _ := cmd.Flags().GetBool("no-truncate")
+		verify, _ := cmd.Flags().GetBool("verify")
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+		if porcelain != "" && porcelain != porcelainV1 {
+			return fmt.Errorf("unsupported porcelain version '%s' (supported: %s)", porcelain, porcelainV1)
+		}
+
 		runner := &logCmdRunner{
-			logger: slog.Default(),
-			stdout: cmd.OutOrStdout(),
-			stderr: cmd.ErrOrStderr(),
+			logger:   slog.Default(),
+			stdout:   cmd.OutOrStdout(),
+			stderr:   cmd.ErrOrStderr(),
+			all:      all || mine || everyone,
+			everyone: everyone,
+
+			porcelain: porcelain,
+			fetch:     fetch,
+			verify:    verify,
+			noCache:   noCache,
+		}
+		if !noTruncate {
+			runner.width = ui.TerminalWidth(runner.stdout)
 		}
 		return runner.run(context.Background())
 	},
@@ -25,4 +103,16 @@ Includes status indicating if a branch needs rebasing onto its parent.`,
 
 func init() {
 	AddCommand(logCmd)
+	logCmd.Flags().Bool("all", false, "Show all stacks from the current base, not just the current one")
+	logCmd.Flags().Bool("mine", false, "Show all of your stacks from the current base (implies --all)")
+	logCmd.Flags().Bool("everyone", false, "Show stacks from all authors (implies --all)")
+	logCmd.MarkFlagsMutuallyExclusive("mine", "everyone")
+	logCmd.Flags().Bool("fetch", false, "Fetch the remote (with --prune) before computing statuses")
+	logCmd.Flags().Bool("no-truncate", false, "Never shorten branch names or statuses to fit the terminal width")
+	logCmd.Flags().String("porcelain", "", "Machine-readable output in the given format version (v1)")
+	logCmd.Flags().Lookup("porcelain").NoOptDefVal = porcelainV1
+	logCmd.Flags().Bool("no-cache", false, "Ask GitHub for every PR status instead of reusing recently fetched ones")
+	logCmd.Flags().Bool("verify", false, "Cross-check the stack's metadata with GitHub and the remote (slower)")
+	logCmd.MarkFlagsMutuallyExclusive("verify", "porcelain")
+	logCmd.MarkFlagsMutuallyExclusive("verify", "all")
 }
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/interrupt"
+)
+
+// porcelainV1 is the only porcelain format version. Its line layout is a
+// compatibility promise; see logCmd's help text for the schema.
+const porcelainV1 = "v1"
+
+// stackRecord is the machine-readable status of one tracked branch. It backs
+// both --porcelain=v1 and the JSON served by 'so graph serve', so the two can
+// never disagree about a branch's state.
+type stackRecord struct {
+	Branch        string `json:"branch"`
+	Parent        string `json:"parent"`
+	Base          string `json:"base"`
+	NeedsRestack  string `json:"needsRestack"` // "1", "0" or "?"
+	PRNumber      int    `json:"prNumber,omitempty"`
+	PRState       string `json:"prState"` // none, open, draft, merged, closed or error
+	PRURL         string `json:"prURL,omitempty"`
+	Current       bool   `json:"current"`
+	WIP           bool   `json:"wip"`
+	Note          string `json:"note,omitempty"`          // Not in porcelain v1: notes contain spaces
+	ConflictsWith string `json:"conflictsWith,omitempty"` // Not in porcelain v1: base the PR conflicts with
+}
+
+// collectStackRecords returns the records of every stack the log would show,
+// each bottom of the stack first.
+func (r *logCmdRunner) collectStackRecords(ctx context.Context, stackInfo *git.StackInfo, currentBranch string) [][]stackRecord {
+	var stacks [][]string
+	if r.all || (stackInfo.FullStack == nil && currentBranch == stackInfo.BaseBranch) {
+		available, err := git.GetAvailableStacksFromBase(stackInfo.BaseBranch)
+		if err != nil {
+			r.logger.Debug("No stacks for machine-readable output", "base", stackInfo.BaseBranch, "error", err)
+			return nil
+		}
+		if !r.everyone {
+			available, _ = r.filterOwnStacks(available, currentBranch)
+		}
+		stacks = available
+	} else if stackInfo.FullStack != nil {
+		stacks = [][]string{stackInfo.FullStack}
+	} else {
+		stacks = [][]string{stackInfo.CurrentStack}
+	}
+
+	// Look up the PRs of every stack at once, while the stacks' local state
+	// is computed.
+	var branches []string
+	for _, stack := range stacks {
+		if len(stack) > 1 {
+			branches = append(branches, stack[1:]...)
+		}
+	}
+	prefetch := startPRStatusPrefetch(ctx, branches, func() (gh.ClientInterface, error) {
+		client, err := newStatusGitHubClient(ctx, r.noCache, r.logger)
+		if err != nil {
+			r.logger.Debug("GitHub client unavailable for machine-readable output", "error", err)
+		}
+		return client, err
+	}, r.logger)
+
+	var result [][]stackRecord
+	for _, stack := range stacks {
+		if len(stack) <= 1 {
+			continue
+		}
+		parentOIDs, err := git.GetMultipleBranchCommits(stack[:len(stack)-1])
+		if err != nil {
+			r.logger.Debug("Could not pre-fetch parent OIDs", "error", err)
+			parentOIDs = make(map[string]string)
+		}
+
+		infos := r.collectBranchInfosWith(stack, parentOIDs, prefetch)
+		// collectBranchInfos is ordered top first; records are bottom first.
+		records := make([]stackRecord, 0, len(infos))
+		for i := len(infos) - 1; i >= 0; i-- {
+			records = append(records, newStackRecord(infos[i], stack[0], currentBranch))
+		}
+		result = append(result, records)
+	}
+	return result
+}
+
+func newStackRecord(info branchLogInfo, base, currentBranch string) stackRecord {
+	record := stackRecord{
+		Branch:        info.branchName,
+		Parent:        info.parentName,
+		Base:          base,
+		NeedsRestack:  "0",
+		PRState:       porcelainPRState(info.prText),
+		PRURL:         info.prURL,
+		Current:       info.branchName == currentBranch,
+		WIP:           info.wip,
+		Note:          info.note,
+		ConflictsWith: info.conflictsWith,
+	}
+	switch info.rebaseStatus.status {
+	case RebaseStatusNeedsRestack, RebaseStatusMerged:
+		record.NeedsRestack = "1"
+	case RebaseStatusError:
+		record.NeedsRestack = "?"
+	}
+	if n, err := git.GetStoredPRNumber(info.branchName); err == nil && n > 0 {
+		record.PRNumber = n
+	}
+	return record
+}
+
+// runPorcelain prints the stack(s) in the stable, line-oriented porcelain
+// format: one line per branch, bottom of each stack first, fields separated by
+// a single space, "-" for an empty value. Nothing else is written to stdout.
+func (r *logCmdRunner) runPorcelain(ctx context.Context, stackInfo *git.StackInfo, currentBranch string) error {
+	stacks := r.collectStackRecords(ctx, stackInfo, currentBranch)
+	// Scripts must not mistake the records of skipped checks for real ones.
+	if err := interrupt.Check(ctx, "run 'so log' again"); err != nil {
+		return err
+	}
+	for _, records := range stacks {
+		for _, record := range records {
+			_, _ = fmt.Fprintln(r.stdout, porcelainLine(record))
+		}
+	}
+	return nil
+}
+
+// porcelainLine formats one v1 record:
+//
+//	<branch> <parent> <base> <needs-restack> <pr-number> <pr-state> <current> <wip>
+func porcelainLine(record stackRecord) string {
+	prNumber := "-"
+	if record.PRNumber > 0 {
+		prNumber = fmt.Sprintf("%d", record.PRNumber)
+	}
+
+	return strings.Join([]string{
+		record.Branch,
+		record.Parent,
+		record.Base,
+		record.NeedsRestack,
+		prNumber,
+		record.PRState,
+		porcelainFlag(record.Current),
+		porcelainFlag(record.WIP),
+	}, " ")
+}
+
+func porcelainFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func porcelainPRState(prText string) string {
+	switch prText {
+	case gh.PRStatusOpen:
+		return "open"
+	case gh.PRStatusDraft:
+		return "draft"
+	case gh.PRStatusMerged:
+		return "merged"
+	case gh.PRStatusClosed:
+		return "closed"
+	case gh.PRStatusAPIError:
+		return "error"
+	default:
+		return "none"
+	}
+}
+
+// newOriginGitHubClient builds a client for the configured remote (socle.remote).
+func newOriginGitHubClient(ctx context.Context) (gh.ClientInterface, error) {
+	owner, repoName, err := originOwnerAndRepo()
+	if err != nil {
+		return nil, err
+	}
+	client, err := gh.CreateClient(ctx, owner, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub client init failed: %w", err)
+	}
+	return client, nil
+}
+
+// newStatusGitHubClient is newOriginGitHubClient for commands that show PR
+// statuses, which reuse them for socle.prStatusCacheTTL seconds unless
+// noCache is set.
+func newStatusGitHubClient(ctx context.Context, noCache bool, logger *slog.Logger) (gh.ClientInterface, error) {
+	client, err := newOriginGitHubClient(ctx)
+	if err != nil || noCache {
+		return client, err
+	}
+	owner, repoName, _ := originOwnerAndRepo() // Parsed fine just now
+	return gh.WithStatusCache(client, owner+"/"+repoName, prStatusCacheTTL(logger), false), nil
+}
+
+// originOwnerAndRepo parses the GitHub owner and repository of the
+// configured remote's URL.
+func originOwnerAndRepo() (owner, repoName string, err error) {
+	remoteName := git.GetRemoteName()
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot get remote URL '%s': %w", remoteName, err)
+	}
+	owner, repoName, err = git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot parse owner/repo from '%s': %w", remoteURL, err)
+	}
+	return owner, repoName, nil
+}
+
+// prStatusCacheTTL reads socle.prStatusCacheTTL, in seconds. Zero turns the
+// PR status cache off.
+func prStatusCacheTTL(logger *slog.Logger) time.Duration {
+	val, err := git.GetSocleConfig("socle.prStatusCacheTTL")
+	if err != nil {
+		return gh.DefaultStatusCacheTTL
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(val), 10, 32)
+	if err != nil {
+		logger.Warn("Ignoring invalid socle.prStatusCacheTTL", "value", val)
+		return gh.DefaultStatusCacheTTL
+	}
+	return time.Duration(n) * time.Second
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/workpool"
+)
+
+// prStatusPrefetch looks up PR statuses in the background. The lookups only
+// need the stored PR numbers, so 'so log' starts them, client creation
+// included, before computing merge bases and rebase statuses, and the
+// network round trips overlap with the local git work instead of following
+// it.
+type prStatusPrefetch struct {
+	ctx  context.Context // Lookups and the local work beside them stop with it
+	done chan struct{}   // Closed once every lookup finished
+
+	// Set before done is closed
+	client    gh.ClientInterface
+	clientErr error
+	results   map[string]prStatusResult // By branch
+}
+
+// prStatusResult is what collectBranchInfos shows about a branch's PR.
+type prStatusResult struct {
+	number int
+	status string
+	url    string
+}
+
+// startPRStatusPrefetch creates a GitHub client with newClient and asks it
+// for the status of the stored PR of each of branches, in the background and
+// workpool.DefaultWorkers at a time. The PR numbers are read up front, in one
+// config scan. Lookups not started before ctx is cancelled are skipped.
+func startPRStatusPrefetch(ctx context.Context, branches []string, newClient func() (gh.ClientInterface, error), logger *slog.Logger) *prStatusPrefetch {
+	p := &prStatusPrefetch{ctx: ctx, done: make(chan struct{}), results: make(map[string]prStatusResult, len(branches))}
+	numbers, err := git.GetAllStoredPRNumbers()
+	if err != nil {
+		logger.Debug("Could not read stored PR numbers", "error", err)
+	}
+
+	go func() {
+		defer close(p.done)
+		p.client, p.clientErr = newClient()
+
+		var lookups []string // Branches with a PR to ask about
+		for _, branch := range branches {
+			number := numbers[branch]
+			if number == 0 || p.client == nil {
+				result := prStatusResult{number: number, status: gh.PRStatusAPIError}
+				if number == 0 {
+					result.status = gh.PRStatusNotFound
+				}
+				p.results[branch] = result
+				continue
+			}
+			lookups = append(lookups, branch)
+		}
+
+		statuses := make([]prStatusResult, len(lookups))
+		for i, branch := range lookups {
+			statuses[i] = prStatusResult{number: numbers[branch], status: gh.PRStatusAPIError} // Unless looked up
+		}
+		if err := workpool.Run(ctx, len(lookups), workpool.DefaultWorkers, func(i int) {
+			status, url, err := p.client.GetPullRequestStatus(statuses[i].number)
+			if err == nil {
+				statuses[i].status, statuses[i].url = status, url
+			}
+		}); err != nil {
+			logger.Debug("PR status lookups cancelled", "error", err)
+		}
+		for i, branch := range lookups {
+			p.results[branch] = statuses[i]
+		}
+	}()
+	return p
+}
+
+// wait blocks until the lookups finished and returns the client they used,
+// or why none could be created.
+func (p *prStatusPrefetch) wait() (gh.ClientInterface, error) {
+	<-p.done
+	return p.client, p.clientErr
+}
+
+// result returns the PR status of branch. Call wait first.
+func (p *prStatusPrefetch) result(branch string) prStatusResult {
+	if result, ok := p.results[branch]; ok {
+		return result
+	}
+	return prStatusResult{status: gh.PRStatusNotFound}
+}
@@ -2,15 +2,20 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/benekuehn/socle/cli/so/internal/gh"
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/interrupt"
+	"github.com/benekuehn/socle/cli/so/internal/profile"
 	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/benekuehn/socle/cli/so/internal/workpool"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/list"
 )
@@ -20,6 +25,7 @@ type RebaseStatus string
 
 const (
 	RebaseStatusNeedsRestack RebaseStatus = "Needs Restack"
+	RebaseStatusMerged       RebaseStatus = "Merged Into Parent" // Needs a restack that leaves it empty
 	RebaseStatusUpToDate     RebaseStatus = "Up-to-date"
 	RebaseStatusError        RebaseStatus = "Error"
 )
@@ -30,7 +36,11 @@ type branchLogInfo struct {
 	branchNameStyle func(string) string
 	prText          string
 	prURL           string
+	prNumber        int
 	rebaseStatus    statusResult
+	wip             bool
+	note            string // Set with 'so annotate'
+	conflictsWith   string // Base the open PR cannot merge into cleanly, if any
 }
 
 type statusResult struct {
@@ -42,6 +52,16 @@ type logCmdRunner struct {
 	logger *slog.Logger
 	stdout io.Writer
 	stderr io.Writer
+
+	all       bool   // Show every stack from the base, even when not on it
+	everyone  bool   // Don't filter multi-stack views down to the user's own stacks
+	porcelain string // Porcelain format version; empty for human output
+	fetch     bool   // Fetch the remote before computing statuses
+	verify    bool   // Cross-check the metadata with GitHub and the remote afterwards
+	noCache   bool   // Ask GitHub for every PR status instead of reusing cached ones
+	width     int    // Terminal width to fit lines into; 0 to never truncate
+
+	fetched bool // The remote was fetched by this run
 }
 
 var (
@@ -52,27 +72,29 @@ var (
 	prDotSubmittedStyle   = ui.Colors.InfoStyle
 	prDotClosedStyle      = ui.Colors.FailureStyle
 	mutedStyle            = ui.Colors.MutedStyle
+	wipBadgeStyle         = ui.Colors.WarningStyle
+	conflictBadgeStyle    = ui.Colors.FailureStyle
+	noteStyle             = ui.Colors.FaintStyle
 )
 
-var branchInfoMap = make(map[string]branchLogInfo)
-
-func branchEnumerator(items list.Items, i int) string {
-	item := items.At(i)
-	// Strip ANSI escape codes from the branch name
-	branchName := strings.SplitN(item.Value(), " ", 2)[0]
-	branchName = strings.TrimSuffix(branchName, "\x1b[0m")
-	branchName = strings.TrimPrefix(branchName, "\x1b[1m")
-
-	// Base or without info need space for alignment
-	branchInfo, exists := branchInfoMap[branchName]
-	if !exists || strings.Contains(item.Value(), "(base)") {
-		return "   " // Three spaces for branches without info
+// stackEnumerator returns a list enumerator that renders the status dots for
+// each item from entries, which is indexed by list position. A nil entry (the
+// base branch) renders as blank padding. No rendered strings are parsed.
+func stackEnumerator(entries []*branchLogInfo) list.Enumerator {
+	return func(items list.Items, i int) string {
+		if i < 0 || i >= len(entries) || entries[i] == nil {
+			return "   " // Three spaces for base/branches without info
+		}
+		return statusDots(entries[i])
 	}
+}
 
+// statusDots renders the rebase and PR status dots of a branch.
+func statusDots(info *branchLogInfo) string {
 	// First dot: Rebase status
 	var firstDot string
-	switch branchInfo.rebaseStatus.status {
-	case RebaseStatusNeedsRestack:
+	switch info.rebaseStatus.status {
+	case RebaseStatusNeedsRestack, RebaseStatusMerged:
 		firstDot = rebaseDotWarningStyle.Render("●")
 	default:
 		firstDot = rebaseDotStyle.Render("●")
@@ -80,12 +102,12 @@ func branchEnumerator(items list.Items, i int) string {
 
 	// Second dot: PR status
 	var secondDot string
-	switch {
-	case strings.Contains(branchInfo.prText, "Merged"):
+	switch info.prText {
+	case gh.PRStatusMerged:
 		secondDot = prDotMergedStyle.Render("●")
-	case strings.Contains(branchInfo.prText, "Open"), strings.Contains(branchInfo.prText, "Draft"):
+	case gh.PRStatusOpen, gh.PRStatusDraft:
 		secondDot = prDotSubmittedStyle.Render("●")
-	case strings.Contains(branchInfo.prText, "Closed"):
+	case gh.PRStatusClosed:
 		secondDot = prDotClosedStyle.Render("●")
 	default:
 		secondDot = prDotDefaultStyle.Render("○")
@@ -103,7 +125,10 @@ func (r *logCmdRunner) run(ctx context.Context) error {
 
 	// 3. Handle specific error cases for log command
 	if err != nil {
-		if strings.Contains(err.Error(), "not tracked by socle") {
+		if errors.Is(err, git.ErrNotTracked) {
+			if r.porcelain != "" {
+				return nil // Porcelain output for an untracked branch is empty
+			}
 			// For the log command, we should print the message ourselves to match test expectations
 			_, _ = fmt.Fprintf(r.stdout, "Branch '%s' is not currently tracked by socle.\n", currentBranch)
 			_, _ = fmt.Fprintln(r.stdout, "Use 'so track' to associate it with a parent branch and start a stack.")
@@ -118,9 +143,15 @@ func (r *logCmdRunner) run(ctx context.Context) error {
 		return nil
 	}
 
+	r.maybeFetch()
+
+	if r.porcelain != "" {
+		return r.runPorcelain(ctx, stackInfo, currentBranch)
+	}
+
 	// If FullStack is nil, it means there are multiple stacks from the base
 	// But only show multiple stacks if we're actually ON the base branch
-	if stackInfo.FullStack == nil && currentBranch == stackInfo.BaseBranch {
+	if r.all || (stackInfo.FullStack == nil && currentBranch == stackInfo.BaseBranch) {
 		return r.displayMultipleStacks(ctx, stackInfo.BaseBranch, currentBranch)
 	}
 
@@ -144,6 +175,12 @@ func (r *logCmdRunner) run(ctx context.Context) error {
 		numBranchesInStack = 0
 	}
 
+	// PR statuses need only the stored PR numbers: look them up while the
+	// local state below is computed.
+	prefetch := startPRStatusPrefetch(ctx, stackToDisplay[1:], func() (gh.ClientInterface, error) {
+		return newStatusGitHubClient(ctx, r.noCache, r.logger)
+	}, r.logger)
+
 	// Pre-fetch all parent OIDs for branches in the stack to reduce git calls
 	parentOIDs := make(map[string]string)
 	if numBranchesInStack > 0 {
@@ -170,191 +207,276 @@ func (r *logCmdRunner) run(ctx context.Context) error {
 		}
 	}
 
-	var ghClient gh.ClientInterface
-	var ghClientInitError error
-	remoteName := "origin"
-	remoteURL, errURL := git.GetRemoteURL(remoteName)
-	if errURL != nil {
-		ghClientInitError = fmt.Errorf("cannot get remote URL '%s': %w", remoteName, errURL)
-	} else {
-		owner, repoName, errParse := git.ParseOwnerAndRepo(remoteURL)
-		if errParse != nil {
-			ghClientInitError = fmt.Errorf("cannot parse owner/repo from '%s': %w", remoteURL, errParse)
-		} else {
-			client, errCli := gh.CreateClient(ctx, owner, repoName)
-			if errCli != nil {
-				ghClientInitError = fmt.Errorf("GitHub client init failed: %w", errCli)
-			} else {
-				ghClient = client
-			}
-		}
+	r.printTrunkHeader(stackInfo.BaseBranch)
+	branchInfos := r.collectBranchInfosWith(stackToDisplay, parentOIDs, prefetch)
+	if err := interrupt.Check(ctx, "run 'so log' again"); err != nil {
+		return err
 	}
-
+	ghClient, ghClientInitError := prefetch.wait()
 	if ghClientInitError != nil {
 		_, _ = fmt.Fprintf(r.stderr, ui.Colors.WarningStyle.Render("Warning: GitHub client initialization failed: %v\nPR statuses may not be available.\n"), ghClientInitError)
 	}
+	_, _ = fmt.Fprintln(r.stdout, renderStackList(branchInfos, stackInfo.BaseBranch, 1, r.width))
 
-	// Process branches in parallel
-	branchInfos := make([]branchLogInfo, 0, numBranchesInStack)
-	var wg sync.WaitGroup
-	results := make(map[string]branchLogInfo)
-	var mu sync.Mutex
-
-	// Process each branch in parallel
-	for i := len(stackToDisplay) - 1; i >= 1; i-- {
-		branchName := stackToDisplay[i]
-		parentName := stackToDisplay[i-1]
-		parentOID := parentOIDs[parentName]
-
-		wg.Add(1)
-		go func(branch, parent string, parentOID string) {
-			defer wg.Done()
-
-			// Get PR status
-			prNumber, err := git.GetStoredPRNumber(branch)
-			var prStatus string
-			var prURL string
-			if err != nil || prNumber == 0 {
-				prStatus = gh.PRStatusNotFound
-			} else if ghClient != nil {
-				prStatus, prURL, err = ghClient.GetPullRequestStatus(prNumber)
-				if err != nil {
-					prStatus = gh.PRStatusAPIError
-				}
-			} else {
-				prStatus = gh.PRStatusAPIError
-			}
+	if r.verify {
+		return r.verifyStack(stackToDisplay, ghClient)
+	}
+	return nil
+}
 
-			// Get rebase status
-			rebaseStatusResult := getRebaseStatus(parent, branch, parentOID, r.stderr)
-
-			// Create branch info
-			info := branchLogInfo{
-				branchName:      branch,
-				parentName:      parent,
-				branchNameStyle: func(s string) string { return lipgloss.NewStyle().Bold(true).Render(s) },
-				prText:          prStatus,
-				prURL:           prURL,
-				rebaseStatus:    rebaseStatusResult,
-			}
+// collectBranchInfos gathers PR and rebase status for every non-base branch of
+// stack in parallel. The result is ordered top of stack first.
+func (r *logCmdRunner) collectBranchInfos(ctx context.Context, stack []string, parentOIDs map[string]string, ghClient gh.ClientInterface) []branchLogInfo {
+	prefetch := startPRStatusPrefetch(ctx, stack[1:], func() (gh.ClientInterface, error) { return ghClient, nil }, r.logger)
+	return r.collectBranchInfosWith(stack, parentOIDs, prefetch)
+}
+
+// collectBranchInfosWith is collectBranchInfos with PR statuses that are
+// already being looked up. The local state of every branch is computed,
+// workpool.DefaultWorkers branches at a time, while the lookups run; the PR
+// statuses are filled in once both are done. Once the prefetch's context is
+// cancelled, branches not yet looked at keep just their names.
+func (r *logCmdRunner) collectBranchInfosWith(stack []string, parentOIDs map[string]string, prefetch *prStatusPrefetch) []branchLogInfo {
+	infos := make([]branchLogInfo, len(stack)) // Indexed like stack; the base stays empty
+	for i := 1; i < len(stack); i++ {
+		infos[i] = branchLogInfo{
+			branchName:      stack[i],
+			parentName:      stack[i-1],
+			branchNameStyle: func(s string) string { return lipgloss.NewStyle().Bold(true).Render(s) },
+		}
+	}
+
+	err := workpool.Run(prefetch.ctx, len(stack)-1, workpool.DefaultWorkers, func(i int) {
+		info := &infos[i+1]
+		branch, parent := info.branchName, info.parentName
 
-			mu.Lock()
-			results[branch] = info
-			mu.Unlock()
-		}(branchName, parentName, parentOID)
+		// Get rebase status
+		info.rebaseStatus = getRebaseStatus(parent, branch, parentOIDs[parent], r.stderr)
+
+		var errWIP, errNote error
+		if info.wip, errWIP = git.IsBranchWIP(branch); errWIP != nil {
+			r.logger.Debug("Failed to read WIP mark", "branch", branch, "error", errWIP)
+		}
+		if info.note, errNote = git.GetBranchNote(branch); errNote != nil {
+			r.logger.Debug("Failed to read branch note", "branch", branch, "error", errNote)
+		}
+	})
+	if err != nil {
+		r.logger.Debug("Branch status checks cancelled", "error", err)
 	}
 
-	// Wait for all checks to complete
-	wg.Wait()
+	results := make(map[string]branchLogInfo, len(stack)-1)
+	for _, info := range infos[1:] {
+		results[info.branchName] = info
+	}
+
+	ghClient, _ := prefetch.wait()
+	for branch, info := range results {
+		pr := prefetch.result(branch)
+		info.prNumber, info.prText, info.prURL = pr.number, pr.status, pr.url
+		results[branch] = info
+	}
+	r.markConflicts(results, ghClient)
 
 	// Process branches in order to maintain the original order
-	for i := len(stackToDisplay) - 1; i >= 1; i-- {
-		branchName := stackToDisplay[i]
-		branchInfos = append(branchInfos, results[branchName])
+	branchInfos := make([]branchLogInfo, 0, len(stack)-1)
+	for i := len(stack) - 1; i >= 1; i-- {
+		branchInfos = append(branchInfos, results[stack[i]])
+	}
+	return branchInfos
+}
+
+// markConflicts asks GitHub in one batch which open PRs conflict with their
+// base. A branch can be up to date with its parent locally and still conflict
+// with trunk once the PRs below it merge.
+func (r *logCmdRunner) markConflicts(infos map[string]branchLogInfo, ghClient gh.ClientInterface) {
+	if ghClient == nil {
+		return
+	}
+	var numbers []int
+	for _, info := range infos {
+		if info.prNumber > 0 && (info.prText == gh.PRStatusOpen || info.prText == gh.PRStatusDraft) {
+			numbers = append(numbers, info.prNumber)
+		}
+	}
+	if len(numbers) == 0 {
+		return
+	}
+	states, err := ghClient.GetMergeableStates(numbers)
+	if err != nil {
+		r.logger.Debug("Could not read mergeable states", "error", err)
+		return
+	}
+	for branch, info := range infos {
+		state, ok := states[info.prNumber]
+		if !ok || !state.HasConflicts() {
+			continue
+		}
+		info.conflictsWith = state.BaseRefName
+		if info.conflictsWith == "" {
+			info.conflictsWith = info.parentName
+		}
+		infos[branch] = info
+	}
+}
+
+// prStatusLabel is the human-readable PR status shown in the log.
+func prStatusLabel(prText string) string {
+	switch prText {
+	case gh.PRStatusDraft:
+		return "pr drafted"
+	case gh.PRStatusMerged:
+		return "pr merged"
+	case gh.PRStatusOpen:
+		return "pr open"
+	case gh.PRStatusClosed:
+		return "pr closed"
+	case gh.PRStatusAPIError:
+		return "pr check failed"
+	default:
+		return "no PR submitted"
+	}
+}
+
+// branchStatusText renders "(<rebase status>, <pr status>)" for a branch,
+// hyperlinking the PR status when a URL is known.
+func branchStatusText(info branchLogInfo) string {
+	var statusText string
+	switch info.rebaseStatus.status {
+	case RebaseStatusNeedsRestack:
+		statusText = "(needs restack"
+	case RebaseStatusMerged:
+		statusText = "(already merged into parent"
+	case RebaseStatusError:
+		statusText = "(rebase check failed"
+	default:
+		statusText = "(up-to-date"
 	}
 
-	// Create a new list
+	prStatus := prStatusLabel(info.prText)
+	if info.prURL != "" {
+		// OSC 8 escape sequence for hyperlinks
+		prStatus = fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", info.prURL, prStatus)
+	}
+	return statusText + ", " + prStatus + ")"
+}
+
+// stackListIndent is how many columns renderStackList puts before and after
+// an item's text: the left padding, the two dots and the margins.
+const stackListIndent = 2 + 3 + 1 + 1
+
+// minTruncatedName is the fewest columns a branch name is cut down to, so
+// it stays recognizable even when the status has to be shortened too.
+const minTruncatedName = 12
+
+// renderStackList renders branchInfos (top of stack first) followed by the
+// base branch as a lipgloss list. The enumerator reads structured entries kept
+// alongside the list items, so styling of the item text never matters. With a
+// width, lines that would wrap are shortened with ellipses so the dots stay
+// aligned; 0 keeps every line whole.
+func renderStackList(branchInfos []branchLogInfo, baseBranch string, paddingTop, width int) string {
+	defer profile.Start(profile.CategoryRender, "stack list")()
 	l := list.New()
+	entries := make([]*branchLogInfo, 0, len(branchInfos)+1)
 
-	// Clear the global map
-	branchInfoMap = make(map[string]branchLogInfo)
-
-	for _, info := range branchInfos {
-		var statusText string
-		switch info.rebaseStatus.status {
-		case RebaseStatusNeedsRestack:
-			statusText = "(needs restack"
-		case RebaseStatusError:
-			statusText = "(rebase check failed"
-		default:
-			statusText = "(up-to-date"
+	for i := range branchInfos {
+		info := &branchInfos[i]
+		var badges []string
+		if info.wip {
+			badges = append(badges, wipBadgeStyle.Render("[WIP]"))
 		}
-
-		// Add PR status with hyperlink if URL exists
-		if info.prURL != "" {
-			// OSC 8 escape sequence for hyperlinks
-			prStatus := ""
-			switch info.prText {
-			case gh.PRStatusDraft:
-				prStatus = "pr drafted"
-			case gh.PRStatusMerged:
-				prStatus = "pr merged"
-			case gh.PRStatusOpen:
-				prStatus = "pr open"
-			case gh.PRStatusClosed:
-				prStatus = "pr closed"
-			case gh.PRStatusAPIError:
-				prStatus = "pr check failed"
-			case gh.PRStatusNotFound:
-				prStatus = "no PR submitted"
-			default:
-				prStatus = "no PR submitted"
-			}
-			prLink := fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", info.prURL, prStatus)
-			statusText += ", " + prLink
-		} else {
-			// No PR URL, just add the status text
-			switch info.prText {
-			case gh.PRStatusDraft:
-				statusText += ", pr drafted"
-			case gh.PRStatusMerged:
-				statusText += ", pr merged"
-			case gh.PRStatusOpen:
-				statusText += ", pr open"
-			case gh.PRStatusClosed:
-				statusText += ", pr closed"
-			case gh.PRStatusAPIError:
-				statusText += ", pr check failed"
-			case gh.PRStatusNotFound:
-				statusText += ", no PR submitted"
-			default:
-				statusText += ", no PR submitted"
-			}
+		if info.conflictsWith != "" {
+			badges = append(badges, conflictBadgeStyle.Render("[conflicts with "+info.conflictsWith+"]"))
+		}
+		name, status, note := info.branchName, branchStatusText(*info), info.note
+		if width > 0 {
+			name, status, note = fitStackItem(name, badges, status, note, width-stackListIndent)
 		}
-		statusText += ")"
-
-		branchInfoMap[info.branchName] = info
 
-		boldBranchName := lipgloss.NewStyle().Bold(true).Render(info.branchName)
-		mutedStatus := mutedStyle.Render(statusText)
-		l.Item(boldBranchName + " " + mutedStatus)
+		item := lipgloss.NewStyle().Bold(true).Render(name)
+		for _, badge := range badges {
+			item += " " + badge
+		}
+		item += " " + mutedStyle.Render(status)
+		if note != "" {
+			item += " " + noteStyle.Render(note)
+		}
+		l.Item(item)
+		entries = append(entries, info)
 	}
 
-	mutedBase := mutedStyle.Render(stackInfo.BaseBranch + " (base)")
-	l.Item(mutedBase)
+	base := baseBranch + " (base)"
+	if width > 0 {
+		base = ui.Truncate(base, width-stackListIndent)
+	}
+	l.Item(mutedStyle.Render(base))
+	entries = append(entries, nil)
 
-	l = l.Enumerator(branchEnumerator).
+	l = l.Enumerator(stackEnumerator(entries)).
 		EnumeratorStyle(lipgloss.NewStyle().MarginRight(1).Bold(true)).
 		ItemStyle(lipgloss.NewStyle().MarginRight(1))
 
-	paddedList := lipgloss.NewStyle().
+	return lipgloss.NewStyle().
 		PaddingLeft(2).
-		PaddingTop(1).
+		PaddingTop(paddingTop).
 		PaddingBottom(1).
 		Render(l.String())
-	_, _ = fmt.Fprintln(r.stdout, paddedList)
-
-	return nil
 }
 
-// It calculates needsRestack by comparing parentOID with the merge-base of parentName and branchName.
-func getRebaseStatus(parentName, branchName string, parentOID string, errW io.Writer) statusResult {
-	// parentOID is pre-fetched. We still need the merge-base between parentName and branchName.
-	mergeBase, errMergeBase := git.GetMergeBase(parentName, branchName)
-	if errMergeBase != nil {
-		// Log the warning about failing to get merge-base.
-		_, _ = fmt.Fprintf(errW, ui.Colors.WarningStyle.Render("  Warning: Could not get merge base between '%s' and '%s' to check rebase status: %v\n"), parentName, branchName, errMergeBase)
-		return statusResult{RebaseStatusError, func(s string) string { return ui.Colors.FailureStyle.Render(s) }}
+// fitStackItem shortens the parts of a stack list line to fit in budget
+// columns: first the note, then the branch name (down to minTruncatedName),
+// then the status. Badges are short and always kept.
+func fitStackItem(name string, badges []string, status, note string, budget int) (string, string, string) {
+	fixed := ui.Width(status) + 1
+	for _, badge := range badges {
+		fixed += ui.Width(badge) + 1
+	}
+	over := func() int {
+		total := ui.Width(name) + fixed
+		if note != "" {
+			total += ui.Width(note) + 1
+		}
+		return total - budget
 	}
 
+	if excess := over(); excess > 0 && note != "" {
+		if keep := ui.Width(note) - excess; keep >= 4 {
+			note = ui.Truncate(note, keep)
+		} else {
+			note = ""
+		}
+	}
+	if excess := over(); excess > 0 {
+		name = ui.Truncate(name, max(ui.Width(name)-excess, min(ui.Width(name), minTruncatedName)))
+	}
+	if excess := over(); excess > 0 {
+		status = ui.Truncate(status, ui.Width(status)-excess)
+	}
+	return name, status, note
+}
+
+// getRebaseStatus reports whether branchName still sits on parentOID. It uses an
+// ancestry check (commit-graph friendly) so partial clones never fetch blobs here.
+// A branch off its parent is checked for commits the parent already has, which
+// compares patches and is therefore skipped in sparse-safe mode.
+func getRebaseStatus(parentName, branchName string, parentOID string, errW io.Writer) statusResult {
 	if parentOID == "" { // Can happen if parent OID fetch failed
 		_, _ = fmt.Fprintf(errW, ui.Colors.WarningStyle.Render("  Warning: Provided parent OID for '%s' is empty. Cannot determine rebase status for '%s'.\n"), parentName, branchName)
 		return statusResult{RebaseStatusError, func(s string) string { return ui.Colors.FailureStyle.Render(s) }}
 	}
 
-	needsRestack := (mergeBase != parentOID)
+	basedOnParent, errAncestor := git.IsAncestor(parentOID, branchName)
+	if errAncestor != nil {
+		_, _ = fmt.Fprintf(errW, ui.Colors.WarningStyle.Render("  Warning: Could not check ancestry between '%s' and '%s' to check rebase status: %v\n"), parentName, branchName, errAncestor)
+		return statusResult{RebaseStatusError, func(s string) string { return ui.Colors.FailureStyle.Render(s) }}
+	}
 
-	if needsRestack {
+	if !basedOnParent {
+		if !git.IsSparseSafe() {
+			if merged, err := git.MergedIntoParent(parentOID, branchName); err == nil && merged {
+				return statusResult{RebaseStatusMerged, func(s string) string { return ui.Colors.WarningStyle.Render(s) }}
+			}
+		}
 		return statusResult{RebaseStatusNeedsRestack, func(s string) string { return ui.Colors.WarningStyle.Render(s) }}
 	} else {
 		return statusResult{RebaseStatusUpToDate, func(s string) string { return ui.Colors.SuccessStyle.Render(s) }}
@@ -373,6 +495,29 @@ func (r *logCmdRunner) displayMultipleStacks(ctx context.Context, baseBranch, cu
 		return nil
 	}
 
+	hidden := 0
+	if !r.everyone {
+		availableStacks, hidden = r.filterOwnStacks(availableStacks, currentBranch)
+		if len(availableStacks) == 0 {
+			_, _ = fmt.Fprintf(r.stdout, "No stacks of yours found starting from base branch '%s'.\n", baseBranch)
+			r.printHiddenStacksNote(hidden)
+			return nil
+		}
+	}
+
+	// One round of PR lookups for all stacks, running while they are printed
+	var branches []string
+	for _, stack := range availableStacks {
+		if len(stack) > 1 {
+			branches = append(branches, stack[1:]...)
+		}
+	}
+	prefetch := startPRStatusPrefetch(ctx, branches, func() (gh.ClientInterface, error) {
+		return newStatusGitHubClient(ctx, r.noCache, r.logger) // Failures are silent here
+	}, r.logger)
+
+	r.printTrunkHeader(baseBranch)
+
 	// Display header with count
 	stackCount := len(availableStacks)
 	if stackCount == 1 {
@@ -383,239 +528,199 @@ func (r *logCmdRunner) displayMultipleStacks(ctx context.Context, baseBranch, cu
 
 	// Display each stack with detailed info
 	for _, stack := range availableStacks {
-		err := r.displaySingleStackDetailed(ctx, stack, currentBranch)
+		if err := interrupt.Check(ctx, "run 'so log' again"); err != nil {
+			return err
+		}
+		err := r.displaySingleStackDetailed(stack, prefetch)
 		if err != nil {
 			return err
 		}
 	}
 
+	r.printHiddenStacksNote(hidden)
 	return nil
 }
 
-func (r *logCmdRunner) displaySingleStackDetailed(ctx context.Context, stack []string, currentBranch string) error {
-	if len(stack) <= 1 {
-		// Stack with only base branch
-		mutedBase := mutedStyle.Render(stack[0] + " (base, no branches)")
-		_, _ = fmt.Fprintf(r.stdout, "     %s\n\n", mutedBase)
-		return nil
+// filterOwnStacks keeps the stacks that contain a branch whose tip commit was
+// authored by the configured identity, plus the stack holding currentBranch.
+// It returns the kept stacks and how many were hidden. If no identity is
+// configured or authors can't be read, nothing is filtered.
+func (r *logCmdRunner) filterOwnStacks(stacks [][]string, currentBranch string) ([][]string, int) {
+	identity := git.GetAuthorIdentity()
+	if identity == "" {
+		r.logger.Debug("No author identity configured, showing all stacks")
+		return stacks, 0
 	}
 
-	// Get GitHub client for PR status (same setup as main log)
-	var ghClient gh.ClientInterface
-	remoteName := "origin"
-	remoteURL, errURL := git.GetRemoteURL(remoteName)
-	if errURL != nil {
-		// No GitHub client available
-	} else {
-		owner, repoName, errParse := git.ParseOwnerAndRepo(remoteURL)
-		if errParse != nil {
-			// No GitHub client available
-		} else {
-			client, errCli := gh.CreateClient(ctx, owner, repoName)
-			if errCli == nil {
-				ghClient = client
-			}
+	var branches []string
+	for _, stack := range stacks {
+		if len(stack) > 1 {
+			branches = append(branches, stack[1:]...)
 		}
 	}
+	authors, err := git.GetBranchTipAuthors(branches)
+	if err != nil {
+		r.logger.Debug("Could not read branch authors, showing all stacks", "error", err)
+		return stacks, 0
+	}
 
-	// Pre-fetch parent OIDs for rebase status checks
-	parentOIDs := make(map[string]string)
-	if len(stack) > 1 {
-		parentNamesToFetch := make(map[string]struct{})
-		for i := len(stack) - 1; i >= 1; i-- {
-			if i > 0 {
-				parentNamesToFetch[stack[i-1]] = struct{}{}
+	var kept [][]string
+	for _, stack := range stacks {
+		own := false
+		for _, branch := range stack[1:] {
+			if branch == currentBranch || authors[branch].Matches(identity) {
+				own = true
+				break
 			}
 		}
-		uniqueParentNames := make([]string, 0, len(parentNamesToFetch))
-		for name := range parentNamesToFetch {
-			uniqueParentNames = append(uniqueParentNames, name)
-		}
-
-		if len(uniqueParentNames) > 0 {
-			var errFetchParentOIDs error
-			parentOIDs, errFetchParentOIDs = git.GetMultipleBranchCommits(uniqueParentNames)
-			if errFetchParentOIDs != nil && parentOIDs == nil {
-				parentOIDs = make(map[string]string)
-			}
+		if own {
+			kept = append(kept, stack)
 		}
 	}
+	r.logger.Debug("Filtered stacks by author", "identity", identity, "kept", len(kept), "total", len(stacks))
+	return kept, len(stacks) - len(kept)
+}
 
-	// Process branches in parallel to get PR and rebase status
-	branchInfos := make([]branchLogInfo, 0, len(stack)-1)
-	var wg sync.WaitGroup
-	results := make(map[string]branchLogInfo)
-	var mu sync.Mutex
-
-	for i := len(stack) - 1; i >= 1; i-- {
-		branchName := stack[i]
-		parentName := stack[i-1]
-		parentOID := parentOIDs[parentName]
-
-		wg.Add(1)
-		go func(branch, parent string, parentOID string) {
-			defer wg.Done()
-
-			// Get PR status
-			prNumber, err := git.GetStoredPRNumber(branch)
-			var prStatus string
-			var prURL string
-			if err != nil || prNumber == 0 {
-				prStatus = gh.PRStatusNotFound
-			} else if ghClient != nil {
-				prStatus, prURL, err = ghClient.GetPullRequestStatus(prNumber)
-				if err != nil {
-					prStatus = gh.PRStatusAPIError
-				}
-			} else {
-				prStatus = gh.PRStatusAPIError
-			}
-
-			// Get rebase status
-			rebaseStatusResult := getRebaseStatus(parent, branch, parentOID, r.stderr)
-
-			// Create branch info
-			info := branchLogInfo{
-				branchName:      branch,
-				parentName:      parent,
-				branchNameStyle: func(s string) string { return lipgloss.NewStyle().Bold(true).Render(s) },
-				prText:          prStatus,
-				prURL:           prURL,
-				rebaseStatus:    rebaseStatusResult,
-			}
-
-			mu.Lock()
-			results[branch] = info
-			mu.Unlock()
-		}(branchName, parentName, parentOID)
+// printTrunkHeader says how far the base branch is behind its remote and when
+// the remote was last fetched, i.e. how stale the statuses below may be.
+// Prints nothing when the base has no remote-tracking branch.
+func (r *logCmdRunner) printTrunkHeader(baseBranch string) {
+	d, err := git.GetTrunkDivergence(baseBranch, git.GetRemoteName())
+	if err != nil {
+		r.logger.Debug("Could not compare base with its remote", "base", baseBranch, "error", err)
+		return
 	}
-
-	// Wait for all checks to complete
-	wg.Wait()
-
-	// Process branches in order to maintain the original order
-	for i := len(stack) - 1; i >= 1; i-- {
-		branchName := stack[i]
-		branchInfos = append(branchInfos, results[branchName])
+	if d == nil {
+		return
 	}
+	_, _ = fmt.Fprintln(r.stdout, trunkHeaderText(baseBranch, d, r.fetched, time.Now()))
+}
 
-	// Create a temporary list for this stack
-	l := list.New()
-	stackBranchInfoMap := make(map[string]branchLogInfo)
-
-	for _, info := range branchInfos {
-		var statusText string
-		switch info.rebaseStatus.status {
-		case RebaseStatusNeedsRestack:
-			statusText = "(needs restack"
-		case RebaseStatusError:
-			statusText = "(rebase check failed"
-		default:
-			statusText = "(up-to-date"
+// maybeFetch runs 'git fetch --prune' with --fetch, or when
+// socle.log.autofetchInterval is set and the last fetch is older than that
+// many minutes. A failed fetch only warns; the log is shown from local refs.
+func (r *logCmdRunner) maybeFetch() {
+	if !r.fetch {
+		interval := logAutofetchInterval(r.logger)
+		if interval == 0 {
+			return
 		}
-
-		// Add PR status with hyperlink if URL exists
-		if info.prURL != "" {
-			// OSC 8 escape sequence for hyperlinks
-			prStatus := ""
-			switch info.prText {
-			case gh.PRStatusDraft:
-				prStatus = "pr drafted"
-			case gh.PRStatusMerged:
-				prStatus = "pr merged"
-			case gh.PRStatusOpen:
-				prStatus = "pr open"
-			case gh.PRStatusClosed:
-				prStatus = "pr closed"
-			case gh.PRStatusAPIError:
-				prStatus = "pr check failed"
-			case gh.PRStatusNotFound:
-				prStatus = "no PR submitted"
-			default:
-				prStatus = "no PR submitted"
-			}
-			prLink := fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", info.prURL, prStatus)
-			statusText += ", " + prLink
-		} else {
-			// No PR URL, just add the status text
-			switch info.prText {
-			case gh.PRStatusDraft:
-				statusText += ", pr drafted"
-			case gh.PRStatusMerged:
-				statusText += ", pr merged"
-			case gh.PRStatusOpen:
-				statusText += ", pr open"
-			case gh.PRStatusClosed:
-				statusText += ", pr closed"
-			case gh.PRStatusAPIError:
-				statusText += ", pr check failed"
-			case gh.PRStatusNotFound:
-				statusText += ", no PR submitted"
-			default:
-				statusText += ", no PR submitted"
-			}
+		if last := git.LastFetchTime(); !last.IsZero() && time.Since(last) < interval {
+			r.logger.Debug("Skipping autofetch, last fetch is recent", "lastFetched", last, "interval", interval)
+			return
 		}
-		statusText += ")"
-
-		stackBranchInfoMap[info.branchName] = info
-
-		boldBranchName := lipgloss.NewStyle().Bold(true).Render(info.branchName)
-		mutedStatus := mutedStyle.Render(statusText)
-		l.Item(boldBranchName + " " + mutedStatus)
 	}
+	remote := git.GetRemoteName()
+	if err := git.FetchPrune(remote); err != nil {
+		if r.porcelain != "" {
+			r.logger.Debug("Fetch before log failed", "remote", remote, "error", err)
+			return
+		}
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: %v\nStatuses are computed from local refs.", err)))
+		return
+	}
+	r.fetched = true
+}
 
-	mutedBase := mutedStyle.Render(stack[0] + " (base)")
-	l.Item(mutedBase)
-
-	// Create custom enumerator for this stack
-	stackEnumerator := func(items list.Items, i int) string {
-		item := items.At(i)
-		// Strip ANSI escape codes from the branch name
-		branchName := strings.SplitN(item.Value(), " ", 2)[0]
-		branchName = strings.TrimSuffix(branchName, "\x1b[0m")
-		branchName = strings.TrimPrefix(branchName, "\x1b[1m")
+// logAutofetchInterval reads socle.log.autofetchInterval, in minutes. Zero,
+// the default, turns autofetch off.
+func logAutofetchInterval(logger *slog.Logger) time.Duration {
+	val, err := git.GetSocleConfig("socle.log.autofetchInterval")
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(val), 10, 32)
+	if err != nil {
+		logger.Warn("Ignoring invalid socle.log.autofetchInterval", "value", val)
+		return 0
+	}
+	return time.Duration(n) * time.Minute
+}
 
-		// Base or without info need space for alignment
-		branchInfo, exists := stackBranchInfoMap[branchName]
-		if !exists || strings.Contains(item.Value(), "(base)") {
-			return "   " // Three spaces for branches without info
+func trunkHeaderText(baseBranch string, d *git.TrunkDivergence, fetched bool, now time.Time) string {
+	style := mutedStyle
+	var text string
+	switch {
+	case d.Behind == 0 && d.Ahead == 0:
+		text = fmt.Sprintf("%s is up to date with %s", baseBranch, d.Remote)
+	case d.Behind == 0:
+		text = fmt.Sprintf("%s is %d %s ahead of %s", baseBranch, d.Ahead, pluralize(d.Ahead, "commit", "commits"), d.Remote)
+	default:
+		style = ui.Colors.WarningStyle
+		text = fmt.Sprintf("%s is %d %s behind %s", baseBranch, d.Behind, pluralize(d.Behind, "commit", "commits"), d.Remote)
+		if d.Lag >= time.Hour {
+			text += fmt.Sprintf(" (%s)", formatAge(d.Lag))
 		}
-
-		// First dot: Rebase status
-		var firstDot string
-		switch branchInfo.rebaseStatus.status {
-		case RebaseStatusNeedsRestack:
-			firstDot = rebaseDotWarningStyle.Render("●")
-		default:
-			firstDot = rebaseDotStyle.Render("●")
+		if d.Ahead > 0 {
+			text += fmt.Sprintf(", %d ahead", d.Ahead)
 		}
+	}
+	if fetched {
+		text += " · fetched just now"
+	} else if d.LastFetched.IsZero() {
+		text += " · never fetched"
+	} else {
+		text += " · last fetched " + formatAge(now.Sub(d.LastFetched)) + " ago"
+	}
+	return style.Render(text)
+}
 
-		// Second dot: PR status
-		var secondDot string
-		switch {
-		case strings.Contains(branchInfo.prText, "Merged"):
-			secondDot = prDotMergedStyle.Render("●")
-		case strings.Contains(branchInfo.prText, "Open"), strings.Contains(branchInfo.prText, "Draft"):
-			secondDot = prDotSubmittedStyle.Render("●")
-		case strings.Contains(branchInfo.prText, "Closed"):
-			secondDot = prDotClosedStyle.Render("●")
-		default:
-			secondDot = prDotDefaultStyle.Render("○")
-		}
+// formatAge renders d in the largest whole unit that fits.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "less than a minute"
+	case d < time.Hour:
+		n := int(d / time.Minute)
+		return fmt.Sprintf("%d %s", n, pluralize(n, "minute", "minutes"))
+	case d < 48*time.Hour:
+		n := int(d / time.Hour)
+		return fmt.Sprintf("%d %s", n, pluralize(n, "hour", "hours"))
+	default:
+		n := int(d / (24 * time.Hour))
+		return fmt.Sprintf("%d days", n)
+	}
+}
 
-		return firstDot + " " + secondDot
+func (r *logCmdRunner) printHiddenStacksNote(hidden int) {
+	if hidden == 0 {
+		return
 	}
+	noun := pluralize(hidden, "stack", "stacks")
+	_, _ = fmt.Fprintln(r.stdout, mutedStyle.Render(fmt.Sprintf("%d %s by other authors hidden. Use --everyone to show them.", hidden, noun)))
+}
 
-	l = l.Enumerator(stackEnumerator).
-		EnumeratorStyle(lipgloss.NewStyle().MarginRight(1).Bold(true)).
-		ItemStyle(lipgloss.NewStyle().MarginRight(1))
+func pluralize(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
 
-	paddedList := lipgloss.NewStyle().
-		PaddingLeft(2).
-		PaddingTop(0).
-		PaddingBottom(1).
-		Render(l.String())
-	_, _ = fmt.Fprintln(r.stdout, paddedList)
+func (r *logCmdRunner) displaySingleStackDetailed(stack []string, prefetch *prStatusPrefetch) error {
+	if len(stack) <= 1 {
+		// Stack with only base branch
+		mutedBase := mutedStyle.Render(stack[0] + " (base, no branches)")
+		_, _ = fmt.Fprintf(r.stdout, "     %s\n\n", mutedBase)
+		return nil
+	}
+
+	branchInfos := r.collectBranchInfosWith(stack, stackParentOIDs(stack), prefetch)
+	_, _ = fmt.Fprintln(r.stdout, renderStackList(branchInfos, stack[0], 0, r.width))
 
 	return nil
 }
+
+// stackParentOIDs resolves the tips of the parents in stack in one git call.
+// Parents that cannot be resolved are left out; their rebase status is an
+// error.
+func stackParentOIDs(stack []string) map[string]string {
+	if len(stack) <= 1 {
+		return map[string]string{}
+	}
+	parentOIDs, err := git.GetMultipleBranchCommits(stack[:len(stack)-1])
+	if err != nil && parentOIDs == nil {
+		return map[string]string{}
+	}
+	return parentOIDs
+}
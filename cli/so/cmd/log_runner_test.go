@@ -3,11 +3,18 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/benekuehn/socle/cli/so/internal/gh"
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // BenchmarkLogRunner benchmarks the log command's performance
@@ -86,3 +93,122 @@ func TestGetRebaseStatus(t *testing.T) {
 	// 2. Use a different mocking approach
 	// 3. Test against real git repos with known states
 }
+
+// TestStackEnumeratorUsesStructuredEntries checks that status dots come from the
+// entries slice by position; the rendered items are never inspected.
+func TestStackEnumeratorUsesStructuredEntries(t *testing.T) {
+	entries := []*branchLogInfo{
+		{branchName: "feature-b", prText: gh.PRStatusMerged, rebaseStatus: statusResult{status: RebaseStatusNeedsRestack}},
+		{branchName: "feature-a", prText: gh.PRStatusNotFound, rebaseStatus: statusResult{status: RebaseStatusUpToDate}},
+		nil, // base
+	}
+	enumerator := stackEnumerator(entries)
+
+	if got := stripAnsi(enumerator(nil, 0)); got != "● ●" {
+		t.Errorf("unexpected dots for feature-b: %q", got)
+	}
+	if got := stripAnsi(enumerator(nil, 1)); got != "● ○" {
+		t.Errorf("unexpected dots for feature-a: %q", got)
+	}
+	if got := enumerator(nil, 2); got != "   " {
+		t.Errorf("expected blank padding for base, got %q", got)
+	}
+}
+
+func TestRenderStackListTruncatesToWidth(t *testing.T) {
+	infos := []branchLogInfo{
+		{branchName: "feature/very-long-branch-name-describing-everything", prText: gh.PRStatusOpen, rebaseStatus: statusResult{status: RebaseStatusUpToDate}, note: "waiting on the infra team"},
+		{branchName: "short", prText: gh.PRStatusNotFound, rebaseStatus: statusResult{status: RebaseStatusNeedsRestack}},
+	}
+
+	narrow := stripAnsi(renderStackList(infos, "main", 0, 50))
+	var dotColumns []int
+	for _, line := range strings.Split(narrow, "\n") {
+		if w := ui.Width(line); w > 50 {
+			t.Errorf("line is %d columns wide, want at most 50: %q", w, line)
+		}
+		if i := strings.Index(line, "●"); i >= 0 {
+			dotColumns = append(dotColumns, i)
+		}
+	}
+	if len(dotColumns) != 2 || dotColumns[0] != dotColumns[1] {
+		t.Errorf("status dots are not aligned: %v\n%s", dotColumns, narrow)
+	}
+	if !strings.Contains(narrow, "feature/very-long-br… (up-to-date, pr open)") {
+		t.Errorf("expected the long branch name to be shortened:\n%s", narrow)
+	}
+	if strings.Contains(narrow, "infra") {
+		t.Errorf("expected the note to be dropped first:\n%s", narrow)
+	}
+	if !strings.Contains(narrow, "short (needs restack, no PR submitted)") {
+		t.Errorf("a line that fits must be left alone:\n%s", narrow)
+	}
+
+	full := stripAnsi(renderStackList(infos, "main", 0, 0))
+	if !strings.Contains(full, "feature/very-long-branch-name-describing-everything (up-to-date, pr open) waiting on the infra team") {
+		t.Errorf("width 0 must not truncate:\n%s", full)
+	}
+}
+
+// TestParseRemoteURL covers the remote URL forms log, submit and sync resolve
+// the repository from.
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url                     string
+		host, port, owner, repo string
+		web                     string
+	}{
+		{"git@github.com:org/repo.git", "github.com", "", "org", "repo", "https://github.com/org/repo"},
+		{"ssh://git@github.com:2222/org/repo.git", "github.com", "2222", "org", "repo", "https://github.com/org/repo"},
+		{"ssh://git@github.com:org/repo.git", "github.com", "", "org", "repo", "https://github.com/org/repo"},
+		{"https://github.com/org/repo/", "github.com", "", "org", "repo", "https://github.com/org/repo"},
+		{"https://user@GHE.example.com:8443/org/repo.git", "ghe.example.com", "8443", "org", "repo", "https://ghe.example.com:8443/org/repo"},
+		{"https://gitlab.com/group/sub/repo.git", "gitlab.com", "", "group/sub", "repo", "https://gitlab.com/group/sub/repo"},
+		{"gitlab.com:group/sub/repo", "gitlab.com", "", "group/sub", "repo", "https://gitlab.com/group/sub/repo"},
+		{"/tmp/remotes/test-owner/test-repo.git", "", "", "test-owner", "test-repo", "https://github.com/test-owner/test-repo"},
+		{"file:///tmp/remotes/test-owner/test-repo.git/", "", "", "test-owner", "test-repo", "https://github.com/test-owner/test-repo"},
+	}
+	for _, tt := range tests {
+		u, err := git.ParseRemoteURL(tt.url)
+		if err != nil {
+			t.Errorf("ParseRemoteURL(%q) failed: %v", tt.url, err)
+			continue
+		}
+		if u.Host != tt.host || u.Port != tt.port || u.Owner != tt.owner || u.Repo != tt.repo {
+			t.Errorf("ParseRemoteURL(%q) = host %q port %q owner %q repo %q, want %q %q %q %q",
+				tt.url, u.Host, u.Port, u.Owner, u.Repo, tt.host, tt.port, tt.owner, tt.repo)
+		}
+		if web := u.WebURL(); web != tt.web {
+			t.Errorf("ParseRemoteURL(%q).WebURL() = %q, want %q", tt.url, web, tt.web)
+		}
+	}
+
+	for _, bad := range []string{"", "https://github.com/org", "git@github.com:repo.git"} {
+		if _, err := git.ParseRemoteURL(bad); err == nil {
+			t.Errorf("ParseRemoteURL(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestLogRunnerStopsWhenInterrupted(t *testing.T) {
+	_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	defer cleanup()
+
+	var stdout bytes.Buffer
+	runner := &logCmdRunner{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		stdout: &stdout,
+		stderr: io.Discard,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	infos := runner.collectBranchInfos(ctx, []string{"main", "feature-a", "feature-b"}, nil, nil)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "feature-b", infos[0].branchName, "skipped branches keep their names")
+	assert.Equal(t, "feature-a", infos[1].branchName)
+
+	err := runner.run(ctx)
+	assert.Equal(t, socleerr.Interrupted, socleerr.KindOf(err))
+	assert.NotContains(t, stdout.String(), "feature-a", "no half-checked stack is shown")
+}
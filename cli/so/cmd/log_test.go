@@ -3,11 +3,16 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
 	"github.com/benekuehn/socle/cli/so/internal/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -66,6 +71,65 @@ func TestLogCommand(t *testing.T) {
 		assert.Contains(t, actualContent, "      main (base)")
 	})
 
+	t.Run("Log header shows how far the base is behind its remote", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/example/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		for _, name := range []string{"upstream1.txt", "upstream2.txt"} {
+			writeFile(t, repoPath, name, name)
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-m", "upstream "+name)
+		}
+		testutils.RunCommand(t, repoPath, "git", "update-ref", "refs/remotes/origin/main", "HEAD")
+		testutils.RunCommand(t, repoPath, "git", "reset", "--hard", "HEAD~2")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+
+		stdout, _, err := runSoCommandWithOutput(t, "log")
+
+		require.NoError(t, err)
+		lines := strings.Split(stripAnsi(stdout), "\n")
+		assert.Equal(t, "main is 2 commits behind origin/main · never fetched", lines[0])
+
+		testutils.RunCommand(t, repoPath, "git", "update-ref", "refs/remotes/origin/main", "main")
+		stdout, _, err = runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "main is up to date with origin/main")
+	})
+
+	t.Run("Log --fetch updates the base before computing statuses", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		resetCommandFlags(t, logCmd, "fetch")
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--bare", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "push", "origin", "main", "feature-a")
+		testutils.RunCommand(t, repoPath, "git", "fetch", "origin")
+
+		// Someone else moves main on the remote and deletes feature-a there
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		writeFile(t, repoPath, "upstream.txt", "upstream")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "upstream")
+		testutils.RunCommand(t, repoPath, "git", "push", "origin", "main:main", ":feature-a")
+		testutils.RunCommand(t, repoPath, "git", "reset", "--hard", "HEAD~1")
+		testutils.RunCommand(t, repoPath, "git", "update-ref", "refs/remotes/origin/main", "main")
+		testutils.RunCommand(t, repoPath, "git", "update-ref", "refs/remotes/origin/feature-a", "feature-a")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+
+		stdout, _, err := runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "main is up to date with origin/main")
+
+		stdout, _, err = runSoCommandWithOutput(t, "log", "--fetch")
+		require.NoError(t, err)
+		lines := strings.Split(stripAnsi(stdout), "\n")
+		assert.Equal(t, "main is 1 commit behind origin/main · fetched just now", lines[0])
+		_, err = git.GetRemoteBranchCommit("feature-a", "origin")
+		assert.ErrorIs(t, err, git.ErrRefNotFound, "--fetch prunes deleted remote branches")
+	})
+
 	t.Run("Log stack needs restack (no PR)", func(t *testing.T) {
 		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
 		defer cleanup()
@@ -86,6 +150,25 @@ func TestLogCommand(t *testing.T) {
 		assert.Contains(t, actualContent, "      main (base)")
 	})
 
+	t.Run("Log marks branches already merged into their parent", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/example/test-repo.git")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+		writeFile(t, repoPath, "a_change.txt", "change")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "change feature-a")
+		testutils.RunCommand(t, repoPath, "git", "cherry-pick", "feature-b")
+
+		stdout, _, err := runSoCommandWithOutput(t, "log")
+
+		require.NoError(t, err)
+		actualContent := stripAnsi(stdout)
+		assert.Contains(t, actualContent, "feature-b (already merged into parent, no PR submitted)")
+		assert.Contains(t, actualContent, "feature-a (up-to-date, no PR submitted)")
+	})
+
 	t.Run("Log stack with PR config (PR not found scenario)", func(t *testing.T) {
 		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
 		defer cleanup()
@@ -177,6 +260,31 @@ func TestLogCommand(t *testing.T) {
 		assert.Contains(t, strippedContent, "pr open")
 	})
 
+	t.Run("Log marks open PRs that conflict with their base", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/example/test-repo.git")
+		require.NoError(t, git.SetStoredPRNumber("feature-a", 11))
+		require.NoError(t, git.SetStoredPRNumber("feature-b", 12))
+
+		mockClient := gh.NewMockClient()
+		mockClient.PRStatuses[11] = gh.PRStatusOpen
+		mockClient.PRStatuses[12] = gh.PRStatusDraft
+		mockClient.MergeableStates[11] = gh.MergeableState{Number: 11, BaseRefName: "main", Mergeable: "MERGEABLE", MergeStateStatus: "CLEAN"}
+		mockClient.MergeableStates[12] = gh.MergeableState{Number: 12, BaseRefName: "feature-a", Mergeable: "CONFLICTING", MergeStateStatus: "DIRTY"}
+		originalCreateGHClient := gh.CreateClient
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+		stdout, _, err := runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Regexp(t, `feature-b \[conflicts with feature-a\] \(up-to-date`, out)
+		assert.NotContains(t, out, "conflicts with main")
+	})
+
 	t.Run("Log on base branch with multiple stacks", func(t *testing.T) {
 		repoPath, cleanup := setupRepoWithMultipleStacks(t)
 		defer cleanup()
@@ -216,4 +324,265 @@ func TestLogCommand(t *testing.T) {
 		}
 		assert.True(t, hasBlankLineBetweenStacks, "Should have blank line between stacks")
 	})
+
+	t.Run("Log filters multiple stacks to own author", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithMultipleStacks(t)
+		defer cleanup()
+		resetCommandFlags(t, logCmd, "all", "mine", "everyone")
+
+		// feature-y's tip is authored by someone else, who is "us" via socle.author
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-y")
+		testutils.RunCommand(t, repoPath, "git", "commit", "--amend", "--no-edit", "--author", "Other Dev <other@example.com>")
+		testutils.RunCommand(t, repoPath, "git", "config", "socle.author", "other@example.com")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+
+		stdout, _, err := runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		actualContent := stripAnsi(stdout)
+		assert.Contains(t, actualContent, "feature-y")
+		assert.NotContains(t, actualContent, "feature-a")
+		assert.Contains(t, actualContent, "1 stack by other authors hidden")
+
+		stdout, _, err = runSoCommandWithOutput(t, "log", "--everyone")
+		require.NoError(t, err)
+		actualContent = stripAnsi(stdout)
+		assert.Contains(t, actualContent, "feature-a")
+		assert.Contains(t, actualContent, "feature-y")
+		assert.NotContains(t, actualContent, "hidden")
+	})
+
+	t.Run("Log --mine lists own stacks when not on base", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithMultipleStacks(t)
+		defer cleanup()
+		resetCommandFlags(t, logCmd, "mine")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-b")
+
+		stdout, _, err := runSoCommandWithOutput(t, "log", "--mine")
+		require.NoError(t, err)
+		actualContent := stripAnsi(stdout)
+		assert.Contains(t, actualContent, "2 stacks from base 'main':")
+		assert.Contains(t, actualContent, "feature-a")
+		assert.Contains(t, actualContent, "feature-x")
+	})
+}
+
+func TestLogPorcelain(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, logCmd, "porcelain")
+
+	t.Run("v1 prints one stable line per branch, bottom first", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/example/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "7")
+
+		// Make feature-b need a restack
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+		writeFile(t, repoPath, "later.txt", "later")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "later change on feature-a")
+
+		mockClient := gh.NewMockClient()
+		mockClient.PRStatuses[7] = gh.PRStatusDraft
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+
+		stdout, _, err := runSoCommandWithOutput(t, "log", "--porcelain=v1")
+		require.NoError(t, err)
+		assert.Equal(t,
+			"feature-a main main 0 7 draft 1 0\n"+
+				"feature-b feature-a main 1 - none 0 0\n",
+			stdout)
+	})
+
+	t.Run("Blobless partial clone reports status without fetching blobs", func(t *testing.T) {
+		srcPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, srcPath, "git", "checkout", "feature-a")
+		writeFile(t, srcPath, "later.txt", "later")
+		testutils.RunCommand(t, srcPath, "git", "add", ".")
+		testutils.RunCommand(t, srcPath, "git", "commit", "-m", "later change on feature-a")
+		testutils.RunCommand(t, srcPath, "git", "config", "uploadpack.allowFilter", "true")
+
+		clonePath := filepath.Join(t.TempDir(), "clone")
+		testutils.RunCommand(t, srcPath, "git", "clone", "--filter=blob:none", "--branch", "main", "file://"+srcPath, clonePath)
+		testutils.RunCommand(t, clonePath, "git", "branch", "feature-a", "origin/feature-a")
+		testutils.RunCommand(t, clonePath, "git", "checkout", "-b", "feature-b", "origin/feature-b")
+		trackBranch(t, clonePath, "feature-a", "main", "main")
+		trackBranch(t, clonePath, "feature-b", "feature-a", "main")
+		// Any lazy blob fetch would now fail loudly
+		testutils.RunCommand(t, clonePath, "git", "remote", "set-url", "origin", filepath.Join(t.TempDir(), "gone"))
+
+		require.NoError(t, os.Chdir(clonePath))
+		defer func() { _ = os.Chdir(srcPath) }() // Runs before cleanup restores the original directory
+		require.True(t, git.IsSparseSafe(), "partial clones should default to sparse-safe mode")
+
+		stdout, _, err := runSoCommandWithOutput(t, "log", "--porcelain")
+		require.NoError(t, err)
+		assert.Equal(t,
+			"feature-a main main 0 - none 0 0\n"+
+				"feature-b feature-a main 1 - none 1 0\n",
+			stdout)
+	})
+
+	t.Run("Untracked branch prints nothing", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "untracked")
+
+		stdout, _, err := runSoCommandWithOutput(t, "log", "--porcelain")
+		require.NoError(t, err)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("Unknown version is rejected", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		_, _, err := runSoCommandWithOutput(t, "log", "--porcelain=v9")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported porcelain version 'v9'")
+	})
+}
+
+func TestLogVerify(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, logCmd, "verify")
+	resetCommandFlags(t, submitCmd, "test-title", "test-body")
+
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	defer cleanup()
+	remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+	testutils.RunCommand(t, repoPath, "git", "init", "--quiet", "--bare", remotePath)
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+
+	api := gh.NewFakeServer("test-owner", "test-repo")
+	defer api.Close()
+	ctx := context.Background()
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return api.Client(ctx), nil
+	}
+
+	_, _, err := runSoCommandWithOutput(t, "submit", "--test-title=T", "--test-body=Body")
+	require.NoError(t, err)
+
+	stdout, _, err := runSoCommandWithOutput(t, "log", "--verify")
+	require.NoError(t, err)
+	assert.Contains(t, stripAnsi(stdout), "✓ Metadata matches GitHub and the remote.")
+
+	// Someone retargets PR #2, edits the stack comment of PR #1 and pushes over feature-a
+	client := api.Client(ctx)
+	_, err = client.UpdatePullRequestBase(2, "main")
+	require.NoError(t, err)
+	commentID, err := client.FindCommentWithMarker(1, stackCommentMarker)
+	require.NoError(t, err)
+	_, err = client.UpdateComment(commentID, stackCommentMarker+"\n- **#1** 👈")
+	require.NoError(t, err)
+	testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "--force", "origin", "main:refs/heads/feature-a")
+
+	stdout, _, err = runSoCommandWithOutput(t, "log", "--verify")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found 3 mismatch(es)")
+	output := stripAnsi(stdout)
+	assert.Contains(t, output, "feature-b: PR #2 targets 'main', but the branch is stacked on 'feature-a'")
+	assert.Contains(t, output, "feature-a: the stack comment of PR #1 lists #1 instead of #2, #1")
+	assert.Contains(t, output, "→ so comment refresh")
+	assert.Contains(t, output, "but socle last pushed")
+}
+
+func TestLogStatusCache(t *testing.T) {
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	defer cleanup()
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+
+	cachePath := filepath.Join(t.TempDir(), "pr_status.json")
+	originalCachePath := gh.StatusCachePath
+	gh.StatusCachePath = func() (string, error) { return cachePath, nil }
+	t.Cleanup(func() { gh.StatusCachePath = originalCachePath })
+	resetCommandFlags(t, logCmd, "no-cache")
+
+	mockClient := gh.NewMockClient()
+	mockClient.PRStatuses[101] = gh.PRStatusOpen
+	mockClient.PRStatuses[102] = gh.PRStatusDraft
+	originalCreateGHClient := gh.CreateClient
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return mockClient, nil
+	}
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	gh.Counter.Reset()
+
+	_, _, err := runSoCommandWithOutput(t, "log")
+	require.NoError(t, err)
+	assert.Equal(t, 2, gh.Counter.GetCount("GetPullRequestStatus"))
+
+	// PR #101 merges on GitHub; a second log within the TTL does not ask
+	mockClient.PRStatuses[101] = gh.PRStatusMerged
+	stdout, _, err := runSoCommandWithOutput(t, "log")
+	require.NoError(t, err)
+	assert.Equal(t, 2, gh.Counter.GetCount("GetPullRequestStatus"))
+	assert.NotContains(t, stdout, "pr merged")
+
+	stdout, _, err = runSoCommandWithOutput(t, "log", "--no-cache")
+	require.NoError(t, err)
+	assert.Equal(t, 4, gh.Counter.GetCount("GetPullRequestStatus"))
+	assert.Contains(t, stdout, "pr merged")
+
+	// With the cache off, every run asks
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.prStatusCacheTTL", "0")
+	resetCommandFlags(t, logCmd, "no-cache")
+	_, _, err = runSoCommandWithOutput(t, "log")
+	require.NoError(t, err)
+	assert.Equal(t, 6, gh.Counter.GetCount("GetPullRequestStatus"))
+}
+
+func TestLogPrefetchesPRStatuses(t *testing.T) {
+	repoPath, cleanup := setupRepoWithMultipleStacks(t)
+	defer cleanup()
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	for branch, number := range map[string]string{"feature-a": "1", "feature-b": "2", "feature-x": "3"} {
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+branch+".socle-pr-number", number)
+	}
+	resetCommandFlags(t, logCmd, "all")
+
+	mockClient := gh.NewMockClient()
+	mockClient.PRStatuses[1] = gh.PRStatusMerged
+	mockClient.PRStatuses[2] = gh.PRStatusOpen
+	mockClient.PRStatuses[3] = gh.PRStatusDraft
+	var clients atomic.Int32
+	originalCreateGHClient := gh.CreateClient
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		clients.Add(1)
+		return mockClient, nil
+	}
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	gh.Counter.Reset()
+
+	// Every stack's PRs are looked up in one round, with one client
+	testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+	stdout, _, err := runSoCommandWithOutput(t, "log", "--all")
+	require.NoError(t, err)
+	output := stripAnsi(stdout)
+	assert.Contains(t, output, "pr merged")
+	assert.Contains(t, output, "pr open")
+	assert.Contains(t, output, "pr drafted")
+	assert.Equal(t, int32(1), clients.Load())
+	assert.Equal(t, 3, gh.Counter.GetCount("GetPullRequestStatus"), "feature-y has no PR to look up")
+
+	// A client that can't be created still leaves the local statuses
+	resetCommandFlags(t, logCmd, "all")
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return nil, errors.New("no token")
+	}
+	testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+	stdout, stderr, err := runSoCommandWithOutput(t, "log")
+	require.NoError(t, err)
+	assert.Contains(t, stripAnsi(stderr), "GitHub client initialization failed")
+	assert.Contains(t, stripAnsi(stdout), "feature-a")
+	assert.Contains(t, stripAnsi(stdout), "feature-b")
 }
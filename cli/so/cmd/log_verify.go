@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+// verifyFinding is a place where socle's metadata disagrees with GitHub or
+// the remote, with the command that reconciles them.
+type verifyFinding struct {
+	branch  string
+	problem string
+	fix     string
+}
+
+// stackCommentPR matches a PR's entry in the stack comment.
+var stackCommentPR = regexp.MustCompile(`\*\*#(\d+)\*\*`)
+
+// verifyStack cross-checks the metadata of each branch of stack with GitHub
+// and the remote: the stored PR's head and base, the PR's stack comment, and
+// the remote branch against the tip socle last pushed. It prints a
+// reconciliation report and fails if anything disagrees.
+func (r *logCmdRunner) verifyStack(stack []string, ghClient gh.ClientInterface) error {
+	if ghClient == nil {
+		return fmt.Errorf("cannot verify the stack without access to GitHub")
+	}
+	remoteName := git.GetRemoteName()
+	_, _ = fmt.Fprintf(r.stdout, "Verifying metadata against GitHub and '%s'...\n", remoteName)
+
+	prNumbers := make(map[string]int)
+	var listed []int // PR numbers as the stack comment lists them, newest first
+	for i := len(stack) - 1; i >= 1; i-- {
+		if number, err := git.GetStoredPRNumber(stack[i]); err == nil && number > 0 {
+			prNumbers[stack[i]] = number
+			listed = append(listed, number)
+		}
+	}
+	tips := r.remoteTips(stack, remoteName)
+
+	var findings []verifyFinding
+	for i := 1; i < len(stack); i++ {
+		branch, parent := stack[i], stack[i-1]
+		findings = append(findings, verifyBranch(branch, parent, prNumbers[branch], listed, ghClient)...)
+		if finding, ok := verifyRemoteTip(branch, remoteName, tips); ok {
+			findings = append(findings, finding)
+		}
+	}
+
+	if len(findings) == 0 {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("✓ Metadata matches GitHub and the remote."))
+		return nil
+	}
+	for _, f := range findings {
+		_, _ = fmt.Fprintf(r.stdout, "  %s %s: %s\n", ui.Colors.FailureStyle.Render("✗"), f.branch, f.problem)
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("      → "+f.fix))
+	}
+	return fmt.Errorf("found %d mismatch(es) between socle's metadata and GitHub", len(findings))
+}
+
+// remoteTips lists the remote's branch tips when a branch of stack has a
+// recorded push to compare with. Failing to reach the remote only skips
+// those checks.
+func (r *logCmdRunner) remoteTips(stack []string, remoteName string) map[string]string {
+	needed := false
+	for _, branch := range stack[1:] {
+		if _, ok := git.GetSubmittedState(branch); ok {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil
+	}
+	tips, err := git.RemoteBranchTips(remoteName)
+	if err != nil {
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: %v. Remote branch tips are not checked.", err)))
+		return nil
+	}
+	return tips
+}
+
+// verifyBranch checks the PR stored for branch: that it is the branch's PR,
+// that an open one targets the branch's parent and that its stack comment
+// lists the PRs of the stack in order (listed) and marks this one.
+func verifyBranch(branch, parent string, number int, listed []int, ghClient gh.ClientInterface) []verifyFinding {
+	if number == 0 {
+		return nil
+	}
+	resubmit := "so submit --stack-only " + branch
+	forget := fmt.Sprintf("git config --unset %s && %s", git.BranchConfigKey(branch, "socle-pr-number"), resubmit)
+
+	pr, err := ghClient.GetPullRequest(number)
+	if err != nil {
+		return []verifyFinding{{branch, fmt.Sprintf("stored PR #%d could not be read: %v", number, err), forget}}
+	}
+	if head := pr.GetHead().GetRef(); head != branch {
+		return []verifyFinding{{branch, fmt.Sprintf("stored PR #%d is for branch '%s'", number, head), forget}}
+	}
+	if pr.GetState() != "open" {
+		return nil // Merged and closed PRs keep the base and comment they ended with
+	}
+
+	var findings []verifyFinding
+	if base, want := pr.GetBase().GetRef(), git.PRBaseOf(branch, parent); base != want {
+		reason := fmt.Sprintf("the branch is stacked on '%s'", want)
+		if git.GetPRBaseOverride(branch) != "" {
+			reason = fmt.Sprintf("its PR base is overridden to '%s'", want)
+		}
+		findings = append(findings, verifyFinding{branch, fmt.Sprintf("PR #%d targets '%s', but %s", number, base, reason), resubmit})
+	}
+	if problem := checkStackComment(ghClient, number, listed); problem != "" {
+		findings = append(findings, verifyFinding{branch, problem, "so comment refresh"})
+	}
+	return findings
+}
+
+// checkStackComment describes what is wrong with the stack comment of PR
+// number, or returns "" if it lists the PRs as listed and points at number.
+func checkStackComment(ghClient gh.ClientInterface, number int, listed []int) string {
+	id, err := ghClient.FindCommentWithMarker(number, stackCommentMarker)
+	if err != nil {
+		return fmt.Sprintf("the stack comment of PR #%d could not be read: %v", number, err)
+	}
+	if id == 0 {
+		return fmt.Sprintf("PR #%d has no stack comment", number)
+	}
+	comment, err := ghClient.GetIssueComment(id)
+	if err != nil {
+		return fmt.Sprintf("the stack comment of PR #%d could not be read: %v", number, err)
+	}
+	body := comment.GetBody()
+	if _, full, ok := strings.Cut(body, "<details>"); ok {
+		body = full // Deep stacks list every PR in the fold-out
+	}
+
+	var found []int
+	for _, match := range stackCommentPR.FindAllStringSubmatch(body, -1) {
+		n, _ := strconv.Atoi(match[1])
+		found = append(found, n)
+	}
+	if !slices.Equal(found, listed) {
+		return fmt.Sprintf("the stack comment of PR #%d lists %s instead of %s", number, formatPRNumbers(found), formatPRNumbers(listed))
+	}
+	own := fmt.Sprintf("**#%d**", number)
+	for _, line := range strings.Split(body, "\n") {
+		if strings.Contains(line, own) && strings.Contains(line, "👈") {
+			return ""
+		}
+	}
+	return fmt.Sprintf("the stack comment of PR #%d does not point at it", number)
+}
+
+// verifyRemoteTip compares the remote branch with the tip socle last pushed.
+// A remote branch at the local tip is fine however it got there.
+func verifyRemoteTip(branch, remoteName string, tips map[string]string) (verifyFinding, bool) {
+	state, ok := git.GetSubmittedState(branch)
+	if !ok || tips == nil {
+		return verifyFinding{}, false
+	}
+	resubmit := "so submit --stack-only " + branch
+	remote, exists := tips[branch]
+	if !exists {
+		return verifyFinding{branch, fmt.Sprintf("pushed before, but no longer on '%s'", remoteName), resubmit}, true
+	}
+	if remote == state.Tip {
+		return verifyFinding{}, false
+	}
+	if local, err := git.GetCurrentBranchCommit(branch); err == nil && local == remote {
+		return verifyFinding{}, false
+	}
+	problem := fmt.Sprintf("'%s/%s' is at %s, but socle last pushed %s", remoteName, branch, remote[:min(8, len(remote))], state.Tip[:min(8, len(state.Tip))])
+	fix := fmt.Sprintf("git fetch %s %s, integrate the new commits, then %s (or add --force to overwrite them)", remoteName, branch, resubmit)
+	return verifyFinding{branch, problem, fix}, true
+}
+
+func formatPRNumbers(numbers []int) string {
+	if len(numbers) == 0 {
+		return "no PRs"
+	}
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = fmt.Sprintf("#%d", n)
+	}
+	return strings.Join(parts, ", ")
+}
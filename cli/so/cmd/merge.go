@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Merge the bottom PR of the stack and restack the rest onto the base branch",
+	Long: `Lands the bottom-most open pull request of the current stack:
+
+  1. Merges it through the GitHub API, provided GitHub reports no blockers
+     (missing reviews, failing required checks or conflicts) and the PR's
+     head is still the branch's local tip.
+  2. Points the branches above it, and their PRs, at the base branch.
+  3. Updates the base branch and deletes the merged branch locally and on
+     the remote.
+  4. Restacks the branches that were above it onto the updated base branch.
+     Other stacks on the base are left alone.
+
+With 'socle.closeTrackingIssue' set, merging the last PR of the stack also
+closes its tracking issue.
+
+The merge method must be one the repository allows. Without --method, the
+first allowed one of merge, squash and rebase is used, as on GitHub.`,
+	Args: cobra.NoArgs,
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		method, _ := cmd.Flags().GetString("method")
+		noRestack, _ := cmd.Flags().GetBool("no-restack")
+
+		switch method {
+		case "", "merge", "squash", "rebase":
+		default:
+			return fmt.Errorf("invalid --method '%s': expected merge, squash or rebase", method)
+		}
+
+		runner := &mergeCmdRunner{
+			logger:         slog.Default(),
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          cmd.InOrStdin(),
+			nonInteractive: nonInteractive,
+			method:         method,
+			doRestack:      !noRestack,
+		}
+		return runner.run(cmd)
+	}),
+}
+
+func init() {
+	AddCommand(mergeCmd)
+	mergeCmd.Flags().String("method", "", "How to merge the PR: merge, squash or rebase (default: first one the repository allows)")
+	mergeCmd.Flags().Bool("no-restack", false, "Don't restack the remaining branches after merging")
+}
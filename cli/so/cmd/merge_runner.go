@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+type mergeCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+
+	nonInteractive bool
+	method         string // Empty picks the first method the repository allows
+	doRestack      bool
+}
+
+func (r *mergeCmdRunner) run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if git.IsRebaseInProgress() {
+		return socleerr.New(socleerr.Conflict, "a rebase is in progress; finish or abort it before merging")
+	}
+	hasChanges, err := git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if hasChanges {
+		return socleerr.New(socleerr.DirtyWorktree, "uncommitted changes detected. Please commit or stash them before merging")
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	stack := stackInfo.FullStack
+	if len(stack) <= 1 {
+		return fmt.Errorf("no stack to merge: check out a tracked branch of the stack first")
+	}
+	base, bottom := stack[0], stack[1]
+
+	prNumber, err := git.GetStoredPRNumber(bottom)
+	if err != nil {
+		return fmt.Errorf("failed to read PR number for '%s': %w", bottom, err)
+	}
+	if prNumber == 0 {
+		return fmt.Errorf("'%s' has no PR yet; run 'so submit' first", bottom)
+	}
+
+	client, err := newOriginGitHubClient(ctx)
+	if err != nil {
+		return err
+	}
+	readiness, err := client.GetMergeReadiness(prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get the state of #%d: %w", prNumber, err)
+	}
+	method, err := r.mergeMethod(client)
+	if err != nil {
+		return err
+	}
+	// Merge what is checked out here, not commits pushed from elsewhere
+	headOID, err := git.GetCurrentBranchCommit(bottom)
+	if err != nil {
+		return fmt.Errorf("cannot get current commit of '%s': %w", bottom, err)
+	}
+
+	trunk := git.PRBaseFor(base)
+	if err := mergeBottomPR(client, r.stdout, bottom, trunk, readiness, method, headOID); err != nil {
+		return err
+	}
+
+	// Retarget the PRs above before the branch they point at is deleted;
+	// GitHub closes PRs whose base branch disappears.
+	children, err := r.retargetChildren(client, bottom, base, trunk, prNumber)
+	if err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		closeTrackingIssue(client, r.stdout, r.stderr, []string{bottom})
+	}
+
+	remoteName := git.GetRemoteName()
+	if err := r.updateBase(base, remoteName); err != nil {
+		return err
+	}
+
+	// Stay on the stack: a merged current branch hands over to the branch
+	// above it.
+	target := stackInfo.CurrentBranch
+	if target == bottom || target == base {
+		target = base
+		if len(children) > 0 {
+			target = children[0]
+		}
+	}
+	if err := git.CheckoutBranch(target); err != nil {
+		return fmt.Errorf("failed to check out '%s': %w", target, err)
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "Deleting branch %s... ", bottom)
+	if err := git.DeleteBranch(bottom); err != nil {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.FailureStyle.Render("Failed"))
+		return fmt.Errorf("failed to delete branch '%s': %w", bottom, err)
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Success"))
+	r.deleteRemoteBranch(bottom, remoteName)
+
+	if len(children) == 0 {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("\n✓ The whole stack has landed."))
+		return nil
+	}
+	if !r.doRestack {
+		_, _ = fmt.Fprintln(r.stdout, "\nSkipping restack (--no-restack). Run 'so restack' to move the remaining branches onto the updated base.")
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, "\nRestacking the remaining branches...")
+	restackRunner := &restackCmdRunner{
+		logger:         r.logger,
+		stdout:         r.stdout,
+		stderr:         r.stderr,
+		stdin:          r.stdin,
+		nonInteractive: r.nonInteractive,
+		noFetch:        true, // The base was just updated
+		allStacks:      true,
+		subtrees:       children,
+	}
+	if err := restackRunner.run(cmd); err != nil {
+		return fmt.Errorf("failed during restack: %w", err)
+	}
+	return nil
+}
+
+// mergeMethod checks --method against the repository's merge settings, or
+// picks the first allowed method when none was given.
+func (r *mergeCmdRunner) mergeMethod(client gh.ClientInterface) (string, error) {
+	allowed, err := client.MergeMethods()
+	if err != nil {
+		return "", err
+	}
+	if len(allowed) == 0 {
+		return "", fmt.Errorf("the repository allows no merge method")
+	}
+	if r.method == "" {
+		return allowed[0], nil
+	}
+	if !slices.Contains(allowed, r.method) {
+		return "", fmt.Errorf("the repository does not allow --method %s; allowed: %s", r.method, strings.Join(allowed, ", "))
+	}
+	return r.method, nil
+}
+
+// retargetChildren moves the branches on top of the merged one, and their
+// PRs, onto the base branch. The merged branch's tip is recorded as where
+// each child's own commits start, so the restack that follows replays only
+// those and not the merged commits, which a squash merge rewrote. It returns
+// the children sorted by name.
+func (r *mergeCmdRunner) retargetChildren(client gh.ClientInterface, merged, base, trunk string, mergedNumber int) ([]string, error) {
+	parents, err := git.GetAllSocleParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking relationships: %w", err)
+	}
+	children := git.BuildChildMap(parents)[merged]
+	sort.Strings(children)
+
+	for _, child := range children {
+		if err := recordUpstream(child, merged); err != nil {
+			return nil, err
+		}
+		if err := git.UpdateBranchParent(child, base); err != nil {
+			return nil, fmt.Errorf("failed to update parent for branch '%s' to '%s': %w", child, base, err)
+		}
+		number, err := git.GetStoredPRNumber(child)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PR number for '%s': %w", child, err)
+		}
+		if number == 0 {
+			_, _ = fmt.Fprintf(r.stdout, "Moved '%s' onto '%s'.\n", child, base)
+			continue
+		}
+		if _, err := client.UpdatePullRequestBase(number, trunk); err != nil {
+			return nil, fmt.Errorf("merged #%d, but could not point #%d at '%s': %w", mergedNumber, number, trunk, err)
+		}
+		_, _ = fmt.Fprintf(r.stdout, "Moved '%s' onto '%s' and pointed #%d at '%s'.\n", child, base, number, trunk)
+	}
+	return children, nil
+}
+
+// updateBase fetches the remote and fast-forwards the base branch to the
+// merge. A frozen base stays where it is.
+func (r *mergeCmdRunner) updateBase(base, remoteName string) error {
+	if frozen, ok := git.GetFrozenBase(base); ok {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render(fmt.Sprintf("'%s' is frozen at %s; not updated.", base, frozen.Ref)))
+		return nil
+	}
+	if err := git.FetchAll(remoteName); err != nil {
+		return fmt.Errorf("failed to fetch from remote '%s': %w", remoteName, err)
+	}
+	if err := git.FastForwardBranch(base, remoteName); err != nil {
+		if errors.Is(err, git.ErrNotFastForward) {
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: '%s' has diverged from '%s/%s' and was not updated.", base, remoteName, base)))
+			return nil
+		}
+		return fmt.Errorf("failed to update '%s': %w", base, err)
+	}
+	_, _ = fmt.Fprintf(r.stdout, "Updated '%s' from '%s/%s'.\n", base, remoteName, base)
+	return nil
+}
+
+// deleteRemoteBranch removes the merged branch from the remote unless GitHub
+// already did. Failures only warn; the PR is merged.
+func (r *mergeCmdRunner) deleteRemoteBranch(branch, remoteName string) {
+	exists, err := git.RemoteBranchExists(branch, remoteName)
+	if err != nil {
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: could not check '%s' on '%s': %v", branch, remoteName, err)))
+		return
+	}
+	if !exists {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render(fmt.Sprintf("Remote branch '%s/%s' was already deleted on merge.", remoteName, branch)))
+		return
+	}
+	_, _ = fmt.Fprintf(r.stdout, "Deleting remote branch %s/%s... ", remoteName, branch)
+	if err := git.DeleteRemoteBranch(branch, remoteName); err != nil {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.FailureStyle.Render("Failed"))
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: %v", err)))
+		return
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Success"))
+}
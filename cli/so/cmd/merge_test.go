@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, mergeCmd, "method", "no-restack")
+
+	// main -> feature-a (#101) -> feature-b (#102), pushed to a bare origin
+	// whose path parses as test-owner/test-repo.
+	setup := func(t *testing.T) (string, string, *gh.MockClient) {
+		resetCommandFlags(t, mergeCmd, "method", "no-restack")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		t.Cleanup(cleanup)
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--quiet", "--bare", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "main", "feature-a", "feature-b")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		return repoPath, remotePath, mockClient
+	}
+
+	t.Run("Merges the bottom PR, deletes its branch and restacks the rest", func(t *testing.T) {
+		repoPath, remotePath, mockClient := setup(t)
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{
+			Number: 101, State: "OPEN", MergeStateStatus: "CLEAN", ReviewDecision: "APPROVED",
+		}, nil).Once()
+		mockClient.On("MergeMethods").Return([]string{"squash", "rebase"}, nil).Once()
+		// GitHub squashes feature-a into main.
+		head := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-a"))
+		mockClient.On("MergePullRequest", 101, "squash", head).Return(nil).Run(func(mock.Arguments) {
+			squashed := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "commit-tree", "feature-a^{tree}", "-p", "main", "-m", "feat: commit on feature-a (#101)"))
+			testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", squashed+":refs/heads/main")
+		}).Once()
+		mockClient.On("UpdatePullRequestBase", 102, "main").Return(&github.PullRequest{}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "merge", "--non-interactive")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "✓ Merged #101 ('feature-a') into 'main' (squash).")
+		assert.Contains(t, out, "Moved 'feature-b' onto 'main' and pointed #102 at 'main'.")
+		assert.Contains(t, out, "Updated 'main' from 'origin/main'.")
+		assert.Contains(t, out, "Deleting branch feature-a... Success")
+		assert.Contains(t, out, "Deleting remote branch origin/feature-a... Success")
+		assert.Contains(t, out, "Restacking the remaining branches...")
+
+		assert.Equal(t, "feature-b", strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "branch", "--show-current")))
+		assert.Equal(t, "main", strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "branch.feature-b.socle-parent")))
+		assert.NotContains(t, testutils.RunCommand(t, repoPath, "git", "branch", "--list"), "feature-a")
+		assert.NotContains(t, testutils.RunCommand(t, remotePath, "git", "branch", "--list"), "feature-a")
+
+		mainTip := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "main"))
+		assert.Equal(t, mainTip, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "merge-base", "main", "feature-b")))
+		assert.Equal(t, "feat: commit on feature-b\n", testutils.RunCommand(t, repoPath, "git", "log", "--format=%s", "main..feature-b"))
+	})
+
+	t.Run("Replays only the children's own commits after a squash merge", func(t *testing.T) {
+		repoPath, _, mockClient := setup(t)
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{
+			Number: 101, State: "OPEN", MergeStateStatus: "CLEAN", ReviewDecision: "APPROVED",
+		}, nil).Once()
+		mockClient.On("MergeMethods").Return([]string{"squash"}, nil).Once()
+		// feature-a gets a second commit, so its squash matches neither commit's
+		// patch, and a later commit on main edits its file: replaying
+		// feature-a's own commits would conflict.
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		writeFile(t, repoPath, "a2.txt", "a2\n")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "feat: second on feature-a")
+		testutils.RunCommand(t, repoPath, "git", "rebase", "-q", "feature-a", "feature-b")
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "--force", "origin", "feature-a", "feature-b")
+		head := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-a"))
+		mockClient.On("MergePullRequest", 101, "squash", head).Return(nil).Run(func(mock.Arguments) {
+			squashed := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "commit-tree", "feature-a^{tree}", "-p", "main", "-m", "feat: commit on feature-a (#101)"))
+			testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", squashed+":refs/heads/main")
+			testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "--detach", squashed)
+			writeFile(t, repoPath, "feature-a.txt", "edited on main\n")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-am", "chore: edit feature-a.txt")
+			testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "HEAD:refs/heads/main")
+			testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+		}).Once()
+		mockClient.On("UpdatePullRequestBase", 102, "main").Return(&github.PullRequest{}, nil).Once()
+
+		_, _, err := runSoCommandWithOutput(t, "merge", "--non-interactive")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+
+		assert.Equal(t, "feat: commit on feature-b\n", testutils.RunCommand(t, repoPath, "git", "log", "--format=%s", "main..feature-b"))
+		assert.Equal(t, "edited on main\n", testutils.RunCommand(t, repoPath, "git", "show", "feature-b:feature-a.txt"))
+	})
+
+	t.Run("Restacks only the branches above the merged one", func(t *testing.T) {
+		repoPath, _, mockClient := setup(t)
+		// other is a separate stack on main.
+		for _, branch := range [][2]string{{"other", "main"}} {
+			testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", branch[0], branch[1])
+			writeFile(t, repoPath, branch[0]+".txt", branch[0]+"\n")
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "feat: commit on "+branch[0])
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+branch[0]+".socle-parent", branch[1])
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+branch[0]+".socle-base", "main")
+		}
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		otherBase := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "main"))
+
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{
+			Number: 101, State: "OPEN", MergeStateStatus: "CLEAN", ReviewDecision: "APPROVED",
+		}, nil).Once()
+		mockClient.On("MergeMethods").Return([]string{"squash"}, nil).Once()
+		mockClient.On("MergePullRequest", 101, "squash", mock.Anything).Return(nil).Run(func(mock.Arguments) {
+			squashed := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "commit-tree", "feature-a^{tree}", "-p", "main", "-m", "feat: commit on feature-a (#101)"))
+			testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", squashed+":refs/heads/main")
+		}).Once()
+		mockClient.On("UpdatePullRequestBase", 102, "main").Return(&github.PullRequest{}, nil).Once()
+
+		require.NoError(t, runSoCommand(t, "merge", "--non-interactive"))
+		mockClient.AssertExpectations(t)
+
+		mainTip := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "main"))
+		for _, branch := range []string{"feature-b"} {
+			assert.Equal(t, mainTip, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "merge-base", "main", branch)), "'%s' is restacked", branch)
+		}
+		assert.Equal(t, otherBase, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "merge-base", "main", "other")), "the unrelated stack is left alone")
+	})
+
+	t.Run("Closes the tracking issue with the last PR", func(t *testing.T) {
+		repoPath, _, mockClient := setup(t)
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		testutils.RunCommand(t, repoPath, "git", "branch", "-q", "-D", "feature-b")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.closeTrackingIssue", "true")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-tracking-issue", "7")
+
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{
+			Number: 101, State: "OPEN", MergeStateStatus: "CLEAN", ReviewDecision: "APPROVED",
+		}, nil).Once()
+		mockClient.On("MergeMethods").Return([]string{"squash"}, nil).Once()
+		mockClient.On("MergePullRequest", 101, "squash", mock.Anything).Return(nil).Once()
+		mockClient.On("CloseIssue", 7).Return(nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "merge", "--non-interactive")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "Closed tracking issue #7.")
+		assert.Contains(t, out, "The whole stack has landed.")
+	})
+
+	t.Run("Refuses methods the repository does not allow and PRs with blockers", func(t *testing.T) {
+		repoPath, _, mockClient := setup(t)
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{
+			Number: 101, State: "OPEN", MergeStateStatus: "CLEAN", ReviewDecision: "APPROVED",
+		}, nil).Once()
+		mockClient.On("MergeMethods").Return([]string{"squash"}, nil).Twice()
+
+		err := runSoCommand(t, "merge", "--method=rebase")
+		require.ErrorContains(t, err, "the repository does not allow --method rebase; allowed: squash")
+		resetCommandFlags(t, mergeCmd, "method", "no-restack")
+
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{
+			Number: 101, State: "OPEN", MergeStateStatus: "BLOCKED", ReviewDecision: "REVIEW_REQUIRED",
+		}, nil).Once()
+		err = runSoCommand(t, "merge")
+		require.ErrorContains(t, err, "#101 ('feature-a') cannot be merged yet: review required")
+
+		mockClient.AssertNotCalled(t, "MergePullRequest", mock.Anything, mock.Anything, mock.Anything)
+		testutils.RunCommand(t, repoPath, "git", "rev-parse", "--verify", "feature-a")
+	})
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateBaseCmd = &cobra.Command{
+	Use:   "migrate-base",
+	Short: "Move stacks off a renamed base branch (e.g. master -> main)",
+	Long: `Detects stacks whose base branch no longer exists locally and moves them onto
+the remote's current default branch, as recorded in origin/HEAD.
+
+Every 'socle-base' and 'socle-parent' value naming the old base is rewritten, and
+open pull requests of the bottom branches are retargeted to the new base. If the
+new base only exists as 'origin/<name>', a local branch is created from it.
+
+Use --from/--to to migrate explicitly instead of relying on detection, e.g. when
+origin/HEAD is not set ('git remote set-head origin --auto' fixes that).`,
+	Args: cobra.NoArgs,
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		yes, _ := cmd.Flags().GetBool("yes")
+		noPR, _ := cmd.Flags().GetBool("no-pr")
+
+		runner := &migrateBaseCmdRunner{
+			logger:         slog.Default(),
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          os.Stdin,
+			nonInteractive: nonInteractive,
+			from:           from,
+			to:             to,
+			yes:            yes,
+			noPR:           noPR,
+		}
+		return runner.run(cmd.Context())
+	}),
+}
+
+func init() {
+	AddCommand(migrateBaseCmd)
+	migrateBaseCmd.Flags().String("from", "", "Old base branch name (default: detect missing bases)")
+	migrateBaseCmd.Flags().String("to", "", "New base branch name (default: the branch origin/HEAD points to)")
+	migrateBaseCmd.Flags().BoolP("yes", "y", false, "Apply the migration without asking for confirmation")
+	migrateBaseCmd.Flags().Bool("no-pr", false, "Only rewrite local metadata; do not retarget pull requests")
+}
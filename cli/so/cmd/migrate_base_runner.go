@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type migrateBaseCmdRunner struct {
+	logger         *slog.Logger
+	stdout         io.Writer
+	stderr         io.Writer
+	stdin          io.Reader
+	nonInteractive bool
+
+	from string
+	to   string
+	yes  bool
+	noPR bool
+}
+
+func (r *migrateBaseCmdRunner) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	remoteName := git.GetRemoteName()
+
+	renames, err := r.planRenames(remoteName)
+	if err != nil {
+		return err
+	}
+	if len(renames) == 0 {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("All stack bases exist. Nothing to migrate."))
+		return nil
+	}
+
+	for _, rename := range renames {
+		_, _ = fmt.Fprintf(r.stdout, "Base '%s' -> '%s' (%d tracked branch(es)):\n", rename.From, rename.To, len(rename.Branches))
+		for _, branch := range rename.Branches {
+			_, _ = fmt.Fprintf(r.stdout, "  - %s\n", branch)
+		}
+	}
+
+	if !r.yes {
+		if r.nonInteractive {
+			return fmt.Errorf("refusing to migrate in non-interactive mode; rerun with --yes")
+		}
+		confirm := false
+		prompt := &survey.Confirm{Message: "Rewrite stack metadata and retarget pull requests?", Default: true}
+		surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
+		if err := ui.AskOne(prompt, &confirm, surveyOpts); err != nil {
+			return ui.HandleSurveyInterrupt(err, "Migration cancelled.")
+		}
+		if !confirm {
+			_, _ = fmt.Fprintln(r.stdout, "Migration cancelled.")
+			return nil
+		}
+	}
+
+	var ghClient gh.ClientInterface
+	for _, rename := range renames {
+		if err := r.ensureLocalBranch(rename.To, remoteName); err != nil {
+			return err
+		}
+		reparented, err := git.MigrateBase(rename.From, rename.To)
+		if err != nil {
+			return fmt.Errorf("failed to migrate base '%s' to '%s': %w", rename.From, rename.To, err)
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Stacks on '%s' now use '%s'.", rename.From, rename.To)))
+
+		if r.noPR {
+			continue
+		}
+		for _, branch := range reparented {
+			prNumber, err := git.GetStoredPRNumber(branch)
+			if err != nil || prNumber == 0 {
+				continue
+			}
+			if ghClient == nil {
+				ghClient, err = newOriginGitHubClient(ctx)
+				if err != nil {
+					_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: cannot retarget pull requests: %v", err)))
+					return nil
+				}
+			}
+			if _, err := ghClient.UpdatePullRequestBase(prNumber, rename.To); err != nil {
+				_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: failed to retarget PR #%d (%s): %v", prNumber, branch, err)))
+				continue
+			}
+			_, _ = fmt.Fprintf(r.stdout, "  Retargeted PR #%d (%s) to '%s'.\n", prNumber, branch, rename.To)
+		}
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, "Run 'so restack' on each stack to rebase onto the new base.")
+	return nil
+}
+
+// planRenames returns the explicit --from/--to migration or the detected ones.
+func (r *migrateBaseCmdRunner) planRenames(remoteName string) ([]git.BaseRename, error) {
+	if r.from == "" {
+		if r.to != "" {
+			return nil, fmt.Errorf("--to requires --from")
+		}
+		return git.DetectBaseRenames(remoteName)
+	}
+
+	to := r.to
+	if to == "" {
+		detected, err := git.GetRemoteDefaultBranch(remoteName)
+		if err != nil {
+			return nil, err
+		}
+		to = detected
+	}
+	if to == r.from {
+		return nil, fmt.Errorf("old and new base are both '%s'", to)
+	}
+
+	meta, err := git.ReadSocleMetadata()
+	if err != nil {
+		return nil, err
+	}
+	rename := git.BaseRename{From: r.from, To: to}
+	for key, values := range meta {
+		branch, name, ok := git.ParseBranchConfigKey(key)
+		if !ok || name != "socle-base" || len(values) == 0 || values[len(values)-1] != r.from {
+			continue
+		}
+		rename.Branches = append(rename.Branches, branch)
+	}
+	if len(rename.Branches) == 0 {
+		return nil, nil
+	}
+	return []git.BaseRename{rename}, nil
+}
+
+// ensureLocalBranch creates branch from <remote>/<branch> when it only exists remotely.
+func (r *migrateBaseCmdRunner) ensureLocalBranch(branch, remoteName string) error {
+	exists, err := git.BranchExists(branch)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if err := git.CreateBranch(branch, remoteName+"/"+branch); err != nil {
+		return fmt.Errorf("new base '%s' does not exist locally or on '%s': %w", branch, remoteName, err)
+	}
+	r.logger.Debug("Created local base branch from remote", "branch", branch, "remote", remoteName)
+	return nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renameBase simulates the default branch moving from oldName to newName, with
+// origin/HEAD pointing at the new name.
+func renameBase(t *testing.T, repoPath, oldName, newName string) {
+	t.Helper()
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "git@github.com:example/repo.git")
+	testutils.RunCommand(t, repoPath, "git", "branch", "-m", oldName, newName)
+	testutils.RunCommand(t, repoPath, "git", "update-ref", "refs/remotes/origin/"+newName, newName)
+	testutils.RunCommand(t, repoPath, "git", "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/"+newName)
+}
+
+func TestMigrateBaseCommand(t *testing.T) {
+	t.Run("Commands point to migrate-base when the base is gone", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		renameBase(t, repoPath, "main", "trunk")
+
+		_, _, err := runSoCommandWithOutput(t, "log")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, git.ErrBaseBranchMissing), "expected ErrBaseBranchMissing, got: %v", err)
+		assert.Contains(t, err.Error(), "so migrate-base")
+	})
+
+	t.Run("Detected rename rewrites metadata and retargets PRs", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		require.NoError(t, git.SetStoredPRNumber("feature-a", 11))
+		require.NoError(t, git.SetStoredPRNumber("feature-b", 12))
+		renameBase(t, repoPath, "main", "trunk")
+
+		mockClient := gh.NewMockClient()
+		mockClient.On("UpdatePullRequestBase", 11, "trunk").Return(&github.PullRequest{Number: github.Ptr(11)}, nil)
+		originalCreateClient := gh.CreateClient
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		t.Cleanup(func() { gh.CreateClient = originalCreateClient })
+
+		resetCommandFlags(t, migrateBaseCmd, "yes")
+		stdout, _, err := runSoCommandWithOutput(t, "migrate-base", "--yes")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "Base 'main' -> 'trunk' (2 tracked branch(es))")
+		assert.Contains(t, stdout, "Retargeted PR #11 (feature-a) to 'trunk'")
+
+		for _, branch := range []string{"feature-a", "feature-b"} {
+			base, err := git.GetGitConfig("branch." + branch + ".socle-base")
+			require.NoError(t, err)
+			assert.Equal(t, "trunk", base, "base of %s", branch)
+		}
+		parentA, _ := git.GetGitConfig("branch.feature-a.socle-parent")
+		parentB, _ := git.GetGitConfig("branch.feature-b.socle-parent")
+		assert.Equal(t, "trunk", parentA)
+		assert.Equal(t, "feature-a", parentB)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Non-interactive run requires --yes", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		renameBase(t, repoPath, "main", "trunk")
+
+		_, _, err := runSoCommandWithOutput(t, "--non-interactive", "migrate-base")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rerun with --yes")
+
+		base, _ := git.GetGitConfig("branch.feature-a.socle-base")
+		assert.Equal(t, "main", base, "metadata must be untouched")
+	})
+
+	t.Run("Nothing to do when all bases exist", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		resetCommandFlags(t, migrateBaseCmd, "yes")
+		stdout, _, err := runSoCommandWithOutput(t, "migrate-base", "--yes")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "Nothing to migrate")
+	})
+}
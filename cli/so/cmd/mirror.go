@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror <base> | --status",
+	Short: "Keep a copy of the current stack on a second base, such as a release branch",
+	Long: `Tracks the current stack against a second base: every branch gets a mirror
+on <base>, stacked like the original, so a hotfix stack can be submitted as one
+PR chain against main and another against release/1.2.
+
+The first run creates 'backport/<base>/<branch>' for each branch (reusing
+the 'so backport' naming), cherry-picks the branch's commits onto it with
+'git cherry-pick -x' and tracks it on the previous mirror with <base> as its
+stack base. Later runs pick only the commits a mirror is still missing, so
+amend or add commits on the original stack, run 'so mirror <base>' again and
+'so submit' on the mirror chain. 'so restack' works on either chain.
+
+If a commit does not apply, the cherry-pick pauses like a restack does:
+resolve the conflicts, run 'git cherry-pick --continue' and then 'so mirror
+<base>' again.
+
+--status lists each branch with its mirrors and their PRs, and warns when a
+pair has diverged: commits on one side without an equivalent change on the
+other. 'so submit' warns about diverged pairs too, and the stack comment links
+each PR to its mirror's PR.
+
+  so mirror release/1.2
+  so mirror --status`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if status, _ := cmd.Flags().GetBool("status"); status {
+			return cobra.NoArgs(cmd, args)
+		}
+		if len(args) != 1 {
+			return fmt.Errorf("requires the base to mirror onto (or --status)")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, _ := cmd.Flags().GetBool("status")
+		noFetch, _ := cmd.Flags().GetBool("no-fetch")
+		if !noFetch {
+			var err error
+			if noFetch, err = git.GetSocleConfigBool("socle.noFetch", false); err != nil {
+				return err
+			}
+		}
+
+		runner := &mirrorCmdRunner{
+			logger:  slog.Default(),
+			stdout:  cmd.OutOrStdout(),
+			stderr:  cmd.ErrOrStderr(),
+			noFetch: noFetch,
+		}
+		if status {
+			return runner.status()
+		}
+		return runner.run(args[0])
+	},
+}
+
+func init() {
+	AddCommand(mirrorCmd)
+	mirrorCmd.Flags().Bool("status", false, "List the stack's mirrors and warn about diverged pairs")
+	mirrorCmd.Flags().Bool("no-fetch", false, "Do not fetch the base first (default from socle.noFetch)")
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type mirrorCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	noFetch bool
+}
+
+// mirrorPair is a stack branch and its mirror on one base.
+type mirrorPair struct {
+	source, sourceParent string
+	mirror, mirrorParent string
+	base                 string
+}
+
+// divergence lists the commits of each side that the other lacks.
+func (p mirrorPair) divergence() (onlySource, onlyMirror []git.Commit, err error) {
+	if onlySource, err = git.CommitsMissingFrom(p.mirror, p.source, p.sourceParent); err != nil {
+		return nil, nil, err
+	}
+	if onlyMirror, err = git.CommitsMissingFrom(p.source, p.mirror, p.mirrorParent); err != nil {
+		return nil, nil, err
+	}
+	return onlySource, onlyMirror, nil
+}
+
+func (r *mirrorCmdRunner) run(base string) error {
+	if git.IsCherryPickInProgress() {
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Git cherry-pick already in progress."))
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Resolve conflicts and run 'git cherry-pick --continue' or cancel with 'git cherry-pick --abort'."))
+		_, _ = fmt.Fprintf(r.stderr, "%s\n", ui.Colors.InfoStyle.Render(fmt.Sprintf("Once the cherry-pick is finished, run 'so mirror %s' again.", base)))
+		return socleerr.New(socleerr.Conflict, "a cherry-pick is already in progress")
+	}
+	hasChanges, err := git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if hasChanges {
+		return socleerr.New(socleerr.DirtyWorktree, "uncommitted changes detected. Please commit or stash them before mirroring")
+	}
+
+	stack, err := r.sourceStack()
+	if err != nil {
+		return err
+	}
+	if stack[0] == base {
+		return fmt.Errorf("the stack is already based on '%s'; mirror it onto another base", base)
+	}
+	startOID, err := prepareReleaseBase(r.logger, base, git.GetRemoteName(), r.noFetch)
+	if err != nil {
+		return err
+	}
+
+	originalBranch, _ := git.GetCurrentBranch()
+	_, _ = fmt.Fprintf(r.stdout, "Mirroring %d branch(es) onto '%s':\n", len(stack)-1, base)
+	mirrorParent := base
+	var needsRestack []string
+	for i := 1; i < len(stack); i++ {
+		source := stack[i]
+		mirrors, err := git.GetMirrors(source)
+		if err != nil {
+			return err
+		}
+		mirror, known := mirrors[base]
+		if !known {
+			mirror = git.MirrorBranchName(base, source)
+		}
+		exists, err := git.BranchExists(mirror)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			start := startOID
+			if mirrorParent != base {
+				if start, err = git.GetCurrentBranchCommit(mirrorParent); err != nil {
+					return err
+				}
+			}
+			if err := r.createMirror(source, mirror, mirrorParent, base, start); err != nil {
+				return err
+			}
+		} else {
+			if !known {
+				return fmt.Errorf("'%s' already exists but is not a mirror of '%s'; rename it first", mirror, source)
+			}
+			if stale, err := git.NeedsRestack(mirrorParent, mirror); err == nil && stale {
+				needsRestack = append(needsRestack, mirror)
+			}
+		}
+
+		missing, err := git.CommitsMissingFrom(mirror, source, stack[i-1])
+		if err != nil {
+			return err
+		}
+		if len(missing) == 0 {
+			_, _ = fmt.Fprintf(r.stdout, "  %s → %s %s\n", source, mirror, ui.Colors.MutedStyle.Render("(up to date)"))
+		} else {
+			_, _ = fmt.Fprintf(r.stdout, "  %s → %s %s\n", source, mirror, ui.Colors.MutedStyle.Render(fmt.Sprintf("(picking %d commit(s))", len(missing))))
+			if err := git.CheckoutBranch(mirror); err != nil {
+				return err
+			}
+			if err := git.CherryPickCommits(missing); err != nil {
+				if errors.Is(err, git.ErrCherryPickConflict) {
+					r.printConflictHelp(mirror, base)
+					return socleerr.New(socleerr.Conflict, "mirror paused on a conflict in '%s'", mirror) // The user needs to use Git
+				}
+				return err
+			}
+		}
+		mirrorParent = mirror
+	}
+
+	if originalBranch != "" {
+		if current, _ := git.GetCurrentBranch(); current != originalBranch {
+			if err := git.CheckoutBranch(originalBranch); err != nil {
+				_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: Failed to checkout original branch '%s': %v", originalBranch, err)))
+			}
+		}
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ The stack is mirrored onto '%s'.", base)))
+	if len(needsRestack) > 0 {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render(fmt.Sprintf("%s no longer sit on their parents; check out '%s' and run 'so restack'.", strings.Join(needsRestack, ", "), mirrorParent)))
+	}
+	_, _ = fmt.Fprintf(r.stdout, "Check out '%s' and run 'so submit' to open or update the PRs against '%s'.\n", mirrorParent, base)
+	return nil
+}
+
+func (r *mirrorCmdRunner) createMirror(source, mirror, parent, base, start string) error {
+	if err := git.IsValidBranchName(mirror); err != nil {
+		return fmt.Errorf("cannot use '%s' as the mirror of '%s': %w", mirror, source, err)
+	}
+	if err := git.CreateBranch(mirror, start); err != nil {
+		return err
+	}
+	if err := git.SetGitConfig(git.BranchConfigKey(mirror, "socle-parent"), parent); err != nil {
+		return fmt.Errorf("failed to set socle-parent config for '%s': %w", mirror, err)
+	}
+	if err := git.SetGitConfig(git.BranchConfigKey(mirror, "socle-base"), base); err != nil {
+		return fmt.Errorf("failed to set socle-base config for '%s': %w", mirror, err)
+	}
+	if err := git.SetMirror(source, base, mirror); err != nil {
+		return fmt.Errorf("failed to record '%s' as the mirror of '%s': %w", mirror, source, err)
+	}
+	r.logger.Debug("Created mirror", "source", source, "mirror", mirror, "parent", parent)
+	return nil
+}
+
+// sourceStack returns the current stack, or the stack a mirror chain copies
+// when a mirror is checked out.
+func (r *mirrorCmdRunner) sourceStack() ([]string, error) {
+	current, err := git.GetCurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if source, err := git.GetMirrorSource(current); err == nil && source != "" {
+		return nil, fmt.Errorf("'%s' is a mirror of '%s'; run this on the original stack", current, source)
+	}
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return nil, err
+	}
+	if len(stackInfo.FullStack) <= 1 {
+		return nil, fmt.Errorf("not on a stack: check out a tracked branch of the stack first")
+	}
+	return stackInfo.FullStack, nil
+}
+
+// status prints every branch of the stack with its mirrors, their PRs and
+// whether the pair has diverged.
+func (r *mirrorCmdRunner) status() error {
+	stack, err := r.sourceStack()
+	if err != nil {
+		return err
+	}
+	pairs, err := mirrorPairs(stack)
+	if err != nil {
+		return err
+	}
+	if len(pairs) == 0 {
+		_, _ = fmt.Fprintln(r.stdout, "No branch of this stack is mirrored. Use 'so mirror <base>' to add mirrors.")
+		return nil
+	}
+
+	for _, p := range pairs {
+		line := fmt.Sprintf("%s%s ↔ %s%s", p.source, prSuffix(p.source), p.mirror, prSuffix(p.mirror))
+		exists, err := git.BranchExists(p.mirror)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			_, _ = fmt.Fprintf(r.stdout, "%s  %s\n", line, ui.Colors.WarningStyle.Render("mirror branch is missing"))
+			continue
+		}
+		onlySource, onlyMirror, err := p.divergence()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(r.stdout, "%s  %s\n", line, divergenceText(p, onlySource, onlyMirror))
+	}
+	return nil
+}
+
+// mirrorPairs lists the mirrors of the stack's branches, grouped by base in
+// stack order.
+func mirrorPairs(stack []string) ([]mirrorPair, error) {
+	byBase := make(map[string][]mirrorPair)
+	for i := 1; i < len(stack); i++ {
+		mirrors, err := git.GetMirrors(stack[i])
+		if err != nil {
+			return nil, err
+		}
+		for base, mirror := range mirrors {
+			parent, _ := git.GetGitConfig(git.BranchConfigKey(mirror, "socle-parent"))
+			if parent == "" {
+				parent = base
+			}
+			byBase[base] = append(byBase[base], mirrorPair{source: stack[i], sourceParent: stack[i-1], mirror: mirror, mirrorParent: parent, base: base})
+		}
+	}
+	bases := make([]string, 0, len(byBase))
+	for base := range byBase {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+	var pairs []mirrorPair
+	for _, base := range bases {
+		pairs = append(pairs, byBase[base]...)
+	}
+	return pairs, nil
+}
+
+// stackMirrorPairs lists the pairs a stack takes part in: the mirrors of its
+// branches and, on a mirror chain, the sources of its branches.
+func stackMirrorPairs(stack []string) ([]mirrorPair, error) {
+	pairs, err := mirrorPairs(stack)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(stack); i++ {
+		source, err := git.GetMirrorSource(stack[i])
+		if err != nil {
+			return nil, err
+		}
+		if source == "" {
+			continue
+		}
+		sourceParent, err := git.GetGitConfig(git.BranchConfigKey(source, "socle-parent"))
+		if err != nil {
+			continue // The source is no longer tracked; nothing to compare against
+		}
+		base, _ := git.GetGitConfig(git.BranchConfigKey(stack[i], "socle-base"))
+		pairs = append(pairs, mirrorPair{source: source, sourceParent: sourceParent, mirror: stack[i], mirrorParent: stack[i-1], base: base})
+	}
+	return pairs, nil
+}
+
+func prSuffix(branch string) string {
+	if n, err := git.GetStoredPRNumber(branch); err == nil && n > 0 {
+		return fmt.Sprintf(" #%d", n)
+	}
+	return ""
+}
+
+func divergenceText(p mirrorPair, onlySource, onlyMirror []git.Commit) string {
+	if len(onlySource) == 0 && len(onlyMirror) == 0 {
+		return ui.Colors.SuccessStyle.Render("in sync")
+	}
+	var parts []string
+	if len(onlySource) > 0 {
+		parts = append(parts, fmt.Sprintf("%d commit(s) only on '%s'", len(onlySource), p.source))
+	}
+	if len(onlyMirror) > 0 {
+		parts = append(parts, fmt.Sprintf("%d commit(s) only on '%s'", len(onlyMirror), p.mirror))
+	}
+	return ui.Colors.WarningStyle.Render("diverged: " + strings.Join(parts, ", "))
+}
+
+func (r *mirrorCmdRunner) printConflictHelp(branch, base string) {
+	_, _ = fmt.Fprintln(r.stderr, "")
+	_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render("⚠️ Mirroring paused due to conflicts."))
+	_, _ = fmt.Fprintf(r.stderr, "Please resolve the conflicts in branch '%s' and then run:\n", branch)
+	_, _ = fmt.Fprintln(r.stderr, "  1. Run 'git add <resolved-files...>'.")
+	_, _ = fmt.Fprintln(r.stderr, "  2. Run 'git cherry-pick --continue' ('git cherry-pick --skip' drops a commit the base already has).")
+	_, _ = fmt.Fprintln(r.stderr, "   (To cancel, run 'git cherry-pick --abort')")
+	_, _ = fmt.Fprintf(r.stderr, "   Once the cherry-pick is complete, run 'so mirror %s' again.\n", base)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorCommand(t *testing.T) {
+	resetCommandFlags(t, mirrorCmd, "status", "no-fetch")
+
+	// main -> feature-a -> feature-b, and release/1.2 cut from main on origin.
+	setup := func(t *testing.T) string {
+		resetCommandFlags(t, mirrorCmd, "status", "no-fetch")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		t.Cleanup(cleanup)
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--quiet", "--bare", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "main", "main:refs/heads/release/1.2")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "--quiet", "feature-b")
+		return repoPath
+	}
+	mirrorA, mirrorB := "backport/release/1.2/feature-a", "backport/release/1.2/feature-b"
+
+	t.Run("Mirrors the stack as a chain on the release base", func(t *testing.T) {
+		setup(t)
+
+		stdout, _, err := runSoCommandWithOutput(t, "mirror", "release/1.2")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "feature-a → "+mirrorA+" (picking 1 commit(s))")
+		assert.Contains(t, out, "✓ The stack is mirrored onto 'release/1.2'.")
+
+		current, _ := git.GetCurrentBranch()
+		assert.Equal(t, "feature-b", current)
+		parentA, _ := git.GetGitConfig(git.BranchConfigKey(mirrorA, "socle-parent"))
+		parentB, _ := git.GetGitConfig(git.BranchConfigKey(mirrorB, "socle-parent"))
+		base, _ := git.GetGitConfig(git.BranchConfigKey(mirrorB, "socle-base"))
+		assert.Equal(t, "release/1.2", parentA)
+		assert.Equal(t, mirrorA, parentB)
+		assert.Equal(t, "release/1.2", base)
+		mirrors, _ := git.GetMirrors("feature-b")
+		assert.Equal(t, map[string]string{"release/1.2": mirrorB}, mirrors)
+		source, _ := git.GetMirrorSource(mirrorB)
+		assert.Equal(t, "feature-b", source)
+
+		stdout, _, err = runSoCommandWithOutput(t, "mirror", "--status")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "feature-b ↔ "+mirrorB+"  in sync")
+	})
+
+	t.Run("Picks only new commits and reports divergence", func(t *testing.T) {
+		repoPath := setup(t)
+		_, _, err := runSoCommandWithOutput(t, "mirror", "release/1.2")
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, "more.txt"), []byte("more\n"), 0o644))
+		testutils.RunCommand(t, repoPath, "git", "add", "more.txt")
+		testutils.RunCommand(t, repoPath, "git", "commit", "--quiet", "-m", "feat: more on feature-b")
+
+		resetCommandFlags(t, mirrorCmd, "status", "no-fetch")
+		stdout, _, err := runSoCommandWithOutput(t, "mirror", "--status")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "diverged: 1 commit(s) only on 'feature-b'")
+
+		resetCommandFlags(t, mirrorCmd, "status", "no-fetch")
+		stdout, _, err = runSoCommandWithOutput(t, "mirror", "release/1.2", "--no-fetch")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "feature-a → "+mirrorA+" (up to date)")
+		assert.Contains(t, out, "feature-b → "+mirrorB+" (picking 1 commit(s))")
+
+		resetCommandFlags(t, mirrorCmd, "status", "no-fetch")
+		stdout, _, err = runSoCommandWithOutput(t, "mirror", "--status")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "feature-b ↔ "+mirrorB+"  in sync")
+	})
+
+	t.Run("Refuses the stack's own base", func(t *testing.T) {
+		setup(t)
+		_, _, err := runSoCommandWithOutput(t, "mirror", "main")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already based on 'main'")
+	})
+}
@@ -1,19 +1,93 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"io"
+	"os"
+	"slices"
+	"sort"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
 )
 
 // checkoutBranch wraps git.CheckoutBranch with common error message logic.
 func checkoutBranch(target string, current string) error {
 	if err := git.CheckoutBranch(target); err != nil {
-		if strings.Contains(err.Error(), "Please commit your changes or stash them") {
-			return fmt.Errorf("cannot checkout branch '%s': uncommitted changes detected in '%s'. Please commit or stash them first", target, current)
+		if errors.Is(err, git.ErrLocalChanges) {
+			return socleerr.New(socleerr.DirtyWorktree, "cannot checkout branch '%s': uncommitted changes detected in '%s'. Please commit or stash them first", target, current)
 		}
 		return fmt.Errorf("failed to checkout branch '%s': %w", target, err)
 	}
 	return nil
 }
+
+// childSelector chooses between the children of a branch where a stack forks.
+// The hidden test flags take precedence over the prompt, mirroring the stack
+// selection on base branches.
+type childSelector struct {
+	stdin          io.Reader
+	stderr         io.Writer
+	nonInteractive bool
+	testIndex      int
+	testChild      string
+}
+
+// selectChild returns the child of branch to continue with. Children are
+// offered in name order.
+func (s childSelector) selectChild(branch string, children []string) (string, error) {
+	options := slices.Clone(children)
+	sort.Strings(options)
+
+	if s.testChild != "" {
+		if !slices.Contains(options, s.testChild) {
+			return "", fmt.Errorf("test-select-stack-child '%s' is not a child of '%s'", s.testChild, branch)
+		}
+		return s.testChild, nil
+	}
+	if s.testIndex >= 0 {
+		if s.testIndex >= len(options) {
+			return "", fmt.Errorf("test-select-stack-index %d out of range (have %d children)", s.testIndex, len(options))
+		}
+		return options[s.testIndex], nil
+	}
+	if s.nonInteractive {
+		return "", fmt.Errorf("multiple branches stack on '%s' (%v); check out the one to follow before running this command in non-interactive mode", branch, options)
+	}
+
+	var selected string
+	prompt := &survey.Select{Message: fmt.Sprintf("Multiple branches stack on '%s'. Select a branch:", branch), Options: options}
+	err := ui.AskOne(prompt, &selected, survey.WithStdio(s.stdin.(*os.File), s.stderr.(*os.File), s.stderr.(*os.File)))
+	if err != nil {
+		return "", ui.HandleSurveyInterrupt(err, "Navigation cancelled.")
+	}
+	return selected, nil
+}
+
+// walkToTip follows the children of branch up to the tip of its lineage,
+// asking at every fork which branch to follow.
+func (s childSelector) walkToTip(branch string, childMap map[string][]string) (string, error) {
+	visited := map[string]bool{branch: true}
+	for {
+		children := childMap[branch]
+		switch len(children) {
+		case 0:
+			return branch, nil
+		case 1:
+			branch = children[0]
+		default:
+			next, err := s.selectChild(branch, children)
+			if err != nil {
+				return "", err
+			}
+			branch = next
+		}
+		if visited[branch] {
+			return "", fmt.Errorf("cycle detected in stack tracking near branch '%s'", branch)
+		}
+		visited[branch] = true
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open <pr>",
+	Short: "Check out the branch of a pull request, given its number or URL",
+	Long: `Jumps to the code of a pull request: resolves its head branch, fetches it
+from the remote if there is no local branch yet, and checks it out.
+
+A branch socle does not track yet is tracked on the PR's base branch, and the
+PR number is recorded, so 'so log', 'so up'/'so down' and 'so submit' work on
+it right away. An existing local branch is checked out as it is; run 'so sync'
+to update it. PRs from forks cannot be opened.
+
+  so open 1234
+  so open https://github.com/acme/app/pull/1234
+
+Use 'so adopt-upstream' to check out the whole stack below a PR.`,
+	Args: cobra.ExactArgs(1),
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		runner := &openCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+		}
+		return runner.run(cmd.Context(), args[0])
+	}),
+}
+
+func init() {
+	AddCommand(openCmd)
+}
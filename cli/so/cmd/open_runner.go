@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type openCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (r *openCmdRunner) run(ctx context.Context, reference string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	remoteName := git.GetRemoteName()
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return fmt.Errorf("cannot get remote URL for '%s': %w", remoteName, err)
+	}
+	owner, repoName, err := git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", remoteName, remoteURL, err)
+	}
+	number, err := parsePRReference(reference, owner, repoName)
+	if err != nil {
+		return err
+	}
+
+	ghClient, err := gh.CreateClient(ctx, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	pr, err := ghClient.GetPullRequest(number)
+	if err != nil {
+		return err
+	}
+	repoFullName := owner + "/" + repoName
+	if headRepo := pr.GetHead().GetRepo().GetFullName(); headRepo != "" && !strings.EqualFold(headRepo, repoFullName) {
+		return fmt.Errorf("PR #%d comes from the fork '%s'; only branches of '%s' can be opened", number, headRepo, repoFullName)
+	}
+	head, base := pr.GetHead().GetRef(), pr.GetBase().GetRef()
+	r.logger.Debug("Resolved PR", "pr", number, "head", head, "base", base)
+
+	if err := r.ensureLocalBranches(remoteName, head, base); err != nil {
+		return err
+	}
+	if err := r.ensureTracked(remoteName, head, base, number); err != nil {
+		return err
+	}
+
+	if current, _ := git.GetCurrentBranch(); current != head {
+		if err := git.CheckoutBranch(head); err != nil {
+			if errors.Is(err, git.ErrLocalChanges) {
+				return fmt.Errorf("%w; commit or stash them and run 'so open %s' again", err, reference)
+			}
+			return err
+		}
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Checked out '%s'", head))+
+		ui.Colors.MutedStyle.Render(fmt.Sprintf(" #%d %s", number, pr.GetTitle())))
+	return nil
+}
+
+// ensureLocalBranches creates local branches for head and, so the branch can
+// be restacked, its base, fetching whichever does not exist locally yet.
+func (r *openCmdRunner) ensureLocalBranches(remoteName, head, base string) error {
+	var missing []string
+	for _, branch := range []string{base, head} {
+		exists, err := git.BranchExists(branch)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, branch)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "Fetching %s from '%s'...\n", strings.Join(missing, ", "), remoteName)
+	if err := git.FetchRemoteBranches(remoteName, missing); err != nil {
+		return err
+	}
+	for _, branch := range missing {
+		if err := git.CreateTrackingBranch(branch, remoteName, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureTracked tracks head on base unless socle already tracks it, and
+// records the PR number.
+func (r *openCmdRunner) ensureTracked(remoteName, head, base string, number int) error {
+	parentKey := git.BranchConfigKey(head, "socle-parent")
+	if parent, err := git.GetGitConfig(parentKey); err == nil && parent != "" {
+		r.logger.Debug("Branch already tracked", "branch", head, "parent", parent)
+	} else if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
+		return fmt.Errorf("failed to read socle-parent config for '%s': %w", head, err)
+	} else {
+		stackBase, err := r.stackBaseFor(remoteName, base)
+		if err != nil {
+			return err
+		}
+		if err := git.SetGitConfig(parentKey, base); err != nil {
+			return fmt.Errorf("failed to set socle-parent config for '%s': %w", head, err)
+		}
+		if err := git.SetGitConfig(git.BranchConfigKey(head, "socle-base"), stackBase); err != nil {
+			_ = git.UnsetGitConfig(parentKey)
+			return fmt.Errorf("failed to set socle-base config for '%s': %w", head, err)
+		}
+		_, _ = fmt.Fprintf(r.stdout, "Tracking branch '%s' with parent '%s' and base '%s'.\n", head, base, stackBase)
+	}
+
+	stored, err := git.GetStoredPRNumber(head)
+	if err != nil {
+		return fmt.Errorf("failed to read PR number for '%s': %w", head, err)
+	}
+	if stored != number {
+		if err := git.SetStoredPRNumber(head, number); err != nil {
+			return fmt.Errorf("failed to store PR number for '%s': %w", head, err)
+		}
+	}
+	return nil
+}
+
+// stackBaseFor returns the stack base of a branch whose PR targets parent:
+// parent itself when it is a base branch, else the base parent is tracked on.
+// For an untracked parent it assumes the remote's default branch, as 'so
+// track' would.
+func (r *openCmdRunner) stackBaseFor(remoteName, parent string) (string, error) {
+	if git.KnownBaseBranchSet()[parent] {
+		return parent, nil
+	}
+	inherited, err := git.GetGitConfig(git.BranchConfigKey(parent, "socle-base"))
+	if err == nil && inherited != "" {
+		return inherited, nil
+	}
+	if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
+		return "", fmt.Errorf("failed to check tracking base for parent branch '%s': %w", parent, err)
+	}
+	assumed, errDefault := git.GetRemoteDefaultBranch(remoteName)
+	if errDefault != nil {
+		r.logger.Debug("Falling back to the default base branch", "error", errDefault)
+		assumed = defaultBaseBranch
+	}
+	_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf(
+		"Warning: Parent branch '%s' is not tracked. Assuming stack base is '%s'; run 'so open' on its PR first for an accurate stack.", parent, assumed)))
+	return assumed, nil
+}
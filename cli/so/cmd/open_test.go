@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	// feat-1 (on main) and feat-2 (on feat-1) exist only on origin.
+	setup := func(t *testing.T) (string, *gh.MockClient) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		t.Cleanup(cleanup)
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--quiet", "--bare", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "main")
+		for _, b := range []string{"feat-1", "feat-2"} {
+			testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", b)
+			writeFile(t, repoPath, b+".txt", b)
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "Add "+b)
+			testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", b)
+		}
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		testutils.RunCommand(t, repoPath, "git", "branch", "-q", "-D", "feat-1", "feat-2")
+		testutils.RunCommand(t, repoPath, "git", "update-ref", "-d", "refs/remotes/origin/feat-1")
+		testutils.RunCommand(t, repoPath, "git", "update-ref", "-d", "refs/remotes/origin/feat-2")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		return repoPath, mockClient
+	}
+	pr := func(number int, head, base, headRepo string) *github.PullRequest {
+		return &github.PullRequest{
+			Number: github.Ptr(number),
+			Title:  github.Ptr("Add " + head),
+			Head:   &github.PullRequestBranch{Ref: github.Ptr(head), Repo: &github.Repository{FullName: github.Ptr(headRepo)}},
+			Base:   &github.PullRequestBranch{Ref: github.Ptr(base)},
+		}
+	}
+
+	t.Run("Fetches, tracks and checks out the PR branch", func(t *testing.T) {
+		_, mockClient := setup(t)
+		mockClient.On("GetPullRequest", 12).Return(pr(12, "feat-2", "feat-1", "test-owner/test-repo"), nil)
+
+		stdout, stderr, err := runSoCommandWithOutput(t, "open", "https://github.com/test-owner/test-repo/pull/12")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "Fetching feat-1, feat-2 from 'origin'...")
+		assert.Contains(t, out, "Tracking branch 'feat-2' with parent 'feat-1' and base 'main'.")
+		assert.Contains(t, stderr, "Parent branch 'feat-1' is not tracked. Assuming stack base is 'main'")
+		assert.Contains(t, out, "✓ Checked out 'feat-2' #12 Add feat-2")
+
+		current, _ := git.GetCurrentBranch()
+		assert.Equal(t, "feat-2", current)
+		exists, _ := git.BranchExists("feat-1")
+		assert.True(t, exists, "the base branch is created so the PR branch can be restacked")
+		prNumber, _ := git.GetStoredPRNumber("feat-2")
+		assert.Equal(t, 12, prNumber)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Keeps the tracking of a known branch and inherits the stack base", func(t *testing.T) {
+		repoPath, mockClient := setup(t)
+		mockClient.On("GetPullRequest", 11).Return(pr(11, "feat-1", "main", "test-owner/test-repo"), nil)
+		mockClient.On("GetPullRequest", 12).Return(pr(12, "feat-2", "feat-1", "test-owner/test-repo"), nil)
+
+		_, _, err := runSoCommandWithOutput(t, "open", "11")
+		require.NoError(t, err)
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+
+		stdout, stderr, err := runSoCommandWithOutput(t, "open", "#12")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Tracking branch 'feat-2' with parent 'feat-1' and base 'main'.")
+		assert.NotContains(t, stderr, "is not tracked")
+		assert.NotContains(t, stdout, "Fetching feat-1", "existing local branches are not fetched again")
+
+		// A second open only checks out
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		stdout, _, err = runSoCommandWithOutput(t, "open", "12")
+		require.NoError(t, err)
+		assert.NotContains(t, stdout, "Tracking branch")
+		assert.NotContains(t, stdout, "Fetching")
+		current, _ := git.GetCurrentBranch()
+		assert.Equal(t, "feat-2", current)
+	})
+
+	t.Run("Rejects PRs from forks", func(t *testing.T) {
+		_, mockClient := setup(t)
+		mockClient.On("GetPullRequest", 13).Return(pr(13, "patch-1", "main", "someone/test-repo"), nil)
+
+		_, _, err := runSoCommandWithOutput(t, "open", "13")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "comes from the fork 'someone/test-repo'")
+	})
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/plugin"
+	"github.com/spf13/cobra"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Manage external subcommands",
+	Long: `Groups commands about plugins: executables named 'so-<name>' on PATH,
+which run as 'so <name>', like git and gh extensions. Built-in commands
+always take precedence over plugins of the same name.
+
+A plugin receives its arguments unchanged and these environment variables:
+  SOCLE_VERSION      The version of so running the plugin
+  SOCLE_EXECUTABLE   The path of the so executable, for calling back
+  SOCLE_REPO_ROOT    The top-level directory of the repository
+  SOCLE_BRANCH       The checked out branch
+  SOCLE_STACK_FILE   A JSON file describing the current stack:
+                     {"currentBranch", "baseBranch", "branches": [{"branch",
+                     "parent", "prNumber", "current"}, ...]}, bottom first
+The repository variables are only set inside a git repository, and the stack
+file only on a tracked branch or a base branch. The file is removed when the
+plugin exits.`,
+	Args: cobra.NoArgs,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List the plugins found on PATH",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationNoRepo: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &pluginsListCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			root:   cmd.Root(),
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	pluginsCmd.AddCommand(pluginsListCmd)
+	AddCommand(pluginsCmd)
+}
+
+// addPluginCommand registers the plugin args invoke as a subcommand of root,
+// unless args name a built-in command. Only the invoked plugin is looked up,
+// so ordinary commands don't pay for scanning PATH.
+func addPluginCommand(root *cobra.Command, args []string) {
+	name := ""
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			name = arg
+			break
+		}
+	}
+	if name == "" || name == "help" || name == "completion" {
+		return
+	}
+	if found, _, err := root.Find(args); err == nil && found != root {
+		return
+	}
+	p, ok := plugin.Lookup(name)
+	if !ok {
+		return
+	}
+	root.AddCommand(&cobra.Command{
+		Use:                p.Name,
+		Short:              "Plugin " + p.Path,
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{annotationNoRepo: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runner := &pluginCmdRunner{
+				logger: slog.Default(),
+				stdin:  cmd.InOrStdin(),
+				stdout: cmd.OutOrStdout(),
+				stderr: cmd.ErrOrStderr(),
+				plugin: p,
+			}
+			return runner.run(args)
+		},
+	})
+}
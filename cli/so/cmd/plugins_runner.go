@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/plugin"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+type pluginsListCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+	root   *cobra.Command
+}
+
+func (r *pluginsListCmdRunner) run() error {
+	plugins := plugin.Discover()
+	if len(plugins) == 0 {
+		_, _ = fmt.Fprintf(r.stdout, "No plugins found. Executables named '%s<name>' on PATH run as 'so <name>'.\n", plugin.Prefix)
+		return nil
+	}
+
+	width := 0
+	for _, p := range plugins {
+		width = max(width, len(p.Name))
+	}
+	for _, p := range plugins {
+		line := fmt.Sprintf("%-*s  %s", width, p.Name, p.Path)
+		if found, _, err := r.root.Find([]string{p.Name}); err == nil && found != r.root {
+			line += ui.Colors.MutedStyle.Render("  (shadowed by the built-in command)")
+		}
+		_, _ = fmt.Fprintln(r.stdout, line)
+	}
+	return nil
+}
+
+// pluginExitError carries a plugin's exit status, which so exits with
+// without adding a message of its own.
+type pluginExitError struct {
+	name string
+	code int
+}
+
+func (e *pluginExitError) Error() string {
+	return fmt.Sprintf("plugin '%s' exited with status %d", e.name, e.code)
+}
+
+type pluginCmdRunner struct {
+	logger *slog.Logger
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	plugin plugin.Plugin
+}
+
+// pluginStack is the JSON in SOCLE_STACK_FILE.
+type pluginStack struct {
+	CurrentBranch string         `json:"currentBranch"`
+	BaseBranch    string         `json:"baseBranch"`
+	Branches      []pluginBranch `json:"branches"` // Bottom of the stack first
+}
+
+type pluginBranch struct {
+	Branch   string `json:"branch"`
+	Parent   string `json:"parent"`
+	PRNumber int    `json:"prNumber,omitempty"`
+	Current  bool   `json:"current"`
+}
+
+func (r *pluginCmdRunner) run(args []string) error {
+	env := append(os.Environ(), "SOCLE_VERSION="+version)
+	if executable, err := os.Executable(); err == nil {
+		env = append(env, "SOCLE_EXECUTABLE="+executable)
+	}
+	if git.IsGitRepo() {
+		repoEnv, cleanup, err := r.repoEnv()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		env = append(env, repoEnv...)
+	}
+
+	r.logger.Debug("Running plugin", "name", r.plugin.Name, "path", r.plugin.Path, "args", args)
+	cmd := exec.Command(r.plugin.Path, args...)
+	cmd.Stdin = r.stdin
+	cmd.Stdout = r.stdout
+	cmd.Stderr = r.stderr
+	cmd.Env = env
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			return &pluginExitError{name: r.plugin.Name, code: exitErr.ExitCode()}
+		}
+		return fmt.Errorf("failed to run plugin '%s': %w", r.plugin.Path, err)
+	}
+	return nil
+}
+
+// repoEnv describes the repository and, where there is one, the current
+// stack. The returned cleanup removes the stack file.
+func (r *pluginCmdRunner) repoEnv() ([]string, func(), error) {
+	noop := func() {}
+	var env []string
+	if root, err := git.GetRepoRoot(); err == nil {
+		env = append(env, "SOCLE_REPO_ROOT="+root)
+	}
+	currentBranch, err := git.GetCurrentBranch()
+	if err != nil {
+		r.logger.Debug("No current branch for plugin", "error", err)
+		return env, noop, nil
+	}
+	env = append(env, "SOCLE_BRANCH="+currentBranch)
+
+	stackInfo, err := git.GetNavigationStackInfo()
+	if err != nil {
+		r.logger.Debug("No stack for plugin", "error", err)
+		return env, noop, nil
+	}
+	stack := stackInfo.FullStack
+	if stack == nil {
+		stack = stackInfo.CurrentStack
+	}
+	data := pluginStack{CurrentBranch: currentBranch, BaseBranch: stackInfo.BaseBranch, Branches: []pluginBranch{}}
+	for _, branch := range stack[1:] {
+		number, _ := git.GetStoredPRNumber(branch)
+		data.Branches = append(data.Branches, pluginBranch{
+			Branch:   branch,
+			Parent:   stackInfo.ParentMap[branch],
+			PRNumber: number,
+			Current:  branch == currentBranch,
+		})
+	}
+
+	file, err := os.CreateTemp("", "socle-stack-*.json")
+	if err != nil {
+		return nil, noop, fmt.Errorf("failed to create the stack file for plugin '%s': %w", r.plugin.Name, err)
+	}
+	cleanup := func() { _ = os.Remove(file.Name()) }
+	encodeErr := json.NewEncoder(file).Encode(data)
+	if closeErr := file.Close(); encodeErr == nil {
+		encodeErr = closeErr
+	}
+	if encodeErr != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("failed to write the stack file for plugin '%s': %w", r.plugin.Name, encodeErr)
+	}
+	return append(env, "SOCLE_STACK_FILE="+file.Name()), cleanup, nil
+}
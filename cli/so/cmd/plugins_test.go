@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installPlugin writes an executable so-<name> shell script into dir.
+func installPlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	path := filepath.Join(dir, "so-"+name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+}
+
+func TestPlugins(t *testing.T) {
+	setupPlugins := func(t *testing.T) {
+		binDir := t.TempDir()
+		installPlugin(t, binDir, "hello", `echo "args: $*"
+echo "branch: $SOCLE_BRANCH"
+cat "$SOCLE_STACK_FILE"
+`)
+		installPlugin(t, binDir, "fail", "exit 3\n")
+		installPlugin(t, binDir, "log", "echo plugin log\n")
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "so-notes.txt"), []byte("not executable"), 0o644))
+		t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+
+	t.Run("Runs so-<name> with its arguments and the current stack", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		setupPlugins(t)
+
+		stdout, _, err := runSoCommandWithOutput(t, "hello", "one", "--two")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "args: one --two")
+		assert.Contains(t, stdout, "branch: feature-b")
+		assert.Contains(t, stdout, `"baseBranch":"main"`)
+		assert.Contains(t, stdout, `{"branch":"feature-a","parent":"main","current":false}`)
+		assert.Contains(t, stdout, `{"branch":"feature-b","parent":"feature-a","current":true}`)
+	})
+
+	t.Run("Passes the plugin's exit status on", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		setupPlugins(t)
+
+		_, _, err := runSoCommandWithOutput(t, "fail")
+		var exitErr *pluginExitError
+		require.True(t, errors.As(err, &exitErr), "got %v", err)
+		assert.Equal(t, 3, exitErr.code)
+	})
+
+	t.Run("Built-in commands take precedence", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		setupPlugins(t)
+
+		stdout, _, err := runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		assert.NotContains(t, stdout, "plugin log")
+		assert.Contains(t, stdout, "feature-a")
+	})
+
+	t.Run("plugins list shows executables on PATH", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		setupPlugins(t)
+
+		stdout, _, err := runSoCommandWithOutput(t, "plugins", "list")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Regexp(t, `fail\s+\S+so-fail`, out)
+		assert.Regexp(t, `hello\s+\S+so-hello`, out)
+		assert.Regexp(t, `log\s+\S+so-log\s+\(shadowed by the built-in command\)`, out)
+		assert.NotContains(t, out, "so-notes.txt")
+	})
+}
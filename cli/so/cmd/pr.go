@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Manage the pull requests of the current stack",
+	Long: `Groups commands that operate on the GitHub pull requests belonging to the
+branches of the current stack.`,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	AddCommand(prCmd)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var prBaseCmd = &cobra.Command{
+	Use:   "base",
+	Short: "Show or override the branch the current branch's PR targets",
+	Long: `Shows the branch the PR of the current branch targets: normally its parent
+in the stack.
+
+With --override <branch>, 'so submit' opens or retargets the PR against that
+branch instead, e.g. against trunk while the parent is about to be folded
+away. Restack still rebases the branch onto its real parent. --clear goes
+back to targeting the parent.
+
+When the override's branch is renamed, the override follows it. When it is
+deleted, e.g. by 'so sync' after its PR merged, the override moves to that
+branch's parent, or is dropped once it names the branch's own parent.
+
+The override is stored in git config as branch.<name>.socle-pr-base-override.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &prBaseCmdRunner{
+			logger:   slog.Default(),
+			stdout:   cmd.OutOrStdout(),
+			stderr:   cmd.ErrOrStderr(),
+			override: cmd.Flag("override").Value.String(),
+			clear:    cmd.Flag("clear").Changed,
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	prCmd.AddCommand(prBaseCmd)
+	prBaseCmd.Flags().String("override", "", "Target this branch with the PR instead of the parent")
+	prBaseCmd.Flags().Bool("clear", false, "Remove the override and target the parent again")
+	prBaseCmd.MarkFlagsMutuallyExclusive("override", "clear")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type prBaseCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	override string // Branch to target instead of the parent
+	clear    bool   // Remove the override
+}
+
+func (r *prBaseCmdRunner) run() error {
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parent, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-parent"))
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", branch)
+		}
+		return fmt.Errorf("failed to check tracking status for branch '%s': %w", branch, err)
+	}
+
+	switch {
+	case r.clear:
+		if git.GetPRBaseOverride(branch) == "" {
+			_, _ = fmt.Fprintf(r.stdout, "The PR of '%s' has no base override; it targets '%s'.\n", branch, git.PRBaseFor(parent))
+			return nil
+		}
+		if err := git.SetPRBaseOverride(branch, ""); err != nil {
+			return fmt.Errorf("failed to remove the PR base override of '%s': %w", branch, err)
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ The PR of '%s' targets its parent '%s' again.", branch, git.PRBaseFor(parent))))
+	case r.override != "":
+		if err := validatePRBaseOverride(branch, r.override); err != nil {
+			return err
+		}
+		if err := git.SetPRBaseOverride(branch, r.override); err != nil {
+			return fmt.Errorf("failed to set the PR base override of '%s': %w", branch, err)
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ The PR of '%s' targets '%s'; restack keeps it on '%s'.", branch, r.override, parent)))
+	default:
+		if override := git.GetPRBaseOverride(branch); override != "" {
+			_, _ = fmt.Fprintf(r.stdout, "'%s' is stacked on '%s'; its PR targets '%s' (override).\n", branch, parent, override)
+		} else {
+			_, _ = fmt.Fprintf(r.stdout, "'%s' is stacked on '%s'; its PR targets '%s'.\n", branch, parent, git.PRBaseFor(parent))
+		}
+		return nil
+	}
+
+	if number, _ := git.GetStoredPRNumber(branch); number > 0 {
+		_, _ = fmt.Fprintf(r.stdout, "Run 'so submit' to retarget PR #%d.\n", number)
+	}
+	return nil
+}
+
+// validatePRBaseOverride checks that the PR of branch can target base: another
+// branch that exists locally.
+func validatePRBaseOverride(branch, base string) error {
+	if base == branch {
+		return fmt.Errorf("the PR of '%s' cannot target the branch itself", branch)
+	}
+	exists, err := git.BranchExists(base)
+	if err != nil {
+		return fmt.Errorf("failed to check if branch '%s' exists: %w", base, err)
+	}
+	if !exists {
+		return fmt.Errorf("cannot target '%s': no such local branch", base)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPRBaseCommand(t *testing.T) {
+	// Flags keep their values from one run of the command to the next.
+	resetCommandFlags(t, prBaseCmd, "override", "clear")
+
+	t.Run("Shows, sets and clears the override", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		stdout, _, err := runSoCommandWithOutput(t, "pr", "base")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "'feature-b' is stacked on 'feature-a'; its PR targets 'feature-a'.")
+
+		_, _, err = runSoCommandWithOutput(t, "pr", "base", "--override", "main")
+		require.NoError(t, err)
+		assert.Equal(t, "main", git.GetPRBaseOverride("feature-b"))
+		assert.Equal(t, "main", git.PRBaseOf("feature-b", "feature-a"))
+		parent, err := git.GetGitConfig("branch.feature-b.socle-parent")
+		require.NoError(t, err)
+		assert.Equal(t, "feature-a", parent, "restack keeps the real parent")
+
+		resetCommandFlags(t, prBaseCmd, "override", "clear")
+		stdout, _, err = runSoCommandWithOutput(t, "pr", "base")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "its PR targets 'main' (override)")
+
+		_, _, err = runSoCommandWithOutput(t, "pr", "base", "--override", "no-such-branch")
+		require.ErrorContains(t, err, "no such local branch")
+
+		resetCommandFlags(t, prBaseCmd, "override", "clear")
+		_, _, err = runSoCommandWithOutput(t, "pr", "base", "--clear")
+		require.NoError(t, err)
+		assert.Empty(t, git.GetPRBaseOverride("feature-b"))
+	})
+
+	t.Run("Submit opens the PR against the override", func(t *testing.T) {
+		originalCreateGHClient, originalStatusCachePath := gh.CreateClient, gh.StatusCachePath
+		t.Cleanup(func() { gh.CreateClient, gh.StatusCachePath = originalCreateGHClient, originalStatusCachePath })
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		require.NoError(t, os.MkdirAll(remotePath, 0o755))
+		testutils.RunCommand(t, remotePath, "git", "init", "--bare")
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "config", "branch.feature-b.socle-pr-base-override", "main")
+
+		keepGitEnv(t)
+		answers := filepath.Join(t.TempDir(), "answers")
+		require.NoError(t, os.WriteFile(answers, nil, 0o644))
+		t.Setenv("SOCLE_TEST_MODE", "1")
+		t.Setenv("SOCLE_TEST_ANSWERS", answers)
+
+		require.NoError(t, runSoCommand(t, "--non-interactive", "submit"))
+
+		data, err := os.ReadFile(filepath.Join(repoPath, ".git", "socle-test-api.json"))
+		require.NoError(t, err)
+		var state struct {
+			PullRequests []struct {
+				Head struct {
+					Ref string `json:"ref"`
+				} `json:"head"`
+				Base struct {
+					Ref string `json:"ref"`
+				} `json:"base"`
+			} `json:"pull_requests"`
+		}
+		require.NoError(t, json.Unmarshal(data, &state))
+		bases := map[string]string{}
+		for _, pr := range state.PullRequests {
+			bases[pr.Head.Ref] = pr.Base.Ref
+		}
+		assert.Equal(t, map[string]string{"feature-a": "main", "feature-b": "main"}, bases)
+	})
+
+	t.Run("Follows the target when it is renamed or deleted", func(t *testing.T) {
+		resetCommandFlags(t, renameCmd, "local")
+		resetCommandFlags(t, submitCmd, "test-title", "test-body")
+		originalCreateGHClient := gh.CreateClient
+		t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "config", "branch.feature-c.socle-pr-base-override", "feature-a")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		require.NoError(t, runSoCommand(t, "rename", "feature-x"))
+		assert.Equal(t, "feature-x", git.GetPRBaseOverride("feature-c"))
+
+		// Once feature-x lands, feature-c's PR skips to what was below it.
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "branch", "origin/main", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "branch.feature-x.socle-pr-number", "101")
+		mockClient := gh.NewMockClient()
+		mockClient.PRStatuses[101] = gh.PRStatusMerged
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		require.NoError(t, runSoCommand(t, "sync", "--test-no-fetch", "--test-no-survey"))
+		exists, err := git.BranchExists("feature-x")
+		require.NoError(t, err)
+		require.False(t, exists)
+		assert.Equal(t, "main", git.GetPRBaseOverride("feature-c"))
+	})
+}
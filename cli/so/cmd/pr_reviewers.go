@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var prReviewersCmd = &cobra.Command{
+	Use:   "reviewers",
+	Short: "Show or rebalance review requests across the PRs of the stack",
+	Long: `Lists the pending review requests of every pull request in the current stack.
+
+With --rebalance, reviewers from the configured pool are spread across the
+stack so no single person receives every PR: each PR gets one pool member,
+choosing the least-loaded person in rotation order and never the PR's author.
+Pool members that are no longer planned for a PR have their request withdrawn;
+reviewers outside the pool are left untouched.
+
+Configuration (git config):
+
+  socle.reviewers          Reviewer pool, GitHub logins (repeatable or comma-separated)
+  socle.reviewerStrategy   round-robin (default) or codeowners; codeowners prefers
+                           pool members owning the branch's changed paths in CODEOWNERS
+
+'so submit --assign-reviewers' applies the same rotation to PRs that have no
+reviewers yet.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rebalance, _ := cmd.Flags().GetBool("rebalance")
+
+		runner := &prReviewersCmdRunner{
+			logger:    slog.Default(),
+			stdout:    cmd.OutOrStdout(),
+			stderr:    cmd.ErrOrStderr(),
+			rebalance: rebalance,
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	prCmd.AddCommand(prReviewersCmd)
+	prReviewersCmd.Flags().Bool("rebalance", false, "Reassign reviewers from the pool across the stack's PRs")
+}
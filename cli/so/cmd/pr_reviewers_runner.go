@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type prReviewersCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	rebalance bool
+}
+
+func (r *prReviewersCmdRunner) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	if stackInfo.FullStack == nil || len(stackInfo.FullStack) <= 1 {
+		return fmt.Errorf("no stack found: check out a tracked branch of the stack first")
+	}
+	stack := stackInfo.FullStack
+
+	cfg, err := loadReviewerConfig()
+	if err != nil {
+		return err
+	}
+	if r.rebalance && len(cfg.pool) == 0 {
+		return fmt.Errorf("no reviewer pool configured. Add logins with 'git config --add socle.reviewers <login>'")
+	}
+
+	ghClient, err := newOriginGitHubClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var targets []reviewerTarget
+	current := make(map[string][]string)
+	for i := 1; i < len(stack); i++ {
+		branch := stack[i]
+		prNumber, err := git.GetStoredPRNumber(branch)
+		if err != nil {
+			return fmt.Errorf("failed to read PR number for '%s': %w", branch, err)
+		}
+		if prNumber == 0 {
+			_, _ = fmt.Fprintf(r.stdout, "  %s: no PR submitted, skipping.\n", branch)
+			continue
+		}
+		pr, err := ghClient.GetPullRequest(prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to fetch PR #%d for '%s': %w", prNumber, branch, err)
+		}
+		targets = append(targets, reviewerTarget{branch: branch, parent: stack[i-1], prNumber: prNumber, author: pr.GetUser().GetLogin()})
+		current[branch] = requestedReviewerLogins(pr)
+	}
+
+	if !r.rebalance {
+		for _, target := range targets {
+			reviewers := "no reviewers requested"
+			if len(current[target.branch]) > 0 {
+				reviewers = strings.Join(current[target.branch], ", ")
+			}
+			_, _ = fmt.Fprintf(r.stdout, "  %s (#%d): %s\n", target.branch, target.prNumber, reviewers)
+		}
+		return nil
+	}
+
+	var owners func(reviewerTarget) []string
+	if cfg.strategy == reviewerStrategyCodeowners {
+		owners, err = codeownersLookup()
+		if err != nil {
+			return err
+		}
+	}
+	plan, cursor := planReviewers(targets, cfg, owners)
+	r.logger.Debug("Planned reviewers", "strategy", cfg.strategy, "plan", plan)
+
+	errs := applyReviewerPlan(ghClient, targets, current, plan, cfg.pool, r.stdout)
+	if err := saveReviewerCursor(cursor); err != nil {
+		r.logger.Debug("Failed to save reviewer cursor", "error", err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to update reviewers on %d PR(s): %w", len(errs), errors.Join(errs...))
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("✓ Reviewers rebalanced."))
+	return nil
+}
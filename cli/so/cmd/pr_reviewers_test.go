@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reviewerPR(number int, author string, requested ...string) *github.PullRequest {
+	pr := &github.PullRequest{Number: github.Ptr(number), User: &github.User{Login: github.Ptr(author)}}
+	for _, login := range requested {
+		pr.RequestedReviewers = append(pr.RequestedReviewers, &github.User{Login: github.Ptr(login)})
+	}
+	return pr
+}
+
+func TestPRReviewersCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, prReviewersCmd, "rebalance")
+
+	setup := func(t *testing.T) (string, *gh.MockClient, func()) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "--add", "socle.reviewers", "alice, bob")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "--add", "socle.reviewers", "@carol")
+		require.NoError(t, git.SetStoredPRNumber("feature-a", 101))
+		require.NoError(t, git.SetStoredPRNumber("feature-b", 102))
+		require.NoError(t, git.SetStoredPRNumber("feature-c", 103))
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		return repoPath, mockClient, cleanup
+	}
+
+	t.Run("Lists pending review requests", func(t *testing.T) {
+		_, mockClient, cleanup := setup(t)
+		defer cleanup()
+		mockClient.On("GetPullRequest", 101).Return(reviewerPR(101, "dev", "bob", "dave"), nil)
+		mockClient.On("GetPullRequest", 102).Return(reviewerPR(102, "dev"), nil)
+		mockClient.On("GetPullRequest", 103).Return(reviewerPR(103, "dev", "carol"), nil)
+
+		stdout, _, err := runSoCommandWithOutput(t, "pr", "reviewers")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "feature-a (#101): bob, dave")
+		assert.Contains(t, stdout, "feature-b (#102): no reviewers requested")
+		assert.Contains(t, stdout, "feature-c (#103): carol")
+		mockClient.AssertNotCalled(t, "RequestReviewers")
+	})
+
+	t.Run("Rebalance rotates the pool, skipping authors and outside reviewers", func(t *testing.T) {
+		_, mockClient, cleanup := setup(t)
+		defer cleanup()
+		// alice authored #101, so rotation starts with bob there
+		mockClient.On("GetPullRequest", 101).Return(reviewerPR(101, "alice", "carol", "dave"), nil)
+		mockClient.On("GetPullRequest", 102).Return(reviewerPR(102, "dev"), nil)
+		mockClient.On("GetPullRequest", 103).Return(reviewerPR(103, "dev"), nil)
+		mockClient.On("RemoveReviewers", 101, []string{"carol"}).Return(nil).Once()
+		mockClient.On("RequestReviewers", 101, []string{"bob"}).Return(nil).Once()
+		mockClient.On("RequestReviewers", 102, []string{"carol"}).Return(nil).Once()
+		mockClient.On("RequestReviewers", 103, []string{"alice"}).Return(nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "pr", "reviewers", "--rebalance")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "feature-a (#101): bob (was carol)")
+		assert.Contains(t, out, "feature-b (#102): carol")
+		assert.Contains(t, out, "feature-c (#103): alice")
+
+		cursor, err := git.GetGitConfig(reviewerCursorKey)
+		require.NoError(t, err)
+		assert.Equal(t, "1", cursor, "next rotation should continue after alice")
+	})
+
+	t.Run("Codeowners strategy prefers owners of changed paths", func(t *testing.T) {
+		repoPath, mockClient, cleanup := setup(t)
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.reviewerStrategy", "codeowners")
+		writeFile(t, repoPath, "CODEOWNERS", "*.txt @bob\nfeature-c.txt @carol\n")
+
+		mockClient.On("GetPullRequest", 101).Return(reviewerPR(101, "dev"), nil)
+		mockClient.On("GetPullRequest", 102).Return(reviewerPR(102, "dev"), nil)
+		mockClient.On("GetPullRequest", 103).Return(reviewerPR(103, "dev"), nil)
+		mockClient.On("RequestReviewers", 101, []string{"bob"}).Return(nil).Once()
+		mockClient.On("RequestReviewers", 102, []string{"bob"}).Return(nil).Once()
+		mockClient.On("RequestReviewers", 103, []string{"carol"}).Return(nil).Once()
+
+		_, _, err := runSoCommandWithOutput(t, "pr", "reviewers", "--rebalance")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Rebalance without a pool fails", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+
+		_, _, err := runSoCommandWithOutput(t, "pr", "reviewers", "--rebalance")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no reviewer pool configured")
+	})
+}
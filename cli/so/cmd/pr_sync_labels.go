@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var prSyncLabelsCmd = &cobra.Command{
+	Use:   "sync-labels",
+	Short: "Apply the stack's configured labels and milestone to every PR in the stack",
+	Long: `Ensures every pull request in the current stack carries the labels and
+milestone configured for the stack, so the whole stack is categorized the same
+way on triage boards.
+
+Labels and milestone are read from the bottom branch of the stack, falling back
+to the stack's base branch:
+
+  git config --add branch.<bottom>.socle-labels <label>   (repeat per label)
+  git config branch.<bottom>.socle-milestone "<milestone title>"
+
+Missing labels are added. With --prune, labels not in the configured set are
+removed as well. Branches without a submitted PR are skipped.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prune, _ := cmd.Flags().GetBool("prune")
+
+		runner := &prSyncLabelsCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			prune:  prune,
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	prCmd.AddCommand(prSyncLabelsCmd)
+	prSyncLabelsCmd.Flags().Bool("prune", false, "Remove labels that are not configured for the stack")
+}
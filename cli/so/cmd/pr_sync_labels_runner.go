@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/google/go-github/v71/github"
+)
+
+type prSyncLabelsCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	prune bool
+}
+
+// stackLabelConfig is the categorization configured for a stack.
+type stackLabelConfig struct {
+	source    string // Branch the config was read from
+	labels    []string
+	milestone string
+}
+
+func (r *prSyncLabelsCmdRunner) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	if stackInfo.FullStack == nil || len(stackInfo.FullStack) <= 1 {
+		return fmt.Errorf("no stack to sync: check out a tracked branch of the stack first")
+	}
+	stack := stackInfo.FullStack
+	branches := stack[1:]
+
+	cfg, err := loadStackLabelConfig(stack[1], stack[0])
+	if err != nil {
+		return err
+	}
+	if len(cfg.labels) == 0 && cfg.milestone == "" {
+		_, _ = fmt.Fprintf(r.stdout, "No labels or milestone configured for this stack. Set 'branch.%s.socle-labels' or 'branch.%s.socle-milestone'.\n", stack[1], stack[1])
+		return nil
+	}
+	r.logger.Debug("Loaded stack label config", "source", cfg.source, "labels", cfg.labels, "milestone", cfg.milestone)
+
+	remoteName := git.GetRemoteName()
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return fmt.Errorf("cannot get remote URL for '%s': %w", remoteName, err)
+	}
+	owner, repoName, err := git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", remoteName, remoteURL, err)
+	}
+	ghClient, err := gh.CreateClient(ctx, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	milestoneNumber := 0
+	if cfg.milestone != "" {
+		milestoneNumber, err = ghClient.FindMilestone(cfg.milestone)
+		if err != nil {
+			return err
+		}
+		if milestoneNumber == 0 {
+			return fmt.Errorf("milestone '%s' not found among open milestones", cfg.milestone)
+		}
+	}
+
+	wanted := make(map[string]bool, len(cfg.labels))
+	for _, l := range cfg.labels {
+		wanted[l] = true
+	}
+
+	var failed []string
+	for _, branch := range branches {
+		prNumber, err := git.GetStoredPRNumber(branch)
+		if err != nil {
+			return fmt.Errorf("failed to read PR number for '%s': %w", branch, err)
+		}
+		if prNumber == 0 {
+			_, _ = fmt.Fprintf(r.stdout, "%s: no PR submitted, skipping.\n", branch)
+			continue
+		}
+
+		pr, err := ghClient.GetPullRequest(prNumber)
+		if err != nil {
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("%s: %v", branch, err)))
+			failed = append(failed, branch)
+			continue
+		}
+
+		if err := r.syncPR(ghClient, branch, pr, cfg, wanted, milestoneNumber); err != nil {
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("%s: %v", branch, err)))
+			failed = append(failed, branch)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to sync labels for %d branch(es): %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (r *prSyncLabelsCmdRunner) syncPR(client gh.ClientInterface, branch string, pr *github.PullRequest, cfg stackLabelConfig, wanted map[string]bool, milestoneNumber int) error {
+	prNumber := pr.GetNumber()
+	present := make(map[string]bool, len(pr.Labels))
+	for _, l := range pr.Labels {
+		present[l.GetName()] = true
+	}
+
+	var missing []string
+	for _, l := range cfg.labels {
+		if !present[l] {
+			missing = append(missing, l)
+		}
+	}
+	var extra []string
+	if r.prune {
+		for _, l := range pr.Labels {
+			if !wanted[l.GetName()] {
+				extra = append(extra, l.GetName())
+			}
+		}
+	}
+	needsMilestone := milestoneNumber != 0 && pr.GetMilestone().GetNumber() != milestoneNumber
+
+	if len(missing) == 0 && len(extra) == 0 && !needsMilestone {
+		_, _ = fmt.Fprintf(r.stdout, "%s (#%d): %s\n", branch, prNumber, ui.Colors.SuccessStyle.Render("already up to date"))
+		return nil
+	}
+
+	var changes []string
+	if len(missing) > 0 {
+		if err := client.AddLabels(prNumber, missing); err != nil {
+			return err
+		}
+		changes = append(changes, "added "+strings.Join(missing, ", "))
+	}
+	for _, l := range extra {
+		if err := client.RemoveLabel(prNumber, l); err != nil {
+			return err
+		}
+	}
+	if len(extra) > 0 {
+		changes = append(changes, "removed "+strings.Join(extra, ", "))
+	}
+	if needsMilestone {
+		if err := client.SetMilestone(prNumber, milestoneNumber); err != nil {
+			return err
+		}
+		changes = append(changes, fmt.Sprintf("milestone '%s'", cfg.milestone))
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "%s (#%d): %s\n", branch, prNumber, strings.Join(changes, "; "))
+	return nil
+}
+
+// loadStackLabelConfig reads socle-labels/socle-milestone from the bottom branch
+// of the stack, falling back to the base branch if the bottom has neither.
+func loadStackLabelConfig(bottom, base string) (stackLabelConfig, error) {
+	for _, branch := range []string{bottom, base} {
+		labels, err := git.GetGitConfigAll(git.BranchConfigKey(branch, "socle-labels"))
+		if err != nil {
+			return stackLabelConfig{}, err
+		}
+		milestone, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-milestone"))
+		if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
+			return stackLabelConfig{}, err
+		}
+
+		var cleaned []string
+		seen := make(map[string]bool)
+		for _, value := range labels {
+			// Accept comma-separated values as well as repeated keys
+			for _, l := range strings.Split(value, ",") {
+				l = strings.TrimSpace(l)
+				if l != "" && !seen[l] {
+					seen[l] = true
+					cleaned = append(cleaned, l)
+				}
+			}
+		}
+		milestone = strings.TrimSpace(milestone)
+		if len(cleaned) > 0 || milestone != "" {
+			return stackLabelConfig{source: branch, labels: cleaned, milestone: milestone}, nil
+		}
+	}
+	return stackLabelConfig{}, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPRSyncLabelsCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	t.Run("Adds missing labels and milestone to every PR in the stack", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "--add", "branch.feature-a.socle-labels", "team-ui")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "--add", "branch.feature-a.socle-labels", "stacked")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-milestone", "v2.0")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-b")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+
+		mockClient.On("FindMilestone", "v2.0").Return(7, nil).Once()
+		mockClient.On("GetPullRequest", 101).Return(&github.PullRequest{
+			Number:    github.Ptr(101),
+			Labels:    []*github.Label{{Name: github.Ptr("team-ui")}, {Name: github.Ptr("wip")}},
+			Milestone: &github.Milestone{Number: github.Ptr(7)},
+		}, nil).Once()
+		mockClient.On("GetPullRequest", 102).Return(&github.PullRequest{Number: github.Ptr(102)}, nil).Once()
+		mockClient.On("AddLabels", 101, []string{"stacked"}).Return(nil).Once()
+		mockClient.On("AddLabels", 102, []string{"team-ui", "stacked"}).Return(nil).Once()
+		mockClient.On("SetMilestone", 102, 7).Return(nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "pr", "sync-labels")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "RemoveLabel", 101, "wip")
+
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "feature-a (#101): added stacked")
+		assert.Contains(t, out, "feature-b (#102): added team-ui, stacked; milestone 'v2.0'")
+		assert.Contains(t, out, "feature-c: no PR submitted, skipping.")
+	})
+
+	t.Run("Prune removes labels that are not configured", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		resetCommandFlags(t, prSyncLabelsCmd, "prune")
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.main.socle-labels", "stacked")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+
+		mockClient.On("GetPullRequest", 101).Return(&github.PullRequest{
+			Number: github.Ptr(101),
+			Labels: []*github.Label{{Name: github.Ptr("stacked")}, {Name: github.Ptr("wip")}},
+		}, nil).Once()
+		mockClient.On("RemoveLabel", 101, "wip").Return(nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "pr", "sync-labels", "--prune")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+		assert.Contains(t, stripAnsi(stdout), "feature-a (#101): removed wip")
+	})
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var prTemplateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Check stack PR descriptions against the team's PR template",
+	Long: `Groups commands that compare the descriptions of the stack's pull requests
+with the sections the team requires ('socle.requiredSections').`,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	prCmd.AddCommand(prTemplateCmd)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var prTemplateSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Verify every PR in the stack still contains the required template sections",
+	Long: `Checks that the description of every pull request in the current stack
+contains a Markdown heading for each required section, and lists the PRs that
+are missing some. Fails if any PR is incomplete, so it can gate CI or a
+pre-merge script.
+
+Required sections are configured once per repository (or user), one heading
+per value, compared case-insensitively:
+
+  git config --add socle.requiredSections "Testing"
+  git config --add socle.requiredSections "Rollback plan"
+
+'so submit --verify-body' runs the same check after submitting and reports
+incomplete PRs as warnings. Branches without a submitted PR are skipped.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &prTemplateSyncCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	prTemplateCmd.AddCommand(prTemplateSyncCmd)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type prTemplateSyncCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (r *prTemplateSyncCmdRunner) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	required, err := loadRequiredSections()
+	if err != nil {
+		return err
+	}
+	if len(required) == 0 {
+		return fmt.Errorf("no required sections configured; add them with 'git config --add socle.requiredSections <heading>'")
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	if stackInfo.FullStack == nil || len(stackInfo.FullStack) <= 1 {
+		return fmt.Errorf("no stack to check: check out a tracked branch of the stack first")
+	}
+
+	remoteName := git.GetRemoteName()
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return fmt.Errorf("cannot get remote URL for '%s': %w", remoteName, err)
+	}
+	owner, repoName, err := git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", remoteName, remoteURL, err)
+	}
+	ghClient, err := gh.CreateClient(ctx, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	var incomplete, failed []string
+	for _, branch := range stackInfo.FullStack[1:] {
+		prNumber, err := git.GetStoredPRNumber(branch)
+		if err != nil {
+			return fmt.Errorf("failed to read PR number for '%s': %w", branch, err)
+		}
+		if prNumber == 0 {
+			_, _ = fmt.Fprintf(r.stdout, "%s: no PR submitted, skipping.\n", branch)
+			continue
+		}
+
+		missing, err := checkPRBody(ghClient, prNumber, required)
+		if err != nil {
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("%s: %v", branch, err)))
+			failed = append(failed, branch)
+			continue
+		}
+		if len(missing) > 0 {
+			_, _ = fmt.Fprintf(r.stdout, "%s (#%d): %s\n", branch, prNumber, ui.Colors.WarningStyle.Render("missing "+strings.Join(missing, ", ")))
+			incomplete = append(incomplete, fmt.Sprintf("#%d (%s)", prNumber, branch))
+			continue
+		}
+		_, _ = fmt.Fprintf(r.stdout, "%s (#%d): %s\n", branch, prNumber, ui.Colors.SuccessStyle.Render("all required sections present"))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to check %d PR(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	if len(incomplete) > 0 {
+		return fmt.Errorf("%d PR(s) are missing required sections: %s", len(incomplete), strings.Join(incomplete, ", "))
+	}
+	return nil
+}
+
+// loadRequiredSections reads the socle.requiredSections headings, ignoring
+// blanks and duplicates.
+func loadRequiredSections() ([]string, error) {
+	values, err := git.GetSocleConfigAll("socle.requiredSections")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read socle.requiredSections: %w", err)
+	}
+	var sections []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !seen[strings.ToLower(v)] {
+			seen[strings.ToLower(v)] = true
+			sections = append(sections, v)
+		}
+	}
+	return sections, nil
+}
+
+// checkPRBody fetches a PR and returns the required sections its body lacks.
+func checkPRBody(client gh.ClientInterface, prNumber int, required []string) ([]string, error) {
+	pr, err := client.GetPullRequest(prNumber)
+	if err != nil {
+		return nil, err
+	}
+	return missingSections(pr.GetBody(), required), nil
+}
+
+// missingSections returns the required headings that do not appear as a
+// Markdown ATX heading ('## Testing') in body. Headings inside fenced code
+// blocks don't count.
+func missingSections(body string, required []string) []string {
+	present := make(map[string]bool)
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		text := strings.TrimLeft(line, "#")
+		if level := len(line) - len(text); level > 6 || (text != "" && text[0] != ' ' && text[0] != '\t') {
+			continue // Not a heading, e.g. '#123' or '#######'
+		}
+		text = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), "#"))
+		text = strings.TrimSuffix(text, ":")
+		present[strings.ToLower(strings.TrimSpace(text))] = true
+	}
+
+	var missing []string
+	for _, section := range required {
+		if !present[strings.ToLower(strings.TrimSuffix(section, ":"))] {
+			missing = append(missing, section)
+		}
+	}
+	return missing
+}
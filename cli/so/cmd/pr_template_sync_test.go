@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPRTemplateSyncCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	t.Run("Lists PRs missing required sections and fails", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "--add", "socle.requiredSections", "Testing")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "--add", "socle.requiredSections", "Rollback plan")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-b")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("GetPullRequest", 101).Return(&github.PullRequest{
+			Number: github.Ptr(101),
+			Body:   github.Ptr("## Summary\nStuff\n\n### testing:\nUnit tests\n\n## Rollback Plan ##\nRevert"),
+		}, nil).Once()
+		mockClient.On("GetPullRequest", 102).Return(&github.PullRequest{
+			Number: github.Ptr(102),
+			Body:   github.Ptr("## Testing\nManual\n\n```md\n## Rollback plan\n```\n#Rollback plan"),
+		}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "pr", "template", "sync")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 PR(s) are missing required sections: #102 (feature-b)")
+		mockClient.AssertExpectations(t)
+
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "feature-a (#101): all required sections present")
+		assert.Contains(t, out, "feature-b (#102): missing Rollback plan")
+		assert.Contains(t, out, "feature-c: no PR submitted, skipping.")
+	})
+
+	t.Run("Requires configured sections", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		_, _, err := runSoCommandWithOutput(t, "pr", "template", "sync")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "socle.requiredSections")
+	})
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+// profileOutput is set by the global --profile flag.
+var profileOutput bool
+
+// executeWithProfile runs root and, when --profile was given, appends a timing
+// breakdown to stderr. The report is written even when the command fails, since
+// slow failures are exactly what users want to report.
+func executeWithProfile(ctx context.Context, root *cobra.Command) error {
+	profile.Reset()
+	started := time.Now()
+	err := root.ExecuteContext(ctx)
+	if profileOutput {
+		profile.Report(root.ErrOrStderr(), time.Since(started))
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileFlag(t *testing.T) {
+	t.Run("Reports git and render timings after the command", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		stdout, stderr, err := runSoCommandWithOutput(t, "--profile", "log")
+		require.NoError(t, err)
+
+		assert.Contains(t, stdout, "feature-a")
+		assert.Contains(t, stderr, "Profile (total")
+		assert.Regexp(t, `git\s+\d+ calls`, stderr)
+		assert.Contains(t, stderr, "rev-parse")
+		assert.Contains(t, stderr, "stack list")
+	})
+
+	t.Run("Prints nothing without the flag", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		_, stderr, err := runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		assert.NotContains(t, stderr, "Profile (total")
+	})
+
+	t.Run("Branch config reads do not grow with the stack", func(t *testing.T) {
+		configCalls := regexp.MustCompile(`\n\s+config\s+(\d+)\s`)
+		count := func(branches []string) string {
+			_, cleanup := setupRepoWithStack(t, branches)
+			defer cleanup()
+			_, stderr, err := runSoCommandWithOutput(t, "--profile", "log")
+			require.NoError(t, err)
+			match := configCalls.FindStringSubmatch(stderr)
+			require.NotNil(t, match, stderr)
+			return match[1]
+		}
+
+		assert.Equal(t, count([]string{"main", "feature-a"}), count([]string{"main", "a", "b", "c", "d", "e", "f"}))
+	})
+}
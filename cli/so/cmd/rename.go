@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <new-name>",
+	Short: "Rename the current branch, keeping its stack metadata and PR",
+	Long: `Renames the current branch like 'git branch -m', and then repairs what a plain
+rename leaves behind:
+
+- The branches stacked on it are re-parented onto the new name.
+- The branch's own socle metadata (PR number, stack comment, notes) moves
+  with it, as git moves its config section.
+- If the branch was pushed, the remote branch is renamed too. On GitHub the
+  branch is renamed through the API, which keeps the PR open on the new name
+  and retargets the PRs stacked on it. Where that is not possible, the new
+  name is pushed, the PRs stacked on it are retargeted, the PR is replaced by
+  a new one from the new name (same title, description and draft state; the
+  old one is closed with a link to it) and the old remote branch is deleted.
+
+Use --local to rename only the local branch and leave the remote branch and
+PR as they are.`,
+	Args: cobra.ExactArgs(1),
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		localOnly, _ := cmd.Flags().GetBool("local")
+
+		runner := &renameCmdRunner{
+			logger:    slog.Default(),
+			stdout:    cmd.OutOrStdout(),
+			stderr:    cmd.ErrOrStderr(),
+			newName:   args[0],
+			localOnly: localOnly,
+		}
+		return runner.run(cmd.Context())
+	}),
+}
+
+func init() {
+	AddCommand(renameCmd)
+	renameCmd.Flags().Bool("local", false, "Rename only the local branch; leave the remote branch and PR alone")
+}
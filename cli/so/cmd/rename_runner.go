@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type renameCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	newName   string
+	localOnly bool // Leave the remote branch and PR alone
+}
+
+func (r *renameCmdRunner) run(ctx context.Context) error {
+	oldName, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if err := r.checkNames(oldName); err != nil {
+		return err
+	}
+	parent, err := git.GetGitConfig(git.BranchConfigKey(oldName, "socle-parent"))
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle; rename it with 'git branch -m'", oldName)
+		}
+		return fmt.Errorf("failed to check tracking status for branch '%s': %w", oldName, err)
+	}
+
+	remoteName := git.GetRemoteName()
+	prNumber, _ := git.GetStoredPRNumber(oldName)
+	pushed := false
+	if _, errURL := git.GetRemoteURL(remoteName); errURL == nil && !r.localOnly {
+		if pushed, err = git.RemoteBranchExists(oldName, remoteName); err != nil {
+			return fmt.Errorf("%w; use --local to rename only the local branch", err)
+		}
+	}
+
+	// Connect before anything is renamed: without GitHub the PRs cannot
+	// follow, and deleting the old remote branch would close them.
+	var ghClient gh.ClientInterface
+	if !r.localOnly && (pushed || prNumber > 0) {
+		client, err := newOriginGitHubClient(ctx)
+		if err != nil {
+			if withPRs := branchesWithPRs(oldName); len(withPRs) > 0 {
+				return fmt.Errorf("cannot reach GitHub (%v), and renaming '%s' on '%s' would close the PRs of %s. Try again later, or use --local to rename only the local branch", err, oldName, remoteName, strings.Join(withPRs, ", "))
+			}
+			r.warn(fmt.Sprintf("cannot reach GitHub (%v); the remote branch is renamed by pushing instead", err))
+		} else {
+			ghClient = client
+		}
+	}
+
+	// Git moves branch.<old>.* along, so the branch keeps its own metadata;
+	// only the children name it.
+	if err := git.RenameBranch(oldName, r.newName); err != nil {
+		return err
+	}
+	children, err := git.MigrateBase(oldName, r.newName)
+	if err != nil {
+		return fmt.Errorf("renamed '%s' to '%s', but failed to update the branches stacked on it: %w", oldName, r.newName, err)
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Renamed '%s' to '%s'.", oldName, r.newName)))
+	for _, child := range children {
+		_, _ = fmt.Fprintf(r.stdout, "  '%s' is now stacked on '%s'.\n", child, r.newName)
+	}
+
+	if r.localOnly || (!pushed && prNumber == 0) {
+		if r.localOnly && prNumber > 0 {
+			_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render(fmt.Sprintf("  PR #%d still uses '%s' on '%s'.", prNumber, oldName, remoteName)))
+		}
+		return nil
+	}
+	return r.renameRemote(ghClient, oldName, parent, remoteName, prNumber, children)
+}
+
+// branchesWithPRs returns branch and the branches stacked directly on it
+// that have a PR, quoted for a message.
+func branchesWithPRs(branch string) []string {
+	candidates := []string{branch}
+	if parents, err := git.GetAllSocleParents(); err == nil {
+		candidates = append(candidates, git.BuildChildMap(parents)[branch]...)
+	}
+	var withPRs []string
+	for _, candidate := range candidates {
+		if number, err := git.GetStoredPRNumber(candidate); err == nil && number > 0 {
+			withPRs = append(withPRs, fmt.Sprintf("'%s' (#%d)", candidate, number))
+		}
+	}
+	return withPRs
+}
+
+// checkNames rejects renaming oldName to the runner's new name when that
+// name is invalid or taken.
+func (r *renameCmdRunner) checkNames(oldName string) error {
+	if r.newName == oldName {
+		return fmt.Errorf("branch is already named '%s'", oldName)
+	}
+	if err := git.IsValidBranchName(r.newName); err != nil {
+		return err
+	}
+	exists, err := git.BranchExists(r.newName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("a branch named '%s' already exists", r.newName)
+	}
+	return nil
+}
+
+// renameRemote moves the remote branch and the PR of the renamed branch to
+// its new name. GitHub's branch rename keeps the PR and retargets the PRs
+// stacked on it; when that is not possible, the new branch is pushed, the
+// PR is reopened from it and the old remote branch is deleted. ghClient is
+// nil when GitHub is unreachable, which run only allows when no PR uses the
+// branch.
+func (r *renameCmdRunner) renameRemote(ghClient gh.ClientInterface, oldName, parent, remoteName string, prNumber int, children []string) error {
+	// Only a PR that is open now is carried over; the rename closes those
+	// whose head it does not move.
+	prOpen := false
+	if ghClient != nil && prNumber > 0 {
+		pr, err := ghClient.GetPullRequest(prNumber)
+		if err != nil {
+			return fmt.Errorf("renamed locally, but failed to read PR #%d: %w", prNumber, err)
+		}
+		prOpen = pr.GetState() == "open"
+	}
+
+	renamedOnGitHub := false
+	if ghClient != nil {
+		if err := ghClient.RenameBranch(oldName, r.newName); err != nil {
+			r.logger.Debug("GitHub branch rename failed, pushing instead", "error", err)
+			r.warn(fmt.Sprintf("%v. Pushing '%s' instead.", err, r.newName))
+		} else {
+			renamedOnGitHub = true
+			_, _ = fmt.Fprintf(r.stdout, "  Renamed '%s' on GitHub.\n", oldName)
+		}
+	}
+
+	// Once renamed on GitHub the push finds the branch there already; a
+	// remote that is not GitHub, or a failed rename, still needs it.
+	cfg, err := git.LoadPushConfig()
+	if err != nil {
+		return err
+	}
+	if err := git.PushBranch(r.newName, remoteName, false, cfg); err != nil {
+		return fmt.Errorf("renamed locally, but %w", err)
+	}
+	if err := git.TrackRemoteBranch(r.newName, oldName, remoteName); err != nil {
+		return err
+	}
+	if !renamedOnGitHub {
+		_, _ = fmt.Fprintf(r.stdout, "  Pushed '%s' to '%s'.\n", r.newName, remoteName)
+	}
+
+	if ghClient != nil {
+		r.retargetChildren(ghClient, children)
+		if prOpen {
+			if err := r.movePR(ghClient, prNumber, parent); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Deleting the old branch closes PRs still using it, so it goes last.
+	if exists, err := git.RemoteBranchExists(oldName, remoteName); err == nil && exists {
+		if err := git.DeleteRemoteBranch(oldName, remoteName); err != nil {
+			r.warn(err.Error())
+		} else {
+			_, _ = fmt.Fprintf(r.stdout, "  Deleted '%s' on '%s'.\n", oldName, remoteName)
+		}
+	}
+	return nil
+}
+
+// retargetChildren points the open PRs of children at the new name, which
+// GitHub's rename already did unless it failed.
+func (r *renameCmdRunner) retargetChildren(ghClient gh.ClientInterface, children []string) {
+	base := git.PRBaseFor(r.newName)
+	for _, child := range children {
+		number, err := git.GetStoredPRNumber(child)
+		if err != nil || number == 0 || git.GetPRBaseOverride(child) != "" {
+			continue
+		}
+		pr, err := ghClient.GetPullRequest(number)
+		if err != nil {
+			r.warn(fmt.Sprintf("failed to read PR #%d (%s): %v", number, child, err))
+			continue
+		}
+		if pr.GetState() != "open" || pr.GetBase().GetRef() == base {
+			continue
+		}
+		if _, err := ghClient.UpdatePullRequestBase(number, base); err != nil {
+			r.warn(fmt.Sprintf("failed to retarget PR #%d (%s): %v", number, child, err))
+			continue
+		}
+		_, _ = fmt.Fprintf(r.stdout, "  Retargeted PR #%d (%s) to '%s'.\n", number, child, base)
+	}
+}
+
+// movePR makes sure the open PR number has the renamed branch as its head. A
+// PR can't change its head, so one that did not follow GitHub's rename is
+// replaced: a new PR with the same title, description and draft state is
+// opened from the new name, and the old one is closed with a pointer to it.
+func (r *renameCmdRunner) movePR(ghClient gh.ClientInterface, number int, parent string) error {
+	pr, err := ghClient.GetPullRequest(number)
+	if err != nil {
+		return fmt.Errorf("failed to read PR #%d: %w", number, err)
+	}
+	if pr.GetHead().GetRef() == r.newName {
+		_, _ = fmt.Fprintf(r.stdout, "  PR #%d follows the new name.\n", number)
+		return nil
+	}
+	base := pr.GetBase().GetRef()
+	if base == "" {
+		base = git.PRBaseOf(r.newName, parent)
+	}
+	newPR, err := ghClient.CreatePullRequest(r.newName, base, pr.GetTitle(), pr.GetBody(), pr.GetDraft())
+	if err != nil {
+		return fmt.Errorf("failed to open a PR for '%s' to replace #%d: %w", r.newName, number, err)
+	}
+	if err := git.SetStoredPRNumber(r.newName, newPR.GetNumber()); err != nil {
+		return err
+	}
+	// The stack comment lives on the old PR; submit posts a new one.
+	if err := git.UnsetStoredCommentID(r.newName); err != nil {
+		r.logger.Debug("Failed to forget the old stack comment", "error", err)
+	}
+
+	note := fmt.Sprintf("The branch was renamed to `%s`; continued in #%d.", r.newName, newPR.GetNumber())
+	if _, err := ghClient.CreateComment(number, note); err != nil {
+		r.warn(fmt.Sprintf("failed to comment on PR #%d: %v", number, err))
+	}
+	if pr.GetState() == "open" {
+		if err := ghClient.ClosePullRequest(number); err != nil {
+			r.warn(err.Error())
+		}
+	}
+	_, _ = fmt.Fprintf(r.stdout, "  Replaced PR #%d with #%d: %s\n", number, newPR.GetNumber(), newPR.GetHTMLURL())
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("  Run 'so submit' to update the stack comments."))
+	return nil
+}
+
+func (r *renameCmdRunner) warn(message string) {
+	_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render("Warning: "+message))
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameCommand_LocalStack(t *testing.T) {
+	resetCommandFlags(t, renameCmd, "local")
+	resetCommandFlags(t, submitCmd, "test-title", "test-body")
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+	defer cleanup()
+	testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+
+	stdout, _, err := runSoCommandWithOutput(t, "rename", "feature-renamed")
+	require.NoError(t, err)
+	output := stripAnsi(stdout)
+	assert.Contains(t, output, "✓ Renamed 'feature-b' to 'feature-renamed'.")
+	assert.Contains(t, output, "'feature-c' is now stacked on 'feature-renamed'.")
+
+	current, err := git.GetCurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "feature-renamed", current)
+	exists, err := git.BranchExists("feature-b")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	parent, err := git.GetGitConfig(git.BranchConfigKey("feature-renamed", "socle-parent"))
+	require.NoError(t, err)
+	assert.Equal(t, "feature-a", parent)
+	parent, err = git.GetGitConfig(git.BranchConfigKey("feature-c", "socle-parent"))
+	require.NoError(t, err)
+	assert.Equal(t, "feature-renamed", parent)
+
+	t.Run("rejects a taken name", func(t *testing.T) {
+		_, _, err := runSoCommandWithOutput(t, "rename", "feature-a")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a branch named 'feature-a' already exists")
+	})
+
+	t.Run("rejects an untracked branch", func(t *testing.T) {
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		_, _, err := runSoCommandWithOutput(t, "rename", "trunk")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not tracked by socle")
+	})
+}
+
+func TestRenameCommand_MovesRemoteBranchAndPR(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, renameCmd, "local")
+	resetCommandFlags(t, submitCmd, "test-title", "test-body")
+
+	setup := func(t *testing.T) (*gh.FakeServer, string) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		t.Cleanup(cleanup)
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--quiet", "--bare", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "main")
+
+		api := gh.NewFakeServer("test-owner", "test-repo")
+		t.Cleanup(api.Close)
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return api.Client(ctx), nil
+		}
+		_, _, err := runSoCommandWithOutput(t, "submit", "--test-title=T", "--test-body=Body")
+		require.NoError(t, err)
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		return api, repoPath
+	}
+	remoteBranches := func(t *testing.T, repoPath string) string {
+		return testutils.RunCommand(t, repoPath, "git", "ls-remote", "--heads", "origin")
+	}
+	prFor := func(api *gh.FakeServer, number int) (head, base, state string) {
+		for _, pr := range api.PullRequests() {
+			if pr.GetNumber() == number {
+				return pr.GetHead().GetRef(), pr.GetBase().GetRef(), pr.GetState()
+			}
+		}
+		return "", "", ""
+	}
+
+	t.Run("GitHub rename keeps the PR", func(t *testing.T) {
+		api, repoPath := setup(t)
+
+		stdout, stderr, err := runSoCommandWithOutput(t, "rename", "feature-renamed")
+		require.NoError(t, err, stderr)
+		output := stripAnsi(stdout)
+		assert.Contains(t, output, "Renamed 'feature-a' on GitHub.")
+		assert.Contains(t, output, "PR #1 follows the new name.")
+
+		number, err := git.GetStoredPRNumber("feature-renamed")
+		require.NoError(t, err)
+		assert.Equal(t, 1, number)
+		head, _, state := prFor(api, 1)
+		assert.Equal(t, "feature-renamed", head)
+		assert.Equal(t, "open", state)
+		_, base, _ := prFor(api, 2)
+		assert.Equal(t, "feature-renamed", base)
+
+		heads := remoteBranches(t, repoPath)
+		assert.Contains(t, heads, "refs/heads/feature-renamed")
+		assert.NotContains(t, heads, "refs/heads/feature-a\n")
+		upstream := testutils.RunCommand(t, repoPath, "git", "rev-parse", "--abbrev-ref", "feature-renamed@{upstream}")
+		assert.Equal(t, "origin/feature-renamed", strings.TrimSpace(upstream))
+	})
+
+	t.Run("failed GitHub rename replaces the PR", func(t *testing.T) {
+		api, repoPath := setup(t)
+		api.InjectFault(gh.Fault{Method: http.MethodPost, Path: "/rename", Status: http.StatusForbidden, Times: 1})
+
+		stdout, stderr, err := runSoCommandWithOutput(t, "rename", "feature-renamed")
+		require.NoError(t, err, stderr)
+		output := stripAnsi(stdout)
+		assert.Contains(t, stripAnsi(stderr), "Pushing 'feature-renamed' instead.")
+		assert.Contains(t, output, "Pushed 'feature-renamed' to 'origin'.")
+		assert.Contains(t, output, "Retargeted PR #2 (feature-b) to 'feature-renamed'.")
+		assert.Contains(t, output, "Replaced PR #1 with #3")
+
+		number, err := git.GetStoredPRNumber("feature-renamed")
+		require.NoError(t, err)
+		assert.Equal(t, 3, number)
+		head, base, state := prFor(api, 3)
+		assert.Equal(t, "feature-renamed", head)
+		assert.Equal(t, "main", base)
+		assert.Equal(t, "open", state)
+		_, _, state = prFor(api, 1)
+		assert.Equal(t, "closed", state)
+		comments := strings.Join(api.CommentsOn(1), "\n")
+		assert.Contains(t, comments, "continued in #3")
+		_, base, _ = prFor(api, 2)
+		assert.Equal(t, "feature-renamed", base)
+
+		heads := remoteBranches(t, repoPath)
+		assert.Contains(t, heads, "refs/heads/feature-renamed")
+		assert.NotContains(t, heads, "refs/heads/feature-a\n")
+	})
+
+	t.Run("unreachable GitHub renames nothing while PRs use the branch", func(t *testing.T) {
+		_, repoPath := setup(t)
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return nil, errors.New("network is unreachable")
+		}
+
+		err := runSoCommand(t, "rename", "feature-renamed")
+		require.ErrorContains(t, err, "network is unreachable), and renaming 'feature-a' on 'origin' would close the PRs of 'feature-a' (#1), 'feature-b' (#2)")
+		require.ErrorContains(t, err, "use --local to rename only the local branch")
+		exists, err := git.BranchExists("feature-a")
+		require.NoError(t, err)
+		assert.True(t, exists, "the local branch keeps its name")
+		assert.Contains(t, remoteBranches(t, repoPath), "refs/heads/feature-a")
+		assert.NotContains(t, remoteBranches(t, repoPath), "refs/heads/feature-renamed")
+	})
+
+	t.Run("local leaves the remote alone", func(t *testing.T) {
+		_, repoPath := setup(t)
+
+		stdout, _, err := runSoCommandWithOutput(t, "rename", "--local", "feature-renamed")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "PR #1 still uses 'feature-a' on 'origin'.")
+		heads := remoteBranches(t, repoPath)
+		assert.Contains(t, heads, "refs/heads/feature-a")
+		assert.NotContains(t, heads, "refs/heads/feature-renamed")
+	})
+}
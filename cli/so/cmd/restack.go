@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/benekuehn/socle/cli/so/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -11,21 +12,69 @@ var restackCmd = &cobra.Command{
 	Use:   "restack",
 	Short: "Rebase the current stack onto the latest base branch",
 	Long: `Updates the current stack by rebasing each branch sequentially onto its updated parent.
-Handles remote 'origin' automatically.
+Works against the remote named by socle.remote (default 'origin').
 
 Process:
 1. Checks for clean state & existing Git rebase.
-2. Fetches the base branch from 'origin' (unless --no-fetch).
+2. Fetches the base branch from the remote (unless --no-fetch or socle.noFetch).
 3. Rebases each branch in the stack onto the latest commit of its parent.
    - Skips branches that are already up-to-date.
 4. If conflicts occur:
-   - Stops and instructs you to use standard Git commands (status, add, rebase --continue / --abort).
-   - Run 'so restack' again after resolving or aborting the Git rebase.
+   - Stops so you can resolve them: 'git add' the resolved files and run 'so continue',
+     which finishes the rebase and restacks the rest of the stack with the same flags.
+   - To give up instead, run 'git rebase --abort' and later 'so restack' again.
+   - With --stop-on-conflict=skip (or 'git config socle.stopOnConflict skip'), aborts
+     that rebase instead, leaves the branch and everything above it as they were,
+     restacks the rest and lists the skipped branches at the end.
 5. If successful:
-   - Prompts to force-push updated branches to 'origin' (use --force-push or --no-push to skip prompt).`,
+   - Prompts to force-push updated branches to the remote (use --force-push or --no-push to skip prompt).
+
+With --trailers (or 'git config socle.commitTrailers true'), every rewritten commit
+carries 'Stacked-on: <parent>' and, once a PR exists, 'PR: <url>' trailers. Branches
+whose trailers are stale are rewritten even when they need no rebase.
+
+Merge commits inside a branch (e.g. trunk merged into a long-lived branch) are
+flattened by a plain rebase, and restack warns before doing so. With
+--rebase-merges, 'git config socle.rebaseMerges true' or, per branch,
+'git config branch.<name>.socle-rebase-merges true', they are recreated with
+'git rebase --rebase-merges' instead.
+
+Branches whose commits already landed in their parent (for example
+cherry-picked onto it) are reported as already merged; their commits are
+dropped by the rebase ('git rebase --empty=drop'), which leaves them empty.
+Restack then offers to delete them and move their children onto the parent;
+--fold-merged does so without asking.
+
+--upstack restacks only the current branch and the branches above it;
+--downstack only the branches from the base up to the current one.
+
+--onto <branch> moves the whole stack to another base branch, e.g. from
+'develop' to 'main': only the stack's own commits are replayed onto it, and
+every branch's socle-base is updated. After a conflict, resolve it and run
+'so restack' to finish the move. Run 'so submit' afterwards to retarget the
+bottom PR.`,
 	Args: cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: withNextStepHint(guardStackInvariants(func(cmd *cobra.Command, args []string) error {
 		logger := slog.Default()
+		pushOptions, _ := cmd.Flags().GetStringArray("push-option")
+		noFetch := cmd.Flag("no-fetch").Changed
+		if !noFetch {
+			var err error
+			if noFetch, err = git.GetSocleConfigBool("socle.noFetch", false); err != nil {
+				return err
+			}
+		}
+
+		onConflict, err := conflictPolicy(cmd)
+		if err != nil {
+			return err
+		}
+		scope := ""
+		if cmd.Flag("upstack").Changed {
+			scope = scopeUpstack
+		} else if cmd.Flag("downstack").Changed {
+			scope = scopeDownstack
+		}
 
 		runner := &restackCmdRunner{
 			logger:         logger,
@@ -35,21 +84,37 @@ Process:
 			nonInteractive: nonInteractive,
 
 			// Populate config from flags
-			noFetch:   cmd.Flag("no-fetch").Changed,
-			forcePush: cmd.Flag("force-push").Changed,
-			noPush:    cmd.Flag("no-push").Changed,
+			noFetch:     noFetch,
+			forcePush:   cmd.Flag("force-push").Changed,
+			noPush:      cmd.Flag("no-push").Changed,
+			pushOptions: pushOptions,
+			trailers:    cmd.Flag("trailers").Changed,
+			keepMerges:  cmd.Flag("rebase-merges").Changed,
+			scope:       scope,
+			onConflict:  onConflict,
+			foldMerged:  cmd.Flag("fold-merged").Changed,
+			onto:        cmd.Flag("onto").Value.String(),
 		}
 
 		return runner.run(cmd)
-	},
+	})),
 }
 
 func init() {
 	AddCommand(restackCmd)
 	// Define flags without binding to global vars
-	restackCmd.Flags().Bool("no-fetch", false, "Skip fetching the remote base branch")
+	restackCmd.Flags().Bool("no-fetch", false, "Skip fetching the remote base branch (default from socle.noFetch)")
 	restackCmd.Flags().Bool("force-push", false, "Force push rebased branches without prompting")
 	restackCmd.Flags().Bool("no-push", false, "Do not push branches after successful rebase")
+	restackCmd.Flags().StringArrayP("push-option", "o", nil, "Transmit the given string to the server as a push option (repeatable)")
+	restackCmd.Flags().Bool("trailers", false, "Maintain Stacked-on and PR trailers in commit messages (default from socle.commitTrailers)")
+	restackCmd.Flags().Bool("rebase-merges", false, "Recreate merge commits instead of flattening them (default from socle.rebaseMerges)")
+	restackCmd.Flags().Bool("upstack", false, "Restack only the current branch and the branches above it")
+	restackCmd.Flags().Bool("downstack", false, "Restack only the branches from the base up to the current one")
+	restackCmd.Flags().String("stop-on-conflict", conflictHalt, "On a conflict, halt for you to resolve it or skip the branch and its descendants: halt|skip (default from socle.stopOnConflict)")
+	restackCmd.Flags().Bool("fold-merged", false, "Delete branches already merged into their parent and move their children onto it, without asking")
+	restackCmd.Flags().String("onto", "", "Move the whole stack onto another base branch")
 	// Flags that decide push behavior are mutually exclusive
 	restackCmd.MarkFlagsMutuallyExclusive("force-push", "no-push")
+	restackCmd.MarkFlagsMutuallyExclusive("upstack", "downstack", "onto")
 }
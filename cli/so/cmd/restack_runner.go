@@ -6,10 +6,15 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/benekuehn/socle/cli/so/internal/events"
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/interrupt"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
 	"github.com/benekuehn/socle/cli/so/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -18,35 +23,70 @@ type restackCmdRunner struct {
 	logger *slog.Logger
 	stdout io.Writer
 	stderr io.Writer
-	stdin  io.Reader // For push prompt
+	stdin  io.Reader   // For push prompt
+	events *events.Bus // Progress output; text on stdout/stderr unless set
 
 	nonInteractive bool
 
 	// Config flags
-	noFetch   bool
-	forcePush bool
-	noPush    bool
+	noFetch     bool
+	forcePush   bool
+	noPush      bool
+	pushOptions []string
+	trailers    bool     // Maintain Stacked-on/PR commit trailers (--trailers or socle.commitTrailers)
+	keepMerges  bool     // --rebase-merges for every branch
+	scope       string   // "", scopeUpstack or scopeDownstack
+	onConflict  string   // conflictHalt (default) or conflictSkip
+	allStacks   bool     // Restack every stack on the base, not just the current one
+	bases       []string // With allStacks, restack every stack on these bases instead
+	subtrees    []string // With allStacks, restack only these branches and the branches above them instead
+	foldMerged  bool     // Delete branches already merged into their parent without asking
+	onto        string   // Move the whole stack onto this base branch
+}
+
+// Parts of the stack --upstack and --downstack restrict a restack to.
+const (
+	scopeUpstack   = "upstack"   // The current branch and everything above it
+	scopeDownstack = "downstack" // Everything from the base up to the current branch
+)
+
+// What --stop-on-conflict does when a rebase conflicts.
+const (
+	conflictHalt = "halt" // Pause the rebase for the user to resolve
+	conflictSkip = "skip" // Abort it and leave the branch and its descendants alone
+)
+
+// restackStep rebases branch onto parent.
+type restackStep struct {
+	branch, parent string
 }
 
 func (r *restackCmdRunner) run(cmd *cobra.Command) error {
+	if r.events == nil {
+		r.events = events.New(events.NewTextRenderer(r.stdout, r.stderr))
+	}
+
 	// --- Pre-Checks ---
 	if git.IsRebaseInProgress() {
-		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Git rebase already in progress."))
-		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Resolve conflicts and run 'git rebase --continue' or cancel with 'git rebase --abort'."))
-		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Once the Git rebase is finished, run 'so restack' again if needed."))
-		cmd.SilenceUsage = true // Prevent usage printing on clean exit
-		return nil              // Exit cleanly, user needs to act in Git
+		r.events.Emit(events.RebaseInProgress{})
+		cmd.SilenceUsage = true
+		return socleerr.New(socleerr.Conflict, "a rebase is already in progress") // The user needs to act in Git
+	}
+	// A restack paused earlier and abandoned must not be resumed by a later
+	// 'so continue' once this one starts over.
+	if err := clearRestackProgress(); err != nil {
+		return err
 	}
 	hasChanges, err := git.HasUncommittedChanges()
 	if err != nil {
 		return fmt.Errorf("failed to check working tree status: %w", err)
 	}
 	if hasChanges {
-		return fmt.Errorf("uncommitted changes detected. Please commit or stash them before restacking")
+		return socleerr.New(socleerr.DirtyWorktree, "uncommitted changes detected. Please commit or stash them before restacking")
 	}
 
 	// Get complete stack info in one call
-	stackInfo, err := git.GetStackInfo()
+	stackInfo, err := r.stackInfo()
 	if err != nil {
 		return err
 	}
@@ -57,38 +97,41 @@ func (r *restackCmdRunner) run(cmd *cobra.Command) error {
 	currentBranch := stackInfo.CurrentBranch
 
 	// Handle case where we're on a base branch with multiple stacks
-	if stack == nil {
+	if stack == nil && !r.allStacks {
 		return fmt.Errorf("cannot restack from base branch '%s' with multiple stacks. Please navigate to a specific stack first using 'so up', 'so bottom', or 'so stacks' to see available options", currentBranch)
 	}
 
-	r.logger.Debug("Identified stack for restacking", "stack", stack, "base", baseBranch)
-
-	if len(stack) <= 1 {
-		_, _ = fmt.Fprintln(r.stdout, ui.Colors.InfoStyle.Render("No branches to restack: current branch is a base branch."))
-		return nil
+	var steps []restackStep
+	if r.onto != "" && r.onto != baseBranch {
+		if steps, err = r.moveOnto(stackInfo); err != nil {
+			return err
+		}
+		baseBranch = r.onto
+	} else if len(r.bases) > 0 {
+		steps = restackBaseSteps(stackInfo.ChildMap, r.bases)
+	} else if len(r.subtrees) > 0 {
+		steps = restackSubtreeSteps(stackInfo.ParentMap, stackInfo.ChildMap, r.subtrees)
+	} else {
+		steps = restackSteps(stackInfo, r.scope, r.allStacks)
 	}
+	r.logger.Debug("Identified branches for restacking", "steps", steps, "base", baseBranch)
 
-	// Defer returning to the original branch
-	defer func() {
-		// Only run if no rebase is currently in progress (i.e., we didn't exit due to conflict)
-		if !git.IsRebaseInProgress() {
-			if currentBranch != baseBranch {
-				r.logger.Debug("Checking out original branch", "name", currentBranch)
-				errCheckout := git.CheckoutBranch(currentBranch)
-				if errCheckout != nil {
-					_, _ = fmt.Fprintf(r.stderr, ui.Colors.WarningStyle.Render("Warning: Failed to checkout original branch '%s': %v\\n"), currentBranch, errCheckout)
-				}
-			}
+	if len(steps) == 0 {
+		message := "No branches to restack: current branch is a base branch."
+		if r.scope == scopeDownstack && len(stack) > 1 {
+			message = "No branches to restack below the current branch."
 		}
-	}()
+		r.events.Emit(events.Info{Message: message})
+		return nil
+	}
 
 	// --- Fetch Base (with remote check) ---
-	remoteName := "origin"
+	remoteName := git.GetRemoteName()
 	shouldFetch := !r.noFetch
 	if shouldFetch {
 		_, errRemote := git.GetRemoteURL(remoteName)
 		if errRemote != nil {
-			if strings.Contains(errRemote.Error(), "not found") {
+			if errors.Is(errRemote, git.ErrRemoteNotFound) {
 				r.logger.Debug("Remote not found. Skipping fetch.", "remoteName", remoteName)
 				shouldFetch = false
 			} else {
@@ -96,6 +139,13 @@ func (r *restackCmdRunner) run(cmd *cobra.Command) error {
 			}
 		}
 	}
+	if frozen, ok := git.GetFrozenBase(baseBranch); ok {
+		// A frozen base never moves; there is nothing to fetch.
+		shouldFetch = false
+		if moved, err := git.FrozenBaseMoved(frozen); err != nil || moved {
+			r.events.Emit(events.Warning{Message: fmt.Sprintf("frozen base '%s' no longer points at %s", baseBranch, frozen.Ref)})
+		}
+	}
 	if shouldFetch {
 		r.logger.Debug("Fetching latest", "baseBranch", baseBranch, "remoteName", remoteName)
 		// Pass remote name to FetchBranch if it needs it
@@ -107,15 +157,81 @@ func (r *restackCmdRunner) run(cmd *cobra.Command) error {
 		r.logger.Debug("Skipping fetch (--no-fetch).")
 	}
 
+	return r.rebaseSteps(cmd, &restackProgress{
+		OriginalBranch: currentBranch,
+		BaseBranch:     baseBranch,
+		Steps:          steps,
+		Skipped:        map[string]bool{},
+		Options:        r.options(),
+	})
+}
+
+// rebaseSteps rebases the steps of p from p.Next on, then offers to fold the
+// branches the rebases emptied and to push. A rebase that stops on a
+// conflict saves p for 'so continue'.
+func (r *restackCmdRunner) rebaseSteps(cmd *cobra.Command, p *restackProgress) error {
+	currentBranch, baseBranch := p.OriginalBranch, p.BaseBranch
+	remoteName := git.GetRemoteName()
+
+	// Defer returning to the original branch
+	defer func() {
+		// Only run if no rebase is currently in progress (i.e., we didn't exit due to conflict)
+		if !git.IsRebaseInProgress() {
+			if currentBranch != baseBranch {
+				r.logger.Debug("Checking out original branch", "name", currentBranch)
+				errCheckout := git.CheckoutBranch(currentBranch)
+				if errCheckout != nil {
+					r.events.Emit(events.Warning{Branch: currentBranch, Message: fmt.Sprintf("Failed to checkout original branch '%s': %v", currentBranch, errCheckout)})
+				}
+			}
+		}
+	}()
+
+	// --- Commit Trailers ---
+	withTrailers := commitTrailersEnabled(r.trailers, r.logger)
+	repoURL := ""
+	if withTrailers {
+		if remoteURL, errURL := git.GetRemoteURL(remoteName); errURL == nil {
+			if remote, errParse := git.ParseRemoteURL(remoteURL); errParse == nil {
+				repoURL = remote.WebURL()
+			}
+		}
+		r.logger.Debug("Maintaining commit trailers", "repo", repoURL)
+	}
+
 	// --- Iterative Rebase Loop ---
 	r.logger.Debug("\n--- Starting Stack Rebase ---")
-	rebasedBranches := []string{} // Keep track of branches we actually rebased/checked
+	worktrees, err := git.OtherWorktreeBranches()
+	if err != nil {
+		return err
+	}
 
-	for i := 1; i < len(stack); i++ {
-		branch := stack[i]
-		parent := stack[i-1]
+	// A second Ctrl+C ends socle while git may still be rebasing; what was
+	// done so far is saved for 'so continue' all the same.
+	defer interrupt.OnForcedExit(func() {
+		p.Interrupted = true
+		_ = saveRestackProgress(p)
+	})()
 
-		r.logger.Debug("Processing branch", "index", i, "total", len(stack)-1, "branch", branch, "parent", parent)
+	for ; p.Next < len(p.Steps); p.Next++ {
+		i, step := p.Next, p.Steps[p.Next]
+		branch, parent := step.branch, step.parent
+
+		if err := interrupt.Check(cmd.Context(), "run 'so continue' to restack the remaining branches"); err != nil {
+			p.Interrupted = true
+			if errSave := saveRestackProgress(p); errSave != nil {
+				return fmt.Errorf("interrupted before restacking '%s', and %w", branch, errSave)
+			}
+			return err
+		}
+
+		r.logger.Debug("Processing branch", "index", i+1, "total", len(p.Steps), "branch", branch, "parent", parent)
+		if p.Skipped[parent] {
+			p.Skipped[branch] = true
+			r.events.Emit(events.BranchSkipped{Branch: branch, Reason: fmt.Sprintf("'%s' stays on '%s', which was skipped.", branch, parent)})
+			p.SkipNotes = append(p.SkipNotes, fmt.Sprintf("'%s' skipped: its parent '%s' was skipped", branch, parent))
+			continue
+		}
 
 		// Get current OIDs
 		parentOID, errPO := git.GetCurrentBranchCommit(parent)
@@ -123,16 +239,47 @@ func (r *restackCmdRunner) run(cmd *cobra.Command) error {
 			return fmt.Errorf("cannot get current commit of parent '%s': %w", parent, errPO)
 		}
 
+		var trailers []git.Trailer
+		if withTrailers {
+			trailers = branchTrailers(branch, parent, repoURL)
+		}
+
+		// Set while the branch is being moved by --onto
+		upstream := git.GetRestackUpstream(branch)
+
 		// Optimization Check
 		mergeBase, errMB := git.GetMergeBase(parent, branch)
 		if errMB != nil {
 			// If merge-base fails, maybe the branches have diverged significantly?
 			// Warn and proceed with rebase attempt.
-			_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render(fmt.Sprintf("  Warning: Could not find merge base between '%s' and '%s': %v. Attempting rebase anyway.", parent, branch, errMB)))
-		} else if mergeBase == parentOID {
+			r.events.Emit(events.Warning{Branch: branch, Message: fmt.Sprintf("Could not find merge base between '%s' and '%s': %v. Attempting rebase anyway.", parent, branch, errMB)})
+		} else if mergeBase == parentOID && r.hasTrailers(parent, branch, trailers) && !carriesOldBase(upstream, parent, branch) {
 			r.logger.Debug("Branch is already based on current parent. Skipping rebase.", "branch", branch, "parent", parent)
-			rebasedBranches = append(rebasedBranches, branch) // Add to list even if skipped, as it's confirmed correct
-			continue                                          // Skip to next branch
+			p.Rebased = append(p.Rebased, branch) // Add to list even if skipped, as it's confirmed correct
+			r.finishMove(branch, upstream)
+			r.events.Emit(events.BranchUpToDate{Branch: branch, Parent: parent})
+			continue // Skip to next branch
+		}
+
+		opts := git.RebaseOptions{Trailers: trailers, RebaseMerges: rebaseMergesEnabled(branch, r.keepMerges, r.logger), Upstream: upstream}
+		if !opts.RebaseMerges {
+			r.warnFlattenedMerges(branch, parent)
+		}
+
+		// Git cannot check out a branch another worktree has checked out
+		if path, ok := worktrees[branch]; ok {
+			p.Skipped[branch] = true
+			r.events.Emit(events.BranchSkipped{Branch: branch, Reason: fmt.Sprintf("'%s' is checked out in the worktree at '%s'; rebase it there or remove that worktree.", branch, path)})
+			p.SkipNotes = append(p.SkipNotes, fmt.Sprintf("'%s' skipped: checked out in the worktree at '%s'", branch, path))
+			continue
+		}
+
+		// Commits the parent already has (cherry-picked, applied from a patch)
+		// would replay as empty ones; --empty=drop discards them.
+		opts.DropEmpty = true
+		isMerged := r.mergedIntoParent(parent, branch)
+		if isMerged {
+			r.events.Emit(events.BranchMergedIntoParent{Branch: branch, Parent: parent})
 		}
 
 		// Checkout and Rebase
@@ -142,35 +289,64 @@ func (r *restackCmdRunner) run(cmd *cobra.Command) error {
 		}
 
 		r.logger.Debug("Rebasing onto parent", "branch", branch, "parent", parent, "parentOID", parentOID[:7])
-		err = git.RebaseCurrentBranchOnto(parentOID) // Rebase onto specific parent commit OID
+		err = git.RebaseCurrentBranchOntoWith(parentOID, opts) // Rebase onto specific parent commit OID
 
 		if err == nil {
 			r.logger.Debug("Rebase step successful.")
-			rebasedBranches = append(rebasedBranches, branch) // Track success
-			continue                                          // Success, move to next branch
+			p.Rebased = append(p.Rebased, branch) // Track success
+			r.finishMove(branch, upstream)
+			if isMerged {
+				p.Merged = append(p.Merged, step)
+			}
+			r.events.Emit(events.BranchRebased{Branch: branch, Parent: parent})
+			continue // Success, move to next branch
 		}
 
 		// Handle Rebase Failure
 		if errors.Is(err, git.ErrRebaseConflict) {
+			if r.onConflict == conflictSkip {
+				if errAbort := git.AbortRebase(); errAbort != nil {
+					return fmt.Errorf("failed to skip '%s' after a conflict: %w", branch, errAbort)
+				}
+				p.Skipped[branch] = true
+				r.events.Emit(events.BranchSkipped{Branch: branch, Reason: fmt.Sprintf("rebasing '%s' onto '%s' conflicts; left as it was.", branch, parent)})
+				p.SkipNotes = append(p.SkipNotes, fmt.Sprintf("'%s' skipped: rebasing onto '%s' conflicts", branch, parent))
+				continue
+			}
+
 			// CONFLICT Case
-			_, _ = fmt.Fprintln(r.stderr, "")
-			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render("⚠️ Rebase paused due to conflicts."))
-			_, _ = fmt.Fprintf(r.stderr, "Please resolve the conflicts in branch '%s' and then run:\n", branch)
-			_, _ = fmt.Fprintln(r.stderr, "  1. Run 'git add <resolved-files...>'.")
-			_, _ = fmt.Fprintln(r.stderr, "  2. Run 'git rebase --continue'.")
-			_, _ = fmt.Fprintln(r.stderr, "   (To cancel, run 'git rebase --abort')")
-			_, _ = fmt.Fprintln(r.stderr, "   Once the Git rebase is complete, run 'so restack' again.")
+			p.PausedMerged = isMerged
+			if errSave := saveRestackProgress(p); errSave != nil {
+				r.events.Emit(events.Warning{Branch: branch, Message: fmt.Sprintf("%v; finish with 'git rebase --continue' and run 'so restack' again", errSave)})
+			}
+			r.events.Emit(r.conflictEvent(branch, parent, i+1, len(p.Steps)))
 
-			cmd.SilenceUsage = true // Prevent usage printing
-			return nil              // Exit cleanly, user needs to use Git
+			cmd.SilenceUsage = true
+			return socleerr.New(socleerr.Conflict, "restack paused on a conflict in '%s'", branch) // The user needs to use Git
 		}
 
 		// Other Unexpected Rebase Failure
 		return fmt.Errorf("unexpected error during rebase of '%s': %w", branch, err)
 	}
 
+	if err := clearRestackProgress(); err != nil {
+		r.logger.Debug("Failed to clear restack progress", "error", err)
+	}
+
 	// --- Post-Success ---
-	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("\n✓ Stack Rebase Completed Successfully\n"))
+	rebasedBranches := p.Rebased
+	if len(p.SkipNotes) > 0 {
+		r.events.Emit(events.Finished{Operation: "restack", Problems: p.SkipNotes})
+		r.events.Emit(events.Info{Message: "To resolve the conflicts, check out a skipped branch and run 'so restack --stop-on-conflict=halt'."})
+	} else {
+		r.events.Emit(events.StackRebased{Branches: rebasedBranches})
+	}
+
+	folded, err := r.foldMergedBranches(p.Merged, &currentBranch)
+	if err != nil {
+		return err
+	}
+	rebasedBranches = slices.DeleteFunc(rebasedBranches, func(b string) bool { return folded[b] })
 
 	// Determine if push is desired
 	doPush := false
@@ -192,12 +368,12 @@ func (r *restackCmdRunner) run(cmd *cobra.Command) error {
 		}
 
 		surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
-		err := survey.AskOne(prompt, &confirmPush, surveyOpts)
+		err := ui.AskOne(prompt, &confirmPush, surveyOpts)
 		if err != nil {
 			if err.Error() == "interrupt" {
 				return ui.HandleSurveyInterrupt(err, "Push cancelled.")
 			}
-			_, _ = fmt.Fprintf(r.stderr, "Push prompt failed: %v. Skipping push.\n", err)
+			r.events.Emit(events.Warning{Message: fmt.Sprintf("Push prompt failed: %v. Skipping push.", err)})
 		}
 		doPush = confirmPush
 	}
@@ -205,17 +381,22 @@ func (r *restackCmdRunner) run(cmd *cobra.Command) error {
 	// Execute push if needed
 	if doPush && len(rebasedBranches) > 0 {
 		r.logger.Debug("Force Pushing Updated Branches", "remoteName", remoteName, "count", len(rebasedBranches))
+		pushConfig, err := git.LoadPushConfig()
+		if err != nil {
+			return fmt.Errorf("failed to read push configuration: %w", err)
+		}
+		pushConfig = pushConfig.WithOptions(r.pushOptions...)
 		pushSuccessCount := 0
 		for _, branch := range rebasedBranches {
-			_, _ = fmt.Fprintf(r.stdout, "Pushing %s... ", branch)
-			err := git.PushBranchWithLease(branch, remoteName) // Use force-with-lease
+			if err := interrupt.Check(cmd.Context(), "the stack is restacked; run 'so restack --force-push' to push it"); err != nil {
+				return err
+			}
+			err := git.PushBranchWithLease(branch, remoteName, pushConfig) // Use force-with-lease
 			if err != nil {
-				_, _ = fmt.Fprintln(r.stdout, ui.Colors.FailureStyle.Render("Failed!"))
-				// Log error but continue trying other branches? Or abort?
-				_, _ = fmt.Fprintf(r.stderr, "  Error pushing %s: %v\n", branch, err)
-				// Let's continue for now
+				// Report and keep trying the other branches
+				r.events.Emit(events.PushFailed{Branch: branch, Remote: remoteName, Error: err.Error()})
 			} else {
-				_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Success."))
+				r.events.Emit(events.BranchPushed{Branch: branch, Remote: remoteName})
 				pushSuccessCount++
 			}
 		}
@@ -228,3 +409,384 @@ func (r *restackCmdRunner) run(cmd *cobra.Command) error {
 
 	return nil
 }
+
+// hasTrailers reports whether branch's commits already carry the wanted
+// trailers. With no trailers requested there is nothing to check.
+func (r *restackCmdRunner) hasTrailers(parent, branch string, trailers []git.Trailer) bool {
+	if len(trailers) == 0 {
+		return true
+	}
+	ok, err := git.BranchHasTrailers(parent, branch, trailers)
+	if err != nil {
+		r.logger.Debug("Could not read commit trailers, rewriting", "branch", branch, "error", err)
+		return false
+	}
+	return ok
+}
+
+// mergedIntoParent reports whether parent already has every commit of branch
+// (see git.MergedIntoParent). Errors only cost the note, so they are logged.
+// In sparse-safe mode it reports false: git cherry reads the blobs to compare
+// patches.
+func (r *restackCmdRunner) mergedIntoParent(parent, branch string) bool {
+	if git.IsSparseSafe() {
+		return false
+	}
+	merged, err := git.MergedIntoParent(parent, branch)
+	if err != nil {
+		r.logger.Debug("Could not compare commits with parent", "branch", branch, "parent", parent, "error", err)
+		return false
+	}
+	return merged
+}
+
+// foldMergedBranches offers to delete the branches the restack emptied
+// because their parent already had their commits, moving their children onto
+// that parent. currentBranch is moved along when it is deleted. It returns
+// the deleted branches.
+func (r *restackCmdRunner) foldMergedBranches(merged []restackStep, currentBranch *string) (map[string]bool, error) {
+	folded := map[string]bool{}
+	// Top of the stack first: children of a folded branch move onto its
+	// parent, which may be folded in turn.
+	for i := len(merged) - 1; i >= 0; i-- {
+		branch, parent := merged[i].branch, merged[i].parent
+		fold, err := r.confirmFold(branch, parent)
+		if err != nil {
+			return folded, err
+		}
+		if !fold {
+			continue
+		}
+
+		parents, err := git.GetAllSocleParents()
+		if err != nil {
+			return folded, fmt.Errorf("failed to read stack metadata: %w", err)
+		}
+		for _, child := range git.BuildChildMap(parents)[branch] {
+			if err := git.UpdateBranchParent(child, parent); err != nil {
+				return folded, fmt.Errorf("failed to move '%s' onto '%s': %w", child, parent, err)
+			}
+			r.events.Emit(events.Info{Message: fmt.Sprintf("  Moved '%s' onto '%s'.", child, parent)})
+		}
+
+		// Git refuses to delete the checked-out branch
+		if head, err := git.GetCurrentBranch(); err == nil && head == branch {
+			if err := git.CheckoutBranch(parent); err != nil {
+				return folded, fmt.Errorf("failed to check out '%s' before deleting '%s': %w", parent, branch, err)
+			}
+		}
+		if *currentBranch == branch {
+			*currentBranch = parent
+		}
+		if err := git.DeleteBranch(branch); err != nil {
+			return folded, err
+		}
+		folded[branch] = true
+		r.events.Emit(events.Success{Message: fmt.Sprintf("Deleted '%s', which was already merged into '%s'.", branch, parent)})
+	}
+	return folded, nil
+}
+
+// confirmFold decides whether the emptied branch is deleted: always with
+// --fold-merged, never without a terminal to ask on.
+func (r *restackCmdRunner) confirmFold(branch, parent string) (bool, error) {
+	if r.foldMerged {
+		return true, nil
+	}
+	if r.nonInteractive {
+		r.events.Emit(events.Info{Message: fmt.Sprintf("'%s' has no commits left on top of '%s'. Run 'so restack --fold-merged' to delete it and move its children onto '%s'.", branch, parent, parent)})
+		return false, nil
+	}
+	fold := false
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("'%s' has no commits left on top of '%s'. Delete it and move its children onto '%s'?", branch, parent, parent),
+		Default: false,
+	}
+	err := ui.AskOne(prompt, &fold, survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File)))
+	if err != nil {
+		if err.Error() == "interrupt" {
+			return false, ui.HandleSurveyInterrupt(err, "Restack cancelled.")
+		}
+		return false, fmt.Errorf("fold prompt failed: %w", err)
+	}
+	return fold, nil
+}
+
+// warnFlattenedMerges warns when branch contains merge commits that a plain
+// rebase is about to flatten.
+func (r *restackCmdRunner) warnFlattenedMerges(branch, parent string) {
+	merges, err := git.GetMergeCommits(parent, branch)
+	if err != nil {
+		r.logger.Debug("Failed to look for merge commits", "branch", branch, "error", err)
+		return
+	}
+	if len(merges) == 0 {
+		return
+	}
+	commits := make([]events.Commit, 0, len(merges))
+	for _, c := range merges {
+		commits = append(commits, events.Commit{OID: c.OID, Subject: c.Subject})
+	}
+	r.events.Emit(events.MergesFlattened{Branch: branch, Commits: commits, ConfigKey: git.BranchConfigKey(branch, "socle-rebase-merges")})
+}
+
+// rebaseMergesEnabled reports whether branch is rebased with --rebase-merges:
+// always with the flag, otherwise as configured (see git.RebaseMergesEnabled).
+func rebaseMergesEnabled(branch string, flag bool, logger *slog.Logger) bool {
+	if flag {
+		return true
+	}
+	enabled, err := git.RebaseMergesEnabled(branch)
+	if err != nil {
+		logger.Warn("Ignoring rebase-merges config", "branch", branch, "error", err)
+		return false
+	}
+	return enabled
+}
+
+// moveOnto points the current stack at the base branch r.onto and returns
+// the steps that rebase it there. Before any rebase, it records for every
+// branch the old tip of its parent, after which the branch's own commits
+// start, so only those are replayed; commits of the old base stay behind.
+// Because the metadata already names the new base, a plain 'so restack'
+// resumes the move after a conflict.
+func (r *restackCmdRunner) moveOnto(info *git.StackInfo) ([]restackStep, error) {
+	exists, err := git.BranchExists(r.onto)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("cannot move the stack onto '%s': no such local branch", r.onto)
+	}
+	if _, tracked := info.ParentMap[r.onto]; tracked {
+		return nil, fmt.Errorf("cannot move the stack onto '%s': it is a branch of a stack. --onto takes a base branch such as 'main'", r.onto)
+	}
+	if len(info.FullStack) < 2 {
+		return nil, fmt.Errorf("no stack to move onto '%s'", r.onto)
+	}
+
+	// Every branch stacked on the bottom one moves, not just the current line
+	bottom := info.FullStack[1]
+	steps := []restackStep{{branch: bottom, parent: r.onto}}
+	var walk func(parent string)
+	walk = func(parent string) {
+		children := append([]string(nil), info.ChildMap[parent]...)
+		sort.Strings(children)
+		for _, child := range children {
+			steps = append(steps, restackStep{branch: child, parent: parent})
+			walk(child)
+		}
+	}
+	walk(bottom)
+
+	for i, step := range steps {
+		oldParent := step.parent
+		if i == 0 {
+			oldParent = info.BaseBranch
+		}
+		oid, err := git.GetCurrentBranchCommit(oldParent)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get current commit of '%s': %w", oldParent, err)
+		}
+		if git.GetRestackUpstream(step.branch) != "" {
+			continue // An earlier move that has not finished; its commits still start there
+		}
+		if err := git.SetRestackUpstream(step.branch, oid); err != nil {
+			return nil, fmt.Errorf("failed to record where '%s' starts: %w", step.branch, err)
+		}
+	}
+	for _, step := range steps {
+		key := git.BranchConfigKey(step.branch, "socle-base")
+		if err := git.UnsetGitConfig(key); err != nil {
+			return nil, err
+		}
+		if err := git.SetGitConfig(key, r.onto); err != nil {
+			return nil, fmt.Errorf("failed to set socle-base config for '%s': %w", step.branch, err)
+		}
+	}
+	parentKey := git.BranchConfigKey(bottom, "socle-parent")
+	if err := git.UnsetGitConfig(parentKey); err != nil {
+		return nil, err
+	}
+	if err := git.SetGitConfig(parentKey, r.onto); err != nil {
+		return nil, fmt.Errorf("failed to set socle-parent config for '%s': %w", bottom, err)
+	}
+
+	r.events.Emit(events.Info{Message: fmt.Sprintf("Moving %d branch(es) from '%s' onto '%s'. Run 'so submit' afterwards to retarget the bottom PR.", len(steps), info.BaseBranch, r.onto)})
+	return steps, nil
+}
+
+// carriesOldBase reports whether branch, though it contains its parent, still
+// has the commits below upstream that a move with --onto leaves behind, as
+// when the new base is an ancestor of the old one.
+func carriesOldBase(upstream, parent, branch string) bool {
+	if upstream == "" {
+		return false
+	}
+	inBranch, err := git.IsAncestor(upstream, branch)
+	if err != nil || !inBranch {
+		return false
+	}
+	inParent, err := git.IsAncestor(upstream, parent)
+	return err == nil && !inParent
+}
+
+// finishMove forgets where branch's commits started once it sits on its new
+// parent.
+func (r *restackCmdRunner) finishMove(branch, upstream string) {
+	if upstream == "" {
+		return
+	}
+	if err := git.SetRestackUpstream(branch, ""); err != nil {
+		r.events.Emit(events.Warning{Branch: branch, Message: fmt.Sprintf("Failed to clear the recorded start of '%s': %v", branch, err)})
+	}
+}
+
+// restackSteps lists the rebases a restack performs, parents before children.
+// Normally that is the current stack, narrowed by scope; with allStacks it is
+// every tracked branch on the base, one stack after another.
+func restackSteps(info *git.StackInfo, scope string, allStacks bool) []restackStep {
+	if allStacks {
+		return restackBaseSteps(info.ChildMap, []string{info.BaseBranch})
+	}
+
+	var steps []restackStep
+	stack := info.FullStack
+	current := slices.Index(stack, info.CurrentBranch)
+	for i := 1; i < len(stack); i++ {
+		switch {
+		case scope == scopeUpstack && i < current:
+			continue
+		case scope == scopeDownstack && i > current:
+			continue
+		}
+		steps = append(steps, restackStep{branch: stack[i], parent: stack[i-1]})
+	}
+	return steps
+}
+
+// restackBaseSteps lists the rebases of every tracked branch on bases, base
+// by base and one stack after another, parents before children.
+func restackBaseSteps(childMap map[string][]string, bases []string) []restackStep {
+	var steps []restackStep
+	var walk func(parent string)
+	walk = func(parent string) {
+		children := append([]string(nil), childMap[parent]...)
+		sort.Strings(children)
+		for _, child := range children {
+			steps = append(steps, restackStep{branch: child, parent: parent})
+			walk(child)
+		}
+	}
+	for _, base := range bases {
+		walk(base)
+	}
+	return steps
+}
+
+// restackSubtreeSteps lists the rebases of each of roots onto its parent,
+// each followed by the branches above it, parents before children.
+func restackSubtreeSteps(parentMap map[string]string, childMap map[string][]string, roots []string) []restackStep {
+	var steps []restackStep
+	for _, root := range roots {
+		steps = append(steps, restackStep{branch: root, parent: parentMap[root]})
+		steps = append(steps, restackBaseSteps(childMap, []string{root})...)
+	}
+	return steps
+}
+
+// stackInfo returns the current branch's stack. When restacking given bases
+// or subtrees, the current branch needn't be tracked, so only the tracking
+// relationships are read.
+func (r *restackCmdRunner) stackInfo() (*git.StackInfo, error) {
+	if len(r.bases) == 0 && len(r.subtrees) == 0 {
+		return git.GetStackInfo()
+	}
+	currentBranch, err := git.GetCurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parentMap, err := git.GetAllSocleParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking relationships: %w", err)
+	}
+	var base string
+	if len(r.bases) > 0 {
+		base = r.bases[0]
+	} else {
+		// The subtrees share the base their first branch is stacked on
+		base = r.subtrees[0]
+		for i := 0; i <= len(parentMap) && parentMap[base] != ""; i++ {
+			base = parentMap[base]
+		}
+	}
+	return &git.StackInfo{
+		CurrentBranch: currentBranch,
+		BaseBranch:    base,
+		CurrentStack:  []string{currentBranch},
+		ParentMap:     parentMap,
+		ChildMap:      git.BuildChildMap(parentMap),
+	}, nil
+}
+
+// conflictEvent describes the paused rebase of branch, the position-th of
+// total, from git's rebase state, and which branch below it changed each
+// conflicting file. Missing state only leaves details out.
+func (r *restackCmdRunner) conflictEvent(branch, parent string, position, total int) events.RebaseConflict {
+	e := events.RebaseConflict{Branch: branch, Parent: parent, Position: position, Total: total}
+	state, err := git.ReadRebaseState()
+	if err != nil || state == nil {
+		r.logger.Debug("No rebase state for conflict details", "branch", branch, "error", err)
+		return e
+	}
+	e.Onto = state.Onto
+	e.Files = state.Conflicted
+	if len(e.Files) > 0 {
+		owners, err := git.ConflictOwners(stackChain(parent), e.Files)
+		if err != nil {
+			r.logger.Debug("Could not tell whose changes conflict", "branch", branch, "error", err)
+		} else {
+			e.Owners = owners
+		}
+	}
+	for _, c := range state.Commits {
+		e.Commits = append(e.Commits, events.Commit{OID: c.OID, Subject: c.Subject})
+	}
+	if state.Stopped.OID != "" {
+		e.Stopped = &events.Commit{OID: state.Stopped.OID, Subject: state.Stopped.Subject}
+	}
+	return e
+}
+
+// stackChain returns branch and the tracked branches below it, base first.
+func stackChain(branch string) []string {
+	parents, err := git.GetAllSocleParents()
+	if err != nil {
+		return []string{branch}
+	}
+	chain := []string{branch}
+	seen := map[string]bool{branch: true}
+	for parent, ok := parents[branch]; ok && !seen[parent]; parent, ok = parents[parent] {
+		seen[parent] = true
+		chain = append([]string{parent}, chain...)
+	}
+	return chain
+}
+
+// conflictPolicy returns --stop-on-conflict when given, else
+// socle.stopOnConflict.
+func conflictPolicy(cmd *cobra.Command) (string, error) {
+	policy := mustGetString(cmd, "stop-on-conflict")
+	if !cmd.Flags().Changed("stop-on-conflict") {
+		if value, err := git.GetSocleConfig("socle.stopOnConflict"); err == nil {
+			policy = value
+		} else if !errors.Is(err, git.ErrConfigNotFound) {
+			return "", err
+		}
+	}
+	switch policy = strings.ToLower(strings.TrimSpace(policy)); policy {
+	case conflictHalt, conflictSkip:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid --stop-on-conflict '%s': expected halt or skip", policy)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+)
+
+// restackProgress is how far a restack got. A restack that stops on a
+// conflict or is interrupted saves it with the options it ran with, so
+// 'so continue' can finish the paused rebase and go on with the remaining
+// steps as the restack would have.
+type restackProgress struct {
+	OriginalBranch string          `json:"originalBranch"` // Checked out again at the end
+	BaseBranch     string          `json:"baseBranch"`
+	Steps          []restackStep   `json:"steps"`
+	Next           int             `json:"next"`                   // Step being run; the paused one after a conflict
+	PausedMerged   bool            `json:"pausedMerged,omitempty"` // The paused branch's parent already had its commits
+	Interrupted    bool            `json:"interrupted,omitempty"`  // Ctrl+C stopped the restack before step Next
+	Rebased        []string        `json:"rebased,omitempty"`
+	Merged         []restackStep   `json:"merged,omitempty"`
+	Skipped        map[string]bool `json:"skipped,omitempty"`
+	SkipNotes      []string        `json:"skipNotes,omitempty"`
+	Options        restackOptions  `json:"options"`
+}
+
+// restackOptions are the flags of a paused restack that still matter for
+// the steps after the conflict.
+type restackOptions struct {
+	ForcePush   bool     `json:"forcePush,omitempty"`
+	NoPush      bool     `json:"noPush,omitempty"`
+	PushOptions []string `json:"pushOptions,omitempty"`
+	Trailers    bool     `json:"trailers,omitempty"`
+	KeepMerges  bool     `json:"keepMerges,omitempty"`
+	OnConflict  string   `json:"onConflict,omitempty"`
+	FoldMerged  bool     `json:"foldMerged,omitempty"`
+}
+
+func (s restackStep) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Branch string `json:"branch"`
+		Parent string `json:"parent"`
+	}{s.branch, s.parent})
+}
+
+func (s *restackStep) UnmarshalJSON(data []byte) error {
+	var saved struct {
+		Branch string `json:"branch"`
+		Parent string `json:"parent"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	s.branch, s.parent = saved.Branch, saved.Parent
+	return nil
+}
+
+// options returns the flags of r worth keeping for 'so continue'.
+func (r *restackCmdRunner) options() restackOptions {
+	return restackOptions{
+		ForcePush:   r.forcePush,
+		NoPush:      r.noPush,
+		PushOptions: r.pushOptions,
+		Trailers:    r.trailers,
+		KeepMerges:  r.keepMerges,
+		OnConflict:  r.onConflict,
+		FoldMerged:  r.foldMerged,
+	}
+}
+
+// withOptions sets the flags of r from a saved restack.
+func (r *restackCmdRunner) withOptions(o restackOptions) *restackCmdRunner {
+	r.forcePush = o.ForcePush
+	r.noPush = o.NoPush
+	r.pushOptions = o.PushOptions
+	r.trailers = o.Trailers
+	r.keepMerges = o.KeepMerges
+	r.onConflict = o.OnConflict
+	r.foldMerged = o.FoldMerged
+	return r
+}
+
+// saveRestackProgress writes p for 'so continue'.
+func saveRestackProgress(p *restackProgress) error {
+	path, err := git.RestackStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal restack progress: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create '%s': %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save restack progress: %w", err)
+	}
+	return nil
+}
+
+// loadRestackProgress reads the progress of the paused restack. It returns
+// nil without an error when no restack is paused.
+func loadRestackProgress() (*restackProgress, error) {
+	path, err := git.RestackStatePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read restack progress: %w", err)
+	}
+	var p restackProgress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse restack progress in '%s': %w", path, err)
+	}
+	if p.Next < 0 || p.Next >= len(p.Steps) {
+		return nil, fmt.Errorf("restack progress in '%s' is inconsistent; remove it and run 'so restack'", path)
+	}
+	if p.Skipped == nil {
+		p.Skipped = map[string]bool{}
+	}
+	return &p, nil
+}
+
+// clearRestackProgress forgets the paused restack, if any.
+func clearRestackProgress() error {
+	path, err := git.RestackStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove restack progress: %w", err)
+	}
+	return nil
+}
+
+// restackPaused reports whether 'so continue' has a restack to resume.
+func restackPaused() bool {
+	path, err := git.RestackStatePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// ownsRebase reports whether the rebase in progress is the one p paused in,
+// i.e. it rebases the branch of step Next, not one the user started with
+// git after the restack stopped.
+func (p *restackProgress) ownsRebase() bool {
+	head, err := git.RebaseHeadName()
+	return err == nil && head == p.Steps[p.Next].branch
+}
@@ -2,10 +2,19 @@
 package cmd
 
 import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/benekuehn/socle/cli/so/internal/events"
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
 	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -68,6 +77,143 @@ func TestRestackCommand(t *testing.T) {
 		assert.Equal(t, hashA2, parentB, "feature-b should now be based on new feature-a")
 	})
 
+	t.Run("Localized git messages do not change the outcome", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		t.Setenv("LANG", "de_DE.UTF-8")
+		t.Setenv("LANGUAGE", "de")
+		t.Setenv("LC_ALL", "de_DE.UTF-8")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		writeFile(t, repoPath, "main_change.txt", "change")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+
+		// Without a remote, the fetch is skipped based on git's exit code.
+		_, stderr, err := runSoCommandWithOutput(t, "restack", "--non-interactive")
+
+		require.NoError(t, err)
+		assert.Empty(t, stderr)
+		mainHash, _ := git.GetCurrentBranchCommit("main")
+		parentA, _ := git.GetMergeBase("main", "feature-a")
+		assert.Equal(t, mainHash, parentA)
+
+		// The user's locale never reaches the git commands socle parses.
+		testutils.RunCommand(t, repoPath, "git", "config", "alias.locale", "!echo $LC_ALL")
+		locale, err := git.RunGitCommand("locale")
+		require.NoError(t, err)
+		assert.Equal(t, "C", locale)
+		testutils.RunCommand(t, repoPath, "git", "config", "alias.prompt", "!echo $GIT_TERMINAL_PROMPT")
+		prompt, err := git.RunGitCommand("prompt")
+		require.NoError(t, err)
+		assert.Equal(t, "0", prompt, "only fetches and pushes may ask for credentials")
+
+		// Read-only plumbing ignores the system config; other commands keep it.
+		systemConfig := filepath.Join(t.TempDir(), "gitconfig")
+		writeFile(t, filepath.Dir(systemConfig), "gitconfig", "[core]\n\tabbrev = 20\n")
+		t.Setenv("GIT_CONFIG_SYSTEM", systemConfig)
+		t.Setenv("GIT_CONFIG_NOSYSTEM", "")
+		short, err := git.RunGitCommand("log", "-1", "--format=%h")
+		require.NoError(t, err)
+		assert.Len(t, short, 20)
+		short, err = git.RunGitCommand("rev-parse", "--short", "HEAD")
+		require.NoError(t, err)
+		assert.Less(t, len(short), 20)
+	})
+
+	t.Run("Branches checked out in another worktree are skipped", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		writeFile(t, repoPath, "main_change.txt", "change")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+		worktreePath := filepath.Join(t.TempDir(), "feature-b")
+		testutils.RunCommand(t, repoPath, "git", "worktree", "add", "--quiet", worktreePath, "feature-b")
+		oldB, _ := git.GetCurrentBranchCommit("feature-b")
+
+		stdout, stderr, err := runSoCommandWithOutput(t, "restack", "--non-interactive")
+
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout+stderr), "'feature-b' is checked out in the worktree at")
+		mainHash, _ := git.GetCurrentBranchCommit("main")
+		parentA, _ := git.GetMergeBase("main", "feature-a")
+		assert.Equal(t, mainHash, parentA)
+		newB, _ := git.GetCurrentBranchCommit("feature-b")
+		assert.Equal(t, oldB, newB, "feature-b must stay untouched")
+		cur, _ := git.GetCurrentBranch()
+		assert.Equal(t, "feature-a", cur)
+	})
+
+	t.Run("Branches already merged into their parent are emptied and can be folded", func(t *testing.T) {
+		setup := func(t *testing.T) string {
+			repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+			t.Cleanup(cleanup)
+			// feature-b's commit lands in feature-a, e.g. cherry-picked there
+			testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+			writeFile(t, repoPath, "a_change.txt", "change")
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-m", "change feature-a")
+			testutils.RunCommand(t, repoPath, "git", "cherry-pick", "feature-b")
+			testutils.RunCommand(t, repoPath, "git", "checkout", "feature-b")
+			return repoPath
+		}
+
+		t.Run("non-interactive restack empties the branch and suggests folding", func(t *testing.T) {
+			setup(t)
+
+			stdout, stderr, err := runSoCommandWithOutput(t, "restack", "--non-interactive")
+
+			require.NoError(t, err)
+			output := stripAnsi(stdout + stderr)
+			assert.Contains(t, output, "'feature-b' is already merged into 'feature-a'; dropping its commits.")
+			assert.Contains(t, output, "Run 'so restack --fold-merged' to delete it")
+			hashA, _ := git.GetCurrentBranchCommit("feature-a")
+			hashB, _ := git.GetCurrentBranchCommit("feature-b")
+			assert.Equal(t, hashA, hashB, "feature-b should have no commits left")
+			exists, _ := git.BranchExists("feature-b")
+			assert.True(t, exists)
+		})
+
+		t.Run("sparse-safe mode does not compare patches", func(t *testing.T) {
+			repoPath := setup(t)
+			testutils.RunCommand(t, repoPath, "git", "config", "socle.sparseSafe", "true")
+
+			stdout, stderr, err := runSoCommandWithOutput(t, "restack", "--non-interactive")
+
+			require.NoError(t, err)
+			output := stripAnsi(stdout + stderr)
+			assert.NotContains(t, output, "already merged into")
+			hashA, _ := git.GetCurrentBranchCommit("feature-a")
+			onA, _ := git.IsAncestor(hashA, "feature-b")
+			assert.True(t, onA, "feature-b is still restacked")
+		})
+
+		t.Run("fold-merged deletes the branch and moves its children onto the parent", func(t *testing.T) {
+			setup(t)
+			resetCommandFlags(t, restackCmd, "fold-merged")
+
+			stdout, _, err := runSoCommandWithOutput(t, "restack", "--non-interactive", "--fold-merged")
+
+			require.NoError(t, err)
+			output := stripAnsi(stdout)
+			assert.Contains(t, output, "Moved 'feature-c' onto 'feature-a'.")
+			assert.Contains(t, output, "Deleted 'feature-b', which was already merged into 'feature-a'.")
+			exists, _ := git.BranchExists("feature-b")
+			assert.False(t, exists)
+			parent, _ := git.GetGitConfig("branch.feature-c.socle-parent")
+			assert.Equal(t, "feature-a", parent)
+			hashA, _ := git.GetCurrentBranchCommit("feature-a")
+			onA, _ := git.IsAncestor(hashA, "feature-c")
+			assert.True(t, onA)
+			cur, _ := git.GetCurrentBranch()
+			assert.Equal(t, "feature-a", cur, "the deleted current branch is replaced by its parent")
+		})
+	})
+
 	t.Run("Conflict during rebase", func(t *testing.T) {
 		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
 		defer cleanup()
@@ -94,13 +240,309 @@ func TestRestackCommand(t *testing.T) {
 		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
 
 		// Action
-		err := runSoCommand(t, "restack", "--no-fetch") // Should conflict
+		_, stderr, err := runSoCommandWithOutput(t, "restack", "--no-fetch") // Should conflict
 
 		// Assertions
-		require.NoError(t, err, "so restack should exit cleanly (nil error) on conflict")
+		require.Error(t, err)
+		assert.Equal(t, socleerr.Conflict, socleerr.KindOf(err), "so restack should report the conflict")
 		// Check Git state
 		isRebasing := git.IsRebaseInProgress()
 		assert.True(t, isRebasing, "Git should be in a rebase state after conflict")
-		// TODO: Capture stderr and assert the conflict message was printed? More complex.
+
+		// The message says what is being resolved
+		out := stripAnsi(stderr)
+		mainOID := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "--short=7", "main"))
+		assert.Contains(t, out, "Branch:  feature-a (1/1)")
+		assert.Contains(t, out, "Onto:    main at "+mainOID)
+		// setupRepoWithStack leaves feature-a checked out, so the first
+		// file.txt commit landed on feature-a rather than main
+		assert.Contains(t, out, "Replaying 3 commit(s):")
+		assert.Regexp(t, `✓ [0-9a-f]{7} feat: commit on feature-a`, out)
+		assert.Regexp(t, `✗ [0-9a-f]{7} add file on main  <- conflicts`, out)
+		assert.Regexp(t, `\n      [0-9a-f]{7} change file on feature-a`, out)
+		assert.Contains(t, out, "Conflicting files:\n    file.txt")
+	})
+
+	t.Run("Conflict names the branch whose changes conflict", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+
+		// feature-b and feature-c both change shared.txt
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+		writeFile(t, repoPath, "shared.txt", "b")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "shared on feature-b")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-c")
+		testutils.RunCommand(t, repoPath, "git", "rebase", "-q", "feature-b")
+		writeFile(t, repoPath, "shared.txt", "c")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "shared on feature-c")
+		// Rewriting feature-b makes feature-c's change to shared.txt conflict
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+		writeFile(t, repoPath, "shared.txt", "b2")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-a", "--amend", "-m", "shared on feature-b")
+
+		_, stderr, err := runSoCommandWithOutput(t, "restack", "--no-fetch")
+		require.Error(t, err)
+		out := stripAnsi(stderr)
+		assert.Contains(t, out, "Branch:  feature-c (3/3)")
+		assert.Contains(t, out, "shared.txt  (conflicts with changes from 'feature-b')")
+		require.NoError(t, git.AbortRebase())
+	})
+
+	t.Run("Push forwards configured and flag push options", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		// Bare remote that accepts push options and records them via a hook
+		remotePath := filepath.Join(t.TempDir(), "remote.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--bare", remotePath)
+		testutils.RunCommand(t, remotePath, "git", "config", "receive.advertisePushOptions", "true")
+		recordPath := filepath.Join(t.TempDir(), "push-options.txt")
+		hook := "#!/bin/sh\ni=0\nwhile [ $i -lt \"${GIT_PUSH_OPTION_COUNT:-0}\" ]; do\n  eval \"echo \\$GIT_PUSH_OPTION_$i\" >> " + recordPath + "\n  i=$((i+1))\ndone\n"
+		hookPath := filepath.Join(remotePath, "hooks", "pre-receive")
+		require.NoError(t, os.WriteFile(hookPath, []byte(hook), 0755))
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.pushOptions", "ci.skip")
+
+		err := runSoCommand(t, "restack", "--no-fetch", "--force-push", "--push-option=merge_request.create")
+
+		require.NoError(t, err)
+		recorded, errRead := os.ReadFile(recordPath)
+		require.NoError(t, errRead, "pre-receive hook should have recorded push options")
+		assert.Equal(t, []string{"ci.skip", "merge_request.create"}, strings.Fields(string(recorded)))
+	})
+
+	t.Run("Commit trailers are maintained when enabled", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "git@github.com:example/repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.commitTrailers", "true")
+		require.NoError(t, git.SetStoredPRNumber("feature-a", 42))
+
+		err := runSoCommand(t, "restack", "--no-fetch")
+		require.NoError(t, err)
+
+		trailersA := testutils.RunCommand(t, repoPath, "git", "log", "-1", "--format=%(trailers:only,unfold)", "feature-a")
+		assert.Contains(t, trailersA, "Stacked-on: main")
+		assert.Contains(t, trailersA, "PR: https://github.com/example/repo/pull/42")
+		trailersB := testutils.RunCommand(t, repoPath, "git", "log", "-1", "--format=%(trailers:only,unfold)", "feature-b")
+		assert.Contains(t, trailersB, "Stacked-on: feature-a")
+		assert.NotContains(t, trailersB, "PR:")
+
+		needsRestack, err := git.NeedsRestack("feature-a", "feature-b")
+		require.NoError(t, err)
+		assert.False(t, needsRestack, "feature-b should be based on the rewritten feature-a")
+
+		// A second run finds the trailers current and leaves the commits alone
+		hashA, _ := git.GetCurrentBranchCommit("feature-a")
+		hashB, _ := git.GetCurrentBranchCommit("feature-b")
+		require.NoError(t, runSoCommand(t, "restack", "--no-fetch"))
+		hashA2, _ := git.GetCurrentBranchCommit("feature-a")
+		hashB2, _ := git.GetCurrentBranchCommit("feature-b")
+		assert.Equal(t, hashA, hashA2)
+		assert.Equal(t, hashB, hashB2)
+
+		// PRs on GitHub Enterprise link to their own host
+		testutils.RunCommand(t, repoPath, "git", "remote", "set-url", "origin", "git@ghe.example.com:example/repo.git")
+		require.NoError(t, runSoCommand(t, "restack", "--no-fetch"))
+		trailersA = testutils.RunCommand(t, repoPath, "git", "log", "-1", "--format=%(trailers:only,unfold)", "feature-a")
+		assert.Contains(t, trailersA, "PR: https://ghe.example.com/example/repo/pull/42")
+	})
+
+	t.Run("Merge commits are flattened with a warning unless rebase-merges is on", func(t *testing.T) {
+		resetCommandFlags(t, restackCmd, "no-fetch", "no-push", "force-push", "rebase-merges")
+
+		// feature-a merges a side branch; then main moves on.
+		setup := func(t *testing.T) string {
+			resetCommandFlags(t, restackCmd, "no-fetch", "no-push", "force-push", "rebase-merges")
+			repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+			t.Cleanup(cleanup)
+			testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "side", "feature-a")
+			writeFile(t, repoPath, "side.txt", "side")
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-m", "side work")
+			testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+			testutils.RunCommand(t, repoPath, "git", "merge", "--no-ff", "-m", "Merge side into feature-a", "side")
+			testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+			writeFile(t, repoPath, "main_change.txt", "change")
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on main")
+			testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+			return repoPath
+		}
+
+		t.Run("plain rebase warns and flattens", func(t *testing.T) {
+			setup(t)
+			_, stderr, err := runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push")
+			require.NoError(t, err)
+			assert.Contains(t, stderr, "'feature-a' contains 1 merge commit(s); a plain rebase flattens them")
+			assert.Contains(t, stderr, "Merge side into feature-a")
+
+			merges, err := git.GetMergeCommits("main", "feature-a")
+			require.NoError(t, err)
+			assert.Empty(t, merges)
+		})
+
+		t.Run("per-branch config keeps the merge", func(t *testing.T) {
+			repoPath := setup(t)
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-rebase-merges", "true")
+			_, stderr, err := runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push")
+			require.NoError(t, err)
+			assert.NotContains(t, stderr, "plain rebase flattens")
+
+			merges, err := git.GetMergeCommits("main", "feature-a")
+			require.NoError(t, err)
+			require.Len(t, merges, 1)
+			assert.Equal(t, "Merge side into feature-a", merges[0].Subject)
+			onMain, _ := git.IsAncestor("main", "feature-a")
+			assert.True(t, onMain)
+		})
+
+		t.Run("flag keeps the merge", func(t *testing.T) {
+			setup(t)
+			_, _, err := runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push", "--rebase-merges")
+			require.NoError(t, err)
+
+			merges, err := git.GetMergeCommits("main", "feature-a")
+			require.NoError(t, err)
+			assert.Len(t, merges, 1)
+		})
+	})
+	t.Run("Events can be rendered as JSON lines", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		writeFile(t, repoPath, "main_change.txt", "change")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-b")
+
+		var out, text bytes.Buffer
+		runner := &restackCmdRunner{
+			logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+			stdout:  &text,
+			stderr:  &text,
+			events:  events.New(events.NewJSONRenderer(&out)),
+			noFetch: true,
+			noPush:  true,
+		}
+		require.NoError(t, runner.run(&cobra.Command{}))
+
+		assert.Empty(t, text.String(), "nothing should bypass the renderer")
+		assert.Equal(t, []string{
+			`{"event":"branch_rebased","branch":"feature-a","parent":"main"}`,
+			`{"event":"branch_rebased","branch":"feature-b","parent":"feature-a"}`,
+			`{"event":"stack_rebased","branches":["feature-a","feature-b"]}`,
+		}, strings.Split(strings.TrimSpace(out.String()), "\n"))
+	})
+	t.Run("Skip policy leaves a conflicting branch and its descendants and restacks sibling stacks", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature-c", "main")
+		writeFile(t, repoPath, "feature-c.txt", "c")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on feature-c")
+		require.NoError(t, runSoCommand(t, "track", "--test-parent=main"))
+
+		// main now conflicts with feature-a only
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		writeFile(t, repoPath, "feature-a.txt", "main")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "conflict with feature-a")
+		featureB := testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-b")
+
+		var out bytes.Buffer
+		runner := &restackCmdRunner{
+			logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+			stdout:     io.Discard,
+			stderr:     io.Discard,
+			events:     events.New(events.NewJSONRenderer(&out)),
+			noFetch:    true,
+			noPush:     true,
+			onConflict: conflictSkip,
+			allStacks:  true,
+		}
+		require.NoError(t, runner.run(&cobra.Command{}))
+
+		assert.False(t, git.IsRebaseInProgress(), "the conflicting rebase is aborted")
+		assert.Equal(t, featureB, testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-b"))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Equal(t, []string{
+			`{"event":"branch_skipped","branch":"feature-a","reason":"rebasing 'feature-a' onto 'main' conflicts; left as it was."}`,
+			`{"event":"branch_skipped","branch":"feature-b","reason":"'feature-b' stays on 'feature-a', which was skipped."}`,
+			`{"event":"branch_rebased","branch":"feature-c","parent":"main"}`,
+		}, lines[:3])
+		assert.Contains(t, lines[3], `"event":"finished"`)
+		stale, err := git.NeedsRestack("main", "feature-c")
+		require.NoError(t, err)
+		assert.False(t, stale)
+	})
+	t.Run("Downstack restacks only up to the current branch", func(t *testing.T) {
+		resetCommandFlags(t, restackCmd, "no-fetch", "no-push", "downstack", "stop-on-conflict")
+
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		writeFile(t, repoPath, "main_change.txt", "change")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+
+		_, _, err := runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push", "--downstack")
+		require.NoError(t, err)
+		stale, err := git.NeedsRestack("main", "feature-a")
+		require.NoError(t, err)
+		assert.False(t, stale, "feature-a is restacked")
+		stale, err = git.NeedsRestack("feature-a", "feature-b")
+		require.NoError(t, err)
+		assert.True(t, stale, "feature-b is above the current branch and left alone")
+
+		_, _, err = runSoCommandWithOutput(t, "restack", "--stop-on-conflict=later")
+		require.ErrorContains(t, err, "invalid --stop-on-conflict")
+	})
+	t.Run("Onto moves the stack to another base without the old base's commits", func(t *testing.T) {
+		resetCommandFlags(t, restackCmd, "no-fetch", "no-push", "onto")
+
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "develop")
+		writeFile(t, repoPath, "develop.txt", "develop")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on develop")
+		for _, step := range []restackStep{{branch: "feature-a", parent: "develop"}, {branch: "feature-b", parent: "feature-a"}} {
+			branch, parent := step.branch, step.parent
+			testutils.RunCommand(t, repoPath, "git", "checkout", parent)
+			testutils.RunCommand(t, repoPath, "git", "checkout", "-b", branch)
+			writeFile(t, repoPath, branch+".txt", branch)
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on "+branch)
+			require.NoError(t, runSoCommand(t, "track", "--test-parent="+parent))
+		}
+
+		_, _, err := runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push", "--onto", "nowhere")
+		require.ErrorContains(t, err, "no such local branch")
+
+		stdout, _, err := runSoCommandWithOutput(t, "restack", "--no-fetch", "--no-push", "--onto", "main")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "Moving 2 branch(es) from 'develop' onto 'main'")
+
+		log := testutils.RunCommand(t, repoPath, "git", "log", "--format=%s", "main..feature-b")
+		assert.Equal(t, []string{"feat: commit on feature-b", "feat: commit on feature-a"}, strings.Split(strings.TrimSpace(log), "\n"))
+		for _, branch := range []string{"feature-a", "feature-b"} {
+			base, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-base"))
+			require.NoError(t, err)
+			assert.Equal(t, "main", base)
+			assert.Empty(t, git.GetRestackUpstream(branch))
+		}
+		parent, err := git.GetGitConfig(git.BranchConfigKey("feature-a", "socle-parent"))
+		require.NoError(t, err)
+		assert.Equal(t, "main", parent)
+
+		stale, err := git.NeedsRestack("feature-a", "feature-b")
+		require.NoError(t, err)
+		assert.False(t, stale)
 	})
 }
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <snapshot | branch>",
+	Short: "Restore branches and socle metadata from a snapshot, or a deleted branch",
+	Long: `Given a snapshot, moves every branch recorded in it back to its recorded
+commit, recreating branches that were deleted since, and replaces all socle
+metadata with the snapshot's. Branches created after the snapshot are left in
+place but lose their socle tracking. Base branches such as main are never
+moved. The working tree must be clean, and no branch to move may be checked
+out in another worktree. See
+'so snapshot' for creating snapshots.
+
+Given the name of a branch 'so sync' deleted, recreates it at the commit it
+pointed to with its parent, base and PR number. Deleted branches are kept for
+socle.tombstoneDays days (default 14; 0 keeps none). Branches that were
+moved onto the deleted branch's parent stay there; use 'so track' to move
+them back. If the branch's parent was deleted too, restore the parent first;
+a parent that cannot be restored is replaced by the branch's base.
+
+A snapshot wins when a snapshot and a deleted branch share the name.`,
+	Args: cobra.ExactArgs(1),
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		runner := &restoreCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			name:   args[0],
+		}
+		return runner.run()
+	}),
+}
+
+func init() {
+	AddCommand(restoreCmd)
+}
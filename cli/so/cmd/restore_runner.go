@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type restoreCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	name string
+}
+
+func (r *restoreCmdRunner) run() error {
+	snapshot, err := git.ReadSnapshot(r.name)
+	if errors.Is(err, git.ErrRefNotFound) {
+		tombstone, errTombstone := git.ReadTombstone(r.name)
+		if errTombstone == nil {
+			return r.restoreBranch(tombstone)
+		}
+		if !errors.Is(errTombstone, git.ErrRefNotFound) {
+			return errTombstone
+		}
+		return fmt.Errorf("no snapshot or deleted branch named '%s'", r.name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if git.IsRebaseInProgress() {
+		return socleerr.New(socleerr.Conflict, "a rebase is in progress. Finish or abort it before restoring a snapshot")
+	}
+	hasChanges, err := git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if hasChanges {
+		return socleerr.New(socleerr.DirtyWorktree, "uncommitted changes detected. Please commit or stash them before restoring")
+	}
+
+	moved, err := git.RestoreSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	r.logger.Debug("Restored snapshot", "name", r.name, "moved", moved)
+
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Restored snapshot '%s'.", r.name)))
+	if len(moved) > 0 {
+		_, _ = fmt.Fprintf(r.stdout, "Moved branches: %s\n", strings.Join(moved, ", "))
+	} else {
+		_, _ = fmt.Fprintln(r.stdout, "All branches were already at their snapshot commits.")
+	}
+	return nil
+}
+
+// restoreBranch recreates a branch deleted by socle from its tombstone.
+func (r *restoreCmdRunner) restoreBranch(tombstone *git.Tombstone) error {
+	parent, reparented, err := settleRestoredParent(tombstone)
+	if err != nil {
+		return err
+	}
+	if err := git.RestoreTombstone(tombstone); err != nil {
+		return err
+	}
+	r.logger.Debug("Restored deleted branch", "branch", tombstone.Branch, "oid", tombstone.OID, "deletedAt", tombstone.DeletedAt)
+
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Restored branch '%s' at %s.", tombstone.Branch, tombstone.OID[:min(8, len(tombstone.OID))])))
+	switch {
+	case parent == "":
+	case reparented:
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf(
+			"Warning: its parent no longer exists, so it is tracked on '%s' instead. Run 'so restack' to move it there, or pick another parent with 'so track'.", parent)))
+	default:
+		_, _ = fmt.Fprintf(r.stdout, "It is tracked on '%s' again. Run 'so restack' if its parent moved since.\n", parent)
+	}
+	return nil
+}
+
+// settleRestoredParent makes sure a tombstone's branch comes back on a parent
+// that exists. A parent deleted with a tombstone of its own has to be
+// restored first; one that is gone for good is replaced by the branch's base
+// in the tombstone's metadata. It returns the parent the branch will have,
+// empty if it was not tracked.
+func settleRestoredParent(tombstone *git.Tombstone) (parent string, reparented bool, err error) {
+	parentKey := git.BranchConfigKey(tombstone.Branch, "socle-parent")
+	values := tombstone.Metadata[parentKey]
+	if len(values) == 0 {
+		return "", false, nil
+	}
+	parent = values[len(values)-1]
+	exists, err := git.BranchExists(parent)
+	if err != nil || exists {
+		return parent, false, err
+	}
+
+	if _, err := git.ReadTombstone(parent); err == nil {
+		return "", false, fmt.Errorf("the parent of '%s', '%s', was deleted too; restore it first with 'so restore %s'", tombstone.Branch, parent, parent)
+	}
+	bases := tombstone.Metadata[git.BranchConfigKey(tombstone.Branch, "socle-base")]
+	if len(bases) == 0 {
+		return "", false, fmt.Errorf("cannot restore '%s': its parent '%s' no longer exists and it has no base to fall back to", tombstone.Branch, parent)
+	}
+	base := bases[len(bases)-1]
+	if exists, err := git.BranchExists(base); err != nil || !exists {
+		return "", false, fmt.Errorf("cannot restore '%s': neither its parent '%s' nor its base '%s' exists", tombstone.Branch, parent, base)
+	}
+	tombstone.Metadata[parentKey] = []string{base}
+	return base, true, nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreCommand(t *testing.T) {
+	t.Run("Branches named like a directory of each other both get tombstones", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "foo"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		tipFoo := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "foo"))
+		require.NoError(t, git.DeleteBranch("foo"))
+
+		testutils.RunCommand(t, repoPath, "git", "branch", "foo/bar", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.foo/bar.socle-parent", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.foo/bar.socle-base", "main")
+		require.NoError(t, git.DeleteBranch("foo/bar"))
+
+		stdout, _, err := runSoCommandWithOutput(t, "restore", "foo")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Restored branch 'foo' at "+tipFoo[:8]+".")
+		testutils.RunCommand(t, repoPath, "git", "branch", "-D", "-q", "foo")
+
+		_, _, err = runSoCommandWithOutput(t, "restore", "foo/bar")
+		require.NoError(t, err)
+		assert.Equal(t, "main", strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "branch.foo/bar.socle-parent")))
+	})
+
+	t.Run("A branch whose parent is gone for good is restored onto its base", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		require.NoError(t, git.DeleteBranch("feature-b"))
+		testutils.RunCommand(t, repoPath, "git", "branch", "-D", "-q", "feature-a") // No tombstone
+
+		_, stderr, err := runSoCommandWithOutput(t, "restore", "feature-b")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stderr), "it is tracked on 'main' instead")
+		assert.Equal(t, "main", strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "branch.feature-b.socle-parent")))
+		violations, err := git.CheckStackInvariants()
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Review a stack's pull requests locally",
+	Long: `Groups commands for reviewers who have the repository checked out and want to
+read a stack the way its author built it, one branch on top of the other.`,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	AddCommand(reviewCmd)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var reviewNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Walk up the stack one branch at a time, showing each branch's diffstat and PR",
+	Long: `Checks out the next branch of the current stack and shows what its pull
+request changes relative to its parent: the commits, the diffstat and the PR
+link. Press Enter to move on to the branch above, or q to stop on the current
+one; running 'so review next' again continues from there.
+
+From the base branch, or with --restart, the walk starts at the bottom of the
+stack. Without a terminal (or with --non-interactive) one branch is shown per
+run.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		restart, _ := cmd.Flags().GetBool("restart")
+		runner := &reviewNextCmdRunner{
+			logger:         slog.Default(),
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          cmd.InOrStdin(),
+			nonInteractive: nonInteractive,
+			restart:        restart,
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewNextCmd)
+	reviewNextCmd.Flags().Bool("restart", false, "Start again at the bottom of the stack")
+}
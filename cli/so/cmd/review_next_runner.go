@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type reviewNextCmdRunner struct {
+	logger         *slog.Logger
+	stdout         io.Writer
+	stderr         io.Writer
+	stdin          io.Reader
+	nonInteractive bool
+
+	restart bool
+}
+
+func (r *reviewNextCmdRunner) run() error {
+	hasChanges, err := git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if hasChanges {
+		return socleerr.New(socleerr.DirtyWorktree, "uncommitted changes detected. Please commit or stash them before reviewing")
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	stack := stackInfo.FullStack
+	if len(stack) <= 1 {
+		return fmt.Errorf("not on a stack: check out a tracked branch of the stack first")
+	}
+
+	next := 1
+	if !r.restart {
+		for i, branch := range stack {
+			if branch == stackInfo.CurrentBranch {
+				next = max(1, i+1)
+			}
+		}
+	}
+	if next >= len(stack) {
+		_, _ = fmt.Fprintf(r.stdout, "'%s' is the top of the stack; nothing left to review. Use --restart to start again at the bottom.\n", stackInfo.CurrentBranch)
+		return nil
+	}
+
+	prURL := r.prURLFunc()
+	input := bufio.NewReader(r.stdin)
+	for i := next; i < len(stack); i++ {
+		if err := git.CheckoutBranch(stack[i]); err != nil {
+			return err
+		}
+		if err := r.show(i, stack, prURL); err != nil {
+			return err
+		}
+		if i == len(stack)-1 {
+			_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("\n✓ Reached the top of the stack."))
+			return nil
+		}
+		if !r.waitForNext(input, stack[i+1]) {
+			_, _ = fmt.Fprintf(r.stdout, "\nStopped on '%s'. Run 'so review next' to continue with '%s'.\n", stack[i], stack[i+1])
+			return nil
+		}
+	}
+	return nil
+}
+
+// show prints branch stack[i]: its PR, commits and diffstat against its parent.
+func (r *reviewNextCmdRunner) show(i int, stack []string, prURL func(string) string) error {
+	branch, parent := stack[i], stack[i-1]
+	_, _ = fmt.Fprintf(r.stdout, "\n%s %s\n", ui.Colors.InfoStyle.Render(fmt.Sprintf("[%d/%d] %s", i, len(stack)-1, branch)), ui.Colors.MutedStyle.Render("(on "+parent+")"))
+	if url := prURL(branch); url != "" {
+		_, _ = fmt.Fprintf(r.stdout, "  PR: %s\n", url)
+	} else {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("  No PR submitted yet."))
+	}
+	if description, err := git.GetBranchDescription(branch); err == nil && description != "" {
+		_, _ = fmt.Fprintf(r.stdout, "  %s\n", summarizeDescription(description))
+	}
+
+	commits, err := git.GetCommits(parent, branch)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(r.stdout, "\n  %d commit(s):\n", len(commits))
+	for _, c := range commits {
+		_, _ = fmt.Fprintf(r.stdout, "    %s %s\n", ui.Colors.MutedStyle.Render(c.OID[:min(8, len(c.OID))]), c.Subject)
+	}
+
+	stat, err := git.DiffStat(parent, branch)
+	if err != nil {
+		return err
+	}
+	if stat == "" {
+		_, _ = fmt.Fprintln(r.stdout, "\n  No changes against the parent.")
+		return nil
+	}
+	_, _ = fmt.Fprintln(r.stdout, "")
+	for _, line := range strings.Split(stat, "\n") {
+		_, _ = fmt.Fprintf(r.stdout, "  %s\n", line)
+	}
+	return nil
+}
+
+// waitForNext pauses until the user presses Enter. It reports false when the
+// user quits or no input is available.
+func (r *reviewNextCmdRunner) waitForNext(input *bufio.Reader, nextBranch string) bool {
+	if r.nonInteractive {
+		return false
+	}
+	_, _ = fmt.Fprintf(r.stdout, "\nPress Enter to review %s (q to stop) ", ui.Colors.UserInputStyle.Render(nextBranch))
+	line, err := input.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return false
+	}
+	return !strings.EqualFold(strings.TrimSpace(line), "q")
+}
+
+// prURLFunc returns a lookup of the PR link for a branch, falling back to the
+// bare PR number when the remote is not a GitHub repository.
+func (r *reviewNextCmdRunner) prURLFunc() func(string) string {
+	var remote *git.RemoteURL
+	if remoteURL, err := git.GetRemoteURL(git.GetRemoteName()); err == nil {
+		if remote, err = git.ParseRemoteURL(remoteURL); err != nil {
+			r.logger.Debug("Could not parse owner/repo for PR links", "error", err)
+		}
+	}
+	return func(branch string) string {
+		number, err := git.GetStoredPRNumber(branch)
+		if err != nil || number == 0 {
+			return ""
+		}
+		if remote == nil {
+			return fmt.Sprintf("#%d", number)
+		}
+		return fmt.Sprintf("%s/pull/%d", remote.WebURL(), number)
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewNextCommand(t *testing.T) {
+	resetCommandFlags(t, reviewNextCmd, "restart")
+
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	t.Cleanup(cleanup)
+	require.NoError(t, git.SetStoredPRNumber("feature-a", 11))
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	testutils.RunCommand(t, repoPath, "git", "checkout", "--quiet", "main")
+	current := func() string {
+		branch, _ := git.GetCurrentBranch()
+		return branch
+	}
+
+	// Without input, each run shows one branch and stops on it.
+	stdout, _, err := runSoCommandWithOutput(t, "review", "next")
+	require.NoError(t, err)
+	out := stripAnsi(stdout)
+	assert.Equal(t, "feature-a", current())
+	assert.Contains(t, out, "[1/2] feature-a (on main)")
+	assert.Contains(t, out, "PR: https://github.com/test-owner/test-repo/pull/11")
+	assert.Contains(t, out, "1 commit(s):")
+	assert.Contains(t, out, "feature-a.txt")
+	assert.Contains(t, out, "Stopped on 'feature-a'. Run 'so review next' to continue with 'feature-b'.")
+
+	stdout, _, err = runSoCommandWithOutput(t, "review", "next")
+	require.NoError(t, err)
+	out = stripAnsi(stdout)
+	assert.Equal(t, "feature-b", current())
+	assert.Contains(t, out, "[2/2] feature-b (on feature-a)")
+	assert.Contains(t, out, "No PR submitted yet.")
+	assert.Contains(t, out, "✓ Reached the top of the stack.")
+
+	stdout, _, err = runSoCommandWithOutput(t, "review", "next")
+	require.NoError(t, err)
+	assert.Contains(t, stripAnsi(stdout), "nothing left to review")
+
+	_, _, err = runSoCommandWithOutput(t, "review", "next", "--restart")
+	require.NoError(t, err)
+	assert.Equal(t, "feature-a", current())
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/google/go-github/v71/github"
+)
+
+// Reviewer assignment strategies (socle.reviewerStrategy).
+const (
+	reviewerStrategyRoundRobin = "round-robin"
+	reviewerStrategyCodeowners = "codeowners"
+)
+
+// reviewerCursorKey is state socle writes, not a setting, so it lives under
+// socle.state and stays out of 'so config'.
+const reviewerCursorKey = "socle.state.reviewerCursor"
+
+// reviewerConfig is the reviewer pool and how to spread it over a stack.
+type reviewerConfig struct {
+	pool     []string // GitHub logins, without "@"
+	strategy string
+	cursor   int // Pool index the next rotation starts at (socle.state.reviewerCursor)
+}
+
+// loadReviewerConfig reads socle.reviewers (repeatable, comma-separated values
+// allowed), socle.reviewerStrategy and socle.state.reviewerCursor.
+func loadReviewerConfig() (reviewerConfig, error) {
+	cfg := reviewerConfig{strategy: reviewerStrategyRoundRobin}
+
+	values, err := git.GetSocleConfigAll("socle.reviewers")
+	if err != nil {
+		return cfg, err
+	}
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, login := range strings.Split(value, ",") {
+			login = strings.TrimPrefix(strings.TrimSpace(login), "@")
+			if login != "" && !seen[strings.ToLower(login)] {
+				seen[strings.ToLower(login)] = true
+				cfg.pool = append(cfg.pool, login)
+			}
+		}
+	}
+
+	strategy, err := git.GetSocleConfig("socle.reviewerStrategy")
+	if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
+		return cfg, err
+	}
+	switch strategy = strings.TrimSpace(strategy); strategy {
+	case "":
+	case reviewerStrategyRoundRobin, reviewerStrategyCodeowners:
+		cfg.strategy = strategy
+	default:
+		return cfg, fmt.Errorf("invalid socle.reviewerStrategy '%s': expected %s or %s", strategy, reviewerStrategyRoundRobin, reviewerStrategyCodeowners)
+	}
+
+	if cursor, err := git.GetGitConfig(reviewerCursorKey); err == nil {
+		if n, errParse := strconv.Atoi(strings.TrimSpace(cursor)); errParse == nil && n >= 0 {
+			cfg.cursor = n
+		}
+	}
+	return cfg, nil
+}
+
+// saveReviewerCursor persists where the next rotation starts, so consecutive
+// stacks don't all begin with the same reviewer.
+func saveReviewerCursor(cursor int) error {
+	if err := git.UnsetGitConfig(reviewerCursorKey); err != nil {
+		return err
+	}
+	return git.SetGitConfig(reviewerCursorKey, strconv.Itoa(cursor))
+}
+
+// reviewerTarget is one PR that needs a reviewer.
+type reviewerTarget struct {
+	branch   string
+	parent   string
+	prNumber int
+	author   string // PR author login; never assigned to their own PR
+}
+
+// planReviewers picks one reviewer per target, bottom of the stack first.
+// Each pick is the least-loaded eligible pool member, ties broken by rotation
+// order from the cursor. With the codeowners strategy, eligible members are
+// narrowed to owners of the branch's changed paths when any are in the pool.
+// Returns branch -> reviewer (targets without an eligible reviewer are left
+// out) and the advanced cursor.
+func planReviewers(targets []reviewerTarget, cfg reviewerConfig, owners func(reviewerTarget) []string) (map[string]string, int) {
+	plan := make(map[string]string)
+	if len(cfg.pool) == 0 {
+		return plan, cfg.cursor
+	}
+	load := make(map[string]int)
+	cursor := cfg.cursor % len(cfg.pool)
+
+	for _, target := range targets {
+		eligible := make(map[string]bool)
+		for _, login := range cfg.pool {
+			if !strings.EqualFold(login, target.author) {
+				eligible[login] = true
+			}
+		}
+		if cfg.strategy == reviewerStrategyCodeowners && owners != nil {
+			narrowed := make(map[string]bool)
+			for _, owner := range owners(target) {
+				for login := range eligible {
+					if strings.EqualFold(login, strings.TrimPrefix(owner, "@")) {
+						narrowed[login] = true
+					}
+				}
+			}
+			if len(narrowed) > 0 {
+				eligible = narrowed
+			}
+		}
+
+		best := -1
+		for offset := 0; offset < len(cfg.pool); offset++ {
+			idx := (cursor + offset) % len(cfg.pool)
+			login := cfg.pool[idx]
+			if !eligible[login] {
+				continue
+			}
+			if best == -1 || load[login] < load[cfg.pool[best]] {
+				best = idx
+			}
+		}
+		if best == -1 {
+			continue
+		}
+		chosen := cfg.pool[best]
+		plan[target.branch] = chosen
+		load[chosen]++
+		cursor = (best + 1) % len(cfg.pool)
+	}
+	return plan, cursor
+}
+
+// codeownersLookup returns an owners func for planReviewers backed by the
+// repository's CODEOWNERS file, or nil if there is none.
+func codeownersLookup() (func(reviewerTarget) []string, error) {
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	codeowners, err := gh.LoadCodeowners(root)
+	if err != nil || codeowners == nil {
+		return nil, err
+	}
+	return func(target reviewerTarget) []string {
+		paths, err := git.GetChangedPaths(target.parent, target.branch)
+		if err != nil {
+			return nil
+		}
+		var owners []string
+		for _, path := range paths {
+			owners = append(owners, codeowners.Owners(path)...)
+		}
+		return owners
+	}, nil
+}
+
+// requestedReviewerLogins returns the logins with pending review requests on pr.
+func requestedReviewerLogins(pr *github.PullRequest) []string {
+	logins := make([]string, 0, len(pr.RequestedReviewers))
+	for _, user := range pr.RequestedReviewers {
+		logins = append(logins, user.GetLogin())
+	}
+	return logins
+}
+
+// applyReviewerPlan makes each target's pending pool reviewers match the plan:
+// the planned reviewer is requested and other pool members are withdrawn.
+// Reviewers outside the pool are never touched. Results are reported to out;
+// failures are returned per PR so callers can decide whether they are fatal.
+func applyReviewerPlan(client gh.ClientInterface, targets []reviewerTarget, current map[string][]string, plan map[string]string, pool []string, out io.Writer) []error {
+	inPool := make(map[string]bool, len(pool))
+	for _, login := range pool {
+		inPool[strings.ToLower(login)] = true
+	}
+
+	var errs []error
+	for _, target := range targets {
+		want, planned := plan[target.branch]
+		if !planned {
+			_, _ = fmt.Fprintf(out, "  %s (#%d): no eligible reviewer in the pool\n", target.branch, target.prNumber)
+			continue
+		}
+
+		alreadyRequested := false
+		var withdraw []string
+		for _, login := range current[target.branch] {
+			switch {
+			case strings.EqualFold(login, want):
+				alreadyRequested = true
+			case inPool[strings.ToLower(login)]:
+				withdraw = append(withdraw, login)
+			}
+		}
+
+		if len(withdraw) > 0 {
+			if err := client.RemoveReviewers(target.prNumber, withdraw); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if !alreadyRequested {
+			if err := client.RequestReviewers(target.prNumber, []string{want}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		switch {
+		case alreadyRequested && len(withdraw) == 0:
+			_, _ = fmt.Fprintf(out, "  %s (#%d): %s (unchanged)\n", target.branch, target.prNumber, want)
+		case len(withdraw) > 0:
+			_, _ = fmt.Fprintf(out, "  %s (#%d): %s (was %s)\n", target.branch, target.prNumber, want, strings.Join(withdraw, ", "))
+		default:
+			_, _ = fmt.Fprintf(out, "  %s (#%d): %s\n", target.branch, target.prNumber, want)
+		}
+	}
+	return errs
+}
@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 
+	"github.com/benekuehn/socle/cli/so/internal/gh"
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/interrupt"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/testmode"
 	"github.com/spf13/cobra"
 )
 
@@ -16,11 +22,29 @@ var (
 	version = "dev" // Default value
 )
 
+// annotationNoRepo marks commands that run outside a git repository, so the
+// repository and config checks are skipped for them.
+const annotationNoRepo = "socle:no-repo"
+
+// annotationNoMigrate marks commands that must see the repository's metadata
+// as it is, so it is not upgraded before they run.
+const annotationNoMigrate = "socle:no-migrate"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:           "so",
-	Short:         "A CLI tool for managing stacked Git branches and GitHub PRs",
-	Long:          `Socle helps streamline workflows involving stacked branches\n(sequences of dependent branches) on Git and GitHub.`,
+	Use:   "so",
+	Short: "A CLI tool for managing stacked Git branches and GitHub PRs",
+	Long: `Socle helps streamline workflows involving stacked branches\n(sequences of dependent branches) on Git and GitHub.
+
+Exit status:
+  0  success
+  1  any other error
+  3  the branch is not tracked by socle
+  4  stopped on a rebase or cherry-pick conflict
+  5  no GitHub credentials, or GitHub rejected them
+  6  any other GitHub API or network failure
+  7  uncommitted changes are in the way
+  130  interrupted with Ctrl+C; the error says how to resume`,
 	Version:       version,
 	SilenceErrors: true,
 	SilenceUsage:  true,
@@ -43,29 +67,70 @@ var rootCmd = &cobra.Command{
 
 		slog.Debug("Debug logging enabled")
 
+		// Test mode keeps the user's git config out before any git runs.
+		if testmode.Enabled() {
+			if err := testmode.IsolateGit(); err != nil {
+				return err
+			}
+		}
+
+		if cmd.Annotations[annotationNoRepo] == "true" {
+			return nil
+		}
+
 		// Git repo check
 		if !git.IsGitRepo() {
 			// Use slog for this internal error message? Or keep direct print?
 			// Let's keep direct print for this specific startup failure.
 			return fmt.Errorf("error: not a git repository (or any of the parent directories)")
 		}
-		return nil
+		if testmode.Enabled() {
+			if err := enableTestMode(); err != nil {
+				return err
+			}
+		}
+		if err := migrateMetadata(cmd); err != nil {
+			return err
+		}
+
+		// Report every invalid socle.* value up front instead of failing on
+		// the first one somewhere inside the command.
+		return git.ValidateSocleConfig()
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	addPluginCommand(rootCmd, os.Args[1:])
+	// Ctrl+C cancels the commands' context, so they stop between steps.
+	ctx, stop := interrupt.NotifyContext(context.Background(), os.Stderr)
+	err := executeWithProfile(ctx, rootCmd)
+	stop()
+	var pluginErr *pluginExitError
+	if errors.As(err, &pluginErr) {
+		os.Exit(pluginErr.code) // The plugin reported its own error
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err) // More user-friendly error
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode returns the exit status for err: that of its socleerr kind, or of
+// the GitHub failure behind it.
+func exitCode(err error) int {
+	kind := socleerr.KindOf(err)
+	if kind == socleerr.Unknown {
+		kind = gh.ErrorKind(err)
 	}
+	return kind.ExitCode()
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&debugLogging, "debug", false, "Enable debug logging output")
 	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Disable interactive prompts (safe defaults are used where possible)")
+	rootCmd.PersistentFlags().BoolVar(&profileOutput, "profile", false, "Report time spent in git, GitHub API calls and rendering when the command finishes")
 }
 
 // GetRootCmd returns the root command instance.
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidation(t *testing.T) {
+	t.Run("Reports every invalid value with its location", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.commitTrailers", "sometimes")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.reviewerStrategy", "random")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.author", "anything goes")
+
+		_, _, err := runSoCommandWithOutput(t, "log")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, git.ErrInvalidConfig), "expected ErrInvalidConfig, got: %v", err)
+
+		var validationErr *git.ConfigValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Len(t, validationErr.Problems, 2)
+
+		msg := err.Error()
+		assert.Contains(t, msg, "2 problem(s)")
+		assert.Regexp(t, `\.git/config:\d+: socle\.committrailers = 'sometimes': expected true or false`, msg)
+		assert.Regexp(t, `\.git/config:\d+: socle\.reviewerstrategy = 'random': must be one of round-robin, codeowners`, msg)
+		assert.NotContains(t, msg, "socle.author")
+
+		// The reported line really holds the offending entry
+		line := validationErr.Problems[0].Line
+		config := strings.Split(readFile(t, repoPath, ".git/config"), "\n")
+		require.Greater(t, line, 0)
+		assert.Contains(t, config[line-1], "sometimes")
+	})
+
+	t.Run("Valid config passes", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.commitTrailers", "false")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.signedPush", "if-asked")
+
+		_, _, err := runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+	})
+}
+
+func TestExitCodes(t *testing.T) {
+	t.Run("Commands fail with the kind of the problem", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", "untracked")
+		_, _, err := runSoCommandWithOutput(t, "wip")
+		require.Error(t, err)
+		assert.Equal(t, 3, exitCode(err))
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		writeFile(t, repoPath, "feature-a.txt", "uncommitted")
+		_, _, err = runSoCommandWithOutput(t, "restack", "--no-fetch")
+		require.Error(t, err)
+		assert.Equal(t, 7, exitCode(err))
+	})
+
+	t.Run("Wrapped and GitHub errors keep their kind", func(t *testing.T) {
+		response := func(status int) error {
+			return fmt.Errorf("failed to fetch PR #1: %w", &github.ErrorResponse{Response: &http.Response{StatusCode: status}})
+		}
+		assert.Equal(t, 5, exitCode(response(http.StatusUnauthorized)))
+		assert.Equal(t, 6, exitCode(response(http.StatusNotFound)))
+		assert.Equal(t, 4, exitCode(fmt.Errorf("failed during restack: %w", git.ErrRebaseConflict)))
+		assert.Equal(t, 1, exitCode(errors.New("something else")))
+	})
+}
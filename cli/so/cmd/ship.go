@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var shipCmd = &cobra.Command{
+	Use:   "ship",
+	Short: "Restack, submit, wait for checks and merge the bottom PR of the stack",
+	Long: `Runs the end-of-day flow on the current stack in one go:
+
+  1. restack   Rebase every branch onto its parent ('so restack')
+  2. submit    Push the branches and create or update ready-for-review PRs
+               ('so submit')
+  3. checks    Wait for the checks of the pushed commit on the bottom PR to
+               finish; a PR that reports none within two minutes has none
+  4. merge     Merge the bottom PR at that commit if GitHub allows it, then
+               point the next PR at the base branch
+
+--until stops after the named step, e.g. 'so ship --until checks' to see
+whether CI is green without merging. Nothing is merged when the bottom PR has
+failing checks, missing reviews or conflicts; the reasons are listed instead.
+
+After a merge, run 'so sync' to delete the merged branch and restack the rest
+of the stack onto the base branch.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		until, _ := cmd.Flags().GetString("until")
+		method, _ := cmd.Flags().GetString("merge-method")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		if shipStepIndex(until) < 0 {
+			return fmt.Errorf("invalid --until '%s': expected restack, submit, checks or merge", until)
+		}
+		switch method {
+		case "merge", "squash", "rebase":
+		default:
+			return fmt.Errorf("invalid --merge-method '%s': expected merge, squash or rebase", method)
+		}
+
+		runner := &shipCmdRunner{
+			logger:         slog.Default(),
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          cmd.InOrStdin(),
+			nonInteractive: nonInteractive,
+			until:          until,
+			mergeMethod:    method,
+			timeout:        timeout,
+		}
+		return runner.run(cmd)
+	},
+}
+
+func init() {
+	AddCommand(shipCmd)
+	shipCmd.Flags().String("until", "merge", "Stop after this step: restack, submit, checks or merge")
+	shipCmd.Flags().String("merge-method", "merge", "How to merge the bottom PR: merge, squash or rebase")
+	shipCmd.Flags().Duration("timeout", 30*time.Minute, "How long to wait for checks on the bottom PR")
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/interrupt"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// shipSteps are the steps of 'so ship' in order; --until names one of them.
+var shipSteps = []string{"restack", "submit", "checks", "merge"}
+
+// shipPollInterval is how often 'so ship' asks GitHub about pending checks.
+var shipPollInterval = 15 * time.Second
+
+// shipChecksGrace is how long 'so ship' waits for CI to register checks on a
+// fresh push before it concludes the PR has none.
+var shipChecksGrace = 2 * time.Minute
+
+func shipStepIndex(step string) int {
+	for i, s := range shipSteps {
+		if s == step {
+			return i
+		}
+	}
+	return -1
+}
+
+type shipCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+
+	nonInteractive bool
+	until          string
+	mergeMethod    string
+	timeout        time.Duration
+}
+
+func (r *shipCmdRunner) run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	last := shipStepIndex(r.until)
+
+	r.step("restack")
+	restackRunner := &restackCmdRunner{
+		logger:         r.logger,
+		stdout:         r.stdout,
+		stderr:         r.stderr,
+		stdin:          r.stdin,
+		nonInteractive: r.nonInteractive,
+		noPush:         true, // Submit pushes next
+	}
+	if err := restackRunner.run(cmd); err != nil {
+		if socleerr.KindOf(err) == socleerr.Conflict {
+			return socleerr.New(socleerr.Conflict, "restack stopped at a conflict; resolve it, run 'so continue' and run 'so ship' again")
+		}
+		return fmt.Errorf("failed during restack: %w", err)
+	}
+	if last == shipStepIndex("restack") {
+		return nil
+	}
+
+	r.step("submit")
+	defaults, err := git.LoadSubmitDefaults()
+	if err != nil {
+		return err
+	}
+	submitRunner := &submitCmdRunner{
+		logger:         r.logger,
+		stdout:         r.stdout,
+		stderr:         r.stderr,
+		nonInteractive: r.nonInteractive,
+		draft:          false, // Drafts cannot be merged
+		assignRevs:     defaults.AssignReviewers,
+		stackNaming:    defaults.StackName,
+	}
+	if err := submitRunner.run(ctx, cmd); err != nil {
+		return fmt.Errorf("failed during submit: %w", err)
+	}
+	if last == shipStepIndex("submit") {
+		return nil
+	}
+
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return err
+	}
+	stack := stackInfo.FullStack
+	if len(stack) <= 1 {
+		return fmt.Errorf("no stack to ship: check out a tracked branch of the stack first")
+	}
+	bottom := stack[1]
+	prNumber, err := git.GetStoredPRNumber(bottom)
+	if err != nil {
+		return fmt.Errorf("failed to read PR number for '%s': %w", bottom, err)
+	}
+	if prNumber == 0 {
+		return fmt.Errorf("'%s' has no PR after submitting; nothing to wait for", bottom)
+	}
+
+	remoteName := git.GetRemoteName()
+	remoteURL, err := git.GetRemoteURL(remoteName)
+	if err != nil {
+		return fmt.Errorf("cannot get remote URL for '%s': %w", remoteName, err)
+	}
+	owner, repoName, err := git.ParseOwnerAndRepo(remoteURL)
+	if err != nil {
+		return fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", remoteName, remoteURL, err)
+	}
+	ghClient, err := gh.CreateClient(ctx, owner, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	// Checks and the merge are pinned to what submit pushed, so a commit
+	// pushed in the meantime is never merged without its checks.
+	headOID, err := git.GetCurrentBranchCommit(bottom)
+	if err != nil {
+		return fmt.Errorf("cannot get current commit of '%s': %w", bottom, err)
+	}
+
+	r.step("checks")
+	readiness, err := r.waitForChecks(ctx, ghClient, bottom, prNumber, headOID)
+	if err != nil {
+		return err
+	}
+	if last == shipStepIndex("checks") {
+		return nil
+	}
+	if err := interrupt.Check(ctx, "nothing was merged; run 'so ship' again"); err != nil {
+		return err
+	}
+
+	r.step("merge")
+	return r.merge(ghClient, stack, readiness, headOID)
+}
+
+func (r *shipCmdRunner) step(name string) {
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.InfoStyle.Render(fmt.Sprintf("\n==> %s (%d/%d)", name, shipStepIndex(name)+1, len(shipSteps))))
+}
+
+// waitForChecks polls the bottom PR until the checks of headOID finish or
+// the timeout passes. Until GitHub reports headOID as the PR's head, the
+// checks it reports are those of an older commit and do not count. A PR
+// whose head reports no checks within shipChecksGrace counts as finished.
+// Ctrl+C stops the wait.
+func (r *shipCmdRunner) waitForChecks(ctx context.Context, client gh.ClientInterface, branch string, number int, headOID string) (*gh.MergeReadiness, error) {
+	start := time.Now()
+	deadline := start.Add(r.timeout)
+	waiting := false
+	for {
+		readiness, err := client.GetMergeReadiness(number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the state of #%d: %w", number, err)
+		}
+		state := readiness.ChecksState
+		switch {
+		case readiness.HeadOID != headOID:
+			state = "PENDING" // GitHub has not seen the push yet
+		case state == "" && time.Since(start) < shipChecksGrace:
+			state = "EXPECTED" // CI may not have registered its checks yet
+		}
+		switch state {
+		case "FAILURE", "ERROR":
+			return nil, fmt.Errorf("checks failed on #%d ('%s'); nothing was merged", number, branch)
+		case "PENDING", "EXPECTED":
+			if !time.Now().Add(shipPollInterval).Before(deadline) {
+				return nil, fmt.Errorf("checks on #%d are still running after %s; rerun 'so ship' later", number, r.timeout)
+			}
+			if !waiting {
+				_, _ = fmt.Fprintf(r.stdout, "Waiting for checks on #%d ('%s')...\n", number, branch)
+				waiting = true
+			}
+			r.logger.Debug("Checks pending", "pr", number, "state", readiness.ChecksState, "head", readiness.HeadOID, "pushed", headOID)
+			select {
+			case <-time.After(shipPollInterval):
+			case <-ctx.Done():
+				return nil, interrupt.Check(ctx, fmt.Sprintf("nothing was merged; run 'so ship' again to wait for the checks on #%d", number))
+			}
+			continue
+		case "":
+			_, _ = fmt.Fprintf(r.stdout, "No checks reported for #%d after %s.\n", number, shipChecksGrace)
+		default:
+			_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Checks passed on #%d.", number)))
+		}
+		return readiness, nil
+	}
+}
+
+// merge merges the bottom PR at headOID and points the PR above it at the
+// base branch.
+func (r *shipCmdRunner) merge(client gh.ClientInterface, stack []string, readiness *gh.MergeReadiness, headOID string) error {
+	bottom, number := stack[1], readiness.Number
+	trunk := git.PRBaseFor(stack[0])
+	if err := mergeBottomPR(client, r.stdout, bottom, trunk, readiness, r.mergeMethod, headOID); err != nil {
+		return err
+	}
+
+	if len(stack) > 2 {
+		next := stack[2]
+		nextNumber, err := git.GetStoredPRNumber(next)
+		if err != nil {
+			return fmt.Errorf("failed to read PR number for '%s': %w", next, err)
+		}
+		if nextNumber > 0 {
+			if _, err := client.UpdatePullRequestBase(nextNumber, trunk); err != nil {
+				return fmt.Errorf("merged #%d, but could not point #%d at '%s': %w", number, nextNumber, trunk, err)
+			}
+			_, _ = fmt.Fprintf(r.stdout, "Pointed #%d ('%s') at '%s'.\n", nextNumber, next, trunk)
+		}
+	}
+	if len(stack) == 2 {
+		closeTrackingIssue(client, r.stdout, r.stderr, stack[1:])
+	}
+	_, _ = fmt.Fprintln(r.stdout, "Run 'so sync' to delete the merged branch and restack the rest of the stack.")
+	return nil
+}
+
+// mergeBottomPR merges the PR of bottom into trunk with method once GitHub
+// reports no blockers. headOID pins the commit the caller checked: GitHub
+// refuses the merge if the branch has moved since.
+func mergeBottomPR(client gh.ClientInterface, stdout io.Writer, bottom, trunk string, readiness *gh.MergeReadiness, method, headOID string) error {
+	number := readiness.Number
+	if readiness.State == "MERGED" {
+		return fmt.Errorf("#%d ('%s') is already merged; run 'so sync' to clean it up", number, bottom)
+	}
+	if blockers := readiness.Blockers(); len(blockers) > 0 {
+		return fmt.Errorf("#%d ('%s') cannot be merged yet: %s", number, bottom, strings.Join(blockerReasons(blockers), "; "))
+	}
+	if err := client.MergePullRequest(number, method, headOID); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Merged #%d ('%s') into '%s' (%s).", number, bottom, trunk, method)))
+	return nil
+}
+
+// closeTrackingIssue closes the stack's tracking issue after its last PR
+// merged, when socle.closeTrackingIssue is set. Failing to close it only warns;
+// the merge already happened.
+func closeTrackingIssue(client gh.ClientInterface, stdout, stderr io.Writer, branches []string) {
+	enabled, err := git.GetSocleConfigBool("socle.closeTrackingIssue", false)
+	if err != nil || !enabled {
+		return
+	}
+	issue, err := git.GetTrackingIssue(branches)
+	if err != nil || issue == 0 {
+		return
+	}
+	if err := client.CloseIssue(issue); err != nil {
+		_, _ = fmt.Fprintln(stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: %v", err)))
+		return
+	}
+	_, _ = fmt.Fprintf(stdout, "Closed tracking issue #%d.\n", issue)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShipCommand(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	originalPollInterval := shipPollInterval
+	originalChecksGrace := shipChecksGrace
+	shipPollInterval = time.Millisecond
+	shipChecksGrace = 0
+	t.Cleanup(func() {
+		gh.CreateClient = originalCreateGHClient
+		shipPollInterval = originalPollInterval
+		shipChecksGrace = originalChecksGrace
+	})
+	resetCommandFlags(t, shipCmd, "until", "merge-method", "timeout")
+
+	// main -> feature-a -> feature-b, with a bare origin whose path parses as
+	// test-owner/test-repo. Submit creates #101 and #102.
+	setup := func(t *testing.T) *gh.MockClient {
+		resetCommandFlags(t, shipCmd, "until", "merge-method", "timeout")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		t.Cleanup(cleanup)
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "init", "--quiet", "--bare", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "main")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("CreatePullRequest", "feature-a", "main", "feat: commit on feature-a", "", false).Return(
+			&github.PullRequest{Number: github.Ptr(101), HTMLURL: github.Ptr("url-a")}, nil).Once()
+		mockClient.On("CreatePullRequest", "feature-b", "feature-a", "feat: commit on feature-b", "", false).Return(
+			&github.PullRequest{Number: github.Ptr(102), HTMLURL: github.Ptr("url-b")}, nil).Once()
+		mockClient.On("GetMergeReadiness", 102).Return(&gh.MergeReadiness{Number: 102, State: "OPEN"}, nil)
+		mockClient.On("FindCommentWithMarker", mock.Anything, stackCommentMarker).Return(int64(0), nil)
+		mockClient.On("CreateComment", mock.Anything, mock.Anything).Return(&github.IssueComment{ID: github.Ptr(int64(5000))}, nil)
+		return mockClient
+	}
+
+	t.Run("Waits for checks, merges the bottom PR and retargets the next", func(t *testing.T) {
+		mockClient := setup(t)
+		head, err := git.GetCurrentBranchCommit("feature-a")
+		require.NoError(t, err)
+		pending := &gh.MergeReadiness{Number: 101, State: "OPEN", HeadOID: head, MergeStateStatus: "BLOCKED", ChecksState: "PENDING"}
+		green := &gh.MergeReadiness{Number: 101, State: "OPEN", HeadOID: head, MergeStateStatus: "CLEAN", ReviewDecision: "APPROVED", ChecksState: "SUCCESS"}
+		mockClient.On("GetMergeReadiness", 101).Return(pending, nil).Twice() // Stack comment, then the first poll
+		mockClient.On("GetMergeReadiness", 101).Return(green, nil).Once()
+		mockClient.On("MergePullRequest", 101, "squash", head).Return(nil).Once()
+		mockClient.On("UpdatePullRequestBase", 102, "main").Return(&github.PullRequest{Number: github.Ptr(102)}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "ship", "--non-interactive", "--merge-method=squash")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "==> submit (2/4)")
+		assert.Contains(t, out, "Waiting for checks on #101 ('feature-a')...")
+		assert.Contains(t, out, "✓ Checks passed on #101.")
+		assert.Contains(t, out, "✓ Merged #101 ('feature-a') into 'main' (squash).")
+		assert.Contains(t, out, "Pointed #102 ('feature-b') at 'main'.")
+		mockClient.AssertExpectations(t)
+
+		remoteTip, _ := git.GetRemoteBranchCommit("feature-b", "origin")
+		localTip, _ := git.GetCurrentBranchCommit("feature-b")
+		assert.Equal(t, localTip, remoteTip, "submit pushed the stack")
+	})
+
+	t.Run("Waits for GitHub to see the push and for CI to register checks", func(t *testing.T) {
+		shipChecksGrace = time.Hour
+		t.Cleanup(func() { shipChecksGrace = 0 })
+		mockClient := setup(t)
+		head, err := git.GetCurrentBranchCommit("feature-a")
+		require.NoError(t, err)
+		stale := &gh.MergeReadiness{Number: 101, State: "OPEN", HeadOID: "0ld", MergeStateStatus: "CLEAN", ChecksState: "SUCCESS"}
+		unregistered := &gh.MergeReadiness{Number: 101, State: "OPEN", HeadOID: head, MergeStateStatus: "CLEAN"}
+		green := &gh.MergeReadiness{Number: 101, State: "OPEN", HeadOID: head, MergeStateStatus: "CLEAN", ChecksState: "SUCCESS"}
+		mockClient.On("GetMergeReadiness", 101).Return(stale, nil).Twice() // Stack comment, then the first poll
+		mockClient.On("GetMergeReadiness", 101).Return(unregistered, nil).Once()
+		mockClient.On("GetMergeReadiness", 101).Return(green, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "ship", "--non-interactive", "--until=checks")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "✓ Checks passed on #101.")
+		assert.NotContains(t, stripAnsi(stdout), "No checks reported")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Stops at failing checks or merge blockers", func(t *testing.T) {
+		mockClient := setup(t)
+		head, err := git.GetCurrentBranchCommit("feature-a")
+		require.NoError(t, err)
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "OPEN", HeadOID: head, ChecksState: "FAILURE"}, nil)
+
+		_, _, err = runSoCommandWithOutput(t, "ship", "--non-interactive", "--until=checks")
+		require.ErrorContains(t, err, "checks failed on #101 ('feature-a'); nothing was merged")
+
+		mockClient = setup(t)
+		head, err = git.GetCurrentBranchCommit("feature-a")
+		require.NoError(t, err)
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "OPEN", HeadOID: head, MergeStateStatus: "BLOCKED", ReviewDecision: "REVIEW_REQUIRED", ChecksState: "SUCCESS"}, nil)
+
+		_, _, err = runSoCommandWithOutput(t, "ship", "--non-interactive")
+		require.ErrorContains(t, err, "#101 ('feature-a') cannot be merged yet: review required")
+		mockClient.AssertNotCalled(t, "MergePullRequest", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("--until submit leaves checks and merging alone", func(t *testing.T) {
+		mockClient := setup(t)
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "OPEN"}, nil).Once() // Stack comment only
+
+		stdout, _, err := runSoCommandWithOutput(t, "ship", "--non-interactive", "--until=submit")
+		require.NoError(t, err)
+		assert.NotContains(t, stripAnsi(stdout), "==> checks")
+		mockClient.AssertExpectations(t)
+	})
+}
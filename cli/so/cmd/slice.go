@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var sliceCmd = &cobra.Command{
+	Use:   "slice",
+	Short: "Turn the current branch into a stack with one branch per commit",
+	Long: `For the "every commit is a PR" workflow: splits the commits of the current
+tracked branch into a stack of tracked branches, one per commit, so each
+commit can be submitted and reviewed as its own pull request.
+
+The branch you run it on keeps its last commit (and its PR, if any); every
+earlier commit gets a new branch named after its subject, e.g. "Add login
+form" becomes add-login-form (prefixed with --prefix if given).
+
+Keep working on the original branch: amend, reorder, add or drop commits
+with an interactive rebase, then run 'so slice' again to re-slice. Existing
+slice branches are moved to their updated commits in order, new commits get
+new branches and branches whose commit is gone are deleted. The original
+branch is the source of truth; commits made directly on slice branches are
+overwritten. Run 'so submit' afterwards to update the pull requests.
+
+Slice branches record their source branch in git config as
+branch.<name>.socle-slice.`,
+	Args: cobra.NoArgs,
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		prefix, _ := cmd.Flags().GetString("prefix")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		runner := &sliceCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			prefix: prefix,
+			dryRun: dryRun,
+		}
+		return runner.run()
+	}),
+}
+
+func init() {
+	AddCommand(sliceCmd)
+	sliceCmd.Flags().String("prefix", "", "Prefix for generated branch names (e.g. 'jane/')")
+	sliceCmd.Flags().Bool("dry-run", false, "Show the branches that would be created, moved or deleted without changing anything")
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type sliceCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	prefix string
+	dryRun bool
+}
+
+// slicePlanEntry is one commit below the source branch's tip and the slice
+// branch that should point at it.
+type slicePlanEntry struct {
+	commit git.Commit
+	branch string
+	isNew  bool
+	oldOID string // Current tip of an existing slice branch
+}
+
+func (r *sliceCmdRunner) run() error {
+	source, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	owner, err := git.GetSliceSource(source)
+	if err != nil {
+		return err
+	}
+	if owner != "" {
+		return fmt.Errorf("'%s' is a slice of '%s'; check out '%s' and run 'so slice' there", source, owner, owner)
+	}
+
+	parent, err := git.GetGitConfig(git.BranchConfigKey(source, "socle-parent"))
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", source)
+		}
+		return fmt.Errorf("failed to read parent of '%s': %w", source, err)
+	}
+	base, err := git.GetGitConfig(git.BranchConfigKey(source, "socle-base"))
+	if err != nil {
+		return fmt.Errorf("failed to read base of '%s': %w", source, err)
+	}
+
+	existing, err := git.GetSliceBranches(source)
+	if err != nil {
+		return fmt.Errorf("failed to find slice branches of '%s': %w", source, err)
+	}
+	root := parent
+	if len(existing) > 0 {
+		root, err = git.GetGitConfig(git.BranchConfigKey(existing[0], "socle-parent"))
+		if err != nil {
+			return fmt.Errorf("failed to read parent of '%s': %w", existing[0], err)
+		}
+	}
+
+	commits, err := git.GetCommits(root, source)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("'%s' has no commits on top of '%s'", source, root)
+	}
+	if len(commits) == 1 && len(existing) == 0 {
+		_, _ = fmt.Fprintf(r.stdout, "'%s' has a single commit; nothing to slice.\n", source)
+		return nil
+	}
+
+	plan, removed, err := r.plan(commits[:len(commits)-1], existing)
+	if err != nil {
+		return err
+	}
+
+	r.printPlan(source, root, plan, removed)
+	if r.dryRun {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("Dry run: nothing was changed."))
+		return nil
+	}
+
+	below := root
+	for _, entry := range plan {
+		if entry.isNew {
+			if err := git.CreateBranch(entry.branch, entry.commit.OID); err != nil {
+				return err
+			}
+			if err := git.SetGitConfig(git.BranchConfigKey(entry.branch, "socle-base"), base); err != nil {
+				return err
+			}
+			if err := git.SetSliceSource(entry.branch, source); err != nil {
+				return err
+			}
+		} else if entry.oldOID != entry.commit.OID {
+			if err := git.SetBranchTip(entry.branch, entry.commit.OID); err != nil {
+				return err
+			}
+		}
+		if err := git.UpdateBranchParent(entry.branch, below); err != nil {
+			return err
+		}
+		below = entry.branch
+	}
+	if err := git.UpdateBranchParent(source, below); err != nil {
+		return err
+	}
+
+	for _, branch := range removed {
+		if prNumber, errPR := git.GetStoredPRNumber(branch); errPR == nil && prNumber > 0 {
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: '%s' had PR #%d; close it on GitHub.", branch, prNumber)))
+		}
+		if err := git.BranchDelete(branch); err != nil {
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ '%s' is now a stack of %d branches.", source, len(plan)+1)))
+	if len(existing) > 0 {
+		_, _ = fmt.Fprintln(r.stdout, "Run 'so submit' to update the pull requests.")
+	}
+	return nil
+}
+
+// plan pairs commits (oldest first) with slice branches: existing branches are
+// reused in order, extra commits get new branches and leftover branches are
+// returned for removal.
+func (r *sliceCmdRunner) plan(commits []git.Commit, existing []string) ([]slicePlanEntry, []string, error) {
+	var tips map[string]string
+	if len(existing) > 0 {
+		var err error
+		tips, err = git.GetMultipleBranchCommits(existing)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read slice branch tips: %w", err)
+		}
+	}
+
+	taken := make(map[string]bool)
+	for _, branch := range existing {
+		taken[branch] = true
+	}
+
+	plan := make([]slicePlanEntry, 0, len(commits))
+	for i, commit := range commits {
+		if i < len(existing) {
+			plan = append(plan, slicePlanEntry{commit: commit, branch: existing[i], oldOID: tips[existing[i]]})
+			continue
+		}
+		name, err := r.branchName(commit, taken)
+		if err != nil {
+			return nil, nil, err
+		}
+		taken[name] = true
+		plan = append(plan, slicePlanEntry{commit: commit, branch: name, isNew: true})
+	}
+
+	var removed []string
+	if len(existing) > len(commits) {
+		removed = existing[len(commits):]
+	}
+	return plan, removed, nil
+}
+
+// branchName derives an unused, valid branch name from the commit subject.
+func (r *sliceCmdRunner) branchName(commit git.Commit, taken map[string]bool) (string, error) {
+	slug := git.BranchSlug(commit.Subject)
+	if slug == "" {
+		slug = "commit-" + commit.OID[:8]
+	}
+	candidate := r.prefix + slug
+	for n := 2; ; n++ {
+		if !taken[candidate] {
+			exists, err := git.BranchExists(candidate)
+			if err != nil {
+				return "", err
+			}
+			if !exists {
+				break
+			}
+		}
+		candidate = fmt.Sprintf("%s%s-%d", r.prefix, slug, n)
+	}
+	if err := git.IsValidBranchName(candidate); err != nil {
+		return "", fmt.Errorf("cannot name a branch for commit %s: %w (try a different --prefix)", commit.OID[:8], err)
+	}
+	return candidate, nil
+}
+
+func (r *sliceCmdRunner) printPlan(source, root string, plan []slicePlanEntry, removed []string) {
+	_, _ = fmt.Fprintf(r.stdout, "Slicing '%s' on top of '%s':\n", source, root)
+	for _, entry := range plan {
+		status := "unchanged"
+		switch {
+		case entry.isNew:
+			status = "new"
+		case entry.oldOID != entry.commit.OID:
+			status = "moved"
+		}
+		_, _ = fmt.Fprintf(r.stdout, "  %s %s %s\n",
+			entry.branch,
+			ui.Colors.MutedStyle.Render(fmt.Sprintf("(%s)", status)),
+			ui.Colors.FaintStyle.Render(entry.commit.OID[:8]+" "+entry.commit.Subject))
+	}
+	_, _ = fmt.Fprintf(r.stdout, "  %s %s\n", source, ui.Colors.MutedStyle.Render("(top, keeps the last commit)"))
+	for _, branch := range removed {
+		_, _ = fmt.Fprintf(r.stdout, "  %s %s\n", branch, ui.Colors.WarningStyle.Render("(deleted, its commit is gone)"))
+	}
+}
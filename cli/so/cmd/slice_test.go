@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSliceCommand(t *testing.T) {
+	resetCommandFlags(t, sliceCmd, "prefix", "dry-run")
+
+	commit := func(t *testing.T, repoPath, file, message string) {
+		writeFile(t, repoPath, file, message)
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", message)
+	}
+	parentOf := func(t *testing.T, branch string) string {
+		parent, err := git.GetGitConfig("branch." + branch + ".socle-parent")
+		require.NoError(t, err, "branch %s should be tracked", branch)
+		return parent
+	}
+	tipSubject := func(t *testing.T, repoPath, branch string) string {
+		return strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "log", "-1", "--format=%s", branch))
+	}
+
+	t.Run("Slices commits into tracked branches and re-slices after a rewrite", func(t *testing.T) {
+		resetCommandFlags(t, sliceCmd, "prefix", "dry-run")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature"})
+		defer cleanup()
+		commit(t, repoPath, "login.txt", "Add login form")
+		commit(t, repoPath, "input.txt", "Fix: handle empty input!")
+
+		// Dry run changes nothing
+		stdout, _, err := runSoCommandWithOutput(t, "slice", "--prefix", "me/", "--dry-run")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "me/add-login-form (new)")
+		exists, err := git.BranchExists("me/add-login-form")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		stdout, _, err = runSoCommandWithOutput(t, "slice", "--prefix", "me/", "--dry-run=false")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "'feature' is now a stack of 3 branches.")
+
+		assert.Equal(t, "main", parentOf(t, "me/feat-commit-on-feature"))
+		assert.Equal(t, "me/feat-commit-on-feature", parentOf(t, "me/add-login-form"))
+		assert.Equal(t, "me/add-login-form", parentOf(t, "feature"))
+		assert.Equal(t, "Add login form", tipSubject(t, repoPath, "me/add-login-form"))
+		source, err := git.GetSliceSource("me/add-login-form")
+		require.NoError(t, err)
+		assert.Equal(t, "feature", source)
+		current, _ := git.GetCurrentBranch()
+		assert.Equal(t, "feature", current)
+
+		// Rewrite the source branch: reword the first commit, add one in the middle.
+		testutils.RunCommand(t, repoPath, "git", "reset", "--hard", "HEAD~2")
+		testutils.RunCommand(t, repoPath, "git", "commit", "--amend", "-m", "feat: reworded")
+		commit(t, repoPath, "login.txt", "Add login form")
+		commit(t, repoPath, "logout.txt", "Add logout")
+		commit(t, repoPath, "input.txt", "Fix: handle empty input!")
+
+		stdout, _, err = runSoCommandWithOutput(t, "slice", "--prefix", "me/")
+		require.NoError(t, err)
+		stdout = stripAnsi(stdout)
+		assert.Contains(t, stdout, "me/feat-commit-on-feature (moved)")
+		assert.Contains(t, stdout, "me/add-logout (new)")
+
+		assert.Equal(t, "feat: reworded", tipSubject(t, repoPath, "me/feat-commit-on-feature"))
+		assert.Equal(t, "Add login form", tipSubject(t, repoPath, "me/add-login-form"))
+		assert.Equal(t, "me/add-login-form", parentOf(t, "me/add-logout"))
+		assert.Equal(t, "me/add-logout", parentOf(t, "feature"))
+		slices, err := git.GetSliceBranches("feature")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"me/feat-commit-on-feature", "me/add-login-form", "me/add-logout"}, slices)
+
+		// Dropping commits deletes the slice branches that no longer have one.
+		testutils.RunCommand(t, repoPath, "git", "reset", "--hard", "HEAD~3")
+		commit(t, repoPath, "input.txt", "Fix: handle empty input!")
+
+		stdout, _, err = runSoCommandWithOutput(t, "slice", "--prefix", "me/")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "me/add-logout (deleted, its commit is gone)")
+		slices, err = git.GetSliceBranches("feature")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"me/feat-commit-on-feature"}, slices)
+		assert.Equal(t, "me/feat-commit-on-feature", parentOf(t, "feature"))
+		for _, gone := range []string{"me/add-login-form", "me/add-logout"} {
+			exists, err := git.BranchExists(gone)
+			require.NoError(t, err)
+			assert.False(t, exists, "%s should be deleted", gone)
+		}
+	})
+
+	t.Run("Refuses to run on a slice branch", func(t *testing.T) {
+		resetCommandFlags(t, sliceCmd, "prefix", "dry-run")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature"})
+		defer cleanup()
+		commit(t, repoPath, "login.txt", "Add login form")
+		_, _, err := runSoCommandWithOutput(t, "slice")
+		require.NoError(t, err)
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feat-commit-on-feature")
+		_, _, err = runSoCommandWithOutput(t, "slice")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is a slice of 'feature'")
+	})
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [name]",
+	Short: "Save all tracked branch tips and socle metadata under a name",
+	Long: `Records the commit of every tracked branch together with all socle
+metadata, so the exact state can be brought back later with
+'so restore <name>'. Useful before experimenting with a risky operation or to
+hand a reproducible state to a colleague. Base branches such as main are not
+recorded, so restoring never rewinds them past commits fetched since.
+
+Snapshots are stored as refs under refs/socle/snapshots/, which keeps the
+recorded commits reachable. Share one with:
+  git push origin refs/socle/snapshots/<name>
+
+If no name is given, a timestamp is used.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, _ := cmd.Flags().GetBool("list")
+
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		runner := &snapshotCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			name:   name,
+			list:   list,
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	AddCommand(snapshotCmd)
+	snapshotCmd.Flags().BoolP("list", "l", false, "List existing snapshots")
+}
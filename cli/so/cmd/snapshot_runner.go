@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type snapshotCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	name string
+	list bool
+}
+
+func (r *snapshotCmdRunner) run() error {
+	if r.list {
+		return r.listSnapshots()
+	}
+
+	name := r.name
+	if name == "" {
+		name = time.Now().Format("20060102-150405")
+	}
+
+	snapshot, err := git.CreateSnapshot(name)
+	if err != nil {
+		return err
+	}
+	r.logger.Debug("Created snapshot", "name", name, "branches", len(snapshot.Branches), "keys", len(snapshot.Metadata))
+
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Saved snapshot '%s' (%d branches).", name, len(snapshot.Branches))))
+	_, _ = fmt.Fprintf(r.stdout, "Restore it with 'so restore %s'.\n", name)
+	return nil
+}
+
+func (r *snapshotCmdRunner) listSnapshots() error {
+	snapshots, err := git.ListSnapshots()
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		_, _ = fmt.Fprintln(r.stdout, "No snapshots found.")
+		return nil
+	}
+	for _, s := range snapshots {
+		_, _ = fmt.Fprintf(r.stdout, "%s  %s\n", s.Name,
+			mutedStyle.Render(fmt.Sprintf("%s, %d branches", s.CreatedAt.Local().Format("2006-01-02 15:04"), len(s.Branches))))
+	}
+	return nil
+}
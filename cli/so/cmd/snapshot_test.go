@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+	t.Run("Restore brings back branch tips, deleted branches and metadata", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		tipA := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-a"))
+		tipB := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-b"))
+
+		stdout, _, err := runSoCommandWithOutput(t, "snapshot", "before-experiment")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Saved snapshot 'before-experiment' (2 branches).")
+
+		// Mess things up: rewrite feature-a, delete feature-b, retrack feature-a
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature-a")
+		writeFile(t, repoPath, "extra.txt", "extra")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "experiment")
+		testutils.RunCommand(t, repoPath, "git", "branch", "-D", "feature-b")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "42")
+
+		stdout, _, err = runSoCommandWithOutput(t, "restore", "before-experiment")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Moved branches: feature-a, feature-b")
+
+		assert.Equal(t, tipA, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-a")))
+		assert.Equal(t, tipB, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-b")))
+
+		parent, err := git.GetGitConfig("branch.feature-b.socle-parent")
+		require.NoError(t, err)
+		assert.Equal(t, "feature-a", parent)
+		_, err = git.GetGitConfig("branch.feature-a.socle-pr-number")
+		assert.ErrorIs(t, err, git.ErrConfigNotFound)
+	})
+
+	t.Run("Restore leaves base branches alone", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		require.NoError(t, runSoCommand(t, "snapshot", "s"))
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		writeFile(t, repoPath, "fetched.txt", "fetched")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "fetched since")
+		mainTip := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "main"))
+
+		stdout, _, err := runSoCommandWithOutput(t, "restore", "s")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "All branches were already at their snapshot commits.")
+		assert.Equal(t, mainTip, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "main")))
+	})
+
+	t.Run("Restore refuses before moving anything when a branch is in another worktree", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		require.NoError(t, runSoCommand(t, "snapshot", "s"))
+		for _, branch := range []string{"feature-a", "feature-b"} {
+			testutils.RunCommand(t, repoPath, "git", "checkout", branch)
+			writeFile(t, repoPath, "extra-"+branch+".txt", "extra")
+			testutils.RunCommand(t, repoPath, "git", "add", ".")
+			testutils.RunCommand(t, repoPath, "git", "commit", "-m", "experiment on "+branch)
+		}
+		tipA := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-a"))
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		testutils.RunCommand(t, repoPath, "git", "worktree", "add", t.TempDir()+"/wt", "feature-b")
+
+		err := runSoCommand(t, "restore", "s")
+		require.ErrorContains(t, err, "'feature-b' is checked out in worktree")
+		assert.Equal(t, tipA, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-a")), "nothing is moved")
+	})
+
+	t.Run("List and duplicate names", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		resetCommandFlags(t, snapshotCmd, "list")
+
+		require.NoError(t, runSoCommand(t, "snapshot", "one"))
+		err := runSoCommand(t, "snapshot", "one")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+
+		stdout, _, err := runSoCommandWithOutput(t, "snapshot", "--list")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "one  ")
+	})
+
+	t.Run("Restore refuses with uncommitted changes", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		require.NoError(t, runSoCommand(t, "snapshot", "s"))
+		writeFile(t, repoPath, "feature-a.txt", "dirty")
+
+		err := runSoCommand(t, "restore", "s")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "uncommitted changes")
+	})
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Break the current branch into stacked branches at chosen commits",
+	Long: `Splits the commits of the current tracked branch into a stack: you pick the
+last commit of each new branch, and every range of commits up to a pick
+becomes its own tracked branch. The current branch keeps the commits after
+the last pick (and its PR, if any).
+
+Without --at, socle lists the branch's commits and asks which ones end a
+branch and what to call each new branch. With --at, no questions are asked:
+
+  so split --at HEAD~3=auth-model --at HEAD~1=auth-api
+
+turns a branch of five commits into auth-model (the first two commits),
+auth-api (the next two) and the current branch (the last one), stacked in
+that order on the branch's parent. No commits are rewritten; the new branches
+point at existing commits. Use 'so slice' instead for one branch per commit.`,
+	Args: cobra.NoArgs,
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		at, _ := cmd.Flags().GetStringArray("at")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		runner := &splitCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			stdin:  os.Stdin,
+			at:     at,
+			dryRun: dryRun,
+		}
+		return runner.run()
+	}),
+}
+
+func init() {
+	AddCommand(splitCmd)
+	splitCmd.Flags().StringArray("at", nil, "End a new branch at a commit, as <commit>=<branch> (repeatable)")
+	splitCmd.Flags().Bool("dry-run", false, "Show the branches that would be created without changing anything")
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type splitCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+
+	at     []string // <commit>=<branch> from --at
+	dryRun bool
+}
+
+// splitPoint is a new branch ending at one of the source branch's commits.
+type splitPoint struct {
+	index  int // Position of commit among the source branch's commits
+	commit git.Commit
+	branch string
+}
+
+func (r *splitCmdRunner) run() error {
+	source, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parent, err := git.GetGitConfig(git.BranchConfigKey(source, "socle-parent"))
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", source)
+		}
+		return fmt.Errorf("failed to read parent of '%s': %w", source, err)
+	}
+	base, err := git.GetGitConfig(git.BranchConfigKey(source, "socle-base"))
+	if err != nil {
+		return fmt.Errorf("failed to read base of '%s': %w", source, err)
+	}
+
+	commits, err := git.GetCommits(parent, source)
+	if err != nil {
+		return err
+	}
+	if len(commits) < 2 {
+		_, _ = fmt.Fprintf(r.stdout, "'%s' has %d commit(s) on top of '%s'; nothing to split.\n", source, len(commits), parent)
+		return nil
+	}
+
+	var points []splitPoint
+	if len(r.at) > 0 {
+		points, err = r.pointsFromFlags(commits)
+	} else {
+		if nonInteractive || !hasInteractiveSurveyTerminal(r.stdin, r.stderr) {
+			return fmt.Errorf("no interactive terminal; choose the split points with --at <commit>=<branch>")
+		}
+		points, err = r.promptPoints(source, commits)
+	}
+	if err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		_, _ = fmt.Fprintln(r.stdout, "No split points chosen; nothing changed.")
+		return nil
+	}
+	if err := r.validateNames(points); err != nil {
+		return err
+	}
+
+	r.printPlan(source, parent, commits, points)
+	if r.dryRun {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("Dry run: nothing was changed."))
+		return nil
+	}
+
+	below := parent
+	for _, p := range points {
+		if err := git.CreateBranch(p.branch, p.commit.OID); err != nil {
+			return err
+		}
+		if err := git.SetGitConfig(git.BranchConfigKey(p.branch, "socle-base"), base); err != nil {
+			return err
+		}
+		if err := git.UpdateBranchParent(p.branch, below); err != nil {
+			return err
+		}
+		below = p.branch
+	}
+	if err := git.UpdateBranchParent(source, below); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ '%s' is now a stack of %d branches.", source, len(points)+1)))
+	_, _ = fmt.Fprintln(r.stdout, "Run 'so submit' to open pull requests for the new branches.")
+	return nil
+}
+
+// pointsFromFlags resolves --at values against commits (oldest first). The
+// tip cannot be a split point; it stays on the source branch.
+func (r *splitCmdRunner) pointsFromFlags(commits []git.Commit) ([]splitPoint, error) {
+	index := make(map[string]int, len(commits))
+	for i, c := range commits {
+		index[c.OID] = i
+	}
+
+	var points []splitPoint
+	seen := make(map[int]bool)
+	for _, value := range r.at {
+		rev, branch, ok := strings.Cut(value, "=")
+		rev, branch = strings.TrimSpace(rev), strings.TrimSpace(branch)
+		if !ok || rev == "" || branch == "" {
+			return nil, fmt.Errorf("invalid --at '%s': expected <commit>=<branch>", value)
+		}
+		oid, err := git.ResolveCommit(rev)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --at '%s': %w", value, err)
+		}
+		i, found := index[oid]
+		if !found {
+			return nil, fmt.Errorf("invalid --at '%s': the commit is not on the current branch", value)
+		}
+		if i == len(commits)-1 {
+			return nil, fmt.Errorf("invalid --at '%s': the last commit stays on the current branch", value)
+		}
+		if seen[i] {
+			return nil, fmt.Errorf("invalid --at '%s': commit %s is already a split point", value, oid[:8])
+		}
+		seen[i] = true
+		points = append(points, splitPoint{index: i, commit: commits[i], branch: branch})
+	}
+	sort.Slice(points, func(a, b int) bool { return points[a].index < points[b].index })
+	return points, nil
+}
+
+// promptPoints asks which commits end a new branch and what to call each.
+func (r *splitCmdRunner) promptPoints(source string, commits []git.Commit) ([]splitPoint, error) {
+	candidates := commits[:len(commits)-1]
+	labels := make([]string, len(candidates))
+	for i, c := range candidates {
+		labels[i] = fmt.Sprintf("%s %s", c.OID[:8], c.Subject)
+	}
+
+	surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
+	var picked []string
+	prompt := &survey.MultiSelect{
+		Message: fmt.Sprintf("Select the last commit of each new branch (later commits stay on '%s'):", source),
+		Options: labels,
+	}
+	if err := ui.AskOne(prompt, &picked, surveyOpts); err != nil {
+		return nil, ui.HandleSurveyInterrupt(err, "Split cancelled.")
+	}
+	chosen := make(map[string]bool, len(picked))
+	for _, label := range picked {
+		chosen[label] = true
+	}
+
+	taken := make(map[string]bool)
+	var points []splitPoint
+	for i, c := range candidates {
+		if !chosen[labels[i]] {
+			continue
+		}
+		name := git.BranchSlug(c.Subject)
+		if name == "" || taken[name] {
+			name = "split-" + c.OID[:8]
+		}
+		input := &survey.Input{Message: fmt.Sprintf("Branch ending at %s:", labels[i]), Default: name}
+		if err := ui.AskOne(input, &name, survey.WithValidator(survey.Required), surveyOpts); err != nil {
+			return nil, ui.HandleSurveyInterrupt(err, "Split cancelled.")
+		}
+		name = strings.TrimSpace(name)
+		taken[name] = true
+		points = append(points, splitPoint{index: i, commit: c, branch: name})
+	}
+	return points, nil
+}
+
+// validateNames rejects branch names that are invalid, already exist, are
+// used twice or match an ignore rule.
+func (r *splitCmdRunner) validateNames(points []splitPoint) error {
+	ignore, err := git.LoadBranchIgnoreRules()
+	if err != nil {
+		return err
+	}
+	used := make(map[string]bool, len(points))
+	for _, p := range points {
+		if used[p.branch] {
+			return fmt.Errorf("branch name '%s' is used for more than one split point", p.branch)
+		}
+		used[p.branch] = true
+		if err := git.IsValidBranchName(p.branch); err != nil {
+			return fmt.Errorf("invalid branch name '%s': %w", p.branch, err)
+		}
+		exists, err := git.BranchExists(p.branch)
+		if err != nil {
+			return fmt.Errorf("failed to check if branch '%s' exists: %w", p.branch, err)
+		}
+		if exists {
+			return fmt.Errorf("branch '%s' already exists", p.branch)
+		}
+		if pattern, ignored := ignore.Match(p.branch); ignored {
+			return fmt.Errorf("branch name '%s' matches the ignore rule '%s' (%s or socle.ignoreBranches); socle would not track it", p.branch, pattern, git.BranchIgnoreFile)
+		}
+	}
+	return nil
+}
+
+func (r *splitCmdRunner) printPlan(source, parent string, commits []git.Commit, points []splitPoint) {
+	_, _ = fmt.Fprintf(r.stdout, "Splitting '%s' on top of '%s':\n", source, parent)
+	start := 0
+	branches := append(append([]splitPoint(nil), points...), splitPoint{index: len(commits) - 1, branch: source})
+	for _, p := range branches {
+		note := "new"
+		if p.branch == source {
+			note = "current branch"
+		}
+		_, _ = fmt.Fprintf(r.stdout, "  %s %s\n", p.branch, ui.Colors.MutedStyle.Render(fmt.Sprintf("(%s, %d commit(s))", note, p.index-start+1)))
+		for _, c := range commits[start : p.index+1] {
+			_, _ = fmt.Fprintf(r.stdout, "    %s\n", ui.Colors.FaintStyle.Render(c.OID[:8]+" "+c.Subject))
+		}
+		start = p.index + 1
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCommand(t *testing.T) {
+	resetCommandFlags(t, splitCmd, "at", "dry-run")
+
+	commit := func(t *testing.T, repoPath, file, message string) {
+		writeFile(t, repoPath, file, message)
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", message)
+	}
+	parentOf := func(t *testing.T, branch string) string {
+		parent, err := git.GetGitConfig("branch." + branch + ".socle-parent")
+		require.NoError(t, err, "branch %s should be tracked", branch)
+		return parent
+	}
+	tipSubject := func(t *testing.T, repoPath, branch string) string {
+		return strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "log", "-1", "--format=%s", branch))
+	}
+
+	t.Run("Splits commit ranges into stacked branches", func(t *testing.T) {
+		resetCommandFlags(t, splitCmd, "at", "dry-run")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature"})
+		defer cleanup()
+		commit(t, repoPath, "model.txt", "Add auth model")
+		commit(t, repoPath, "api.txt", "Add auth API")
+		commit(t, repoPath, "routes.txt", "Wire auth routes")
+		commit(t, repoPath, "ui.txt", "Add login screen")
+		tip := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature"))
+
+		stdout, _, err := runSoCommandWithOutput(t, "split", "--at", "HEAD~1=auth-api", "--at", "HEAD~3=auth-model", "--dry-run")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "auth-model (new, 2 commit(s))")
+		assert.Contains(t, out, "auth-api (new, 2 commit(s))")
+		assert.Contains(t, out, "feature (current branch, 1 commit(s))")
+		exists, err := git.BranchExists("auth-model")
+		require.NoError(t, err)
+		assert.False(t, exists, "dry run creates nothing")
+
+		resetCommandFlags(t, splitCmd, "at", "dry-run")
+		stdout, _, err = runSoCommandWithOutput(t, "split", "--at", "HEAD~3=auth-model", "--at", "HEAD~1=auth-api")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "'feature' is now a stack of 3 branches.")
+
+		assert.Equal(t, "main", parentOf(t, "auth-model"))
+		assert.Equal(t, "auth-model", parentOf(t, "auth-api"))
+		assert.Equal(t, "auth-api", parentOf(t, "feature"))
+		base, err := git.GetGitConfig("branch.auth-api.socle-base")
+		require.NoError(t, err)
+		assert.Equal(t, "main", base)
+		assert.Equal(t, "Add auth model", tipSubject(t, repoPath, "auth-model"))
+		assert.Equal(t, "Wire auth routes", tipSubject(t, repoPath, "auth-api"))
+		assert.Equal(t, tip, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature")), "no commits are rewritten")
+	})
+
+	t.Run("Rejects split points that are not below the tip", func(t *testing.T) {
+		resetCommandFlags(t, splitCmd, "at", "dry-run")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature"})
+		defer cleanup()
+		commit(t, repoPath, "model.txt", "Add auth model")
+
+		_, _, err := runSoCommandWithOutput(t, "split", "--at", "HEAD=top")
+		require.ErrorContains(t, err, "the last commit stays on the current branch")
+
+		resetCommandFlags(t, splitCmd, "at", "dry-run")
+		_, _, err = runSoCommandWithOutput(t, "split", "--at", "main=old")
+		require.ErrorContains(t, err, "the commit is not on the current branch")
+
+		resetCommandFlags(t, splitCmd, "at", "dry-run")
+		_, _, err = runSoCommandWithOutput(t, "split", "--at", "HEAD~1")
+		require.ErrorContains(t, err, "expected <commit>=<branch>")
+
+		resetCommandFlags(t, splitCmd, "at", "dry-run")
+		_, _, err = runSoCommandWithOutput(t, "split")
+		require.ErrorContains(t, err, "choose the split points with --at")
+	})
+}
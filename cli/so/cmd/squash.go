@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var squashCmd = &cobra.Command{
+	Use:   "squash",
+	Short: "Squash the current branch's commits into one and restack the branches above",
+	Long: `Replaces the commits of the current branch (those since its parent) with a
+single commit holding the same changes, e.g. to tidy a branch up before
+review. The commit's message joins the messages of the squashed commits,
+oldest first, leaving out those of 'fixup!' commits. Pass -m to use another
+message, or --edit to adjust it in your editor.
+
+The working tree must be clean and the branch must sit on its parent; run
+'so restack' first if it does not.
+
+Then every branch stacked above the current one is rebased onto the new
+commit, replaying only its own commits, as 'so restack --upstack' would.
+Nothing is fetched or pushed; run 'so submit' to update the PRs. On a
+conflict, resolve it and run 'so continue'.`,
+	Args: cobra.NoArgs,
+	RunE: withNextStepHint(guardStackInvariants(func(cmd *cobra.Command, args []string) error {
+		onConflict, err := conflictPolicy(cmd)
+		if err != nil {
+			return err
+		}
+
+		runner := &squashCmdRunner{
+			logger:         slog.Default(),
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          os.Stdin,
+			nonInteractive: nonInteractive,
+			message:        cmd.Flag("message").Value.String(),
+			edit:           cmd.Flag("edit").Changed,
+			onConflict:     onConflict,
+		}
+		return runner.run(cmd)
+	})),
+}
+
+func init() {
+	AddCommand(squashCmd)
+	squashCmd.Flags().StringP("message", "m", "", "Message of the squashed commit instead of the joined messages")
+	squashCmd.Flags().BoolP("edit", "e", false, "Edit the message of the squashed commit in your editor")
+	squashCmd.Flags().String("stop-on-conflict", conflictHalt, "On a conflict, halt for you to resolve it or skip the branch and its descendants: halt|skip (default from socle.stopOnConflict)")
+}
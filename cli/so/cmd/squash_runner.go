@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+type squashCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+	stdin  io.Reader
+
+	nonInteractive bool
+
+	message    string // Message of the squashed commit instead of the joined ones
+	edit       bool   // Edit the message in $EDITOR before committing
+	onConflict string // conflictHalt or conflictSkip
+}
+
+func (r *squashCmdRunner) run(cmd *cobra.Command) error {
+	if git.IsRebaseInProgress() {
+		return socleerr.New(socleerr.Conflict, "a rebase is in progress. Finish it with 'so continue' or 'git rebase --continue' first")
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parent, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-parent"))
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", branch)
+		}
+		return fmt.Errorf("failed to check tracking status for branch '%s': %w", branch, err)
+	}
+
+	hasChanges, err := git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if hasChanges {
+		return socleerr.New(socleerr.DirtyWorktree, "you have uncommitted changes. Commit or stash them before squashing '%s'", branch)
+	}
+
+	// Resetting onto a parent the branch is not on would fold the parent's
+	// newer changes, reverted, into the squashed commit.
+	onParent, err := git.IsAncestor(parent, branch)
+	if err != nil {
+		return fmt.Errorf("failed to check whether '%s' is on '%s': %w", branch, parent, err)
+	}
+	if !onParent {
+		return fmt.Errorf("'%s' is not on top of its parent '%s'. Run 'so restack' first", branch, parent)
+	}
+
+	commits, err := git.GetCommits(parent, branch)
+	if err != nil {
+		return fmt.Errorf("failed to list the commits of '%s': %w", branch, err)
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("'%s' has no commits of its own to squash", branch)
+	}
+	if len(commits) == 1 && r.message == "" && !r.edit {
+		_, _ = fmt.Fprintf(r.stdout, "'%s' already has a single commit; nothing to squash.\n", branch)
+		return nil
+	}
+
+	message := strings.TrimSpace(r.message)
+	if message == "" {
+		messages, err := git.CommitMessages(parent, branch)
+		if err != nil {
+			return err
+		}
+		message = squashMessage(messages)
+	}
+	if r.edit {
+		if r.nonInteractive {
+			return fmt.Errorf("--edit needs an interactive terminal; pass the message with -m instead")
+		}
+		prompt := &survey.Editor{Message: fmt.Sprintf("Message of the squashed commit of '%s':", branch), FileName: "COMMIT_EDITMSG", Default: message, HideDefault: true, AppendDefault: true}
+		if err := ui.AskOne(prompt, &message, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr)); err != nil {
+			return fmt.Errorf("failed to edit the commit message: %w", err)
+		}
+		message = strings.TrimSpace(message)
+	}
+	if message == "" {
+		return fmt.Errorf("the commit message is empty; '%s' was not squashed", branch)
+	}
+
+	// The branches above still hold the old tip; each replays only the
+	// commits after its parent's current tip.
+	above, err := recordUpstackStarts(branch)
+	if err != nil {
+		return err
+	}
+
+	oldTip, err := git.GetCurrentCommit()
+	if err != nil {
+		return err
+	}
+	parentTip, err := git.GetCurrentBranchCommit(parent)
+	if err != nil {
+		return fmt.Errorf("cannot get current commit of '%s': %w", parent, err)
+	}
+	if err := git.ResetSoft(parentTip); err != nil {
+		return err
+	}
+	if err := git.CommitChanges(message); err != nil {
+		if resetErr := git.ResetSoft(oldTip); resetErr != nil {
+			r.logger.Error("Failed to restore the branch after the commit failed", "branch", branch, "oid", oldTip, "error", resetErr)
+			return fmt.Errorf("%w; restore '%s' with 'git reset --soft %s'", err, branch, oldTip)
+		}
+		return err
+	}
+	if len(commits) == 1 {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Reworded the commit of '%s'.", branch)))
+	} else {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("✓ Squashed %d commits of '%s' into one.", len(commits), branch)))
+	}
+
+	if above == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, "\nRestacking the branches above...")
+	restack := &restackCmdRunner{
+		logger:         r.logger,
+		stdout:         r.stdout,
+		stderr:         r.stderr,
+		stdin:          r.stdin,
+		nonInteractive: r.nonInteractive,
+		noFetch:        true,
+		noPush:         true,
+		scope:          scopeUpstack,
+		onConflict:     r.onConflict,
+	}
+	return restack.run(cmd)
+}
+
+// squashMessage joins the messages of the squashed commits, oldest first,
+// leaving out those of 'fixup!' commits, which repeat the subject of the
+// commit they fix. If every commit is a fixup, all messages are kept.
+func squashMessage(messages []string) string {
+	var kept []string
+	for _, message := range messages {
+		if !strings.HasPrefix(message, "fixup! ") {
+			kept = append(kept, message)
+		}
+	}
+	if len(kept) == 0 {
+		kept = messages
+	}
+	return strings.Join(kept, "\n\n")
+}
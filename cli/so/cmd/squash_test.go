@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquashCommand(t *testing.T) {
+	// -m keeps its value from one run of the command to the next.
+	addCommit := func(t *testing.T, repoPath, file, message string) {
+		writeFile(t, repoPath, file, file)
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", message)
+	}
+
+	t.Run("Squashes the branch's commits and restacks the branches above", func(t *testing.T) {
+		resetCommandFlags(t, squashCmd, "message")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		addCommit(t, repoPath, "second.txt", "feat: second\n\nWith a body.")
+		addCommit(t, repoPath, "fix.txt", "fixup! feat: second")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+		require.NoError(t, runSoCommand(t, "restack", "--no-fetch", "--no-push"))
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		treeBefore := testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-a^{tree}")
+
+		stdout, _, err := runSoCommandWithOutput(t, "squash")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Squashed 3 commits of 'feature-a' into one.")
+
+		commits, err := git.GetCommits("main", "feature-a")
+		require.NoError(t, err)
+		require.Len(t, commits, 1)
+		assert.Equal(t, "feat: commit on feature-a\n\nfeat: second\n\nWith a body.",
+			strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "log", "-1", "--format=%B", "feature-a")))
+		assert.Equal(t, treeBefore, testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-a^{tree}"), "the changes stay the same")
+
+		for _, step := range [][2]string{{"feature-a", "feature-b"}, {"feature-b", "feature-c"}} {
+			onParent, err := git.IsAncestor(step[0], step[1])
+			require.NoError(t, err)
+			assert.True(t, onParent, "%s is restacked onto %s", step[1], step[0])
+			own, err := git.GetCommits(step[0], step[1])
+			require.NoError(t, err)
+			assert.Len(t, own, 1, "only the commit of %s is replayed", step[1])
+		}
+		assert.Equal(t, "fix.txt", testutils.RunCommand(t, repoPath, "git", "show", "feature-c:fix.txt"))
+		assert.Empty(t, git.GetRestackUpstream("feature-b"), "the recorded start is cleared")
+	})
+
+	t.Run("With -m uses that message", func(t *testing.T) {
+		resetCommandFlags(t, squashCmd, "message")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		addCommit(t, repoPath, "second.txt", "feat: second")
+		require.NoError(t, runSoCommand(t, "squash", "-m", "feat: all of feature-a"))
+
+		commits, err := git.GetCommits("main", "feature-a")
+		require.NoError(t, err)
+		require.Len(t, commits, 1)
+		assert.Equal(t, "feat: all of feature-a", commits[0].Subject)
+	})
+
+	t.Run("A single commit is left alone", func(t *testing.T) {
+		resetCommandFlags(t, squashCmd, "message")
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		before, err := git.GetCurrentBranchCommit("feature-a")
+		require.NoError(t, err)
+
+		stdout, _, err := runSoCommandWithOutput(t, "squash")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "'feature-a' already has a single commit; nothing to squash.")
+		after, err := git.GetCurrentBranchCommit("feature-a")
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("Refuses a branch that is not on its parent", func(t *testing.T) {
+		resetCommandFlags(t, squashCmd, "message")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-a")
+		addCommit(t, repoPath, "second.txt", "feat: second")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+		addCommit(t, repoPath, "third.txt", "feat: third")
+		before, err := git.GetCurrentBranchCommit("feature-b")
+		require.NoError(t, err)
+
+		err = runSoCommand(t, "squash")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'feature-b' is not on top of its parent 'feature-a'. Run 'so restack' first")
+		after, err := git.GetCurrentBranchCommit("feature-b")
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("Refuses uncommitted changes", func(t *testing.T) {
+		resetCommandFlags(t, squashCmd, "message")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		addCommit(t, repoPath, "second.txt", "feat: second")
+		writeFile(t, repoPath, "wip.txt", "wip")
+
+		err := runSoCommand(t, "squash")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "uncommitted changes")
+	})
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var stackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "Manage the current stack as a whole",
+	Long: `Groups commands that apply to the current stack as a whole rather than to
+one of its branches.`,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	AddCommand(stackCmd)
+}
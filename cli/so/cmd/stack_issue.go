@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var stackIssueCmd = &cobra.Command{
+	Use:   "issue [number|url]",
+	Short: "Set the issue that tracks the current stack, or show it",
+	Long: `Links the current stack to a tracking issue, given as a number, '#123' or
+the issue's URL, or prints the stack's tracking issue when called without
+one. Use --clear to unlink it. 'so submit --tracking-issue' sets it too.
+
+With a tracking issue, 'so submit':
+  - ends the description of every new PR with "Part of #<issue>", which puts
+    the PR on the issue's timeline without closing the issue when the PR merges;
+  - keeps a task list of the stack's PRs in a comment on the issue, ticking
+    PRs as they merge. PRs that left the stack stay on the list, ticked, if
+    they merged; ones closed without merging are dropped from it.
+
+With 'socle.closeTrackingIssue' set to true, 'so ship' and 'so merge' close
+the issue once they merge the last PR of the stack.
+
+The issue is stored in git config on every branch of the stack as
+branch.<branch>.socle-tracking-issue, and submit copies it to branches added
+later, so it stays with the stack as its lower branches merge and are deleted.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clear, _ := cmd.Flags().GetBool("clear")
+
+		runner := &stackIssueCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			clear:  clear,
+		}
+		if len(args) > 0 {
+			runner.reference = args[0]
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	stackCmd.AddCommand(stackIssueCmd)
+	stackIssueCmd.Flags().Bool("clear", false, "Unlink the stack from its tracking issue")
+}
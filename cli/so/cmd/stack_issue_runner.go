@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type stackIssueCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	reference string
+	clear     bool
+}
+
+func (r *stackIssueCmdRunner) run() error {
+	branches, err := currentStackBranches()
+	if err != nil {
+		return err
+	}
+
+	if r.clear {
+		if err := git.SetTrackingIssue(branches, 0); err != nil {
+			return fmt.Errorf("failed to clear the tracking issue: %w", err)
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Unlinked the stack from its tracking issue."))
+		return nil
+	}
+
+	if r.reference == "" {
+		issue, err := git.GetTrackingIssue(branches)
+		if err != nil {
+			return err
+		}
+		if issue == 0 {
+			_, _ = fmt.Fprintln(r.stdout, "This stack has no tracking issue. Set one with 'so stack issue <number>'.")
+			return nil
+		}
+		_, _ = fmt.Fprintf(r.stdout, "#%d\n", issue)
+		return nil
+	}
+
+	issue, err := parseIssueReference(r.reference)
+	if err != nil {
+		return err
+	}
+	if err := git.SetTrackingIssue(branches, issue); err != nil {
+		return fmt.Errorf("failed to save the tracking issue: %w", err)
+	}
+	r.logger.Debug("Set tracking issue", "issue", issue, "branches", branches)
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("The stack is tracked in #%d. 'so submit' links its PRs there.", issue)))
+	return nil
+}
+
+// issueReferenceRegex matches an issue number, optionally with '#', or an
+// issue URL.
+var issueReferenceRegex = regexp.MustCompile(`^(?:#?(\d+)|https?://[^/]+/([^/]+)/([^/]+)/issues/(\d+)/?)$`)
+
+// parseIssueReference returns the number of an issue of the origin
+// repository given as 123, #123 or its URL.
+func parseIssueReference(reference string) (int, error) {
+	m := issueReferenceRegex.FindStringSubmatch(strings.TrimSpace(reference))
+	if m == nil {
+		return 0, fmt.Errorf("'%s' is not an issue number or URL", reference)
+	}
+	number := m[1]
+	if number == "" {
+		if remoteURL, err := git.GetRemoteURL(git.GetRemoteName()); err == nil {
+			if owner, repo, err := git.ParseOwnerAndRepo(remoteURL); err == nil && (!strings.EqualFold(m[2], owner) || !strings.EqualFold(m[3], repo)) {
+				return 0, fmt.Errorf("'%s' belongs to %s/%s, but the remote is %s/%s", reference, m[2], m[3], owner, repo)
+			}
+		}
+		number = m[4]
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("'%s' is not a valid issue number", reference)
+	}
+	return n, nil
+}
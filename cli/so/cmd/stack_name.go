@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var stackNameCmd = &cobra.Command{
+	Use:   "name [name]",
+	Short: "Name the current stack, or show its name",
+	Long: `Gives the current stack a name such as 'auth-refactor', or prints the
+stack's name when called without one. Use --clear to remove it.
+
+'so submit --stack-name' uses the name to group the stack's PRs on GitHub:
+'prefix' starts the title of new PRs with "[auth-refactor] ", 'label' adds the
+label 'stack:auth-refactor' to every PR, and 'both' does both. Searching for
+label:stack:auth-refactor then finds the whole stack.
+
+Names may contain letters, digits, '.', '_', '-' and '/'. The name is stored in
+git config on the bottom branch of the stack as
+branch.<bottom>.socle-stack-name, so it stays with the stack as branches are
+added on top.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clear, _ := cmd.Flags().GetBool("clear")
+
+		runner := &stackNameCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			clear:  clear,
+		}
+		if len(args) > 0 {
+			runner.name = args[0]
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	stackCmd.AddCommand(stackNameCmd)
+	stackNameCmd.Flags().Bool("clear", false, "Remove the stack's name")
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+// stackNamePattern keeps stack names usable in PR titles, label names and
+// GitHub search queries without quoting.
+var stackNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+type stackNameCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	name  string
+	clear bool
+}
+
+func (r *stackNameCmdRunner) run() error {
+	bottom, err := currentStackBottom()
+	if err != nil {
+		return err
+	}
+
+	if r.clear {
+		if err := git.SetStackName(bottom, ""); err != nil {
+			return fmt.Errorf("failed to clear the stack name on '%s': %w", bottom, err)
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Cleared the stack's name."))
+		return nil
+	}
+
+	if r.name == "" {
+		name, err := git.GetStackName(bottom)
+		if err != nil {
+			return fmt.Errorf("failed to read the stack name on '%s': %w", bottom, err)
+		}
+		if name == "" {
+			_, _ = fmt.Fprintln(r.stdout, "This stack has no name. Set one with 'so stack name <name>'.")
+			return nil
+		}
+		_, _ = fmt.Fprintln(r.stdout, name)
+		return nil
+	}
+
+	if !stackNamePattern.MatchString(r.name) {
+		return fmt.Errorf("invalid stack name '%s': use letters, digits, '.', '_', '-' and '/'", r.name)
+	}
+	if err := git.SetStackName(bottom, r.name); err != nil {
+		return fmt.Errorf("failed to save the stack name on '%s': %w", bottom, err)
+	}
+	r.logger.Debug("Named stack", "bottom", bottom, "name", r.name)
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Named the stack '%s'.", r.name)))
+	return nil
+}
+
+// currentStackBottom returns the lowest branch above the base of the stack
+// the current branch belongs to.
+func currentStackBottom() (string, error) {
+	branches, err := currentStackBranches()
+	if err != nil {
+		return "", err
+	}
+	return branches[0], nil
+}
+
+// currentStackBranches returns the branches above the base of the stack the
+// current branch belongs to, bottom first.
+func currentStackBranches() ([]string, error) {
+	stackInfo, err := git.GetStackInfo()
+	if err != nil {
+		return nil, err
+	}
+	stack := stackInfo.FullStack
+	if stack == nil {
+		stack = stackInfo.CurrentStack
+	}
+	if len(stack) <= 1 {
+		return nil, fmt.Errorf("not on a stack: check out a tracked branch of the stack first")
+	}
+	return stack[1:], nil
+}
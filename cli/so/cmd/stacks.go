@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var stacksCmd = &cobra.Command{
+	Use:   "stacks",
+	Short: "List every tracked stack in the repository",
+	Long: `Lists the tracked stacks on every base branch, wherever you are: each stack's
+branches from bottom to top (with its name, if set with 'so stack name'),
+how many branches it has and how many of them need a restack. The stack
+holding the current branch is marked with '*', along with whether the
+working tree has uncommitted changes.
+
+Only local git state is read, so the list costs no GitHub requests. With
+--detailed, every stack is shown the way 'so log' shows several stacks,
+PR statuses included.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &stacksCmdRunner{
+			logger:   slog.Default(),
+			stdout:   cmd.OutOrStdout(),
+			stderr:   cmd.ErrOrStderr(),
+			detailed: cmd.Flag("detailed").Changed,
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	AddCommand(stacksCmd)
+	stacksCmd.Flags().BoolP("detailed", "d", false, "Show every branch of every stack with its PR status, as 'so log' does")
+}
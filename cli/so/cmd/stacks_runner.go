@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/interrupt"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type stacksCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	detailed bool // Show the stacks as 'so log' does instead of one line each
+}
+
+func (r *stacksCmdRunner) run(ctx context.Context) error {
+	currentBranch, err := git.GetCurrentBranch()
+	if err != nil {
+		r.logger.Debug("No current branch, no stack is marked", "error", err)
+		currentBranch = ""
+	}
+
+	bases, err := r.basesWithStacks()
+	if err != nil {
+		return err
+	}
+	if len(bases) == 0 {
+		_, _ = fmt.Fprintf(r.stdout, "No tracked stacks on any base branch (%s). Start one with 'so create'.\n", strings.Join(git.KnownBaseBranches(), ", "))
+		return nil
+	}
+
+	if r.detailed {
+		logRunner := &logCmdRunner{logger: r.logger, stdout: r.stdout, stderr: r.stderr, everyone: true}
+		for _, base := range bases {
+			if err := logRunner.displayMultipleStacks(ctx, base, currentBranch); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	dirty := false
+	if currentBranch != "" {
+		if dirty, err = git.HasUncommittedChanges(); err != nil {
+			r.logger.Debug("Failed to check working tree status", "error", err)
+		}
+	}
+	logRunner := &logCmdRunner{logger: r.logger, stdout: io.Discard, stderr: r.stderr}
+	printed := 0
+	for _, base := range bases {
+		stacks, err := git.GetAvailableStacksFromBase(base)
+		if err != nil {
+			return fmt.Errorf("failed to get the stacks on '%s': %w", base, err)
+		}
+		if len(stacks) == 0 {
+			continue // Only ignored branches on it
+		}
+		if printed++; printed > 1 {
+			_, _ = fmt.Fprintln(r.stdout)
+		}
+		_, _ = fmt.Fprintln(r.stdout, lipgloss.NewStyle().Bold(true).Render(base))
+		for _, stack := range stacks {
+			infos := logRunner.collectBranchInfos(ctx, stack, stackParentOIDs(stack), nil)
+			if err := interrupt.Check(ctx, "run 'so stacks' again"); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(r.stdout, r.stackLine(stack, infos, currentBranch, dirty))
+		}
+	}
+	return nil
+}
+
+// basesWithStacks returns the known base branches that exist and have
+// tracked branches on them, configured ones first.
+func (r *stacksCmdRunner) basesWithStacks() ([]string, error) {
+	parentMap, err := git.GetAllSocleParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking relationships: %w", err)
+	}
+	childMap := git.BuildChildMap(parentMap)
+	var bases []string
+	for _, base := range syncBases() {
+		if len(childMap[base]) == 0 {
+			continue
+		}
+		if exists, err := git.BranchExists(base); err != nil || !exists {
+			continue
+		}
+		bases = append(bases, base)
+	}
+	return bases, nil
+}
+
+// stackLine renders one stack: its branches bottom to top, how many need a
+// restack, and whether it holds the current branch. infos is ordered top of
+// the stack first, as collectBranchInfos returns it.
+func (r *stacksCmdRunner) stackLine(stack []string, infos []branchLogInfo, currentBranch string, dirty bool) string {
+	branches := stack[1:]
+	name := branches[0]
+	if len(branches) > 1 {
+		name += " → " + branches[len(branches)-1]
+	}
+	if stackName, err := git.GetStackName(branches[0]); err == nil && stackName != "" {
+		name = fmt.Sprintf("%s (%s)", stackName, name)
+	}
+
+	stale := 0
+	for _, info := range infos {
+		if info.rebaseStatus.status == RebaseStatusNeedsRestack || info.rebaseStatus.status == RebaseStatusMerged {
+			stale++
+		}
+	}
+	summary := fmt.Sprintf("%d %s", len(branches), pluralize(len(branches), "branch", "branches"))
+	if stale > 0 {
+		summary += ", " + ui.Colors.WarningStyle.Render(fmt.Sprintf("%d %s restack", stale, pluralize(stale, "needs", "need")))
+	} else {
+		summary += ", " + ui.Colors.SuccessStyle.Render("up to date")
+	}
+
+	marker := " "
+	if slices.Contains(branches, currentBranch) {
+		marker = ui.Colors.InfoStyle.Render("*")
+		head := fmt.Sprintf("HEAD on '%s'", currentBranch)
+		if dirty {
+			head += ", uncommitted changes"
+		}
+		summary += "  " + mutedStyle.Render(head)
+	}
+	return fmt.Sprintf("  %s %s  %s", marker, name, summary)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStacksCommand(t *testing.T) {
+	// --detailed keeps its value from one run of the command to the next.
+	resetCommandFlags(t, stacksCmd, "detailed")
+
+	t.Run("Lists the stacks of every base from a branch of one", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", "feature-c", "main")
+		writeFile(t, repoPath, "c.txt", "c")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "feat: c")
+		require.NoError(t, runSoCommand(t, "track", "--test-parent=main"))
+
+		testutils.RunCommand(t, repoPath, "git", "branch", "develop", "main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", "feature-d", "develop")
+		writeFile(t, repoPath, "d.txt", "d")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "feat: d")
+		require.NoError(t, runSoCommand(t, "track", "--test-parent=develop"))
+		testutils.RunCommand(t, repoPath, "git", "config", "branch.feature-d.socle-stack-name", "docs")
+
+		// Both stacks on main fall behind it; work in progress on feature-b
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+		writeFile(t, repoPath, "main.txt", "main")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "feat: main moves")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+		writeFile(t, repoPath, "wip.txt", "wip")
+
+		stdout, _, err := runSoCommandWithOutput(t, "stacks")
+		require.NoError(t, err)
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "main\n")
+		assert.Contains(t, out, "  * feature-a → feature-b  2 branches, 1 needs restack  HEAD on 'feature-b', uncommitted changes\n")
+		assert.Contains(t, out, "    feature-c  1 branch, 1 needs restack\n")
+		assert.Contains(t, out, "\n\ndevelop\n    docs (feature-d)  1 branch, up to date\n")
+
+		stdout, _, err = runSoCommandWithOutput(t, "stacks", "--detailed")
+		require.NoError(t, err)
+		out = stripAnsi(stdout)
+		assert.Contains(t, out, "2 stacks from base 'main'")
+		assert.Contains(t, out, "1 stack from base 'develop'")
+	})
+
+	t.Run("No stacks", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main"})
+		defer cleanup()
+
+		stdout, _, err := runSoCommandWithOutput(t, "stacks")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "No tracked stacks on any base branch")
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/benekuehn/socle/cli/so/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -16,12 +17,81 @@ var submitCmd = &cobra.Command{
 	Long: `Pushes branches in the current stack to the remote ('origin' by default)
 and creates or updates corresponding GitHub Pull Requests.
 
-- Requires GITHUB_TOKEN environment variable with 'repo' scope or auth setup via 'gh auth login'.
-- Reads PR templates from .github/ or root directory.
+- Requires GITHUB_TOKEN environment variable with 'repo' scope or auth setup via 'gh auth login',
+  a token stored in the OS keychain under service 'socle' and account 'github.com'
+  (e.g. 'security add-generic-password -s socle -a github.com -w' on macOS or
+  'secret-tool store --label=socle service socle account github.com' on Linux),
+  or a GitHub App installation with 'socle.auth' set to 'app' (see 'so config').
+- Reads PR templates from .github/ or root directory, or from the file named
+  by 'socle.prTemplatePath'. In the description of a new PR, {{branch}},
+  {{parent}} and {{base}} become branch names, {{parent_pr}} the parent's PR
+  ("#12") and {{stack_overview}} the stack overview of the stack comment,
+  which later submits keep current.
 - Creates Draft PRs by default (use --no-draft to override).
-- Stores PR numbers locally in '.git/config' for future updates.`,
+- Reads defaults from 'socle.submit.draft', 'socle.submit.noPush' and
+  'socle.submit.assignReviewers' (SOCLE_DRAFT, SOCLE_NO_PUSH and
+  SOCLE_ASSIGN_REVIEWERS in the environment, or repo or user config); flags
+  given on the command line override them, e.g. --draft or --no-push=false.
+  See 'so config'.
+- Pushes with a lease: a remote branch is only overwritten while it is still
+  at the commit socle last pushed, or at one the local branch already
+  contains, so a teammate's push to a shared branch is never lost. Use
+  --force to overwrite it anyway.
+- Stores PR numbers locally in '.git/config' for future updates.
+- Remembers the tip each branch was pushed with and its PR's base. Branches
+  that have not changed since their last submit are neither pushed nor
+  checked on GitHub again; use --all to process every branch.
+- Forwards push options from 'socle.pushOptions' and --push-option to every push,
+  and signs pushes when 'socle.signedPush' is 'true' or 'if-asked'.
+- Skips branches marked with 'so wip', and every branch stacked on top of them.
+- Opens each PR against the branch's parent, or against the branch set with
+  'so pr base --override' (e.g. trunk while the parent is to be folded).
+- With --trailers (or 'socle.commitTrailers'), rewrites commits to carry
+  'Stacked-on: <parent>' and 'PR: <url>' trailers before pushing. The PR trailer
+  of a newly created PR is added on the next submit or restack.
+- With --assign-reviewers, requests one reviewer from the 'socle.reviewers' pool
+  for each PR that has none, rotating across the stack (see 'so pr reviewers').
+- With --verify-body, warns about PRs whose description lacks a heading listed
+  in 'socle.requiredSections' (see 'so pr template sync').
+- With --stack-name (or 'socle.submit.stackName', SOCLE_STACK_NAME), marks the
+  PRs with the name given by 'so stack name': 'prefix' starts the title of new
+  PRs with "[<name>] ", 'label' adds the label 'stack:<name>' to every PR and
+  'both' does both. Stacks without a name are submitted unchanged.
+- With --tracking-issue (or an issue set with 'so stack issue'), ends the
+  description of new PRs with "Part of #<issue>" and keeps a task list of the
+  stack's PRs in a comment on the issue.
+- With --delete-branch-on-merge (or 'socle.submit.deleteBranchOnMerge'), new
+  PRs have their head branch deleted once they merge: by GitHub when the
+  repository's "Automatically delete head branches" setting is on, otherwise
+  by 'so sync'. Either way sync expects the remote branch to disappear.
+- Before pushing, scans the lines each branch adds for credentials such as
+  private keys and AWS, GitHub or Slack tokens, and stops without pushing if
+  it finds any. 'socle.secretScanCommand' (SOCLE_SECRET_SCAN_COMMAND) adds a
+  shell command that gets the diff on stdin, with SOCLE_BRANCH and
+  SOCLE_PARENT set, and blocks the push by exiting non-zero; its output is
+  shown. Use --no-secret-scan to push anyway, or set 'socle.secretScan' to
+  false to turn scanning off.
+- The title prompt of a new PR starts from the subject of the branch's first
+  commit. 'socle.prTitle' picks another source: 'newest-commit', 'branch' (the
+  branch name) or 'pattern', which fills 'socle.prTitlePattern' from {branch},
+  {words} (the name with spaces for dashes), {first}, {newest} and {count}.
+  Commits are counted from where the branch forks off its parent; merge
+  commits and commits the parent already has as a copy with the same change
+  (after a rebase or cherry-pick upstream) are skipped. Commits squashed
+  together into the parent are not recognized.
+- With 'socle.messageGenerator' set, offers to draft the title and description
+  of each new PR: the command gets the branch's diff on stdin with
+  SOCLE_MESSAGE_KIND=pr, and the first line of its output pre-fills the title
+  prompt and the rest the description, unless the branch has a description
+  from 'so describe'.
+- With --only, --downstack, --until <branch> or --stack-only <branch>,...,
+  pushes and updates just part of the stack: the current branch, the
+  branches up to the current one, those up to the named one, or the named
+  ones. A branch is only submitted without its parent when the parent
+  already has a PR, or its PR base is overridden. The stack comments of the other PRs are only rewritten
+  when the stack's shape changed.`,
 	Args: cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: withNextStepHint(func(cmd *cobra.Command, args []string) error {
 		logger := slog.Default()
 
 		body, _ := cmd.Flags().GetString("body")
@@ -37,8 +107,52 @@ and creates or updates corresponding GitHub Pull Requests.
 
 		title, _ := cmd.Flags().GetString("title")
 		forcePush, _ := cmd.Flags().GetBool("force")
-		noPush, _ := cmd.Flags().GetBool("no-push")
-		noDraft, _ := cmd.Flags().GetBool("no-draft")
+		pushOptions, _ := cmd.Flags().GetStringArray("push-option")
+		trailers, _ := cmd.Flags().GetBool("trailers")
+		verifyBody, _ := cmd.Flags().GetBool("verify-body")
+
+		// socle.submit.* supplies the defaults; flags given explicitly win.
+		defaults, err := git.LoadSubmitDefaults()
+		if err != nil {
+			return err
+		}
+		noPush := defaults.NoPush
+		if cmd.Flags().Changed("no-push") {
+			noPush, _ = cmd.Flags().GetBool("no-push")
+		}
+		draft := defaults.Draft
+		if cmd.Flags().Changed("draft") {
+			draft, _ = cmd.Flags().GetBool("draft")
+		}
+		if cmd.Flags().Changed("no-draft") {
+			noDraft, _ := cmd.Flags().GetBool("no-draft")
+			draft = !noDraft
+		}
+		assignReviewers := defaults.AssignReviewers
+		stackNameMode := defaults.StackName
+		if cmd.Flags().Changed("stack-name") {
+			stackNameMode, _ = cmd.Flags().GetString("stack-name")
+		}
+		switch stackNameMode {
+		case "off", "prefix", "label", "both":
+		default:
+			return fmt.Errorf("invalid --stack-name '%s': expected off, prefix, label or both", stackNameMode)
+		}
+		trackingIssue := 0
+		if ref, _ := cmd.Flags().GetString("tracking-issue"); ref != "" {
+			if trackingIssue, err = parseIssueReference(ref); err != nil {
+				return err
+			}
+		}
+		if cmd.Flags().Changed("assign-reviewers") {
+			assignReviewers, _ = cmd.Flags().GetBool("assign-reviewers")
+		}
+		deleteOnMerge := defaults.DeleteOnMerge
+		if cmd.Flags().Changed("delete-branch-on-merge") {
+			deleteOnMerge, _ = cmd.Flags().GetBool("delete-branch-on-merge")
+		}
+
+		stackOnly, _ := cmd.Flags().GetStringSlice("stack-only")
 
 		runner := &submitCmdRunner{
 			logger:         logger,
@@ -47,11 +161,26 @@ and creates or updates corresponding GitHub Pull Requests.
 			nonInteractive: nonInteractive,
 
 			// Populate config from flags
-			forcePush:   forcePush,
-			noPush:      noPush,
-			draft:       !noDraft,
-			submitTitle: title,
-			submitBody:  body,
+			forcePush:     forcePush,
+			noPush:        noPush,
+			pushOptions:   pushOptions,
+			draft:         draft,
+			submitTitle:   title,
+			submitBody:    body,
+			trailers:      trailers,
+			assignRevs:    assignReviewers,
+			verifyBody:    verifyBody,
+			stackNaming:   stackNameMode,
+			noSecretScan:  mustGetBool(cmd, "no-secret-scan"),
+			trackingIssue: trackingIssue,
+			deleteOnMerge: deleteOnMerge,
+			all:           mustGetBool(cmd, "all"),
+			scope: submitScope{
+				only:      mustGetBool(cmd, "only"),
+				downstack: mustGetBool(cmd, "downstack"),
+				until:     mustGetString(cmd, "until"),
+				branches:  stackOnly,
+			},
 			// --- TESTING FLAGS ---
 			testSubmitTitle:       mustGetString(cmd, "test-title"),
 			testSubmitBody:        mustGetString(cmd, "test-body"),
@@ -59,17 +188,31 @@ and creates or updates corresponding GitHub Pull Requests.
 		}
 
 		return runner.run(context.Background(), cmd)
-	},
+	}),
 }
 
 func init() {
 	rootCmd.AddCommand(submitCmd)
-	submitCmd.Flags().Bool("force", false, "Force push branches")
-	submitCmd.Flags().Bool("no-push", false, "Skip pushing branches to remote")
+	submitCmd.Flags().Bool("force", false, "Overwrite the remote branches even if someone else pushed to them since socle did")
+	submitCmd.Flags().Bool("no-push", false, "Skip pushing branches to remote (default from socle.submit.noPush)")
+	submitCmd.Flags().Bool("draft", false, "Create draft Pull Requests (default from socle.submit.draft, true if unset)")
 	submitCmd.Flags().Bool("no-draft", false, "Create non-draft Pull Requests")
+	submitCmd.Flags().StringArrayP("push-option", "o", nil, "Transmit the given string to the server as a push option (repeatable)")
 	submitCmd.Flags().String("title", "", "PR title to use when creating pull requests")
 	submitCmd.Flags().String("body", "", "PR body (markdown) to use when creating pull requests")
 	submitCmd.Flags().String("body-file", "", "Path to file containing PR body markdown")
+	submitCmd.Flags().Bool("assign-reviewers", false, "Request a reviewer from the socle.reviewers pool for PRs without one, rotating across the stack (default from socle.submit.assignReviewers)")
+	submitCmd.Flags().Bool("trailers", false, "Maintain Stacked-on and PR trailers in commit messages (default from socle.commitTrailers)")
+	submitCmd.Flags().Bool("verify-body", false, "Warn about PRs whose description lacks a section from socle.requiredSections")
+	submitCmd.Flags().Bool("no-secret-scan", false, "Push even if the branches appear to add secrets")
+	submitCmd.Flags().String("tracking-issue", "", "Link the stack's PRs to this issue (number or URL) and remember it (see 'so stack issue')")
+	submitCmd.Flags().Bool("delete-branch-on-merge", false, "Have new PRs delete their head branch once merged (default from socle.submit.deleteBranchOnMerge)")
+	submitCmd.Flags().Bool("all", false, "Push and update every branch, including those unchanged since the last submit")
+	submitCmd.Flags().Bool("only", false, "Push and update only the current branch")
+	submitCmd.Flags().Bool("downstack", false, "Push and update only the branches from the base up to the current one")
+	submitCmd.Flags().String("until", "", "Push and update only the branches from the base up to this one")
+	submitCmd.Flags().StringSlice("stack-only", nil, "Push and update only these branches of the stack (comma-separated or repeated)")
+	submitCmd.Flags().String("stack-name", "off", "Mark PRs with the stack's name: prefix, label, both or off (default from socle.submit.stackName)")
 
 	// --- TESTING FLAGS ---
 	submitCmd.Flags().String("test-title", "", "TESTING: Override PR title")
@@ -80,6 +223,8 @@ func init() {
 	_ = submitCmd.Flags().MarkHidden("test-edit-confirm")
 
 	submitCmd.MarkFlagsMutuallyExclusive("body", "body-file")
+	submitCmd.MarkFlagsMutuallyExclusive("draft", "no-draft")
+	submitCmd.MarkFlagsMutuallyExclusive("only", "downstack", "until", "stack-only")
 }
 
 // mustGetString is a helper that panics if the flag doesn't exist (programming error).
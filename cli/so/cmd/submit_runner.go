@@ -2,20 +2,38 @@ package cmd
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/benekuehn/socle/cli/so/internal/events"
 	"github.com/benekuehn/socle/cli/so/internal/gh"
 	"github.com/benekuehn/socle/cli/so/internal/git"
-	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/benekuehn/socle/cli/so/internal/interrupt"
+	"github.com/benekuehn/socle/cli/so/internal/profile"
+	"github.com/benekuehn/socle/cli/so/internal/secrets"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/workpool"
 	"github.com/spf13/cobra"
 )
 
+// stackCommentMarker identifies socle's stack overview comment on a PR.
+const stackCommentMarker = "<!-- socle-stack-overview -->"
+
 type submittedPrInfo struct {
-	Number int
+	Number  int
+	Health  string // Review/CI emoji shown next to the PR in the stack comment
+	Summary string // First line of the branch's description
+	Mirror  string // Paired PR on the other base, e.g. "#201 (release/1.2)"
+	Merged  bool   // Collapsed into one line on deep stacks
 }
 
 type submitCmdRunner struct {
@@ -24,14 +42,25 @@ type submitCmdRunner struct {
 	ghClient       gh.ClientInterface
 	stdout         io.Writer
 	stderr         io.Writer
+	events         *events.Bus // Progress output; text on stdout/stderr unless set
 	nonInteractive bool
 
 	// Configuration from flags
-	forcePush   bool
-	noPush      bool
-	draft       bool
-	submitTitle string
-	submitBody  string
+	forcePush     bool
+	noPush        bool
+	pushOptions   []string
+	draft         bool
+	submitTitle   string
+	submitBody    string
+	trailers      bool
+	assignRevs    bool
+	verifyBody    bool
+	stackNaming   string // off, prefix, label or both (--stack-name)
+	noSecretScan  bool   // --no-secret-scan
+	trackingIssue int    // --tracking-issue, else the stack's stored one (see loadTrackingIssue)
+	deleteOnMerge bool   // --delete-branch-on-merge
+	all           bool   // --all: push and update branches unchanged since the last submit too
+	scope         submitScope
 
 	// --- TESTING FLAGS --- (passed via options if needed, or kept if strictly for cmd level tests)
 	testSubmitTitle       string
@@ -41,9 +70,15 @@ type submitCmdRunner struct {
 	// Internal state
 	owner        string
 	repoName     string
+	repoURL      string // Web URL of the repository, for PR links in trailers
 	remoteName   string
+	pushConfig   git.PushConfig
 	prInfoMap    map[string]submittedPrInfo
 	submitErrors []error
+	rewritten    map[string]bool // Branches whose commits were rewritten for trailers
+	selected     map[string]bool // Branches a partial submit pushes and updates; nil for all
+	stackName    string          // Set with 'so stack name'; empty unless stackNaming uses it
+	repoDeletes  *bool           // The repository's delete-branch-on-merge setting, once looked up
 
 	// --- Dependencies (for testing) ---
 	GhClient gh.ClientInterface
@@ -51,6 +86,9 @@ type submitCmdRunner struct {
 
 func (r *submitCmdRunner) run(ctx context.Context, cmd *cobra.Command) error {
 	r.logger.Debug("Starting submit command execution")
+	if r.events == nil {
+		r.events = events.New(events.NewTextRenderer(r.stdout, r.stderr))
+	}
 
 	// --- Phase 1: Preparation ---
 	fullStack, allParents, err := r.prepareSubmit(ctx)
@@ -69,15 +107,53 @@ func (r *submitCmdRunner) run(ctx context.Context, cmd *cobra.Command) error {
 	r.prInfoMap = make(map[string]submittedPrInfo)
 	r.submitErrors = make([]error, 0)
 
+	// --- Phase 1a: Partial Submit (optional) ---
+	if err := r.resolveScope(fullStack); err != nil {
+		return err
+	}
+
+	// --- Phase 1b: Commit Trailers (optional) ---
+	if commitTrailersEnabled(r.trailers, r.logger) {
+		r.updateCommitTrailers(r.fromLowestSelected(fullStack))
+	}
+
+	// --- Phase 1c: Secret Scan (only what is about to be pushed) ---
+	if !r.noPush && !r.noSecretScan {
+		if err := r.scanForSecrets(fullStack); err != nil {
+			return err
+		}
+	}
+
+	// --- Phase 1d: Mirror Divergence ---
+	r.warnDivergedMirrors(fullStack)
+
 	// --- Phase 2: Process Stack (Submit PRs) ---
 	if err := r.processStack(ctx, cmd, fullStack, allParents); err != nil {
+		if socleerr.KindOf(err) == socleerr.Interrupted {
+			return err
+		}
 		// Handle fatal errors during stack processing (push failed, submit action failed fatally, user cancelled)
 		return fmt.Errorf("failed processing stack: %w", err) // Return immediately on fatal error
 	}
 
+	// --- Phase 2b: Reviewer Rotation (optional) ---
+	if r.assignRevs {
+		r.assignReviewers(fullStack)
+	}
+
 	// --- Phase 3: Update Stack Comments ---
 	r.updateStackComments(ctx, fullStack)
 
+	// --- Phase 3a: Tracking Issue (optional) ---
+	if r.trackingIssue > 0 {
+		r.updateTrackingIssue(fullStack)
+	}
+
+	// --- Phase 3b: Required PR Sections (optional) ---
+	if r.verifyBody {
+		r.verifyPRBodies(fullStack)
+	}
+
 	// --- Phase 4: Final Summary ---
 	r.summarizeResults()
 
@@ -95,20 +171,31 @@ var errStartupHandled = errors.New("startup handled (e.g., help shown)")
 func (r *submitCmdRunner) prepareSubmit(ctx context.Context) ([]string, map[string]string, error) {
 	r.logger.Debug("Preparing submit operation")
 
-	r.remoteName = "origin" // TODO: Make configurable?
+	r.remoteName = git.GetRemoteName()
 	remoteURL, err := git.GetRemoteURL(r.remoteName)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot get remote URL for '%s': %w", r.remoteName, err)
 	}
 	r.logger.Debug("Found remote URL", "remote", r.remoteName, "url", remoteURL)
 
-	r.owner, r.repoName, err = git.ParseOwnerAndRepo(remoteURL)
+	remote, err := git.ParseRemoteURL(remoteURL)
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot parse owner/repo from remote '%s' URL '%s': %w", r.remoteName, remoteURL, err)
 	}
+	r.owner, r.repoName, r.repoURL = remote.Owner, remote.Repo, remote.WebURL()
 	r.logger.Debug("Operating on repository", "owner", r.owner, "repoName", r.repoName)
 
-	r.ghClient, err = gh.CreateClient(ctx, r.owner, r.repoName)
+	if !r.noPush {
+		pushConfig, err := git.LoadPushConfig()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read push configuration: %w", err)
+		}
+		r.pushConfig = pushConfig.WithOptions(r.pushOptions...)
+	}
+
+	// A request that was sent is answered even after Ctrl+C, so a PR is never
+	// left half-updated; ctx only keeps further steps from starting.
+	r.ghClient, err = gh.CreateClient(context.WithoutCancel(ctx), r.owner, r.repoName)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create GitHub client: %w", err)
 	}
@@ -134,7 +221,7 @@ func (r *submitCmdRunner) prepareSubmit(ctx context.Context) ([]string, map[stri
 	r.logger.Debug("Full ordered stack identified for processing", "fullStack", fullStack)
 
 	if len(fullStack) <= 1 {
-		_, _ = fmt.Fprintln(r.stdout, "Current branch is the base or directly on base. Nothing to submit.")
+		r.events.Emit(events.Info{Message: "Current branch is the base or directly on base. Nothing to submit."})
 		return nil, nil, errTrivialStack
 	}
 
@@ -145,7 +232,10 @@ func (r *submitCmdRunner) prepareSubmit(ctx context.Context) ([]string, map[stri
 // It populates r.prInfoMap and r.submitErrors (for non-fatal internal errors).
 // Returns a fatal error if a push fails, submit action fails critically, or user cancels.
 func (r *submitCmdRunner) processStack(ctx context.Context, cmd *cobra.Command, fullStack []string, allParents map[string]string) error {
-	_, _ = fmt.Fprintln(r.stdout, "Processing stack...")
+	r.events.Emit(events.StackStarted{Base: fullStack[0], Branches: fullStack[1:]})
+	r.loadStackName(fullStack[1])
+	r.loadTrackingIssue(fullStack[1:])
+	wipBranch := "" // Lowest WIP branch seen; it and everything above it stay unpublished
 	for i := 1; i < len(fullStack); i++ {
 		branch := fullStack[i]
 		parent, ok := allParents[branch]
@@ -158,13 +248,45 @@ func (r *submitCmdRunner) processStack(ctx context.Context, cmd *cobra.Command,
 			continue                                      // Skip this branch
 		}
 
-		_, _ = fmt.Fprintf(r.stdout, "\nProcessing branch: %s (parent: %s)\n", branch, parent)
+		if !r.inScope(branch) {
+			// Not submitted, but a WIP mark still holds back what is above it
+			if wipBranch == "" {
+				if wip, err := git.IsBranchWIP(branch); wip || err != nil {
+					wipBranch = branch
+				}
+			}
+			continue
+		}
+
+		if err := interrupt.Check(ctx, "run 'so submit' again to submit the remaining branches"); err != nil {
+			return err
+		}
+		r.events.Emit(events.BranchStarted{Branch: branch, Parent: parent})
+
+		if wipBranch == "" {
+			wip, err := git.IsBranchWIP(branch)
+			if err != nil {
+				// Without knowing, the branch may be one not meant to go out yet.
+				wipBranch = branch
+				err = fmt.Errorf("could not read the WIP mark of '%s', not pushing it or the branches above: %w", branch, err)
+				r.events.Emit(events.BranchSkipped{Branch: branch, Reason: err.Error()})
+				r.submitErrors = append(r.submitErrors, err)
+				continue
+			} else if wip {
+				wipBranch = branch
+				r.events.Emit(events.BranchSkipped{Branch: branch, Reason: fmt.Sprintf("'%s' is marked WIP (run 'so wip %s' to unmark).", branch, branch)})
+				continue
+			}
+		} else {
+			r.events.Emit(events.BranchSkipped{Branch: branch, Reason: fmt.Sprintf("stacked on WIP branch '%s'.", wipBranch)})
+			continue
+		}
 
 		prInfoResult, err := r.submitBranch(ctx, cmd, branch, parent)
 		if err != nil {
 			// submitBranch returns fatal errors (push fail, action fail) or ErrSubmitCancelled
 			if errors.Is(err, gh.ErrSubmitCancelled) {
-				_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render("Submit operation cancelled."))
+				r.events.Emit(events.Cancelled{Operation: "submit"})
 				return err // Return cancellation error to halt processing
 			}
 			// Otherwise, it's a fatal error from push or action
@@ -173,6 +295,8 @@ func (r *submitCmdRunner) processStack(ctx context.Context, cmd *cobra.Command,
 
 		if prInfoResult != nil {
 			r.prInfoMap[branch] = *prInfoResult
+			r.events.Emit(events.PRSubmitted{Branch: branch, Number: prInfoResult.Number})
+			r.labelWithStackName(branch, prInfoResult.Number)
 			r.logger.Debug("Stored PR info from submitBranch", "branch", branch, "prInfo", *prInfoResult)
 		} else {
 			r.logger.Debug("No PR info returned from submitBranch (skipped or handled internally).", "branch", branch)
@@ -181,50 +305,507 @@ func (r *submitCmdRunner) processStack(ctx context.Context, cmd *cobra.Command,
 	return nil
 }
 
-// updateStackComments iterates through the processed PRs and updates their comments.
+// submitScope narrows a submit to part of the stack; at most one field is set.
+type submitScope struct {
+	only      bool     // --only: just the current branch
+	downstack bool     // --downstack: the base up to the current branch
+	until     string   // --until: the base up to this branch
+	branches  []string // --stack-only: just these branches
+}
+
+// resolveScope works out which branches of fullStack a partial submit
+// pushes and updates. Every selected branch must sit on the base, on another
+// selected branch or on a branch whose PR already exists, so its PR has a
+// base to point at.
+func (r *submitCmdRunner) resolveScope(fullStack []string) error {
+	names := r.scope.branches
+	if r.scope.only || r.scope.downstack || r.scope.until != "" {
+		last := r.scope.until
+		if last == "" {
+			current, err := git.GetCurrentBranch()
+			if err != nil {
+				return err
+			}
+			last = current
+		}
+		idx := slices.Index(fullStack, last)
+		if idx < 1 {
+			return fmt.Errorf("'%s' is not a branch of the current stack (%s)", last, strings.Join(fullStack[1:], ", "))
+		}
+		names = fullStack[1 : idx+1]
+		if r.scope.only {
+			names = fullStack[idx : idx+1]
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	r.selected = make(map[string]bool, len(names))
+	for _, name := range names {
+		if slices.Index(fullStack, name) < 1 {
+			return fmt.Errorf("'%s' is not a branch of the current stack (%s)", name, strings.Join(fullStack[1:], ", "))
+		}
+		r.selected[name] = true
+	}
+	var submitted []string
+	for i := 1; i < len(fullStack); i++ {
+		branch, parent := fullStack[i], fullStack[i-1]
+		if !r.selected[branch] {
+			continue
+		}
+		submitted = append(submitted, branch)
+		if i == 1 || r.selected[parent] || git.GetPRBaseOverride(branch) != "" {
+			continue
+		}
+		if number, _ := git.GetStoredPRNumber(parent); number == 0 {
+			return fmt.Errorf("'%s' is stacked on '%s', which has no PR yet. Submit '%s' as well, or first on its own", branch, parent, parent)
+		}
+	}
+	r.events.Emit(events.Info{Message: fmt.Sprintf("Submitting only %s; the rest of the stack is left as it is.", strings.Join(submitted, ", "))})
+	return nil
+}
+
+// inScope reports whether branch is pushed and updated by this submit.
+func (r *submitCmdRunner) inScope(branch string) bool {
+	return r.selected == nil || r.selected[branch]
+}
+
+// fromLowestSelected returns fullStack from the parent of the lowest selected
+// branch up, so rewriting commit trailers never touches the branches below a
+// partial submit, which are not pushed.
+func (r *submitCmdRunner) fromLowestSelected(fullStack []string) []string {
+	for i := 1; i < len(fullStack); i++ {
+		if r.inScope(fullStack[i]) {
+			return fullStack[i-1:]
+		}
+	}
+	return fullStack
+}
+
+// assignReviewers requests one reviewer from the pool for every submitted PR
+// that has none yet, rotating across the stack. Failures become warnings:
+// the PRs are up already, and 'so pr reviewers --rebalance' can retry.
+func (r *submitCmdRunner) assignReviewers(fullStack []string) {
+	warn := func(err error) { r.events.Emit(events.Warning{Message: err.Error()}) }
+	cfg, err := loadReviewerConfig()
+	if err != nil {
+		warn(err)
+		return
+	}
+	if len(cfg.pool) == 0 {
+		warn(fmt.Errorf("--assign-reviewers: no reviewer pool configured (socle.reviewers)"))
+		return
+	}
+
+	var targets []reviewerTarget
+	for i := 1; i < len(fullStack); i++ {
+		branch := fullStack[i]
+		info, submitted := r.prInfoMap[branch]
+		if !submitted {
+			continue
+		}
+		pr, err := r.ghClient.GetPullRequest(info.Number)
+		if err != nil {
+			warn(fmt.Errorf("failed to fetch PR #%d for reviewer assignment: %w", info.Number, err))
+			continue
+		}
+		if len(pr.RequestedReviewers) > 0 {
+			continue // Leave existing review requests alone; use 'so pr reviewers --rebalance' to redistribute
+		}
+		targets = append(targets, reviewerTarget{branch: branch, parent: fullStack[i-1], prNumber: info.Number, author: pr.GetUser().GetLogin()})
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	var owners func(reviewerTarget) []string
+	if cfg.strategy == reviewerStrategyCodeowners {
+		if owners, err = codeownersLookup(); err != nil {
+			warn(err)
+		}
+	}
+	plan, cursor := planReviewers(targets, cfg, owners)
+
+	r.events.Emit(events.Step{Title: "Requesting reviewers..."})
+	for _, err := range applyReviewerPlan(r.ghClient, targets, nil, plan, cfg.pool, r.stdout) {
+		warn(err)
+	}
+	if err := saveReviewerCursor(cursor); err != nil {
+		r.logger.Debug("Failed to save reviewer cursor", "error", err)
+	}
+}
+
+// scanForSecrets checks the lines each branch adds for credentials before
+// anything is pushed, using the built-in rules and socle.secretScanCommand.
+// Branches from the lowest WIP branch up are not pushed, so not scanned.
+func (r *submitCmdRunner) scanForSecrets(fullStack []string) error {
+	enabled, err := git.GetSocleConfigBool("socle.secretScan", true)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		r.logger.Debug("Secret scan disabled by socle.secretScan")
+		return nil
+	}
+	command, err := git.GetSocleConfig("socle.secretScanCommand")
+	if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
+		return err
+	}
+	command = strings.TrimSpace(command)
+
+	var blocked []string
+	for i := 1; i < len(fullStack); i++ {
+		branch, parent := fullStack[i], fullStack[i-1]
+		if wip, err := git.IsBranchWIP(branch); wip || err != nil {
+			break // Not pushed, see processStack
+		}
+		if !r.inScope(branch) {
+			continue
+		}
+		diff, err := git.GetAddedLines(parent, branch)
+		if err != nil {
+			return err
+		}
+		found := events.SecretsFound{Branch: branch}
+		for _, f := range secrets.ScanDiff(diff, secrets.DefaultRules) {
+			found.Findings = append(found.Findings, events.SecretFinding{File: f.File, Line: f.Line, Rule: f.Rule, Match: f.Redacted()})
+		}
+		if command != "" {
+			report, flagged, err := secrets.RunHook(command, diff, branch, parent)
+			if err != nil {
+				return err
+			}
+			if flagged {
+				found.Report = report
+				if found.Report == "" {
+					found.Report = "socle.secretScanCommand reported a problem"
+				}
+			}
+		}
+		if len(found.Findings) > 0 || found.Report != "" {
+			r.events.Emit(found)
+			blocked = append(blocked, branch)
+		}
+	}
+	if len(blocked) > 0 {
+		return fmt.Errorf("possible secrets in %s; nothing was pushed. Remove them from the branch history, or rerun with --no-secret-scan if they are false positives", strings.Join(blocked, ", "))
+	}
+	return nil
+}
+
+// loadStackName reads the stack's name when --stack-name asks for it.
+func (r *submitCmdRunner) loadStackName(bottom string) {
+	if r.stackNaming == "" || r.stackNaming == "off" {
+		return
+	}
+	name, err := git.GetStackName(bottom)
+	if err != nil {
+		r.submitErrors = append(r.submitErrors, fmt.Errorf("failed to read the stack name: %w", err))
+		return
+	}
+	if name == "" {
+		r.logger.Debug("Stack has no name, not marking PRs", "bottom", bottom)
+	}
+	r.stackName = name
+}
+
+// loadTrackingIssue settles the stack's tracking issue: --tracking-issue if
+// given, else the one stored on the stack. It is written to every branch so
+// branches added since it was set carry it too.
+func (r *submitCmdRunner) loadTrackingIssue(branches []string) {
+	if r.trackingIssue == 0 {
+		issue, err := git.GetTrackingIssue(branches)
+		if err != nil {
+			r.submitErrors = append(r.submitErrors, fmt.Errorf("failed to read the tracking issue: %w", err))
+			return
+		}
+		r.trackingIssue = issue
+	}
+	if r.trackingIssue == 0 {
+		return
+	}
+	if err := git.SetTrackingIssue(branches, r.trackingIssue); err != nil {
+		r.submitErrors = append(r.submitErrors, fmt.Errorf("failed to save tracking issue #%d: %w", r.trackingIssue, err))
+	}
+}
+
+// trackingIssueMarker identifies socle's task list comment on a tracking issue.
+const trackingIssueMarker = "<!-- socle-tracking-issue -->"
+
+// updateTrackingIssue writes the stack's PRs as a task list into a comment on
+// the tracking issue.
+func (r *submitCmdRunner) updateTrackingIssue(fullStack []string) {
+	r.events.Emit(events.Step{Title: fmt.Sprintf("Updating tracking issue #%d...", r.trackingIssue)})
+	err := gh.EnsureIssueComment(r.ghClient, r.trackingIssue, trackingIssueMarker, func(previous string) string {
+		return renderTrackingIssueBody(fullStack, r.prInfoMap, previous, func(number int) (string, error) {
+			status, _, err := r.ghClient.GetPullRequestStatus(number)
+			return status, err
+		})
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to update tracking issue #%d: %w", r.trackingIssue, err)
+		r.events.Emit(events.Warning{Message: err.Error()})
+		r.submitErrors = append(r.submitErrors, err)
+	}
+}
+
+var trackingTaskRegex = regexp.MustCompile(`(?m)^- \[[ x]\] #(\d+)(.*)$`)
+
+// renderTrackingIssueBody lists the stack's PRs bottom first as a task list,
+// ticking merged ones. PRs on the previous list that are no longer in the
+// stack are looked up with prStatus: merged ones stay at the top, ticked, and
+// the others are dropped. A PR whose status cannot be read keeps its line.
+func renderTrackingIssueBody(stack []string, prInfoMap map[string]submittedPrInfo, previous string, prStatus func(number int) (string, error)) string {
+	inStack := make(map[string]bool)
+	var current []string
+	merged, total := 0, 0
+	for _, branch := range stack[1:] {
+		info, ok := prInfoMap[branch]
+		if !ok {
+			continue
+		}
+		number := strconv.Itoa(info.Number)
+		inStack[number] = true
+		box := " "
+		if info.Merged {
+			box = "x"
+			merged++
+		}
+		total++
+		current = append(current, fmt.Sprintf("- [%s] #%s `%s`", box, number, branch))
+	}
+
+	var lines []string
+	for _, m := range trackingTaskRegex.FindAllStringSubmatch(previous, -1) {
+		if inStack[m[1]] {
+			continue
+		}
+		inStack[m[1]] = true
+		number, _ := strconv.Atoi(m[1])
+		status, err := prStatus(number)
+		switch {
+		case err != nil:
+			lines = append(lines, m[0])
+			if strings.HasPrefix(m[0], "- [x]") {
+				merged++
+			}
+		case status == gh.PRStatusMerged:
+			lines = append(lines, fmt.Sprintf("- [x] #%s%s", m[1], m[2]))
+			merged++
+		default:
+			continue
+		}
+		total++
+	}
+	lines = append(lines, current...)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Stack progress:** %d of %d merged\n\n", merged, total)
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\nUpdated by [Socle](https://github.com/benekuehn/socle) on every submit. " + trackingIssueMarker + "\n")
+	return b.String()
+}
+
+// labelWithStackName adds the stack:<name> label to a submitted PR.
+func (r *submitCmdRunner) labelWithStackName(branch string, prNumber int) {
+	if r.stackName == "" || (r.stackNaming != "label" && r.stackNaming != "both") {
+		return
+	}
+	label := "stack:" + r.stackName
+	if err := r.ghClient.AddLabels(prNumber, []string{label}); err != nil {
+		err = fmt.Errorf("failed to add label '%s' to PR #%d (branch '%s'): %w", label, prNumber, branch, err)
+		r.events.Emit(events.Warning{Branch: branch, Message: err.Error()})
+		r.submitErrors = append(r.submitErrors, err)
+	}
+}
+
+// updateCommitTrailers rewrites Stacked-on/PR trailers before anything is pushed.
+// Problems are reported as warnings; submit continues with the commits as they are.
+func (r *submitCmdRunner) updateCommitTrailers(fullStack []string) {
+	hasChanges, err := git.HasUncommittedChanges()
+	if err != nil || hasChanges {
+		r.events.Emit(events.Warning{Message: "uncommitted changes detected; skipping commit trailer update."})
+		return
+	}
+
+	rewritten, err := applyCommitTrailers(fullStack, r.repoURL, r.logger)
+	r.rewritten = make(map[string]bool, len(rewritten))
+	for _, branch := range rewritten {
+		r.rewritten[branch] = true
+	}
+	if err != nil {
+		if errors.Is(err, errTrailersNeedRestack) {
+			r.events.Emit(events.Warning{Message: fmt.Sprintf("%v. Run 'so restack' to update commit trailers.", err)})
+		} else {
+			r.events.Emit(events.Warning{Message: fmt.Sprintf("failed to update commit trailers: %v", err)})
+		}
+		return
+	}
+	if len(rewritten) > 0 {
+		r.events.Emit(events.TrailersUpdated{Branches: rewritten})
+	}
+}
+
+// updateStackComments writes the stack comment of every processed PR. The
+// comments of the stack's other PRs are rewritten too when the stack's shape
+// changed since they were last written, so their position markers stay right
+// after branches were added, removed or reordered.
 // Errors encountered here are collected in r.submitErrors.
 func (r *submitCmdRunner) updateStackComments(ctx context.Context, fullStack []string) {
 	r.logger.Debug("Updating PR comments with stack overview")
-	stackCommentMarker := "<!-- socle-stack-overview -->"
 
 	if len(r.prInfoMap) == 0 {
-		_, _ = fmt.Fprintln(r.stdout, "\nNo pull requests were found or created/updated. Skipping comment updates.")
+		r.events.Emit(events.Step{Title: "No pull requests were found or created/updated. Skipping comment updates."})
 		return
 	}
 
-	_, _ = fmt.Fprintln(r.stdout, "\nUpdating PR comments with stack overview...")
-	for i := 1; i < len(fullStack); i++ { // Iterate through stack branches again
+	r.events.Emit(events.Step{Title: "Updating PR comments with stack overview..."})
+	commentInfo := maps.Clone(r.prInfoMap)
+	for _, branch := range fullStack[1:] {
+		if _, processed := commentInfo[branch]; processed {
+			continue
+		}
+		if number, err := git.GetStoredPRNumber(branch); err == nil && number > 0 {
+			commentInfo[branch] = submittedPrInfo{Number: number}
+		}
+	}
+	layout := readStackCommentLayout(r.logger)
+	shape := stackShape(fullStack, commentInfo, layout)
+	write := make(map[string]bool, len(commentInfo))
+	for branch := range commentInfo {
+		_, processed := r.prInfoMap[branch]
+		write[branch] = processed || git.GetCommentShape(branch) != shape
+	}
+
+	fillStackHealth(r.ghClient, commentInfo, r.logger)
+	fillDescriptionSummaries(commentInfo, r.logger)
+	fillMirrorLinks(fullStack, commentInfo, r.logger)
+	errs := ensureStackComments(ctx, r.ghClient, fullStack, commentInfo, layout, write)
+	for i := 1; i < len(fullStack); i++ {
 		branch := fullStack[i]
-		prInfo, ok := r.prInfoMap[branch] // Check map for this specific branch
-		if !ok {
-			r.logger.Debug("Skipping comment update for branch: No PR info was stored.", "branch", branch)
+		prInfo, ok := commentInfo[branch]
+		if !ok || !write[branch] {
+			r.logger.Debug("Skipping comment update for branch: no PR or its comment is current.", "branch", branch)
 			continue
 		}
+		if errs[i] != nil {
+			wrappedErr := fmt.Errorf("error processing stack comment for PR #%d (branch '%s'): %w", prInfo.Number, branch, errs[i])
+			r.events.Emit(events.Warning{Branch: branch, Message: wrappedErr.Error()})
+			r.submitErrors = append(r.submitErrors, wrappedErr)
+			continue // Continue processing comments for other PRs
+		}
+		recordCommentShape(branch, shape, r.logger)
+		r.events.Emit(events.CommentUpdated{Branch: branch, Number: prInfo.Number})
+	}
+}
+
+// commentWorkers bounds how many stack comments are written at once, to stay
+// clear of GitHub's secondary rate limits on content-creating requests.
+const commentWorkers = 4
+
+// ensureStackComments renders the stack comment of every branch in prInfoMap
+// that write selects (all of them for a nil write) and writes them
+// commentWorkers at a time, into the PR description as well where it holds
+// a stack overview. The returned errors are indexed like stack; nil
+// for branches without a PR. Comments not started before ctx is cancelled
+// are left as they were and get ctx's error.
+func ensureStackComments(ctx context.Context, ghClient gh.ClientInterface, stack []string, prInfoMap map[string]submittedPrInfo, layout stackCommentLayout, write map[string]bool) []error {
+	errs := make([]error, len(stack))
+	var todo []int // Indexes into stack
+	for i := 1; i < len(stack); i++ {
+		branch := stack[i]
+		if _, ok := prInfoMap[branch]; ok && (write == nil || write[branch]) {
+			todo = append(todo, i)
+		}
+	}
+	started := make([]bool, len(todo))
+	err := workpool.Run(ctx, len(todo), commentWorkers, func(j int) {
+		i := todo[j]
+		started[j] = true
+		branch, prInfo := stack[i], prInfoMap[stack[i]]
+		body := renderStackCommentBody(stack, branch, stackCommentMarker, prInfoMap, layout)
+		errs[i] = gh.EnsureStackComment(ctx, ghClient, branch, prInfo.Number, body, stackCommentMarker)
+		if errs[i] == nil && git.GetBodyOverview(branch) {
+			errs[i] = gh.EnsureBodyOverview(ghClient, prInfo.Number, body)
+		}
+	})
+	if err != nil {
+		for j, i := range todo {
+			if !started[j] {
+				errs[i] = err
+			}
+		}
+	}
+	return errs
+}
+
+// stackShape fingerprints what every stack comment of the stack shows the
+// same way: the branches in order, their PRs and the layout.
+func stackShape(stack []string, prInfoMap map[string]submittedPrInfo, layout stackCommentLayout) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d %s\n", layout.neighbors, layout.verbosity)
+	for _, branch := range stack {
+		_, _ = fmt.Fprintf(h, "%s #%d\n", branch, prInfoMap[branch].Number)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
 
-		commentBody := renderStackCommentBody(fullStack, branch, stackCommentMarker, r.prInfoMap)
+// recordCommentShape remembers the shape a branch's comment was written for.
+// Failing only costs a rewrite on the next submit.
+func recordCommentShape(branch, shape string, logger *slog.Logger) {
+	if err := git.SetCommentShape(branch, shape); err != nil {
+		logger.Debug("Could not record stack comment shape", "branch", branch, "error", err)
+	}
+}
+
+// verifyPRBodies checks every submitted PR for the socle.requiredSections
+// headings. Incomplete PRs are reported as warnings; the PRs stay submitted.
+func (r *submitCmdRunner) verifyPRBodies(fullStack []string) {
+	required, err := loadRequiredSections()
+	if err != nil {
+		r.submitErrors = append(r.submitErrors, err)
+		return
+	}
+	if len(required) == 0 {
+		r.submitErrors = append(r.submitErrors, fmt.Errorf("--verify-body: no required sections configured (socle.requiredSections)"))
+		return
+	}
 
-		err := gh.EnsureStackComment(ctx, r.ghClient, branch, prInfo.Number, commentBody, stackCommentMarker)
+	r.events.Emit(events.Step{Title: "Checking PR descriptions for required sections..."})
+	var incomplete []string
+	for _, branch := range fullStack[1:] {
+		info, submitted := r.prInfoMap[branch]
+		if !submitted {
+			continue
+		}
+		missing, err := checkPRBody(r.ghClient, info.Number, required)
 		if err != nil {
-			// TODO: Differentiate critical errors vs warnings?
-			wrappedErr := fmt.Errorf("error processing stack comment for PR #%d (branch '%s'): %w", prInfo.Number, branch, err)
-			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render("  "+wrappedErr.Error())) // Print immediate feedback
-			r.submitErrors = append(r.submitErrors, wrappedErr)
-			continue // Continue processing comments for other PRs
-		} else {
-			_, _ = fmt.Fprintf(r.stdout, "  Stack comment processed for PR #%d.\n", prInfo.Number)
+			r.submitErrors = append(r.submitErrors, fmt.Errorf("failed to check description of PR #%d: %w", info.Number, err))
+			continue
+		}
+		if len(missing) > 0 {
+			incomplete = append(incomplete, fmt.Sprintf("#%d (%s) is missing %s", info.Number, branch, strings.Join(missing, ", ")))
 		}
 	}
+	if len(incomplete) == 0 {
+		r.events.Emit(events.Success{Message: "All PR descriptions contain the required sections."})
+		return
+	}
+	for _, problem := range incomplete {
+		r.submitErrors = append(r.submitErrors, fmt.Errorf("PR %s", problem))
+	}
 }
 
-// summarizeResults prints the final status and any collected errors.
+// summarizeResults reports the final status and any collected errors.
 func (r *submitCmdRunner) summarizeResults() {
-	_, _ = fmt.Fprintln(r.stdout, "\nSubmit process finished.")
-	if len(r.submitErrors) > 0 {
-		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render("\nEncountered warnings/errors during submit:"))
-		for _, submitErr := range r.submitErrors {
-			_, _ = fmt.Fprintln(r.stderr, " - "+submitErr.Error())
-		}
+	problems := make([]string, 0, len(r.submitErrors))
+	for _, submitErr := range r.submitErrors {
+		problems = append(problems, submitErr.Error())
 	}
+	r.events.Emit(events.Finished{Operation: "submit", Problems: problems})
 }
 
 // submitBranch now orchestrates push and calls the main action.
@@ -243,17 +824,36 @@ func (r *submitCmdRunner) submitBranch( // Make it a method of submitCmdRunner
 
 	r.logger.Debug("submitBranch: Orchestrating action", "branch", branch, "parent", parent)
 
+	// 0. Skip branches that are already published as they are
+	state := git.SubmittedState{PRBase: git.PRBaseOf(branch, parent)}
+	if doPush {
+		tip, err := git.GetCurrentBranchCommit(branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the tip of '%s': %w", branch, err)
+		}
+		state.Tip = tip
+		if number := r.unchangedSinceSubmit(branch, state); number > 0 {
+			r.events.Emit(events.PushSkipped{Branch: branch, Reason: fmt.Sprintf("unchanged since the last submit of PR #%d", number)})
+			return &submittedPrInfo{Number: number}, nil
+		}
+	}
+
 	// 1. Push Branch (if enabled)
 	if doPush {
 		r.logger.Debug("Pushing branch", "branch", branch, "remote", r.remoteName, "force", forcePush)
-		err := git.PushBranch(branch, r.remoteName, forcePush)
+		var err error
+		if forcePush {
+			err = git.PushBranch(branch, r.remoteName, true, r.pushConfig)
+		} else {
+			err = r.pushWithLease(branch)
+		}
 		if err != nil {
 			// Treat push failure as fatal
 			return nil, fmt.Errorf("failed to push branch '%s': %w", branch, err)
 		}
-		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("  Branch pushed successfully."))
+		r.events.Emit(events.BranchPushed{Branch: branch, Remote: r.remoteName})
 	} else {
-		_, _ = fmt.Fprintln(r.stdout, "  Skipping push (--no-push).")
+		r.events.Emit(events.PushSkipped{Branch: branch, Reason: "--no-push"})
 	}
 
 	// 2. Call the SubmitBranch action to handle PR logic
@@ -267,16 +867,47 @@ func (r *submitCmdRunner) submitBranch( // Make it a method of submitCmdRunner
 		TestSubmitEditConfirm: r.testSubmitEditConfirm,
 		NonInteractive:        r.nonInteractive,
 	}
+	if prBase := git.PRBaseOf(branch, parent); prBase != parent {
+		opts.PRBase = prBase
+	}
+	if r.stackName != "" && (r.stackNaming == "prefix" || r.stackNaming == "both") {
+		opts.TitlePrefix = "[" + r.stackName + "] "
+	}
+	opts.TrackingIssue = r.trackingIssue
+	if description, err := git.GetBranchDescription(branch); err != nil {
+		r.events.Emit(events.Warning{Branch: branch, Message: fmt.Sprintf("could not read the description of '%s': %v", branch, err)})
+	} else {
+		opts.Description = description
+	}
 	r.logger.Debug("Calling gh.SubmitBranch", "branch", branch, "options", opts)
 
+	storedPR, _ := git.GetStoredPRNumber(branch)
 	finalPR, err := gh.SubmitBranch(ctx, r.ghClient, cmd, branch, parent, opts)
 	if err != nil {
 		// Error could be fatal API error or ErrSubmitCancelled from action
 		return nil, err // Propagate error up (already wrapped by SubmitBranch if needed)
 	}
+	// A status another command cached, e.g. "closed" before the PR was
+	// reopened, would make 'so sync' treat the open PR as done.
+	if finalPR != nil && finalPR.GetState() != "closed" {
+		gh.ForgetPullRequestStatus(r.owner+"/"+r.repoName, finalPR.GetNumber())
+	}
+	if finalPR != nil && storedPR == 0 && r.deleteOnMerge {
+		r.requestDeleteOnMerge(branch)
+	}
+	if finalPR != nil && doPush {
+		if err := git.SetSubmittedState(branch, state); err != nil {
+			r.logger.Debug("Failed to record submitted state", "branch", branch, "error", err)
+		}
+	}
 
 	// 3. Return PR info if available
 	if finalPR != nil {
+		if present := gh.HasBodyOverview(finalPR.GetBody()); present != git.GetBodyOverview(branch) {
+			if err := git.SetBodyOverview(branch, present); err != nil {
+				r.logger.Debug("Failed to record the stack overview of the PR description", "branch", branch, "error", err)
+			}
+		}
 		return &submittedPrInfo{
 			Number: finalPR.GetNumber(),
 		}, nil
@@ -287,32 +918,231 @@ func (r *submitCmdRunner) submitBranch( // Make it a method of submitCmdRunner
 	return nil, nil
 }
 
-func renderStackCommentBody(stack []string, currentBranch string, stackCommentMarker string, prInfoMap map[string]submittedPrInfo) string {
-	var sb strings.Builder
-	sb.WriteString("**Stack Overview:**\n\n")
-	// Iterate through stack in reverse order to show most recent branch first
-	for i := len(stack) - 1; i >= 0; i-- {
-		branchName := stack[i]
-		if i == 0 {
-			sb.WriteString(fmt.Sprintf("* `%s` (base)\n", branchName))
+// pushWithLease pushes branch, replacing the remote branch only while it is
+// where socle expects it (see git.ExpectedRemoteTip), so rebased branches go
+// out without --force yet a teammate's push to a shared branch is not lost.
+func (r *submitCmdRunner) pushWithLease(branch string) error {
+	last, _ := git.GetSubmittedState(branch)
+	expected, err := git.ExpectedRemoteTip(branch, r.remoteName, last.Tip)
+	if err != nil {
+		return err
+	}
+	r.logger.Debug("Pushing with lease", "branch", branch, "expected", expected)
+	err = git.PushBranchWithExpectedTip(branch, r.remoteName, expected, r.pushConfig)
+	if errors.Is(err, git.ErrStaleLease) {
+		return fmt.Errorf("%w. Someone else may have pushed to it: fetch and integrate their commits, or rerun with --force to overwrite them", err)
+	}
+	return err
+}
+
+// unchangedSinceSubmit returns the PR number of branch when its last submit
+// published exactly state, the remote-tracking branch still points at the
+// same tip and the PR is still open against the same base, so pushing and
+// updating the PR again would change nothing. It returns 0 otherwise, and
+// always with --all.
+func (r *submitCmdRunner) unchangedSinceSubmit(branch string, state git.SubmittedState) int {
+	if r.all || r.rewritten[branch] {
+		return 0
+	}
+	last, ok := git.GetSubmittedState(branch)
+	if !ok || last != state {
+		return 0
+	}
+	number, err := git.GetStoredPRNumber(branch)
+	if err != nil || number == 0 {
+		return 0
+	}
+	remoteTip, err := git.ResolveCommit("refs/remotes/" + r.remoteName + "/" + branch)
+	if err != nil || remoteTip != state.Tip {
+		r.logger.Debug("Remote-tracking branch differs from the last submit", "branch", branch, "remoteTip", remoteTip, "error", err)
+		return 0
+	}
+	// Someone may have closed or retargeted the PR on GitHub since
+	pr, err := r.ghClient.GetPullRequest(number)
+	if err != nil || pr.GetState() != "open" || pr.GetBase().GetRef() != state.PRBase {
+		r.logger.Debug("PR changed on GitHub since the last submit", "branch", branch, "number", number, "state", pr.GetState(), "base", pr.GetBase().GetRef(), "error", err)
+		return 0
+	}
+	return number
+}
+
+// requestDeleteOnMerge records that branch's new PR should take its head
+// branch with it when it merges, so 'so sync' deletes the remote branch or
+// expects GitHub to have done so.
+func (r *submitCmdRunner) requestDeleteOnMerge(branch string) {
+	if err := git.SetBranchDeleteOnMerge(branch); err != nil {
+		r.events.Emit(events.Warning{Branch: branch, Message: fmt.Sprintf("could not record delete-on-merge for '%s': %v", branch, err)})
+		return
+	}
+	if r.repoDeletes == nil {
+		deletes, err := r.ghClient.DeletesBranchOnMerge()
+		if err != nil {
+			r.logger.Debug("Could not read the repository's delete-branch-on-merge setting", "error", err)
+		}
+		r.repoDeletes = &deletes
+		if !deletes {
+			r.events.Emit(events.Info{Message: fmt.Sprintf("GitHub does not delete head branches on merge in %s/%s; 'so sync' deletes them once their PRs merge.", r.owner, r.repoName)})
+		}
+	}
+}
+
+// fillStackHealth looks up the review and CI state of every PR in prInfoMap.
+// Failures only cost the emoji, so they are logged rather than reported.
+func fillStackHealth(ghClient gh.ClientInterface, prInfoMap map[string]submittedPrInfo, logger *slog.Logger) {
+	for branch, prInfo := range prInfoMap {
+		readiness, err := ghClient.GetMergeReadiness(prInfo.Number)
+		if err != nil {
+			logger.Debug("Could not get review/CI state for stack comment", "branch", branch, "pr", prInfo.Number, "error", err)
 			continue
 		}
-		prInfo, ok := prInfoMap[branchName]
-		indicator := ""
-		if branchName == currentBranch {
-			indicator = " 👈"
+		prInfo.Health = readiness.HealthEmoji()
+		prInfo.Merged = readiness.State == "MERGED"
+		prInfoMap[branch] = prInfo
+	}
+}
+
+// fillDescriptionSummaries adds the summary of each branch's description to
+// prInfoMap for the stack comment.
+func fillDescriptionSummaries(prInfoMap map[string]submittedPrInfo, logger *slog.Logger) {
+	for branch, prInfo := range prInfoMap {
+		description, err := git.GetBranchDescription(branch)
+		if err != nil {
+			logger.Debug("Could not read branch description for stack comment", "branch", branch, "error", err)
+			continue
 		}
+		prInfo.Summary = summarizeDescription(description)
+		prInfoMap[branch] = prInfo
+	}
+}
 
-		if ok {
-			sb.WriteString(fmt.Sprintf("* **#%d** %s\n",
-				prInfo.Number,
-				indicator,
-			))
-		} else {
-			sb.WriteString(fmt.Sprintf("* `%s` (Coming soon 🤞)%s\n",
-				branchName,
-				indicator,
-			))
+// warnDivergedMirrors warns about branches of the stack whose mirror (or
+// source, on a mirror chain) has commits the other side lacks, so paired PRs
+// do not quietly drift apart.
+func (r *submitCmdRunner) warnDivergedMirrors(fullStack []string) {
+	pairs, err := stackMirrorPairs(fullStack)
+	if err != nil {
+		r.logger.Debug("Could not read mirrors", "error", err)
+		return
+	}
+	for _, p := range pairs {
+		if exists, err := git.BranchExists(p.mirror); err != nil || !exists {
+			continue
+		}
+		onlySource, onlyMirror, err := p.divergence()
+		if err != nil {
+			r.logger.Debug("Could not compare mirror", "source", p.source, "mirror", p.mirror, "error", err)
+			continue
+		}
+		if len(onlySource) == 0 && len(onlyMirror) == 0 {
+			continue
+		}
+		r.events.Emit(events.Warning{Branch: p.source, Message: fmt.Sprintf("'%s' and its mirror '%s' have diverged (%d commit(s) only on the branch, %d only on the mirror). Run 'so mirror %s' to pick missing commits.",
+			p.source, p.mirror, len(onlySource), len(onlyMirror), p.base)})
+	}
+}
+
+// fillMirrorLinks adds the PR of each branch's mirror, or of its source on a
+// mirror chain, to prInfoMap for the stack comment.
+func fillMirrorLinks(stack []string, prInfoMap map[string]submittedPrInfo, logger *slog.Logger) {
+	pairs, err := stackMirrorPairs(stack)
+	if err != nil {
+		logger.Debug("Could not read mirrors for stack comment", "error", err)
+		return
+	}
+	for _, p := range pairs {
+		branch, other, otherBase := p.source, p.mirror, p.base
+		if _, ok := prInfoMap[branch]; !ok {
+			branch, other = p.mirror, p.source
+			otherBase, _ = git.GetGitConfig(git.BranchConfigKey(p.source, "socle-base"))
+		}
+		prInfo, ok := prInfoMap[branch]
+		if !ok || prInfo.Mirror != "" {
+			continue
+		}
+		if n, err := git.GetStoredPRNumber(other); err == nil && n > 0 {
+			prInfo.Mirror = fmt.Sprintf("#%d (%s)", n, otherBase)
+			prInfoMap[branch] = prInfo
+		}
+	}
+}
+
+// defaultCommentNeighbors is how many open PRs above and below its own one a
+// stack comment lists before folding the rest away.
+const defaultCommentNeighbors = 5
+
+// maxCommentLength stays below GitHub's 65536-character limit on comments.
+const maxCommentLength = 60000
+
+// Stack comment verbosities (socle.commentVerbosity).
+const (
+	commentCompact = "compact" // PR numbers only
+	commentLabeled = "labeled" // PR numbers with branch names
+	commentVerbose = "verbose" // Labeled, plus a legend explaining the list
+)
+
+// stackCommentLayout is how stack comments are laid out.
+type stackCommentLayout struct {
+	neighbors int
+	verbosity string
+}
+
+// readStackCommentLayout reads socle.commentNeighbors and
+// socle.commentVerbosity.
+func readStackCommentLayout(logger *slog.Logger) stackCommentLayout {
+	layout := stackCommentLayout{neighbors: stackCommentNeighbors(logger), verbosity: commentCompact}
+	if val, err := git.GetSocleConfig("socle.commentVerbosity"); err == nil {
+		switch v := strings.ToLower(strings.TrimSpace(val)); v {
+		case commentCompact, commentLabeled, commentVerbose:
+			layout.verbosity = v
+		default:
+			logger.Warn("Ignoring invalid socle.commentVerbosity", "value", val)
+		}
+	}
+	return layout
+}
+
+// stackCommentNeighbors reads socle.commentNeighbors; 0 never truncates.
+func stackCommentNeighbors(logger *slog.Logger) int {
+	val, err := git.GetSocleConfig("socle.commentNeighbors")
+	if err != nil {
+		return defaultCommentNeighbors
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(val), 10, 16)
+	if err != nil {
+		logger.Debug("Ignoring invalid socle.commentNeighbors", "value", val)
+		return defaultCommentNeighbors
+	}
+	return int(n)
+}
+
+// stackCommentLegend explains the list to readers new to stacked PRs.
+const stackCommentLegend = "_Newest PR first; each PR builds on the one below it. 👈 marks this PR._\n\n"
+
+// renderStackCommentBody lists the stack, newest branch first. On deep stacks
+// merged PRs collapse into one line and only the open PRs within
+// layout.neighbors of currentBranch are listed, with counts for the rest and
+// the full list in a <details> fold-out.
+func renderStackCommentBody(stack []string, currentBranch string, stackCommentMarker string, prInfoMap map[string]submittedPrInfo, layout stackCommentLayout) string {
+	defer profile.Start(profile.CategoryRender, "stack comment")()
+	labeled := layout.verbosity == commentLabeled || layout.verbosity == commentVerbose
+	var full []string
+	for i := len(stack) - 1; i >= 1; i-- {
+		full = append(full, stackCommentLine(stack[i], currentBranch, prInfoMap, labeled))
+	}
+	baseLine := fmt.Sprintf("* `%s` (base)\n", stack[0])
+
+	var sb strings.Builder
+	sb.WriteString("**Stack Overview:**\n\n")
+	if layout.verbosity == commentVerbose {
+		sb.WriteString(stackCommentLegend)
+	}
+	short, truncated := truncateStackLines(stack, currentBranch, prInfoMap, full, layout.neighbors)
+	sb.WriteString(strings.Join(short, ""))
+	sb.WriteString(baseLine)
+	if truncated {
+		details := fmt.Sprintf("\n<details><summary>Full stack (%d branches)</summary>\n\n%s%s\n</details>\n", len(stack)-1, strings.Join(full, ""), baseLine)
+		if sb.Len()+len(details) < maxCommentLength {
+			sb.WriteString(details)
 		}
 	}
 
@@ -320,3 +1150,80 @@ func renderStackCommentBody(stack []string, currentBranch string, stackCommentMa
 
 	return sb.String()
 }
+
+// stackCommentLine renders one branch of the stack comment; labeled adds the
+// branch name after the PR number.
+func stackCommentLine(branchName, currentBranch string, prInfoMap map[string]submittedPrInfo, labeled bool) string {
+	prInfo, ok := prInfoMap[branchName]
+	indicator := ""
+	if branchName == currentBranch {
+		indicator = " 👈"
+	}
+	if !ok {
+		return fmt.Sprintf("* `%s` (Coming soon 🤞)%s\n", branchName, indicator)
+	}
+	label := ""
+	if labeled {
+		label = fmt.Sprintf(" `%s`", branchName)
+	}
+	health := ""
+	if prInfo.Health != "" {
+		health = " " + prInfo.Health
+	}
+	summary := ""
+	if prInfo.Summary != "" {
+		summary = " " + prInfo.Summary
+	}
+	mirror := ""
+	if prInfo.Mirror != "" {
+		mirror = " ↔ " + prInfo.Mirror
+	}
+	return fmt.Sprintf("* **#%d**%s%s%s%s %s\n", prInfo.Number, label, health, mirror, summary, indicator)
+}
+
+// truncateStackLines shortens full, the stack's lines newest first, when the
+// stack has merged PRs or more open branches than fit around currentBranch.
+// It reports whether anything was left out.
+func truncateStackLines(stack []string, currentBranch string, prInfoMap map[string]submittedPrInfo, full []string, neighbors int) ([]string, bool) {
+	if neighbors <= 0 || len(full) <= 2*neighbors+1 {
+		return full, false
+	}
+	// Lines newest first; the branch of full[j] is stack[len(stack)-1-j].
+	var open []int
+	var merged []string
+	current := -1
+	for j := range full {
+		branch := stack[len(stack)-1-j]
+		if info, ok := prInfoMap[branch]; ok && info.Merged && branch != currentBranch {
+			merged = append(merged, fmt.Sprintf("#%d", info.Number))
+			continue
+		}
+		if branch == currentBranch {
+			current = len(open)
+		}
+		open = append(open, j)
+	}
+	if current < 0 {
+		current = len(open) - 1 // Not in the stack: show the bottom of it
+	}
+
+	lo, hi := max(0, current-neighbors), min(len(open), current+neighbors+1)
+	var lines []string
+	if lo > 0 {
+		lines = append(lines, fmt.Sprintf("* … %d more above\n", lo))
+	}
+	for _, j := range open[lo:hi] {
+		lines = append(lines, full[j])
+	}
+	if hi < len(open) {
+		lines = append(lines, fmt.Sprintf("* … %d more below\n", len(open)-hi))
+	}
+	if len(merged) > 0 {
+		// Oldest first, like a changelog.
+		for a, b := 0, len(merged)-1; a < b; a, b = a+1, b-1 {
+			merged[a], merged[b] = merged[b], merged[a]
+		}
+		lines = append(lines, fmt.Sprintf("* ✔️ %d merged: %s\n", len(merged), strings.Join(merged, ", ")))
+	}
+	return lines, true
+}
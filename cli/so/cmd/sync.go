@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/benekuehn/socle/cli/so/internal/git"
 	"github.com/spf13/cobra"
 )
 
@@ -15,17 +16,57 @@ Restacks all branches in your repository that can be restacked without conflicts
 If trunk cannot be fast-forwarded to match remote, overwrites trunk with the remote version.
 
 Process:
-1. Fetches all branches from remote
-2. Checks PR status for each branch
-3. Prompts to delete branches with merged/closed PRs
-4. Restacks branches that can be restacked without conflicts
-5. Updates trunk to match remote if needed`,
+1. Fetches all branches from remote (unless socle.noFetch is set)
+2. Checks PR status for each branch. A branch without a merged or closed PR
+   whose commits are all in the fetched trunk anyway (merged by hand,
+   fast-forwarded or cherry-picked) counts as merged too
+3. Prompts to delete branches with merged/closed PRs, listing each branch's PR
+   and any commits that are not in trunk and would become unreachable. All
+   branches are pre-selected; unchecked branches are kept and not offered
+   again until their PR status changes (or with --include-kept)
+4. Restacks every stack on the trunk. A conflict pauses the restack, unless
+   --stop-on-conflict=skip (or socle.stopOnConflict) skips the conflicting
+   branch and its descendants and carries on with the other stacks
+5. Updates trunk to match remote if needed
+
+When every PR of a stack has merged, socle.changelog records the stack as a
+changelog snippet once you confirm deleting all of its branches: 'file' writes
+<socle.changelogDir>/<stack name>.md (changelog.d by default), 'issue' posts
+it on the stack's tracking issue ('so stack issue'), 'both' does both. Each PR
+becomes a line of socle.changelogTemplate, "- {title} (#{number})" by default,
+which can also use {url}, {branch}, {body} and {summary} (the first paragraph
+of the PR description).
+
+Open PRs are always checked with GitHub. Merged and closed statuses fetched
+by 'so log' or an earlier sync are reused for socle.prStatusCacheTTL seconds
+(60 by default); --no-cache asks GitHub about every PR.
+
+With --all, sync covers every stack on every base branch (socle.baseBranches
+and frozen bases) instead of the current branch's stack: each base is
+updated and the stacks are restacked base by base, parents before children.
+
+Use --dry-run to print the whole plan without changing anything; a dry run
+does not fetch, so the plan is based on the remote branches as last fetched.`,
 	Args: cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: withNextStepHint(guardStackInvariants(func(cmd *cobra.Command, args []string) error {
 		logger := slog.Default()
 
 		noFetch, _ := cmd.Flags().GetBool("test-no-fetch")
+		if !noFetch {
+			var err error
+			if noFetch, err = git.GetSocleConfigBool("socle.noFetch", false); err != nil {
+				return err
+			}
+		}
 		noSurvey, _ := cmd.Flags().GetBool("test-no-survey")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		includeKept, _ := cmd.Flags().GetBool("include-kept")
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+		all, _ := cmd.Flags().GetBool("all")
+		onConflict, err := conflictPolicy(cmd)
+		if err != nil {
+			return err
+		}
 
 		runner := &syncCmdRunner{
 			logger:         logger,
@@ -35,18 +76,28 @@ Process:
 			nonInteractive: nonInteractive,
 
 			// Populate config from flags
-			doRestack: !cmd.Flag("no-restack").Changed,
-			noFetch:   noFetch,
-			noSurvey:  noSurvey,
+			doRestack:   !cmd.Flag("no-restack").Changed,
+			noFetch:     noFetch,
+			noSurvey:    noSurvey,
+			dryRun:      dryRun,
+			includeKept: includeKept,
+			noCache:     noCache,
+			all:         all,
+			onConflict:  onConflict,
 		}
 
 		return runner.run(cmd)
-	},
+	})),
 }
 
 func init() {
 	AddCommand(syncCmd)
 	syncCmd.Flags().Bool("no-restack", false, "Skip restacking branches")
+	syncCmd.Flags().Bool("all", false, "Sync every stack on every base branch, not just the current one")
+	syncCmd.Flags().Bool("include-kept", false, "Offer branches kept in an earlier sync for deletion again")
+	syncCmd.Flags().String("stop-on-conflict", conflictHalt, "On a restack conflict, halt or skip the branch and its descendants: halt|skip (default from socle.stopOnConflict)")
+	syncCmd.Flags().Bool("no-cache", false, "Ask GitHub for every PR status instead of reusing cached merged and closed ones")
+	syncCmd.Flags().Bool("dry-run", false, "Print the deletions, reparenting and trunk update sync would perform without changing anything")
 	syncCmd.Flags().Bool("test-no-fetch", false, "TESTING: Skip fetching from remote")
 	syncCmd.Flags().Bool("test-no-survey", false, "TESTING: Auto-answer yes to all prompts")
 	_ = syncCmd.Flags().MarkHidden("test-no-fetch")
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+// Where socle.changelog puts the snippet of a fully merged stack.
+const (
+	changelogOff   = "off"
+	changelogFile  = "file"  // A Markdown file in socle.changelogDir
+	changelogIssue = "issue" // A comment on the stack's tracking issue
+	changelogBoth  = "both"
+)
+
+// defaultChangelogDir and defaultChangelogTemplate apply when
+// socle.changelogDir and socle.changelogTemplate are unset.
+const (
+	defaultChangelogDir      = "changelog.d"
+	defaultChangelogTemplate = "- {title} (#{number})"
+)
+
+// changelogMarker identifies the changelog comment on a tracking issue, so a
+// later sync updates it instead of posting another.
+const changelogMarker = "<!-- socle-changelog -->"
+
+// unsafeFileChars are replaced in a stack name to make it a file name.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// stackChangelog is the changelog snippet of a fully merged stack, rendered
+// while the stack's metadata still exists and written once its branches are
+// deleted.
+type stackChangelog struct {
+	name     string
+	branches []string
+	snippet  string
+	mode     string
+	issue    int // The stack's tracking issue, 0 if it has none
+}
+
+// prepareChangelog renders the snippet of stack, all of whose PRs merged,
+// when socle.changelog asks for one. It runs before the merged branches are
+// deleted, since their metadata goes with them, and returns nil when there is
+// nothing to write. Failures only warn; the merge already happened.
+func (r *syncCmdRunner) prepareChangelog(client gh.ClientInterface, stack []string, results map[string]syncCandidate) *stackChangelog {
+	mode, err := git.GetSocleConfig("socle.changelog")
+	if err != nil || mode == "" {
+		mode = changelogOff
+	}
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == changelogOff || len(stack) < 2 {
+		return nil
+	}
+	for _, branch := range stack[1:] {
+		if results[branch].status != gh.PRStatusMerged {
+			return nil // Not fully merged yet
+		}
+	}
+
+	name, _ := git.GetStackName(stack[1])
+	if name == "" {
+		name = stack[1]
+	}
+	snippet, err := r.renderChangelog(client, name, stack[1:], results)
+	if err != nil {
+		r.warnChangelog(err)
+		return nil
+	}
+	changelog := &stackChangelog{name: name, branches: stack[1:], snippet: snippet, mode: mode}
+	if mode == changelogIssue || mode == changelogBoth {
+		if changelog.issue, err = git.GetTrackingIssue(stack[1:]); err != nil {
+			r.warnChangelog(err)
+		}
+	}
+	return changelog
+}
+
+// writeChangelog puts the snippet where socle.changelog asks: a file, a
+// comment on the stack's tracking issue, or both.
+func (r *syncCmdRunner) writeChangelog(client gh.ClientInterface, changelog *stackChangelog) {
+	if changelog.mode == changelogFile || changelog.mode == changelogBoth {
+		r.writeChangelogFile(changelog.name, changelog.snippet)
+	}
+	if changelog.mode == changelogIssue || changelog.mode == changelogBoth {
+		r.postChangelogComment(client, changelog.issue, changelog.snippet)
+	}
+}
+
+// renderChangelog fills socle.changelogTemplate for each PR of branches,
+// bottom first, under a heading naming the stack.
+func (r *syncCmdRunner) renderChangelog(client gh.ClientInterface, name string, branches []string, results map[string]syncCandidate) (string, error) {
+	tmpl, err := git.GetSocleConfig("socle.changelogTemplate")
+	if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
+		return "", err
+	}
+	if strings.TrimSpace(tmpl) == "" {
+		tmpl = defaultChangelogTemplate
+	}
+	tmpl = strings.ReplaceAll(tmpl, `\n`, "\n")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", name)
+	for _, branch := range branches {
+		result := results[branch]
+		pr, err := client.GetPullRequest(result.prNumber)
+		if err != nil {
+			return "", fmt.Errorf("failed to read PR #%d: %w", result.prNumber, err)
+		}
+		body := strings.TrimSpace(strings.ReplaceAll(pr.GetBody(), "\r\n", "\n"))
+		summary, _, _ := strings.Cut(body, "\n\n")
+		b.WriteString(strings.NewReplacer(
+			"{title}", pr.GetTitle(),
+			"{number}", strconv.Itoa(result.prNumber),
+			"{url}", pr.GetHTMLURL(),
+			"{branch}", branch,
+			"{summary}", strings.TrimSpace(summary),
+			"{body}", body,
+		).Replace(tmpl))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// writeChangelogFile writes snippet to <socle.changelogDir>/<stack>.md,
+// relative to the top of the work tree. Syncing the same stack again
+// rewrites the same file.
+func (r *syncCmdRunner) writeChangelogFile(name, snippet string) {
+	dir, err := git.GetSocleConfig("socle.changelogDir")
+	if err != nil || strings.TrimSpace(dir) == "" {
+		dir = defaultChangelogDir
+	}
+	if !filepath.IsAbs(dir) {
+		root, err := git.GetRepoRoot()
+		if err != nil {
+			r.warnChangelog(err)
+			return
+		}
+		dir = filepath.Join(root, dir)
+	}
+	path := filepath.Join(dir, strings.Trim(unsafeFileChars.ReplaceAllString(name, "-"), "-")+".md")
+
+	if r.dryRun {
+		_, _ = fmt.Fprintf(r.stdout, "  Would write the changelog snippet of '%s' to %s\n", name, path)
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		r.warnChangelog(err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(snippet), 0o644); err != nil {
+		r.warnChangelog(err)
+		return
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("  Wrote the changelog snippet of '%s' to %s", name, path)))
+}
+
+// postChangelogComment posts snippet on the tracking issue, or updates the
+// changelog comment already there.
+func (r *syncCmdRunner) postChangelogComment(client gh.ClientInterface, issue int, snippet string) {
+	if issue == 0 {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("  The stack has no tracking issue to post its changelog snippet on ('so stack issue')."))
+		return
+	}
+	if r.dryRun {
+		_, _ = fmt.Fprintf(r.stdout, "  Would post the changelog snippet on #%d\n", issue)
+		return
+	}
+
+	body := changelogMarker + "\n" + snippet
+	id, err := client.FindCommentWithMarker(issue, changelogMarker)
+	if err == nil && id != 0 {
+		_, err = client.UpdateComment(id, body)
+	} else if err == nil {
+		_, err = client.CreateComment(issue, body)
+	}
+	if err != nil {
+		r.warnChangelog(err)
+		return
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("  Posted the changelog snippet on #%d", issue)))
+}
+
+func (r *syncCmdRunner) warnChangelog(err error) {
+	_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("  Warning: could not write the changelog snippet: %v", err)))
+}
@@ -6,13 +6,17 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"strconv"
-	"sync"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/benekuehn/socle/cli/so/internal/gh"
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/interrupt"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
 	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/benekuehn/socle/cli/so/internal/workpool"
 	"github.com/spf13/cobra"
 )
 
@@ -25,19 +29,56 @@ type syncCmdRunner struct {
 	nonInteractive bool
 
 	// Config flags
-	doRestack bool
-	noFetch   bool
-	noSurvey  bool // Auto-confirm any prompts for tests
+	doRestack   bool
+	onConflict  string // conflictHalt or conflictSkip, for the restack
+	noFetch     bool
+	noSurvey    bool // Auto-confirm any prompts for tests
+	dryRun      bool // Print the plan without changing anything
+	includeKept bool // Offer branches kept in an earlier sync again
+	noCache     bool // Ask GitHub about every PR, even ones cached as merged or closed
+	all         bool // Sync every stack on every base, not just the current one
+}
+
+// syncScope is what a sync covers: the current branch's stack or, with
+// --all, every stack on every base.
+type syncScope struct {
+	bases     []string          // Trunks, in the order they are updated
+	stacks    [][]string        // Base first
+	branches  []string          // Stacked branches, parents before children
+	baseOf    map[string]string // Branch -> the base of its stack
+	parentMap map[string]string // Child -> parent
+}
+
+// syncCandidate is a branch whose PR was merged or closed, or that landed in
+// its trunk without one.
+type syncCandidate struct {
+	branch   string
+	prNumber int
+	status   string // A PR status, or syncStatusLanded
+	prURL    string
+	trunkRef string // For syncStatusLanded, where the commits are
+}
+
+// syncStatusLanded is the status of a branch without a merged or closed PR
+// whose commits are all in its trunk.
+const syncStatusLanded = "Landed"
+
+// describe says why c is offered for deletion.
+func (c syncCandidate) describe() string {
+	if c.status == syncStatusLanded {
+		return fmt.Sprintf("merged into '%s'", c.trunkRef)
+	}
+	return fmt.Sprintf("PR #%d %s", c.prNumber, c.status)
 }
 
 func (r *syncCmdRunner) run(cmd *cobra.Command) error {
 	// --- Pre-Checks ---
 	if git.IsRebaseInProgress() {
 		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Git rebase already in progress."))
-		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Resolve conflicts and run 'git rebase --continue' or cancel with 'git rebase --abort'."))
-		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Once the Git rebase is finished, run 'so sync' again if needed."))
-		cmd.SilenceUsage = true // Prevent usage printing on clean exit
-		return nil              // Exit cleanly, user needs to act in Git
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Resolve conflicts and run 'so continue' (or 'git rebase --continue' for a rebase of your own), or cancel with 'git rebase --abort'."))
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.InfoStyle.Render("Once the rebase is finished, run 'so sync' again if needed."))
+		cmd.SilenceUsage = true
+		return socleerr.New(socleerr.Conflict, "a rebase is already in progress") // The user needs to act in Git
 	}
 
 	hasChanges, err := git.HasUncommittedChanges()
@@ -45,11 +86,11 @@ func (r *syncCmdRunner) run(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to check working tree status: %w", err)
 	}
 	if hasChanges {
-		return fmt.Errorf("uncommitted changes detected. Please commit or stash them before syncing")
+		return socleerr.New(socleerr.DirtyWorktree, "uncommitted changes detected. Please commit or stash them before syncing")
 	}
 
 	// --- Setup GitHub Client ---
-	remoteName := "origin"
+	remoteName := git.GetRemoteName()
 	remoteURL, err := git.GetRemoteURL(remoteName)
 	if err != nil {
 		return fmt.Errorf("cannot get remote URL for '%s': %w", remoteName, err)
@@ -64,9 +105,14 @@ func (r *syncCmdRunner) run(cmd *cobra.Command) error {
 	if err != nil {
 		return fmt.Errorf("failed to create GitHub client: %w", err)
 	}
+	if !r.noCache {
+		ghClient = gh.WithStatusCache(ghClient, owner+"/"+repoName, prStatusCacheTTL(r.logger), true)
+	}
 
 	// --- Fetch All Branches ---
-	if !r.noFetch {
+	if r.dryRun {
+		_, _ = fmt.Fprintln(r.stdout, "Skipping fetch (--dry-run).")
+	} else if !r.noFetch {
 		_, _ = fmt.Fprintln(r.stdout, "Fetching all branches from remote...")
 		if err := git.FetchAll(remoteName); err != nil {
 			return fmt.Errorf("failed to fetch from remote '%s': %w", remoteName, err)
@@ -76,127 +122,143 @@ func (r *syncCmdRunner) run(cmd *cobra.Command) error {
 		_, _ = fmt.Fprintln(r.stdout, "Skipping fetch (--no-fetch).")
 	}
 
+	if err := interrupt.Check(cmd.Context(), "run 'so sync' again"); err != nil {
+		return err
+	}
+
 	// --- Get Stack Info ---
-	stackInfo, err := git.GetStackInfo()
+	scope, err := r.scope()
 	if err != nil {
-		return fmt.Errorf("failed to get stack info: %w", err)
+		return err
 	}
 
 	// --- Check PR Statuses and Clean Up ---
 	_, _ = fmt.Fprintln(r.stdout, "\nChecking PR statuses...")
 
-	// Process branches in parallel
-	var wg sync.WaitGroup
-	results := make(map[string]struct {
+	// Ask about the PRs workpool.DefaultWorkers at a time
+	type prLookup struct {
+		branch   string
 		prNumber int
 		status   string
-	})
-	var mu sync.Mutex
-
-	for i := 1; i < len(stackInfo.FullStack); i++ {
-		branch := stackInfo.FullStack[i]
+		prURL    string
+		err      error
+	}
+	var lookups []prLookup
+	for _, branch := range scope.branches {
 		prNumber, err := git.GetStoredPRNumber(branch)
 		if err != nil || prNumber == 0 {
 			continue // Skip branches without PRs
 		}
+		lookups = append(lookups, prLookup{branch: branch, prNumber: prNumber})
+	}
+	if err := workpool.Run(cmd.Context(), len(lookups), workpool.DefaultWorkers, func(i int) {
+		l := &lookups[i]
+		l.status, l.prURL, l.err = ghClient.GetPullRequestStatus(l.prNumber)
+	}); err != nil {
+		return interrupt.Check(cmd.Context(), "nothing was changed; run 'so sync' again")
+	}
 
-		wg.Add(1)
-		go func(branchName string, prNum int) {
-			defer wg.Done()
-
-			status, _, err := ghClient.GetPullRequestStatus(prNum)
-			if err != nil {
-				_, _ = fmt.Fprintf(r.stderr, ui.Colors.WarningStyle.Render("  Warning: Could not get status for PR #%d (branch '%s'): %v\n"), prNum, branchName, err)
-				return
-			}
+	results := make(map[string]syncCandidate)
+	var openBranches []string // Branches whose PR is still (or again) open
+	for _, l := range lookups {
+		if l.err != nil {
+			_, _ = fmt.Fprintf(r.stderr, ui.Colors.WarningStyle.Render("  Warning: Could not get status for PR #%d (branch '%s'): %v\n"), l.prNumber, l.branch, l.err)
+			continue
+		}
+		if l.status == gh.PRStatusMerged || l.status == gh.PRStatusClosed {
+			results[l.branch] = syncCandidate{branch: l.branch, prNumber: l.prNumber, status: l.status, prURL: l.prURL}
+		} else {
+			openBranches = append(openBranches, l.branch)
+		}
+	}
+	r.detectLanded(scope, results, openBranches, remoteName)
 
-			if status == gh.PRStatusMerged || status == gh.PRStatusClosed {
-				mu.Lock()
-				results[branchName] = struct {
-					prNumber int
-					status   string
-				}{prNum, status}
-				mu.Unlock()
+	// A kept branch whose PR was reopened is offered again once it closes.
+	for _, branch := range openBranches {
+		if kept, _ := git.GetSyncKept(branch); kept != "" && !r.dryRun {
+			if err := git.SetSyncKept(branch, ""); err != nil {
+				r.logger.Debug("Failed to forget kept branch", "branch", branch, "error", err)
 			}
-		}(branch, prNumber)
+		}
 	}
 
-	// Wait for all checks to complete
-	wg.Wait()
-
 	// Process results in order
+	candidates := make([]syncCandidate, 0, len(results))
 	branchesToDelete := make([]string, 0, len(results))
 	currentBranch, err := git.GetCurrentBranch()
 	if err != nil {
 		return fmt.Errorf("failed to get current branch: %w", err)
 	}
 
-	for i := 1; i < len(stackInfo.FullStack); i++ {
-		branch := stackInfo.FullStack[i]
-		if result, ok := results[branch]; ok {
-			// Include the current branch in branches to delete
-			branchesToDelete = append(branchesToDelete, branch)
+	for _, branch := range scope.branches {
+		result, ok := results[branch]
+		if !ok {
+			continue
+		}
+		if kept, _ := git.GetSyncKept(branch); kept == result.status && !r.includeKept {
+			_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render(fmt.Sprintf("  Keeping '%s' (%s), as chosen in an earlier sync.", branch, result.describe())))
+			continue
+		}
+		// Include the current branch in branches to delete
+		candidates = append(candidates, result)
+		branchesToDelete = append(branchesToDelete, branch)
+		if result.status == syncStatusLanded {
+			_, _ = fmt.Fprintf(r.stdout, "  Found branch '%s' merged into '%s' without a merged PR\n", branch, result.trunkRef)
+		} else {
 			_, _ = fmt.Fprintf(r.stdout, "  Found %s PR #%d for branch '%s'\n", result.status, result.prNumber, branch)
 		}
 	}
 
+	// The snippet reads the stack's metadata, which deleting the branches drops.
+	var changelogs []*stackChangelog
+	for _, stack := range scope.stacks {
+		if changelog := r.prepareChangelog(ghClient, stack, results); changelog != nil {
+			changelogs = append(changelogs, changelog)
+		}
+	}
+
+	// Work out the reparenting up front so the preview and the dry run show
+	// exactly what deleting every candidate would do.
+	branchUpdates, err := r.planReparenting(scope, branchesToDelete)
+	if err != nil {
+		return err
+	}
+
 	// --- Prompt to Delete Branches ---
 	if len(branchesToDelete) > 0 {
 		_, _ = fmt.Fprintf(r.stdout, "\nThe following branches have merged or closed PRs:\n")
-		for _, branch := range branchesToDelete {
-			_, _ = fmt.Fprintf(r.stdout, "  - %s\n", branch)
+		if err := r.printDeletionPreview(candidates, scope, remoteName); err != nil {
+			return err
 		}
 
-		confirm := r.noSurvey // Auto-confirm for tests
-		if !r.noSurvey && !r.nonInteractive {
-			prompt := &survey.Confirm{
-				Message: "Delete these " + strconv.Itoa(len(branchesToDelete)) + " branches?",
-			}
-			if err := survey.AskOne(prompt, &confirm); err != nil {
-				return fmt.Errorf("failed to get user confirmation: %w", err)
+		if r.dryRun {
+			for _, changelog := range changelogs {
+				r.writeChangelog(ghClient, changelog)
 			}
+			return r.printDryRunPlan(scope, branchUpdates, remoteName)
 		}
 
-		if r.nonInteractive && !r.noSurvey {
-			_, _ = fmt.Fprintln(r.stdout, ui.Colors.InfoStyle.Render("Non-interactive mode: skipping branch deletion; rerun without --non-interactive to confirm."))
+		selected, err := r.selectDeletions(candidates)
+		if err != nil {
+			return err
 		}
-
-		if confirm {
-			// Get initial stack info before any modifications
-			initialStackInfo, err := git.GetStackInfo()
-			if err != nil {
-				return fmt.Errorf("failed to get initial stack info: %w", err)
-			}
-
-			// Create a map of branch -> new parent for all branches that need updating
-			// This ensures we have all the updates ready before making any changes
-			branchUpdates := make(map[string]string)
-			for _, branch := range branchesToDelete {
-				// Get the parent of the branch to be deleted
-				parentConfigKey := fmt.Sprintf("branch.%s.socle-parent", branch)
-				deletedBranchParent, err := git.GetGitConfig(parentConfigKey)
-				if err != nil {
-					return fmt.Errorf("failed to get parent for branch '%s': %w", branch, err)
-				}
-
-				// Find all branches that were tracking this branch
-				for _, currentBranch := range initialStackInfo.FullStack {
-					if currentBranch == branch || currentBranch == initialStackInfo.BaseBranch {
-						continue
-					}
-					parent, ok := initialStackInfo.ParentMap[currentBranch]
-					if !ok {
-						continue
-					}
-					if parent == branch {
-						// This branch needs to be updated to track the deleted branch's parent
-						branchUpdates[currentBranch] = deletedBranchParent
-					}
-				}
+		if selected, err = r.releaseWorktrees(selected); err != nil {
+			return err
+		}
+		if len(selected) < len(branchesToDelete) {
+			branchesToDelete = selected
+			if branchUpdates, err = r.planReparenting(scope, branchesToDelete); err != nil {
+				return err
 			}
+		}
 
+		if len(branchesToDelete) > 0 {
 			// Apply all tracking updates first
-			for branch, newParent := range branchUpdates {
+			for _, branch := range scope.branches {
+				newParent, ok := branchUpdates[branch]
+				if !ok {
+					continue
+				}
 				if err := git.UpdateBranchParent(branch, newParent); err != nil {
 					return fmt.Errorf("failed to update parent for branch '%s' to '%s': %w", branch, newParent, err)
 				}
@@ -205,12 +267,24 @@ func (r *syncCmdRunner) run(cmd *cobra.Command) error {
 
 			// Now that all tracking is updated, delete the branches
 			for _, branch := range branchesToDelete {
+				// Tracking is already updated, so a rerun deletes the rest.
+				if err := interrupt.Check(cmd.Context(), "run 'so sync' again to delete the remaining branches"); err != nil {
+					return err
+				}
 				// If this is the current branch, switch to main first
 				if branch == currentBranch {
-					if err := git.SwitchBranch(initialStackInfo.BaseBranch); err != nil {
+					base := scope.baseOf[branch]
+					if err := git.SwitchBranch(base); err != nil {
 						return fmt.Errorf("failed to switch to base branch before deleting current branch: %w", err)
 					}
-					_, _ = fmt.Fprintf(r.stdout, "  Switched to base branch '%s'\n", initialStackInfo.BaseBranch)
+					_, _ = fmt.Fprintf(r.stdout, "  Switched to base branch '%s'\n", base)
+					currentBranch = base
+				}
+
+				// Deleting the branch drops its config, so read this first
+				deleteRemote := false
+				if results[branch].status == gh.PRStatusMerged {
+					deleteRemote, _ = git.GetBranchDeleteOnMerge(branch)
 				}
 
 				_, _ = fmt.Fprintf(r.stdout, "Deleting branch %s... ", branch)
@@ -219,28 +293,45 @@ func (r *syncCmdRunner) run(cmd *cobra.Command) error {
 					return fmt.Errorf("failed to delete branch '%s': %w", branch, err)
 				}
 				_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Success"))
+				if deleteRemote {
+					r.deleteMergedRemoteBranch(branch, remoteName)
+				}
+			}
+			// A stack's snippet is written once all of its branches are gone.
+			for _, changelog := range changelogs {
+				kept := slices.ContainsFunc(changelog.branches, func(b string) bool { return !slices.Contains(branchesToDelete, b) })
+				if !kept {
+					r.writeChangelog(ghClient, changelog)
+				}
+			}
+			if retention, err := git.TombstoneRetention(); err == nil && retention > 0 {
+				_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render(fmt.Sprintf("  Deleted branches can be brought back with 'so restore <branch>' for %d days.", int(retention.Hours()/24))))
 			}
 		}
+	} else if r.dryRun {
+		_, _ = fmt.Fprintln(r.stdout, "  No branches with merged or closed PRs.")
+		return r.printDryRunPlan(scope, branchUpdates, remoteName)
 	}
 
 	// --- Update Trunk ---
-	baseBranch := stackInfo.BaseBranch
-	_, _ = fmt.Fprintf(r.stdout, "\nUpdating trunk branch '%s'...\n", baseBranch)
-
-	// Try fast-forward first
-	if err := git.FastForwardBranch(baseBranch, remoteName); err != nil {
-		if errors.Is(err, git.ErrNotFastForward) {
-			// Not fast-forwardable, need to force update
-			_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render("  Trunk cannot be fast-forwarded. Force updating..."))
-			if err := git.ForceUpdateBranch(baseBranch, remoteName); err != nil {
-				return fmt.Errorf("failed to force update trunk: %w", err)
-			}
-			_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("  Trunk force updated."))
-		} else {
-			return fmt.Errorf("failed to update trunk: %w", err)
+	for _, baseBranch := range scope.bases {
+		if interrupt.Requested(cmd.Context()) {
+			break // Check out the user's branch again before stopping
+		}
+		if err := r.updateTrunk(baseBranch, remoteName); err != nil {
+			return err
+		}
+	}
+	// Updating a trunk checks it out; the restack returns to the branch the
+	// user was on.
+	if current, err := git.GetCurrentBranch(); err == nil && current != currentBranch {
+		if err := git.CheckoutBranch(currentBranch); err != nil {
+			return fmt.Errorf("failed to check out '%s' again: %w", currentBranch, err)
 		}
-	} else {
-		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("  Trunk fast-forwarded."))
+	}
+
+	if err := interrupt.Check(cmd.Context(), "run 'so sync' again"); err != nil {
+		return err
 	}
 
 	// --- Restack if Enabled ---
@@ -254,6 +345,11 @@ func (r *syncCmdRunner) run(cmd *cobra.Command) error {
 			nonInteractive: r.nonInteractive,
 			noFetch:        true, // We already fetched
 			noPush:         true, // Don't push during sync
+			onConflict:     r.onConflict,
+			allStacks:      true,
+		}
+		if r.all {
+			restackRunner.bases = scope.bases
 		}
 		if err := restackRunner.run(cmd); err != nil {
 			return fmt.Errorf("failed during restack: %w", err)
@@ -263,3 +359,332 @@ func (r *syncCmdRunner) run(cmd *cobra.Command) error {
 	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("\nSync completed successfully."))
 	return nil
 }
+
+// scope returns the current branch's stack or, with --all, every stack on
+// every base.
+func (r *syncCmdRunner) scope() (*syncScope, error) {
+	parentMap, err := git.GetAllSocleParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking relationships: %w", err)
+	}
+	scope := &syncScope{baseOf: map[string]string{}, parentMap: parentMap}
+	add := func(stack []string) {
+		scope.stacks = append(scope.stacks, stack)
+		for _, branch := range stack[1:] {
+			if _, seen := scope.baseOf[branch]; !seen {
+				scope.baseOf[branch] = stack[0]
+				scope.branches = append(scope.branches, branch)
+			}
+		}
+	}
+
+	if !r.all {
+		stackInfo, err := git.GetStackInfo()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stack info: %w", err)
+		}
+		scope.bases = []string{stackInfo.BaseBranch}
+		if len(stackInfo.FullStack) > 0 {
+			add(stackInfo.FullStack)
+		}
+		return scope, nil
+	}
+
+	childMap := git.BuildChildMap(parentMap)
+	for _, base := range syncBases() {
+		if exists, err := git.BranchExists(base); err != nil || !exists {
+			continue
+		}
+		if len(childMap[base]) == 0 {
+			continue
+		}
+		stacks, err := git.GetAvailableStacksFromBase(base)
+		if err != nil {
+			return nil, err
+		}
+		if len(stacks) == 0 {
+			continue
+		}
+		scope.bases = append(scope.bases, base)
+		for _, stack := range stacks {
+			add(stack)
+		}
+	}
+	if len(scope.bases) == 0 {
+		return nil, fmt.Errorf("no stacks to sync on any base branch (%s)", strings.Join(git.KnownBaseBranches(), ", "))
+	}
+	return scope, nil
+}
+
+// syncBases returns the configured base branches followed by the frozen ones.
+func syncBases() []string {
+	bases := git.KnownBaseBranches()
+	var frozen []string
+	for base := range git.KnownBaseBranchSet() {
+		if !slices.Contains(bases, base) {
+			frozen = append(frozen, base)
+		}
+	}
+	sort.Strings(frozen)
+	return append(slices.Clone(bases), frozen...)
+}
+
+// updateTrunk fast-forwards baseBranch to its remote branch, or resets it
+// when it cannot be fast-forwarded. A frozen base is left alone.
+func (r *syncCmdRunner) updateTrunk(baseBranch, remoteName string) error {
+	_, _ = fmt.Fprintf(r.stdout, "\nUpdating trunk branch '%s'...\n", baseBranch)
+
+	if frozen, ok := git.GetFrozenBase(baseBranch); ok {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render(fmt.Sprintf("  Frozen at %s; not updated.", frozen.Ref)))
+	} else if err := git.FastForwardBranch(baseBranch, remoteName); err != nil {
+		if errors.Is(err, git.ErrNotFastForward) {
+			// Not fast-forwardable, need to force update
+			_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render("  Trunk cannot be fast-forwarded. Force updating..."))
+			if err := git.ForceUpdateBranch(baseBranch, remoteName); err != nil {
+				return fmt.Errorf("failed to force update trunk: %w", err)
+			}
+			_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("  Trunk force updated."))
+		} else {
+			return fmt.Errorf("failed to update trunk: %w", err)
+		}
+	} else {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("  Trunk fast-forwarded."))
+	}
+	return nil
+}
+
+// detectLanded adds to results the branches that have no merged or closed PR
+// but whose commits are all in their (fetched) trunk anyway: merged by hand,
+// or with the PR number lost. Branches with an open PR are left alone.
+func (r *syncCmdRunner) detectLanded(scope *syncScope, results map[string]syncCandidate, openBranches []string, remoteName string) {
+	trunkRefs := make(map[string]string, len(scope.bases))
+	for _, base := range scope.bases {
+		trunkRefs[base] = git.ResolveTrunkRef(base, remoteName)
+	}
+	for _, branch := range scope.branches {
+		if _, ok := results[branch]; ok || slices.Contains(openBranches, branch) {
+			continue
+		}
+		trunkRef := trunkRefs[scope.baseOf[branch]]
+		landed, err := git.LandedIn(trunkRef, scope.parentMap[branch], branch)
+		if err != nil {
+			r.logger.Debug("Could not check whether branch landed", "branch", branch, "trunk", trunkRef, "error", err)
+			continue
+		}
+		if landed {
+			results[branch] = syncCandidate{branch: branch, status: syncStatusLanded, trunkRef: trunkRef}
+		}
+	}
+}
+
+// selectDeletions asks which candidates to delete, all pre-selected. Branches
+// the user unchecks are remembered with their PR status so later syncs don't
+// ask again until the status changes (or --include-kept is given).
+func (r *syncCmdRunner) selectDeletions(candidates []syncCandidate) ([]string, error) {
+	all := make([]string, 0, len(candidates))
+	labels := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		all = append(all, c.branch)
+		labels = append(labels, fmt.Sprintf("%s (%s)", c.branch, c.describe()))
+	}
+	if r.noSurvey {
+		return all, nil // Auto-confirm for tests
+	}
+	if r.nonInteractive {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.InfoStyle.Render("Non-interactive mode: skipping branch deletion; rerun without --non-interactive to confirm."))
+		return nil, nil
+	}
+
+	var picked []string
+	prompt := &survey.MultiSelect{
+		Message: "Delete these branches? Unchecked branches are kept and not offered again:",
+		Options: labels,
+		Default: labels,
+	}
+	if err := ui.AskOne(prompt, &picked); err != nil {
+		return nil, fmt.Errorf("failed to get user confirmation: %w", err)
+	}
+
+	chosen := make(map[string]bool, len(picked))
+	for _, label := range picked {
+		chosen[label] = true
+	}
+	var selected []string
+	for i, c := range candidates {
+		if chosen[labels[i]] {
+			selected = append(selected, c.branch)
+			continue
+		}
+		if err := git.SetSyncKept(c.branch, c.status); err != nil {
+			r.logger.Debug("Failed to remember kept branch", "branch", c.branch, "error", err)
+		}
+		_, _ = fmt.Fprintf(r.stdout, "  Keeping '%s'.\n", c.branch)
+	}
+	return selected, nil
+}
+
+// releaseWorktrees drops the branches checked out in another worktree, which
+// git cannot delete. Interactively, it offers to remove such worktrees first.
+func (r *syncCmdRunner) releaseWorktrees(branches []string) ([]string, error) {
+	worktrees, err := git.OtherWorktreeBranches()
+	if err != nil {
+		return nil, err
+	}
+	var deletable []string
+	for _, branch := range branches {
+		path, ok := worktrees[branch]
+		if !ok {
+			deletable = append(deletable, branch)
+			continue
+		}
+		if !r.noSurvey && !r.nonInteractive {
+			remove := false
+			prompt := &survey.Confirm{Message: fmt.Sprintf("'%s' is checked out in the worktree at '%s'. Remove that worktree?", branch, path), Default: false}
+			if err := ui.AskOne(prompt, &remove); err != nil {
+				return nil, fmt.Errorf("failed to get user confirmation: %w", err)
+			}
+			if remove {
+				err := git.RemoveWorktree(path)
+				if err == nil {
+					_, _ = fmt.Fprintf(r.stdout, "  Removed worktree '%s'.\n", path)
+					deletable = append(deletable, branch)
+					continue
+				}
+				_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("  Warning: %v", err)))
+			}
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render(fmt.Sprintf("  Skipping '%s': it is checked out in the worktree at '%s'. Remove that worktree and run 'so sync' again to delete it.", branch, path)))
+	}
+	return deletable, nil
+}
+
+// planReparenting maps every branch whose parent is about to be deleted to
+// the closest ancestor that is kept.
+func (r *syncCmdRunner) planReparenting(scope *syncScope, branchesToDelete []string) (map[string]string, error) {
+	deleted := make(map[string]bool, len(branchesToDelete))
+	for _, branch := range branchesToDelete {
+		deleted[branch] = true
+	}
+
+	branchUpdates := make(map[string]string)
+	for _, branch := range branchesToDelete {
+		// Get the parent of the branch to be deleted, skipping parents that are deleted as well
+		deletedBranchParent := branch
+		for deleted[deletedBranchParent] {
+			parent, err := git.GetGitConfig(git.BranchConfigKey(deletedBranchParent, "socle-parent"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parent for branch '%s': %w", deletedBranchParent, err)
+			}
+			deletedBranchParent = parent
+		}
+
+		// Find all branches that were tracking this branch
+		for _, child := range scope.branches {
+			if child == branch {
+				continue
+			}
+			if parent, ok := scope.parentMap[child]; ok && parent == branch && !deleted[child] {
+				branchUpdates[child] = deletedBranchParent
+			}
+		}
+	}
+	return branchUpdates, nil
+}
+
+// deleteMergedRemoteBranch removes the remote branch of a merged PR opened
+// with --delete-branch-on-merge. GitHub has usually deleted it already, which
+// is expected rather than a problem. Failures only warn; the PR is merged.
+func (r *syncCmdRunner) deleteMergedRemoteBranch(branch, remoteName string) {
+	exists, err := git.RemoteBranchExists(branch, remoteName)
+	if err != nil {
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("  Warning: could not check '%s' on '%s': %v", branch, remoteName, err)))
+		return
+	}
+	if !exists {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render(fmt.Sprintf("  Remote branch '%s/%s' was deleted on merge, as requested.", remoteName, branch)))
+		return
+	}
+	_, _ = fmt.Fprintf(r.stdout, "Deleting remote branch %s/%s... ", remoteName, branch)
+	if err := git.DeleteRemoteBranch(branch, remoteName); err != nil {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.FailureStyle.Render("Failed"))
+		_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("  Warning: %v", err)))
+		return
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Success"))
+}
+
+// printDeletionPreview lists each candidate with its PR link and the commits
+// that are not in its trunk and would become unreachable once it is deleted.
+func (r *syncCmdRunner) printDeletionPreview(candidates []syncCandidate, scope *syncScope, remoteName string) error {
+	for _, candidate := range candidates {
+		trunkRef := git.ResolveTrunkRef(scope.baseOf[candidate.branch], remoteName)
+		detail := candidate.describe()
+		if candidate.prURL != "" {
+			detail += ": " + candidate.prURL
+		}
+		_, _ = fmt.Fprintf(r.stdout, "  - %s %s\n", candidate.branch, ui.Colors.MutedStyle.Render("("+detail+")"))
+
+		parent, err := git.GetGitConfig(git.BranchConfigKey(candidate.branch, "socle-parent"))
+		if err != nil {
+			return fmt.Errorf("failed to get parent for branch '%s': %w", candidate.branch, err)
+		}
+		if candidate.status == gh.PRStatusMerged {
+			if deleteRemote, _ := git.GetBranchDeleteOnMerge(candidate.branch); deleteRemote {
+				_, _ = fmt.Fprintf(r.stdout, "      %s\n", ui.Colors.MutedStyle.Render("Its remote branch goes too (opened with --delete-branch-on-merge)."))
+			}
+		}
+		commits, err := git.GetUnmergedCommits(parent, candidate.branch, trunkRef)
+		if err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			_, _ = fmt.Fprintf(r.stdout, "      %s\n", ui.Colors.SuccessStyle.Render(fmt.Sprintf("All commits are in '%s'.", trunkRef)))
+			continue
+		}
+		_, _ = fmt.Fprintf(r.stdout, "      %s\n", ui.Colors.WarningStyle.Render(fmt.Sprintf("%d commit(s) not in '%s' would become unreachable:", len(commits), trunkRef)))
+		for _, commit := range commits {
+			_, _ = fmt.Fprintf(r.stdout, "        %s %s\n", ui.Colors.FaintStyle.Render(commit.OID[:8]), commit.Subject)
+		}
+	}
+	return nil
+}
+
+// printDryRunPlan prints the reparenting, trunk update and restack steps sync
+// would perform after deleting the candidates.
+func (r *syncCmdRunner) printDryRunPlan(scope *syncScope, branchUpdates map[string]string, remoteName string) error {
+	_, _ = fmt.Fprintln(r.stdout, "\nReparenting:")
+	if len(branchUpdates) == 0 {
+		_, _ = fmt.Fprintln(r.stdout, "  None.")
+	}
+	for _, branch := range scope.branches {
+		if newParent, ok := branchUpdates[branch]; ok {
+			_, _ = fmt.Fprintf(r.stdout, "  %s: %s -> %s\n", branch, scope.parentMap[branch], newParent)
+		}
+	}
+
+	for _, baseBranch := range scope.bases {
+		_, _ = fmt.Fprintf(r.stdout, "\nTrunk '%s':\n", baseBranch)
+		frozen, isFrozen := git.GetFrozenBase(baseBranch)
+		canFastForward, err := git.CanFastForward(baseBranch, remoteName)
+		switch {
+		case isFrozen:
+			_, _ = fmt.Fprintf(r.stdout, "  Frozen at %s; left alone.\n", frozen.Ref)
+		case err != nil:
+			_, _ = fmt.Fprintf(r.stdout, "  Cannot compare with '%s/%s': %v\n", remoteName, baseBranch, err)
+		case canFastForward:
+			_, _ = fmt.Fprintf(r.stdout, "  Fast-forward to '%s/%s'.\n", remoteName, baseBranch)
+		default:
+			_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render(fmt.Sprintf("  Cannot fast-forward; would reset to '%s/%s', discarding local trunk commits.", remoteName, baseBranch)))
+		}
+	}
+
+	if r.doRestack {
+		_, _ = fmt.Fprintln(r.stdout, "\nRestack the remaining branches onto their parents.")
+	} else {
+		_, _ = fmt.Fprintln(r.stdout, "\nSkip restacking (--no-restack).")
+	}
+
+	_, _ = fmt.Fprintln(r.stdout)
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render("Dry run: nothing was changed."))
+	return nil
+}
@@ -2,11 +2,16 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
 	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
 	"github.com/stretchr/testify/require"
 )
 
@@ -77,3 +82,391 @@ func TestSyncCommand_ReparentedBranchKeepsRemoteTracking(t *testing.T) {
 	parentVal := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "--get", "branch.feature-b.socle-parent"))
 	require.Equal(t, "main", parentVal, "socle parent should update to the deleted branch's parent")
 }
+
+func TestSyncCommand_DeleteBranchOnMerge(t *testing.T) {
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+	defer cleanup()
+	remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+	require.NoError(t, os.MkdirAll(remotePath, 0o755))
+	testutils.RunCommand(t, remotePath, "git", "init", "--bare")
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+	testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "main", "feature-a", "feature-b", "feature-c")
+
+	// feature-a and feature-b were opened with --delete-branch-on-merge;
+	// GitHub already deleted feature-b. feature-c was not.
+	for i, branch := range []string{"feature-a", "feature-b", "feature-c"} {
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+branch+".socle-pr-number", fmt.Sprint(101+i))
+	}
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-delete-on-merge", "true")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-delete-on-merge", "true")
+	testutils.RunCommand(t, repoPath, "git", "push", "--quiet", "origin", "--delete", "feature-b")
+
+	mockClient := gh.NewMockClient()
+	mockClient.PRStatuses[101] = gh.PRStatusMerged
+	mockClient.PRStatuses[102] = gh.PRStatusMerged
+	mockClient.PRStatuses[103] = gh.PRStatusMerged
+	originalCreateGHClient := gh.CreateClient
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return mockClient, nil
+	}
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	stdout, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+	require.NoError(t, err)
+	out := stripAnsi(stdout)
+	require.Contains(t, out, "Deleting remote branch origin/feature-a... Success")
+	require.Contains(t, out, "Remote branch 'origin/feature-b' was deleted on merge, as requested.")
+	require.NotContains(t, out, "origin/feature-c")
+
+	remoteHeads := testutils.RunCommand(t, remotePath, "git", "branch", "--list")
+	require.NotContains(t, remoteHeads, "feature-a")
+	require.Contains(t, remoteHeads, "feature-c", "only branches opened with the option are deleted remotely")
+}
+
+func TestSyncCommand_DryRun(t *testing.T) {
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	defer cleanup()
+	resetCommandFlags(t, syncCmd, "dry-run")
+
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	testutils.RunCommand(t, repoPath, "git", "branch", "origin/main", "main")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+
+	mockClient := gh.NewMockClient()
+	mockClient.PRStatuses[101] = gh.PRStatusClosed
+
+	originalCreateGHClient := gh.CreateClient
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return mockClient, nil
+	}
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	// No --test-no-fetch: a dry run must not reach the (unreachable) remote
+	stdout, _, err := runSoCommandWithOutput(t, "sync", "--dry-run")
+	require.NoError(t, err)
+	stdout = stripAnsi(stdout)
+
+	require.Contains(t, stdout, "Skipping fetch (--dry-run).")
+	require.Contains(t, stdout, "feature-a (PR #101 Closed: https://github.com/mock/mock/pull/101)")
+	require.Contains(t, stdout, "1 commit(s) not in 'origin/main' would become unreachable:")
+	require.Contains(t, stdout, "feat: commit on feature-a")
+	require.Contains(t, stdout, "feature-b: feature-a -> main")
+	require.Contains(t, stdout, "Fast-forward to 'origin/main'.")
+	require.Contains(t, stdout, "Dry run: nothing was changed.")
+
+	// Nothing was deleted or reparented
+	testutils.RunCommand(t, repoPath, "git", "rev-parse", "--verify", "feature-a")
+	parentVal := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "--get", "branch.feature-b.socle-parent"))
+	require.Equal(t, "feature-a", parentVal)
+}
+
+func TestSyncCommand_KeptBranches(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, syncCmd, "include-kept", "no-restack")
+
+	setup := func(t *testing.T) string {
+		resetCommandFlags(t, syncCmd, "include-kept", "no-restack")
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		t.Cleanup(cleanup)
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "branch", "origin/main", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+
+		mockClient := gh.NewMockClient()
+		mockClient.PRStatuses[101] = gh.PRStatusClosed
+		mockClient.PRStatuses[102] = gh.PRStatusMerged
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		return repoPath
+	}
+	branchExists := func(t *testing.T, branch string) bool {
+		exists, err := git.BranchExists(branch)
+		require.NoError(t, err)
+		return exists
+	}
+	parentOf := func(t *testing.T, repoPath, branch string) string {
+		return strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "--get", "branch."+branch+".socle-parent"))
+	}
+
+	t.Run("Deleting consecutive branches reparents onto the closest kept ancestor", func(t *testing.T) {
+		repoPath := setup(t)
+		_, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+		require.NoError(t, err)
+		require.False(t, branchExists(t, "feature-a"))
+		require.False(t, branchExists(t, "feature-b"))
+		require.Equal(t, "main", parentOf(t, repoPath, "feature-c"))
+	})
+
+	t.Run("A branch kept earlier is not offered again", func(t *testing.T) {
+		repoPath := setup(t)
+		require.NoError(t, git.SetSyncKept("feature-a", gh.PRStatusClosed))
+
+		stdout, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+		require.NoError(t, err)
+		require.Contains(t, stripAnsi(stdout), "Keeping 'feature-a' (PR #101 Closed), as chosen in an earlier sync.")
+		require.True(t, branchExists(t, "feature-a"))
+		require.False(t, branchExists(t, "feature-b"))
+		require.Equal(t, "feature-a", parentOf(t, repoPath, "feature-c"))
+	})
+
+	t.Run("A changed PR status or --include-kept offers it again", func(t *testing.T) {
+		setup(t)
+		require.NoError(t, git.SetSyncKept("feature-b", gh.PRStatusClosed)) // Kept while closed, merged since
+
+		_, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+		require.NoError(t, err)
+		require.False(t, branchExists(t, "feature-b"))
+
+		setup(t)
+		require.NoError(t, git.SetSyncKept("feature-a", gh.PRStatusClosed))
+		_, _, err = runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey", "--include-kept")
+		require.NoError(t, err)
+		require.False(t, branchExists(t, "feature-a"))
+	})
+	t.Run("Deleted branches can be restored with their metadata", func(t *testing.T) {
+		repoPath := setup(t)
+		tipB := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-b"))
+		stdout, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+		require.NoError(t, err)
+		require.Contains(t, stripAnsi(stdout), "Deleted branches can be brought back with 'so restore <branch>' for 14 days.")
+		require.False(t, branchExists(t, "feature-b"))
+
+		// feature-a was deleted too, so it comes back first
+		_, _, err = runSoCommandWithOutput(t, "restore", "feature-b")
+		require.ErrorContains(t, err, "restore it first with 'so restore feature-a'")
+		require.False(t, branchExists(t, "feature-b"))
+		require.NoError(t, runSoCommand(t, "restore", "feature-a"))
+
+		stdout, _, err = runSoCommandWithOutput(t, "restore", "feature-b")
+		require.NoError(t, err)
+		require.Contains(t, stripAnsi(stdout), "Restored branch 'feature-b' at "+tipB[:8]+".")
+		require.Contains(t, stripAnsi(stdout), "It is tracked on 'feature-a' again.")
+		require.Equal(t, tipB, strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "feature-b")))
+		require.Equal(t, "feature-a", parentOf(t, repoPath, "feature-b"))
+		prNumber, _ := git.GetStoredPRNumber("feature-b")
+		require.Equal(t, 102, prNumber)
+
+		_, _, err = runSoCommandWithOutput(t, "restore", "feature-b")
+		require.ErrorContains(t, err, "no snapshot or deleted branch named 'feature-b'")
+	})
+
+	t.Run("socle.tombstoneDays=0 keeps no tombstones", func(t *testing.T) {
+		repoPath := setup(t)
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.tombstoneDays", "0")
+		_, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+		require.NoError(t, err)
+
+		_, _, err = runSoCommandWithOutput(t, "restore", "feature-a")
+		require.ErrorContains(t, err, "no snapshot or deleted branch named 'feature-a'")
+	})
+}
+
+func TestSyncCommand_BranchCheckedOutInWorktree(t *testing.T) {
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	defer cleanup()
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	testutils.RunCommand(t, repoPath, "git", "branch", "origin/main", "main")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+
+	// feature-a is checked out in a second worktree
+	worktreePath := filepath.Join(t.TempDir(), "feature-a")
+	testutils.RunCommand(t, repoPath, "git", "worktree", "add", "--quiet", worktreePath, "feature-a")
+
+	mockClient := gh.NewMockClient()
+	mockClient.PRStatuses[101] = gh.PRStatusMerged
+	mockClient.PRStatuses[102] = gh.PRStatusMerged
+	originalCreateGHClient := gh.CreateClient
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return mockClient, nil
+	}
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	stdout, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+	require.NoError(t, err)
+	out := stripAnsi(stdout)
+	require.Contains(t, out, "Skipping 'feature-a': it is checked out in the worktree at")
+	require.Contains(t, out, "Deleting branch feature-b... Success")
+
+	branches := testutils.RunCommand(t, repoPath, "git", "branch", "--list", "feature-*")
+	require.Contains(t, branches, "feature-a")
+	require.NotContains(t, branches, "feature-b")
+	require.Equal(t, "main", strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "--get", "branch.feature-a.socle-parent")))
+}
+
+func TestSyncCommand_ChangelogForMergedStack(t *testing.T) {
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	defer cleanup()
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	testutils.RunCommand(t, repoPath, "git", "branch", "origin/main", "main")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-pr-number", "102")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-stack-name", "Auth rework")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-b.socle-tracking-issue", "50")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.changelog", "both")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.changelogTemplate", "- {title} (#{number}): {summary}")
+
+	mockClient := gh.NewMockClient()
+	mockClient.PRStatuses[101] = gh.PRStatusMerged
+	mockClient.PRStatuses[102] = gh.PRStatusMerged
+	mockClient.On("GetPullRequest", 101).Return(&github.PullRequest{Title: github.Ptr("Add login"), Body: github.Ptr("Adds the login form.\n\nDetails follow.")}, nil)
+	mockClient.On("GetPullRequest", 102).Return(&github.PullRequest{Title: github.Ptr("Add logout"), Body: github.Ptr("Adds logout.")}, nil)
+	want := "### Auth rework\n\n- Add login (#101): Adds the login form.\n- Add logout (#102): Adds logout.\n"
+	mockClient.On("FindCommentWithMarker", 50, changelogMarker).Return(int64(0), nil)
+	mockClient.On("CreateComment", 50, changelogMarker+"\n"+want).Return(&github.IssueComment{}, nil)
+	originalCreateGHClient := gh.CreateClient
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return mockClient, nil
+	}
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	stdout, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+	require.NoError(t, err)
+	out := stripAnsi(stdout)
+	require.Contains(t, out, "Wrote the changelog snippet of 'Auth rework'")
+	require.Contains(t, out, "Posted the changelog snippet on #50")
+	mockClient.AssertExpectations(t)
+
+	content, err := os.ReadFile(filepath.Join(repoPath, "changelog.d", "Auth-rework.md"))
+	require.NoError(t, err)
+	require.Equal(t, want, string(content))
+}
+
+func TestSyncCommand_ChangelogWaitsForDeletion(t *testing.T) {
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+	defer cleanup()
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	testutils.RunCommand(t, repoPath, "git", "branch", "origin/main", "main")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-pr-number", "101")
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.changelog", "file")
+
+	mockClient := gh.NewMockClient()
+	mockClient.PRStatuses[101] = gh.PRStatusMerged
+	mockClient.On("GetPullRequest", 101).Return(&github.PullRequest{Title: github.Ptr("Add login")}, nil)
+	originalCreateGHClient := gh.CreateClient
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return mockClient, nil
+	}
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, syncCmd, "test-no-survey", "no-restack")
+
+	// Without a prompt the deletion is skipped, so the stack is not done yet.
+	stdout, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--non-interactive")
+	require.NoError(t, err)
+	require.NotContains(t, stripAnsi(stdout), "Wrote the changelog snippet")
+	require.NoDirExists(t, filepath.Join(repoPath, "changelog.d"))
+
+	stdout, _, err = runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+	require.NoError(t, err)
+	require.Contains(t, stripAnsi(stdout), "Wrote the changelog snippet of 'feature-a'")
+	require.FileExists(t, filepath.Join(repoPath, "changelog.d", "feature-a.md"))
+}
+
+func TestSyncCommand_All(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	resetCommandFlags(t, syncCmd, "all", "no-restack", "dry-run")
+
+	// Stacks main/feature-a/feature-b, main/feature-x/feature-y and
+	// develop/dev-a/dev-b; main moves on after they were created
+	repoPath, cleanup := setupRepoWithMultipleStacks(t)
+	defer cleanup()
+	testutils.RunCommand(t, repoPath, "git", "config", "--local", "socle.baseBranches", "main,develop")
+	testutils.RunCommand(t, repoPath, "git", "branch", "develop", "main")
+	for _, branch := range []string{"dev-a", "dev-b"} {
+		parent := map[string]string{"dev-a": "develop", "dev-b": "dev-a"}[branch]
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "-b", branch, parent)
+		writeFile(t, repoPath, branch+".txt", branch)
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "feat: commit on "+branch)
+		trackBranch(t, repoPath, branch, parent, "develop")
+	}
+	testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+	writeFile(t, repoPath, "main.txt", "moved on")
+	testutils.RunCommand(t, repoPath, "git", "add", ".")
+	testutils.RunCommand(t, repoPath, "git", "commit", "-q", "-m", "main moves on")
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	testutils.RunCommand(t, repoPath, "git", "branch", "origin/main", "main")
+	testutils.RunCommand(t, repoPath, "git", "branch", "origin/develop", "develop")
+	for branch, number := range map[string]string{"feature-a": "101", "feature-x": "201", "dev-a": "301", "dev-b": "302"} {
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+branch+".socle-pr-number", number)
+	}
+	testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+
+	mockClient := gh.NewMockClient()
+	mockClient.PRStatuses[101] = gh.PRStatusMerged
+	mockClient.PRStatuses[201] = gh.PRStatusOpen
+	mockClient.PRStatuses[301] = gh.PRStatusMerged
+	mockClient.PRStatuses[302] = gh.PRStatusOpen
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return mockClient, nil
+	}
+
+	stdout, _, err := runSoCommandWithOutput(t, "sync", "--all", "--test-no-fetch", "--dry-run")
+	require.NoError(t, err)
+	output := stripAnsi(stdout)
+	require.Contains(t, output, "Found Merged PR #101 for branch 'feature-a'")
+	require.Contains(t, output, "Found Merged PR #301 for branch 'dev-a'")
+	require.Contains(t, output, "feature-b: feature-a -> main")
+	require.Contains(t, output, "dev-b: dev-a -> develop")
+	require.Contains(t, output, "Trunk 'main':")
+	require.Contains(t, output, "Trunk 'develop':")
+	resetCommandFlags(t, syncCmd, "all", "no-restack", "dry-run")
+
+	stdout, _, err = runSoCommandWithOutput(t, "sync", "--all", "--test-no-fetch", "--test-no-survey")
+	require.NoError(t, err)
+	require.Contains(t, stripAnsi(stdout), "Sync completed successfully.")
+
+	for _, branch := range []string{"feature-a", "dev-a"} {
+		exists, err := git.BranchExists(branch)
+		require.NoError(t, err)
+		require.False(t, exists, "'%s' was landed and is deleted", branch)
+	}
+	for _, step := range [][2]string{{"main", "feature-b"}, {"main", "feature-x"}, {"feature-x", "feature-y"}, {"develop", "dev-b"}} {
+		parent := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "--get", "branch."+step[1]+".socle-parent"))
+		require.Equal(t, step[0], parent)
+		onParent, err := git.IsAncestor(step[0], step[1])
+		require.NoError(t, err)
+		require.True(t, onParent, "'%s' is restacked onto '%s'", step[1], step[0])
+	}
+	current, err := git.GetCurrentBranch()
+	require.NoError(t, err)
+	require.Equal(t, "feature-b", current)
+}
+
+func TestSyncCommand_BranchesLandedWithoutPR(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return gh.NewMockClient(), nil
+	}
+
+	repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+	defer cleanup()
+	testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+	// feature-a was merged by hand and has no PR; fresh has no commits yet
+	testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "main")
+	testutils.RunCommand(t, repoPath, "git", "merge", "-q", "--no-ff", "-m", "Merge feature-a", "feature-a")
+	testutils.RunCommand(t, repoPath, "git", "branch", "origin/main", "main")
+	testutils.RunCommand(t, repoPath, "git", "branch", "fresh", "feature-b")
+	trackBranch(t, repoPath, "fresh", "feature-b", "main")
+	testutils.RunCommand(t, repoPath, "git", "checkout", "-q", "feature-b")
+
+	stdout, _, err := runSoCommandWithOutput(t, "sync", "--test-no-fetch", "--no-restack", "--test-no-survey")
+	require.NoError(t, err)
+	output := stripAnsi(stdout)
+	require.Contains(t, output, "Found branch 'feature-a' merged into 'origin/main' without a merged PR")
+	require.Contains(t, output, "- feature-a (merged into 'origin/main')")
+
+	exists, err := git.BranchExists("feature-a")
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = git.BranchExists("fresh")
+	require.NoError(t, err)
+	require.True(t, exists, "a branch without commits of its own has not landed")
+	parent := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "--get", "branch.feature-b.socle-parent"))
+	require.Equal(t, "main", parent)
+}
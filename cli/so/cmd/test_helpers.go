@@ -2,17 +2,31 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testmode"
 	"github.com/benekuehn/socle/cli/so/internal/testutils"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
 
+func init() {
+	// Tests reuse PR numbers across repositories, so a cached status would
+	// leak from one test into the next. Tests of the cache point it at a
+	// file of their own.
+	gh.StatusCachePath = func() (string, error) {
+		return "", errors.New("PR status cache disabled in tests")
+	}
+}
+
 // setupRepoWithStack creates a git repository with a stack of branches
 func setupRepoWithStack(t *testing.T, branches []string) (repoPath string, cleanup func()) {
 	t.Helper()
@@ -52,9 +66,10 @@ func runSoCommandWithOutput(t *testing.T, args ...string) (stdout, stderr string
 	testRootCmd.SetOut(&outBuf)
 	testRootCmd.SetErr(&errBuf)
 	testRootCmd.SetArgs(args)
+	addPluginCommand(testRootCmd, args)
 
 	t.Logf("Executing 'so %s'", strings.Join(args, " "))
-	err = testRootCmd.Execute()
+	err = executeWithProfile(context.Background(), testRootCmd)
 	t.Logf("Execution finished, returned error: %v", err)
 
 	stdout = outBuf.String()
@@ -69,7 +84,9 @@ func runSoCommandWithOutput(t *testing.T, args ...string) (stdout, stderr string
 // writeFile writes content to a file in the given directory
 func writeFile(t *testing.T, dir, filename, content string) {
 	t.Helper()
-	err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0644)
+	path := filepath.Join(dir, filename)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755), "Failed to create directory for %s", filename)
+	err := os.WriteFile(path, []byte(content), 0644)
 	require.NoError(t, err, "Failed to write file %s", filename)
 }
 
@@ -84,12 +101,35 @@ func readFile(t *testing.T, dir, filename string) string {
 // trackBranch sets socle tracking metadata for a branch
 func trackBranch(t *testing.T, repoPath, branch, parent, base string) {
 	t.Helper()
-	parentKey := fmt.Sprintf("branch.%s.socle-parent", branch)
-	baseKey := fmt.Sprintf("branch.%s.socle-base", branch)
+	parentKey := git.BranchConfigKey(branch, "socle-parent")
+	baseKey := git.BranchConfigKey(branch, "socle-base")
 	testutils.RunCommand(t, repoPath, "git", "config", "--local", parentKey, parent)
 	testutils.RunCommand(t, repoPath, "git", "config", "--local", baseKey, base)
 }
 
+// resetCommandFlags puts the named flags of cmd back to their defaults now and
+// when the test ends, as flag values outlive a single run of the command
+func resetCommandFlags(t *testing.T, cmd *cobra.Command, names ...string) {
+	t.Helper()
+	reset := func() {
+		for _, name := range names {
+			f := cmd.Flags().Lookup(name)
+			if f == nil {
+				f = cmd.PersistentFlags().Lookup(name)
+			}
+			require.NotNil(t, f, "Unknown flag --%s on %s", name, cmd.Name())
+			if sv, ok := f.Value.(interface{ Replace([]string) error }); ok {
+				require.NoError(t, sv.Replace(nil))
+			} else {
+				require.NoError(t, f.Value.Set(f.DefValue))
+			}
+			f.Changed = false
+		}
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
 // setupRepoWithMultipleStacks creates a git repository with multiple stacks from main
 // Creates two stacks: main->feature-a->feature-b and main->feature-x->feature-y
 func setupRepoWithMultipleStacks(t *testing.T) (repoPath string, cleanup func()) {
@@ -131,6 +171,27 @@ func setupRepoWithMultipleStacks(t *testing.T) (repoPath string, cleanup func())
 	return repoPath, cleanup
 }
 
+// setupRepoWithForkedStack creates a git repository whose stack forks on a
+// non-base branch: main->feat-a->feat-b and main->feat-a->feat-c->feat-d.
+// feat-a is checked out afterwards.
+func setupRepoWithForkedStack(t *testing.T) (repoPath string, cleanup func()) {
+	t.Helper()
+	repoPath, cleanup = testutils.SetupGitRepo(t)
+
+	for _, b := range [][2]string{{"feat-a", "main"}, {"feat-b", "feat-a"}, {"feat-c", "feat-a"}, {"feat-d", "feat-c"}} {
+		branch, parent := b[0], b[1]
+		testutils.RunCommand(t, repoPath, "git", "checkout", parent)
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", branch)
+		writeFile(t, repoPath, branch+".txt", branch)
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "feat: commit on "+branch)
+		trackBranch(t, repoPath, branch, parent, "main")
+	}
+	testutils.RunCommand(t, repoPath, "git", "checkout", "feat-a")
+
+	return repoPath, cleanup
+}
+
 // runSoCommand executes a so command
 func runSoCommand(t *testing.T, args ...string) error {
 	t.Helper()
@@ -142,7 +203,7 @@ func runSoCommand(t *testing.T, args ...string) error {
 	testRootCmd.SetArgs(args)
 
 	t.Logf("Executing 'so %s'", strings.Join(args, " "))
-	err = testRootCmd.Execute()
+	err = executeWithProfile(context.Background(), testRootCmd)
 	t.Logf("Execution finished, returned error: %v", err)
 	return err
 }
@@ -156,13 +217,38 @@ func runSoCommand(t *testing.T, args ...string) error {
 func initializeCobraAppForTest() (*cobra.Command, error) {
 	var testDebugLogging bool
 	nonInteractive = false
+	profileOutput = false
+	testSelectStackIndex = -1
+	testSelectStackChild = ""
 	testSelectStackIndexTop = -1
 	testSelectStackChildTop = ""
 	testSelectStackIndexBottom = -1
 	testSelectStackChildBottom = ""
-	testRootCmd := &cobra.Command{Use: "so", SilenceErrors: true, SilenceUsage: true}
+	testRootCmd := &cobra.Command{
+		Use:           "so",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Annotations[annotationNoRepo] == "true" {
+				return nil
+			}
+			if testmode.Enabled() {
+				if err := testmode.IsolateGit(); err != nil {
+					return err
+				}
+				if err := enableTestMode(); err != nil {
+					return err
+				}
+			}
+			if err := migrateMetadata(cmd); err != nil {
+				return err
+			}
+			return git.ValidateSocleConfig()
+		},
+	}
 	testRootCmd.PersistentFlags().BoolVar(&testDebugLogging, "debug", false, "Enable debug logging output")
 	testRootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Disable interactive prompts")
+	testRootCmd.PersistentFlags().BoolVar(&profileOutput, "profile", false, "Report time spent per operation")
 	addCmd := func(c *cobra.Command) { testRootCmd.AddCommand(c) }
 	addCmd(trackCmd)
 	addCmd(logCmd)
@@ -175,6 +261,38 @@ func initializeCobraAppForTest() (*cobra.Command, error) {
 	addCmd(downCmd)
 	addCmd(untrackCmd)
 	addCmd(syncCmd)
+	addCmd(prCmd)
+	addCmd(landCmd)
+	addCmd(etaCmd)
+	addCmd(mergeCmd)
+	addCmd(snapshotCmd)
+	addCmd(restoreCmd)
+	addCmd(wipCmd)
+	addCmd(migrateBaseCmd)
+	addCmd(graphCmd)
+	addCmd(tutorialCmd)
+	addCmd(sliceCmd)
+	addCmd(splitCmd)
+	addCmd(doctorCmd)
+	addCmd(commentCmd)
+	addCmd(configCmd)
+	addCmd(annotateCmd)
+	addCmd(adoptUpstreamCmd)
+	addCmd(backportCmd)
+	addCmd(stackCmd)
+	addCmd(openCmd)
+	addCmd(describeCmd)
+	addCmd(shipCmd)
+	addCmd(mirrorCmd)
+	addCmd(reviewCmd)
+	addCmd(pluginsCmd)
+	addCmd(uiCmd)
+	addCmd(renameCmd)
+	addCmd(wsCmd)
+	addCmd(continueCmd)
+	addCmd(amendCmd)
+	addCmd(squashCmd)
+	addCmd(stacksCmd)
 	testRootCmd.Flags().AddFlagSet(trackCmd.Flags())
 	return testRootCmd, nil
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testmode"
+)
+
+// enableTestMode switches a repository command to the isolated test
+// backend (see package testmode): the repository must be a temporary one,
+// and GitHub is a fake whose state persists in the git directory, so one
+// smoke test can chain several 'so' invocations.
+func enableTestMode() error {
+	slog.Debug("Test mode enabled")
+	root, err := git.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("test mode: %w", err)
+	}
+	if err := testmode.CheckRepo(root); err != nil {
+		return err
+	}
+	gitDir, err := git.RunGitCommand("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return fmt.Errorf("test mode: %w", err)
+	}
+	statePath := testmode.APIStatePath(gitDir)
+	// Fake PRs are numbered from 1 in every sandbox; keep their cached
+	// statuses with the sandbox instead of the user's cache.
+	gh.StatusCachePath = func() (string, error) {
+		return filepath.Join(gitDir, "socle-pr-status.json"), nil
+	}
+
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		api := gh.NewFakeServer(owner, repo)
+		if err := api.Persist(statePath); err != nil {
+			api.Close()
+			return nil, err
+		}
+		// The server lives as long as the process; each command creates
+		// at most a few clients.
+		return api.Client(ctx), nil
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestMode(t *testing.T) {
+	originalCreateGHClient := gh.CreateClient
+	t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+	t.Run("Submit runs against the persisted fake API with scripted answers", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		require.NoError(t, os.MkdirAll(remotePath, 0o755))
+		testutils.RunCommand(t, remotePath, "git", "init", "--bare")
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+
+		keepGitEnv(t)
+		answers := filepath.Join(t.TempDir(), "answers")
+		// An empty line accepts the default title; then decline editing the body.
+		require.NoError(t, os.WriteFile(answers, []byte("\nn\n"), 0o644))
+		t.Setenv("SOCLE_TEST_MODE", "1")
+		t.Setenv("SOCLE_TEST_ANSWERS", answers)
+
+		_, _, err := runSoCommandWithOutput(t, "submit")
+		require.NoError(t, err)
+
+		remaining, err := os.ReadFile(answers)
+		require.NoError(t, err)
+		assert.Empty(t, string(remaining), "both answers were consumed")
+		assert.Equal(t, "1", strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "config", "branch.feature-a.socle-pr-number")))
+
+		data, err := os.ReadFile(filepath.Join(repoPath, ".git", "socle-test-api.json"))
+		require.NoError(t, err)
+		var state struct {
+			PullRequests []struct {
+				Number int    `json:"number"`
+				Title  string `json:"title"`
+			} `json:"pull_requests"`
+		}
+		require.NoError(t, json.Unmarshal(data, &state))
+		require.Len(t, state.PullRequests, 1)
+		assert.Equal(t, "feat: commit on feature-a", state.PullRequests[0].Title)
+
+		// A later invocation finds the PR in the saved state instead of
+		// opening another one, and has no prompt left to answer.
+		_, _, err = runSoCommandWithOutput(t, "submit")
+		require.NoError(t, err)
+		data, err = os.ReadFile(filepath.Join(repoPath, ".git", "socle-test-api.json"))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &state))
+		assert.Len(t, state.PullRequests, 1)
+	})
+
+	t.Run("Generated PR details keep the branch description", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		remotePath := filepath.Join(t.TempDir(), "test-owner", "test-repo.git")
+		require.NoError(t, os.MkdirAll(remotePath, 0o755))
+		testutils.RunCommand(t, remotePath, "git", "init", "--bare")
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", remotePath)
+		require.NoError(t, git.SetBranchDescription("feature-a", "Written with so describe."))
+		testutils.RunCommand(t, repoPath, "git", "config", "socle.messageGenerator", "printf 'feat: generated title\\n\\ngenerated body'")
+
+		keepGitEnv(t)
+		answers := filepath.Join(t.TempDir(), "answers")
+		// Accept generating, accept the title, then decline editing the body.
+		require.NoError(t, os.WriteFile(answers, []byte("\n\nn\n"), 0o644))
+		t.Setenv("SOCLE_TEST_MODE", "1")
+		t.Setenv("SOCLE_TEST_ANSWERS", answers)
+
+		_, _, err := runSoCommandWithOutput(t, "submit")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(repoPath, ".git", "socle-test-api.json"))
+		require.NoError(t, err)
+		var state struct {
+			PullRequests []struct {
+				Title string `json:"title"`
+				Body  string `json:"body"`
+			} `json:"pull_requests"`
+		}
+		require.NoError(t, json.Unmarshal(data, &state))
+		require.Len(t, state.PullRequests, 1)
+		assert.Equal(t, "feat: generated title", state.PullRequests[0].Title)
+		assert.Contains(t, state.PullRequests[0].Body, "Written with so describe.")
+		assert.NotContains(t, state.PullRequests[0].Body, "generated body")
+	})
+
+	t.Run("Prompts fail once the script runs out", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		keepGitEnv(t)
+		answers := filepath.Join(t.TempDir(), "answers")
+		require.NoError(t, os.WriteFile(answers, nil, 0o644))
+		t.Setenv("SOCLE_TEST_MODE", "1")
+		t.Setenv("SOCLE_TEST_ANSWERS", answers)
+
+		_, _, err := runSoCommandWithOutput(t, "submit", "--no-push")
+		require.ErrorContains(t, err, "no scripted answer left")
+	})
+}
+
+// keepGitEnv makes t.Setenv restore the variables test mode exports for git.
+func keepGitEnv(t *testing.T) {
+	for _, key := range []string{"GIT_CONFIG_NOSYSTEM", "GIT_CONFIG_GLOBAL", "GIT_TERMINAL_PROMPT",
+		"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, os.Getenv(key))
+	}
+}
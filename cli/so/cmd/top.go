@@ -19,7 +19,8 @@ var topCmd = &cobra.Command{
 The stack is determined by the tracking information set via 'so track'.
 This command finds the last branch in the sequence starting from the base branch.
 
-If you are on a base branch with multiple stacks, you will be prompted to select which stack to navigate to the top of.`,
+If you are on a base branch with multiple stacks, you will be prompted to select which stack to navigate to the top of.
+Where the stack forks on the way to the tip, you will be prompted to select which branch to follow.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		logger := slog.Default()
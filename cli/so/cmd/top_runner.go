@@ -22,7 +22,7 @@ type topCmdRunner struct {
 }
 
 func (r *topCmdRunner) run() error {
-	stackInfo, err := git.GetStackInfo()
+	stackInfo, err := git.GetNavigationStackInfo()
 	if err != nil {
 		_, _ = fmt.Fprintf(r.stdout, "Error getting stack info: %s\n", err)
 		return nil
@@ -68,20 +68,28 @@ func (r *topCmdRunner) run() error {
 		return checkoutBranch(branch, stackInfo.CurrentBranch)
 	}
 
-	// CASE 3: Standard linear stack
-	branch, msg, navErr := cmdutils.ComputeLinearTarget(stackInfo.CurrentBranch, stackInfo.FullStack, cmdutils.PurposeTop)
-	if navErr != nil {
-		return navErr
+	// CASE 3: Standard stack; the walk to the tip asks at every fork on the way
+	branch, err := r.childSelector().walkToTip(stackInfo.FullStack[len(stackInfo.FullStack)-1], stackInfo.ChildMap)
+	if err != nil {
+		return err
 	}
-	if branch == "" {
-		if msg != "" {
-			_, _ = fmt.Fprintf(r.stdout, "%s\n", msg)
-		}
+	if branch == stackInfo.CurrentBranch {
+		_, _ = fmt.Fprintf(r.stdout, "Already on the top branch: '%s'\n", branch)
 		return nil
 	}
 	return checkoutBranch(branch, stackInfo.CurrentBranch)
 }
 
+func (r *topCmdRunner) childSelector() childSelector {
+	return childSelector{
+		stdin:          r.stdin,
+		stderr:         r.stderr,
+		nonInteractive: r.nonInteractive,
+		testIndex:      testSelectStackIndexTop,
+		testChild:      testSelectStackChildTop,
+	}
+}
+
 func (r *topCmdRunner) promptSelectStack(baseBranch string, purpose cmdutils.NavigationPurpose) (string, bool, error) {
 	options, stacks, err := cmdutils.BuildStackSelectionOptions(baseBranch, purpose)
 	if err != nil {
@@ -93,7 +101,7 @@ func (r *topCmdRunner) promptSelectStack(baseBranch string, purpose cmdutils.Nav
 	}
 	var selectedOption string
 	prompt := &survey.Select{Message: fmt.Sprintf("Multiple stacks available from '%s'. Select a stack to go to the top of:", baseBranch), Options: options}
-	err = survey.AskOne(prompt, &selectedOption, survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File)))
+	err = ui.AskOne(prompt, &selectedOption, survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File)))
 	if err != nil {
 		return "", true, ui.HandleSurveyInterrupt(err, "Navigation cancelled.")
 	}
@@ -154,4 +154,19 @@ func TestTopCommand(t *testing.T) {
 		assert.Contains(t, err.Error(), "multiple stacks found from base branch")
 	})
 
+	t.Run("Follows the selected branch where the stack forks", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithForkedStack(t)
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "main")
+		_, _, err := runSoCommandWithOutput(t, "up")
+		require.NoError(t, err)
+
+		_, stderr, err := runSoCommandWithOutput(t, "top", "--test-select-stack-child=feat-c")
+		require.NoError(t, err)
+		assert.Empty(t, stderr)
+		currentBranch, gitErr := git.GetCurrentBranch()
+		require.NoError(t, gitErr)
+		assert.Equal(t, "feat-d", currentBranch)
+	})
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 
@@ -11,9 +12,27 @@ var trackCmd = &cobra.Command{
 	Use:   "track",
 	Short: "Start tracking the current branch as part of a stack",
 	Long: `Associates the current branch with a parent branch to define its position
-within a stack. This allows 'socle show' to display the specific stack you are on.`,
+within a stack. This allows 'socle show' to display the specific stack you are on.
+
+With --base-ref, the branch is stacked directly on a tag or commit instead of
+a moving branch, e.g. a hotfix cut from a release tag:
+
+  so track --base-ref v2.3.0 --target release/2.3
+
+socle keeps the base as a local branch 'frozen/<ref>' that restack and sync
+never move, and 'so submit' opens the bottom PR against --target (the
+remote's default branch if omitted).
+
+Branches matching a pattern in .socle-ignore (one glob per line at the top of
+the work tree, e.g. 'release/*' or 'dependabot/*') or in socle.ignoreBranches
+cannot be tracked, are not offered as parents and are left out of the stacks
+listed on a base branch. '*' also matches '/', as in 'git branch --list'.
+
+In repositories with more than 100 branches, the parent picker lists the base
+branches and the most recently committed ones; 'Another branch...' asks for
+any other by name, completing it with Tab.`,
 	Args: cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
 		logger := slog.Default()
 
 		discoverRemote, err := cmd.Flags().GetBool("discover")
@@ -21,6 +40,12 @@ within a stack. This allows 'socle show' to display the specific stack you are o
 			return err
 		}
 
+		baseRef, _ := cmd.Flags().GetString("base-ref")
+		target, _ := cmd.Flags().GetString("target")
+		if target != "" && baseRef == "" {
+			return fmt.Errorf("--target requires --base-ref")
+		}
+
 		runner := &trackCmdRunner{
 			ctx:    cmd.Context(),
 			logger: logger,
@@ -29,12 +54,14 @@ within a stack. This allows 'socle show' to display the specific stack you are o
 			stdin:  os.Stdin,
 
 			discoverRemote:     discoverRemote,
+			baseRef:            baseRef,
+			target:             target,
 			testSelectedParent: cmd.Flag("test-parent").Value.String(),
 			testAssumeBase:     cmd.Flag("test-base").Value.String(),
 		}
 
 		return runner.run()
-	},
+	}),
 }
 
 const defaultBaseBranch = "main"
@@ -43,6 +70,8 @@ func init() {
 	AddCommand(trackCmd)
 	trackCmd.Flags().String("test-parent", "", "Parent branch to select (for testing only)")
 	trackCmd.Flags().String("test-base", "", "Base branch to assume if parent is untracked (for testing only)")
+	trackCmd.Flags().String("base-ref", "", "Stack the branch on a tag or commit that never moves")
+	trackCmd.Flags().String("target", "", "Branch PRs on a --base-ref stack target (default: the remote's default branch)")
 	trackCmd.Flags().BoolP("discover", "d", false, "Discover remote metadata (e.g. existing pull requests) while tracking")
 	_ = trackCmd.Flags().MarkHidden("test-parent")
 	_ = trackCmd.Flags().MarkHidden("test-base")
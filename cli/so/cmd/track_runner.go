@@ -7,6 +7,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/benekuehn/socle/cli/so/internal/gh"
@@ -23,6 +24,8 @@ type trackCmdRunner struct {
 	stdin  io.Reader
 
 	discoverRemote bool
+	baseRef        string // Stack on a frozen base at this tag or commit
+	target         string // Branch PRs on the frozen base target
 
 	// Test flags
 	testSelectedParent string
@@ -41,16 +44,24 @@ func (r *trackCmdRunner) run() error {
 		return fmt.Errorf("failed to get current branch: %w", err)
 	}
 	// Basic check: Don't track base branches like main/master/develop
-	knownBases := map[string]bool{"main": true, "master": true, "develop": true} // TODO: Configurable
+	knownBases := git.KnownBaseBranchSet()
 	if knownBases[currentBranch] {
 		return fmt.Errorf("cannot track a base branch ('%s') itself", currentBranch)
 	}
 
+	ignore, err := git.LoadBranchIgnoreRules()
+	if err != nil {
+		return err
+	}
+	if pattern, ignored := ignore.Match(currentBranch); ignored {
+		return fmt.Errorf("branch '%s' matches the ignore rule '%s' (%s or socle.ignoreBranches) and cannot be tracked", currentBranch, pattern, git.BranchIgnoreFile)
+	}
+
 	// 2. Check if already tracked
-	parentConfigKey := fmt.Sprintf("branch.%s.socle-parent", currentBranch)
+	parentConfigKey := git.BranchConfigKey(currentBranch, "socle-parent")
 	existingParent, errGetParent := git.GetGitConfig(parentConfigKey)
 	if errGetParent == nil && existingParent != "" {
-		baseConfigKey := fmt.Sprintf("branch.%s.socle-base", currentBranch)
+		baseConfigKey := git.BranchConfigKey(currentBranch, "socle-base")
 		existingBase, _ := git.GetGitConfig(baseConfigKey)
 		_, _ = fmt.Fprintf(r.stdout, "Branch '%s' is already tracked.\n", currentBranch)
 		_, _ = fmt.Fprintf(r.stdout, "  Parent: %s\n", existingParent)
@@ -60,17 +71,14 @@ func (r *trackCmdRunner) run() error {
 		return fmt.Errorf("failed to check tracking status for branch '%s': %w", currentBranch, errGetParent) // Use actual error
 	}
 
-	// 3. Get potential parent branches
-	allBranches, err := git.GetLocalBranches()
-	if err != nil {
-		return fmt.Errorf("failed to list local branches: %w", err)
+	if r.baseRef != "" {
+		return r.trackOnFrozenBase(currentBranch)
 	}
 
-	potentialParents := []string{}
-	for _, b := range allBranches {
-		if b != currentBranch {
-			potentialParents = append(potentialParents, b)
-		}
+	// 3. Get potential parent branches
+	potentialParents, truncated, err := parentCandidates(currentBranch, ignore)
+	if err != nil {
+		return err
 	}
 
 	if len(potentialParents) == 0 {
@@ -122,6 +130,9 @@ func (r *trackCmdRunner) run() error {
 				break
 			}
 		}
+		if !found && truncated {
+			found, _ = git.BranchExists(r.testSelectedParent)
+		}
 		if !found && !knownBases[r.testSelectedParent] {
 			return fmt.Errorf("invalid test parent '%s': not found in potential parents %v or known bases", r.testSelectedParent, potentialParents)
 		}
@@ -139,15 +150,24 @@ func (r *trackCmdRunner) run() error {
 			// Use runner's stdio
 			surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
 			r.logger.Debug("Prompting user for parent branch")
-			prompt := &survey.Select{Message: fmt.Sprintf("Select the parent branch for '%s':", currentBranch), Options: potentialParents}
+			options := potentialParents
+			if truncated {
+				options = append(options, otherParentOption)
+			}
+			prompt := &survey.Select{Message: fmt.Sprintf("Select the parent branch for '%s':", currentBranch), Options: options}
 			if defaultParent != "" {
 				prompt.Default = defaultParent
 			}
-			err := survey.AskOne(prompt, &selectedParent, surveyOpts)
+			err := ui.AskOne(prompt, &selectedParent, surveyOpts)
 			if err != nil {
 				// Use ui.HandleSurveyInterrupt which should be in internal/ui
 				return ui.HandleSurveyInterrupt(err, "Track command cancelled.")
 			}
+			if selectedParent == otherParentOption {
+				if selectedParent, err = askParentByName(currentBranch, ignore, surveyOpts); err != nil {
+					return err
+				}
+			}
 			r.logger.Debug("Parent selected via prompt", "selectedParent", selectedParent)
 		}
 	}
@@ -157,7 +177,7 @@ func (r *trackCmdRunner) run() error {
 	if knownBases[selectedParent] {
 		selectedBase = selectedParent
 	} else {
-		parentBaseKey := fmt.Sprintf("branch.%s.socle-base", selectedParent)
+		parentBaseKey := git.BranchConfigKey(selectedParent, "socle-base")
 		inheritedBase, errGetBase := git.GetGitConfig(parentBaseKey)
 		if errGetBase == nil && inheritedBase != "" {
 			selectedBase = inheritedBase
@@ -191,7 +211,7 @@ func (r *trackCmdRunner) run() error {
 		return fmt.Errorf("failed to set socle-parent config: %w", err)
 	}
 
-	baseConfigKey := fmt.Sprintf("branch.%s.socle-base", currentBranch)
+	baseConfigKey := git.BranchConfigKey(currentBranch, "socle-base")
 	err = git.SetGitConfig(baseConfigKey, selectedBase)
 	if err != nil {
 		_ = git.UnsetGitConfig(parentConfigKey)
@@ -225,6 +245,45 @@ func (r *trackCmdRunner) run() error {
 	return nil
 }
 
+// trackOnFrozenBase tracks currentBranch directly on the frozen base for
+// r.baseRef, creating it if needed.
+func (r *trackCmdRunner) trackOnFrozenBase(currentBranch string) error {
+	target := r.target
+	if target == "" {
+		remoteName := git.GetRemoteName()
+		if defaultBranch, err := git.GetRemoteDefaultBranch(remoteName); err == nil && defaultBranch != "" {
+			target = defaultBranch
+		} else {
+			target = defaultBaseBranch
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: could not determine the default branch of '%s'; PRs will target '%s'. Use --target to choose.", remoteName, target)))
+		}
+	}
+
+	base, err := git.CreateFrozenBase(r.baseRef, target)
+	if err != nil {
+		return err
+	}
+	contains, err := git.IsAncestor(base.Branch, currentBranch)
+	if err != nil {
+		return err
+	}
+	if !contains {
+		return fmt.Errorf("'%s' is not based on '%s'; create it from there first, e.g. 'git switch -c <branch> %s'", currentBranch, r.baseRef, r.baseRef)
+	}
+
+	_, _ = fmt.Fprintf(r.stdout, "Tracking branch '%s' on '%s' (frozen at %s, PRs target '%s').\n", currentBranch, base.Branch, base.Ref, base.Target)
+	parentConfigKey := git.BranchConfigKey(currentBranch, "socle-parent")
+	if err := git.SetGitConfig(parentConfigKey, base.Branch); err != nil {
+		return fmt.Errorf("failed to set socle-parent config: %w", err)
+	}
+	if err := git.SetGitConfig(git.BranchConfigKey(currentBranch, "socle-base"), base.Branch); err != nil {
+		_ = git.UnsetGitConfig(parentConfigKey)
+		return fmt.Errorf("failed to set socle-base config: %w", err)
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("Branch tracking information saved successfully."))
+	return nil
+}
+
 type remoteDiscoveryResult struct {
 	remoteName string
 	remoteURL  string
@@ -235,8 +294,8 @@ type remoteDiscoveryResult struct {
 }
 
 func (r *trackCmdRunner) discoverRemoteInfo(branch string) (*remoteDiscoveryResult, error) {
-	remoteName := "origin"
-	remoteKey := fmt.Sprintf("branch.%s.remote", branch)
+	remoteName := git.GetRemoteName()
+	remoteKey := git.BranchConfigKey(branch, "remote")
 	if remoteConfig, err := git.GetGitConfig(remoteKey); err == nil && remoteConfig != "" {
 		remoteName = remoteConfig
 	} else if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
@@ -304,3 +363,81 @@ func (r *trackCmdRunner) emitDiscoverySummary(branch string, discovery *remoteDi
 		)))
 	}
 }
+
+// parentPickerLimit is how many branches the parent picker lists. Beyond it,
+// the picker offers the base branches and the most recently committed ones,
+// and finds any other branch by name.
+const parentPickerLimit = 100
+
+// otherParentOption is the picker entry that asks for the parent by name.
+const otherParentOption = "Another branch..."
+
+// parentCandidates lists the branches the parent picker offers for branch.
+// With more than parentPickerLimit of them it stops reading, offers the
+// existing base branches and the most recently committed branches instead,
+// and reports the list as truncated.
+func parentCandidates(branch string, ignore *git.BranchIgnoreRules) ([]string, bool, error) {
+	var candidates []string
+	truncated := false
+	err := git.ForEachLocalBranch(nil, func(name string) bool {
+		if name == branch || ignore.Ignored(name) {
+			return true
+		}
+		if len(candidates) == parentPickerLimit {
+			truncated = true
+			return false
+		}
+		candidates = append(candidates, name)
+		return true
+	})
+	if err != nil || !truncated {
+		return candidates, false, err
+	}
+
+	candidates = nil
+	seen := map[string]bool{branch: true}
+	add := func(name string) {
+		if !seen[name] && !ignore.Ignored(name) {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+	for _, base := range git.KnownBaseBranches() {
+		if exists, _ := git.BranchExists(base); exists {
+			add(base)
+		}
+	}
+	recent, err := git.GetRecentLocalBranches(parentPickerLimit)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, name := range recent {
+		add(name)
+	}
+	return candidates, true, nil
+}
+
+// askParentByName prompts for the parent of branch by name, completing it
+// from the local branches that start with what was typed.
+func askParentByName(branch string, ignore *git.BranchIgnoreRules, surveyOpts survey.AskOpt) (string, error) {
+	prompt := &survey.Input{
+		Message: fmt.Sprintf("Name of the parent branch for '%s' (Tab completes):", branch),
+		Suggest: func(toComplete string) []string {
+			names, _ := git.GetLocalBranchesWithPrefix(toComplete, parentPickerLimit)
+			return ignore.Filter(names)
+		},
+	}
+	var name string
+	if err := ui.AskOne(prompt, &name, surveyOpts); err != nil {
+		return "", ui.HandleSurveyInterrupt(err, "Track command cancelled.")
+	}
+	name = strings.TrimSpace(name)
+	exists, err := git.BranchExists(name)
+	if err != nil {
+		return "", err
+	}
+	if !exists || name == branch || ignore.Ignored(name) {
+		return "", fmt.Errorf("'%s' cannot be the parent of '%s': no such local branch, or it is ignored", name, branch)
+	}
+	return name, nil
+}
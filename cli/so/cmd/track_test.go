@@ -3,8 +3,11 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
 	"strings"
 	"testing"
 
@@ -135,6 +138,177 @@ func TestTrackCommand(t *testing.T) {
 		}
 	})
 
+	t.Run("Ignored branches are not tracked, offered as parents or listed", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+
+		if err := os.WriteFile(".socle-ignore", []byte("# Release branches are cut by CI\nrelease/*\n"), 0o644); err != nil {
+			t.Fatalf("failed to write .socle-ignore: %v", err)
+		}
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "--add", "socle.ignoreBranches", "dependabot/*, wip-*")
+		testutils.RunCommand(t, repoPath, "git", "branch", "dependabot/npm/lodash")
+		testutils.RunCommand(t, repoPath, "git", "branch", "release/1.0")
+		testutils.RunCommand(t, repoPath, "git", "branch", "wip-spike")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.wip-spike.socle-parent", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.wip-spike.socle-base", "main")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "dependabot/npm/lodash")
+		err := runSoCommand(t, "track", "--test-parent=main")
+		if err == nil || !strings.Contains(err.Error(), "matches the ignore rule 'dependabot/*'") {
+			t.Fatalf("expected tracking an ignored branch to fail, got: %v", err)
+		}
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/a", "main")
+		err = runSoCommand(t, "track", "--test-parent=release/1.0")
+		if err == nil || !strings.Contains(err.Error(), "invalid test parent 'release/1.0'") {
+			t.Fatalf("expected an ignored parent to be rejected, got: %v", err)
+		}
+
+		// Without a terminal the first candidate is picked; the ignored
+		// dependabot branch sorts before main but is no candidate.
+		originalNonInteractive := nonInteractive
+		nonInteractive = true
+		t.Cleanup(func() { nonInteractive = originalNonInteractive })
+		runner := &trackCmdRunner{
+			ctx:    context.Background(),
+			logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+			stdout: &bytes.Buffer{},
+			stderr: &bytes.Buffer{},
+			stdin:  strings.NewReader(""),
+		}
+		if err := runner.run(); err != nil {
+			t.Fatalf("track runner failed: %v", err)
+		}
+		parent, err := git.GetGitConfig("branch.feature/a.socle-parent")
+		if err != nil || parent != "main" {
+			t.Errorf("expected parent 'main', got '%s' (%v)", parent, err)
+		}
+
+		stacks, err := git.GetAvailableStacksFromBase("main")
+		if err != nil {
+			t.Fatalf("failed to list stacks: %v", err)
+		}
+		if len(stacks) != 1 || stacks[0][1] != "feature/a" {
+			t.Errorf("expected only the feature/a stack, got %v", stacks)
+		}
+	})
+
+	t.Run("Parent picker is bounded in repositories with many branches", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+
+		head := strings.TrimSpace(testutils.RunCommand(t, repoPath, "git", "rev-parse", "HEAD"))
+		var refs strings.Builder
+		for i := 0; i < parentPickerLimit+50; i++ {
+			fmt.Fprintf(&refs, "create refs/heads/bulk/%03d %s\n", i, head)
+		}
+		cmd := exec.Command("git", "update-ref", "--stdin")
+		cmd.Dir = repoPath
+		cmd.Stdin = strings.NewReader(refs.String())
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("failed to create branches: %v\n%s", err, out)
+		}
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "zz-recent")
+		t.Setenv("GIT_COMMITTER_DATE", "2090-01-01T00:00:00")
+		testutils.RunCommand(t, repoPath, "git", "commit", "--allow-empty", "-m", "feat: recent work")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/a")
+
+		ignore, err := git.LoadBranchIgnoreRules()
+		if err != nil {
+			t.Fatalf("failed to load ignore rules: %v", err)
+		}
+		candidates, truncated, err := parentCandidates("feature/a", ignore)
+		if err != nil {
+			t.Fatalf("failed to list parent candidates: %v", err)
+		}
+		if !truncated || len(candidates) > parentPickerLimit+1 {
+			t.Fatalf("expected a truncated list of at most %d candidates, got %d (truncated: %v)", parentPickerLimit+1, len(candidates), truncated)
+		}
+		if candidates[0] != "main" || candidates[1] != "zz-recent" {
+			t.Errorf("expected the base branch, then the most recent branch, got %v", candidates[:2])
+		}
+
+		names, err := git.GetLocalBranchesWithPrefix("bulk/14", 5)
+		if err != nil || len(names) != 5 || names[0] != "bulk/140" {
+			t.Errorf("expected five branches starting at bulk/140, got %v (%v)", names, err)
+		}
+
+		// A branch the picker does not list can still be chosen
+		if err := runSoCommand(t, "track", "--test-parent=bulk/149"); err != nil {
+			t.Fatalf("tracking on an unlisted branch failed: %v", err)
+		}
+		parent, err := git.GetGitConfig("branch.feature/a.socle-parent")
+		if err != nil || parent != "bulk/149" {
+			t.Errorf("expected parent 'bulk/149', got '%s' (%v)", parent, err)
+		}
+	})
+
+	t.Run("Tracking that would fork a stack is rolled back", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/a")
+		trackBranch(t, repoPath, "feature/a", "main", "main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/b")
+		trackBranch(t, repoPath, "feature/b", "feature/a", "main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature/a")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/c")
+
+		err := runSoCommand(t, "track", "--test-parent=feature/a")
+		if !errors.Is(err, ErrStackInvariantViolated) {
+			t.Fatalf("expected ErrStackInvariantViolated, got: %v", err)
+		}
+		if !strings.Contains(err.Error(), "non-base branch has multiple children") {
+			t.Errorf("expected diagnostic about multiple children, got: %v", err)
+		}
+
+		if _, err := git.GetGitConfig("branch.feature/c.socle-parent"); !errors.Is(err, git.ErrConfigNotFound) {
+			t.Errorf("expected socle-parent of feature/c to be rolled back, got err=%v", err)
+		}
+		if _, err := git.GetGitConfig("branch.feature/c.socle-base"); !errors.Is(err, git.ErrConfigNotFound) {
+			t.Errorf("expected socle-base of feature/c to be rolled back, got err=%v", err)
+		}
+	})
+
+	t.Run("Track on a frozen base ref", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		resetCommandFlags(t, trackCmd, "base-ref", "target")
+		resetCommandFlags(t, restackCmd, "no-push")
+
+		testutils.RunCommand(t, repoPath, "git", "tag", "v1.0")
+		writeFile(t, repoPath, "main-later.txt", "later")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "main moves on")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "hotfix", "v1.0")
+		writeFile(t, repoPath, "fix.txt", "fix")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "fix: hotfix")
+
+		if err := runSoCommand(t, "track", "--base-ref", "v1.0", "--target", "main"); err != nil {
+			t.Fatalf("so track --base-ref failed unexpectedly: %v", err)
+		}
+		parent, _ := git.GetGitConfig("branch.hotfix.socle-parent")
+		if parent != "frozen/v1.0" {
+			t.Errorf("Expected socle-parent to be 'frozen/v1.0', but got '%s'", parent)
+		}
+		if base, ok := git.GetFrozenBase("frozen/v1.0"); !ok || base.Ref != "v1.0" || base.Target != "main" {
+			t.Errorf("Expected frozen base at v1.0 targeting main, got %+v (ok=%v)", base, ok)
+		}
+		if got := git.PRBaseFor("frozen/v1.0"); got != "main" {
+			t.Errorf("Expected PRs on the frozen base to target 'main', got '%s'", got)
+		}
+
+		if err := runSoCommand(t, "restack", "--no-push"); err != nil {
+			t.Fatalf("so restack failed unexpectedly: %v", err)
+		}
+		onMain, _ := git.IsAncestor("main", "hotfix")
+		onTag, _ := git.IsAncestor("v1.0", "hotfix")
+		if onMain || !onTag {
+			t.Errorf("Expected restack to keep 'hotfix' on v1.0 (on v1.0: %v, on main: %v)", onTag, onMain)
+		}
+	})
+
 	t.Run("Discover remote pull request metadata", func(t *testing.T) {
 		repoPath, cleanup := testutils.SetupGitRepo(t)
 		defer cleanup()
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+)
+
+// errTrailersNeedRestack is returned when commit trailers cannot be rewritten
+// without also moving branches onto their parents.
+var errTrailersNeedRestack = errors.New("stack needs a restack before commit trailers can be updated")
+
+// commitTrailersEnabled resolves whether trailers should be maintained: the
+// --trailers flag forces them on, otherwise socle.commitTrailers decides.
+func commitTrailersEnabled(flag bool, logger *slog.Logger) bool {
+	if flag {
+		return true
+	}
+	enabled, err := git.IsCommitTrailersEnabled()
+	if err != nil {
+		logger.Warn("Ignoring socle.commitTrailers", "error", err)
+		return false
+	}
+	return enabled
+}
+
+// branchTrailers returns the trailers every commit of branch should carry.
+// The PR trailer is only included once a PR number is stored and the
+// repository's web URL (see git.RemoteURL.WebURL) is known, so PRs on GitHub
+// Enterprise link to their own host.
+func branchTrailers(branch, parent, repoURL string) []git.Trailer {
+	trailers := []git.Trailer{{Key: git.TrailerStackedOn, Value: parent}}
+	if repoURL == "" {
+		return trailers
+	}
+	prNumber, err := git.GetStoredPRNumber(branch)
+	if err != nil || prNumber == 0 {
+		return trailers
+	}
+	return append(trailers, git.Trailer{
+		Key:   git.TrailerPR,
+		Value: fmt.Sprintf("%s/pull/%d", repoURL, prNumber),
+	})
+}
+
+// applyCommitTrailers rewrites the commits of every branch above the base so
+// they carry up-to-date trailers, bottom-up. Branches are only touched when
+// their trailers are stale or their parent was rewritten; the stack must
+// already be restacked, since only commit messages may change here.
+// Returns the branches whose history was rewritten.
+func applyCommitTrailers(stack []string, repoURL string, logger *slog.Logger) ([]string, error) {
+	for i := 1; i < len(stack); i++ {
+		needsRestack, err := git.NeedsRestack(stack[i-1], stack[i])
+		if err != nil {
+			return nil, err
+		}
+		if needsRestack {
+			return nil, fmt.Errorf("%w: '%s' is not based on '%s'", errTrailersNeedRestack, stack[i], stack[i-1])
+		}
+	}
+
+	originalBranch, err := git.GetCurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	var rewritten []string
+	parentRewritten := false
+	for i := 1; i < len(stack); i++ {
+		branch, parent := stack[i], stack[i-1]
+		trailers := branchTrailers(branch, parent, repoURL)
+
+		if !parentRewritten {
+			upToDate, err := git.BranchHasTrailers(parent, branch, trailers)
+			if err != nil {
+				return rewritten, err
+			}
+			if upToDate {
+				logger.Debug("Commit trailers already up to date", "branch", branch)
+				continue
+			}
+		}
+
+		parentOID, err := git.GetCurrentBranchCommit(parent)
+		if err != nil {
+			return rewritten, fmt.Errorf("cannot get current commit of parent '%s': %w", parent, err)
+		}
+		if err := git.CheckoutBranch(branch); err != nil {
+			return rewritten, fmt.Errorf("failed to checkout branch '%s' to update trailers: %w", branch, err)
+		}
+		logger.Debug("Rewriting commit trailers", "branch", branch, "trailers", trailers)
+		opts := git.RebaseOptions{Trailers: trailers, RebaseMerges: rebaseMergesEnabled(branch, false, logger)}
+		if err := git.RebaseCurrentBranchOntoWith(parentOID, opts); err != nil {
+			return rewritten, fmt.Errorf("failed to update commit trailers on '%s': %w", branch, err)
+		}
+		rewritten = append(rewritten, branch)
+		parentRewritten = true
+	}
+
+	if len(rewritten) > 0 {
+		if err := git.CheckoutBranch(originalBranch); err != nil {
+			return rewritten, fmt.Errorf("failed to checkout original branch '%s': %w", originalBranch, err)
+		}
+	}
+	return rewritten, nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var tutorialCmd = &cobra.Command{
+	Use:   "tutorial",
+	Short: "Learn the stacked workflow in a throwaway sandbox repository",
+	Long: `Walks you through the core stacked workflow (create, log, restack and
+submit) step by step. Each step explains what is about to happen, runs the
+real so command once you press Enter and checks the result.
+
+Everything happens in a temporary repository that is deleted afterwards
+(keep it with --keep). Pushes go to a local bare repository and 'so submit'
+talks to a local mock of the GitHub API, so no network access or GitHub
+account is needed and your own repositories are never touched. The tutorial
+can be started from any directory.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationNoRepo: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keep, _ := cmd.Flags().GetBool("keep")
+
+		runner := &tutorialCmdRunner{
+			logger:         slog.Default(),
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          os.Stdin,
+			nonInteractive: nonInteractive,
+			keep:           keep,
+			root:           cmd.Root(),
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	AddCommand(tutorialCmd)
+	tutorialCmd.Flags().Bool("keep", false, "Keep the sandbox repository after the tutorial")
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// The sandbox's origin pretends to be this GitHub repository; pushes go to a
+// local bare repository and API calls to a gh.FakeServer.
+const (
+	tutorialOwner = "socle-tutorial"
+	tutorialRepo  = "sandbox"
+)
+
+var errTutorialQuit = errors.New("tutorial quit")
+
+type tutorialCmdRunner struct {
+	logger         *slog.Logger
+	stdout         io.Writer
+	stderr         io.Writer
+	stdin          io.Reader
+	nonInteractive bool
+
+	keep bool
+	root *cobra.Command // Runs each step's so command in-process
+
+	input *bufio.Reader
+}
+
+// tutorialStep is one lesson: explain, optionally set up the situation, run
+// a so command and check it did what the explanation promised.
+type tutorialStep struct {
+	title   string
+	explain []string
+	prepare func() error
+	args    []string
+	verify  func() error
+}
+
+func (r *tutorialCmdRunner) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r.input = bufio.NewReader(r.stdin)
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot determine current directory: %w", err)
+	}
+	sandbox, err := os.MkdirTemp("", "socle-tutorial-")
+	if err != nil {
+		return fmt.Errorf("cannot create sandbox directory: %w", err)
+	}
+	defer func() {
+		_ = os.Chdir(originalDir)
+		if r.keep {
+			_, _ = fmt.Fprintf(r.stdout, "\nThe sandbox repository was kept at %s\n", filepath.Join(sandbox, "repo"))
+			return
+		}
+		if errRemove := os.RemoveAll(sandbox); errRemove != nil {
+			r.logger.Debug("Failed to remove tutorial sandbox", "path", sandbox, "error", errRemove)
+		}
+	}()
+
+	if err := r.setupSandbox(sandbox); err != nil {
+		return fmt.Errorf("failed to set up the tutorial sandbox: %w", err)
+	}
+
+	api := gh.NewFakeServer(tutorialOwner, tutorialRepo)
+	defer api.Close()
+	originalCreateClient := gh.CreateClient
+	gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+		return api.Client(ctx), nil
+	}
+	defer func() { gh.CreateClient = originalCreateClient }()
+
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.UserInputStyle.Render("Welcome to the socle tutorial!"))
+	_, _ = fmt.Fprintln(r.stdout, "You will build a stack of two branches, restack it and open pull requests.")
+	_, _ = fmt.Fprintf(r.stdout, "Sandbox repository: %s\n", ui.Colors.MutedStyle.Render(filepath.Join(sandbox, "repo")))
+
+	steps := r.steps(api)
+	for i, step := range steps {
+		if err := r.runStep(ctx, i+1, len(steps), step); err != nil {
+			if errors.Is(err, errTutorialQuit) {
+				_, _ = fmt.Fprintln(r.stdout, "\nTutorial stopped. Run 'so tutorial' again any time.")
+				return nil
+			}
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("\n✓ Tutorial complete!"))
+	_, _ = fmt.Fprintln(r.stdout, "In your own repository, run 'so track' on an existing branch or 'so create' to start a stack.")
+	return nil
+}
+
+func (r *tutorialCmdRunner) runStep(ctx context.Context, number, total int, step tutorialStep) error {
+	_, _ = fmt.Fprintf(r.stdout, "\n%s\n", ui.Colors.InfoStyle.Render(fmt.Sprintf("Step %d/%d: %s", number, total, step.title)))
+	for _, line := range step.explain {
+		_, _ = fmt.Fprintf(r.stdout, "  %s\n", line)
+	}
+
+	if step.prepare != nil {
+		if err := step.prepare(); err != nil {
+			return fmt.Errorf("step %d (%s): preparation failed: %w", number, step.title, err)
+		}
+	}
+
+	command := "so " + strings.Join(step.args, " ")
+	if err := r.waitForEnter(command); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(r.stdout, "\n$ %s\n", ui.Colors.UserInputStyle.Render(command))
+
+	r.root.SetArgs(step.args)
+	if err := r.root.ExecuteContext(ctx); err != nil {
+		return fmt.Errorf("step %d (%s): '%s' failed: %w", number, step.title, command, err)
+	}
+	if step.verify != nil {
+		if err := step.verify(); err != nil {
+			return fmt.Errorf("step %d (%s): '%s' did not have the expected effect: %w", number, step.title, command, err)
+		}
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render("  ✓ Looks good."))
+	return nil
+}
+
+// waitForEnter pauses until the user presses Enter; "q" quits the tutorial.
+func (r *tutorialCmdRunner) waitForEnter(command string) error {
+	if r.nonInteractive {
+		return nil
+	}
+	_, _ = fmt.Fprintf(r.stdout, "\nPress Enter to run %s (q to quit) ", ui.Colors.UserInputStyle.Render(command))
+	line, err := r.input.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	if strings.EqualFold(strings.TrimSpace(line), "q") || (errors.Is(err, io.EOF) && line == "") {
+		return errTutorialQuit
+	}
+	return nil
+}
+
+// setupSandbox creates <dir>/repo with one commit on main and an origin whose
+// URL names the fake GitHub repository but whose pushes land in <dir>/remote.git.
+func (r *tutorialCmdRunner) setupSandbox(dir string) error {
+	repoDir := filepath.Join(dir, "repo")
+	remoteDir := filepath.Join(dir, "remote.git")
+	if err := os.Mkdir(repoDir, 0o755); err != nil {
+		return err
+	}
+	if err := os.Chdir(repoDir); err != nil {
+		return err
+	}
+
+	commands := [][]string{
+		{"init", "--quiet", "--initial-branch=main"},
+		{"config", "user.name", "Socle Tutorial"},
+		{"config", "user.email", "tutorial@socle.invalid"},
+		{"config", "commit.gpgsign", "false"},
+	}
+	for _, args := range commands {
+		if _, err := git.RunGitCommand(args...); err != nil {
+			return err
+		}
+	}
+	if err := commitFile("README.md", "# Tutorial sandbox\n", "Initial commit"); err != nil {
+		return err
+	}
+
+	commands = [][]string{
+		{"init", "--quiet", "--bare", remoteDir},
+		{"remote", "add", "origin", fmt.Sprintf("https://github.com/%s/%s.git", tutorialOwner, tutorialRepo)},
+		{"config", "remote.origin.pushurl", remoteDir},
+		{"push", "--quiet", "origin", "main"},
+	}
+	for _, args := range commands {
+		if _, err := git.RunGitCommand(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *tutorialCmdRunner) steps(api *gh.FakeServer) []tutorialStep {
+	return []tutorialStep{
+		{
+			title: "Create your first stacked branch",
+			explain: []string{
+				"A stack is a chain of small branches, each building on the one below.",
+				"We wrote greeting.txt for you. 'so create' puts uncommitted changes on a",
+				"new branch on top of the current one and records main as its parent.",
+			},
+			prepare: func() error { return os.WriteFile("greeting.txt", []byte("Hello!\n"), 0o644) },
+			args:    []string{"create", "add-greeting", "-m", "Add greeting"},
+			verify:  func() error { return expectStackedOn("add-greeting", "main") },
+		},
+		{
+			title: "Stack a second branch on top",
+			explain: []string{
+				"Now farewell.txt is waiting. Running 'so create' again stacks the next",
+				"branch on add-greeting, so each branch can be reviewed on its own.",
+			},
+			prepare: func() error { return os.WriteFile("farewell.txt", []byte("Goodbye!\n"), 0o644) },
+			args:    []string{"create", "add-farewell", "-m", "Add farewell"},
+			verify:  func() error { return expectStackedOn("add-farewell", "add-greeting") },
+		},
+		{
+			title: "See the stack",
+			explain: []string{
+				"'so log' shows the stack from top to bottom. The first dot is green when a",
+				"branch sits on its parent, the second shows the pull request status.",
+			},
+			args: []string{"log"},
+		},
+		{
+			title: "Restack after changing a lower branch",
+			explain: []string{
+				"Review feedback often lands on a lower branch. We just committed a fix to",
+				"add-greeting, so add-farewell no longer sits on top of it. 'so restack'",
+				"rebases every branch onto its parent again. (--no-fetch and --no-push only",
+				"because the sandbox has no real remote.)",
+			},
+			prepare: func() error {
+				if _, err := git.RunGitCommand("checkout", "--quiet", "add-greeting"); err != nil {
+					return err
+				}
+				if err := commitFile("greeting.txt", "Hello, stacked world!\n", "Polish greeting"); err != nil {
+					return err
+				}
+				_, err := git.RunGitCommand("checkout", "--quiet", "add-farewell")
+				return err
+			},
+			args: []string{"restack", "--no-fetch", "--no-push"},
+			verify: func() error {
+				onTop, err := git.IsAncestor("add-greeting", "add-farewell")
+				if err != nil {
+					return err
+				}
+				if !onTop {
+					return fmt.Errorf("add-farewell is not on top of add-greeting")
+				}
+				return nil
+			},
+		},
+		{
+			title: "Submit the stack as pull requests",
+			explain: []string{
+				"'so submit' pushes every branch and opens one pull request per branch,",
+				"each targeting the branch below it, plus a comment linking the whole",
+				"stack. Here it talks to a local mock of GitHub.",
+			},
+			args:   []string{"submit"},
+			verify: func() error { return expectTutorialPRs(api) },
+		},
+	}
+}
+
+// commitFile writes content to path in the current repository and commits it.
+func commitFile(path, content, message string) error {
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return err
+	}
+	if _, err := git.RunGitCommand("add", path); err != nil {
+		return err
+	}
+	_, err := git.RunGitCommand("commit", "--quiet", "-m", message)
+	return err
+}
+
+func expectStackedOn(branch, parent string) error {
+	current, err := git.GetCurrentBranch()
+	if err != nil {
+		return err
+	}
+	if current != branch {
+		return fmt.Errorf("expected to be on '%s', but on '%s'", branch, current)
+	}
+	storedParent, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-parent"))
+	if err != nil {
+		return fmt.Errorf("'%s' is not tracked: %w", branch, err)
+	}
+	if storedParent != parent {
+		return fmt.Errorf("expected parent '%s' for '%s', got '%s'", parent, branch, storedParent)
+	}
+	return nil
+}
+
+func expectTutorialPRs(api *gh.FakeServer) error {
+	want := map[string]string{"add-greeting": "main", "add-farewell": "add-greeting"}
+	prs := api.PullRequests()
+	if len(prs) != len(want) {
+		return fmt.Errorf("expected %d pull requests, found %d", len(want), len(prs))
+	}
+	for _, pr := range prs {
+		head, base := pr.GetHead().GetRef(), pr.GetBase().GetRef()
+		if want[head] != base {
+			return fmt.Errorf("pull request #%d for '%s' targets '%s', expected '%s'", pr.GetNumber(), head, base, want[head])
+		}
+		stored, err := git.GetStoredPRNumber(head)
+		if err != nil || stored != pr.GetNumber() {
+			return fmt.Errorf("pull request #%d is not recorded for '%s'", pr.GetNumber(), head)
+		}
+		if len(api.CommentsOn(pr.GetNumber())) == 0 {
+			return fmt.Errorf("pull request #%d has no stack comment", pr.GetNumber())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTutorialCommand(t *testing.T) {
+	// Steps run real commands, so flag values left behind by other tests
+	// (and by the tutorial itself) must not leak in either direction.
+	resetCommandFlags(t, createCmd, "message", "test-branch-name", "test-stage-choice")
+	resetCommandFlags(t, restackCmd, "no-fetch", "no-push", "force-push")
+	resetCommandFlags(t, submitCmd, "no-push", "force", "title", "test-title")
+	resetCommandFlags(t, tutorialCmd, "keep")
+
+	// The tutorial must work from outside any repository.
+	chdirTemp := func(t *testing.T) {
+		originalWD, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(t.TempDir()))
+		t.Cleanup(func() { _ = os.Chdir(originalWD) })
+	}
+	sandboxRegex := regexp.MustCompile(`Sandbox repository: (\S+)`)
+
+	t.Run("Walks through every step and removes the sandbox", func(t *testing.T) {
+		chdirTemp(t)
+		startDir, _ := os.Getwd()
+
+		stdout, _, err := runSoCommandWithOutput(t, "tutorial", "--non-interactive")
+		require.NoError(t, err)
+		stdout = stripAnsi(stdout)
+
+		for _, want := range []string{
+			"Step 1/5: Create your first stacked branch",
+			"$ so create add-greeting -m Add greeting",
+			"$ so log",
+			"$ so restack --no-fetch --no-push",
+			"Step 5/5: Submit the stack as pull requests",
+			"Tutorial complete!",
+		} {
+			assert.Contains(t, stdout, want)
+		}
+		assert.Equal(t, 5, strings.Count(stdout, "✓ Looks good."))
+
+		m := sandboxRegex.FindStringSubmatch(stdout)
+		require.NotNil(t, m)
+		_, statErr := os.Stat(m[1])
+		assert.True(t, os.IsNotExist(statErr), "sandbox should be removed")
+
+		cwd, _ := os.Getwd()
+		assert.Equal(t, startDir, cwd)
+	})
+
+	t.Run("Quitting at the prompt stops without running the step", func(t *testing.T) {
+		chdirTemp(t)
+		root, err := initializeCobraAppForTest()
+		require.NoError(t, err)
+		var out strings.Builder
+		root.SetOut(&out)
+
+		runner := &tutorialCmdRunner{
+			logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			stdout: &out,
+			stderr: io.Discard,
+			stdin:  strings.NewReader("q\n"),
+			keep:   true,
+			root:   root,
+		}
+		require.NoError(t, runner.run(context.Background()))
+
+		stdout := stripAnsi(out.String())
+		assert.Contains(t, stdout, "Tutorial stopped.")
+		assert.NotContains(t, stdout, "$ so create")
+
+		m := sandboxRegex.FindStringSubmatch(stdout)
+		require.NotNil(t, m)
+		assert.Contains(t, stdout, "The sandbox repository was kept at "+m[1])
+		_, statErr := os.Stat(m[1])
+		assert.NoError(t, statErr)
+		_ = os.RemoveAll(filepath.Dir(m[1]))
+	})
+}
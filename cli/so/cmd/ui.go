@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse and work on the current stack in an interactive view",
+	Long: `Opens a full-screen view of the current stack with the status dots of
+'so log': whether each branch needs a restack, and the state of its PR. The
+statuses refresh every 30 seconds and after every action.
+
+Keys:
+  ↑/k, ↓/j   Move between branches
+  enter      Check out the selected branch
+  r          Check out the selected branch and restack it and the branches above it
+  s          Submit the selected branch ('so submit --stack-only <branch>')
+  g          Refresh the statuses now
+  q          Quit
+
+Restack and submit run as usual in the terminal, prompts included, and return
+to the view when they finish. Needs an interactive terminal; use 'so log'
+in scripts.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner := &uiCmdRunner{
+			logger: slog.Default(),
+			stdin:  os.Stdin,
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+		}
+		return runner.run(cmd.Context())
+	},
+}
+
+func init() {
+	AddCommand(uiCmd)
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// uiRefreshInterval is how often 'so ui' recomputes the statuses on its own.
+const uiRefreshInterval = 30 * time.Second
+
+type uiCmdRunner struct {
+	logger *slog.Logger
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	ghClient gh.ClientInterface // Created on the first load that can
+}
+
+func (r *uiCmdRunner) run(ctx context.Context) error {
+	if !hasInteractiveSurveyTerminal(r.stdin, r.stderr) {
+		return fmt.Errorf("'so ui' needs an interactive terminal; use 'so log' instead")
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the so executable: %w", err)
+	}
+
+	model := newUIModel(func() tea.Msg { return r.loadStack(ctx) }, func(args ...string) tea.Cmd {
+		return tea.ExecProcess(exec.Command(self, args...), func(err error) tea.Msg {
+			return uiActionDoneMsg{action: "so " + strings.Join(args, " "), err: err}
+		})
+	})
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx), tea.WithInput(r.stdin), tea.WithOutput(r.stdout))
+	final, err := program.Run()
+	if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		return fmt.Errorf("interactive view failed: %w", err)
+	}
+	if m, ok := final.(uiModel); ok && m.err != nil {
+		return m.err
+	}
+	return nil
+}
+
+// loadStack gathers what 'so log' shows for the current stack.
+func (r *uiCmdRunner) loadStack(ctx context.Context) uiStackLoadedMsg {
+	info, err := git.GetStackInfo()
+	if err != nil {
+		return uiStackLoadedMsg{err: err}
+	}
+	stack := info.FullStack
+	if stack == nil {
+		stack = info.CurrentStack
+	}
+	if len(stack) <= 1 {
+		return uiStackLoadedMsg{err: fmt.Errorf("no stack to show on '%s'; check out a branch of a stack first", info.CurrentBranch)}
+	}
+
+	if r.ghClient == nil {
+		client, errClient := newOriginGitHubClient(ctx)
+		if errClient != nil {
+			r.logger.Debug("GitHub client unavailable, PR statuses are unknown", "error", errClient)
+		} else {
+			r.ghClient = client
+		}
+	}
+	logRunner := &logCmdRunner{logger: r.logger, stdout: io.Discard, stderr: io.Discard}
+	return uiStackLoadedMsg{
+		base:    stack[0],
+		current: info.CurrentBranch,
+		infos:   logRunner.collectBranchInfos(ctx, stack, stackParentOIDs(stack), r.ghClient),
+	}
+}
+
+// uiStackLoadedMsg carries a freshly computed stack, or why it could not be.
+type uiStackLoadedMsg struct {
+	base    string
+	current string
+	infos   []branchLogInfo // Top of stack first
+	err     error
+}
+
+// uiActionDoneMsg reports that an action on a branch finished.
+type uiActionDoneMsg struct {
+	action string
+	err    error
+	then   []string // Arguments of the so command to run next if the action succeeded
+}
+
+// uiTickMsg asks for the periodic refresh.
+type uiTickMsg struct{}
+
+// uiModel is the state of 'so ui'. Loading the stack and running socle
+// commands are injected, so the model can be driven without a terminal.
+type uiModel struct {
+	load func() tea.Msg               // Computes the stack; returns a uiStackLoadedMsg
+	run  func(args ...string) tea.Cmd // Runs 'so <args>' in the terminal
+
+	base    string
+	current string
+	infos   []branchLogInfo
+	cursor  int
+	loaded  bool
+	loading bool
+	running bool   // An action is running; the periodic refresh waits for it
+	status  string // Outcome of the last action
+	width   int
+	err     error // Fatal: shown after the view closes
+}
+
+func newUIModel(load func() tea.Msg, run func(args ...string) tea.Cmd) uiModel {
+	return uiModel{load: load, run: run, loading: true}
+}
+
+func (m uiModel) Init() tea.Cmd {
+	return tea.Batch(m.load, uiTick())
+}
+
+func uiTick() tea.Cmd {
+	return tea.Tick(uiRefreshInterval, func(time.Time) tea.Msg { return uiTickMsg{} })
+}
+
+func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case uiStackLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			if !m.loaded {
+				m.err = msg.err
+				return m, tea.Quit
+			}
+			m.status = fmt.Sprintf("Refresh failed: %v", msg.err)
+			return m, nil
+		}
+		selected := m.selected()
+		m.base, m.current, m.infos = msg.base, msg.current, msg.infos
+		if !m.loaded {
+			selected = m.current
+		}
+		m.loaded = true
+		m.cursor = 0
+		for i, info := range m.infos {
+			if info.branchName == selected {
+				m.cursor = i
+			}
+		}
+	case uiActionDoneMsg:
+		if msg.err == nil && len(msg.then) > 0 {
+			return m, m.run(msg.then...)
+		}
+		m.running = false
+		if msg.err != nil {
+			m.status = fmt.Sprintf("'%s' failed: %v", msg.action, msg.err)
+		} else {
+			m.status = fmt.Sprintf("'%s' done.", msg.action)
+		}
+		return m.refresh()
+	case uiTickMsg:
+		if m.loading || m.running {
+			return m, uiTick()
+		}
+		var cmd tea.Cmd
+		m, cmd = m.refresh()
+		return m, tea.Batch(cmd, uiTick())
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m uiModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.cursor < len(m.infos)-1 {
+			m.cursor++
+		}
+		return m, nil
+	case "g":
+		return m.refresh()
+	}
+
+	branch := m.selected()
+	if branch == "" || m.loading {
+		return m, nil
+	}
+	switch msg.String() {
+	case "enter":
+		m.status = fmt.Sprintf("Checking out '%s'...", branch)
+		m.running = true
+		return m, uiCheckout(branch)
+	case "r":
+		m.status = fmt.Sprintf("Restacking from '%s'...", branch)
+		m.running = true
+		return m, uiCheckout(branch, "restack", "--upstack")
+	case "s":
+		m.status = fmt.Sprintf("Submitting '%s'...", branch)
+		m.running = true
+		return m, m.run("submit", "--stack-only", branch)
+	}
+	return m, nil
+}
+
+// refresh reloads the stack unless a load is already running.
+func (m uiModel) refresh() (uiModel, tea.Cmd) {
+	if m.loading {
+		return m, nil
+	}
+	m.loading = true
+	return m, m.load
+}
+
+func (m uiModel) selected() string {
+	if m.cursor < 0 || m.cursor >= len(m.infos) {
+		return ""
+	}
+	return m.infos[m.cursor].branchName
+}
+
+// uiCheckout checks out branch, then runs 'so <then>' if that worked.
+func uiCheckout(branch string, then ...string) tea.Cmd {
+	return func() tea.Msg {
+		return uiActionDoneMsg{action: "git checkout " + branch, err: git.CheckoutBranch(branch), then: then}
+	}
+}
+
+func (m uiModel) View() string {
+	if !m.loaded {
+		return "\n  Loading the stack...\n"
+	}
+
+	var b strings.Builder
+	header := fmt.Sprintf("Stack on '%s'", m.base)
+	if m.loading {
+		header += mutedStyle.Render("  refreshing...")
+	}
+	b.WriteString("\n  " + lipgloss.NewStyle().Bold(true).Render(header) + "\n\n")
+
+	for i := range m.infos {
+		info := &m.infos[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = ui.Colors.InfoStyle.Render("❯ ")
+		}
+		name := lipgloss.NewStyle().Bold(true).Render(info.branchName)
+		if info.branchName == m.current {
+			name += ui.Colors.InfoStyle.Render(" (current)")
+		}
+		line := fmt.Sprintf("%s%s %s %s", cursor, statusDots(info), name, mutedStyle.Render(branchStatusText(*info)))
+		if info.wip {
+			line += " " + wipBadgeStyle.Render("[WIP]")
+		}
+		if info.conflictsWith != "" {
+			line += " " + conflictBadgeStyle.Render("[conflicts with "+info.conflictsWith+"]")
+		}
+		if m.width > 0 {
+			line = ui.Truncate(line, m.width-2)
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	b.WriteString("        " + mutedStyle.Render(m.base+" (base)") + "\n\n")
+
+	b.WriteString("  " + mutedStyle.Render("↑/↓ move · enter check out · r restack · s submit · g refresh · q quit") + "\n")
+	if m.status != "" {
+		b.WriteString("  " + m.status + "\n")
+	}
+	return b.String()
+}
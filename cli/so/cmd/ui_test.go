@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUICommand(t *testing.T) {
+	t.Run("Needs an interactive terminal", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		_, _, err := runSoCommandWithOutput(t, "ui")
+		require.ErrorContains(t, err, "needs an interactive terminal")
+	})
+
+	t.Run("Model shows the stack and maps keys to actions", func(t *testing.T) {
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		originalCreateGHClient := gh.CreateClient
+		t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return nil, assert.AnError
+		}
+
+		runner := &uiCmdRunner{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+		var ran [][]string
+		m := newUIModel(func() tea.Msg { return runner.loadStack(context.Background()) }, func(args ...string) tea.Cmd {
+			ran = append(ran, args)
+			return nil
+		})
+
+		update := func(msg tea.Msg) tea.Cmd {
+			next, cmd := m.Update(msg)
+			m = next.(uiModel)
+			return cmd
+		}
+		update(m.load())
+		view := stripAnsi(m.View())
+		assert.Contains(t, view, "Stack on 'main'")
+		assert.Contains(t, view, "feature-b (current)")
+		assert.Contains(t, view, "main (base)")
+		assert.Equal(t, "feature-b", m.selected(), "the cursor starts on the current branch")
+
+		update(tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, "feature-a", m.selected())
+
+		update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+		assert.Equal(t, [][]string{{"submit", "--stack-only", "feature-a"}}, ran)
+		update(uiTickMsg{})
+		assert.False(t, m.loading, "the periodic refresh waits while an action runs")
+
+		// Checking out reloads the stack with the new current branch
+		cmd := update(tea.KeyMsg{Type: tea.KeyEnter})
+		require.NotNil(t, cmd)
+		cmd = update(cmd())
+		require.NotNil(t, cmd, "a finished action refreshes the stack")
+		update(cmd())
+		current, err := git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature-a", current)
+		assert.Contains(t, stripAnsi(m.View()), "feature-a (current)")
+		assert.Contains(t, m.status, "'git checkout feature-a' done.")
+
+		// Restacking runs only once its checkout worked
+		writeFile(t, repoPath, "feature-b.txt", "untracked here\n")
+		update(tea.KeyMsg{Type: tea.KeyUp})
+		cmd = update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+		require.NotNil(t, cmd)
+		cmd = update(cmd())
+		require.NotNil(t, cmd, "a failed checkout refreshes the stack")
+		update(cmd())
+		assert.Contains(t, m.status, "'git checkout feature-b' failed")
+		assert.Len(t, ran, 1, "no restack after a failed checkout")
+		require.NoError(t, os.Remove(filepath.Join(repoPath, "feature-b.txt")))
+		cmd = update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+		update(cmd())
+		assert.Equal(t, []string{"restack", "--upstack"}, ran[len(ran)-1])
+
+		cmd = update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+		require.NotNil(t, cmd)
+		assert.IsType(t, tea.QuitMsg{}, cmd())
+	})
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 
@@ -11,22 +12,54 @@ var untrackCmd = &cobra.Command{
 	Use:   "untrack",
 	Short: "Remove a branch from the stack",
 	Long: `Removes a branch from the stack by clearing its tracking information.
-A branch can only be untracked if it has no children depending on it higher in the stack.`,
+A branch can only be untracked if it has no children depending on it higher in the stack.
+
+With --purge, every socle setting of the branch is removed as well: its PR
+number, stack comment ID, description and any other socle-* key, so the
+branch looks as if socle had never seen it. Children of the branch can be
+moved onto its parent first: 'so untrack --purge' asks, and --reparent does it
+without asking. With --cascade, the branch is untracked together with every
+branch stacked on it.
+
+With --orphans, no branch is untracked. Instead, the metadata a branch deleted
+outside socle (for example with 'git branch -D') leaves behind is repaired:
+branches stacked on it move onto their base branch, and the metadata of
+tracked branches that no longer exist is removed.`,
 	Args: cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: guardStackInvariants(func(cmd *cobra.Command, args []string) error {
 		logger := slog.Default()
 
+		purge, _ := cmd.Flags().GetBool("purge")
+		reparent, _ := cmd.Flags().GetBool("reparent")
+		cascade, _ := cmd.Flags().GetBool("cascade")
+		orphans, _ := cmd.Flags().GetBool("orphans")
+		if reparent && !purge {
+			return fmt.Errorf("--reparent requires --purge")
+		}
+
 		runner := &untrackCmdRunner{
-			logger: logger,
-			stdout: cmd.OutOrStdout(),
-			stderr: cmd.ErrOrStderr(),
-			stdin:  os.Stdin,
+			logger:         logger,
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          os.Stdin,
+			nonInteractive: nonInteractive,
+			purge:          purge,
+			reparent:       reparent,
+			cascade:        cascade,
+			orphans:        orphans,
 		}
 
 		return runner.run()
-	},
+	}),
 }
 
 func init() {
 	AddCommand(untrackCmd)
+	untrackCmd.Flags().Bool("purge", false, "Also remove the PR number, comment ID and every other socle setting of the branch")
+	untrackCmd.Flags().Bool("reparent", false, "With --purge, move children of the branch onto its parent without asking")
+	untrackCmd.Flags().Bool("cascade", false, "Also untrack every branch stacked on the branch")
+	untrackCmd.Flags().Bool("orphans", false, "Repair the tracking left behind by branches deleted outside socle instead of untracking")
+	untrackCmd.MarkFlagsMutuallyExclusive("cascade", "reparent")
+	untrackCmd.MarkFlagsMutuallyExclusive("orphans", "cascade")
+	untrackCmd.MarkFlagsMutuallyExclusive("orphans", "purge")
 }
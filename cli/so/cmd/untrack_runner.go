@@ -4,19 +4,33 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"sort"
+	"strings"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/benekuehn/socle/cli/so/internal/git"
 	"github.com/benekuehn/socle/cli/so/internal/ui"
 )
 
 type untrackCmdRunner struct {
-	logger *slog.Logger
-	stdout io.Writer
-	stderr io.Writer
-	stdin  io.Reader
+	logger         *slog.Logger
+	stdout         io.Writer
+	stderr         io.Writer
+	stdin          io.Reader
+	nonInteractive bool
+
+	purge    bool // Remove every socle-* key, not just parent and base
+	reparent bool // Move children onto the branch's parent without asking
+	cascade  bool // Untrack every branch stacked on the branch as well
+	orphans  bool // Repair metadata of deleted branches instead of untracking
 }
 
 func (r *untrackCmdRunner) run() error {
+	if r.orphans {
+		return r.repairOrphans()
+	}
+
 	// Get current branch
 	currentBranch, err := git.GetCurrentBranch()
 	if err != nil {
@@ -24,48 +38,160 @@ func (r *untrackCmdRunner) run() error {
 	}
 
 	// Check if branch is a base branch
-	knownBases := map[string]bool{"main": true, "master": true, "develop": true}
+	knownBases := git.KnownBaseBranchSet()
 	if knownBases[currentBranch] {
 		return fmt.Errorf("cannot untrack a base branch ('%s')", currentBranch)
 	}
 
-	// Get all local branches to check for children
-	allBranches, err := git.GetLocalBranches()
+	// Find the children in the tracking metadata, not by visiting every local branch
+	parents, err := git.GetAllSocleParents()
 	if err != nil {
-		return fmt.Errorf("failed to list local branches: %w", err)
+		return fmt.Errorf("failed to read tracking relationships: %w", err)
 	}
+	childMap := git.BuildChildMap(parents)
+	children := childMap[currentBranch]
+	sort.Strings(children)
 
-	// Check if any branch has this branch as its parent
-	var children []string
-	for _, branch := range allBranches {
-		if branch == currentBranch {
-			continue
+	branches := []string{currentBranch}
+	if len(children) > 0 {
+		switch {
+		case r.cascade:
+			// Untrack the branches above first, so a failure never leaves a
+			// child tracked on an untracked parent.
+			descendants := git.FindAllDescendants(currentBranch, childMap)
+			branches = append(reverseBranchOrder(descendants), currentBranch)
+		case !r.purge:
+			return fmt.Errorf("cannot untrack branch '%s' because it has children depending on it: %v. Rerun with --cascade to untrack them too", currentBranch, children)
+		default:
+			if err := r.reparentChildren(currentBranch, children); err != nil {
+				return err
+			}
 		}
-		parentConfigKey := fmt.Sprintf("branch.%s.socle-parent", branch)
-		parent, err := git.GetGitConfig(parentConfigKey)
-		if err == nil && parent == currentBranch {
-			children = append(children, branch)
+	}
+
+	for _, branch := range branches {
+		if err := r.untrackBranch(branch); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if len(children) > 0 {
-		return fmt.Errorf("cannot untrack branch '%s' because it has children depending on it: %v", currentBranch, children)
+// untrackBranch clears the tracking information of branch, or every socle
+// setting with --purge.
+func (r *untrackCmdRunner) untrackBranch(branch string) error {
+	if r.purge {
+		removed, err := git.UnsetSocleBranchConfig(branch)
+		if err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			_, _ = fmt.Fprintf(r.stdout, "Branch '%s' has no socle settings to remove.\n", branch)
+			return nil
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Branch '%s' has been untracked and purged (%s).", branch, strings.Join(removed, ", "))))
+		return nil
 	}
 
 	// Clear tracking information
-	parentConfigKey := fmt.Sprintf("branch.%s.socle-parent", currentBranch)
-	baseConfigKey := fmt.Sprintf("branch.%s.socle-base", currentBranch)
+	parentConfigKey := git.BranchConfigKey(branch, "socle-parent")
+	baseConfigKey := git.BranchConfigKey(branch, "socle-base")
 
+	parent, _ := git.GetGitConfig(parentConfigKey)
 	if err := git.UnsetGitConfig(parentConfigKey); err != nil {
 		return fmt.Errorf("failed to clear parent tracking: %w", err)
 	}
 
 	if err := git.UnsetGitConfig(baseConfigKey); err != nil {
 		// Try to restore parent config if base config fails
-		_ = git.SetGitConfig(parentConfigKey, currentBranch)
+		if parent != "" {
+			_ = git.SetGitConfig(parentConfigKey, parent)
+		}
 		return fmt.Errorf("failed to clear base tracking: %w", err)
 	}
 
-	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Branch '%s' has been untracked.", currentBranch)))
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Branch '%s' has been untracked.", branch)))
+	return nil
+}
+
+// reverseBranchOrder returns branches in reverse order.
+func reverseBranchOrder(branches []string) []string {
+	reversed := make([]string, 0, len(branches))
+	for i := len(branches) - 1; i >= 0; i-- {
+		reversed = append(reversed, branches[i])
+	}
+	return reversed
+}
+
+// repairOrphans fixes the metadata a branch deleted outside socle leaves
+// behind: its children move onto their base branch, and metadata of
+// branches that no longer exist is removed.
+func (r *untrackCmdRunner) repairOrphans() error {
+	found, err := git.FindOrphanedMetadata()
+	if err != nil {
+		return err
+	}
+	if found.Empty() {
+		_, _ = fmt.Fprintln(r.stdout, "No orphaned tracking metadata found.")
+		return nil
+	}
+
+	orphans := make([]string, 0, len(found.Orphans))
+	for branch := range found.Orphans {
+		orphans = append(orphans, branch)
+	}
+	sort.Strings(orphans)
+	for _, branch := range orphans {
+		missing := found.Orphans[branch]
+		base, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-base"))
+		if err != nil || base == "" {
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: '%s' stacks on the missing '%s' and has no base to move onto; run 'so track' on it.", branch, missing)))
+			continue
+		}
+		if err := git.UpdateBranchParent(branch, base); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(r.stdout, "Moved '%s' from the missing '%s' onto '%s'. Run 'so restack' to rebase it.\n", branch, missing, base)
+	}
+
+	for _, branch := range found.Stale {
+		if _, err := git.UnsetSocleBranchConfig(branch); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(r.stdout, "Removed the tracking of '%s', which no longer exists.\n", branch)
+	}
+	return nil
+}
+
+// reparentChildren moves children of branch onto branch's parent, after
+// asking unless --reparent was given.
+func (r *untrackCmdRunner) reparentChildren(branch string, children []string) error {
+	parent, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-parent"))
+	if err != nil {
+		return fmt.Errorf("cannot untrack branch '%s': it has children %v but no tracked parent to move them onto", branch, children)
+	}
+	_, _ = fmt.Fprintln(r.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("Warning: %s still stack(s) on '%s'.", strings.Join(children, ", "), branch)))
+
+	if !r.reparent {
+		if r.nonInteractive || !hasInteractiveSurveyTerminal(r.stdin, r.stderr) {
+			return fmt.Errorf("cannot untrack branch '%s' because it has children depending on it: %v. Rerun with --reparent to move them onto '%s'", branch, children, parent)
+		}
+		confirm := false
+		prompt := &survey.Confirm{Message: fmt.Sprintf("Move them onto '%s'?", parent), Default: true}
+		surveyOpts := survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File))
+		if err := ui.AskOne(prompt, &confirm, surveyOpts); err != nil {
+			return ui.HandleSurveyInterrupt(err, "Untrack cancelled.")
+		}
+		if !confirm {
+			return fmt.Errorf("cannot untrack branch '%s' because it has children depending on it: %v", branch, children)
+		}
+	}
+
+	for _, child := range children {
+		if err := git.UpdateBranchParent(child, parent); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(r.stdout, "Moved '%s' onto '%s'. Run 'so restack' to rebase it.\n", child, parent)
+	}
 	return nil
 }
@@ -86,4 +86,120 @@ func TestUntrackCommand(t *testing.T) {
 			t.Errorf("Parent branch config was unexpectedly modified")
 		}
 	})
+
+	t.Run("Purge removes every socle key", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		resetCommandFlags(t, untrackCmd, "purge", "reparent", "cascade", "orphans")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/a")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/a.socle-parent", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/a.socle-base", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/a.socle-pr-number", "42")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/a.socle-comment-id", "1001")
+
+		if err := runSoCommand(t, "untrack", "--purge"); err != nil {
+			t.Fatalf("so untrack --purge failed unexpectedly: %v", err)
+		}
+
+		for _, key := range []string{"socle-parent", "socle-base", "socle-pr-number", "socle-comment-id"} {
+			if val, err := git.GetGitConfig("branch.feature/a." + key); err == nil {
+				t.Errorf("Expected %s to be removed, but got '%s'", key, val)
+			}
+		}
+	})
+
+	t.Run("Purge with reparent moves children onto the parent", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		resetCommandFlags(t, untrackCmd, "purge", "reparent", "cascade", "orphans")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feature/a")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/a.socle-parent", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/a.socle-base", "main")
+		testutils.RunCommand(t, repoPath, "git", "branch", "feature/b")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/b.socle-parent", "feature/a")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/b.socle-base", "main")
+
+		err := runSoCommand(t, "untrack", "--purge", "--non-interactive")
+		if err == nil || !strings.Contains(err.Error(), "--reparent") {
+			t.Fatalf("Expected an error suggesting --reparent, but got: %v", err)
+		}
+
+		if err := runSoCommand(t, "untrack", "--purge", "--reparent"); err != nil {
+			t.Fatalf("so untrack --purge --reparent failed unexpectedly: %v", err)
+		}
+		parent, err := git.GetGitConfig("branch.feature/b.socle-parent")
+		if err != nil || parent != "main" {
+			t.Errorf("Expected feature/b to be moved onto 'main', but got '%s' (%v)", parent, err)
+		}
+		if _, err := git.GetGitConfig("branch.feature/a.socle-parent"); err == nil {
+			t.Errorf("Expected feature/a to be untracked")
+		}
+	})
+
+	t.Run("Cascade untracks every branch stacked on the branch", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		resetCommandFlags(t, untrackCmd, "purge", "reparent", "cascade", "orphans")
+
+		for _, b := range [][2]string{{"feature/a", "main"}, {"feature/b", "feature/a"}, {"feature/c", "feature/b"}, {"feature/x", "main"}} {
+			testutils.RunCommand(t, repoPath, "git", "branch", b[0])
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+b[0]+".socle-parent", b[1])
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+b[0]+".socle-base", "main")
+		}
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature/a")
+
+		if err := runSoCommand(t, "untrack", "--cascade"); err != nil {
+			t.Fatalf("so untrack --cascade failed unexpectedly: %v", err)
+		}
+		for _, branch := range []string{"feature/a", "feature/b", "feature/c"} {
+			if _, err := git.GetGitConfig("branch." + branch + ".socle-parent"); err == nil {
+				t.Errorf("Expected %s to be untracked", branch)
+			}
+		}
+		if parent, err := git.GetGitConfig("branch.feature/x.socle-parent"); err != nil || parent != "main" {
+			t.Errorf("Expected feature/x to stay tracked on 'main', but got '%s' (%v)", parent, err)
+		}
+	})
+
+	t.Run("Orphans repairs metadata of deleted branches", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		resetCommandFlags(t, untrackCmd, "purge", "reparent", "cascade", "orphans")
+
+		for _, b := range [][2]string{{"feature/a", "main"}, {"feature/b", "feature/a"}} {
+			testutils.RunCommand(t, repoPath, "git", "branch", b[0])
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+b[0]+".socle-parent", b[1])
+			testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch."+b[0]+".socle-base", "main")
+		}
+		// feature/a is deleted behind socle's back; feature/gone only left its metadata
+		testutils.RunCommand(t, repoPath, "git", "branch", "-D", "feature/a")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/gone.socle-parent", "main")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature/gone.socle-base", "main")
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feature/b")
+
+		if _, _, err := runSoCommandWithOutput(t, "log"); err == nil || !strings.Contains(err.Error(), "so untrack --orphans") {
+			t.Errorf("Expected the broken stack to point at 'so untrack --orphans', but got: %v", err)
+		}
+
+		stdout, _, err := runSoCommandWithOutput(t, "untrack", "--orphans")
+		if err != nil {
+			t.Fatalf("so untrack --orphans failed unexpectedly: %v", err)
+		}
+		if !strings.Contains(stdout, "Moved 'feature/b' from the missing 'feature/a' onto 'main'.") {
+			t.Errorf("Expected feature/b to be reported as moved, got:\n%s", stdout)
+		}
+		if parent, err := git.GetGitConfig("branch.feature/b.socle-parent"); err != nil || parent != "main" {
+			t.Errorf("Expected feature/b to be moved onto 'main', but got '%s' (%v)", parent, err)
+		}
+		if _, err := git.GetGitConfig("branch.feature/gone.socle-base"); err == nil {
+			t.Errorf("Expected the metadata of feature/gone to be removed")
+		}
+
+		stdout, _, err = runSoCommandWithOutput(t, "untrack", "--orphans")
+		if err != nil || !strings.Contains(stdout, "No orphaned tracking metadata found.") {
+			t.Errorf("Expected nothing left to repair, got %q (%v)", stdout, err)
+		}
+	})
 }
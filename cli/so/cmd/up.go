@@ -22,16 +22,18 @@ var upCmd = &cobra.Command{
 The stack is determined by the tracking information set via 'so track'.
 This command finds the immediate descendent of the current branch.
 
-If you are on a base branch with multiple stacks, you will be prompted to select which stack to navigate to.`,
+If you are on a base branch with multiple stacks, you will be prompted to select which stack to navigate to.
+Likewise, if several branches stack on the current branch, you will be prompted to select one of them.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		logger := slog.Default()
 
 		runner := &upCmdRunner{
-			logger: logger,
-			stdout: cmd.OutOrStdout(),
-			stderr: cmd.ErrOrStderr(),
-			stdin:  os.Stdin,
+			logger:         logger,
+			stdout:         cmd.OutOrStdout(),
+			stderr:         cmd.ErrOrStderr(),
+			stdin:          os.Stdin,
+			nonInteractive: nonInteractive,
 		}
 
 		return runner.run()
@@ -17,10 +17,12 @@ type upCmdRunner struct {
 	stdout io.Writer
 	stderr io.Writer
 	stdin  io.Reader
+
+	nonInteractive bool
 }
 
 func (r *upCmdRunner) run() error {
-	stackInfo, err := git.GetStackInfo()
+	stackInfo, err := git.GetNavigationStackInfo()
 	if err != nil {
 		_, _ = fmt.Fprintf(r.stdout, "Error getting stack info: %s\n", err)
 		return nil
@@ -63,7 +65,16 @@ func (r *upCmdRunner) run() error {
 		return checkoutBranch(branch, stackInfo.CurrentBranch)
 	}
 
-	// CASE 3: Standard linear stack
+	// CASE 3: The stack forks on the current branch -> choose a child
+	if children := stackInfo.ChildMap[stackInfo.CurrentBranch]; len(children) > 1 {
+		branch, selErr := r.childSelector().selectChild(stackInfo.CurrentBranch, children)
+		if selErr != nil {
+			return selErr
+		}
+		return checkoutBranch(branch, stackInfo.CurrentBranch)
+	}
+
+	// CASE 4: Standard linear stack
 	branch, msg, navErr := cmdutils.ComputeLinearTarget(stackInfo.CurrentBranch, stackInfo.FullStack, cmdutils.PurposeUp)
 	if navErr != nil {
 		return navErr
@@ -77,6 +88,16 @@ func (r *upCmdRunner) run() error {
 	return checkoutBranch(branch, stackInfo.CurrentBranch)
 }
 
+func (r *upCmdRunner) childSelector() childSelector {
+	return childSelector{
+		stdin:          r.stdin,
+		stderr:         r.stderr,
+		nonInteractive: r.nonInteractive,
+		testIndex:      testSelectStackIndex,
+		testChild:      testSelectStackChild,
+	}
+}
+
 // promptSelectStack provides interactive stack selection using shared utilities.
 func (r *upCmdRunner) promptSelectStack(baseBranch string, purpose cmdutils.NavigationPurpose) (string, bool, error) {
 	options, stacks, err := cmdutils.BuildStackSelectionOptions(baseBranch, purpose)
@@ -89,7 +110,7 @@ func (r *upCmdRunner) promptSelectStack(baseBranch string, purpose cmdutils.Navi
 	}
 	var selectedOption string
 	prompt := &survey.Select{Message: fmt.Sprintf("Multiple stacks available from '%s'. Select a stack:", baseBranch), Options: options}
-	err = survey.AskOne(prompt, &selectedOption, survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File)))
+	err = ui.AskOne(prompt, &selectedOption, survey.WithStdio(r.stdin.(*os.File), r.stderr.(*os.File), r.stderr.(*os.File)))
 	if err != nil {
 		return "", true, ui.HandleSurveyInterrupt(err, "Navigation cancelled.")
 	}
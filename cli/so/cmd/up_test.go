@@ -43,6 +43,31 @@ func TestUpCommand(t *testing.T) {
 		assert.Equal(t, "feat-b", currentBranch)
 	})
 
+	t.Run("Local changes that checkout would overwrite", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		t.Setenv("LANG", "de_DE.UTF-8") // Git's messages must not matter
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feat-a")
+		writeFile(t, repoPath, "a.txt", "a")
+		testutils.RunCommand(t, repoPath, "git", "add", ".")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-m", "a")
+		trackBranch(t, repoPath, "feat-a", "main", "main")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "feat-b")
+		writeFile(t, repoPath, "a.txt", "b")
+		testutils.RunCommand(t, repoPath, "git", "commit", "-am", "b")
+		trackBranch(t, repoPath, "feat-b", "feat-a", "main")
+
+		testutils.RunCommand(t, repoPath, "git", "checkout", "feat-a")
+		writeFile(t, repoPath, "a.txt", "local edit")
+
+		_, _, err := runSoCommandWithOutput(t, "up")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "uncommitted changes detected in 'feat-a'")
+	})
+
 	t.Run("Checkout child branch from base branch", func(t *testing.T) {
 		repoPath, cleanup := testutils.SetupGitRepo(t)
 		defer cleanup()
@@ -117,4 +142,35 @@ func TestUpCommand(t *testing.T) {
 		require.NoError(t, gitErr)
 		assert.Equal(t, "untracked-feat", currentBranch)
 	})
+
+	t.Run("Selects a child where the stack forks", func(t *testing.T) {
+		_, cleanup := setupRepoWithForkedStack(t)
+		defer cleanup()
+
+		_, stderr, err := runSoCommandWithOutput(t, "up", "--test-select-stack-child=feat-c")
+		require.NoError(t, err)
+		assert.Empty(t, stderr)
+		currentBranch, gitErr := git.GetCurrentBranch()
+		require.NoError(t, gitErr)
+		assert.Equal(t, "feat-c", currentBranch)
+
+		// Past the fork the stack is linear again
+		_, _, err = runSoCommandWithOutput(t, "up")
+		require.NoError(t, err)
+		currentBranch, gitErr = git.GetCurrentBranch()
+		require.NoError(t, gitErr)
+		assert.Equal(t, "feat-d", currentBranch)
+	})
+
+	t.Run("Non-interactive fails where the stack forks", func(t *testing.T) {
+		_, cleanup := setupRepoWithForkedStack(t)
+		defer cleanup()
+
+		_, _, err := runSoCommandWithOutput(t, "--non-interactive", "up")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "multiple branches stack on 'feat-a'")
+		currentBranch, gitErr := git.GetCurrentBranch()
+		require.NoError(t, gitErr)
+		assert.Equal(t, "feat-a", currentBranch)
+	})
 }
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var wipCmd = &cobra.Command{
+	Use:   "wip [branch]",
+	Short: "Toggle the work-in-progress mark on a branch",
+	Long: `Marks a tracked branch as work-in-progress, or clears the mark if it is
+already set. Defaults to the current branch.
+
+'so submit' never pushes or opens a PR for a WIP branch, nor for any branch
+stacked on top of it, since that would publish the WIP commits as well.
+'so log' shows a WIP badge next to marked branches.
+
+The mark is stored in git config as branch.<name>.socle-wip.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		branch := ""
+		if len(args) > 0 {
+			branch = args[0]
+		}
+
+		runner := &wipCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			branch: branch,
+		}
+		return runner.run()
+	},
+}
+
+func init() {
+	AddCommand(wipCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+type wipCmdRunner struct {
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+
+	branch string
+}
+
+func (r *wipCmdRunner) run() error {
+	branch := r.branch
+	if branch == "" {
+		current, err := git.GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+		branch = current
+	}
+
+	if _, err := git.GetGitConfig(git.BranchConfigKey(branch, "socle-parent")); err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return socleerr.New(socleerr.NotTracked, "branch '%s' is not tracked by socle. Use 'so track' first", branch)
+		}
+		return fmt.Errorf("failed to check tracking status for branch '%s': %w", branch, err)
+	}
+
+	wip, err := git.IsBranchWIP(branch)
+	if err != nil {
+		return fmt.Errorf("failed to read WIP mark for '%s': %w", branch, err)
+	}
+	if err := git.SetBranchWIP(branch, !wip); err != nil {
+		return fmt.Errorf("failed to update WIP mark for '%s': %w", branch, err)
+	}
+	r.logger.Debug("Toggled WIP mark", "branch", branch, "wip", !wip)
+
+	if wip {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.SuccessStyle.Render(fmt.Sprintf("Branch '%s' is no longer marked WIP.", branch)))
+	} else {
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.WarningStyle.Render(fmt.Sprintf("Branch '%s' is now marked WIP.", branch)))
+		_, _ = fmt.Fprintln(r.stdout, "'so submit' will skip it and every branch stacked on top of it.")
+	}
+	return nil
+}
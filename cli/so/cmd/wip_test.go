@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benekuehn/socle/cli/so/internal/gh"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/testutils"
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWipCommand(t *testing.T) {
+	t.Run("Toggles the WIP mark on the current branch", func(t *testing.T) {
+		_, cleanup := setupRepoWithStack(t, []string{"main", "feature-a"})
+		defer cleanup()
+
+		stdout, _, err := runSoCommandWithOutput(t, "wip")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Branch 'feature-a' is now marked WIP.")
+		wip, err := git.IsBranchWIP("feature-a")
+		require.NoError(t, err)
+		assert.True(t, wip)
+
+		stdout, _, err = runSoCommandWithOutput(t, "log")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "feature-a [WIP] (up-to-date, no PR submitted)")
+
+		stdout, _, err = runSoCommandWithOutput(t, "wip", "feature-a")
+		require.NoError(t, err)
+		assert.Contains(t, stripAnsi(stdout), "Branch 'feature-a' is no longer marked WIP.")
+		wip, err = git.IsBranchWIP("feature-a")
+		require.NoError(t, err)
+		assert.False(t, wip)
+	})
+
+	t.Run("Rejects untracked branches", func(t *testing.T) {
+		repoPath, cleanup := testutils.SetupGitRepo(t)
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "checkout", "-b", "loose")
+
+		err := runSoCommand(t, "wip")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not tracked by socle")
+	})
+
+	t.Run("Submit skips WIP branches and everything above them", func(t *testing.T) {
+		originalCreateGHClient := gh.CreateClient
+		t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b", "feature-c"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		require.NoError(t, git.SetBranchWIP("feature-b", true))
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+		mockClient.On("CreatePullRequest", "feature-a", "main", "feat: commit on feature-a", "Body", false).Return(
+			&github.PullRequest{Number: github.Ptr(101), HTMLURL: github.Ptr("url-a")}, nil,
+		).Once()
+		mockClient.On("FindCommentWithMarker", 101, mock.AnythingOfType("string")).Return(int64(0), nil).Once()
+		mockClient.On("CreateComment", 101, mock.AnythingOfType("string")).Return(&github.IssueComment{ID: github.Ptr(int64(1))}, nil).Once()
+		mockClient.On("GetMergeReadiness", 101).Return(&gh.MergeReadiness{Number: 101, State: "OPEN"}, nil).Once()
+
+		stdout, _, err := runSoCommandWithOutput(t, "submit", "--no-push", "--no-draft",
+			"--test-title=feat: commit on feature-a", "--test-body=Body")
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "CreatePullRequest", "feature-b", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		out := stripAnsi(stdout)
+		assert.Contains(t, out, "Skipping: 'feature-b' is marked WIP")
+		assert.Contains(t, out, "Skipping: stacked on WIP branch 'feature-b'.")
+	})
+
+	t.Run("Submit holds back a branch whose WIP mark cannot be read", func(t *testing.T) {
+		originalCreateGHClient := gh.CreateClient
+		t.Cleanup(func() { gh.CreateClient = originalCreateGHClient })
+
+		repoPath, cleanup := setupRepoWithStack(t, []string{"main", "feature-a", "feature-b"})
+		defer cleanup()
+		testutils.RunCommand(t, repoPath, "git", "remote", "add", "origin", "https://github.com/test-owner/test-repo.git")
+		testutils.RunCommand(t, repoPath, "git", "config", "--local", "branch.feature-a.socle-wip", "maybe")
+
+		mockClient := gh.NewMockClient()
+		gh.CreateClient = func(ctx context.Context, owner, repo string) (gh.ClientInterface, error) {
+			return mockClient, nil
+		}
+
+		stdout, stderr, err := runSoCommandWithOutput(t, "submit", "--no-push", "--no-draft",
+			"--test-title=feat: commit on feature-a", "--test-body=Body")
+		require.NoError(t, err)
+		mockClient.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		out := stripAnsi(stdout + stderr)
+		assert.Contains(t, out, "could not read the WIP mark of 'feature-a'")
+		assert.Contains(t, out, "invalid value 'maybe'")
+		assert.Contains(t, out, "Skipping: stacked on WIP branch 'feature-a'.")
+	})
+}
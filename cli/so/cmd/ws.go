@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var wsCmd = &cobra.Command{
+	Use:   "ws",
+	Short: "Run socle commands across the repositories of a workspace",
+	Long: `Groups commands that run a socle command in every repository listed in a
+workspace file, for work split across repositories. Each repository keeps its
+own stacks; there is no stacking across repositories.
+
+The workspace file, ` + workspaceFileName + `, lists one repository per line, as a
+path relative to the file or absolute. Blank lines and lines starting with '#'
+are ignored:
+
+  # services
+  api
+  web
+  ../shared/proto
+
+It is looked up in the current directory and its parents, or given with
+--file.
+
+The repositories run one after another, each in its own directory with its
+output as usual. A repository that fails does not stop the others; a summary
+at the end lists the outcome of each, and the command fails if any of them
+did. Flags for the command go after '--':
+
+  so ws sync -- --no-restack`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationNoRepo: "true"},
+}
+
+var wsListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List the repositories of the workspace",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{annotationNoRepo: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		runner := &wsCmdRunner{
+			logger: slog.Default(),
+			stdout: cmd.OutOrStdout(),
+			stderr: cmd.ErrOrStderr(),
+			file:   file,
+		}
+		return runner.list()
+	},
+}
+
+// newWorkspaceRunCmd returns the 'so ws <name>' command running 'so <name>'
+// in every repository of the workspace.
+func newWorkspaceRunCmd(name, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:         name + " [-- <" + name + " flags>]",
+		Short:       short,
+		Args:        cobra.ArbitraryArgs,
+		Annotations: map[string]string{annotationNoRepo: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			runner := &wsCmdRunner{
+				logger: slog.Default(),
+				stdin:  os.Stdin,
+				stdout: cmd.OutOrStdout(),
+				stderr: cmd.ErrOrStderr(),
+				file:   file,
+			}
+			return runner.run(cmd.Context(), name, args)
+		},
+	}
+}
+
+func init() {
+	AddCommand(wsCmd)
+	wsCmd.PersistentFlags().String("file", "", "Workspace file to use instead of the nearest "+workspaceFileName)
+	wsCmd.AddCommand(wsListCmd)
+	wsCmd.AddCommand(newWorkspaceRunCmd("log", "Show the stacks of every repository ('so log')"))
+	wsCmd.AddCommand(newWorkspaceRunCmd("sync", "Sync every repository ('so sync')"))
+	wsCmd.AddCommand(newWorkspaceRunCmd("restack", "Restack the current stack of every repository ('so restack')"))
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+// workspaceFileName is the file 'so ws' looks for in the current directory
+// and its parents.
+const workspaceFileName = ".socle-workspace"
+
+// workspaceRepo is a repository listed in a workspace file.
+type workspaceRepo struct {
+	name string // Directory name, as shown in the summary
+	path string // Absolute
+}
+
+// runInWorkspaceRepo runs 'so <args>' in dir. Tests run the command in
+// process instead of starting the test binary.
+var runInWorkspaceRepo = func(ctx context.Context, dir string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the so executable: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, self, args...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+type wsCmdRunner struct {
+	logger *slog.Logger
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	file string // Workspace file; empty to look it up
+}
+
+// wsResult is the outcome of the command in one repository.
+type wsResult struct {
+	repo workspaceRepo
+	err  error
+}
+
+func (r *wsCmdRunner) list() error {
+	file, repos, err := r.loadWorkspace()
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(r.stdout, ui.Colors.MutedStyle.Render(fmt.Sprintf("Workspace %s:", file)))
+	for _, repo := range repos {
+		_, _ = fmt.Fprintf(r.stdout, "  %s  %s\n", repo.name, ui.Colors.MutedStyle.Render(repo.path))
+	}
+	return nil
+}
+
+// run runs 'so <command> <args>' in each repository of the workspace in
+// turn, then summarizes. A failing repository doesn't stop the others.
+func (r *wsCmdRunner) run(ctx context.Context, command string, args []string) error {
+	_, repos, err := r.loadWorkspace()
+	if err != nil {
+		return err
+	}
+	soArgs := append([]string{command}, args...)
+	if nonInteractive {
+		soArgs = append(soArgs, "--non-interactive")
+	}
+
+	results := make([]wsResult, 0, len(repos))
+	for i, repo := range repos {
+		if ctx.Err() != nil {
+			results = append(results, wsResult{repo: repo, err: ctx.Err()})
+			continue
+		}
+		if i > 0 {
+			_, _ = fmt.Fprintln(r.stdout)
+		}
+		_, _ = fmt.Fprintln(r.stdout, ui.Colors.InfoStyle.Render(fmt.Sprintf("── %s", repo.name)))
+		r.logger.Debug("Running in workspace repository", "repo", repo.path, "args", soArgs)
+		err := r.runInRepo(ctx, repo, soArgs)
+		if err != nil {
+			_, _ = fmt.Fprintln(r.stderr, ui.Colors.FailureStyle.Render(fmt.Sprintf("✗ %s: %v", repo.name, err)))
+		}
+		results = append(results, wsResult{repo: repo, err: err})
+	}
+	return r.summarize(command, results)
+}
+
+// runInRepo checks that repo is a git repository before running the command
+// there, so a stale workspace entry fails with a clear message.
+func (r *wsCmdRunner) runInRepo(ctx context.Context, repo workspaceRepo, soArgs []string) error {
+	info, err := os.Stat(repo.path)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", repo.path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", repo.path)
+	}
+	if _, err := os.Stat(filepath.Join(repo.path, ".git")); err != nil {
+		return fmt.Errorf("%s is not a git repository", repo.path)
+	}
+	return runInWorkspaceRepo(ctx, repo.path, soArgs, r.stdin, r.stdout, r.stderr)
+}
+
+func (r *wsCmdRunner) summarize(command string, results []wsResult) error {
+	width := 0
+	for _, result := range results {
+		width = max(width, len(result.repo.name))
+	}
+	failed := 0
+	_, _ = fmt.Fprintln(r.stdout)
+	_, _ = fmt.Fprintf(r.stdout, "Workspace summary ('so %s'):\n", command)
+	for _, result := range results {
+		name := fmt.Sprintf("%-*s", width, result.repo.name)
+		if result.err != nil {
+			failed++
+			_, _ = fmt.Fprintf(r.stdout, "  %s %s  %s\n", ui.Colors.FailureStyle.Render("✗"), name, ui.Colors.MutedStyle.Render(result.err.Error()))
+			continue
+		}
+		_, _ = fmt.Fprintf(r.stdout, "  %s %s\n", ui.Colors.SuccessStyle.Render("✓"), name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("'so %s' failed in %d of %d repositories", command, failed, len(results))
+	}
+	return nil
+}
+
+// loadWorkspace reads the workspace file given with --file, or the nearest
+// one above the current directory, and returns its path and repositories.
+func (r *wsCmdRunner) loadWorkspace() (string, []workspaceRepo, error) {
+	file := r.file
+	if file == "" {
+		found, err := findWorkspaceFile()
+		if err != nil {
+			return "", nil, err
+		}
+		file = found
+	}
+	repos, err := parseWorkspaceFile(file)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(repos) == 0 {
+		return "", nil, fmt.Errorf("workspace file %s lists no repositories", file)
+	}
+	return file, repos, nil
+}
+
+// findWorkspaceFile looks for workspaceFileName in the current directory and
+// its parents.
+func findWorkspaceFile() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	for {
+		path := filepath.Join(dir, workspaceFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no %s found in the current directory or its parents; create one listing the repositories, or pass --file", workspaceFileName)
+		}
+		dir = parent
+	}
+}
+
+// parseWorkspaceFile reads the repositories listed in file, resolving
+// relative paths against its directory. A repository listed twice runs once.
+func parseWorkspaceFile(file string) ([]workspaceRepo, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("workspace file %s does not exist", file)
+		}
+		return nil, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	base, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace directory: %w", err)
+	}
+	var repos []workspaceRepo
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		path := line
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(base, path)
+		}
+		path = filepath.Clean(path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		repos = append(repos, workspaceRepo{name: filepath.Base(path), path: path})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+	return repos, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runWorkspaceReposInProcess makes 'so ws' run its command in process, since
+// the test binary can't stand in for so.
+func runWorkspaceReposInProcess(t *testing.T) {
+	t.Helper()
+	original := runInWorkspaceRepo
+	t.Cleanup(func() { runInWorkspaceRepo = original })
+	runInWorkspaceRepo = func(_ context.Context, dir string, args []string, _ io.Reader, stdout, stderr io.Writer) error {
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+		root, err := initializeCobraAppForTest()
+		require.NoError(t, err)
+		root.SetOut(stdout)
+		root.SetErr(stderr)
+		root.SetArgs(args)
+		return root.Execute()
+	}
+}
+
+func TestWsCommand(t *testing.T) {
+	runWorkspaceReposInProcess(t)
+	resetCommandFlags(t, wsCmd, "file")
+
+	apiPath, cleanupAPI := setupRepoWithStack(t, []string{"main", "api-a", "api-b"})
+	defer cleanupAPI()
+	webPath, cleanupWeb := setupRepoWithStack(t, []string{"main", "web-a"})
+	defer cleanupWeb()
+
+	workspace := t.TempDir()
+	require.NoError(t, os.Symlink(apiPath, filepath.Join(workspace, "api")))
+	writeFile(t, workspace, workspaceFileName, "# services\napi\n\n"+webPath+"\nmissing\napi\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "notes"), 0o755))
+
+	t.Run("list finds the file in a parent directory", func(t *testing.T) {
+		require.NoError(t, os.Chdir(filepath.Join(workspace, "notes")))
+		stdout, _, err := runSoCommandWithOutput(t, "ws", "list")
+		require.NoError(t, err)
+		output := stripAnsi(stdout)
+		assert.Contains(t, output, filepath.Join(workspace, workspaceFileName))
+		assert.Contains(t, output, filepath.Join(workspace, "api"))
+		assert.Contains(t, output, webPath)
+		assert.Contains(t, output, filepath.Join(workspace, "missing"))
+	})
+
+	t.Run("log runs in every repository and isolates failures", func(t *testing.T) {
+		require.NoError(t, os.Chdir(workspace))
+		stdout, stderr, err := runSoCommandWithOutput(t, "ws", "log")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'so log' failed in 1 of 3 repositories")
+
+		output := stripAnsi(stdout)
+		assert.Contains(t, output, "── api")
+		assert.Contains(t, output, "api-b")
+		assert.Contains(t, output, "── "+filepath.Base(webPath))
+		assert.Contains(t, output, "web-a")
+		assert.Contains(t, output, "Workspace summary ('so log'):")
+		assert.Regexp(t, `✓ api\s*\n`, output)
+		assert.Regexp(t, `✗ missing\s+cannot open`, output)
+		assert.Contains(t, stripAnsi(stderr), "✗ missing: cannot open")
+	})
+
+	t.Run("file flag and missing file", func(t *testing.T) {
+		require.NoError(t, os.Chdir(t.TempDir()))
+		_, _, err := runSoCommandWithOutput(t, "ws", "log")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no "+workspaceFileName+" found")
+
+		other := t.TempDir()
+		writeFile(t, other, "repos", webPath+"\n")
+		stdout, _, err := runSoCommandWithOutput(t, "ws", "--file", filepath.Join(other, "repos"), "log")
+		require.NoError(t, err)
+		output := stripAnsi(stdout)
+		assert.Contains(t, output, "web-a")
+		assert.NotContains(t, output, "api-b")
+		assert.Regexp(t, `✓ `+filepath.Base(webPath), output)
+	})
+}
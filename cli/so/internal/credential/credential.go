@@ -0,0 +1,240 @@
+// Package credential finds the API token for a code forge by trying a chain
+// of sources in order: an environment variable, the forge's CLI, the OS
+// keychain. Each forge backend describes its chain; the lookup order, the
+// on-disk cache and its expiry are shared.
+package credential
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	cmdexec "github.com/benekuehn/socle/cli/so/internal/exec"
+)
+
+// cacheDirName is socle's directory in the user's cache directory.
+const cacheDirName = "socle"
+
+// Provider is one place a token can come from.
+type Provider interface {
+	// Name describes the source in debug output and errors, e.g. "GITHUB_TOKEN".
+	Name() string
+	// Token returns the token, or "" when the source has none. Errors are
+	// for sources that are set up but fail.
+	Token() (string, error)
+}
+
+// Chain looks a forge's token up in Providers, first one wins.
+type Chain struct {
+	Forge     string // Named in the error when no provider has a token, e.g. "GitHub"
+	Providers []Provider
+	Hint      string // How to set up a token, appended to that error
+}
+
+// Resolve returns the first token a provider has and the provider's name.
+// A provider that fails is skipped like one without a token; its error is
+// only reported when no later provider has a token either.
+func (c Chain) Resolve() (token, source string, err error) {
+	names := make([]string, 0, len(c.Providers))
+	var failures []string
+	for _, p := range c.Providers {
+		names = append(names, p.Name())
+		token, err := p.Token()
+		if err != nil {
+			slog.Debug("Credential source failed, trying the next one.", "forge", c.Forge, "source", p.Name(), "error", err)
+			failures = append(failures, fmt.Sprintf("%s failed: %v", p.Name(), err))
+			continue
+		}
+		if token != "" {
+			return token, p.Name(), nil
+		}
+		slog.Debug("No token from credential source, trying the next one.", "forge", c.Forge, "source", p.Name())
+	}
+	tried := strings.Join(names, ", ")
+	if len(failures) > 0 {
+		tried += "; " + strings.Join(failures, "; ")
+	}
+	return "", "", fmt.Errorf("authentication failed: no %s token found (tried %s). %s", c.Forge, tried, c.Hint)
+}
+
+// Env reads the token from an environment variable.
+type Env struct {
+	Var string
+}
+
+func (e Env) Name() string { return e.Var }
+
+func (e Env) Token() (string, error) {
+	return strings.TrimSpace(os.Getenv(e.Var)), nil
+}
+
+// Command runs a forge CLI that prints a token, such as 'gh auth token'. A
+// program missing from PATH has no token; one that fails is an error.
+type Command struct {
+	Label   string // e.g. "gh CLI"
+	Program string
+	Args    []string
+}
+
+func (c Command) Name() string { return c.Label }
+
+func (c Command) Token() (string, error) {
+	if _, err := exec.LookPath(c.Program); err != nil {
+		slog.Debug("Credential helper not found in PATH.", "program", c.Program)
+		return "", nil
+	}
+	output, err := cmdexec.RunExternalCommand(c.Program, c.Args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Keychain reads a generic password stored for Service and Account in the
+// OS keychain: with 'security' on macOS and 'secret-tool' (libsecret) on
+// Linux. Other systems, a missing tool or a missing entry have no token.
+type Keychain struct {
+	Service string
+	Account string
+}
+
+func (k Keychain) Name() string { return "OS keychain" }
+
+func (k Keychain) Token() (string, error) {
+	var program string
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		program, args = "security", []string{"find-generic-password", "-s", k.Service, "-a", k.Account, "-w"}
+	case "linux":
+		program, args = "secret-tool", []string{"lookup", "service", k.Service, "account", k.Account}
+	default:
+		return "", nil
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return "", nil
+	}
+	output, err := cmdexec.RunExternalCommand(program, args...)
+	if err != nil {
+		// Both tools exit non-zero for a missing entry; that is no token, not a failure
+		slog.Debug("No keychain entry.", "service", k.Service, "account", k.Account, "error", err)
+		return "", nil
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Cached keeps the token of a slow Provider, such as a CLI, in a file for
+// TTL. Without a Path it passes straight through.
+type Cached struct {
+	Provider
+	Path string
+	TTL  time.Duration
+}
+
+func (c Cached) Token() (string, error) {
+	if c.Path == "" {
+		return c.Provider.Token()
+	}
+	cached, err := LoadCache(c.Path)
+	if err != nil {
+		slog.Warn("Failed to load token from cache. Proceeding to fetch fresh token.", "path", c.Path, "error", err)
+		// Invalidate bad cache file by attempting to remove it
+		if errRemove := os.Remove(c.Path); errRemove != nil && !errors.Is(errRemove, fs.ErrNotExist) {
+			slog.Warn("Failed to remove corrupted cache file.", "path", c.Path, "error", errRemove)
+		}
+	}
+	if cached != nil {
+		slog.Debug("Using cached token.", "source", c.Name())
+		return cached.Token, nil
+	}
+
+	token, err := c.Provider.Token()
+	if err != nil || token == "" {
+		return token, err
+	}
+	if errSave := SaveCache(c.Path, token, c.TTL); errSave != nil {
+		slog.Warn("Failed to save fetched token to cache.", "path", c.Path, "error", errSave)
+	}
+	return token, nil
+}
+
+// CachePath returns where the cache file name lives, in socle's directory of
+// the user's cache directory.
+func CachePath(name string) (string, error) {
+	usrCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache directory: %w", err)
+	}
+	return filepath.Join(usrCacheDir, cacheDirName, name), nil
+}
+
+// CachedToken stores a token and its expiry time.
+type CachedToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoadCache returns the token cached in filePath, or nil when there is none
+// or it expired.
+func LoadCache(filePath string) (*CachedToken, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		slog.Debug("Cache file does not exist.", "path", filePath)
+		return nil, nil // Not an error, just no cache
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file '%s': %w", filePath, err)
+	}
+
+	if len(data) == 0 {
+		slog.Debug("Cache file is empty.", "path", filePath)
+		return nil, nil // Not an error, just empty cache
+	}
+
+	var cachedToken CachedToken
+	if err := json.Unmarshal(data, &cachedToken); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached token from '%s': %w", filePath, err)
+	}
+
+	if time.Now().After(cachedToken.ExpiresAt) {
+		slog.Debug("Cached token is expired.", "path", filePath, "expires_at", cachedToken.ExpiresAt)
+		return nil, nil // Expired, treat as no cache
+	}
+
+	slog.Debug("Successfully loaded valid token from cache.", "path", filePath, "expires_at", cachedToken.ExpiresAt)
+	return &cachedToken, nil
+}
+
+// SaveCache writes token to filePath, readable only by the user, to expire
+// after ttl.
+func SaveCache(filePath string, token string, ttl time.Duration) error {
+	cacheDir := filepath.Dir(filePath)
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory '%s': %w", cacheDir, err)
+	}
+
+	tokenData := CachedToken{
+		Token:     token,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	data, err := json.MarshalIndent(tokenData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token for cache: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token to cache file '%s': %w", filePath, err)
+	}
+	slog.Debug("Successfully saved token to cache.", "path", filePath, "expires_at", tokenData.ExpiresAt)
+	return nil
+}
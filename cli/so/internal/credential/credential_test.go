@@ -0,0 +1,87 @@
+package credential
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider returns a fixed token or error and counts its calls.
+type fakeProvider struct {
+	name  string
+	token string
+	err   error
+	calls int
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) Token() (string, error) {
+	f.calls++
+	return f.token, f.err
+}
+
+func TestChainResolve(t *testing.T) {
+	t.Run("First provider with a token wins", func(t *testing.T) {
+		empty := &fakeProvider{name: "empty"}
+		first := &fakeProvider{name: "first", token: "t1"}
+		second := &fakeProvider{name: "second", token: "t2"}
+		chain := Chain{Forge: "GitHub", Providers: []Provider{empty, first, second}}
+
+		token, source, err := chain.Resolve()
+		require.NoError(t, err)
+		assert.Equal(t, "t1", token)
+		assert.Equal(t, "first", source)
+		assert.Equal(t, 0, second.calls, "later providers are not asked")
+	})
+
+	t.Run("A failing provider falls through to the next", func(t *testing.T) {
+		chain := Chain{Forge: "GitHub", Providers: []Provider{
+			&fakeProvider{name: "gh CLI", err: errors.New("exit status 1")},
+			&fakeProvider{name: "OS keychain", token: "t"},
+		}}
+
+		token, source, err := chain.Resolve()
+		require.NoError(t, err)
+		assert.Equal(t, "t", token)
+		assert.Equal(t, "OS keychain", source)
+	})
+
+	t.Run("No token names every source and the failures", func(t *testing.T) {
+		chain := Chain{
+			Forge: "GitHub",
+			Providers: []Provider{
+				&fakeProvider{name: "GITHUB_TOKEN"},
+				&fakeProvider{name: "gh CLI", err: errors.New("exit status 1")},
+			},
+			Hint: "Set GITHUB_TOKEN.",
+		}
+
+		_, _, err := chain.Resolve()
+		require.Error(t, err)
+		assert.Equal(t, "authentication failed: no GitHub token found (tried GITHUB_TOKEN, gh CLI; gh CLI failed: exit status 1). Set GITHUB_TOKEN.", err.Error())
+	})
+}
+
+func TestCachedToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socle", "token.json")
+	inner := &fakeProvider{name: "gh CLI", token: "fresh"}
+	cached := Cached{Provider: inner, Path: path, TTL: time.Hour}
+
+	for range 2 {
+		token, err := cached.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "fresh", token)
+	}
+	assert.Equal(t, 1, inner.calls, "the second lookup is served from the cache")
+
+	failedPath := filepath.Join(t.TempDir(), "token.json")
+	failing := Cached{Provider: &fakeProvider{name: "gh CLI", err: errors.New("boom")}, Path: failedPath, TTL: time.Hour}
+	_, err := failing.Token()
+	require.Error(t, err)
+	assert.NoFileExists(t, failedPath, "a failure is not cached")
+}
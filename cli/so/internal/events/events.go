@@ -0,0 +1,234 @@
+// Package events carries what a long-running command is doing as typed
+// events, so the command decides what happened and a Renderer decides how
+// (and whether) it is shown: as text, as JSON lines or in a TUI.
+package events
+
+import "sync"
+
+// Event is something a command reports while it runs. Kind names it in
+// machine-readable output.
+type Event interface {
+	Kind() string
+}
+
+// Renderer presents events. Render is never called concurrently.
+type Renderer interface {
+	Render(Event)
+}
+
+// Bus hands every emitted event to its renderers in order. It is safe for
+// concurrent use; a nil *Bus drops events.
+type Bus struct {
+	mu        sync.Mutex
+	renderers []Renderer
+}
+
+// New returns a Bus rendering to renderers.
+func New(renderers ...Renderer) *Bus {
+	return &Bus{renderers: renderers}
+}
+
+// Subscribe adds a renderer for events emitted from now on.
+func (b *Bus) Subscribe(r Renderer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.renderers = append(b.renderers, r)
+}
+
+// Emit renders e with every renderer.
+func (b *Bus) Emit(e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, r := range b.renderers {
+		r.Render(e)
+	}
+}
+
+// Info is a general progress message.
+type Info struct {
+	Message string `json:"message"`
+}
+
+// Warning is a problem the command works around. Branch is set when it
+// concerns one branch.
+type Warning struct {
+	Branch  string `json:"branch,omitempty"`
+	Message string `json:"message"`
+}
+
+// Step starts a phase of the command, e.g. updating stack comments.
+type Step struct {
+	Title string `json:"title"`
+}
+
+// StackStarted is emitted before the branches of a stack are processed.
+type StackStarted struct {
+	Base     string   `json:"base"`
+	Branches []string `json:"branches"`
+}
+
+// BranchStarted is emitted when the command starts on one branch of the stack.
+type BranchStarted struct {
+	Branch string `json:"branch"`
+	Parent string `json:"parent"`
+}
+
+// BranchSkipped reports a branch left alone, and why.
+type BranchSkipped struct {
+	Branch string `json:"branch"`
+	Reason string `json:"reason"`
+}
+
+// BranchPushed reports a successful push.
+type BranchPushed struct {
+	Branch string `json:"branch"`
+	Remote string `json:"remote"`
+}
+
+// PushFailed reports a push that failed without stopping the command.
+type PushFailed struct {
+	Branch string `json:"branch"`
+	Remote string `json:"remote"`
+	Error  string `json:"error"`
+}
+
+// PushSkipped reports a branch that was not pushed, and why.
+type PushSkipped struct {
+	Branch string `json:"branch"`
+	Reason string `json:"reason"`
+}
+
+// PRSubmitted reports the PR a branch was submitted as, whether it was
+// created or updated.
+type PRSubmitted struct {
+	Branch string `json:"branch"`
+	Number int    `json:"number"`
+}
+
+// CommentUpdated reports a PR whose stack comment is up to date.
+type CommentUpdated struct {
+	Branch string `json:"branch"`
+	Number int    `json:"number"`
+}
+
+// TrailersUpdated lists the branches whose commit trailers were rewritten.
+type TrailersUpdated struct {
+	Branches []string `json:"branches"`
+}
+
+// Success reports that a step completed without problems.
+type Success struct {
+	Message string `json:"message"`
+}
+
+// Cancelled reports that the user cancelled the operation.
+type Cancelled struct {
+	Operation string `json:"operation"`
+}
+
+// Finished ends an operation, listing the problems it ran into.
+type Finished struct {
+	Operation string   `json:"operation"`
+	Problems  []string `json:"problems,omitempty"`
+}
+
+// RebaseInProgress reports that a rebase is already paused in the repository.
+type RebaseInProgress struct{}
+
+// BranchRebased reports a branch rebased onto its parent.
+type BranchRebased struct {
+	Branch string `json:"branch"`
+	Parent string `json:"parent"`
+}
+
+// BranchUpToDate reports a branch already based on its parent's tip.
+type BranchUpToDate struct {
+	Branch string `json:"branch"`
+	Parent string `json:"parent"`
+}
+
+// BranchMergedIntoParent reports a branch whose commits its parent already
+// has, so rebasing it drops them all and leaves it empty.
+type BranchMergedIntoParent struct {
+	Branch string `json:"branch"`
+	Parent string `json:"parent"`
+}
+
+// RebaseConflict reports a rebase that stopped for the user to resolve.
+// Position is the branch's place among the Total branches being rebased.
+// Stopped is the commit whose replay conflicts, one of Commits. Owners maps
+// each of Files to the stack branch whose changes to it the rebase replays
+// onto.
+type RebaseConflict struct {
+	Branch   string            `json:"branch"`
+	Parent   string            `json:"parent"`
+	Position int               `json:"position,omitempty"`
+	Total    int               `json:"total,omitempty"`
+	Onto     string            `json:"onto,omitempty"`
+	Stopped  *Commit           `json:"stopped,omitempty"`
+	Commits  []Commit          `json:"commits,omitempty"`
+	Files    []string          `json:"files,omitempty"`
+	Owners   map[string]string `json:"owners,omitempty"`
+}
+
+// Commit identifies a commit in an event.
+type Commit struct {
+	OID     string `json:"oid"`
+	Subject string `json:"subject"`
+}
+
+// MergesFlattened warns that rebasing Branch drops its merge commits.
+// ConfigKey is the setting that keeps them.
+type MergesFlattened struct {
+	Branch    string   `json:"branch"`
+	Commits   []Commit `json:"commits"`
+	ConfigKey string   `json:"configKey"`
+}
+
+// StackRebased reports that every branch of the stack sits on its parent.
+type StackRebased struct {
+	Branches []string `json:"branches"`
+}
+
+// SecretFinding is one possible secret, with the match redacted.
+type SecretFinding struct {
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Rule  string `json:"rule"`
+	Match string `json:"match"`
+}
+
+// SecretsFound reports possible secrets in the lines a branch adds, from the
+// built-in rules and the output of the configured scan command.
+type SecretsFound struct {
+	Branch   string          `json:"branch"`
+	Findings []SecretFinding `json:"findings,omitempty"`
+	Report   string          `json:"report,omitempty"`
+}
+
+func (Info) Kind() string                   { return "info" }
+func (Warning) Kind() string                { return "warning" }
+func (Step) Kind() string                   { return "step" }
+func (StackStarted) Kind() string           { return "stack_started" }
+func (BranchStarted) Kind() string          { return "branch_started" }
+func (BranchSkipped) Kind() string          { return "branch_skipped" }
+func (BranchPushed) Kind() string           { return "branch_pushed" }
+func (PushFailed) Kind() string             { return "push_failed" }
+func (PushSkipped) Kind() string            { return "push_skipped" }
+func (PRSubmitted) Kind() string            { return "pr_submitted" }
+func (CommentUpdated) Kind() string         { return "comment_updated" }
+func (TrailersUpdated) Kind() string        { return "trailers_updated" }
+func (Success) Kind() string                { return "success" }
+func (Cancelled) Kind() string              { return "cancelled" }
+func (Finished) Kind() string               { return "finished" }
+func (RebaseInProgress) Kind() string       { return "rebase_in_progress" }
+func (BranchRebased) Kind() string          { return "branch_rebased" }
+func (BranchUpToDate) Kind() string         { return "branch_up_to_date" }
+func (BranchMergedIntoParent) Kind() string { return "branch_merged_into_parent" }
+func (RebaseConflict) Kind() string         { return "rebase_conflict" }
+func (MergesFlattened) Kind() string        { return "merges_flattened" }
+func (StackRebased) Kind() string           { return "stack_rebased" }
+func (SecretsFound) Kind() string           { return "secrets_found" }
@@ -0,0 +1,193 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/ui"
+)
+
+// TextRenderer prints events as the human-readable progress socle shows in a
+// terminal. Progress goes to stdout, warnings and instructions to stderr.
+type TextRenderer struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	branch string // Branch of the last BranchStarted; its events print as details
+	nested bool   // Within a Step or BranchStarted, so details are indented
+}
+
+// NewTextRenderer returns a TextRenderer writing to stdout and stderr.
+func NewTextRenderer(stdout, stderr io.Writer) *TextRenderer {
+	return &TextRenderer{stdout: stdout, stderr: stderr}
+}
+
+func (t *TextRenderer) indent() string {
+	if t.nested {
+		return "  "
+	}
+	return ""
+}
+
+// Render prints e. Events with nothing to add for a reader, such as a branch
+// that needed no rebase, print nothing.
+func (t *TextRenderer) Render(e Event) {
+	switch e := e.(type) {
+	case Info:
+		_, _ = fmt.Fprintln(t.stdout, ui.Colors.InfoStyle.Render(e.Message))
+	case Warning:
+		_, _ = fmt.Fprintln(t.stderr, ui.Colors.WarningStyle.Render(t.indent()+"Warning: "+e.Message))
+	case Step:
+		t.branch, t.nested = "", true
+		_, _ = fmt.Fprintln(t.stdout, "\n"+e.Title)
+	case StackStarted:
+		_, _ = fmt.Fprintln(t.stdout, "Processing stack...")
+	case BranchStarted:
+		t.branch, t.nested = e.Branch, true
+		_, _ = fmt.Fprintf(t.stdout, "\nProcessing branch: %s (parent: %s)\n", e.Branch, e.Parent)
+	case BranchSkipped:
+		_, _ = fmt.Fprintln(t.stdout, ui.Colors.WarningStyle.Render(t.indent()+"Skipping: "+e.Reason))
+	case BranchPushed:
+		if e.Branch == t.branch {
+			_, _ = fmt.Fprintln(t.stdout, ui.Colors.SuccessStyle.Render("  Branch pushed successfully."))
+			return
+		}
+		_, _ = fmt.Fprintf(t.stdout, "Pushing %s... %s\n", e.Branch, ui.Colors.SuccessStyle.Render("Success."))
+	case PushFailed:
+		_, _ = fmt.Fprintf(t.stdout, "Pushing %s... %s\n", e.Branch, ui.Colors.FailureStyle.Render("Failed!"))
+		_, _ = fmt.Fprintf(t.stderr, "  Error pushing %s: %s\n", e.Branch, e.Error)
+	case PushSkipped:
+		_, _ = fmt.Fprintf(t.stdout, "%sSkipping push (%s).\n", t.indent(), e.Reason)
+	case CommentUpdated:
+		_, _ = fmt.Fprintf(t.stdout, "  Stack comment processed for PR #%d.\n", e.Number)
+	case TrailersUpdated:
+		_, _ = fmt.Fprintf(t.stdout, "Updated commit trailers on %d branch(es).\n", len(e.Branches))
+	case Success:
+		_, _ = fmt.Fprintln(t.stdout, ui.Colors.SuccessStyle.Render(t.indent()+e.Message))
+	case Cancelled:
+		_, _ = fmt.Fprintln(t.stdout, ui.Colors.WarningStyle.Render(capitalize(e.Operation)+" operation cancelled."))
+	case Finished:
+		t.branch, t.nested = "", false
+		_, _ = fmt.Fprintf(t.stdout, "\n%s process finished.\n", capitalize(e.Operation))
+		if len(e.Problems) > 0 {
+			_, _ = fmt.Fprintln(t.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("\nEncountered warnings/errors during %s:", e.Operation)))
+			for _, p := range e.Problems {
+				_, _ = fmt.Fprintln(t.stderr, " - "+p)
+			}
+		}
+	case BranchMergedIntoParent:
+		_, _ = fmt.Fprintln(t.stdout, ui.Colors.InfoStyle.Render(fmt.Sprintf("'%s' is already merged into '%s'; dropping its commits.", e.Branch, e.Parent)))
+	case RebaseInProgress:
+		_, _ = fmt.Fprintln(t.stderr, ui.Colors.InfoStyle.Render("Git rebase already in progress."))
+		_, _ = fmt.Fprintln(t.stderr, ui.Colors.InfoStyle.Render("Resolve conflicts and run 'so continue' (or 'git rebase --continue' for a rebase of your own), or cancel with 'git rebase --abort'."))
+	case RebaseConflict:
+		_, _ = fmt.Fprintln(t.stderr, "")
+		_, _ = fmt.Fprintln(t.stderr, ui.Colors.WarningStyle.Render("⚠️ Rebase paused due to conflicts."))
+		t.renderConflictContext(e)
+		_, _ = fmt.Fprintf(t.stderr, "Please resolve the conflicts in branch '%s' and then run:\n", e.Branch)
+		_, _ = fmt.Fprintln(t.stderr, "  1. Run 'git add <resolved-files...>'.")
+		_, _ = fmt.Fprintln(t.stderr, "  2. Run 'so continue' to finish the rebase and restack the rest of the stack.")
+		_, _ = fmt.Fprintln(t.stderr, "   (To cancel, run 'git rebase --abort')")
+	case MergesFlattened:
+		_, _ = fmt.Fprintln(t.stderr, ui.Colors.WarningStyle.Render(fmt.Sprintf("  Warning: '%s' contains %d merge commit(s); a plain rebase flattens them:", e.Branch, len(e.Commits))))
+		for _, c := range e.Commits {
+			_, _ = fmt.Fprintf(t.stderr, "    %s %s\n", c.OID[:min(8, len(c.OID))], c.Subject)
+		}
+		_, _ = fmt.Fprintf(t.stderr, "  To keep them, use --rebase-merges or 'git config %s true'.\n", e.ConfigKey)
+	case SecretsFound:
+		_, _ = fmt.Fprintln(t.stderr, ui.Colors.FailureStyle.Render(fmt.Sprintf("Possible secrets in '%s':", e.Branch)))
+		for _, f := range e.Findings {
+			_, _ = fmt.Fprintf(t.stderr, "  %s:%d  %s (%s)\n", f.File, f.Line, f.Rule, f.Match)
+		}
+		for _, line := range strings.Split(e.Report, "\n") {
+			if line != "" {
+				_, _ = fmt.Fprintln(t.stderr, "  "+line)
+			}
+		}
+	case StackRebased:
+		_, _ = fmt.Fprintln(t.stdout, ui.Colors.SuccessStyle.Render("\n✓ Stack Rebase Completed Successfully\n"))
+	}
+}
+
+// renderConflictContext prints where in the stack the rebase stopped and
+// which of the replayed commits conflicts, so it is clear what is resolved.
+func (t *TextRenderer) renderConflictContext(e RebaseConflict) {
+	position := ""
+	if e.Total > 0 {
+		position = fmt.Sprintf(" (%d/%d)", e.Position, e.Total)
+	}
+	_, _ = fmt.Fprintf(t.stderr, "  Branch:  %s%s\n", e.Branch, position)
+	onto := e.Parent
+	if e.Onto != "" {
+		onto += " at " + shortOID(e.Onto)
+	}
+	_, _ = fmt.Fprintf(t.stderr, "  Onto:    %s\n", onto)
+
+	if len(e.Commits) > 0 {
+		_, _ = fmt.Fprintf(t.stderr, "  Replaying %d commit(s):\n", len(e.Commits))
+		applied := e.Stopped != nil
+		for _, c := range e.Commits {
+			line := fmt.Sprintf("%s %s", shortOID(c.OID), c.Subject)
+			switch {
+			case e.Stopped != nil && c.OID == e.Stopped.OID:
+				_, _ = fmt.Fprintln(t.stderr, ui.Colors.FailureStyle.Render("    ✗ "+line+"  <- conflicts"))
+				applied = false
+			case applied:
+				_, _ = fmt.Fprintln(t.stderr, ui.Colors.MutedStyle.Render("    ✓ "+line))
+			default:
+				_, _ = fmt.Fprintln(t.stderr, "      "+line)
+			}
+		}
+	}
+	if len(e.Files) > 0 {
+		_, _ = fmt.Fprintln(t.stderr, "  Conflicting files:")
+		for _, f := range e.Files {
+			if owner := e.Owners[f]; owner != "" {
+				_, _ = fmt.Fprintf(t.stderr, "    %s  %s\n", f, ui.Colors.MutedStyle.Render(fmt.Sprintf("(conflicts with changes from '%s')", owner)))
+				continue
+			}
+			_, _ = fmt.Fprintln(t.stderr, "    "+f)
+		}
+	}
+	_, _ = fmt.Fprintln(t.stderr, "")
+}
+
+func shortOID(oid string) string {
+	return oid[:min(7, len(oid))]
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// JSONRenderer writes every event as one JSON object per line, its kind in
+// the "event" field next to the event's own fields.
+type JSONRenderer struct {
+	w io.Writer
+}
+
+// NewJSONRenderer returns a JSONRenderer writing to w.
+func NewJSONRenderer(w io.Writer) *JSONRenderer {
+	return &JSONRenderer{w: w}
+}
+
+// Render writes e as a JSON line.
+func (j *JSONRenderer) Render(e Event) {
+	kind, _ := json.Marshal(e.Kind())
+	fields, err := json.Marshal(e)
+	if err != nil {
+		fields = []byte("{}")
+	}
+	line := `{"event":` + string(kind)
+	if len(fields) > 2 {
+		line += "," + string(fields[1:])
+	} else {
+		line += "}"
+	}
+	_, _ = fmt.Fprintln(j.w, line)
+}
@@ -0,0 +1,351 @@
+package gh
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/credential"
+	"github.com/benekuehn/socle/cli/so/internal/git"
+)
+
+// Auth modes selected with socle.auth.
+const (
+	AuthModeToken = "token" // GITHUB_TOKEN or the gh CLI
+	AuthModeApp   = "app"   // A GitHub App installation
+)
+
+// appAPIBaseURL, when set, is where app JWTs are exchanged for installation
+// tokens instead of the API of the remote's host (see appAPIBase). Tests
+// point it at a local server.
+var appAPIBaseURL = ""
+
+// appTokenMargin is how long before its expiry an installation token is
+// replaced, so a long-running command does not start with one about to lapse.
+const appTokenMargin = 5 * time.Minute
+
+// AuthMode returns the configured auth mode, AuthModeToken by default.
+func AuthMode() (string, error) {
+	mode, err := git.GetSocleConfig("socle.auth")
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return AuthModeToken, nil
+		}
+		return "", err
+	}
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case AuthModeToken, AuthModeApp:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid value '%s' for socle.auth: expected token or app", mode)
+}
+
+// appToken returns an installation token for owner/repo, from the cache or
+// freshly minted. With socle.githubApp.tokenExchangeUrl set and a GitHub
+// Actions OIDC token available, the token comes from that exchange service
+// and no private key is needed on the runner; otherwise socle signs a JWT with
+// the app's private key.
+func appToken(ctx context.Context, owner, repo string) (string, error) {
+	exchangeURL, _ := getAppConfig("socle.githubApp.tokenExchangeUrl")
+	exchange := exchangeURL != "" && os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL") != ""
+	cachePath, err := credential.CachePath(appTokenCacheName(owner, repo, exchange, exchangeURL))
+	if err == nil {
+		if cached, err := credential.LoadCache(cachePath); err == nil && cached != nil {
+			return cached.Token, nil
+		}
+	}
+
+	var token string
+	var expiresAt time.Time
+	if exchange {
+		slog.Debug("Exchanging GitHub Actions OIDC token for an installation token.", "url", exchangeURL)
+		var err error
+		if token, expiresAt, err = exchangeOIDCToken(ctx, exchangeURL, owner, repo); err != nil {
+			return "", err
+		}
+	} else {
+		creds, err := loadAppCredentials()
+		if err != nil {
+			return "", err
+		}
+		if token, expiresAt, err = creds.installationToken(ctx, owner, repo); err != nil {
+			return "", err
+		}
+	}
+
+	if cachePath != "" {
+		if ttl := time.Until(expiresAt) - appTokenMargin; ttl > 0 {
+			if err := credential.SaveCache(cachePath, token, ttl); err != nil {
+				slog.Warn("Failed to save installation token to cache.", "path", cachePath, "error", err)
+			}
+		}
+	}
+	return token, nil
+}
+
+// appTokenCacheName names the cache file of an installation token. The token
+// belongs to one app installation, so the configured app and installation
+// ids are part of the name, and for tokens from an exchange service its URL:
+// switching apps must not reuse another app's token.
+func appTokenCacheName(owner, repo string, exchange bool, exchangeURL string) string {
+	appID, _ := getAppConfig("socle.githubApp.id")
+	installationID, _ := getAppConfig("socle.githubApp.installationId")
+	issuer := fmt.Sprintf("%s_%s", cmp.Or(appID, "noapp"), cmp.Or(installationID, "auto"))
+	if exchange {
+		sum := sha256.Sum256([]byte(exchangeURL))
+		issuer = fmt.Sprintf("%s_exchange_%x", issuer, sum[:6])
+	}
+	return fmt.Sprintf("gh_app_token_%s_%s_%s.json", issuer, owner, repo)
+}
+
+// appAPIBase returns the REST API URL of the remote's GitHub host:
+// api.github.com for github.com, <host>/api/v3 for GitHub Enterprise Server.
+func appAPIBase() string {
+	if appAPIBaseURL != "" {
+		return strings.TrimSuffix(appAPIBaseURL, "/")
+	}
+	host := ""
+	if remoteURL, err := git.GetRemoteURL(git.GetRemoteName()); err == nil {
+		if parsed, err := git.ParseRemoteURL(remoteURL); err == nil {
+			host = parsed.Host
+		}
+	}
+	if host == "" || host == "github.com" {
+		return "https://api.github.com"
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// appCredentials identify a GitHub App and, optionally, its installation.
+type appCredentials struct {
+	appID          string
+	installationID string // Looked up from the repository when empty
+	key            *rsa.PrivateKey
+}
+
+func getAppConfig(key string) (string, error) {
+	val, err := git.GetSocleConfig(key)
+	if err != nil {
+		if errors.Is(err, git.ErrConfigNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(val), nil
+}
+
+// loadAppCredentials reads socle.githubApp.id, .installationId and
+// .privateKey. The private key setting is a path to the PEM file, or, for CI
+// secrets, the PEM itself.
+func loadAppCredentials() (*appCredentials, error) {
+	appID, err := getAppConfig("socle.githubApp.id")
+	if err != nil {
+		return nil, err
+	}
+	installationID, err := getAppConfig("socle.githubApp.installationId")
+	if err != nil {
+		return nil, err
+	}
+	keySetting, err := getAppConfig("socle.githubApp.privateKey")
+	if err != nil {
+		return nil, err
+	}
+	if appID == "" || keySetting == "" {
+		return nil, fmt.Errorf("socle.auth is 'app' but socle.githubApp.id or socle.githubApp.privateKey is not set")
+	}
+
+	keyPEM := []byte(keySetting)
+	if !strings.HasPrefix(keySetting, "-----BEGIN") {
+		if keyPEM, err = os.ReadFile(keySetting); err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+		}
+	}
+	key, err := parseAppPrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &appCredentials{appID: appID, installationID: installationID, key: key}, nil
+}
+
+// parseAppPrivateKey accepts the PKCS#1 key GitHub generates as well as
+// PKCS#8.
+func parseAppPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// jwt returns the short-lived RS256 token an app authenticates itself with.
+// It is backdated a minute to allow for clock drift.
+func (a *appCredentials) jwt(now time.Time) (string, error) {
+	encode := func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data), err
+	}
+	header, err := encode(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := encode(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + claims
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// installationToken mints an installation access token for owner/repo.
+func (a *appCredentials) installationToken(ctx context.Context, owner, repo string) (string, time.Time, error) {
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	installationID := a.installationID
+	if installationID == "" {
+		var installation struct {
+			ID int64 `json:"id"`
+		}
+		if err := appAPIRequest(ctx, http.MethodGet, fmt.Sprintf("repos/%s/%s/installation", url.PathEscape(owner), url.PathEscape(repo)), jwt, &installation); err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to find the GitHub App installation for %s/%s (is the app installed there?): %w", owner, repo, err)
+		}
+		installationID = strconv.FormatInt(installation.ID, 10)
+	}
+
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := appAPIRequest(ctx, http.MethodPost, fmt.Sprintf("app/installations/%s/access_tokens", url.PathEscape(installationID)), jwt, &resp); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create an installation token: %w", err)
+	}
+	if resp.Token == "" {
+		return "", time.Time{}, fmt.Errorf("GitHub returned an empty installation token")
+	}
+	return resp.Token, resp.ExpiresAt, nil
+}
+
+func appAPIRequest(ctx context.Context, method, path, jwt string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, appAPIBase()+"/"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return doAuthRequest(req, out)
+}
+
+// exchangeOIDCToken asks the runner for an OIDC token with the exchange
+// service as audience and trades it for an installation token. The service
+// answers with {"token": ..., "expires_at": ...}, like GitHub does.
+func exchangeOIDCToken(ctx context.Context, exchangeURL, owner, repo string) (string, time.Time, error) {
+	requestURL, err := url.Parse(os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL"))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	query := requestURL.Query()
+	query.Set("audience", exchangeURL)
+	requestURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN"))
+	var oidc struct {
+		Value string `json:"value"`
+	}
+	if err := doAuthRequest(req, &oidc); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get an OIDC token from the runner (does the job have 'id-token: write'?): %w", err)
+	}
+
+	body, _ := json.Marshal(map[string]string{"owner": owner, "repo": repo})
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, exchangeURL, bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+oidc.Value)
+	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := doAuthRequest(req, &resp); err != nil {
+		return "", time.Time{}, fmt.Errorf("token exchange at %s failed: %w", exchangeURL, err)
+	}
+	if resp.Token == "" {
+		return "", time.Time{}, fmt.Errorf("token exchange at %s returned no token", exchangeURL)
+	}
+	if resp.ExpiresAt.IsZero() {
+		resp.ExpiresAt = time.Now().Add(time.Hour) // Installation tokens last an hour
+	}
+	return resp.Token, resp.ExpiresAt, nil
+}
+
+func doAuthRequest(req *http.Request, out any) error {
+	client, err := NewHTTPClient()
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
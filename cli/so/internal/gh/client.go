@@ -2,34 +2,22 @@ package gh
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io/fs"
 	"log/slog"
 	"net/http"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
-	cmdexec "github.com/benekuehn/socle/cli/so/internal/exec"
+	"github.com/benekuehn/socle/cli/so/internal/credential"
+	"github.com/benekuehn/socle/cli/so/internal/profile"
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
 	"github.com/google/go-github/v71/github"
 	"golang.org/x/oauth2"
 )
 
-const (
-	cacheDirName  = "socle"
-	cacheFileName = "gh_token.json"
-	tokenCacheTTL = 1 * time.Hour
-)
-
-// CachedGhToken stores the GitHub token and its expiry time.
-type CachedGhToken struct {
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
-}
+// tokenCacheTTL is how long a token from the gh CLI is reused before gh is
+// asked again.
+const tokenCacheTTL = 1 * time.Hour
 
 // Client wraps the go-github client.
 type Client struct {
@@ -37,161 +25,95 @@ type Client struct {
 	Owner string
 	Repo  string
 	Ctx   context.Context // Background context for requests
+
+	retry retryPolicy // Zero value means defaultRetryPolicy
 }
 
 type ClientInterface interface {
 	GetPullRequest(number int) (*github.PullRequest, error)
 	CreatePullRequest(head, base, title, body string, isDraft bool) (*github.PullRequest, error)
 	UpdatePullRequestBase(number int, newBase string) (*github.PullRequest, error)
+	UpdatePullRequestBody(number int, body string) (*github.PullRequest, error)
+	MergePullRequest(number int, method, headOID string) error
 	FindPullRequestByHead(headBranch string) (*github.PullRequest, error)
 	CreateComment(issueNumber int, body string) (*github.IssueComment, error)
 	UpdateComment(commentID int64, body string) (*github.IssueComment, error)
 	FindCommentWithMarker(issueNumber int, marker string) (commentID int64, err error)
 	GetIssueComment(commentID int64) (*github.IssueComment, error)
 	GetPullRequestStatus(prNumber int) (status string, prURL string, err error)
+	AddLabels(issueNumber int, labels []string) error
+	RemoveLabel(issueNumber int, label string) error
+	FindMilestone(title string) (number int, err error)
+	SetMilestone(issueNumber int, milestoneNumber int) error
+	GetMergeReadiness(number int) (*MergeReadiness, error)
+	GetMergeableStates(numbers []int) (map[int]MergeableState, error)
+	RequestReviewers(number int, reviewers []string) error
+	RemoveReviewers(number int, reviewers []string) error
+	CloseIssue(number int) error
+	DeletesBranchOnMerge() (bool, error)
+	MergeMethods() ([]string, error)
+	RenameBranch(from, to string) error
+	ClosePullRequest(number int) error
 }
 
 var _ ClientInterface = (*Client)(nil)
 
-func getCacheFilePath() (string, error) {
-	usrCacheDir, err := os.UserCacheDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user cache directory: %w", err)
-	}
-	return filepath.Join(usrCacheDir, cacheDirName, cacheFileName), nil
-}
-
-func loadTokenFromCache(filePath string) (*CachedGhToken, error) {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		slog.Debug("Cache file does not exist.", "path", filePath)
-		return nil, nil // Not an error, just no cache
-	}
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read cache file '%s': %w", filePath, err)
-	}
-
-	if len(data) == 0 {
-		slog.Debug("Cache file is empty.", "path", filePath)
-		return nil, nil // Not an error, just empty cache
-	}
-
-	var cachedToken CachedGhToken
-	if err := json.Unmarshal(data, &cachedToken); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cached token from '%s': %w", filePath, err)
-	}
-
-	if time.Now().After(cachedToken.ExpiresAt) {
-		slog.Debug("Cached token is expired.", "path", filePath, "expires_at", cachedToken.ExpiresAt)
-		return nil, nil // Expired, treat as no cache
-	}
-
-	slog.Debug("Successfully loaded valid token from cache.", "path", filePath, "expires_at", cachedToken.ExpiresAt)
-	return &cachedToken, nil
-}
-
-func saveTokenToCache(filePath string, token string, ttl time.Duration) error {
-	cacheDir := filepath.Dir(filePath)
-	if err := os.MkdirAll(cacheDir, 0700); err != nil {
-		return fmt.Errorf("failed to create cache directory '%s': %w", cacheDir, err)
-	}
-
-	tokenData := CachedGhToken{
-		Token:     token,
-		ExpiresAt: time.Now().Add(ttl),
-	}
-
-	data, err := json.MarshalIndent(tokenData, "", "  ")
+// userCredentials is where a user's GitHub token is looked up: GITHUB_TOKEN,
+// then 'gh auth token' (cached for tokenCacheTTL), then an entry for service
+// "socle" and account "github.com" in the OS keychain.
+func userCredentials() credential.Chain {
+	cachePath, err := credential.CachePath("gh_token.json")
 	if err != nil {
-		return fmt.Errorf("failed to marshal token for cache: %w", err)
+		slog.Warn("Failed to determine cache file path. Proceeding without cache.", "error", err)
 	}
-
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write token to cache file '%s': %w", filePath, err)
+	return credential.Chain{
+		Forge: "GitHub",
+		Providers: []credential.Provider{
+			credential.Env{Var: "GITHUB_TOKEN"},
+			credential.Cached{
+				Provider: credential.Command{Label: "gh CLI", Program: "gh", Args: []string{"auth", "token"}},
+				Path:     cachePath,
+				TTL:      tokenCacheTTL,
+			},
+			credential.Keychain{Service: "socle", Account: "github.com"},
+		},
+		Hint: "Please set GITHUB_TOKEN, install and authenticate GitHub CLI ('gh auth login'), or store a token in the OS keychain for service 'socle' and account 'github.com'",
 	}
-	slog.Debug("Successfully saved token to cache.", "path", filePath, "expires_at", tokenData.ExpiresAt)
-	return nil
 }
 
 // NewClient creates a new GitHub client.
-// It prioritizes GITHUB_TOKEN env var, then a cached token from 'gh auth token',
-// then a fresh 'gh auth token' call if no valid cache.
+// With socle.auth set to "app" it authenticates as a GitHub App installation
+// (see appToken); otherwise it uses userToken.
 func NewClient(ctx context.Context, owner, repo string) (*Client, error) {
-	token := os.Getenv("GITHUB_TOKEN")
-	authMethod := "GITHUB_TOKEN"
-
-	if token == "" {
-		authMethod = "gh CLI (cached)"
-		cacheFilePath, err := getCacheFilePath()
-		if err != nil {
-			slog.Warn("Failed to determine cache file path. Proceeding without cache.", "error", err)
-			// Proceed without cache, will try gh auth token directly.
-		} else {
-			cachedToken, errLoad := loadTokenFromCache(cacheFilePath)
-			if errLoad != nil {
-				slog.Warn("Failed to load token from cache. Proceeding to fetch fresh token.", "path", cacheFilePath, "error", errLoad)
-				// Invalidate bad cache file by attempting to remove it
-				if errRemove := os.Remove(cacheFilePath); errRemove != nil && !errors.Is(errRemove, fs.ErrNotExist) {
-					slog.Warn("Failed to remove corrupted cache file.", "path", cacheFilePath, "error", errRemove)
-				}
-			}
-			if cachedToken != nil {
-				token = cachedToken.Token
-			}
-		}
-
-		if token == "" { // Still no token (no GITHUB_TOKEN, no valid cache)
-			authMethod = "gh CLI (live)"
-			slog.Debug("GITHUB_TOKEN not set and no valid cached token. Checking 'gh' CLI for authentication...")
-
-			ghPath, errLookPath := exec.LookPath("gh")
-			if errLookPath != nil {
-				return nil, fmt.Errorf("authentication failed: GITHUB_TOKEN not set, no cached token, and 'gh' CLI not found in PATH. Please set GITHUB_TOKEN or install and authenticate GitHub CLI ('gh auth login')")
-			}
-			slog.Debug("Found 'gh' CLI. Attempting to fetch token...", "ghPath", ghPath)
-
-			// Check if gh cli is installed by trying to run 'gh --version'
-			// This check might be redundant if LookPath succeeded and 'gh auth token' works,
-			// but keeping for robustness.
-			_, errVersion := cmdexec.RunExternalCommand("gh", "--version")
-			if errVersion != nil {
-				return nil, fmt.Errorf("gh cli not installed or not found in PATH (despite LookPath success): %w. Please run 'gh auth login' or set GITHUB_TOKEN", errVersion)
-			}
-
-			ghToken, errGhAuth := cmdexec.RunExternalCommand("gh", "auth", "token")
-			if errGhAuth != nil {
-				return nil, fmt.Errorf("error getting token via 'gh auth token': %w. Please run 'gh auth login' or set GITHUB_TOKEN", errGhAuth)
-			}
-			if ghToken == "" {
-				return nil, fmt.Errorf("authentication failed: GITHUB_TOKEN not set, no cache, and 'gh auth token' returned empty. Please run 'gh auth login' or set GITHUB_TOKEN")
-			}
-
-			token = strings.TrimSpace(ghToken)
-			slog.Debug("Successfully retrieved token using 'gh auth token'.")
-
-			if cacheFilePath != "" { // If we determined a cache path earlier
-				if errSave := saveTokenToCache(cacheFilePath, token, tokenCacheTTL); errSave != nil {
-					slog.Warn("Failed to save fetched token to cache.", "path", cacheFilePath, "error", errSave)
-				}
-			}
+	mode, err := AuthMode()
+	if err != nil {
+		return nil, err
+	}
+	var token, authMethod string
+	if mode == AuthModeApp {
+		authMethod = "GitHub App"
+		if token, err = appToken(ctx, owner, repo); err != nil {
+			return nil, socleerr.New(socleerr.AuthFailure, "GitHub App authentication failed: %w", err)
 		}
+	} else if token, authMethod, err = userCredentials().Resolve(); err != nil {
+		return nil, socleerr.Wrap(socleerr.AuthFailure, err)
 	}
 
 	slog.Debug("Using token for GitHub client.", "auth_method", authMethod)
 
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	transport := &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 100,
-		IdleConnTimeout:     90 * time.Second,
+	transport, err := newTransport()
+	if err != nil {
+		return nil, err
 	}
 	httpClientWithTimeout := &http.Client{
-		Transport: &oauth2.Transport{
-			Base:   transport,
-			Source: ts,
+		Transport: &profile.Transport{
+			Base: &oauth2.Transport{
+				Base:   transport,
+				Source: ts,
+			},
 		},
-		Timeout: 15 * time.Second,
+		Timeout: requestTimeout,
 	}
 	ghClient := github.NewClient(httpClientWithTimeout)
 
@@ -204,7 +126,11 @@ func NewClient(ctx context.Context, owner, repo string) (*Client, error) {
 
 // GetPullRequest retrieves a specific PR by number.
 func (c *Client) GetPullRequest(number int) (*github.PullRequest, error) {
-	pr, _, err := c.gh.PullRequests.Get(c.Ctx, c.Owner, c.Repo, number)
+	var pr *github.PullRequest
+	err := c.withRetry("get pull request", func(int) (err error) {
+		pr, _, err = c.gh.PullRequests.Get(c.Ctx, c.Owner, c.Repo, number)
+		return err
+	})
 	if err != nil {
 		if ghErr, ok := err.(*github.ErrorResponse); ok && ghErr.Response.StatusCode == http.StatusNotFound {
 			return nil, fmt.Errorf("pull request #%d not found", number)
@@ -225,7 +151,17 @@ func (c *Client) CreatePullRequest(head, base, title, body string, isDraft bool)
 		MaintainerCanModify: github.Ptr(true), // Sensible default
 	}
 
-	pr, _, err := c.gh.PullRequests.Create(c.Ctx, c.Owner, c.Repo, newPR)
+	var pr *github.PullRequest
+	err := c.withRetry("create pull request", func(attempt int) (err error) {
+		pr, _, err = c.gh.PullRequests.Create(c.Ctx, c.Owner, c.Repo, newPR)
+		if err != nil && attempt > 1 && isAlreadyExists(err) {
+			// An earlier try went through before its response was lost.
+			if existing, errFind := c.FindPullRequestByHead(head); errFind == nil && existing != nil {
+				pr, err = existing, nil
+			}
+		}
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pull request (%s -> %s): %w", head, base, err)
 	}
@@ -237,13 +173,45 @@ func (c *Client) UpdatePullRequestBase(number int, newBase string) (*github.Pull
 	update := &github.PullRequest{
 		Base: &github.PullRequestBranch{Ref: github.Ptr(newBase)},
 	}
-	pr, _, err := c.gh.PullRequests.Edit(c.Ctx, c.Owner, c.Repo, number, update)
+	var pr *github.PullRequest
+	err := c.withRetry("update pull request base", func(int) (err error) {
+		pr, _, err = c.gh.PullRequests.Edit(c.Ctx, c.Owner, c.Repo, number, update)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update base for pull request #%d to '%s': %w", number, newBase, err)
 	}
 	return pr, nil
 }
 
+// UpdatePullRequestBody replaces the description of an existing PR.
+func (c *Client) UpdatePullRequestBody(number int, body string) (*github.PullRequest, error) {
+	update := &github.PullRequest{Body: github.Ptr(body)}
+	var pr *github.PullRequest
+	err := c.withRetry("update pull request body", func(int) (err error) {
+		pr, _, err = c.gh.PullRequests.Edit(c.Ctx, c.Owner, c.Repo, number, update)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the description of pull request #%d: %w", number, err)
+	}
+	return pr, nil
+}
+
+// MergePullRequest merges a PR with method: merge, squash or rebase. Unless
+// headOID is empty, GitHub refuses the merge when the PR's head moved past
+// it, so a commit pushed after the checks were read is never merged unseen.
+// It is not retried, as a merge that reached GitHub must not be attempted
+// twice.
+func (c *Client) MergePullRequest(number int, method, headOID string) error {
+	opts := &github.PullRequestOptions{MergeMethod: method, SHA: headOID}
+	if _, _, err := c.gh.PullRequests.Merge(c.Ctx, c.Owner, c.Repo, number, "", opts); err != nil {
+		return fmt.Errorf("failed to merge pull request #%d: %w", number, err)
+	}
+	ForgetPullRequestStatus(c.Owner+"/"+c.Repo, number)
+	return nil
+}
+
 // FindPullRequestByHead finds the first open pull request whose head matches the provided branch.
 func (c *Client) FindPullRequestByHead(headBranch string) (*github.PullRequest, error) {
 	listOpts := &github.PullRequestListOptions{
@@ -254,7 +222,11 @@ func (c *Client) FindPullRequestByHead(headBranch string) (*github.PullRequest,
 		},
 	}
 
-	prs, _, err := c.gh.PullRequests.List(c.Ctx, c.Owner, c.Repo, listOpts)
+	var prs []*github.PullRequest
+	err := c.withRetry("find pull request", func(int) (err error) {
+		prs, _, err = c.gh.PullRequests.List(c.Ctx, c.Owner, c.Repo, listOpts)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pull requests for branch '%s': %w", headBranch, err)
 	}
@@ -271,7 +243,18 @@ func (c *Client) CreateComment(issueNumber int, body string) (*github.IssueComme
 	comment := &github.IssueComment{
 		Body: github.Ptr(body),
 	}
-	newComment, _, err := c.gh.Issues.CreateComment(c.Ctx, c.Owner, c.Repo, issueNumber, comment)
+	var newComment *github.IssueComment
+	err := c.withRetry("create comment", func(attempt int) (err error) {
+		if attempt > 1 {
+			// Don't post the comment twice if an earlier try went through.
+			if existing, errFind := c.findComment(issueNumber, func(b string) bool { return b == body }); errFind == nil && existing != nil {
+				newComment = existing
+				return nil
+			}
+		}
+		newComment, _, err = c.gh.Issues.CreateComment(c.Ctx, c.Owner, c.Repo, issueNumber, comment)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create comment on issue/PR #%d: %w", issueNumber, err)
 	}
@@ -283,7 +266,11 @@ func (c *Client) UpdateComment(commentID int64, body string) (*github.IssueComme
 	comment := &github.IssueComment{
 		Body: github.Ptr(body),
 	}
-	updatedComment, _, err := c.gh.Issues.EditComment(c.Ctx, c.Owner, c.Repo, commentID, comment)
+	var updatedComment *github.IssueComment
+	err := c.withRetry("update comment", func(int) (err error) {
+		updatedComment, _, err = c.gh.Issues.EditComment(c.Ctx, c.Owner, c.Repo, commentID, comment)
+		return err
+	})
 	if err != nil {
 		// Check if comment was deleted (returns 404 Not Found)
 		if ghErr, ok := err.(*github.ErrorResponse); ok && ghErr.Response.StatusCode == http.StatusNotFound {
@@ -296,7 +283,11 @@ func (c *Client) UpdateComment(commentID int64, body string) (*github.IssueComme
 
 // GetIssueComment retrieves a specific issue/PR comment by its ID.
 func (c *Client) GetIssueComment(commentID int64) (*github.IssueComment, error) {
-	comment, _, err := c.gh.Issues.GetComment(c.Ctx, c.Owner, c.Repo, commentID)
+	var comment *github.IssueComment
+	err := c.withRetry("get comment", func(int) (err error) {
+		comment, _, err = c.gh.Issues.GetComment(c.Ctx, c.Owner, c.Repo, commentID)
+		return err
+	})
 	if err != nil {
 		if ghErr, ok := err.(*github.ErrorResponse); ok && ghErr.Response.StatusCode == http.StatusNotFound {
 			return nil, fmt.Errorf("comment ID %d not found", commentID)
@@ -306,46 +297,177 @@ func (c *Client) GetIssueComment(commentID int64) (*github.IssueComment, error)
 	return comment, nil
 }
 
+// FindCommentWithMarker returns the ID of the first comment on the issue/PR
+// containing marker, or 0 if there is none.
 func (c *Client) FindCommentWithMarker(issueNumber int, marker string) (commentID int64, err error) {
-	// GitHub API typically paginates results. We need to handle pagination
-	// to ensure we check all comments.
-	opt := &github.IssueListCommentsOptions{
-		ListOptions: github.ListOptions{PerPage: 50}, // Get 100 per page
+	comment, err := c.findComment(issueNumber, func(body string) bool { return strings.Contains(body, marker) })
+	if err != nil {
+		return 0, err
 	}
-	var foundComment *github.IssueComment = nil
+	return comment.GetID(), nil
+}
 
+// findComment pages through the comments on an issue/PR and returns the first
+// one whose body matches, or nil.
+func (c *Client) findComment(issueNumber int, match func(body string) bool) (*github.IssueComment, error) {
+	opt := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 50},
+	}
 	for {
-		comments, resp, errList := c.gh.Issues.ListComments(c.Ctx, c.Owner, c.Repo, issueNumber, opt)
+		var comments []*github.IssueComment
+		var resp *github.Response
+		errList := c.withRetry("list comments", func(int) (err error) {
+			comments, resp, err = c.gh.Issues.ListComments(c.Ctx, c.Owner, c.Repo, issueNumber, opt)
+			return err
+		})
 		if errList != nil {
-			// Handle potential rate limiting or other API errors
-			return 0, fmt.Errorf("failed to list comments for PR #%d: %w", issueNumber, errList)
+			return nil, fmt.Errorf("failed to list comments for PR #%d: %w", issueNumber, errList)
 		}
 
-		// Search for the marker in the current page of comments
 		for _, comment := range comments {
-			if comment.Body != nil && strings.Contains(*comment.Body, marker) {
-				foundComment = comment
-				break // Found it, exit inner loop
+			if comment.Body != nil && match(*comment.Body) {
+				return comment, nil
 			}
 		}
 
-		if foundComment != nil {
-			break // Found it, exit outer loop
+		if resp.NextPage == 0 {
+			return nil, nil
 		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// AddLabels adds labels to an issue/PR, leaving existing labels in place.
+func (c *Client) AddLabels(issueNumber int, labels []string) error {
+	_, _, err := c.gh.Issues.AddLabelsToIssue(c.Ctx, c.Owner, c.Repo, issueNumber, labels)
+	if err != nil {
+		return fmt.Errorf("failed to add labels %v to #%d: %w", labels, issueNumber, err)
+	}
+	return nil
+}
+
+// RemoveLabel removes a single label from an issue/PR.
+func (c *Client) RemoveLabel(issueNumber int, label string) error {
+	_, err := c.gh.Issues.RemoveLabelForIssue(c.Ctx, c.Owner, c.Repo, issueNumber, label)
+	if err != nil {
+		return fmt.Errorf("failed to remove label '%s' from #%d: %w", label, issueNumber, err)
+	}
+	return nil
+}
 
-		// Check if there are more pages
+// FindMilestone looks up an open milestone by title and returns its number.
+// Returns 0 and a nil error if no milestone with that title exists.
+func (c *Client) FindMilestone(title string) (number int, err error) {
+	opt := &github.MilestoneListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		milestones, resp, errList := c.gh.Issues.ListMilestones(c.Ctx, c.Owner, c.Repo, opt)
+		if errList != nil {
+			return 0, fmt.Errorf("failed to list milestones: %w", errList)
+		}
+		for _, m := range milestones {
+			if m.GetTitle() == title {
+				return m.GetNumber(), nil
+			}
+		}
 		if resp.NextPage == 0 {
-			break // No more pages
+			return 0, nil
 		}
-		opt.Page = resp.NextPage // Move to the next page
+		opt.Page = resp.NextPage
 	}
+}
 
-	if foundComment != nil {
-		return foundComment.GetID(), nil // Return the found ID
+// SetMilestone assigns a milestone (by number) to an issue/PR.
+func (c *Client) SetMilestone(issueNumber int, milestoneNumber int) error {
+	update := &github.IssueRequest{Milestone: github.Ptr(milestoneNumber)}
+	_, _, err := c.gh.Issues.Edit(c.Ctx, c.Owner, c.Repo, issueNumber, update)
+	if err != nil {
+		return fmt.Errorf("failed to set milestone on #%d: %w", issueNumber, err)
 	}
+	return nil
+}
 
-	// Marker not found in any comment
-	return 0, nil // Return 0, nil error signifies "not found"
+// RequestReviewers requests reviews from the given users on a PR.
+func (c *Client) RequestReviewers(number int, reviewers []string) error {
+	_, _, err := c.gh.PullRequests.RequestReviewers(c.Ctx, c.Owner, c.Repo, number, github.ReviewersRequest{Reviewers: reviewers})
+	if err != nil {
+		return fmt.Errorf("failed to request reviewers %v on PR #%d: %w", reviewers, number, err)
+	}
+	return nil
+}
+
+// RemoveReviewers withdraws pending review requests from the given users on a PR.
+func (c *Client) RemoveReviewers(number int, reviewers []string) error {
+	_, err := c.gh.PullRequests.RemoveReviewers(c.Ctx, c.Owner, c.Repo, number, github.ReviewersRequest{Reviewers: reviewers})
+	if err != nil {
+		return fmt.Errorf("failed to remove reviewers %v from PR #%d: %w", reviewers, number, err)
+	}
+	return nil
+}
+
+// CloseIssue closes an issue as completed.
+func (c *Client) CloseIssue(number int) error {
+	req := &github.IssueRequest{State: github.Ptr("closed"), StateReason: github.Ptr("completed")}
+	_, _, err := c.gh.Issues.Edit(c.Ctx, c.Owner, c.Repo, number, req)
+	if err != nil {
+		return fmt.Errorf("failed to close issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// DeletesBranchOnMerge reports whether the repository deletes head branches
+// automatically when their pull request merges.
+func (c *Client) DeletesBranchOnMerge() (bool, error) {
+	repo, _, err := c.gh.Repositories.Get(c.Ctx, c.Owner, c.Repo)
+	if err != nil {
+		return false, fmt.Errorf("failed to get repository settings: %w", err)
+	}
+	return repo.GetDeleteBranchOnMerge(), nil
+}
+
+// MergeMethods lists the merge methods the repository allows, in the order
+// GitHub offers them: merge, squash, rebase.
+func (c *Client) MergeMethods() ([]string, error) {
+	repo, _, err := c.gh.Repositories.Get(c.Ctx, c.Owner, c.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository settings: %w", err)
+	}
+	var methods []string
+	if repo.GetAllowMergeCommit() {
+		methods = append(methods, "merge")
+	}
+	if repo.GetAllowSquashMerge() {
+		methods = append(methods, "squash")
+	}
+	if repo.GetAllowRebaseMerge() {
+		methods = append(methods, "rebase")
+	}
+	return methods, nil
+}
+
+// RenameBranch renames a branch of the repository. GitHub moves the open
+// pull requests based on it along; callers check the ones using it as head.
+// It is not retried, as a second attempt would find the branch gone.
+func (c *Client) RenameBranch(from, to string) error {
+	if _, _, err := c.gh.Repositories.RenameBranch(c.Ctx, c.Owner, c.Repo, from, to); err != nil {
+		return fmt.Errorf("failed to rename branch '%s' to '%s' on GitHub: %w", from, to, err)
+	}
+	return nil
+}
+
+// ClosePullRequest closes a pull request without merging it.
+func (c *Client) ClosePullRequest(number int) error {
+	update := &github.PullRequest{State: github.Ptr("closed")}
+	err := c.withRetry("close pull request", func(int) (err error) {
+		_, _, err = c.gh.PullRequests.Edit(c.Ctx, c.Owner, c.Repo, number, update)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to close pull request #%d: %w", number, err)
+	}
+	return nil
 }
 
 // CreateClient is a factory function for creating a GitHub client. It can be overridden in tests.
@@ -0,0 +1,121 @@
+package gh
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeownersLocations are checked in the order GitHub uses.
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// Codeowners maps repository paths to their owners.
+type Codeowners struct {
+	rules []codeownersRule
+}
+
+// LoadCodeowners reads the first CODEOWNERS file found under repoRoot.
+// Returns nil and a nil error when the repository has none.
+func LoadCodeowners(repoRoot string) (*Codeowners, error) {
+	for _, location := range codeownersLocations {
+		file, err := os.Open(filepath.Join(repoRoot, location))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", location, err)
+		}
+		defer func() { _ = file.Close() }()
+
+		co := &Codeowners{}
+		scanner := bufio.NewScanner(file)
+		for lineNo := 1; scanner.Scan(); lineNo++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if i := strings.Index(line, " #"); i >= 0 {
+				line = line[:i]
+			}
+			fields := strings.Fields(line)
+			re, err := codeownersPatternRegexp(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid pattern '%s': %w", location, lineNo, fields[0], err)
+			}
+			co.rules = append(co.rules, codeownersRule{pattern: re, owners: fields[1:]})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		return co, nil
+	}
+	return nil, nil
+}
+
+// Owners returns the owners of path (slash-separated, relative to the repo
+// root). As on GitHub, the last matching rule wins; a rule without owners
+// leaves the path unowned.
+func (c *Codeowners) Owners(path string) []string {
+	if c == nil {
+		return nil
+	}
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeownersPatternRegexp converts a gitignore-style CODEOWNERS pattern to a regexp.
+func codeownersPatternRegexp(pattern string) (*regexp.Regexp, error) {
+	// A leading slash, or a slash anywhere but the end, anchors to the repo root.
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; ch {
+		case '*':
+			if i+2 < len(pattern) && pattern[i+1] == '*' && pattern[i+2] == '/' {
+				b.WriteString("(?:.*/)?") // "**/" also matches zero directories
+				i += 2
+			} else if i+1 < len(pattern) && pattern[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(pattern, "/*"):
+		b.WriteString("$") // "docs/*" owns direct children only, as on GitHub
+	default:
+		b.WriteString("(?:/.*)?$") // A name also matches everything beneath it
+	}
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,30 @@
+package gh
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+	"github.com/google/go-github/v71/github"
+)
+
+// ErrorKind classifies a failed GitHub call: rejected credentials are an
+// AuthFailure, any other GitHub response or network failure an APIError.
+// Errors that didn't come from GitHub are Unknown.
+func ErrorKind(err error) socleerr.Kind {
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) {
+		if ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusUnauthorized {
+			return socleerr.AuthFailure
+		}
+		return socleerr.APIError
+	}
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var netErr net.Error
+	if errors.As(err, &rateErr) || errors.As(err, &abuseErr) || errors.As(err, &netErr) {
+		return socleerr.APIError
+	}
+	return socleerr.Unknown
+}
@@ -0,0 +1,391 @@
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/profile"
+	"github.com/google/go-github/v71/github"
+)
+
+// FakeServer is a minimal in-memory stand-in for the GitHub REST API. It
+// implements the pull request and issue comment endpoints 'so submit' uses,
+// so the real Client can run against it without network access or a token
+// (e.g. for 'so tutorial').
+type FakeServer struct {
+	Owner string
+	Repo  string
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	prs      map[int]*github.PullRequest
+	comments map[int64]*github.IssueComment
+	onIssue  map[int64]int // Comment ID -> issue number
+
+	lastNumber    int
+	lastCommentID int64
+
+	faults []*Fault
+
+	statePath string // Set by Persist
+
+	// DeleteBranchOnMerge is the repository's "automatically delete head
+	// branches" setting.
+	DeleteBranchOnMerge bool
+}
+
+// Fault makes the fake fail matching requests, e.g. to simulate GitHub
+// outages. Path matches when it is a substring of the request path.
+type Fault struct {
+	Method string
+	Path   string
+	Status int // HTTP status to answer with, e.g. 502
+	Times  int // Number of matching requests to fail
+
+	// AfterHandling applies the request before failing it, like a gateway
+	// timing out after GitHub already created the pull request or comment.
+	AfterHandling bool
+}
+
+// NewFakeServer starts a fake API for owner/repo. Call Close when done.
+func NewFakeServer(owner, repo string) *FakeServer {
+	f := &FakeServer{
+		Owner:    owner,
+		Repo:     repo,
+		prs:      make(map[int]*github.PullRequest),
+		comments: make(map[int64]*github.IssueComment),
+		onIssue:  make(map[int64]int),
+	}
+
+	prefix := fmt.Sprintf("/repos/%s/%s", owner, repo)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix, f.getRepo)
+	mux.HandleFunc("GET "+prefix+"/pulls", f.listPulls)
+	mux.HandleFunc("POST "+prefix+"/pulls", f.createPull)
+	mux.HandleFunc("GET "+prefix+"/pulls/{number}", f.getPull)
+	mux.HandleFunc("PATCH "+prefix+"/pulls/{number}", f.editPull)
+	// issues/{number}/comments and issues/comments/{id} overlap as patterns
+	mux.HandleFunc("GET "+prefix+"/issues/{first}/{second}", func(w http.ResponseWriter, req *http.Request) {
+		if req.PathValue("first") == "comments" {
+			f.getComment(w, req.PathValue("second"))
+			return
+		}
+		f.listComments(w, req.PathValue("first"))
+	})
+	mux.HandleFunc("POST "+prefix+"/branches/{branch...}", f.renameBranch)
+	mux.HandleFunc("POST "+prefix+"/issues/{number}/comments", f.createComment)
+	mux.HandleFunc("PATCH "+prefix+"/issues/comments/{id}", f.editComment)
+	f.server = httptest.NewServer(f.withFaults(f.withPersistence(mux)))
+	return f
+}
+
+// InjectFault fails the next fault.Times requests matching it.
+func (f *FakeServer) InjectFault(fault Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = append(f.faults, &fault)
+}
+
+func (f *FakeServer) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fault := f.takeFault(req)
+		if fault == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if fault.AfterHandling {
+			next.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		writeJSON(w, fault.Status, map[string]string{"message": http.StatusText(fault.Status)})
+	})
+}
+
+func (f *FakeServer) takeFault(req *http.Request) *Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, fault := range f.faults {
+		if fault.Times > 0 && fault.Method == req.Method && strings.Contains(req.URL.Path, fault.Path) {
+			fault.Times--
+			return fault
+		}
+	}
+	return nil
+}
+
+// Close shuts the server down.
+func (f *FakeServer) Close() { f.server.Close() }
+
+// Client returns a Client talking to the fake server.
+func (f *FakeServer) Client(ctx context.Context) *Client {
+	ghClient := github.NewClient(&http.Client{Transport: &profile.Transport{Base: http.DefaultTransport}})
+	baseURL, _ := url.Parse(f.server.URL + "/")
+	ghClient.BaseURL = baseURL
+	// The fake answers locally, so retries need no real back-off.
+	return &Client{gh: ghClient, Owner: f.Owner, Repo: f.Repo, Ctx: ctx, retry: retryPolicy{attempts: defaultRetryPolicy.attempts, baseDelay: time.Millisecond}}
+}
+
+// PullRequests returns copies of all pull requests, ordered by number.
+func (f *FakeServer) PullRequests() []github.PullRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	prs := make([]github.PullRequest, 0, len(f.prs))
+	for _, pr := range f.prs {
+		prs = append(prs, *pr)
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].GetNumber() < prs[j].GetNumber() })
+	return prs
+}
+
+// CommentsOn returns the bodies of the comments on an issue or PR, oldest first.
+func (f *FakeServer) CommentsOn(issueNumber int) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ids []int64
+	for id, number := range f.onIssue {
+		if number == issueNumber {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	bodies := make([]string, 0, len(ids))
+	for _, id := range ids {
+		bodies = append(bodies, f.comments[id].GetBody())
+	}
+	return bodies
+}
+
+func (f *FakeServer) htmlURL(kind string, number int) string {
+	return fmt.Sprintf("https://github.com/%s/%s/%s/%d", f.Owner, f.Repo, kind, number)
+}
+
+func (f *FakeServer) getRepo(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	writeJSON(w, http.StatusOK, &github.Repository{
+		Name:                github.Ptr(f.Repo),
+		FullName:            github.Ptr(f.Owner + "/" + f.Repo),
+		DeleteBranchOnMerge: github.Ptr(f.DeleteBranchOnMerge),
+		// GitHub allows every merge method on new repositories.
+		AllowMergeCommit: github.Ptr(true),
+		AllowSquashMerge: github.Ptr(true),
+		AllowRebaseMerge: github.Ptr(true),
+	})
+}
+
+func (f *FakeServer) listPulls(w http.ResponseWriter, req *http.Request) {
+	head := req.URL.Query().Get("head")
+	head = strings.TrimPrefix(head, f.Owner+":")
+	state := req.URL.Query().Get("state")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := []*github.PullRequest{}
+	for _, pr := range f.prs {
+		if head != "" && pr.GetHead().GetRef() != head {
+			continue
+		}
+		if state != "" && state != "all" && pr.GetState() != state {
+			continue
+		}
+		result = append(result, pr)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GetNumber() < result[j].GetNumber() })
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (f *FakeServer) createPull(w http.ResponseWriter, req *http.Request) {
+	var newPR github.NewPullRequest
+	if err := json.NewDecoder(req.Body).Decode(&newPR); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, pr := range f.prs {
+		if pr.GetState() == "open" && pr.GetHead().GetRef() == newPR.GetHead() {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "A pull request already exists for " + newPR.GetHead()})
+			return
+		}
+	}
+	f.lastNumber++
+	number := f.lastNumber
+	pr := &github.PullRequest{
+		Number:  github.Ptr(number),
+		State:   github.Ptr("open"),
+		Title:   newPR.Title,
+		Body:    newPR.Body,
+		Draft:   github.Ptr(newPR.GetDraft()),
+		Merged:  github.Ptr(false),
+		HTMLURL: github.Ptr(f.htmlURL("pull", number)),
+		Head:    &github.PullRequestBranch{Ref: newPR.Head},
+		Base:    &github.PullRequestBranch{Ref: newPR.Base},
+		User:    &github.User{Login: github.Ptr(f.Owner)},
+	}
+	f.prs[number] = pr
+	writeJSON(w, http.StatusCreated, pr)
+}
+
+func (f *FakeServer) getPull(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, ok := f.prs[pathInt(req, "number")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, pr)
+}
+
+func (f *FakeServer) editPull(w http.ResponseWriter, req *http.Request) {
+	var update struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+		State *string `json:"state"`
+		Base  *string `json:"base"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, ok := f.prs[pathInt(req, "number")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	if update.Title != nil {
+		pr.Title = update.Title
+	}
+	if update.Body != nil {
+		pr.Body = update.Body
+	}
+	if update.State != nil {
+		pr.State = update.State
+	}
+	if update.Base != nil {
+		pr.Base = &github.PullRequestBranch{Ref: update.Base}
+	}
+	writeJSON(w, http.StatusOK, pr)
+}
+
+// renameBranch serves POST branches/{branch}/rename. The fake keeps no
+// refs, so it only moves the open pull requests using the branch.
+func (f *FakeServer) renameBranch(w http.ResponseWriter, req *http.Request) {
+	from, ok := strings.CutSuffix(req.PathValue("branch"), "/rename")
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	var body struct {
+		NewName string `json:"new_name"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.NewName == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "new_name is required"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, pr := range f.prs {
+		if pr.GetState() != "open" {
+			continue
+		}
+		if pr.GetHead().GetRef() == from {
+			pr.Head.Ref = github.Ptr(body.NewName)
+		}
+		if pr.GetBase().GetRef() == from {
+			pr.Base.Ref = github.Ptr(body.NewName)
+		}
+	}
+	writeJSON(w, http.StatusCreated, &github.Branch{Name: github.Ptr(body.NewName)})
+}
+
+func (f *FakeServer) listComments(w http.ResponseWriter, issue string) {
+	number, _ := strconv.Atoi(issue)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := []*github.IssueComment{}
+	for id, issue := range f.onIssue {
+		if issue == number {
+			result = append(result, f.comments[id])
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].GetID() < result[j].GetID() })
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (f *FakeServer) createComment(w http.ResponseWriter, req *http.Request) {
+	var input github.IssueComment
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	number := pathInt(req, "number")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastCommentID++
+	id := f.lastCommentID
+	comment := &github.IssueComment{
+		ID:      github.Ptr(id),
+		Body:    input.Body,
+		HTMLURL: github.Ptr(fmt.Sprintf("%s#issuecomment-%d", f.htmlURL("pull", number), id)),
+	}
+	f.comments[id] = comment
+	f.onIssue[id] = number
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+func (f *FakeServer) getComment(w http.ResponseWriter, idText string) {
+	id, _ := strconv.ParseInt(idText, 10, 64)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	comment, ok := f.comments[id]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, comment)
+}
+
+func (f *FakeServer) editComment(w http.ResponseWriter, req *http.Request) {
+	var input github.IssueComment
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	comment, ok := f.comments[int64(pathInt(req, "id"))]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	comment.Body = input.Body
+	writeJSON(w, http.StatusOK, comment)
+}
+
+func pathInt(req *http.Request, name string) int {
+	n, _ := strconv.Atoi(req.PathValue(name))
+	return n
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,100 @@
+package gh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// fakeState is what a FakeServer keeps between processes. A hand-written
+// file in this format serves as canned fixtures.
+type fakeState struct {
+	PullRequests []*github.PullRequest `json:"pull_requests"`
+	Comments     []fakeStateComment    `json:"comments"`
+}
+
+type fakeStateComment struct {
+	Issue   int                  `json:"issue"`
+	Comment *github.IssueComment `json:"comment"`
+}
+
+// Persist loads the fake's pull requests and comments from path, when it
+// exists, and writes them back after every request that changes them. This
+// lets a sequence of separate 'so' processes share one fake GitHub.
+func (f *FakeServer) Persist(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read fake API state: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(data) > 0 {
+		var state fakeState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("failed to parse fake API state %s: %w", path, err)
+		}
+		for _, pr := range state.PullRequests {
+			f.prs[pr.GetNumber()] = pr
+			f.lastNumber = max(f.lastNumber, pr.GetNumber())
+		}
+		for _, c := range state.Comments {
+			f.comments[c.Comment.GetID()] = c.Comment
+			f.onIssue[c.Comment.GetID()] = c.Issue
+			f.lastCommentID = max(f.lastCommentID, c.Comment.GetID())
+			// Issues and PRs share one number sequence on GitHub.
+			f.lastNumber = max(f.lastNumber, c.Issue)
+		}
+	}
+	f.statePath = path
+	return nil
+}
+
+func (f *FakeServer) withPersistence(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req)
+		if req.Method == http.MethodGet {
+			return
+		}
+		if err := f.saveState(); err != nil {
+			// The response is already on its way; the next process will
+			// simply not see this change.
+			_, _ = fmt.Fprintf(os.Stderr, "fake API: %v\n", err)
+		}
+	})
+}
+
+func (f *FakeServer) saveState() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statePath == "" {
+		return nil
+	}
+	state := fakeState{PullRequests: []*github.PullRequest{}, Comments: []fakeStateComment{}}
+	for _, pr := range f.prs {
+		state.PullRequests = append(state.PullRequests, pr)
+	}
+	sort.Slice(state.PullRequests, func(i, j int) bool {
+		return state.PullRequests[i].GetNumber() < state.PullRequests[j].GetNumber()
+	})
+	for id, comment := range f.comments {
+		state.Comments = append(state.Comments, fakeStateComment{Issue: f.onIssue[id], Comment: comment})
+	}
+	sort.Slice(state.Comments, func(i, j int) bool {
+		return state.Comments[i].Comment.GetID() < state.Comments[j].Comment.GetID()
+	})
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fake API state: %w", err)
+	}
+	if err := os.WriteFile(f.statePath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write fake API state: %w", err)
+	}
+	return nil
+}
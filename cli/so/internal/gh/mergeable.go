@@ -0,0 +1,94 @@
+package gh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mergeableBatchSize caps how many PRs one GraphQL query asks about.
+const mergeableBatchSize = 50
+
+// MergeableState is what GitHub computed about merging a PR into its base.
+type MergeableState struct {
+	Number           int
+	BaseRefName      string
+	Mergeable        string // MERGEABLE, CONFLICTING or UNKNOWN while GitHub is still computing
+	MergeStateStatus string // CLEAN, BLOCKED, BEHIND, DIRTY, ...
+}
+
+// HasConflicts reports whether the PR cannot merge into its base without
+// resolving conflicts. A stack branch can be up to date with its parent and
+// still conflict with trunk once the PRs below it have merged.
+func (s MergeableState) HasConflicts() bool {
+	return s.Mergeable == "CONFLICTING" || s.MergeStateStatus == "DIRTY"
+}
+
+// GetMergeableStates fetches the mergeable state of several PRs with one
+// GraphQL request per batch. PRs GitHub does not know are left out.
+func (c *Client) GetMergeableStates(numbers []int) (map[int]MergeableState, error) {
+	states := make(map[int]MergeableState, len(numbers))
+	for start := 0; start < len(numbers); start += mergeableBatchSize {
+		batch := numbers[start:min(start+mergeableBatchSize, len(numbers))]
+		if err := c.withRetry("GetMergeableStates", func(int) error {
+			return c.getMergeableBatch(batch, states)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return states, nil
+}
+
+func (c *Client) getMergeableBatch(numbers []int, states map[int]MergeableState) error {
+	var fields strings.Builder
+	for _, n := range numbers {
+		fmt.Fprintf(&fields, "    pr%d: pullRequest(number: %d) { number baseRefName mergeable mergeStateStatus }\n", n, n)
+	}
+	payload := map[string]any{
+		"query": "query($owner: String!, $repo: String!) {\n  repository(owner: $owner, name: $repo) {\n" + fields.String() + "  }\n}",
+		"variables": map[string]any{
+			"owner": c.Owner,
+			"repo":  c.Repo,
+		},
+	}
+	req, err := c.gh.NewRequest("POST", "graphql", payload)
+	if err != nil {
+		return fmt.Errorf("failed to build GraphQL request for mergeable states: %w", err)
+	}
+
+	var resp struct {
+		Data struct {
+			Repository map[string]*struct {
+				Number           int    `json:"number"`
+				BaseRefName      string `json:"baseRefName"`
+				Mergeable        string `json:"mergeable"`
+				MergeStateStatus string `json:"mergeStateStatus"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := c.gh.Do(c.Ctx, req, &resp); err != nil {
+		return fmt.Errorf("failed to query mergeable states: %w", err)
+	}
+	// A PR that no longer exists only fails its own alias; keep the rest.
+	if len(resp.Errors) > 0 && len(resp.Data.Repository) == 0 {
+		msgs := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("GraphQL error for mergeable states: %s", strings.Join(msgs, "; "))
+	}
+	for _, pr := range resp.Data.Repository {
+		if pr == nil {
+			continue
+		}
+		states[pr.Number] = MergeableState{
+			Number:           pr.Number,
+			BaseRefName:      pr.BaseRefName,
+			Mergeable:        pr.Mergeable,
+			MergeStateStatus: pr.MergeStateStatus,
+		}
+	}
+	return nil
+}
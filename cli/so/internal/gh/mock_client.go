@@ -9,18 +9,20 @@ import (
 
 // MockClient implements the ClientInterface for testing
 type MockClient struct {
-	mock.Mock   // Embed testify mock object
-	PRStatuses  map[int]string
-	PRNumbers   map[string]int
-	CounterChan chan string // Channel to receive operation names
+	mock.Mock       // Embed testify mock object
+	PRStatuses      map[int]string
+	PRNumbers       map[string]int
+	MergeableStates map[int]MergeableState
+	CounterChan     chan string // Channel to receive operation names
 }
 
 // NewMockClient creates a new MockClient
 func NewMockClient() *MockClient {
 	return &MockClient{
-		PRStatuses:  make(map[int]string),
-		PRNumbers:   make(map[string]int),
-		CounterChan: make(chan string, 100), // Buffer for counting operations
+		PRStatuses:      make(map[int]string),
+		PRNumbers:       make(map[string]int),
+		MergeableStates: make(map[int]MergeableState),
+		CounterChan:     make(chan string, 100), // Buffer for counting operations
 	}
 }
 
@@ -86,6 +88,31 @@ func (c *MockClient) UpdatePullRequestBase(number int, newBase string) (*github.
 	return args.Get(0).(*github.PullRequest), args.Error(1)
 }
 
+// UpdatePullRequestBody simulates replacing a PR's description
+func (c *MockClient) UpdatePullRequestBody(number int, body string) (*github.PullRequest, error) {
+	if c.CounterChan != nil {
+		c.CounterChan <- "UpdatePullRequestBody"
+	}
+	Counter.Increment("UpdatePullRequestBody")
+
+	args := c.Called(number, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*github.PullRequest), args.Error(1)
+}
+
+// MergePullRequest simulates merging a PR
+func (c *MockClient) MergePullRequest(number int, method, headOID string) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "MergePullRequest"
+	}
+	Counter.Increment("MergePullRequest")
+
+	args := c.Called(number, method, headOID)
+	return args.Error(0)
+}
+
 // FindPullRequestByHead simulates discovering a PR by its head branch
 func (c *MockClient) FindPullRequestByHead(headBranch string) (*github.PullRequest, error) {
 	if c.CounterChan != nil {
@@ -157,3 +184,157 @@ func (c *MockClient) GetIssueComment(commentID int64) (*github.IssueComment, err
 	}
 	return args.Get(0).(*github.IssueComment), args.Error(1)
 }
+
+// AddLabels simulates adding labels to an issue/PR
+func (c *MockClient) AddLabels(issueNumber int, labels []string) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "AddLabels"
+	}
+	Counter.Increment("AddLabels")
+
+	args := c.Called(issueNumber, labels)
+	return args.Error(0)
+}
+
+// RemoveLabel simulates removing a label from an issue/PR
+func (c *MockClient) RemoveLabel(issueNumber int, label string) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "RemoveLabel"
+	}
+	Counter.Increment("RemoveLabel")
+
+	args := c.Called(issueNumber, label)
+	return args.Error(0)
+}
+
+// FindMilestone simulates looking up a milestone by title
+func (c *MockClient) FindMilestone(title string) (number int, err error) {
+	if c.CounterChan != nil {
+		c.CounterChan <- "FindMilestone"
+	}
+	Counter.Increment("FindMilestone")
+
+	args := c.Called(title)
+	return args.Int(0), args.Error(1)
+}
+
+// SetMilestone simulates assigning a milestone to an issue/PR
+func (c *MockClient) SetMilestone(issueNumber int, milestoneNumber int) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "SetMilestone"
+	}
+	Counter.Increment("SetMilestone")
+
+	args := c.Called(issueNumber, milestoneNumber)
+	return args.Error(0)
+}
+
+// GetMergeReadiness simulates querying a PR's merge state
+func (c *MockClient) GetMergeReadiness(number int) (*MergeReadiness, error) {
+	if c.CounterChan != nil {
+		c.CounterChan <- "GetMergeReadiness"
+	}
+	Counter.Increment("GetMergeReadiness")
+
+	args := c.Called(number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MergeReadiness), args.Error(1)
+}
+
+// GetMergeableStates returns the predefined MergeableStates of the requested PRs
+func (c *MockClient) GetMergeableStates(numbers []int) (map[int]MergeableState, error) {
+	if c.CounterChan != nil {
+		c.CounterChan <- "GetMergeableStates"
+	}
+	Counter.Increment("GetMergeableStates")
+
+	states := make(map[int]MergeableState)
+	for _, n := range numbers {
+		if state, ok := c.MergeableStates[n]; ok {
+			states[n] = state
+		}
+	}
+	return states, nil
+}
+
+// RequestReviewers simulates requesting reviews on a PR
+func (c *MockClient) RequestReviewers(number int, reviewers []string) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "RequestReviewers"
+	}
+	Counter.Increment("RequestReviewers")
+
+	args := c.Called(number, reviewers)
+	return args.Error(0)
+}
+
+// RemoveReviewers simulates withdrawing review requests on a PR
+func (c *MockClient) RemoveReviewers(number int, reviewers []string) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "RemoveReviewers"
+	}
+	Counter.Increment("RemoveReviewers")
+
+	args := c.Called(number, reviewers)
+	return args.Error(0)
+}
+
+// CloseIssue simulates closing an issue
+func (c *MockClient) CloseIssue(number int) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "CloseIssue"
+	}
+	Counter.Increment("CloseIssue")
+
+	args := c.Called(number)
+	return args.Error(0)
+}
+
+// DeletesBranchOnMerge simulates reading the repository's branch deletion setting
+func (c *MockClient) DeletesBranchOnMerge() (bool, error) {
+	if c.CounterChan != nil {
+		c.CounterChan <- "DeletesBranchOnMerge"
+	}
+	Counter.Increment("DeletesBranchOnMerge")
+
+	args := c.Called()
+	return args.Bool(0), args.Error(1)
+}
+
+// MergeMethods simulates reading the repository's allowed merge methods
+func (c *MockClient) MergeMethods() ([]string, error) {
+	if c.CounterChan != nil {
+		c.CounterChan <- "MergeMethods"
+	}
+	Counter.Increment("MergeMethods")
+
+	args := c.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// RenameBranch simulates renaming a branch on GitHub
+func (c *MockClient) RenameBranch(from, to string) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "RenameBranch"
+	}
+	Counter.Increment("RenameBranch")
+
+	args := c.Called(from, to)
+	return args.Error(0)
+}
+
+// ClosePullRequest simulates closing a PR
+func (c *MockClient) ClosePullRequest(number int) error {
+	if c.CounterChan != nil {
+		c.CounterChan <- "ClosePullRequest"
+	}
+	Counter.Increment("ClosePullRequest")
+
+	args := c.Called(number)
+	return args.Error(0)
+}
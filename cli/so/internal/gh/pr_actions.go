@@ -8,11 +8,13 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
 
 	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/msggen"
 	"github.com/benekuehn/socle/cli/so/internal/ui"
 	"github.com/google/go-github/v71/github"
 	"github.com/spf13/cobra"
@@ -27,6 +29,10 @@ type SubmitBranchOptions struct {
 	TestSubmitBody        string
 	TestSubmitEditConfirm bool
 	NonInteractive        bool
+	TitlePrefix           string // Prepended to the title of new PRs, e.g. "[auth-refactor] "
+	Description           string // The branch's 'so describe' text, the default body of new PRs
+	PRBase                string // Branch the PR targets when it is not parent, e.g. for a frozen base
+	TrackingIssue         int    // Referenced as "Part of #N" at the end of the body of new PRs
 }
 
 // ErrSubmitCancelled indicates the user cancelled the operation during a prompt.
@@ -39,6 +45,10 @@ var ErrSubmitCancelled = errors.New("submit cancelled by user")
 // Returns the final PR state (or nil if skipped) and an error (including ErrSubmitCancelled).
 func SubmitBranch(ctx context.Context, ghClient ClientInterface, cmd *cobra.Command, branch, parent string, opts SubmitBranchOptions) (*github.PullRequest, error) {
 	slog.Debug("Executing SubmitBranch action", "branch", branch, "parent", parent)
+	prBase := parent
+	if opts.PRBase != "" {
+		prBase = opts.PRBase
+	}
 
 	// 1. Check for existing PR via stored number
 	prNumber, configReadErr := git.GetStoredPRNumber(branch)
@@ -52,7 +62,7 @@ func SubmitBranch(ctx context.Context, ghClient ClientInterface, cmd *cobra.Comm
 	// 2. Try to Update Existing PR if number was found
 	if prNumber > 0 {
 		// Call renamed helper function
-		updatedPR, errUpdate := updateExistingPR(ghClient, prNumber, parent)
+		updatedPR, errUpdate := updateExistingPR(ghClient, prNumber, prBase)
 		if errUpdate != nil {
 			return nil, fmt.Errorf("failed trying to update PR #%d: %w", prNumber, errUpdate)
 		}
@@ -143,11 +153,22 @@ func createNewPR(ghClient ClientInterface, cmd *cobra.Command, branch, parent st
 	if errPrompt != nil {
 		return nil, errPrompt // Includes cancellation error
 	}
+	if opts.TitlePrefix != "" && !strings.HasPrefix(title, opts.TitlePrefix) {
+		title = opts.TitlePrefix + title
+	}
+	if opts.TrackingIssue > 0 {
+		body = strings.TrimRight(body, "\n") + fmt.Sprintf("\n\nPart of #%d", opts.TrackingIssue)
+		body = strings.TrimLeft(body, "\n")
+	}
 
+	prBase := parent
+	if opts.PRBase != "" {
+		prBase = opts.PRBase
+	}
 	draftStatus := map[bool]string{true: "Draft", false: "Ready"}[opts.IsDraft]
-	_, _ = fmt.Printf("  Submitting %s PR for '%s' -> '%s'...\n", draftStatus, branch, parent)
-	slog.Debug("Creating PR via API", "branch", branch, "parent", parent, "title", title, "isDraft", opts.IsDraft)
-	newPR, errCreate := ghClient.CreatePullRequest(branch, parent, title, body, opts.IsDraft)
+	_, _ = fmt.Printf("  Submitting %s PR for '%s' -> '%s'...\n", draftStatus, branch, prBase)
+	slog.Debug("Creating PR via API", "branch", branch, "base", prBase, "title", title, "isDraft", opts.IsDraft)
+	newPR, errCreate := ghClient.CreatePullRequest(branch, prBase, title, body, opts.IsDraft)
 	if errCreate != nil {
 		return nil, fmt.Errorf("github API error creating pull request: %w", errCreate)
 	}
@@ -159,20 +180,15 @@ func createNewPR(ghClient ClientInterface, cmd *cobra.Command, branch, parent st
 }
 
 // promptForPRDetails prompts the user for PR title and body using defaults.
+// The placeholders of the body are filled in (see ExpandPRBody).
 func promptForPRDetails(cmd *cobra.Command, branch, parent string, opts SubmitBranchOptions) (title, body string, err error) {
 	var surveyErr error
 	title = ""
-	defaultTitle := ""
-	firstSubject, errSubject := git.GetFirstCommitSubject(parent, branch)
-	if errSubject != nil {
-		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", ui.Colors.WarningStyle.Render(fmt.Sprintf("  Warning: Could not determine first commit subject for default title: %v", errSubject)))
-		defaultTitle = strings.ReplaceAll(branch, "-", " ")
-	} else if firstSubject == "" {
-		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", ui.Colors.WarningStyle.Render("  Warning: No unique commits found for default title. Using branch name."))
-		defaultTitle = strings.ReplaceAll(branch, "-", " ")
+	defaultTitle, titleSource, errTitle := DefaultPRTitle(parent, branch)
+	if errTitle != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", ui.Colors.WarningStyle.Render(fmt.Sprintf("  Warning: Could not derive the default title (%v). Using branch name.", errTitle)))
 	} else {
-		defaultTitle = firstSubject
-		_, _ = fmt.Printf("  Using commit subject for default title: \"%s\"\n", defaultTitle)
+		_, _ = fmt.Printf("  Using %s for default title: \"%s\"\n", titleSource, defaultTitle)
 	}
 	if opts.TestSubmitTitle != "" {
 		title = opts.TestSubmitTitle
@@ -182,9 +198,15 @@ func promptForPRDetails(cmd *cobra.Command, branch, parent string, opts SubmitBr
 		title = defaultTitle
 		_, _ = fmt.Printf("  Non-interactive mode: using default PR title: %q\n", title)
 	} else {
+		if command := msggen.Command(); command != "" {
+			var generateErr error
+			if defaultTitle, opts.Description, generateErr = offerGeneratedPRDetails(cmd, command, branch, parent, defaultTitle, opts.Description); generateErr != nil {
+				return "", "", generateErr
+			}
+		}
 		titlePrompt := &survey.Input{Message: "Pull Request Title:", Default: defaultTitle}
 		// Call renamed helper function
-		surveyErr = survey.AskOne(titlePrompt, &title, survey.WithValidator(survey.Required), survey.WithStdio(os.Stdin, os.Stdout, os.Stderr))
+		surveyErr = ui.AskOne(titlePrompt, &title, survey.WithValidator(survey.Required), survey.WithStdio(os.Stdin, os.Stdout, os.Stderr))
 		if surveyErr != nil {
 			return "", "", handleSurveyInterrupt(surveyErr, "Submit cancelled during title entry.")
 		}
@@ -202,9 +224,17 @@ func promptForPRDetails(cmd *cobra.Command, branch, parent string, opts SubmitBr
 			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), ui.Colors.WarningStyle.Render("  Warning: Could not read PR template: "+errTpl.Error()))
 		} else if templateContent != "" {
 			_, _ = fmt.Println("  Found PR template.")
-		} else {
+		} else if opts.Description == "" {
 			_, _ = fmt.Println("  No PR template found. Using empty description.")
 		}
+		if opts.Description != "" {
+			_, _ = fmt.Println("  Using the branch description for the PR body.")
+			if templateContent != "" {
+				templateContent = opts.Description + "\n\n" + templateContent
+			} else {
+				templateContent = opts.Description
+			}
+		}
 		editBody := false
 		if opts.TestSubmitEditConfirm {
 			editBody = true
@@ -212,14 +242,14 @@ func promptForPRDetails(cmd *cobra.Command, branch, parent string, opts SubmitBr
 			editBody = false
 		} else {
 			confirmPrompt := &survey.Confirm{Message: "Edit description before submitting?", Default: false}
-			surveyErr = survey.AskOne(confirmPrompt, &editBody, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr))
+			surveyErr = ui.AskOne(confirmPrompt, &editBody, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr))
 			if surveyErr != nil {
 				return "", "", handleSurveyInterrupt(surveyErr, "Submit cancelled during edit confirmation.")
 			}
 		}
 		if editBody {
 			editorPrompt := &survey.Editor{Message: "Pull Request Body (Markdown):", FileName: "*.md", Default: templateContent, HideDefault: false}
-			surveyErr = survey.AskOne(editorPrompt, &body, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr))
+			surveyErr = ui.AskOne(editorPrompt, &body, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr))
 			if surveyErr != nil {
 				return "", "", handleSurveyInterrupt(surveyErr, "Submit cancelled during body editing.")
 			}
@@ -227,6 +257,40 @@ func promptForPRDetails(cmd *cobra.Command, branch, parent string, opts SubmitBr
 			body = templateContent
 		}
 	}
+	vars := PRBodyVars{Branch: branch, Parent: parent, Base: parent}
+	if opts.PRBase != "" {
+		vars.Base = opts.PRBase
+	}
+	vars.ParentPR, _ = git.GetStoredPRNumber(parent)
+	return title, ExpandPRBody(body, vars), nil
+}
+
+// offerGeneratedPRDetails asks whether to draft the PR with the
+// socle.messageGenerator command and, if so, returns its first line as the
+// default title and the rest as the default body. A body from the branch
+// description, set with `so describe`, is kept over the generated one. A
+// failing generator only warns; the given defaults are returned unchanged.
+func offerGeneratedPRDetails(cmd *cobra.Command, command, branch, parent, title, body string) (string, string, error) {
+	generate := true
+	prompt := &survey.Confirm{Message: fmt.Sprintf("Generate title and description with '%s'?", command), Default: true}
+	if err := ui.AskOne(prompt, &generate, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr)); err != nil {
+		return "", "", handleSurveyInterrupt(err, "Submit cancelled.")
+	}
+	if !generate {
+		return title, body, nil
+	}
+	diff, err := git.BranchDiff(parent, branch)
+	if err == nil {
+		var message string
+		if message, err = msggen.Generate(command, msggen.KindPR, diff, branch, parent); err == nil {
+			genTitle, genBody := msggen.SplitTitleBody(message)
+			if body != "" || genBody == "" {
+				genBody = body
+			}
+			return genTitle, genBody, nil
+		}
+	}
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr(), ui.Colors.WarningStyle.Render("  Warning: "+err.Error()))
 	return title, body, nil
 }
 
@@ -244,19 +308,45 @@ func handleSurveyInterrupt(err error, message string) error {
 
 // --- Commenting Logic ---
 
+// commentConfigMu serializes the comment ID writes of concurrent
+// EnsureStackComment calls; git takes a lock on the config file per write.
+var commentConfigMu sync.Mutex
+
 // EnsureStackComment handles adding or updating the stack overview comment on a given PR.
+// The comment ID stored for the branch is tried first; only when it no longer
+// points at a stack comment on the PR is the PR's comment list searched. An
+// existing comment is only edited when its body differs from commentBody, so
+// unchanged comments do not get a new "edited" mark. Safe for concurrent use
+// with different branches.
 func EnsureStackComment(ctx context.Context, ghClient ClientInterface, branch string, prNumber int, commentBody string, marker string) error {
 	slog.Debug("Executing EnsureStackComment action", "branch", branch, "prNumber", prNumber)
 	var accumulatedError error // Collect non-fatal errors
+	addWarning := func(warnMsg string) {
+		slog.Warn(warnMsg)
+		if accumulatedError == nil {
+			accumulatedError = errors.New(warnMsg)
+		} else {
+			accumulatedError = fmt.Errorf("%w; %s", accumulatedError, warnMsg)
+		}
+	}
 
-	// 1. Get existing comment ID stored locally
+	// 1. Try the comment ID stored locally
 	storedCommentID, configReadErr := git.GetStoredCommentID(branch)
 	if configReadErr != nil {
-		warnMsg := fmt.Sprintf("failed to read stored comment ID config for branch '%s': %v", branch, configReadErr)
-		slog.Warn(warnMsg)
-		accumulatedError = errors.New(warnMsg) // Use errors.New for initial assignment
+		addWarning(fmt.Sprintf("failed to read stored comment ID config for branch '%s': %v", branch, configReadErr))
 		storedCommentID = 0
 	}
+	if storedCommentID > 0 {
+		comment, getErr := ghClient.GetIssueComment(storedCommentID)
+		if getErr == nil && isStackCommentOn(comment, prNumber, marker) {
+			slog.Debug("Stored comment ID is valid, skipping search", "commentID", storedCommentID)
+			if err := updateCommentIfChanged(ghClient, comment, prNumber, commentBody); err != nil {
+				return err
+			}
+			return accumulatedError
+		}
+		slog.Debug("Stored comment ID is not a stack comment on this PR, searching", "commentID", storedCommentID, "error", getErr)
+	}
 
 	// 2. Find comment on GitHub using marker
 	foundCommentID, findErr := ghClient.FindCommentWithMarker(prNumber, marker)
@@ -267,92 +357,105 @@ func EnsureStackComment(ctx context.Context, ghClient ClientInterface, branch st
 
 	// 3. Update or Create Comment
 	if foundCommentID > 0 {
-		// --- Comment with marker found ---
 		slog.Debug("Found existing stack comment via marker", "foundCommentID", foundCommentID, "prNumber", prNumber)
-
-		// If we found a comment ID locally and it matches the one found on GitHub, it's likely up-to-date.
-		if storedCommentID > 0 && foundCommentID == storedCommentID {
-			slog.Debug("GitHub comment found matches stored ID, checking if body update needed", "commentID", storedCommentID)
-			comment, getErr := ghClient.GetIssueComment(storedCommentID)
-			if getErr != nil {
-				warnMsg := fmt.Sprintf("failed to get comment %d from GitHub: %v", storedCommentID, getErr)
-				slog.Warn(warnMsg)
-				if accumulatedError == nil {
-					accumulatedError = errors.New(warnMsg)
-				} else {
-					accumulatedError = fmt.Errorf("%w; %s", accumulatedError, warnMsg)
-				}
-			} else if comment.GetBody() != commentBody {
-				slog.Debug("Comment body differs, updating...", "commentID", storedCommentID)
-				_, updateErr := ghClient.UpdateComment(storedCommentID, commentBody)
-				if updateErr != nil {
-					errMsg := fmt.Sprintf("failed to update comment %d on PR #%d: %v", storedCommentID, prNumber, updateErr)
-					slog.Error(errMsg)
-					return fmt.Errorf("%s", errMsg)
-				}
-				slog.Debug("Comment updated successfully.")
-			} else {
-				slog.Debug("Comment body is up-to-date.", "commentID", storedCommentID)
-			}
-		} else if storedCommentID == 0 && foundCommentID > 0 {
-			slog.Debug("Found comment on GitHub but none stored locally. Storing it.", "foundCommentID", foundCommentID)
-			if setErr := git.SetStoredCommentID(branch, foundCommentID); setErr != nil {
-				warnMsg := fmt.Sprintf("failed to store found comment ID %d locally for branch '%s': %v", foundCommentID, branch, setErr)
-				slog.Warn(warnMsg)
-				if accumulatedError == nil {
-					accumulatedError = errors.New(warnMsg)
-				} else {
-					accumulatedError = fmt.Errorf("%w; %s", accumulatedError, warnMsg)
-				}
-			}
-		} else if storedCommentID > 0 && foundCommentID == 0 {
-			slog.Warn("Stored comment ID not found on GitHub. Unsetting local and attempting creation.", "storedCommentID", storedCommentID)
-			if unsetErr := git.UnsetStoredCommentID(branch); unsetErr != nil {
-				warnMsg := fmt.Sprintf("failed to unset stale comment ID %d locally for branch '%s': %v", storedCommentID, branch, unsetErr)
-				slog.Warn(warnMsg)
-				if accumulatedError == nil {
-					accumulatedError = errors.New(warnMsg)
-				} else {
-					accumulatedError = fmt.Errorf("%w; %s", accumulatedError, warnMsg)
-				}
-			}
+		commentConfigMu.Lock()
+		setErr := git.SetStoredCommentID(branch, foundCommentID)
+		commentConfigMu.Unlock()
+		if setErr != nil {
+			addWarning(fmt.Sprintf("failed to store found comment ID %d locally for branch '%s': %v", foundCommentID, branch, setErr))
 		}
-	} else {
-		// --- Comment with marker NOT found ---
-		slog.Debug("No existing stack comment found via marker.", "prNumber", prNumber)
-
-		if storedCommentID != 0 {
-			warnMsg := fmt.Sprintf("stored comment ID %d found, but no matching comment exists on PR #%d. Clearing stored ID", storedCommentID, prNumber)
-			slog.Warn(warnMsg)
-			if accumulatedError == nil {
-				accumulatedError = fmt.Errorf("%w", errors.New(warnMsg))
-			} else {
-				accumulatedError = fmt.Errorf("%w; %s", accumulatedError, warnMsg)
-			}
-			if err := git.UnsetStoredCommentID(branch); err != nil {
-				critErrMsg := fmt.Sprintf("failed to clear stale comment ID for branch '%s': %v", branch, err)
-				slog.Error(critErrMsg)
-				accumulatedError = fmt.Errorf("%w; %s", accumulatedError, critErrMsg)
-			}
+		comment, getErr := ghClient.GetIssueComment(foundCommentID)
+		if getErr != nil {
+			addWarning(fmt.Sprintf("failed to get comment %d from GitHub: %v", foundCommentID, getErr))
+			return accumulatedError
 		}
-
-		// Create new comment
-		slog.Debug("Adding stack comment", "prNumber", prNumber)
-		newComment, err := ghClient.CreateComment(prNumber, commentBody)
-		if err != nil {
-			return fmt.Errorf("failed to add stack comment to PR #%d: %w", prNumber, err)
+		if err := updateCommentIfChanged(ghClient, comment, prNumber, commentBody); err != nil {
+			return err
 		}
-		slog.Debug("Comment added successfully.")
+		return accumulatedError
+	}
 
-		// Store the new comment ID
-		newCommentID := newComment.GetID()
-		if err := git.SetStoredCommentID(branch, newCommentID); err != nil {
-			critErrMsg := fmt.Sprintf("failed to store new comment ID %d for branch '%s': %v", newCommentID, branch, err)
+	// --- Comment with marker NOT found ---
+	slog.Debug("No existing stack comment found via marker.", "prNumber", prNumber)
+	if storedCommentID != 0 {
+		addWarning(fmt.Sprintf("stored comment ID %d found, but no matching comment exists on PR #%d. Clearing stored ID", storedCommentID, prNumber))
+		commentConfigMu.Lock()
+		unsetErr := git.UnsetStoredCommentID(branch)
+		commentConfigMu.Unlock()
+		if unsetErr != nil {
+			critErrMsg := fmt.Sprintf("failed to clear stale comment ID for branch '%s': %v", branch, unsetErr)
 			slog.Error(critErrMsg)
-			return fmt.Errorf("%s", critErrMsg)
+			accumulatedError = fmt.Errorf("%w; %s", accumulatedError, critErrMsg)
 		}
-		slog.Debug("Stored new comment ID", "commentID", newCommentID)
 	}
 
+	// Create new comment
+	slog.Debug("Adding stack comment", "prNumber", prNumber)
+	newComment, err := ghClient.CreateComment(prNumber, commentBody)
+	if err != nil {
+		return fmt.Errorf("failed to add stack comment to PR #%d: %w", prNumber, err)
+	}
+	slog.Debug("Comment added successfully.")
+
+	// Store the new comment ID
+	newCommentID := newComment.GetID()
+	commentConfigMu.Lock()
+	err = git.SetStoredCommentID(branch, newCommentID)
+	commentConfigMu.Unlock()
+	if err != nil {
+		critErrMsg := fmt.Sprintf("failed to store new comment ID %d for branch '%s': %v", newCommentID, branch, err)
+		slog.Error(critErrMsg)
+		return fmt.Errorf("%s", critErrMsg)
+	}
+	slog.Debug("Stored new comment ID", "commentID", newCommentID)
+
 	return accumulatedError // Return collected non-fatal errors/warnings
 }
+
+// EnsureIssueComment keeps one comment carrying marker on an issue, creating
+// it if there is none. render gets the comment's current body ("" when it
+// does not exist yet) and returns the new one; the comment is only edited
+// when that differs. The comment is found by its marker each time, so nothing
+// is stored locally.
+func EnsureIssueComment(ghClient ClientInterface, issueNumber int, marker string, render func(previous string) string) error {
+	commentID, err := ghClient.FindCommentWithMarker(issueNumber, marker)
+	if err != nil {
+		return fmt.Errorf("failed to search for the socle comment on #%d: %w", issueNumber, err)
+	}
+	if commentID == 0 {
+		if _, err := ghClient.CreateComment(issueNumber, render("")); err != nil {
+			return fmt.Errorf("failed to add a comment to #%d: %w", issueNumber, err)
+		}
+		return nil
+	}
+	comment, err := ghClient.GetIssueComment(commentID)
+	if err != nil {
+		return err
+	}
+	return updateCommentIfChanged(ghClient, comment, issueNumber, render(comment.GetBody()))
+}
+
+// isStackCommentOn reports whether comment carries marker and, when GitHub
+// says which issue it belongs to, is on PR prNumber.
+func isStackCommentOn(comment *github.IssueComment, prNumber int, marker string) bool {
+	if comment == nil || !strings.Contains(comment.GetBody(), marker) {
+		return false
+	}
+	issueURL := comment.GetIssueURL()
+	return issueURL == "" || strings.HasSuffix(issueURL, fmt.Sprintf("/%d", prNumber))
+}
+
+func updateCommentIfChanged(ghClient ClientInterface, comment *github.IssueComment, prNumber int, commentBody string) error {
+	if comment.GetBody() == commentBody {
+		slog.Debug("Comment body is up-to-date.", "commentID", comment.GetID())
+		return nil
+	}
+	slog.Debug("Comment body differs, updating...", "commentID", comment.GetID())
+	if _, err := ghClient.UpdateComment(comment.GetID(), commentBody); err != nil {
+		errMsg := fmt.Sprintf("failed to update comment %d on PR #%d: %v", comment.GetID(), prNumber, err)
+		slog.Error(errMsg)
+		return fmt.Errorf("%s", errMsg)
+	}
+	slog.Debug("Comment updated successfully.")
+	return nil
+}
@@ -0,0 +1,83 @@
+package gh
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Markers around the stack overview a {{stack_overview}} placeholder leaves in
+// a PR description. Submit rewrites what is between them, like the stack
+// comment.
+const (
+	BodyOverviewStart = "<!-- socle-stack-overview:start -->"
+	BodyOverviewEnd   = "<!-- socle-stack-overview:end -->"
+)
+
+// PRBodyVars are the values of the placeholders in a PR description.
+type PRBodyVars struct {
+	Branch   string
+	Parent   string
+	Base     string // The branch the PR targets
+	ParentPR int    // 0 when the parent has no PR
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// ExpandPRBody fills the placeholders of a PR description: {{branch}},
+// {{parent}}, {{base}}, {{parent_pr}} ("#<number>", or "" without a parent
+// PR) and {{stack_overview}}, which becomes the markers the overview is
+// written between (see EnsureBodyOverview). Unknown placeholders are kept.
+func ExpandPRBody(body string, vars PRBodyVars) string {
+	parentPR := ""
+	if vars.ParentPR > 0 {
+		parentPR = fmt.Sprintf("#%d", vars.ParentPR)
+	}
+	values := map[string]string{
+		"branch":         vars.Branch,
+		"parent":         vars.Parent,
+		"base":           vars.Base,
+		"parent_pr":      parentPR,
+		"stack_overview": BodyOverviewStart + "\n" + BodyOverviewEnd,
+	}
+	return placeholderPattern.ReplaceAllStringFunc(body, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// HasBodyOverview reports whether body holds the overview markers.
+func HasBodyOverview(body string) bool {
+	start := strings.Index(body, BodyOverviewStart)
+	return start >= 0 && strings.Contains(body[start:], BodyOverviewEnd)
+}
+
+// replaceBodyOverview puts overview between the markers of body.
+func replaceBodyOverview(body, overview string) string {
+	start := strings.Index(body, BodyOverviewStart) + len(BodyOverviewStart)
+	end := start + strings.Index(body[start:], BodyOverviewEnd)
+	return body[:start] + "\n" + strings.TrimSpace(overview) + "\n" + body[end:]
+}
+
+// EnsureBodyOverview writes overview into the description of PR prNumber,
+// between its overview markers. A description without them, or with the
+// overview already current, is left alone.
+func EnsureBodyOverview(ghClient ClientInterface, prNumber int, overview string) error {
+	pr, err := ghClient.GetPullRequest(prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to read the description of PR #%d: %w", prNumber, err)
+	}
+	body := pr.GetBody()
+	if !HasBodyOverview(body) {
+		return nil
+	}
+	if updated := replaceBodyOverview(body, overview); updated != body {
+		if _, err := ghClient.UpdatePullRequestBody(prNumber, updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package gh
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+)
+
+// Where socle.prTitle takes the default title of a new PR from.
+const (
+	TitleFromFirstCommit  = "first-commit"  // Subject of the branch's oldest commit
+	TitleFromNewestCommit = "newest-commit" // Subject of the branch's newest commit
+	TitleFromBranch       = "branch"        // The branch name, dashes as spaces
+	TitleFromPattern      = "pattern"       // socle.prTitlePattern
+)
+
+// defaultTitlePattern applies when socle.prTitle is "pattern" but
+// socle.prTitlePattern is unset.
+const defaultTitlePattern = "{first}"
+
+// DefaultPRTitle derives the title offered for a new PR of branch as
+// socle.prTitle asks, and describes where it came from. The commits are
+// those branch adds on top of parent (see git.BranchCommitSubjects); while a
+// restack onto a new base is pending, the old base's commits are left out
+// too. Without commits, or when they cannot be read, the title is the branch
+// name and err says why.
+func DefaultPRTitle(parent, branch string) (title, source string, err error) {
+	mode, errMode := git.GetSocleConfig("socle.prTitle")
+	if errMode != nil || strings.TrimSpace(mode) == "" {
+		mode = TitleFromFirstCommit
+	}
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	branchTitle := strings.ReplaceAll(branch, "-", " ")
+	if mode == TitleFromBranch {
+		return branchTitle, "branch name", nil
+	}
+
+	from := parent
+	if upstream := git.GetRestackUpstream(branch); upstream != "" {
+		from = upstream
+	}
+	subjects, err := git.BranchCommitSubjects(from, branch)
+	if err != nil {
+		return branchTitle, "branch name", err
+	}
+
+	if mode == TitleFromPattern {
+		pattern, errPattern := git.GetSocleConfig("socle.prTitlePattern")
+		if errPattern != nil && !errors.Is(errPattern, git.ErrConfigNotFound) {
+			return branchTitle, "branch name", errPattern
+		}
+		if strings.TrimSpace(pattern) == "" {
+			pattern = defaultTitlePattern
+		}
+		first, newest := branchTitle, branchTitle
+		if len(subjects) > 0 {
+			first, newest = subjects[0], subjects[len(subjects)-1]
+		}
+		title = strings.NewReplacer(
+			"{branch}", branch,
+			"{words}", branchTitle,
+			"{first}", first,
+			"{newest}", newest,
+			"{count}", strconv.Itoa(len(subjects)),
+		).Replace(pattern)
+		return strings.TrimSpace(title), "socle.prTitlePattern", nil
+	}
+
+	if len(subjects) == 0 {
+		return branchTitle, "branch name", errNoBranchCommits
+	}
+	if mode == TitleFromNewestCommit {
+		return subjects[len(subjects)-1], "newest commit subject", nil
+	}
+	return subjects[0], "commit subject", nil
+}
+
+// errNoBranchCommits explains a branch-name title for a branch without
+// commits of its own.
+var errNoBranchCommits = errors.New("no unique commits found")
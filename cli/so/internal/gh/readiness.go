@@ -0,0 +1,211 @@
+package gh
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MergeReadiness captures what GitHub reports about whether a PR can merge,
+// as returned by the GraphQL pullRequest fields of the same names.
+type MergeReadiness struct {
+	Number           int
+	URL              string
+	State            string // OPEN, CLOSED or MERGED
+	IsDraft          bool
+	BaseRefName      string
+	HeadOID          string // The commit GitHub has as the PR's head
+	MergeStateStatus string // CLEAN, BLOCKED, BEHIND, DIRTY, UNSTABLE, HAS_HOOKS, DRAFT, UNKNOWN
+	ReviewDecision   string // APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED or empty
+	ChecksState      string // Rollup of HeadOID's checks: SUCCESS, FAILURE, ERROR, PENDING, EXPECTED or empty
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+const mergeReadinessQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      number
+      url
+      state
+      isDraft
+      baseRefName
+      headRefOid
+      mergeStateStatus
+      reviewDecision
+      createdAt
+      updatedAt
+      commits(last: 1) { nodes { commit { oid statusCheckRollup { state } } } }
+    }
+  }
+}`
+
+// GetMergeReadiness fetches merge state, review decision and check rollup for a PR
+// with a single GraphQL request.
+func (c *Client) GetMergeReadiness(number int) (*MergeReadiness, error) {
+	payload := map[string]any{
+		"query": mergeReadinessQuery,
+		"variables": map[string]any{
+			"owner":  c.Owner,
+			"repo":   c.Repo,
+			"number": number,
+		},
+	}
+	req, err := c.gh.NewRequest("POST", "graphql", payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GraphQL request for #%d: %w", number, err)
+	}
+
+	var resp struct {
+		Data struct {
+			Repository struct {
+				PullRequest *struct {
+					Number           int       `json:"number"`
+					URL              string    `json:"url"`
+					State            string    `json:"state"`
+					IsDraft          bool      `json:"isDraft"`
+					BaseRefName      string    `json:"baseRefName"`
+					HeadRefOid       string    `json:"headRefOid"`
+					MergeStateStatus string    `json:"mergeStateStatus"`
+					ReviewDecision   string    `json:"reviewDecision"`
+					CreatedAt        time.Time `json:"createdAt"`
+					UpdatedAt        time.Time `json:"updatedAt"`
+					Commits          struct {
+						Nodes []struct {
+							Commit struct {
+								OID               string `json:"oid"`
+								StatusCheckRollup *struct {
+									State string `json:"state"`
+								} `json:"statusCheckRollup"`
+							} `json:"commit"`
+						} `json:"nodes"`
+					} `json:"commits"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := c.gh.Do(c.Ctx, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to query merge state for #%d: %w", number, err)
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return nil, fmt.Errorf("GraphQL error for #%d: %s", number, strings.Join(msgs, "; "))
+	}
+	pr := resp.Data.Repository.PullRequest
+	if pr == nil {
+		return nil, fmt.Errorf("pull request #%d not found", number)
+	}
+
+	readiness := &MergeReadiness{
+		Number:           pr.Number,
+		URL:              pr.URL,
+		State:            pr.State,
+		IsDraft:          pr.IsDraft,
+		BaseRefName:      pr.BaseRefName,
+		HeadOID:          pr.HeadRefOid,
+		MergeStateStatus: pr.MergeStateStatus,
+		ReviewDecision:   pr.ReviewDecision,
+		CreatedAt:        pr.CreatedAt,
+		UpdatedAt:        pr.UpdatedAt,
+	}
+	// The rollup belongs to the last commit, which lags the head ref right
+	// after a push; checks of an older commit say nothing about the head.
+	if nodes := pr.Commits.Nodes; len(nodes) > 0 && nodes[0].Commit.OID == pr.HeadRefOid && nodes[0].Commit.StatusCheckRollup != nil {
+		readiness.ChecksState = nodes[0].Commit.StatusCheckRollup.State
+	}
+	return readiness, nil
+}
+
+// Blocker is a reason GitHub would refuse to merge a PR right now. Its value
+// is the human-readable reason.
+type Blocker string
+
+const (
+	BlockerClosed           Blocker = "PR is closed"
+	BlockerDraft            Blocker = "PR is a draft"
+	BlockerChangesRequested Blocker = "changes requested"
+	BlockerReviewRequired   Blocker = "review required"
+	BlockerChecksFailing    Blocker = "checks failing"
+	BlockerChecksPending    Blocker = "checks pending"
+	BlockerConflicts        Blocker = "merge conflicts"
+	BlockerBehind           Blocker = "branch is behind its base"
+	BlockerProtected        Blocker = "blocked by branch protection"
+	BlockerNotComputed      Blocker = "merge state not yet computed by GitHub"
+)
+
+// Transient reports whether b clears without anyone acting on the PR.
+func (b Blocker) Transient() bool {
+	switch b {
+	case BlockerChecksPending, BlockerBehind, BlockerNotComputed:
+		return true
+	}
+	return false
+}
+
+// Blockers lists the reasons the PR cannot be merged right now.
+// An empty result means GitHub would accept a merge.
+func (m *MergeReadiness) Blockers() []Blocker {
+	var blockers []Blocker
+	if m.State == "CLOSED" {
+		return []Blocker{BlockerClosed}
+	}
+	if m.IsDraft || m.MergeStateStatus == "DRAFT" {
+		blockers = append(blockers, BlockerDraft)
+	}
+	switch m.ReviewDecision {
+	case "CHANGES_REQUESTED":
+		blockers = append(blockers, BlockerChangesRequested)
+	case "REVIEW_REQUIRED":
+		blockers = append(blockers, BlockerReviewRequired)
+	}
+	// CLEAN/UNSTABLE/HAS_HOOKS mean any failing or pending checks aren't required.
+	nonBlockingChecks := m.MergeStateStatus == "CLEAN" || m.MergeStateStatus == "UNSTABLE" || m.MergeStateStatus == "HAS_HOOKS"
+	switch {
+	case nonBlockingChecks:
+	case m.ChecksState == "FAILURE", m.ChecksState == "ERROR":
+		blockers = append(blockers, BlockerChecksFailing)
+	case m.ChecksState == "PENDING", m.ChecksState == "EXPECTED":
+		blockers = append(blockers, BlockerChecksPending)
+	}
+	switch m.MergeStateStatus {
+	case "DIRTY":
+		blockers = append(blockers, BlockerConflicts)
+	case "BEHIND":
+		blockers = append(blockers, BlockerBehind)
+	case "BLOCKED":
+		if len(blockers) == 0 {
+			blockers = append(blockers, BlockerProtected)
+		}
+	case "UNKNOWN", "":
+		if len(blockers) == 0 {
+			blockers = append(blockers, BlockerNotComputed)
+		}
+	}
+	return blockers
+}
+
+// HealthEmoji summarizes the review and CI state for the stack comment:
+// ✅ approved, 🔄 changes requested, ❌ checks failing. A review and a CI emoji
+// can both appear; the result is empty when there is nothing to report.
+func (m *MergeReadiness) HealthEmoji() string {
+	if m.State == "MERGED" || m.State == "CLOSED" {
+		return ""
+	}
+	var parts []string
+	switch m.ReviewDecision {
+	case "APPROVED":
+		parts = append(parts, "✅")
+	case "CHANGES_REQUESTED":
+		parts = append(parts, "🔄")
+	}
+	if m.ChecksState == "FAILURE" || m.ChecksState == "ERROR" {
+		parts = append(parts, "❌")
+	}
+	return strings.Join(parts, " ")
+}
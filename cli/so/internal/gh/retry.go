@@ -0,0 +1,87 @@
+package gh
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+)
+
+// retryPolicy bounds how often and how patiently a Client retries a GitHub
+// call that failed with a transient error.
+type retryPolicy struct {
+	attempts  int           // Total tries, including the first
+	baseDelay time.Duration // Doubled after every failed try, plus jitter
+}
+
+var defaultRetryPolicy = retryPolicy{attempts: 3, baseDelay: 500 * time.Millisecond}
+
+// IsTransient reports whether err is a GitHub failure worth retrying: a
+// 500/502/503/504 response or a network timeout. Client errors (4xx) are not.
+func IsTransient(err error) bool {
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) && ghErr.Response != nil {
+		switch ghErr.Response.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isAlreadyExists reports whether GitHub rejected a create because the object
+// (e.g. a pull request for the same head) already exists.
+func isAlreadyExists(err error) bool {
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil || ghErr.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	if strings.Contains(ghErr.Message, "already exists") {
+		return true
+	}
+	for _, e := range ghErr.Errors {
+		if strings.Contains(e.Message, "already exists") {
+			return true
+		}
+	}
+	return false
+}
+
+// withRetry runs call until it succeeds, fails with a non-transient error or
+// the policy's attempts are used up. call receives the 1-based attempt number
+// so non-idempotent operations can check whether an earlier try went through.
+func (c *Client) withRetry(op string, call func(attempt int) error) error {
+	policy := c.retry
+	if policy.attempts < 1 {
+		policy = defaultRetryPolicy
+	}
+	ctx := c.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var err error
+	delay := policy.baseDelay
+	for attempt := 1; ; attempt++ {
+		err = call(attempt)
+		if err == nil || !IsTransient(err) || attempt >= policy.attempts {
+			return err
+		}
+		wait := delay + rand.N(delay/2+1)
+		slog.Debug("Transient GitHub error, retrying", "op", op, "attempt", attempt, "wait", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
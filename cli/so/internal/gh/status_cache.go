@@ -0,0 +1,172 @@
+package gh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/credential"
+)
+
+// DefaultStatusCacheTTL is how long a PR status is reused when
+// socle.prStatusCacheTTL is unset.
+const DefaultStatusCacheTTL = 60 * time.Second
+
+// StatusCachePath returns the file PR statuses are cached in, next to the
+// gh token cache. Tests point it at a temporary directory.
+var StatusCachePath = func() (string, error) {
+	return credential.CachePath("pr_status.json")
+}
+
+// cachedStatus is a PR status and URL as GetPullRequestStatus returned them.
+type cachedStatus struct {
+	Status    string    `json:"status"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// statusCacheClient answers GetPullRequestStatus from the cache file while an
+// entry is fresh, and asks the wrapped client otherwise. All other calls pass
+// straight through.
+type statusCacheClient struct {
+	ClientInterface
+	repo        string // owner/repo, part of every key
+	path        string
+	ttl         time.Duration
+	settledOnly bool // Reuse only merged and closed statuses
+
+	mu      sync.Mutex
+	entries map[string]cachedStatus // Loaded on first use
+}
+
+// WithStatusCache wraps client so PR statuses of repo ("owner/repo") are
+// reused for ttl across invocations. With settledOnly, open and draft PRs
+// are always asked about, for callers waiting on them to merge; their fresh
+// statuses are still cached for others. A ttl of zero or an unknown cache
+// location returns client unchanged.
+func WithStatusCache(client ClientInterface, repo string, ttl time.Duration, settledOnly bool) ClientInterface {
+	if client == nil || ttl <= 0 {
+		return client
+	}
+	path, err := StatusCachePath()
+	if err != nil {
+		slog.Debug("No location for the PR status cache. Proceeding without it.", "error", err)
+		return client
+	}
+	return &statusCacheClient{ClientInterface: client, repo: repo, path: path, ttl: ttl, settledOnly: settledOnly}
+}
+
+func (c *statusCacheClient) GetPullRequestStatus(prNumber int) (string, string, error) {
+	key := statusCacheKey(c.repo, prNumber)
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = loadStatusCache(c.path)
+	}
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if c.settledOnly && (entry.Status == PRStatusOpen || entry.Status == PRStatusDraft) {
+		ok = false
+	}
+	if ok && time.Now().Before(entry.ExpiresAt) {
+		slog.Debug("Using cached PR status.", "pr", prNumber, "status", entry.Status)
+		return entry.Status, entry.URL, nil
+	}
+
+	status, url, err := c.ClientInterface.GetPullRequestStatus(prNumber)
+	if err != nil || !cacheableStatus(status) {
+		return status, url, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cachedStatus{Status: status, URL: url, ExpiresAt: time.Now().Add(c.ttl)}
+	if errSave := saveStatusCache(c.path, c.entries); errSave != nil {
+		slog.Debug("Failed to save the PR status cache.", "path", c.path, "error", errSave)
+	}
+	return status, url, nil
+}
+
+func (c *statusCacheClient) MergePullRequest(number int, method, headOID string) error {
+	err := c.ClientInterface.MergePullRequest(number, method, headOID)
+	c.mu.Lock()
+	delete(c.entries, statusCacheKey(c.repo, number))
+	c.mu.Unlock()
+	return err
+}
+
+// ForgetPullRequestStatus drops the cached status of PR number of repo, for
+// changes made outside a cached client.
+func ForgetPullRequestStatus(repo string, number int) {
+	path, err := StatusCachePath()
+	if err != nil {
+		return
+	}
+	entries := loadStatusCache(path)
+	key := statusCacheKey(repo, number)
+	if _, ok := entries[key]; !ok {
+		return
+	}
+	delete(entries, key)
+	if err := saveStatusCache(path, entries); err != nil {
+		slog.Debug("Failed to save the PR status cache.", "path", path, "error", err)
+	}
+}
+
+// cacheableStatus reports whether status is a PR state worth reusing, as
+// opposed to an error or a missing PR that may appear any moment.
+func cacheableStatus(status string) bool {
+	switch status {
+	case PRStatusOpen, PRStatusDraft, PRStatusMerged, PRStatusClosed:
+		return true
+	}
+	return false
+}
+
+func statusCacheKey(repo string, number int) string {
+	return fmt.Sprintf("%s#%d", repo, number)
+}
+
+// loadStatusCache reads the unexpired entries of the cache file. A missing
+// or unreadable file is an empty cache.
+func loadStatusCache(path string) map[string]cachedStatus {
+	entries := make(map[string]cachedStatus)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Debug("Failed to read the PR status cache.", "path", path, "error", err)
+		}
+		return entries
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		slog.Debug("Ignoring a corrupted PR status cache.", "path", path, "error", err)
+		return make(map[string]cachedStatus)
+	}
+	now := time.Now()
+	for key, entry := range entries {
+		if now.After(entry.ExpiresAt) {
+			delete(entries, key)
+		}
+	}
+	return entries
+}
+
+// saveStatusCache writes entries to path, readable only by the user.
+func saveStatusCache(path string, entries map[string]cachedStatus) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory '%s': %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal PR statuses for cache: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write PR status cache '%s': %w", tmp, err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,119 @@
+package gh
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+	"github.com/benekuehn/socle/cli/so/internal/profile"
+)
+
+// requestTimeout bounds every GitHub request.
+const requestTimeout = 15 * time.Second
+
+// NewHTTPClient returns the client socle sends GitHub requests through,
+// other than the API calls of a Client, which add authentication to the
+// same transport. See newTransport.
+func NewHTTPClient() (*http.Client, error) {
+	transport, err := newTransport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: &profile.Transport{Base: transport}, Timeout: requestTimeout}, nil
+}
+
+// newTransport builds the transport for GitHub requests from the
+// environment and config: HTTPS_PROXY, HTTP_PROXY and NO_PROXY choose the
+// proxy, socle.caBundle adds a PEM bundle of CAs to the system roots (for a
+// TLS-intercepting proxy or a GitHub Enterprise server with an internal CA)
+// and socle.insecureSkipVerify turns certificate checks off altogether.
+// Certificate failures come back with what to configure.
+func newTransport() (http.RoundTripper, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	bundle, err := git.GetSocleConfig("socle.caBundle")
+	if err != nil && !errors.Is(err, git.ErrConfigNotFound) {
+		return nil, err
+	}
+	if bundle = strings.TrimSpace(bundle); bundle != "" {
+		if tlsConfig.RootCAs, err = loadCABundle(bundle); err != nil {
+			return nil, err
+		}
+	}
+	insecure, err := git.GetSocleConfigBool("socle.insecureSkipVerify", false)
+	if err != nil {
+		return nil, err
+	}
+	if insecure {
+		slog.Warn("TLS certificate verification is off (socle.insecureSkipVerify); use socle.caBundle instead where possible")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return &tlsHintTransport{base: &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+		ForceAttemptHTTP2:   true,
+	}}, nil
+}
+
+// loadCABundle returns the system roots plus the certificates in the PEM
+// file at path.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read socle.caBundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("socle.caBundle '%s' holds no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// tlsHintTransport explains certificate failures, which otherwise surface as
+// a bare "x509: ..." inside a request error.
+type tlsHintTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tlsHintTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, explainTLSError(req.URL.Host, err)
+	}
+	return resp, nil
+}
+
+// explainTLSError adds what to configure to a certificate failure talking to
+// host. Other errors are returned as they are.
+func explainTLSError(host string, err error) error {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var verify *tls.CertificateVerificationError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return fmt.Errorf("the TLS certificate of %s is signed by an unknown authority; if a proxy or GitHub Enterprise server uses an internal CA, point socle.caBundle at its PEM bundle: %w", host, err)
+	case errors.As(err, &hostname):
+		return fmt.Errorf("the TLS certificate presented for %s is for another host; check HTTPS_PROXY and NO_PROXY: %w", host, err)
+	case errors.As(err, &invalid):
+		return fmt.Errorf("the TLS certificate of %s is not valid (expired, or not yet valid); check the system clock and the certificate: %w", host, err)
+	case errors.As(err, &verify):
+		return fmt.Errorf("the TLS certificate of %s could not be verified; see socle.caBundle: %w", host, err)
+	}
+	return err
+}
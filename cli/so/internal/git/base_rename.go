@@ -0,0 +1,143 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrBaseBranchMissing is returned when a stack's configured socle-base no
+// longer exists locally, typically because the default branch was renamed.
+var ErrBaseBranchMissing = errors.New("base branch does not exist")
+
+// GetRemoteDefaultBranch returns the branch refs/remotes/<remote>/HEAD points
+// to (e.g. "main"), as set by clone or `git remote set-head <remote> --auto`.
+func GetRemoteDefaultBranch(remoteName string) (string, error) {
+	ref, err := RunGitCommand("symbolic-ref", "--quiet", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", remoteName))
+	if err != nil {
+		return "", fmt.Errorf("cannot determine default branch of '%s' (try 'git remote set-head %s --auto'): %w", remoteName, remoteName, err)
+	}
+	return strings.TrimPrefix(ref, remoteName+"/"), nil
+}
+
+// BaseRename describes a socle-base value that no longer exists and the
+// branch that replaces it.
+type BaseRename struct {
+	From string
+	To   string
+	// Branches whose socle-base is From, sorted.
+	Branches []string
+}
+
+// DetectBaseRenames finds socle-base values that are missing locally and pairs
+// them with the remote's current default branch. Returns nil when all bases exist.
+func DetectBaseRenames(remoteName string) ([]BaseRename, error) {
+	meta, err := ReadSocleMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	byBase := make(map[string][]string)
+	for key, values := range meta {
+		branch, ok := metadataKeyBranch(key, "socle-base")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		base := values[len(values)-1]
+		byBase[base] = append(byBase[base], branch)
+	}
+
+	var missing []string
+	for base := range byBase {
+		exists, err := BranchExists(base)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, base)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	sort.Strings(missing)
+
+	target, err := GetRemoteDefaultBranch(remoteName)
+	if err != nil {
+		return nil, err
+	}
+
+	renames := make([]BaseRename, 0, len(missing))
+	for _, base := range missing {
+		if base == target {
+			continue // The default branch itself is missing locally; nothing to migrate to
+		}
+		branches := byBase[base]
+		sort.Strings(branches)
+		renames = append(renames, BaseRename{From: base, To: target, Branches: branches})
+	}
+	return renames, nil
+}
+
+// MigrateBase rewrites every socle-base, socle-parent and
+// socle-pr-base-override value equal to from so it points at to. Returns the
+// branches whose parent changed (their PRs target from and need
+// retargeting), sorted.
+func MigrateBase(from, to string) ([]string, error) {
+	meta, err := ReadSocleMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	var reparented []string
+	for key, values := range meta {
+		if len(values) == 0 || values[len(values)-1] != from {
+			continue
+		}
+		branch, isParent := metadataKeyBranch(key, "socle-parent")
+		if !isParent {
+			_, isBase := metadataKeyBranch(key, "socle-base")
+			_, isOverride := metadataKeyBranch(key, "socle-pr-base-override")
+			if !isBase && !isOverride {
+				continue
+			}
+		}
+		if err := UnsetGitConfig(key); err != nil {
+			return nil, fmt.Errorf("failed to reset '%s': %w", key, err)
+		}
+		if err := SetGitConfig(key, to); err != nil {
+			return nil, fmt.Errorf("failed to set '%s': %w", key, err)
+		}
+		if isParent {
+			reparented = append(reparented, branch)
+		}
+	}
+	sort.Strings(reparented)
+	return reparented, nil
+}
+
+// retargetPRBaseOverrides points every PR base override naming from at to
+// instead, after from was deleted. An override that ends up naming the
+// branch's own parent, or an empty to, is removed, so the PR targets the
+// parent as usual.
+func retargetPRBaseOverrides(from, to string) error {
+	meta, err := ReadSocleMetadata()
+	if err != nil {
+		return err
+	}
+	for key, values := range meta {
+		branch, ok := metadataKeyBranch(key, "socle-pr-base-override")
+		if !ok || len(values) == 0 || values[len(values)-1] != from {
+			continue
+		}
+		target := to
+		if parent := meta[BranchConfigKey(branch, "socle-parent")]; len(parent) > 0 && parent[len(parent)-1] == to {
+			target = ""
+		}
+		if err := SetPRBaseOverride(branch, target); err != nil {
+			return fmt.Errorf("failed to retarget the PR base of '%s': %w", branch, err)
+		}
+	}
+	return nil
+}
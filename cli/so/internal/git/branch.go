@@ -14,13 +14,87 @@ func GetCurrentBranch() (string, error) {
 
 // GetLocalBranches returns a list of local branch names.
 func GetLocalBranches() ([]string, error) {
-	// Using --format='%(refname:short)' is generally robust
-	output, err := RunGitCommand("branch", "--list", "--format=%(refname:short)")
+	return GetLocalBranchesMatching()
+}
+
+// GetLocalBranchesMatching returns the local branches matching any of
+// patterns (see ForEachLocalBranch), sorted by name.
+func GetLocalBranchesMatching(patterns ...string) ([]string, error) {
+	branches := []string{}
+	err := ForEachLocalBranch(patterns, func(name string) bool {
+		branches = append(branches, name)
+		return true
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list local branches: %w", err)
+		return nil, err
+	}
+	return branches, nil
+}
+
+// ForEachLocalBranch calls fn with each local branch, sorted by name, until fn
+// returns false. Patterns filter the branches in git, the way
+// 'git for-each-ref' does: "team/*" matches one level of "team/", "team"
+// matches "team" and everything below it. Without patterns every branch is
+// listed. Names are streamed, so callers that stop early or keep only some
+// never hold all the branches of a large repository.
+func ForEachLocalBranch(patterns []string, fn func(name string) bool) error {
+	args := []string{"for-each-ref", "--format=%(refname:lstrip=2)"}
+	if len(patterns) == 0 {
+		args = append(args, "refs/heads/")
+	}
+	for _, pattern := range patterns {
+		args = append(args, "refs/heads/"+pattern)
+	}
+	err := streamGitCommand(func(line string) bool {
+		if line == "" {
+			return true
+		}
+		return fn(line)
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list local branches: %w", err)
+	}
+	return nil
+}
+
+// ExistingLocalBranches reports which of names are local branches. Only those
+// are kept while the branches are read, so checking the tracked branches of a
+// repository with thousands of others stays small.
+func ExistingLocalBranches(names []string) (map[string]bool, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	exists := make(map[string]bool, len(wanted))
+	err := ForEachLocalBranch(nil, func(name string) bool {
+		if wanted[name] {
+			exists[name] = true
+		}
+		return len(exists) < len(wanted) // All found; the rest cannot matter
+	})
+	return exists, err
+}
+
+// GetLocalBranchesWithPrefix returns up to limit local branches whose names
+// start with prefix, at any depth, sorted by name.
+func GetLocalBranchesWithPrefix(prefix string, limit int) ([]string, error) {
+	var branches []string
+	err := ForEachLocalBranch([]string{prefix + "*", prefix + "*/**"}, func(name string) bool {
+		branches = append(branches, name)
+		return len(branches) < limit
+	})
+	return branches, err
+}
+
+// GetRecentLocalBranches returns up to limit local branches, most recently
+// committed to first.
+func GetRecentLocalBranches(limit int) ([]string, error) {
+	output, err := RunGitCommand("for-each-ref", "--sort=-committerdate", fmt.Sprintf("--count=%d", limit), "--format=%(refname:lstrip=2)", "refs/heads/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent local branches: %w", err)
 	}
 	if output == "" {
-		return []string{}, nil // No branches found
+		return []string{}, nil
 	}
 	return strings.Split(output, "\n"), nil
 }
@@ -39,11 +113,48 @@ func CheckoutBranch(name string) error {
 	// We assume the branch exists when calling this
 	_, err := RunGitCommand("checkout", name)
 	if err != nil {
+		if inTheWay, errPaths := localChangesInTheWay(name); errPaths == nil && inTheWay {
+			return newKindError(ErrLocalChanges, err, "failed to checkout branch '%s': local changes would be overwritten", name)
+		}
 		return fmt.Errorf("failed to checkout branch '%s': %w", name, err)
 	}
 	return nil
 }
 
+// localChangesInTheWay reports whether an uncommitted or untracked path is one
+// that checking out target would change, which is why git refuses to switch.
+func localChangesInTheWay(target string) (bool, error) {
+	local := make(map[string]bool)
+	for _, args := range [][]string{
+		{"diff", "--name-only", "-z", "HEAD"},
+		{"ls-files", "-z", "--others", "--exclude-standard"},
+	} {
+		output, err := RunGitCommand(args...)
+		if err != nil {
+			return false, fmt.Errorf("failed to list local changes: %w", err)
+		}
+		for _, path := range strings.Split(output, "\x00") {
+			if path != "" {
+				local[path] = true
+			}
+		}
+	}
+	if len(local) == 0 {
+		return false, nil
+	}
+
+	changed, err := RunGitCommand("diff", "--name-only", "-z", "HEAD", target)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare HEAD with '%s': %w", target, err)
+	}
+	for _, path := range strings.Split(changed, "\x00") {
+		if local[path] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // BranchExists checks if a local branch with the given name exists.
 func BranchExists(name string) (bool, error) {
 	ref := fmt.Sprintf("refs/heads/%s", name)
@@ -76,8 +187,8 @@ func IsValidBranchName(name string) error {
 	if err == nil {
 		return nil // Exit code 0 means valid
 	}
-	// Check if the error is the specific "invalid format" error
-	if strings.Contains(err.Error(), "invalid format (exit status 1)") {
+	// Exit code 1 means git parsed the name and rejected it
+	if exitCode(err) == 1 {
 		// Provide a slightly more user-friendly error message than the raw git output
 		return fmt.Errorf("'%s' is not a valid branch name", name)
 	}
@@ -85,12 +196,40 @@ func IsValidBranchName(name string) error {
 	return fmt.Errorf("failed to validate branch name '%s': %w", name, err)
 }
 
+// RenameBranch renames the local branch from to to. Git moves the branch's
+// config section along, socle's metadata and upstream included; metadata in
+// a worktree's config.worktree is moved here.
+func RenameBranch(from, to string) error {
+	defer invalidateBranchConfigCache()
+	if _, err := RunGitCommand("branch", "-m", from, to); err != nil {
+		return fmt.Errorf("failed to rename branch '%s' to '%s': %w", from, to, err)
+	}
+	if err := moveWorktreeBranchMetadata(from, to); err != nil {
+		return fmt.Errorf("renamed '%s' to '%s', but its worktree metadata was not moved: %w", from, to, err)
+	}
+	return nil
+}
+
 // BranchDelete force deletes a local branch. Used for cleanup.
 func BranchDelete(name string) error {
 	// Use -D for force delete
+	defer invalidateBranchConfigCache()
 	_, err := RunGitCommand("branch", "-D", name)
 	if err != nil {
 		return fmt.Errorf("failed to delete branch '%s': %w", name, err)
 	}
+	return moveWorktreeBranchMetadata(name, "")
+}
+
+// CreateTrackingBranch creates (or with force, resets) the local branch name
+// at <remote>/<name> and sets it as the branch's upstream.
+func CreateTrackingBranch(name, remoteName string, force bool) error {
+	args := []string{"branch", "--track"}
+	if force {
+		args = append(args, "--force")
+	}
+	if _, err := RunGitCommand(append(args, name, fmt.Sprintf("%s/%s", remoteName, name))...); err != nil {
+		return fmt.Errorf("failed to create branch '%s' from '%s/%s': %w", name, remoteName, name, err)
+	}
 	return nil
 }
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// BranchConfigKey builds the git config key for a per-branch setting,
+// branch.<branch>.<name>. Git treats everything between the first and the last
+// dot as the subsection, so branch names with dots, slashes or unicode need no
+// escaping on the command line.
+func BranchConfigKey(branch, name string) string {
+	return "branch." + branch + "." + name
+}
+
+// ParseBranchConfigKey splits a key as reported by git (section and variable
+// lower-cased, subsection verbatim) into the branch name and the variable.
+func ParseBranchConfigKey(key string) (branch, name string, ok bool) {
+	rest, found := strings.CutPrefix(key, "branch.")
+	if !found {
+		return "", "", false
+	}
+	dot := strings.LastIndex(rest, ".")
+	if dot <= 0 || dot == len(rest)-1 {
+		return "", "", false
+	}
+	return rest[:dot], rest[dot+1:], true
+}
+
+// configEntry is one key/value pair from 'git config --get-regexp'.
+type configEntry struct {
+	Key   string
+	Value string
+}
+
+// getRepoConfigRegexp returns the keys matching pattern in the repository's
+// own config: the local config, then the worktree's config.worktree, so that
+// a worktree's value comes last and wins. User and system config are left
+// out. NUL-separated output keeps values with spaces or newlines intact,
+// unlike splitting "key value" lines. No matching key is not an error.
+func getRepoConfigRegexp(pattern string) ([]configEntry, error) {
+	output, err := RunGitCommand("config", "--show-scope", "--null", "--get-regexp", pattern)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read git config matching '%s': %w", pattern, err)
+	}
+
+	var entries []configEntry
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		if scope := fields[i]; scope != "local" && scope != "worktree" {
+			continue
+		}
+		key, value, _ := strings.Cut(fields[i+1], "\n")
+		entries = append(entries, configEntry{Key: key, Value: value})
+	}
+	return entries, nil
+}
@@ -0,0 +1,53 @@
+package git
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+)
+
+// ErrCherryPickConflict indicates a git cherry-pick stopped due to conflicts.
+var ErrCherryPickConflict = socleerr.New(socleerr.Conflict, "cherry-pick conflict detected")
+
+// IsCherryPickInProgress reports whether a cherry-pick is paused, either on a
+// conflicting commit or with the rest of a range still queued.
+func IsCherryPickInProgress() bool {
+	for _, name := range []string{"CHERRY_PICK_HEAD", "sequencer"} {
+		path, err := RunGitCommand("rev-parse", "--git-path", name)
+		if err != nil {
+			return false
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CherryPickRange applies the commits in fromRef..toRef onto the current
+// branch with `git cherry-pick -x`, so every copy names its original commit.
+// Returns ErrCherryPickConflict if git paused for the user to resolve.
+func CherryPickRange(fromRef, toRef string) error {
+	_, err := RunGitCommand("cherry-pick", "-x", fmt.Sprintf("%s..%s", fromRef, toRef))
+	if err == nil {
+		return nil
+	}
+	if IsCherryPickInProgress() {
+		return ErrCherryPickConflict
+	}
+	return fmt.Errorf("git cherry-pick %s..%s failed: %w", fromRef, toRef, err)
+}
+
+// FetchPullRequestHead fetches refs/pull/<number>/head from remoteName, which
+// GitHub keeps even after the PR's branch is deleted, and returns its commit.
+func FetchPullRequestHead(remoteName string, number int) (string, error) {
+	if _, err := RunGitCommand("fetch", "--quiet", remoteName, fmt.Sprintf("refs/pull/%d/head", number)); err != nil {
+		return "", fmt.Errorf("failed to fetch the head of PR #%d from '%s': %w", number, remoteName, err)
+	}
+	oid, err := RunGitCommand("rev-parse", "--verify", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the head of PR #%d: %w", number, err)
+	}
+	return oid, nil
+}
@@ -14,13 +14,23 @@ func GetCurrentCommit() (string, error) {
 	return output, nil
 }
 
+// ResolveCommit returns the full hash of the commit rev names, e.g. HEAD~2
+// or an abbreviated hash.
+func ResolveCommit(rev string) (string, error) {
+	output, err := RunGitCommand("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a commit", rev)
+	}
+	return output, nil
+}
+
 // GetMergeBase finds the best common ancestor commit between two refs.
 func GetMergeBase(ref1, ref2 string) (string, error) {
 	output, err := RunGitCommand("merge-base", ref1, ref2)
 	if err != nil {
-		// Propagate the specific error from RunGitCommand for merge-base
-		if strings.Contains(err.Error(), "failed:") { // Check if it's the specific error we added
-			return "", fmt.Errorf("no common ancestor found between '%s' and '%s'", ref1, ref2)
+		// merge-base exits with 1 when the refs share no history
+		if exitCode(err) == 1 {
+			return "", newKindError(ErrNoCommonAncestor, err, "no common ancestor found between '%s' and '%s'", ref1, ref2)
 		}
 		return "", err // Other unexpected errors
 	}
@@ -28,37 +38,71 @@ func GetMergeBase(ref1, ref2 string) (string, error) {
 }
 
 // GetFirstCommitSubject returns the subject line of the first commit unique to branchRef compared to parentRef.
-// Returns empty string if no unique commits found or on error getting log.
+// Returns empty string if no unique commits found. See BranchCommitSubjects for which commits count.
 func GetFirstCommitSubject(parentRef, branchRef string) (string, error) {
-	// --reverse lists oldest first
-	// %s gives just the subject
-	// Use parent..HEAD if branchRef is the current branch and parentRef is its direct tracked parent?
-	// No, stick to explicit range for clarity.
-	logRange := fmt.Sprintf("%s..%s", parentRef, branchRef)
-	output, err := RunGitCommand("log", "--reverse", "--format=%s", logRange)
+	subjects, err := BranchCommitSubjects(parentRef, branchRef)
+	if err != nil || len(subjects) == 0 {
+		return "", err
+	}
+	return subjects[0], nil
+}
+
+// BranchCommitSubjects returns the subjects of the commits branchRef adds on
+// top of parentRef, oldest first. The range starts at the merge base of the
+// two, so a parent that moved on does not matter. Merge commits are skipped,
+// and so are commits whose patch parentRef already has, as after the
+// parent's commits were rebased or cherry-picked into it. Patches are compared
+// one commit at a time, so commits squashed together into parentRef still
+// count.
+func BranchCommitSubjects(parentRef, branchRef string) ([]string, error) {
+	mergeBase, err := GetMergeBase(parentRef, branchRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find where '%s' forks from '%s': %w", branchRef, parentRef, err)
+	}
+	// --cherry-pick compares patches with the parent's side of the
+	// symmetric range, which starts at the same merge base.
+	output, err := RunGitCommand("log", "--reverse", "--topo-order", "--no-merges", "--right-only", "--cherry-pick", "--format=%s",
+		parentRef+"..."+branchRef, "^"+mergeBase)
 	if err != nil {
-		// Log command itself might fail if refs are invalid, etc.
-		// Return empty string and the error for the caller to handle/warn.
-		return "", fmt.Errorf("failed to get log for range '%s': %w", logRange, err)
+		return nil, fmt.Errorf("failed to get log for range '%s..%s': %w", mergeBase, branchRef, err)
 	}
 	if output == "" {
-		// No unique commits found in the range
-		return "", nil // Return empty, no error
+		return nil, nil
+	}
+	var subjects []string
+	for _, line := range strings.Split(output, "\n") {
+		if subject := strings.TrimSpace(line); subject != "" {
+			subjects = append(subjects, subject)
+		}
+	}
+	return subjects, nil
+}
+
+// CommitMessages returns the full messages of the commits in
+// parentRef..branchRef, oldest first.
+func CommitMessages(parentRef, branchRef string) ([]string, error) {
+	output, err := RunGitCommand("log", "--reverse", "--format=%B%x00", parentRef+".."+branchRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log for range '%s..%s': %w", parentRef, branchRef, err)
 	}
-	// Split into lines and take the first one
-	lines := strings.SplitN(output, "\n", 2) // Split only once to get the first line
-	subject := strings.TrimSpace(lines[0])
-	return subject, nil
+	var messages []string
+	for _, message := range strings.Split(output, "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
 }
 
 // GetCurrentBranchCommit returns the full commit hash for the tip of a specific local branch.
 func GetCurrentBranchCommit(branchName string) (string, error) {
 	// Ensure we are asking for the local branch ref
 	ref := fmt.Sprintf("refs/heads/%s", branchName)
-	// Use rev-parse without --verify, as we expect the branch to exist
-	// when this is called in the restack loop.
-	output, err := RunGitCommand("rev-parse", ref)
+	output, err := RunGitCommand("rev-parse", "--verify", "--quiet", ref)
 	if err != nil {
+		if exitCode(err) == 1 {
+			return "", newKindError(ErrRefNotFound, err, "branch '%s' does not exist", branchName)
+		}
 		// This error is more serious than BranchExists failing, as we expect
 		// the branch (parent or current) to exist during the restack loop.
 		// The error from RunGitCommand will include stderr detail.
@@ -99,3 +143,90 @@ func GetMultipleBranchCommits(branchNames []string) (map[string]string, error) {
 
 	return commitMap, nil
 }
+
+// CommitAuthor identifies the author of a commit.
+type CommitAuthor struct {
+	Name  string
+	Email string
+}
+
+// Matches reports whether identity (a name or an email) refers to this author.
+// The comparison is case-insensitive and ignores surrounding angle brackets.
+func (a CommitAuthor) Matches(identity string) bool {
+	identity = strings.Trim(strings.TrimSpace(identity), "<>")
+	if identity == "" {
+		return false
+	}
+	return strings.EqualFold(identity, a.Name) || strings.EqualFold(identity, a.Email)
+}
+
+// GetBranchTipAuthors returns the author of the tip commit of every local branch
+// in branchNames, using a single `git for-each-ref` call. Branches that do not
+// exist are omitted from the result.
+func GetBranchTipAuthors(branchNames []string) (map[string]CommitAuthor, error) {
+	authors := make(map[string]CommitAuthor)
+	if len(branchNames) == 0 {
+		return authors, nil
+	}
+
+	wanted := make(map[string]bool, len(branchNames))
+	for _, b := range branchNames {
+		wanted[b] = true
+	}
+
+	output, err := RunGitCommand("for-each-ref", "--format=%(refname:short)%00%(authorname)%00%(authoremail)", "refs/heads/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branch authors: %w", err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, "\x00")
+		if len(parts) != 3 || !wanted[parts[0]] {
+			continue
+		}
+		authors[parts[0]] = CommitAuthor{Name: parts[1], Email: strings.Trim(parts[2], "<>")}
+	}
+	return authors, nil
+}
+
+// GetAuthorIdentity returns the identity used to decide which branches are
+// "mine": socle.author if configured (a name or an email), otherwise
+// user.email, otherwise user.name. Returns "" if none are set.
+func GetAuthorIdentity() string {
+	for _, key := range []string{"socle.author", "user.email", "user.name"} {
+		if value, err := GetSocleConfig(key); err == nil && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// GetChangedPaths lists the paths changed on branchRef since it diverged from
+// parentRef. Only trees are compared, so no blobs are read.
+func GetChangedPaths(parentRef, branchRef string) ([]string, error) {
+	output, err := RunGitCommand("diff", "--name-only", "--no-renames", fmt.Sprintf("%s...%s", parentRef, branchRef))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed paths of '%s': %w", branchRef, err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// GetAddedLines returns the patch of what branchRef changed since it
+// diverged from parentRef, without context lines, for scanning added content.
+// Unlike GetChangedPaths this reads blobs.
+func GetAddedLines(parentRef, branchRef string) (string, error) {
+	output, err := RunGitCommand("diff", "--no-color", "--no-textconv", "--no-ext-diff", "--no-renames", "-U0", fmt.Sprintf("%s...%s", parentRef, branchRef))
+	if err != nil {
+		return "", fmt.Errorf("failed to diff '%s' against '%s': %w", branchRef, parentRef, err)
+	}
+	return output, nil
+}
+
+// GetUnmergedCommits returns the commits in parentRef..branchRef that are not
+// reachable from trunkRef, oldest first: the commits that become unreachable
+// when branchRef is deleted.
+func GetUnmergedCommits(parentRef, branchRef, trunkRef string) ([]Commit, error) {
+	return GetCommits(parentRef, branchRef, "^"+trunkRef)
+}
@@ -5,13 +5,27 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
-	"regexp"
+	"strconv"
 	"strings"
 )
 
 // GetGitConfig retrieves a specific git config key's value.
 // Returns an error containing "exit status 1" if the key doesn't exist.
+// Per-branch socle keys are served from the branch config cache, which holds
+// the repository's and the worktree's config only; socle never writes them
+// anywhere else.
 func GetGitConfig(key string) (string, error) {
+	if isBranchConfigKey(key) {
+		value, ok, err := cachedBranchConfig(key)
+		if err != nil {
+			return "", fmt.Errorf("failed to get git config '%s': %w", key, err)
+		}
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrConfigNotFound, key)
+		}
+		return strings.TrimSpace(value), nil
+	}
+
 	// Assumes RunGitCommand exists and returns error wrapping *exec.ExitError on failure
 	output, err := RunGitCommand("config", "--get", key)
 	if err == nil {
@@ -29,23 +43,56 @@ func GetGitConfig(key string) (string, error) {
 	return "", fmt.Errorf("failed to get git config '%s': %w", key, err) // <-- Use %w here too
 }
 
+// GetGitConfigAll retrieves every value of a multi-valued git config key.
+// Returns an empty slice (not an error) if the key doesn't exist.
+func GetGitConfigAll(key string) ([]string, error) {
+	output, err := RunGitCommand("config", "--get-all", key)
+	if err == nil {
+		if output == "" {
+			return []string{}, nil
+		}
+		return strings.Split(output, "\n"), nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return []string{}, nil // Key not found
+	}
+	return nil, fmt.Errorf("failed to get git config '%s': %w", key, err)
+}
+
 // SetGitConfig sets (or adds) a git config key-value pair.
 // Uses --add to avoid deleting other values if the key somehow exists multiple times,
 // though for our usage, a simple set would likely be fine too.
 func SetGitConfig(key, value string) error {
-	// Using --local ensures we write to .git/config, not global or system
-	_, err := RunGitCommand("config", "--local", "--add", key, value)
+	// Never global or system config: the repository's, or with
+	// socle.metadataScope=worktree, the worktree's
+	scope, err := metadataScopeOption()
+	if err != nil {
+		return err
+	}
+	defer invalidateBranchConfigCache()
+	_, err = RunGitCommand("config", scope, "--add", key, value)
 	return err
 }
 
 // UnsetGitConfig removes a git config key.
 // Useful for cleanup or an 'untrack' command.
+// The key is removed from the worktree's config as well, since it would
+// still be read from there.
 func UnsetGitConfig(key string) error {
-	// Use --unset-all in case --add resulted in multiple entries (unlikely for us)
-	_, err := RunGitCommand("config", "--local", "--unset-all", key)
-	// Ignore exit code 5 which means the section or key was not found
-	if err != nil && !strings.Contains(err.Error(), "exit status 5") {
-		return err
+	scopes := []string{"--local"}
+	if WorktreeConfigEnabled() {
+		scopes = append(scopes, "--worktree")
+	}
+	defer invalidateBranchConfigCache()
+	for _, scope := range scopes {
+		// Use --unset-all in case --add resulted in multiple entries (unlikely for us)
+		_, err := RunGitCommand("config", scope, "--unset-all", key)
+		// Ignore exit code 5 which means the section or key was not found
+		if err != nil && exitCode(err) != 5 {
+			return err
+		}
 	}
 	return nil
 }
@@ -74,39 +121,14 @@ var ErrConfigNotFound = errors.New("git config key not found")
 
 // GetAllSocleParents returns a map of childBranch -> parentBranch based on socle config.
 func GetAllSocleParents() (map[string]string, error) {
-	// Get all config keys matching the pattern using --get-regexp
-	// Note: Output format is "key value\nkey value\n..."
-	output, err := RunGitCommand("config", "--local", "--get-regexp", `^branch\.(.+)\.socle-parent$`)
+	values, err := branchConfigValues()
 	if err != nil {
-		// It's okay if no keys are found (exit code 1 from --get-regexp)
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
-			return make(map[string]string), nil // No keys found, return empty map
-		}
-		// Other unexpected error
 		return nil, fmt.Errorf("failed to get socle parent configs: %w", err)
 	}
 
 	parentMap := make(map[string]string)
-	lines := strings.Split(output, "\n")
-	// Regex to extract the branch name from the key
-	keyRegex := regexp.MustCompile(`^branch\.(.+)\.socle-parent$`)
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, " ", 2) // Split key and value
-		if len(parts) != 2 {
-			continue
-		} // Skip malformed lines
-
-		key := parts[0]
-		value := parts[1]
-
-		matches := keyRegex.FindStringSubmatch(key)
-		if len(matches) == 2 {
-			childBranch := matches[1]
+	for key, value := range values {
+		if childBranch, name, ok := ParseBranchConfigKey(key); ok && name == "socle-parent" {
 			parentMap[childBranch] = value
 		}
 	}
@@ -165,14 +187,40 @@ func FindAllDescendants(startNode string, childMap map[string][]string) []string
 
 // --- Socle Specific Config Helpers ---
 
+// GetAllStoredPRNumbers returns the stored PR number of every branch that
+// has one, read in a single config scan.
+func GetAllStoredPRNumbers() (map[string]int, error) {
+	values, err := branchConfigValues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored PR numbers: %w", err)
+	}
+
+	numbers := make(map[string]int)
+	for key, value := range values {
+		branch, name, ok := ParseBranchConfigKey(key)
+		if !ok || name != "socle-pr-number" || value == "" {
+			continue
+		}
+		number := 0
+		if _, errParse := fmt.Sscan(value, &number); errParse != nil {
+			slog.Warn("Could not parse stored PR number", "value", value, "branch", branch, "error", errParse)
+			continue
+		}
+		if number > 0 {
+			numbers[branch] = number
+		}
+	}
+	return numbers, nil
+}
+
 // GetStoredPRNumber reads the locally stored PR number for a branch.
 // Returns 0 if not found or parse error occurs.
 func GetStoredPRNumber(branch string) (int, error) {
-	prNumberKey := fmt.Sprintf("branch.%s.socle-pr-number", branch)
+	prNumberKey := BranchConfigKey(branch, "socle-pr-number")
 	prNumberStr, err := GetGitConfig(prNumberKey) // Use gitutils.GetGitConfig
 	if err != nil {
 		// Distinguish "not found" from other errors
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, ErrConfigNotFound) {
 			return 0, nil // Not found is not an error, just means no stored number
 		}
 		return 0, err // Actual error reading config
@@ -193,7 +241,7 @@ func GetStoredPRNumber(branch string) (int, error) {
 
 // SetStoredPRNumber writes the PR number for a branch to local git config.
 func SetStoredPRNumber(branch string, prNumber int) error {
-	prNumberKey := fmt.Sprintf("branch.%s.socle-pr-number", branch)
+	prNumberKey := BranchConfigKey(branch, "socle-pr-number")
 	prNumberStr := fmt.Sprintf("%d", prNumber)
 	slog.Debug("Storing PR number in git config", "key", prNumberKey, "value", prNumberStr)
 	err := SetGitConfig(prNumberKey, prNumberStr) // Use gitutils.SetGitConfig
@@ -206,7 +254,7 @@ func SetStoredPRNumber(branch string, prNumber int) error {
 
 // UnsetStoredPRNumber removes the stored PR number for a branch from local git config.
 func UnsetStoredPRNumber(branch string) error {
-	prNumberKey := fmt.Sprintf("branch.%s.socle-pr-number", branch)
+	prNumberKey := BranchConfigKey(branch, "socle-pr-number")
 	slog.Debug("Unsetting PR number in git config", "key", prNumberKey)
 	err := UnsetGitConfig(prNumberKey) // Use gitutils.UnsetGitConfig
 	if err != nil {
@@ -220,10 +268,10 @@ func UnsetStoredPRNumber(branch string) error {
 // GetStoredCommentID reads the locally stored stack comment ID for a branch.
 // Returns 0 if not found or parse error occurs.
 func GetStoredCommentID(branch string) (int64, error) {
-	key := fmt.Sprintf("branch.%s.socle-comment-id", branch)
+	key := BranchConfigKey(branch, "socle-comment-id")
 	val, err := GetGitConfig(key) // Use gitutils.GetGitConfig
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, ErrConfigNotFound) {
 			return 0, nil // Not found
 		}
 		return 0, err // Read error
@@ -242,7 +290,7 @@ func GetStoredCommentID(branch string) (int64, error) {
 
 // SetStoredCommentID writes the stack comment ID for a branch to local git config.
 func SetStoredCommentID(branch string, commentID int64) error {
-	key := fmt.Sprintf("branch.%s.socle-comment-id", branch)
+	key := BranchConfigKey(branch, "socle-comment-id")
 	val := fmt.Sprintf("%d", commentID)
 	slog.Debug("Storing Comment ID in git config", "key", key, "value", val)
 	err := SetGitConfig(key, val) // Use gitutils.SetGitConfig
@@ -254,7 +302,7 @@ func SetStoredCommentID(branch string, commentID int64) error {
 
 // UnsetStoredCommentID removes the stored stack comment ID for a branch from local git config.
 func UnsetStoredCommentID(branch string) error {
-	key := fmt.Sprintf("branch.%s.socle-comment-id", branch)
+	key := BranchConfigKey(branch, "socle-comment-id")
 	slog.Debug("Unsetting Comment ID in git config", "key", key)
 	err := UnsetGitConfig(key) // Use gitutils.UnsetGitConfig
 	if err != nil {
@@ -262,3 +310,322 @@ func UnsetStoredCommentID(branch string) error {
 	}
 	return err // Return error for caller to handle
 }
+
+// IsBranchWIP reports whether a branch is marked work-in-progress via
+// branch.<name>.socle-wip. A mark that cannot be read or is not a boolean is
+// an error; callers about to push treat that like a WIP mark.
+func IsBranchWIP(branch string) (bool, error) {
+	key := BranchConfigKey(branch, "socle-wip")
+	val, err := GetGitConfig(key)
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	wip, err := strconv.ParseBool(strings.TrimSpace(val))
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' for %s: expected true or false", val, key)
+	}
+	return wip, nil
+}
+
+// SetBranchWIP marks or unmarks a branch as work-in-progress.
+func SetBranchWIP(branch string, wip bool) error {
+	key := BranchConfigKey(branch, "socle-wip")
+	if !wip {
+		return UnsetGitConfig(key)
+	}
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	slog.Debug("Marking branch as WIP", "key", key)
+	return SetGitConfig(key, "true")
+}
+
+// GetBranchDeleteOnMerge reports whether the branch's PR was opened asking
+// for its remote branch to be deleted once it merges
+// (branch.<name>.socle-delete-on-merge).
+func GetBranchDeleteOnMerge(branch string) (bool, error) {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-delete-on-merge"))
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return strings.TrimSpace(val) == "true", nil
+}
+
+// SetBranchDeleteOnMerge records that branch's remote branch should be
+// deleted once its PR merges.
+func SetBranchDeleteOnMerge(branch string) error {
+	key := BranchConfigKey(branch, "socle-delete-on-merge")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	return SetGitConfig(key, "true")
+}
+
+// SubmittedState is what 'so submit' last published for a branch: the tip it
+// pushed and the base its PR was given.
+type SubmittedState struct {
+	Tip    string
+	PRBase string
+}
+
+// GetSubmittedState returns the state recorded by the branch's last submit
+// (branch.<name>.socle-submitted), and false if there is none.
+func GetSubmittedState(branch string) (SubmittedState, bool) {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-submitted"))
+	if err != nil {
+		return SubmittedState{}, false
+	}
+	tip, base, ok := strings.Cut(strings.TrimSpace(val), " ")
+	if !ok || tip == "" || base == "" {
+		return SubmittedState{}, false
+	}
+	return SubmittedState{Tip: tip, PRBase: base}, true
+}
+
+// SetSubmittedState records what a submit published for branch. Neither an
+// object ID nor a branch name can contain a space, which separates them.
+func SetSubmittedState(branch string, state SubmittedState) error {
+	key := BranchConfigKey(branch, "socle-submitted")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	return SetGitConfig(key, state.Tip+" "+state.PRBase)
+}
+
+// GetStackName returns the name given to the stack whose bottom branch is
+// bottom (branch.<bottom>.socle-stack-name), or "" if it has none.
+func GetStackName(bottom string) (string, error) {
+	val, err := GetGitConfig(BranchConfigKey(bottom, "socle-stack-name"))
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(val), nil
+}
+
+// SetStackName names the stack whose bottom branch is bottom. An empty name
+// removes it.
+func SetStackName(bottom, name string) error {
+	key := BranchConfigKey(bottom, "socle-stack-name")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	if name == "" {
+		return nil
+	}
+	slog.Debug("Naming stack", "key", key, "name", name)
+	return SetGitConfig(key, name)
+}
+
+// GetTrackingIssue returns the tracking issue of the stack made of branches,
+// the first branch.<b>.socle-tracking-issue set among them, or 0. It is
+// stored on every branch so it outlives the branches that merge first.
+func GetTrackingIssue(branches []string) (int, error) {
+	for _, branch := range branches {
+		val, err := GetGitConfig(BranchConfigKey(branch, "socle-tracking-issue"))
+		if err != nil {
+			if errors.Is(err, ErrConfigNotFound) {
+				continue
+			}
+			return 0, err
+		}
+		number, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil || number <= 0 {
+			return 0, fmt.Errorf("invalid tracking issue '%s' on branch '%s'", val, branch)
+		}
+		return number, nil
+	}
+	return 0, nil
+}
+
+// SetTrackingIssue records issue as the tracking issue on every branch. Zero
+// removes it.
+func SetTrackingIssue(branches []string, issue int) error {
+	for _, branch := range branches {
+		key := BranchConfigKey(branch, "socle-tracking-issue")
+		if issue == 0 {
+			if err := UnsetGitConfig(key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := SetGitConfig(key, strconv.Itoa(issue)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBranchNote returns the free-form note attached to a branch via
+// branch.<name>.socle-note, or "" if there is none.
+func GetBranchNote(branch string) (string, error) {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-note"))
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(val), nil
+}
+
+// SetBranchNote attaches note to a branch, replacing any previous note. An
+// empty note removes it.
+func SetBranchNote(branch, note string) error {
+	key := BranchConfigKey(branch, "socle-note")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	if note == "" {
+		return nil
+	}
+	return SetGitConfig(key, note)
+}
+
+// GetBranchDescription returns the description of what a branch changes
+// (branch.<name>.socle-description), or "" if it has none.
+func GetBranchDescription(branch string) (string, error) {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-description"))
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(val), nil
+}
+
+// SetBranchDescription replaces the description of a branch. An empty
+// description removes it.
+func SetBranchDescription(branch, description string) error {
+	key := BranchConfigKey(branch, "socle-description")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	if description == "" {
+		return nil
+	}
+	return SetGitConfig(key, description)
+}
+
+// GetCommentShape returns the fingerprint of the stack the branch's stack
+// comment was last written for (branch.<name>.socle-comment-shape), or "".
+func GetCommentShape(branch string) string {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-comment-shape"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(val)
+}
+
+// SetCommentShape records the fingerprint of the stack the branch's stack
+// comment was written for.
+func SetCommentShape(branch, shape string) error {
+	key := BranchConfigKey(branch, "socle-comment-shape")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	return SetGitConfig(key, shape)
+}
+
+// GetSyncKept returns the PR status a branch had when it was kept during
+// 'so sync' (branch.<name>.socle-sync-keep), or "" if it was never kept.
+func GetSyncKept(branch string) (string, error) {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-sync-keep"))
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(val), nil
+}
+
+// SetSyncKept remembers that a branch was kept while its PR had status, so
+// sync does not offer to delete it again. An empty status forgets it.
+func SetSyncKept(branch, status string) error {
+	key := BranchConfigKey(branch, "socle-sync-keep")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	if status == "" {
+		return nil
+	}
+	return SetGitConfig(key, status)
+}
+
+// GetBodyOverview reports whether the PR description of branch holds a stack
+// overview for submit to keep current (branch.<name>.socle-body-overview).
+func GetBodyOverview(branch string) bool {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-body-overview"))
+	return err == nil && strings.TrimSpace(val) == "true"
+}
+
+// SetBodyOverview records whether the PR description of branch holds a stack
+// overview.
+func SetBodyOverview(branch string, present bool) error {
+	key := BranchConfigKey(branch, "socle-body-overview")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	if !present {
+		return nil
+	}
+	return SetGitConfig(key, "true")
+}
+
+// GetPRBaseOverride returns the branch the PR of branch targets instead of
+// its parent (branch.<name>.socle-pr-base-override), or "" if none is set.
+func GetPRBaseOverride(branch string) string {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-pr-base-override"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(val)
+}
+
+// SetPRBaseOverride makes the PR of branch target base instead of its
+// parent. An empty base removes the override.
+func SetPRBaseOverride(branch, base string) error {
+	key := BranchConfigKey(branch, "socle-pr-base-override")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	if base == "" {
+		return nil
+	}
+	return SetGitConfig(key, base)
+}
+
+// GetRestackUpstream returns the commit a pending 'so restack --onto' replays
+// the branch's commits from (branch.<name>.socle-restack-upstream), or "" if
+// the branch is not being moved.
+func GetRestackUpstream(branch string) string {
+	val, err := GetGitConfig(BranchConfigKey(branch, "socle-restack-upstream"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(val)
+}
+
+// SetRestackUpstream records the commit the branch's own commits start after,
+// so a restack interrupted by a conflict can resume the move. An empty oid
+// removes it.
+func SetRestackUpstream(branch, oid string) error {
+	key := BranchConfigKey(branch, "socle-restack-upstream")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	if oid == "" {
+		return nil
+	}
+	return SetGitConfig(key, oid)
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// branchConfigPattern matches the per-branch keys socle keeps in the local
+// config (socle-parent, socle-base, socle-pr-number, ...).
+const branchConfigPattern = `^branch\..+\.socle-`
+
+// branchConfigStore holds every branch.<name>.socle-* key of the local and
+// worktree config, read with a single 'git config --get-regexp' instead of
+// one 'git config --get' per branch and key. Writes through this package
+// invalidate it; the config files' sizes and modification times catch
+// changes made by anything else, such as the user running 'git config'
+// between two commands.
+type branchConfigStore struct {
+	mu     sync.Mutex
+	dir    string            // Working directory the cache belongs to
+	files  []configFileStamp // The repository's config and the worktree's config.worktree
+	values map[string]string // Keys as git reports them: variable lower-cased
+}
+
+// configFileStamp is a config file as it was when the cache was loaded. A
+// missing file has a zero size and time.
+type configFileStamp struct {
+	path  string
+	size  int64
+	mtime time.Time
+}
+
+func (f *configFileStamp) stat() {
+	f.size, f.mtime = 0, time.Time{}
+	if info, err := os.Stat(f.path); err == nil {
+		f.size, f.mtime = info.Size(), info.ModTime()
+	}
+}
+
+var branchConfigCache branchConfigStore
+
+// isBranchConfigKey reports whether key is served by the branch config cache.
+func isBranchConfigKey(key string) bool {
+	_, name, ok := ParseBranchConfigKey(key)
+	return ok && strings.HasPrefix(strings.ToLower(name), "socle-")
+}
+
+// normalizeBranchConfigKey lower-cases the section and variable of key the
+// way git does, keeping the branch name verbatim.
+func normalizeBranchConfigKey(key string) string {
+	branch, name, _ := ParseBranchConfigKey(key)
+	return BranchConfigKey(branch, strings.ToLower(name))
+}
+
+// cachedBranchConfig returns the value of a branch.<name>.socle-* key from
+// the local config, loading the cache first if needed.
+func cachedBranchConfig(key string) (string, bool, error) {
+	values, err := branchConfigValues()
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := values[normalizeBranchConfigKey(key)]
+	return value, ok, nil
+}
+
+// branchConfigValues returns all cached branch.<name>.socle-* keys. Callers
+// must not modify the returned map.
+func branchConfigValues() (map[string]string, error) {
+	c := &branchConfigCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dir, _ := os.Getwd()
+	if c.values != nil && c.dir == dir && c.fresh() {
+		return c.values, nil
+	}
+
+	if c.dir != dir || len(c.files) == 0 {
+		output, err := RunGitCommand("rev-parse", "--git-common-dir", "--git-dir")
+		if err != nil {
+			return nil, err
+		}
+		dirs := strings.Split(output, "\n")
+		if len(dirs) != 2 {
+			return nil, fmt.Errorf("unexpected output of git rev-parse: %q", output)
+		}
+		for i := range dirs {
+			if !filepath.IsAbs(dirs[i]) {
+				dirs[i] = filepath.Join(dir, dirs[i])
+			}
+		}
+		c.dir = dir
+		c.files = []configFileStamp{{path: filepath.Join(dirs[0], "config")}, {path: filepath.Join(dirs[1], "config.worktree")}}
+	}
+	// Stat before reading so a write in between is picked up next time.
+	for i := range c.files {
+		c.files[i].stat()
+	}
+	entries, err := getRepoConfigRegexp(branchConfigPattern)
+	if err != nil {
+		c.values = nil
+		return nil, err
+	}
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.Key] = entry.Value // The last value wins, as with 'git config --get'
+	}
+	c.values = values
+	return c.values, nil
+}
+
+// fresh reports whether the config files are unchanged since the cache was
+// loaded. Must be called with mu held.
+func (c *branchConfigStore) fresh() bool {
+	for _, f := range c.files {
+		now := configFileStamp{path: f.path}
+		now.stat()
+		if now.size != f.size || !now.mtime.Equal(f.mtime) {
+			return false
+		}
+	}
+	return true
+}
+
+// invalidateBranchConfigCache drops the cache after socle changed the config,
+// including by deleting a branch, which removes its config section.
+func invalidateBranchConfigCache() {
+	branchConfigCache.mu.Lock()
+	branchConfigCache.values = nil
+	branchConfigCache.mu.Unlock()
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ConfigSourceDefault is the Source of a setting that is not configured in
+// any git config file.
+const ConfigSourceDefault = "default"
+
+// ConfigSetting is the effective value of one known socle setting.
+type ConfigSetting struct {
+	Key    string
+	Value  string // Multi-valued keys are joined with ", "
+	Source string // ConfigSourceEnv, a git config scope, or ConfigSourceDefault when unset
+	Origin string // Variable or file the value came from, if any
+}
+
+// EffectiveSocleConfig returns every known socle setting with the value socle
+// resolves for it (SOCLE_* variable, then git config) and where that value is
+// defined, sorted by key.
+func EffectiveSocleConfig() ([]ConfigSetting, error) {
+	output, err := RunGitCommand("config", "--show-scope", "--show-origin", "--null", "--get-regexp", `^socle\.`)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("failed to read socle config: %w", err)
+		}
+		output = ""
+	}
+
+	settings := make(map[string]*ConfigSetting, len(socleConfigSchema))
+	for lower, spec := range socleConfigSchema {
+		settings[lower] = &ConfigSetting{Key: spec.name, Value: spec.defaultValue, Source: ConfigSourceDefault}
+	}
+
+	// Entries come in git's precedence order, so later ones win.
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		scope, origin := fields[i], strings.TrimPrefix(fields[i+1], "file:")
+		key, value, _ := strings.Cut(fields[i+2], "\n")
+		lower := strings.ToLower(key)
+		setting, ok := settings[lower]
+		if !ok {
+			continue
+		}
+		if socleConfigSchema[lower].multi && setting.Source != ConfigSourceDefault {
+			setting.Value += ", " + value
+		} else {
+			setting.Value = value
+		}
+		setting.Source, setting.Origin = scope, origin
+	}
+
+	for lower, spec := range socleConfigSchema {
+		if value, name, ok := lookupConfigEnv(spec.name); ok {
+			settings[lower].Value, settings[lower].Source, settings[lower].Origin = value, ConfigSourceEnv, "$"+name
+		}
+	}
+
+	result := make([]ConfigSetting, 0, len(settings))
+	for lower, setting := range settings {
+		if socleConfigSchema[lower].secret {
+			setting.Value = redactSecret(setting.Value)
+		}
+		result = append(result, *setting)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result, nil
+}
+
+// redactSecret hides a secret given inline, such as a PEM private key, and
+// keeps a path to a file holding it, which is safe to show.
+func redactSecret(value string) string {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return "(inline key, hidden)"
+	}
+	return value
+}
+
+// SubmitDefaults are the repo/user defaults for 'so submit' flags
+// (socle.submit.*). Flags given on the command line take precedence.
+type SubmitDefaults struct {
+	Draft           bool
+	NoPush          bool
+	AssignReviewers bool
+	DeleteOnMerge   bool   // Delete the head branch once the PR merges
+	StackName       string // How PRs carry the stack's name: off, prefix, label or both
+}
+
+// LoadSubmitDefaults reads socle.submit.* from the environment and git config.
+func LoadSubmitDefaults() (SubmitDefaults, error) {
+	defaults := SubmitDefaults{Draft: true}
+	for key, target := range map[string]*bool{
+		"socle.submit.draft":               &defaults.Draft,
+		"socle.submit.noPush":              &defaults.NoPush,
+		"socle.submit.assignReviewers":     &defaults.AssignReviewers,
+		"socle.submit.deleteBranchOnMerge": &defaults.DeleteOnMerge,
+	} {
+		value, err := GetSocleConfigBool(key, *target)
+		if err != nil {
+			return SubmitDefaults{}, err
+		}
+		*target = value
+	}
+	defaults.StackName = "off"
+	if value, err := GetSocleConfig("socle.submit.stackName"); err == nil {
+		defaults.StackName = strings.ToLower(strings.TrimSpace(value))
+	} else if !errors.Is(err, ErrConfigNotFound) {
+		return SubmitDefaults{}, err
+	}
+	return defaults, nil
+}
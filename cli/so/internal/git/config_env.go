@@ -0,0 +1,129 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConfigSourceEnv is the Source of a setting taken from its SOCLE_*
+// environment variable.
+const ConfigSourceEnv = "env"
+
+// DefaultRemote is the remote socle pushes to and fetches from unless
+// socle.remote (or SOCLE_REMOTE) names another.
+const DefaultRemote = "origin"
+
+// DefaultBaseBranches are the branches stacks are based on unless
+// socle.baseBranches (or SOCLE_BASE_BRANCHES) lists others.
+var DefaultBaseBranches = []string{"main", "master", "develop"}
+
+// lookupConfigEnv returns the value of key's SOCLE_* override and the
+// variable's name. An empty variable counts as unset.
+func lookupConfigEnv(key string) (value, name string, ok bool) {
+	spec, known := lookupConfigSpec(key)
+	if !known || spec.env == "" {
+		return "", "", false
+	}
+	value, set := os.LookupEnv(spec.env)
+	if !set || strings.TrimSpace(value) == "" {
+		return "", spec.env, false
+	}
+	return value, spec.env, true
+}
+
+// GetSocleConfig resolves a socle.* setting: its SOCLE_* environment variable
+// if set, otherwise git config (which already prefers repository over user
+// config). Returns ErrConfigNotFound when neither has a value; callers apply
+// the default and let command-line flags win over the result.
+func GetSocleConfig(key string) (string, error) {
+	if value, _, ok := lookupConfigEnv(key); ok {
+		return value, nil
+	}
+	return GetGitConfig(key)
+}
+
+// GetSocleConfigAll is GetSocleConfig for multi-valued keys. A SOCLE_*
+// variable holds all values separated by commas and replaces the git config
+// values entirely.
+func GetSocleConfigAll(key string) ([]string, error) {
+	if value, _, ok := lookupConfigEnv(key); ok {
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values, nil
+	}
+	return GetGitConfigAll(key)
+}
+
+// GetSocleConfigBool resolves a boolean socle.* setting, returning fallback
+// when it is not set anywhere.
+func GetSocleConfigBool(key string, fallback bool) (bool, error) {
+	val, err := GetSocleConfig(key)
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return fallback, nil
+		}
+		return fallback, err
+	}
+	parsed, errParse := strconv.ParseBool(strings.TrimSpace(val))
+	if errParse != nil {
+		source := key
+		if _, name, ok := lookupConfigEnv(key); ok {
+			source = name
+		}
+		return fallback, fmt.Errorf("invalid value '%s' for %s: expected true or false", val, source)
+	}
+	return parsed, nil
+}
+
+// GetRemoteName returns the remote socle works with: socle.remote, or
+// DefaultRemote when unset.
+func GetRemoteName() string {
+	if val, err := GetSocleConfig("socle.remote"); err == nil && strings.TrimSpace(val) != "" {
+		return strings.TrimSpace(val)
+	}
+	return DefaultRemote
+}
+
+// KnownBaseBranches returns the branches that may serve as the base of a
+// stack: socle.baseBranches, or DefaultBaseBranches when unset. Values may
+// also be comma-separated within one git config entry.
+func KnownBaseBranches() []string {
+	values, err := GetSocleConfigAll("socle.baseBranches")
+	if err != nil {
+		return DefaultBaseBranches
+	}
+	var bases []string
+	for _, value := range values {
+		for _, b := range strings.Split(value, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				bases = append(bases, b)
+			}
+		}
+	}
+	if len(bases) == 0 {
+		return DefaultBaseBranches
+	}
+	return bases
+}
+
+// KnownBaseBranchSet is KnownBaseBranches as a set, plus the frozen bases
+// (see FrozenBase).
+func KnownBaseBranchSet() map[string]bool {
+	set := make(map[string]bool)
+	for _, b := range KnownBaseBranches() {
+		set[b] = true
+	}
+	if frozen, err := GetFrozenBases(); err == nil {
+		for b := range frozen {
+			set[b] = true
+		}
+	}
+	return set
+}
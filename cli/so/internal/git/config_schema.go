@@ -0,0 +1,262 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configValueKind is the type a socle config value must parse as.
+type configValueKind int
+
+const (
+	kindString configValueKind = iota
+	kindBool
+	kindUint
+	kindEnum
+)
+
+// configKeySpec describes one socle config key.
+type configKeySpec struct {
+	name         string // Canonical spelling, e.g. socle.signedPush
+	kind         configValueKind
+	allowed      []string // For kindEnum, lower-case
+	multi        bool     // Multi-valued (set with 'git config --add')
+	defaultValue string   // Shown by 'so config' when unset
+	env          string   // Environment variable overriding git config, if any
+	secret       bool     // The value may be a secret itself; 'so config' hides it
+}
+
+// socleConfigSchema lists every socle.* key socle reads, keyed by the
+// lower-cased name git reports. Unknown socle.* keys are not validated.
+var socleConfigSchema = map[string]configKeySpec{
+	"socle.author":                     {name: "socle.author", kind: kindString, env: "SOCLE_AUTHOR"},
+	"socle.remote":                     {name: "socle.remote", kind: kindString, defaultValue: "origin", env: "SOCLE_REMOTE"},
+	"socle.basebranches":               {name: "socle.baseBranches", kind: kindString, multi: true, defaultValue: "main, master, develop", env: "SOCLE_BASE_BRANCHES"},
+	"socle.nofetch":                    {name: "socle.noFetch", kind: kindBool, defaultValue: "false", env: "SOCLE_NO_FETCH"},
+	"socle.pushoptions":                {name: "socle.pushOptions", kind: kindString, multi: true, env: "SOCLE_PUSH_OPTIONS"},
+	"socle.signedpush":                 {name: "socle.signedPush", kind: kindEnum, allowed: []string{"true", "false", "yes", "no", "on", "off", "1", "0", "if-asked"}, defaultValue: "false", env: "SOCLE_SIGNED_PUSH"},
+	"socle.committrailers":             {name: "socle.commitTrailers", kind: kindBool, defaultValue: "false", env: "SOCLE_COMMIT_TRAILERS"},
+	"socle.sparsesafe":                 {name: "socle.sparseSafe", kind: kindBool, defaultValue: "false", env: "SOCLE_SPARSE_SAFE"},
+	"socle.ignorebranches":             {name: "socle.ignoreBranches", kind: kindString, multi: true, env: "SOCLE_IGNORE_BRANCHES"},
+	"socle.reviewers":                  {name: "socle.reviewers", kind: kindString, multi: true, env: "SOCLE_REVIEWERS"},
+	"socle.reviewerstrategy":           {name: "socle.reviewerStrategy", kind: kindEnum, allowed: []string{"round-robin", "codeowners"}, defaultValue: "round-robin", env: "SOCLE_REVIEWER_STRATEGY"},
+	"socle.rebasemerges":               {name: "socle.rebaseMerges", kind: kindBool, defaultValue: "false", env: "SOCLE_REBASE_MERGES"},
+	"socle.requiredsections":           {name: "socle.requiredSections", kind: kindString, multi: true, env: "SOCLE_REQUIRED_SECTIONS"},
+	"socle.secretscan":                 {name: "socle.secretScan", kind: kindBool, defaultValue: "true", env: "SOCLE_SECRET_SCAN"},
+	"socle.secretscancommand":          {name: "socle.secretScanCommand", kind: kindString, env: "SOCLE_SECRET_SCAN_COMMAND"},
+	"socle.tombstonedays":              {name: "socle.tombstoneDays", kind: kindUint, defaultValue: "14", env: "SOCLE_TOMBSTONE_DAYS"},
+	"socle.commentneighbors":           {name: "socle.commentNeighbors", kind: kindUint, defaultValue: "5", env: "SOCLE_COMMENT_NEIGHBORS"},
+	"socle.commentverbosity":           {name: "socle.commentVerbosity", kind: kindEnum, allowed: []string{"compact", "labeled", "verbose"}, defaultValue: "compact", env: "SOCLE_COMMENT_VERBOSITY"},
+	"socle.hints":                      {name: "socle.hints", kind: kindBool, defaultValue: "true", env: "SOCLE_HINTS"},
+	"socle.auth":                       {name: "socle.auth", kind: kindEnum, allowed: []string{"token", "app"}, defaultValue: "token", env: "SOCLE_AUTH"},
+	"socle.githubapp.id":               {name: "socle.githubApp.id", kind: kindUint, env: "SOCLE_GITHUB_APP_ID"},
+	"socle.githubapp.installationid":   {name: "socle.githubApp.installationId", kind: kindUint, env: "SOCLE_GITHUB_APP_INSTALLATION_ID"},
+	"socle.githubapp.privatekey":       {name: "socle.githubApp.privateKey", kind: kindString, env: "SOCLE_GITHUB_APP_PRIVATE_KEY", secret: true},
+	"socle.githubapp.tokenexchangeurl": {name: "socle.githubApp.tokenExchangeUrl", kind: kindString, env: "SOCLE_GITHUB_APP_EXCHANGE_URL"},
+	"socle.cabundle":                   {name: "socle.caBundle", kind: kindString, env: "SOCLE_CA_BUNDLE"},
+	"socle.insecureskipverify":         {name: "socle.insecureSkipVerify", kind: kindBool, defaultValue: "false", env: "SOCLE_INSECURE_SKIP_VERIFY"},
+	"socle.closetrackingissue":         {name: "socle.closeTrackingIssue", kind: kindBool, defaultValue: "false", env: "SOCLE_CLOSE_TRACKING_ISSUE"},
+	"socle.messagegenerator":           {name: "socle.messageGenerator", kind: kindString, env: "SOCLE_MESSAGE_GENERATOR"},
+	"socle.defaultcommitmessage":       {name: "socle.defaultCommitMessage", kind: kindBool, defaultValue: "true", env: "SOCLE_DEFAULT_COMMIT_MESSAGE"},
+	"socle.metadatascope":              {name: "socle.metadataScope", kind: kindEnum, allowed: []string{"local", "worktree"}, defaultValue: "local"}, // No variable: every command in a worktree must agree on it
+	"socle.log.autofetchinterval":      {name: "socle.log.autofetchInterval", kind: kindUint, defaultValue: "0", env: "SOCLE_LOG_AUTOFETCH_INTERVAL"},
+	"socle.stoponconflict":             {name: "socle.stopOnConflict", kind: kindEnum, allowed: []string{"halt", "skip"}, defaultValue: "halt", env: "SOCLE_STOP_ON_CONFLICT"},
+	"socle.backporttitle":              {name: "socle.backportTitle", kind: kindString, defaultValue: "[{base}] {title} (#{number})", env: "SOCLE_BACKPORT_TITLE"},
+	"socle.changelog":                  {name: "socle.changelog", kind: kindEnum, allowed: []string{"off", "file", "issue", "both"}, defaultValue: "off", env: "SOCLE_CHANGELOG"},
+	"socle.changelogdir":               {name: "socle.changelogDir", kind: kindString, defaultValue: "changelog.d", env: "SOCLE_CHANGELOG_DIR"},
+	"socle.changelogtemplate":          {name: "socle.changelogTemplate", kind: kindString, defaultValue: "- {title} (#{number})", env: "SOCLE_CHANGELOG_TEMPLATE"},
+	"socle.prtitle":                    {name: "socle.prTitle", kind: kindEnum, allowed: []string{"first-commit", "newest-commit", "branch", "pattern"}, defaultValue: "first-commit", env: "SOCLE_PR_TITLE"},
+	"socle.prtitlepattern":             {name: "socle.prTitlePattern", kind: kindString, defaultValue: "{first}", env: "SOCLE_PR_TITLE_PATTERN"},
+	"socle.prtemplatepath":             {name: "socle.prTemplatePath", kind: kindString, env: "SOCLE_PR_TEMPLATE_PATH"},
+	"socle.prstatuscachettl":           {name: "socle.prStatusCacheTTL", kind: kindUint, defaultValue: "60", env: "SOCLE_PR_STATUS_CACHE_TTL"},
+	"socle.submit.draft":               {name: "socle.submit.draft", kind: kindBool, defaultValue: "true", env: "SOCLE_DRAFT"},
+	"socle.submit.nopush":              {name: "socle.submit.noPush", kind: kindBool, defaultValue: "false", env: "SOCLE_NO_PUSH"},
+	"socle.submit.assignreviewers":     {name: "socle.submit.assignReviewers", kind: kindBool, defaultValue: "false", env: "SOCLE_ASSIGN_REVIEWERS"},
+	"socle.submit.deletebranchonmerge": {name: "socle.submit.deleteBranchOnMerge", kind: kindBool, defaultValue: "false", env: "SOCLE_DELETE_BRANCH_ON_MERGE"},
+	"socle.submit.stackname":           {name: "socle.submit.stackName", kind: kindEnum, allowed: []string{"off", "prefix", "label", "both"}, defaultValue: "off", env: "SOCLE_STACK_NAME"},
+}
+
+// ConfigProblem is one invalid config value, with where it was defined.
+type ConfigProblem struct {
+	File  string // Config file path as reported by git ("" if unknown)
+	Line  int    // 1-based line in File, 0 if it could not be located
+	Key   string
+	Value string
+	Issue string
+}
+
+func (p ConfigProblem) String() string {
+	location := p.File
+	if location == "" {
+		location = "git config"
+	}
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, p.Line)
+	}
+	return fmt.Sprintf("%s: %s = '%s': %s", location, p.Key, p.Value, p.Issue)
+}
+
+// ErrInvalidConfig is wrapped by ConfigValidationError.
+var ErrInvalidConfig = errors.New("invalid socle configuration")
+
+// ConfigValidationError aggregates every problem found in one pass.
+type ConfigValidationError struct {
+	Problems []ConfigProblem
+}
+
+func (e *ConfigValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("%s (%d problem(s)):", ErrInvalidConfig, len(e.Problems)))
+	for _, p := range e.Problems {
+		lines = append(lines, "  - "+p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *ConfigValidationError) Unwrap() error { return ErrInvalidConfig }
+
+// ValidateSocleConfig checks all socle.* config values and SOCLE_* overrides
+// against the schema in one git call and returns a *ConfigValidationError listing every problem, or
+// nil if all values are valid. Per-branch metadata (branch.*.socle-*) is written
+// by socle itself and read leniently, so it is not validated here.
+func ValidateSocleConfig() error {
+	var problems []ConfigProblem
+	for _, spec := range socleConfigSchema {
+		if value, name, ok := lookupConfigEnv(spec.name); ok {
+			if issue := checkConfigValue(spec, value); issue != "" {
+				problems = append(problems, ConfigProblem{File: "$" + name, Key: spec.name, Value: value, Issue: issue})
+			}
+		}
+	}
+
+	output, err := RunGitCommand("config", "--show-origin", "--null", "--get-regexp", `^socle\.`)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return fmt.Errorf("failed to read socle config: %w", err)
+		}
+		output = "" // No socle config at all
+	}
+
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		origin := strings.TrimPrefix(fields[i], "file:")
+		key, value, _ := strings.Cut(fields[i+1], "\n")
+
+		spec, ok := lookupConfigSpec(key)
+		if !ok {
+			continue
+		}
+		if issue := checkConfigValue(spec, value); issue != "" {
+			problems = append(problems, ConfigProblem{
+				File:  origin,
+				Line:  locateConfigLine(origin, key, value),
+				Key:   key,
+				Value: value,
+				Issue: issue,
+			})
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		return problems[i].Line < problems[j].Line
+	})
+	return &ConfigValidationError{Problems: problems}
+}
+
+func lookupConfigSpec(key string) (configKeySpec, bool) {
+	spec, ok := socleConfigSchema[strings.ToLower(key)]
+	return spec, ok
+}
+
+// checkConfigValue returns a human-readable issue, or "" if value is valid.
+func checkConfigValue(spec configKeySpec, value string) string {
+	trimmed := strings.TrimSpace(value)
+	switch spec.kind {
+	case kindBool:
+		if _, err := strconv.ParseBool(trimmed); err != nil {
+			return "expected true or false"
+		}
+	case kindUint:
+		if n, err := strconv.Atoi(trimmed); err != nil || n < 0 {
+			return "expected a non-negative integer"
+		}
+	case kindEnum:
+		for _, allowed := range spec.allowed {
+			if strings.EqualFold(trimmed, allowed) {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(spec.allowed, ", ")
+	}
+	return ""
+}
+
+var configSectionRegex = regexp.MustCompile(`^\[\s*([^\s\]"]+)(?:\s+"((?:[^"\\]|\\.)*)")?\s*\]`)
+
+// locateConfigLine finds the line defining key=value in a git config file.
+// Returns 0 if the file cannot be read or the entry is not found.
+func locateConfigLine(file, key, value string) int {
+	if file == "" {
+		return 0
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		if _, err := os.Stat(path); err != nil {
+			if root, errRoot := GetRepoRoot(); errRoot == nil {
+				path = filepath.Join(root, file)
+			}
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = f.Close() }()
+
+	dot := strings.LastIndex(key, ".")
+	wantSection, wantName := strings.ToLower(key[:dot]), strings.ToLower(key[dot+1:])
+	section, firstMatch := "", 0
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if m := configSectionRegex.FindStringSubmatch(line); m != nil {
+			section = strings.ToLower(m[1])
+			if m[2] != "" {
+				section += "." + m[2]
+			}
+			line = strings.TrimSpace(line[len(m[0]):])
+			if line == "" {
+				continue
+			}
+		}
+		if !strings.EqualFold(section, wantSection) {
+			continue
+		}
+		name, rawValue, _ := strings.Cut(line, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), wantName) {
+			continue
+		}
+		if firstMatch == 0 {
+			firstMatch = lineNo
+		}
+		if strings.Trim(strings.TrimSpace(rawValue), `"`) == value {
+			return lineNo
+		}
+	}
+	return firstMatch
+}
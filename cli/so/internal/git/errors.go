@@ -0,0 +1,59 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+)
+
+// Errors callers can check with errors.Is instead of matching git's message
+// text, which changes between git versions and locales. Missing config keys
+// are reported as ErrConfigNotFound. Those with a socleerr kind decide the
+// exit status.
+var (
+	ErrNotTracked          = socleerr.New(socleerr.NotTracked, "branch is not tracked by socle")
+	ErrRemoteNotFound      = errors.New("remote not found")
+	ErrRefNotFound         = errors.New("ref not found")
+	ErrNoCommonAncestor    = errors.New("no common ancestor")
+	ErrLocalChanges        = socleerr.New(socleerr.DirtyWorktree, "local changes would be overwritten")
+	ErrCheckedOutElsewhere = errors.New("branch is checked out in another worktree")
+	ErrStaleLease          = errors.New("remote branch changed since it was last pushed")
+)
+
+// kindError is an error with its own message that also matches one of the
+// sentinels above, so the wording users see is independent of errors.Is.
+type kindError struct {
+	kind error
+	msg  string
+	err  error // Underlying git failure, if any
+}
+
+func (e *kindError) Error() string        { return e.msg }
+func (e *kindError) Is(target error) bool { return target == e.kind }
+
+// Unwrap returns the git failure and the sentinel, so errors.As finds the
+// socleerr kind of the sentinel too.
+func (e *kindError) Unwrap() []error {
+	if e.err == nil {
+		return []error{e.kind}
+	}
+	return []error{e.err, e.kind}
+}
+
+// newKindError returns an error reading like fmt.Sprintf(format, args...)
+// that matches kind with errors.Is.
+func newKindError(kind error, cause error, format string, args ...any) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...), err: cause}
+}
+
+// exitCode returns the exit status of the git process behind err, or -1 if
+// err did not come from a git process exiting.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
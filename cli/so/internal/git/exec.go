@@ -1,17 +1,103 @@
 package git
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/profile"
 )
 
+// captureCommand returns a git command whose output socle parses rather than
+// shows. It runs in the C locale so messages and exit behaviour do not
+// depend on the user's language.
+//
+// Read-only plumbing also runs with GIT_CONFIG_NOSYSTEM, so settings in the
+// system config (/etc/gitconfig) cannot change what socle reads from refs and
+// objects. Other commands keep the system config: it can hold credential
+// helpers, hooks and signing settings that fetches, pushes and commits need.
+//
+// Terminal prompts are disabled except for the verbs that talk to a remote.
+// No other command has a reason to ask for credentials, so one that tries
+// (e.g. a lazy fetch in a partial clone) fails instead of stopping socle
+// midway. Fetches and pushes keep them: git asks on /dev/tty, not on the
+// captured output, so users of HTTPS remotes can still authenticate.
+//
+// Plumbing commands that never prompt run outside the terminal's process
+// group, so Ctrl+C reaches socle alone: a ref update that already started
+// finishes, and socle stops before the next one.
+func captureCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	verb := profile.GitVerb(args)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	if readOnlyPlumbing[verb] {
+		cmd.Env = append(cmd.Env, "GIT_CONFIG_NOSYSTEM=1")
+	}
+	if !networkVerbs[verb] {
+		cmd.Env = append(cmd.Env, "GIT_TERMINAL_PROMPT=0")
+	}
+	if detachable[verb] {
+		detachFromTerminal(cmd)
+	}
+	return cmd
+}
+
+// readOnlyPlumbing holds the git verbs that only read refs and objects.
+// 'config' is left out, as socle settings may live in the system config.
+var readOnlyPlumbing = map[string]bool{
+	"cat-file":         true,
+	"check-ref-format": true,
+	"for-each-ref":     true,
+	"merge-base":       true,
+	"rev-list":         true,
+	"rev-parse":        true,
+	"show-ref":         true,
+}
+
+// networkVerbs holds the git verbs that talk to a remote and may need to ask
+// for credentials.
+var networkVerbs = map[string]bool{
+	"fetch":     true,
+	"ls-remote": true,
+	"pull":      true,
+	"push":      true,
+}
+
+// detachable holds the git verbs safe to run outside the terminal's process
+// group: plumbing that neither runs hooks nor signs nor talks to a remote.
+// Anything else may start a child that reads /dev/tty, such as an SSH
+// signing passphrase prompt or an interactive hook, which would be stopped
+// with SIGTTIN from a background group and hang.
+var detachable = map[string]bool{
+	"cat-file":         true,
+	"check-ref-format": true,
+	"config":           true,
+	"for-each-ref":     true,
+	"hash-object":      true,
+	"merge-base":       true,
+	"mktree":           true,
+	"rev-list":         true,
+	"rev-parse":        true,
+	"show-ref":         true,
+	"symbolic-ref":     true,
+	"update-ref":       true,
+}
+
 func RunGitCommand(args ...string) (string, error) {
+	return runGitCommand(nil, args...)
+}
 
-	cmd := exec.Command("git", args...)
+// runGitCommand is RunGitCommand feeding stdin, unless nil, to git.
+func runGitCommand(stdin io.Reader, args ...string) (string, error) {
+	defer profile.Start(profile.CategoryGit, profile.GitVerb(args))()
+
+	cmd := captureCommand(args...)
 	var stdout, stderr bytes.Buffer
+	cmd.Stdin = stdin
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
@@ -31,7 +117,53 @@ func RunGitCommand(args ...string) (string, error) {
 	return strings.TrimSpace(stdout.String()), nil
 }
 
+// streamGitCommand runs git and passes each line of its output to fn as it
+// arrives, so long listings are never held in memory at once. When fn returns
+// false, git is stopped and the rest of the output is skipped.
+func streamGitCommand(fn func(line string) bool, args ...string) error {
+	defer profile.Start(profile.CategoryGit, profile.GitVerb(args))()
+
+	cmd := captureCommand(args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("git command execution failed: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git command execution failed: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if !fn(scanner.Text()) {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return nil
+		}
+	}
+	if errScan := scanner.Err(); errScan != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("failed to read git output: %w", errScan)
+	}
+
+	err = cmd.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		stderrStr := strings.TrimSpace(stderr.String())
+		errMsg := fmt.Sprintf("git command failed (%s)", exitErr.Error())
+		if stderrStr != "" {
+			errMsg = fmt.Sprintf("%s\nstderr: %s", errMsg, stderrStr)
+		}
+		return fmt.Errorf("%s: %w", errMsg, exitErr)
+	} else if err != nil {
+		return fmt.Errorf("git command execution failed: %w", err)
+	}
+	return nil
+}
+
 func RunGitCommandInteractive(args ...string) error {
+	defer profile.Start(profile.CategoryGit, profile.GitVerb(args))()
 	cmd := exec.Command("git", args...) // Don't add --no-pager here
 
 	// Connect standard streams directly
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package git
+
+import "os/exec"
+
+// detachFromTerminal leaves cmd as it is; only Unix has process groups to
+// keep it out of the terminal's Ctrl+C.
+func detachFromTerminal(cmd *exec.Cmd) {}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A frozen base is a stack base pinned to a tag or commit, e.g. a hotfix stack
+// cut from v2.3.0. socle keeps a local branch frozen/<ref> at that commit so
+// the rest of the stack machinery can treat it like any base branch. The
+// branch carries branch.<name>.socle-frozen (the ref it was cut from) and
+// branch.<name>.socle-target (the long-lived branch PRs on it target). It is
+// never fetched, fast-forwarded or pushed.
+
+// FrozenBase is a base pinned to Ref, whose PRs target Target.
+type FrozenBase struct {
+	Branch string
+	Ref    string
+	Target string
+}
+
+// FrozenBaseBranchName is the local branch holding the frozen base for ref.
+func FrozenBaseBranchName(ref string) string {
+	return "frozen/" + ref
+}
+
+// GetFrozenBases returns every frozen base keyed by its branch.
+func GetFrozenBases() (map[string]FrozenBase, error) {
+	entries, err := getRepoConfigRegexp(`^branch\..+\.socle-frozen$`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frozen bases: %w", err)
+	}
+	bases := make(map[string]FrozenBase, len(entries))
+	for _, entry := range entries {
+		branch, name, ok := ParseBranchConfigKey(entry.Key)
+		if !ok || name != "socle-frozen" {
+			continue
+		}
+		target, _ := GetGitConfig(BranchConfigKey(branch, "socle-target"))
+		bases[branch] = FrozenBase{Branch: branch, Ref: entry.Value, Target: target}
+	}
+	return bases, nil
+}
+
+// GetFrozenBase reports whether branch is a frozen base and returns it.
+func GetFrozenBase(branch string) (FrozenBase, bool) {
+	ref, err := GetGitConfig(BranchConfigKey(branch, "socle-frozen"))
+	if err != nil || ref == "" {
+		return FrozenBase{}, false
+	}
+	target, _ := GetGitConfig(BranchConfigKey(branch, "socle-target"))
+	return FrozenBase{Branch: branch, Ref: ref, Target: target}, true
+}
+
+// CreateFrozenBase creates (or reuses) the frozen base for ref, whose PRs
+// target target. An existing frozen base must be at the same commit.
+func CreateFrozenBase(ref, target string) (FrozenBase, error) {
+	oid, err := RunGitCommand("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return FrozenBase{}, newKindError(ErrRefNotFound, err, "'%s' is not a tag or commit", ref)
+	}
+	branch := FrozenBaseBranchName(ref)
+	if err := IsValidBranchName(branch); err != nil {
+		return FrozenBase{}, fmt.Errorf("cannot name a base after '%s': %w", ref, err)
+	}
+
+	exists, err := BranchExists(branch)
+	if err != nil {
+		return FrozenBase{}, err
+	}
+	if exists {
+		existing, frozen := GetFrozenBase(branch)
+		tip, errTip := GetCurrentBranchCommit(branch)
+		if !frozen || errTip != nil || tip != oid {
+			return FrozenBase{}, fmt.Errorf("branch '%s' already exists and is not the frozen base of '%s'", branch, ref)
+		}
+		if existing.Target != target {
+			return FrozenBase{}, fmt.Errorf("'%s' is already frozen with PRs targeting '%s', not '%s'", ref, existing.Target, target)
+		}
+		return existing, nil
+	}
+
+	if err := CreateBranch(branch, oid); err != nil {
+		return FrozenBase{}, err
+	}
+	if err := SetGitConfig(BranchConfigKey(branch, "socle-frozen"), ref); err != nil {
+		return FrozenBase{}, fmt.Errorf("failed to mark '%s' as frozen: %w", branch, err)
+	}
+	if err := SetGitConfig(BranchConfigKey(branch, "socle-target"), target); err != nil {
+		return FrozenBase{}, fmt.Errorf("failed to set the PR target of '%s': %w", branch, err)
+	}
+	return FrozenBase{Branch: branch, Ref: ref, Target: target}, nil
+}
+
+// PRBaseFor returns the branch a PR from a branch on parent targets: the
+// target of a frozen base, otherwise parent itself.
+func PRBaseFor(parent string) string {
+	if base, ok := GetFrozenBase(parent); ok && base.Target != "" {
+		return base.Target
+	}
+	return parent
+}
+
+// PRBaseOf returns the branch the PR of branch, stacked on parent, targets:
+// its override (see GetPRBaseOverride) if it has one, else PRBaseFor(parent).
+func PRBaseOf(branch, parent string) string {
+	if base := GetPRBaseOverride(branch); base != "" {
+		return base
+	}
+	return PRBaseFor(parent)
+}
+
+// FrozenBaseMoved reports whether the frozen base branch no longer points at
+// the commit of its ref, e.g. after someone committed on it.
+func FrozenBaseMoved(base FrozenBase) (bool, error) {
+	want, err := RunGitCommand("rev-parse", "--verify", "--quiet", base.Ref+"^{commit}")
+	if err != nil {
+		return false, newKindError(ErrRefNotFound, err, "'%s' no longer exists", base.Ref)
+	}
+	have, err := GetCurrentBranchCommit(base.Branch)
+	if err != nil {
+		if errors.Is(err, ErrRefNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	return strings.TrimSpace(have) != strings.TrimSpace(want), nil
+}
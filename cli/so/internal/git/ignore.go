@@ -0,0 +1,150 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// BranchIgnoreFile lists branch patterns socle leaves alone, one per line,
+// at the top of the work tree. Blank lines and lines starting with # are
+// skipped.
+const BranchIgnoreFile = ".socle-ignore"
+
+// BranchIgnoreRules are the branch patterns from .socle-ignore and
+// socle.ignoreBranches. Matching branches are never tracked, offered as
+// parents or listed as stacks. Base branches are exempt.
+type BranchIgnoreRules struct {
+	patterns []string
+	compiled []*regexp.Regexp
+	bases    map[string]bool
+}
+
+// LoadBranchIgnoreRules reads .socle-ignore and socle.ignoreBranches (or
+// SOCLE_IGNORE_BRANCHES). A missing file means no rules from it.
+func LoadBranchIgnoreRules() (*BranchIgnoreRules, error) {
+	var patterns []string
+
+	root, err := GetRepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repository root: %w", err)
+	}
+	fromFile, err := readBranchIgnoreFile(filepath.Join(root, BranchIgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+	patterns = append(patterns, fromFile...)
+
+	values, err := GetSocleConfigAll("socle.ignoreBranches")
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		for _, p := range strings.Split(value, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				patterns = append(patterns, p)
+			}
+		}
+	}
+
+	rules := &BranchIgnoreRules{bases: KnownBaseBranchSet()}
+	for _, p := range patterns {
+		re, err := compileBranchGlob(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid branch ignore pattern '%s': %w", p, err)
+		}
+		rules.patterns = append(rules.patterns, p)
+		rules.compiled = append(rules.compiled, re)
+	}
+	return rules, nil
+}
+
+func readBranchIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", BranchIgnoreFile, err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", BranchIgnoreFile, err)
+	}
+	return patterns, nil
+}
+
+// compileBranchGlob turns a pattern into a regexp the way 'git branch
+// --list' matches: * and ? also match '/', so dependabot/* covers
+// dependabot/npm/lodash. [...] is a character class.
+func compileBranchGlob(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated '['")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// Match returns the first pattern branch matches.
+func (r *BranchIgnoreRules) Match(branch string) (string, bool) {
+	if r == nil || r.bases[branch] {
+		return "", false
+	}
+	for i, re := range r.compiled {
+		if re.MatchString(branch) {
+			return r.patterns[i], true
+		}
+	}
+	return "", false
+}
+
+// Ignored reports whether socle should leave branch alone.
+func (r *BranchIgnoreRules) Ignored(branch string) bool {
+	_, ok := r.Match(branch)
+	return ok
+}
+
+// Filter returns branches without the ignored ones.
+func (r *BranchIgnoreRules) Filter(branches []string) []string {
+	kept := make([]string, 0, len(branches))
+	for _, b := range branches {
+		if !r.Ignored(b) {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
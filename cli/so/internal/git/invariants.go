@@ -0,0 +1,212 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SocleMetadata is a point-in-time copy of every branch.*.socle-* key in the
+// local git config, mapping full key -> values (in config order).
+type SocleMetadata map[string][]string
+
+// ReadSocleMetadata loads all socle branch metadata with a single git call.
+func ReadSocleMetadata() (SocleMetadata, error) {
+	entries, err := getRepoConfigRegexp(`^branch\..+\.socle-`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read socle metadata: %w", err)
+	}
+
+	meta := SocleMetadata{}
+	for _, entry := range entries {
+		meta[entry.Key] = append(meta[entry.Key], entry.Value)
+	}
+	return meta, nil
+}
+
+// RestoreSocleMetadata rewrites the local socle metadata so it matches snapshot
+// exactly. Keys added since the snapshot are removed, changed keys are reset.
+func RestoreSocleMetadata(snapshot SocleMetadata) error {
+	current, err := ReadSocleMetadata()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]struct{}, len(current)+len(snapshot))
+	for k := range current {
+		keys[k] = struct{}{}
+	}
+	for k := range snapshot {
+		keys[k] = struct{}{}
+	}
+
+	for key := range keys {
+		want, had := snapshot[key], current[key]
+		if strings.Join(want, "\n") == strings.Join(had, "\n") {
+			continue
+		}
+		if len(had) > 0 {
+			if err := UnsetGitConfig(key); err != nil {
+				return fmt.Errorf("failed to reset '%s': %w", key, err)
+			}
+		}
+		for _, value := range want {
+			if err := SetGitConfig(key, value); err != nil {
+				return fmt.Errorf("failed to restore '%s': %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// StackViolation describes one broken invariant in the socle metadata.
+type StackViolation struct {
+	Branch  string
+	Problem string
+}
+
+func (v StackViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Branch, v.Problem)
+}
+
+// CheckStackInvariants verifies that the tracked metadata describes a valid
+// set of stacks:
+//   - every tracked branch exists locally and has a socle-base,
+//   - every parent and base exists locally,
+//   - a branch's base matches the base of its tracked parent,
+//   - following parents never loops back (no cycles),
+//   - only base branches have more than one tracked child.
+//
+// Violations are returned sorted by branch for stable output.
+func CheckStackInvariants() ([]StackViolation, error) {
+	meta, err := ReadSocleMetadata()
+	if err != nil {
+		return nil, err
+	}
+	var mentioned []string
+	for key, values := range meta {
+		if branch, _, ok := ParseBranchConfigKey(key); ok {
+			mentioned = append(mentioned, branch)
+		}
+		mentioned = append(mentioned, values...)
+	}
+	exists, err := ExistingLocalBranches(mentioned)
+	if err != nil {
+		return nil, err
+	}
+	return checkStackInvariants(meta, exists), nil
+}
+
+func checkStackInvariants(meta SocleMetadata, exists map[string]bool) []StackViolation {
+	parents := make(map[string]string)
+	bases := make(map[string]string)
+	for key, values := range meta {
+		if len(values) == 0 {
+			continue
+		}
+		value := values[len(values)-1]
+		if branch, ok := metadataKeyBranch(key, "socle-parent"); ok {
+			parents[branch] = value
+		} else if branch, ok := metadataKeyBranch(key, "socle-base"); ok {
+			bases[branch] = value
+		}
+	}
+
+	var violations []StackViolation
+	add := func(branch, format string, args ...any) {
+		violations = append(violations, StackViolation{Branch: branch, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	for branch, parent := range parents {
+		if !exists[branch] {
+			add(branch, "tracked branch no longer exists locally")
+			continue
+		}
+		if parent == branch {
+			add(branch, "branch is its own parent")
+			continue
+		}
+		if !exists[parent] {
+			add(branch, "parent '%s' does not exist locally", parent)
+		}
+		base, hasBase := bases[branch]
+		if !hasBase || base == "" {
+			add(branch, "missing socle-base")
+		} else if !exists[base] {
+			add(branch, "base '%s' does not exist locally", base)
+		} else if parentBase, parentTracked := bases[parent]; parentTracked && parentBase != base {
+			add(branch, "base '%s' differs from parent '%s' base '%s'", base, parent, parentBase)
+		}
+
+		visited := map[string]bool{branch: true}
+		for walker := parent; ; {
+			next, tracked := parents[walker]
+			if !tracked {
+				break
+			}
+			if visited[walker] {
+				add(branch, "cycle detected in parent chain at '%s'", walker)
+				break
+			}
+			visited[walker] = true
+			walker = next
+		}
+	}
+
+	for branch := range bases {
+		if _, tracked := parents[branch]; !tracked {
+			add(branch, "has socle-base but no socle-parent")
+		}
+	}
+
+	isBase := make(map[string]bool, len(bases))
+	for _, base := range bases {
+		isBase[base] = true
+	}
+	children := BuildChildMap(parents)
+	for parent, kids := range children {
+		if len(kids) > 1 && !IsKnownBaseBranch(parent) && !isBase[parent] {
+			sort.Strings(kids)
+			add(parent, "non-base branch has multiple children %v", kids)
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].Branch != violations[j].Branch {
+			return violations[i].Branch < violations[j].Branch
+		}
+		return violations[i].Problem < violations[j].Problem
+	})
+	return violations
+}
+
+// metadataKeyBranch extracts the branch name from "branch.<name>.<suffix>".
+func metadataKeyBranch(key, suffix string) (string, bool) {
+	branch, name, ok := ParseBranchConfigKey(key)
+	if !ok || name != suffix {
+		return "", false
+	}
+	return branch, true
+}
+
+// UnsetSocleBranchConfig removes every socle-* key of branch, including keys
+// added by later socle versions, and returns the names it removed.
+func UnsetSocleBranchConfig(branch string) ([]string, error) {
+	meta, err := ReadSocleMetadata()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for key := range meta {
+		if b, name, ok := ParseBranchConfigKey(key); ok && b == branch && strings.HasPrefix(name, "socle-") {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		if err := UnsetGitConfig(BranchConfigKey(branch, name)); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", BranchConfigKey(branch, name), err)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MaintenanceCheck is the state of one git setting that speeds up the
+// ancestry and merge-base queries socle runs for every stack operation.
+type MaintenanceCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// CheckMaintenance reports whether the commit-graph is enabled, written on
+// fetch and present, and whether background `git maintenance` covers the repo.
+func CheckMaintenance() ([]MaintenanceCheck, error) {
+	var checks []MaintenanceCheck
+
+	commitGraph, err := configBool("core.commitGraph", true)
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, MaintenanceCheck{
+		Name:   "core.commitGraph",
+		OK:     commitGraph,
+		Detail: pick(commitGraph, "enabled", "disabled; git ignores the commit-graph"),
+	})
+
+	writeOnFetch, err := configBool("fetch.writeCommitGraph", false)
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, MaintenanceCheck{
+		Name:   "fetch.writeCommitGraph",
+		OK:     writeOnFetch,
+		Detail: pick(writeOnFetch, "enabled", "disabled; fetched commits are not added to the commit-graph"),
+	})
+
+	present, err := hasCommitGraph()
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, MaintenanceCheck{
+		Name:   "commit-graph file",
+		OK:     present,
+		Detail: pick(present, "present", "missing; ancestry checks walk every commit object"),
+	})
+
+	registered, err := isMaintenanceRegistered()
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, MaintenanceCheck{
+		Name:   "git maintenance",
+		OK:     registered,
+		Detail: pick(registered, "registered for this repository", "not registered; the commit-graph goes stale over time"),
+	})
+	return checks, nil
+}
+
+// ApplyMaintenance enables the commit-graph settings in the local config,
+// writes the commit-graph now and registers the repository for background
+// maintenance. With schedule, `git maintenance start` also installs the
+// scheduler entry; if that fails the repository is still registered and the
+// scheduling error is returned wrapped in ErrMaintenanceNotScheduled.
+func ApplyMaintenance(schedule bool) error {
+	for _, key := range []string{"core.commitGraph", "fetch.writeCommitGraph"} {
+		if _, err := RunGitCommand("config", "--local", key, "true"); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	if _, err := RunGitCommand("commit-graph", "write", "--reachable", "--changed-paths"); err != nil {
+		return fmt.Errorf("failed to write the commit-graph: %w", err)
+	}
+
+	if schedule {
+		_, errStart := RunGitCommand("maintenance", "start")
+		if errStart == nil {
+			return nil
+		}
+		if _, err := RunGitCommand("maintenance", "register"); err != nil {
+			return fmt.Errorf("failed to register for git maintenance: %w", err)
+		}
+		return fmt.Errorf("%w: %v", ErrMaintenanceNotScheduled, errStart)
+	}
+	if _, err := RunGitCommand("maintenance", "register"); err != nil {
+		return fmt.Errorf("failed to register for git maintenance: %w", err)
+	}
+	return nil
+}
+
+// ErrMaintenanceNotScheduled means the repository was registered for
+// maintenance but no scheduler (cron, launchd, systemd, schtasks) was set up.
+var ErrMaintenanceNotScheduled = errors.New("git maintenance registered but not scheduled")
+
+func configBool(key string, def bool) (bool, error) {
+	val, err := RunGitCommand("config", "--type=bool", "--get", key)
+	if err != nil {
+		if _, errGet := GetGitConfig(key); errors.Is(errGet, ErrConfigNotFound) {
+			return def, nil
+		}
+		return false, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(val))
+	if err != nil {
+		return false, fmt.Errorf("invalid %s '%s': %w", key, val, err)
+	}
+	return b, nil
+}
+
+func hasCommitGraph() (bool, error) {
+	objects, err := RunGitCommand("rev-parse", "--path-format=absolute", "--git-path", "objects/info")
+	if err != nil {
+		return false, fmt.Errorf("failed to locate the object directory: %w", err)
+	}
+	for _, name := range []string{"commit-graph", filepath.Join("commit-graphs", "commit-graph-chain")} {
+		if _, err := os.Stat(filepath.Join(objects, name)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isMaintenanceRegistered() (bool, error) {
+	root, err := GetRepoRoot()
+	if err != nil {
+		return false, err
+	}
+	repos, err := GetGitConfigAll("maintenance.repo")
+	if err != nil {
+		return false, err
+	}
+	for _, repo := range repos {
+		if sameDir(repo, root) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func sameDir(a, b string) bool {
+	if a == b {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+func pick(ok bool, good, bad string) string {
+	if ok {
+		return good
+	}
+	return bad
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Where socle keeps its per-branch metadata, set with socle.metadataScope.
+const (
+	MetadataScopeLocal    = "local"    // The repository's config, shared by every worktree
+	MetadataScopeWorktree = "worktree" // The current worktree's config.worktree
+)
+
+// MetadataScope returns the scope socle writes branch metadata to. Metadata
+// is read from both scopes regardless; a worktree's own value wins.
+func MetadataScope() string {
+	value, err := GetSocleConfig("socle.metadataScope")
+	if err != nil {
+		return MetadataScopeLocal
+	}
+	if strings.EqualFold(strings.TrimSpace(value), MetadataScopeWorktree) {
+		return MetadataScopeWorktree
+	}
+	return MetadataScopeLocal
+}
+
+// WorktreeConfigEnabled reports whether extensions.worktreeConfig is on,
+// which git needs before a worktree can have config of its own.
+func WorktreeConfigEnabled() bool {
+	value, err := RunGitCommand("config", "--type=bool", "--get", "extensions.worktreeConfig")
+	return err == nil && value == "true"
+}
+
+// metadataScopeOption returns the 'git config' option that writes branch
+// metadata to the configured scope.
+func metadataScopeOption() (string, error) {
+	if MetadataScope() != MetadataScopeWorktree {
+		return "--local", nil
+	}
+	if !WorktreeConfigEnabled() {
+		return "", fmt.Errorf("socle.metadataScope is 'worktree' but extensions.worktreeConfig is off; enable it with 'git config extensions.worktreeConfig true'")
+	}
+	return "--worktree", nil
+}
+
+// DescribeMetadataScope says where branch metadata is written, for 'so config'.
+func DescribeMetadataScope() string {
+	if MetadataScope() != MetadataScopeWorktree {
+		return "the repository config (.git/config), shared by all worktrees"
+	}
+	if !WorktreeConfigEnabled() {
+		return "this worktree's config, but extensions.worktreeConfig is off, so writes fail until it is enabled"
+	}
+	return "this worktree's config (config.worktree); other worktrees track their own stacks"
+}
+
+// worktreeConfigFiles returns the config.worktree of every worktree that has
+// one, the main worktree's first.
+func worktreeConfigFiles() ([]string, error) {
+	common, err := RunGitCommand("rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the repository's git directory: %w", err)
+	}
+	linked, err := filepath.Glob(filepath.Join(common, "worktrees", "*", "config.worktree"))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range append([]string{filepath.Join(common, "config.worktree")}, linked...) {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// moveWorktreeBranchMetadata renames the branch.<from> section to
+// branch.<to>, or removes it when to is empty, in every worktree's
+// config.worktree. 'git branch -m' and '-D' only touch the shared config, so
+// metadata written with socle.metadataScope=worktree would otherwise stay
+// behind under the old name.
+func moveWorktreeBranchMetadata(from, to string) error {
+	files, err := worktreeConfigFiles()
+	if err != nil {
+		return err
+	}
+	defer invalidateBranchConfigCache()
+	for _, file := range files {
+		keys, err := RunGitCommand("config", "--file", file, "--name-only", "--get-regexp", `^branch\.`)
+		if err != nil {
+			if exitCode(err) == 1 {
+				continue // No branch sections
+			}
+			return fmt.Errorf("failed to read '%s': %w", file, err)
+		}
+		found := false
+		for _, key := range strings.Split(keys, "\n") {
+			if branch, _, ok := ParseBranchConfigKey(key); ok && branch == from {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		args := []string{"config", "--file", file, "--remove-section", "branch." + from}
+		if to != "" {
+			args = []string{"config", "--file", file, "--rename-section", "branch." + from, "branch." + to}
+		}
+		if _, err := RunGitCommand(args...); err != nil {
+			return fmt.Errorf("failed to update the metadata of '%s' in '%s': %w", from, file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// MetaVersionKey records which version of socle's metadata layout a
+// repository uses. Repositories from before it existed are version 0.
+const MetaVersionKey = "socle.metaVersion"
+
+// MetadataMigration upgrades a repository's metadata from Version-1 to
+// Version. Plan inspects the repository and returns the changes still
+// needed, so running a migration twice changes nothing the second time.
+type MetadataMigration struct {
+	Version int
+	Summary string
+	Plan    func() ([]MetadataChange, error)
+}
+
+// MetadataChange is one pending edit of a migration.
+type MetadataChange struct {
+	Description string
+	apply       func() error
+}
+
+// metadataMigrations lists every migration in version order, starting at
+// version 1. Append new ones at the end; never renumber or remove a
+// released one.
+var metadataMigrations []MetadataMigration
+
+// CurrentMetaVersion is the metadata version this socle writes, 0 while
+// no migration exists.
+func CurrentMetaVersion() int {
+	if len(metadataMigrations) == 0 {
+		return 0
+	}
+	return metadataMigrations[len(metadataMigrations)-1].Version
+}
+
+// RepoMetaVersion returns the metadata version stored in the repository.
+func RepoMetaVersion() (int, error) {
+	val, err := GetGitConfig(MetaVersionKey)
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid %s '%s': expected a non-negative integer", MetaVersionKey, val)
+	}
+	return version, nil
+}
+
+// PendingMigration is a migration the repository has not had yet, with the
+// changes it would make. Changes may be empty when there is nothing to
+// move.
+type PendingMigration struct {
+	MetadataMigration
+	Changes []MetadataChange
+}
+
+// PlanMigrations returns the migrations newer than the repository's
+// metadata version, without changing anything. It fails when the
+// repository was written by a newer socle than this one.
+func PlanMigrations() ([]PendingMigration, error) {
+	version, err := RepoMetaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentMetaVersion() {
+		return nil, fmt.Errorf("repository metadata is version %d, but this socle only understands up to version %d; please upgrade socle", version, CurrentMetaVersion())
+	}
+
+	var pending []PendingMigration
+	for _, m := range metadataMigrations {
+		if m.Version <= version {
+			continue
+		}
+		changes, err := m.Plan()
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan metadata migration %d: %w", m.Version, err)
+		}
+		pending = append(pending, PendingMigration{MetadataMigration: m, Changes: changes})
+	}
+	return pending, nil
+}
+
+// ApplyMigrations runs the pending migrations in order, recording the
+// version after each one so an interrupted upgrade resumes where it stopped.
+// Nothing is written until a migration has something to change, so a
+// repository with nothing to upgrade is left untouched.
+func ApplyMigrations(pending []PendingMigration) error {
+	changed := false
+	for _, m := range pending {
+		if len(m.Changes) == 0 && !changed {
+			continue
+		}
+		changed = true
+		for _, change := range m.Changes {
+			slog.Debug("Applying metadata change", "version", m.Version, "change", change.Description)
+			if err := change.apply(); err != nil {
+				return fmt.Errorf("metadata migration %d failed (%s): %w", m.Version, change.Description, err)
+			}
+		}
+		if err := UnsetGitConfig(MetaVersionKey); err != nil {
+			return err
+		}
+		if err := SetGitConfig(MetaVersionKey, strconv.Itoa(m.Version)); err != nil {
+			return fmt.Errorf("failed to record metadata version %d: %w", m.Version, err)
+		}
+	}
+	return nil
+}
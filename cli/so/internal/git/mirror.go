@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A mirror is a copy of a stack branch on a second base, e.g. a hotfix stack
+// on main mirrored onto release/1.2. The source lists its mirrors in
+// branch.<source>.socle-mirror as "<base>:<mirror>" values (':' cannot occur
+// in branch names), and each mirror names its source in
+// branch.<mirror>.socle-mirror-of. Mirrors are otherwise ordinary tracked
+// branches, stacked on each other and based on the second base.
+
+// MirrorBranchName is the default name of the mirror of branch on base, the
+// same name 'so backport' gives a backport branch.
+func MirrorBranchName(base, branch string) string {
+	return fmt.Sprintf("backport/%s/%s", base, branch)
+}
+
+// GetMirrors returns the mirrors of branch keyed by their base.
+func GetMirrors(branch string) (map[string]string, error) {
+	values, err := GetGitConfigAll(BranchConfigKey(branch, "socle-mirror"))
+	if err != nil {
+		return nil, err
+	}
+	mirrors := make(map[string]string, len(values))
+	for _, v := range values {
+		if base, mirror, ok := strings.Cut(strings.TrimSpace(v), ":"); ok && base != "" && mirror != "" {
+			mirrors[base] = mirror
+		}
+	}
+	return mirrors, nil
+}
+
+// SetMirror records mirror as the copy of branch on base, replacing an
+// earlier mirror on the same base.
+func SetMirror(branch, base, mirror string) error {
+	mirrors, err := GetMirrors(branch)
+	if err != nil {
+		return err
+	}
+	mirrors[base] = mirror
+	key := BranchConfigKey(branch, "socle-mirror")
+	if err := UnsetGitConfig(key); err != nil {
+		return err
+	}
+	bases := make([]string, 0, len(mirrors))
+	for b := range mirrors {
+		bases = append(bases, b)
+	}
+	sort.Strings(bases)
+	for _, b := range bases {
+		if err := SetGitConfig(key, b+":"+mirrors[b]); err != nil {
+			return err
+		}
+	}
+	if err := UnsetGitConfig(BranchConfigKey(mirror, "socle-mirror-of")); err != nil {
+		return err
+	}
+	return SetGitConfig(BranchConfigKey(mirror, "socle-mirror-of"), branch)
+}
+
+// GetMirrorSource returns the branch mirror is a copy of, or "" if it is not
+// a mirror.
+func GetMirrorSource(mirror string) (string, error) {
+	values, err := GetGitConfigAll(BranchConfigKey(mirror, "socle-mirror-of"))
+	if err != nil || len(values) == 0 {
+		return "", err
+	}
+	return strings.TrimSpace(values[len(values)-1]), nil
+}
+
+// CommitsMissingFrom returns the commits of parent..branch whose change has no
+// equivalent (same patch id) in target, oldest first. Cherry-picked copies
+// match their originals, so this is what a mirror still lacks, or, with the
+// roles swapped, what was only changed on the mirror.
+func CommitsMissingFrom(target, branch, parent string) ([]Commit, error) {
+	output, err := RunGitCommand("cherry", "-v", target, branch, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare '%s' with '%s': %w", branch, target, err)
+	}
+	var commits []Commit
+	for _, line := range strings.Split(output, "\n") {
+		rest, ok := strings.CutPrefix(line, "+ ")
+		if !ok {
+			continue // "- " marks a commit target already has
+		}
+		oid, subject, _ := strings.Cut(rest, " ")
+		commits = append(commits, Commit{OID: oid, Subject: subject})
+	}
+	return commits, nil
+}
+
+// CherryPickCommits applies commits onto the current branch in order with
+// `git cherry-pick -x`. Returns ErrCherryPickConflict if git paused for the
+// user to resolve.
+func CherryPickCommits(commits []Commit) error {
+	args := []string{"cherry-pick", "-x"}
+	for _, c := range commits {
+		args = append(args, c.OID)
+	}
+	_, err := RunGitCommand(args...)
+	if err == nil {
+		return nil
+	}
+	if IsCherryPickInProgress() {
+		return ErrCherryPickConflict
+	}
+	return fmt.Errorf("git cherry-pick failed: %w", err)
+}
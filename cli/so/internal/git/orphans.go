@@ -0,0 +1,52 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OrphanedMetadata is tracking metadata left behind by a branch deleted
+// outside socle: children whose parent is gone, and metadata of branches
+// that no longer exist.
+type OrphanedMetadata struct {
+	// Orphans maps each tracked branch whose parent no longer exists to
+	// that missing parent.
+	Orphans map[string]string
+	// Stale lists tracked branches that no longer exist locally, sorted.
+	Stale []string
+}
+
+// Empty reports whether there is nothing to repair.
+func (o OrphanedMetadata) Empty() bool {
+	return len(o.Orphans) == 0 && len(o.Stale) == 0
+}
+
+// FindOrphanedMetadata compares the tracked parents with the local branches.
+// Deleting a branch with plain 'git branch -D' drops its own config, but not
+// the socle-parent of its children.
+func FindOrphanedMetadata() (OrphanedMetadata, error) {
+	parents, err := GetAllSocleParents()
+	if err != nil {
+		return OrphanedMetadata{}, fmt.Errorf("failed to read tracking relationships: %w", err)
+	}
+	mentioned := make([]string, 0, 2*len(parents))
+	for branch, parent := range parents {
+		mentioned = append(mentioned, branch, parent)
+	}
+	exists, err := ExistingLocalBranches(mentioned)
+	if err != nil {
+		return OrphanedMetadata{}, err
+	}
+
+	result := OrphanedMetadata{Orphans: map[string]string{}}
+	for branch, parent := range parents {
+		switch {
+		case !exists[branch]:
+			result.Stale = append(result.Stale, branch)
+		case !exists[parent]:
+			result.Orphans[branch] = parent
+		}
+	}
+	sort.Strings(result.Stale)
+	return result, nil
+}
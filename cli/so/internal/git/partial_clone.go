@@ -0,0 +1,68 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// IsPartialClone reports whether the repository has a promisor remote, i.e.
+// objects (usually blobs) may be missing locally and fetched on demand.
+func IsPartialClone() bool {
+	output, err := RunGitCommand("config", "--get-regexp", `^(remote\..*\.promisor|extensions\.partialclone)$`)
+	if err != nil {
+		return false // Exit 1: no such keys
+	}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		if strings.HasSuffix(key, ".promisor") {
+			if enabled, _ := strconv.ParseBool(value); enabled {
+				return true
+			}
+			continue
+		}
+		if value != "" { // extensions.partialclone names the promisor remote
+			return true
+		}
+	}
+	return false
+}
+
+// IsSparseSafe reports whether socle must avoid operations that read blobs.
+// socle.sparseSafe decides when set; otherwise it is on for partial clones,
+// where touching a missing blob triggers a fetch from the promisor remote.
+func IsSparseSafe() bool {
+	val, err := GetSocleConfig("socle.sparseSafe")
+	if err == nil {
+		if enabled, errParse := strconv.ParseBool(strings.TrimSpace(val)); errParse == nil {
+			return enabled
+		}
+	}
+	return IsPartialClone()
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant, using
+// `git merge-base --is-ancestor` (served from the commit-graph when present).
+func IsAncestor(ancestor, descendant string) (bool, error) {
+	_, err := RunGitCommand("merge-base", "--is-ancestor", ancestor, descendant)
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check whether '%s' is an ancestor of '%s': %w", ancestor, descendant, err)
+}
+
+// treesEqual compares the root trees of two refs without reading any blobs.
+func treesEqual(ref1, ref2 string) (bool, error) {
+	output, err := RunGitCommand("rev-parse", ref1+"^{tree}", ref2+"^{tree}")
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve trees of '%s' and '%s': %w", ref1, ref2, err)
+	}
+	trees := strings.Fields(output)
+	return len(trees) == 2 && trees[0] == trees[1], nil
+}
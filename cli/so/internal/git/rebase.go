@@ -0,0 +1,177 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/profile"
+)
+
+// RebaseOptions tweaks how socle replays a branch onto its parent.
+type RebaseOptions struct {
+	Trailers     []Trailer // Amend every replayed commit to carry these trailers
+	RebaseMerges bool      // Recreate merge commits (git rebase --rebase-merges) instead of flattening them
+	DropEmpty    bool      // Drop commits that end up empty (git rebase --empty=drop), e.g. changes the parent already has
+	Upstream     string    // Replay only the commits after Upstream (git rebase --onto), not all those the new base lacks
+}
+
+// RebaseCurrentBranchOntoWith rebases the current branch onto newBaseOID like
+// RebaseCurrentBranchOnto, applying opts. With trailers, existing trailers with
+// the same key are replaced, so running it again keeps a single up-to-date value
+// per key.
+func RebaseCurrentBranchOntoWith(newBaseOID string, opts RebaseOptions) error {
+	if len(opts.Trailers) == 0 && !opts.RebaseMerges && !opts.DropEmpty && opts.Upstream == "" {
+		return RebaseCurrentBranchOnto(newBaseOID)
+	}
+
+	args := []string{"rebase"}
+	if opts.DropEmpty {
+		args = append(args, "--empty=drop")
+	}
+	if opts.RebaseMerges {
+		args = append(args, "--rebase-merges")
+	}
+	if len(opts.Trailers) > 0 {
+		args = append(args, "--exec", trailerAmendCommand(opts.Trailers))
+	}
+	if opts.Upstream != "" {
+		args = append(args, "--onto", newBaseOID, opts.Upstream)
+	} else {
+		args = append(args, newBaseOID)
+	}
+	_, err := RunGitCommand(args...)
+	if err == nil {
+		return nil
+	}
+	if IsRebaseInProgress() {
+		return ErrRebaseConflict
+	}
+	return fmt.Errorf("git rebase onto '%s' failed: %w", newBaseOID, err)
+}
+
+// ContinueRebase resumes a paused rebase once its conflicts are resolved and
+// staged, keeping the commit messages as they are. It returns
+// ErrRebaseConflict when the rebase stops again.
+func ContinueRebase() error {
+	args := []string{"rebase", "--continue"}
+	defer profile.Start(profile.CategoryGit, profile.GitVerb(args))()
+
+	cmd := captureCommand(args...)
+	cmd.Env = append(cmd.Env, "GIT_EDITOR=true")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if IsRebaseInProgress() {
+			return ErrRebaseConflict
+		}
+		return fmt.Errorf("git rebase --continue failed: %s: %w", strings.TrimSpace(output.String()), err)
+	}
+	return nil
+}
+
+// AbortRebase gives up a paused rebase and restores the branch as it was.
+func AbortRebase() error {
+	if _, err := RunGitCommand("rebase", "--abort"); err != nil {
+		return fmt.Errorf("git rebase --abort failed: %w", err)
+	}
+	return nil
+}
+
+// RebaseMergesEnabled reports whether branch should be rebased with
+// --rebase-merges: branch.<name>.socle-rebase-merges if set, otherwise
+// socle.rebaseMerges (or SOCLE_REBASE_MERGES), otherwise false.
+func RebaseMergesEnabled(branch string) (bool, error) {
+	key := BranchConfigKey(branch, "socle-rebase-merges")
+	val, err := GetGitConfig(key)
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return GetSocleConfigBool("socle.rebaseMerges", false)
+		}
+		return false, err
+	}
+	enabled, errParse := strconv.ParseBool(strings.TrimSpace(val))
+	if errParse != nil {
+		return false, fmt.Errorf("invalid value '%s' for %s: expected true or false", val, key)
+	}
+	return enabled, nil
+}
+
+// GetMergeCommits returns the merge commits in parentRef..branchRef, oldest
+// first. A plain rebase would drop them and flatten their history.
+func GetMergeCommits(parentRef, branchRef string) ([]Commit, error) {
+	return GetCommits(parentRef, branchRef, "--merges")
+}
+
+// MergedIntoParent reports whether every commit branch has on top of parent
+// already landed in parent, for example cherry-picked or applied from a
+// patch: 'git cherry' finds an equivalent commit (same patch ID) in parent for
+// each of them, so a rebase leaves nothing of branch behind. A branch without
+// commits of its own is not reported as merged.
+func MergedIntoParent(parent, branch string) (bool, error) {
+	output, err := RunGitCommand("cherry", parent, branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare the commits of '%s' with '%s': %w", branch, parent, err)
+	}
+	if output == "" {
+		return false, nil
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "-") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// LandedIn reports whether branch's work is in trunk, so branch can go even
+// without a merged PR: branch is an ancestor of trunk, or each of its
+// commits is in trunk as an equivalent one (cherry-picked, see
+// MergedIntoParent). A branch at the same commit as parent has no work of
+// its own. Nor has one whose parent already has all its commits and whose
+// tip is on trunk's first-parent line, as for a branch created on trunk and
+// never committed to; a fast-forward of trunk to a branch looks the same, so
+// only merges with a merge commit are found then.
+func LandedIn(trunk, parent, branch string) (bool, error) {
+	tips, err := GetMultipleBranchCommits([]string{parent, branch})
+	if err != nil {
+		return false, err
+	}
+	if tips[parent] == tips[branch] {
+		return false, nil
+	}
+	merged, err := IsAncestor(branch, trunk)
+	if err != nil {
+		return false, err
+	}
+	if !merged {
+		return MergedIntoParent(trunk, branch)
+	}
+	own, err := GetCommits(parent, branch)
+	if err != nil || len(own) > 0 {
+		return err == nil, err
+	}
+	onLine, err := onFirstParentLine(trunk, tips[branch])
+	return !onLine && err == nil, err
+}
+
+// onFirstParentLine reports whether commit, an ancestor of trunk, is one of
+// the commits trunk's first parents lead through, rather than one merged in.
+func onFirstParentLine(trunk, commit string) (bool, error) {
+	output, err := RunGitCommand("rev-list", "--first-parent", trunk, "^"+commit)
+	if err != nil {
+		return false, fmt.Errorf("failed to walk the history of '%s': %w", trunk, err)
+	}
+	if output == "" {
+		return true, nil // trunk is at commit
+	}
+	lines := strings.Split(output, "\n")
+	firstParent, err := RunGitCommand("rev-parse", "--verify", "--quiet", lines[len(lines)-1]+"^1")
+	if err != nil {
+		return false, nil // Root commit
+	}
+	return firstParent == commit, nil
+}
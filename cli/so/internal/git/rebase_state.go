@@ -0,0 +1,185 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RebaseState describes a rebase paused on a conflict, as recorded in git's
+// rebase-merge state files.
+type RebaseState struct {
+	Onto       string   // Commit the branch is replayed onto
+	Stopped    Commit   // Commit whose replay conflicts
+	Commits    []Commit // Every commit the rebase replays, oldest first
+	Conflicted []string // Files with unresolved conflicts
+}
+
+// ReadRebaseState returns what the paused rebase is doing. It returns nil
+// without an error when no rebase-merge state exists, e.g. for a rebase run
+// with the apply backend.
+func ReadRebaseState() (*RebaseState, error) {
+	dir, err := RunGitCommand("rev-parse", "--git-path", "rebase-merge")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate rebase state: %w", err)
+	}
+	onto, err := os.ReadFile(filepath.Join(dir, "onto"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read rebase state: %w", err)
+	}
+
+	state := &RebaseState{Onto: strings.TrimSpace(string(onto))}
+	done, err := readRebaseTodo(filepath.Join(dir, "done"))
+	if err != nil {
+		return nil, err
+	}
+	todo, err := readRebaseTodo(filepath.Join(dir, "git-rebase-todo"))
+	if err != nil {
+		return nil, err
+	}
+	state.Commits = append(done, todo...)
+
+	// stopped-sha names the commit git could not apply; without it, the last
+	// commit taken from the todo list is the one being replayed.
+	if stopped, err := os.ReadFile(filepath.Join(dir, "stopped-sha")); err == nil {
+		state.Stopped.OID = strings.TrimSpace(string(stopped))
+	} else if len(done) > 0 {
+		state.Stopped.OID = done[len(done)-1].OID
+	}
+	for _, c := range state.Commits {
+		if state.Stopped.OID != "" && strings.HasPrefix(c.OID, state.Stopped.OID) {
+			state.Stopped = c
+			break
+		}
+	}
+
+	files, err := ConflictedFiles()
+	if err != nil {
+		return nil, err
+	}
+	state.Conflicted = files
+	return state, nil
+}
+
+// RebaseHeadName returns the branch the rebase in progress is rebasing, or
+// "" when no rebase is in progress or it started on a detached HEAD.
+func RebaseHeadName() (string, error) {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		dir, err := RunGitCommand("rev-parse", "--git-path", name)
+		if err != nil {
+			return "", fmt.Errorf("failed to locate rebase state: %w", err)
+		}
+		head, err := os.ReadFile(filepath.Join(dir, "head-name"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", fmt.Errorf("failed to read rebase state: %w", err)
+		}
+		return strings.TrimPrefix(strings.TrimSpace(string(head)), "refs/heads/"), nil
+	}
+	return "", nil
+}
+
+// readRebaseTodo returns the commits a rebase todo file picks. Other
+// instructions, such as the exec lines that maintain trailers, are skipped.
+func readRebaseTodo(path string) ([]Commit, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read rebase state: %w", err)
+	}
+	defer f.Close()
+
+	var commits []Commit
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "pick", "p", "reword", "r", "edit", "e", "squash", "s", "fixup", "f":
+		default:
+			continue
+		}
+		c := Commit{OID: fields[1]}
+		if len(fields) == 3 {
+			c.Subject = strings.TrimPrefix(fields[2], "# ")
+		}
+		commits = append(commits, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rebase state: %w", err)
+	}
+	return commits, nil
+}
+
+// ConflictedFiles lists the paths with unresolved merge conflicts.
+func ConflictedFiles() ([]string, error) {
+	output, err := RunGitCommand("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// ConflictOwners returns, for each of paths, the branch of chain whose own
+// commits changed it last. chain is the stack below the conflicting branch,
+// base first; a path none of the stacked branches changed belongs to the
+// base. One 'git log' runs per stacked branch, from the top down, until
+// every path has an owner.
+func ConflictOwners(chain []string, paths []string) (map[string]string, error) {
+	owners := make(map[string]string, len(paths))
+	if len(chain) == 0 || len(paths) == 0 {
+		return owners, nil
+	}
+	for i := len(chain) - 1; i > 0 && len(owners) < len(paths); i-- {
+		args := []string{"log", "--format=", "--name-only", chain[i-1] + ".." + chain[i], "--"}
+		for _, path := range paths {
+			if _, ok := owners[path]; !ok {
+				args = append(args, ":(top,literal)"+path)
+			}
+		}
+		output, err := RunGitCommand(args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the changes of '%s': %w", chain[i], err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			if _, ok := owners[line]; !ok {
+				owners[line] = chain[i]
+			}
+		}
+	}
+	for _, path := range paths {
+		if _, ok := owners[path]; !ok {
+			owners[path] = chain[0]
+		}
+	}
+	return owners, nil
+}
+
+// RestackStatePath returns the file in which a restack paused on a conflict
+// keeps its progress for 'so continue': socle/restack.json in the git
+// directory of the current worktree, next to git's own rebase state.
+func RestackStatePath() (string, error) {
+	path, err := RunGitCommand("rev-parse", "--git-path", "socle/restack.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate restack state: %w", err)
+	}
+	return filepath.Abs(path)
+}
@@ -2,10 +2,10 @@ package git
 
 import (
 	"fmt"
-	"net/url"
-	"os/exec"
-	"regexp"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"errors"
 
@@ -20,31 +20,86 @@ func GetRemoteURL(remoteName string) (string, error) {
 		return output, nil
 	}
 
-	// Handle errors
-	isNoSuchRemote := strings.Contains(err.Error(), "No such remote")
+	// git remote get-url exits with 2 for a remote that does not exist
+	if exitCode(err) == 2 {
+		return "", newKindError(ErrRemoteNotFound, err, "remote '%s' not found", remoteName)
+	}
+
+	// Otherwise, it's some other unexpected error
+	return "", fmt.Errorf("failed to get URL for remote '%s': %w", remoteName, err)
+}
+
+// PushConfig holds settings forwarded to every `git push` socle runs.
+type PushConfig struct {
+	// Signed is passed as --signed=<value> when non-empty ("true" or "if-asked").
+	Signed string
+	// Options are passed as --push-option=<value>, in order.
+	Options []string
+}
+
+// LoadPushConfig reads socle.signedPush and socle.pushOptions (see GetSocleConfig).
+// socle.pushOptions may be set multiple times (git config --add).
+func LoadPushConfig() (PushConfig, error) {
+	var cfg PushConfig
 
-	// Check for the specific exit code 2 case
-	isExitCode2 := false
-	if exitErr, ok := err.(*exec.ExitError); ok { // Perform type assertion
-		isExitCode2 = exitErr.ExitCode() == 2 // Check code if assertion worked
+	signed, err := GetSocleConfig("socle.signedPush")
+	if err != nil && !errors.Is(err, ErrConfigNotFound) {
+		return cfg, err
+	}
+	switch strings.ToLower(strings.TrimSpace(signed)) {
+	case "", "false", "no", "off", "0":
+		cfg.Signed = ""
+	case "true", "yes", "on", "1":
+		cfg.Signed = "true"
+	case "if-asked":
+		cfg.Signed = "if-asked"
+	default:
+		return cfg, fmt.Errorf("invalid value '%s' for socle.signedPush (expected true, false or if-asked)", signed)
 	}
 
-	// Now check if either known "not found" condition is true
-	if isNoSuchRemote || isExitCode2 {
-		return "", fmt.Errorf("remote '%s' not found", remoteName)
+	options, err := GetSocleConfigAll("socle.pushOptions")
+	if err != nil {
+		return cfg, err
+	}
+	for _, opt := range options {
+		if opt = strings.TrimSpace(opt); opt != "" {
+			cfg.Options = append(cfg.Options, opt)
+		}
 	}
+	return cfg, nil
+}
 
-	// Otherwise, it's some other unexpected error
-	return "", fmt.Errorf("failed to get URL for remote '%s': %w", remoteName, err)
+// WithOptions returns a copy of the config with extra push options appended.
+func (c PushConfig) WithOptions(extra ...string) PushConfig {
+	merged := append([]string{}, c.Options...)
+	for _, opt := range extra {
+		if opt != "" {
+			merged = append(merged, opt)
+		}
+	}
+	c.Options = merged
+	return c
+}
+
+func (c PushConfig) args() []string {
+	var args []string
+	if c.Signed != "" {
+		args = append(args, "--signed="+c.Signed)
+	}
+	for _, opt := range c.Options {
+		args = append(args, "--push-option="+opt)
+	}
+	return args
 }
 
 // PushBranch pushes a local branch to a remote.
-func PushBranch(branchName string, remoteName string, force bool) error {
+func PushBranch(branchName string, remoteName string, force bool, cfg PushConfig) error {
 	args := []string{"push"}
 	if force {
 		args = append(args, "--force")
 		// Consider --force-with-lease later for more safety? Requires upstream info.
 	}
+	args = append(args, cfg.args()...)
 	// Explicitly specify refspec to push local branch to remote branch of same name
 	refspec := fmt.Sprintf("refs/heads/%s:refs/heads/%s", branchName, branchName)
 	args = append(args, remoteName, refspec)
@@ -58,33 +113,14 @@ func PushBranch(branchName string, remoteName string, force bool) error {
 	return nil
 }
 
-// Regex to extract owner/repo from common Git URL formats
-var repoUrlRegex = regexp.MustCompile(`(?::|/)([^/:]+)/([^/]+?)(?:\.git)?$`)
-
-// ParseOwnerAndRepo extracts owner and repository name from a remote URL.
+// ParseOwnerAndRepo extracts owner and repository name from a remote URL
+// (see ParseRemoteURL).
 func ParseOwnerAndRepo(remoteUrl string) (owner string, repo string, err error) {
-	// Handle SSH URLs like git@github.com:owner/repo.git
-	if strings.HasPrefix(remoteUrl, "git@") {
-		remoteUrl = strings.Replace(remoteUrl, ":", "/", 1)
-	}
-
-	// Use url.Parse for basic structure check, then regex for flexibility
-	parsed, errParse := url.Parse(remoteUrl)
-	if errParse != nil {
-		return "", "", fmt.Errorf("failed to parse remote URL '%s': %w", remoteUrl, errParse)
-	}
-
-	matches := repoUrlRegex.FindStringSubmatch(parsed.Path) // Apply regex on path part
-	if len(matches) != 3 {
-		// Fallback: Try regex on the original string if path fails (e.g., SSH format after replace)
-		matches = repoUrlRegex.FindStringSubmatch(remoteUrl)
-		if len(matches) != 3 {
-			return "", "", fmt.Errorf("could not extract owner/repo from URL: %s", remoteUrl)
-		}
+	u, err := ParseRemoteURL(remoteUrl)
+	if err != nil {
+		return "", "", err
 	}
-	owner = matches[1]
-	repo = matches[2]
-	return owner, repo, nil
+	return u.Owner, u.Repo, nil
 }
 
 // FetchBranch updates the remote-tracking branch for a given local branch
@@ -130,8 +166,9 @@ func FetchBranch(branchName string, remoteName string) error {
 
 // PushBranchWithLease pushes a local branch to a remote using --force-with-lease.
 // This is safer than --force as it checks if the remote ref hasn't changed unexpectedly.
-func PushBranchWithLease(branchName string, remoteName string) error {
+func PushBranchWithLease(branchName string, remoteName string, cfg PushConfig) error {
 	args := []string{"push", "--force-with-lease"}
+	args = append(args, cfg.args()...)
 
 	// Explicitly specify refspec for clarity and safety
 	refspec := fmt.Sprintf("refs/heads/%s:refs/heads/%s", branchName, branchName)
@@ -147,6 +184,54 @@ func PushBranchWithLease(branchName string, remoteName string) error {
 	return nil
 }
 
+// PushBranchWithExpectedTip force-pushes branchName like PushBranchWithLease,
+// but only if the remote branch is still at expectedOID, or does not exist for
+// an empty expectedOID (see ExpectedRemoteTip). Unlike a bare
+// --force-with-lease, a fetch that moved the remote-tracking branch does not
+// disarm the check. A rejected lease matches ErrStaleLease.
+func PushBranchWithExpectedTip(branchName, remoteName, expectedOID string, cfg PushConfig) error {
+	ref := "refs/heads/" + branchName
+	args := []string{"push", fmt.Sprintf("--force-with-lease=%s:%s", ref, expectedOID)}
+	args = append(args, cfg.args()...)
+	args = append(args, remoteName, ref+":"+ref)
+
+	_, err := RunGitCommand(args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "stale info") {
+			return newKindError(ErrStaleLease, err, "'%s' changed on '%s' since it was last pushed", branchName, remoteName)
+		}
+		return fmt.Errorf("failed to push branch '%s' with lease to remote '%s': %w", branchName, remoteName, err)
+	}
+	return nil
+}
+
+// ExpectedRemoteTip returns the commit a push of branchName expects to
+// replace on remoteName: the remote-tracking branch when the local branch
+// already contains it, else lastPushed, the tip socle last pushed. That way
+// commits a teammate pushed are only overwritten once they were integrated,
+// not merely fetched. Without a remote-tracking branch, the branch is
+// expected not to exist ("").
+func ExpectedRemoteTip(branchName, remoteName, lastPushed string) (string, error) {
+	tracking, err := GetRemoteBranchCommit(branchName, remoteName)
+	if err != nil {
+		if errors.Is(err, ErrRefNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	if lastPushed == "" || lastPushed == tracking {
+		return tracking, nil
+	}
+	integrated, err := IsAncestor(tracking, "refs/heads/"+branchName)
+	if err != nil {
+		return "", err
+	}
+	if integrated {
+		return tracking, nil
+	}
+	return lastPushed, nil
+}
+
 // FetchAll fetches all branches from the specified remote
 func FetchAll(remoteName string) error {
 	_, err := RunGitCommand("fetch", remoteName)
@@ -156,12 +241,77 @@ func FetchAll(remoteName string) error {
 	return nil
 }
 
-// DeleteBranch deletes a local branch
+// FetchPrune fetches remoteName and removes remote-tracking branches that
+// no longer exist there.
+func FetchPrune(remoteName string) error {
+	if _, err := RunGitCommand("fetch", "--quiet", "--prune", remoteName); err != nil {
+		return fmt.Errorf("failed to fetch from remote '%s': %w", remoteName, err)
+	}
+	return nil
+}
+
+// DeleteBranch deletes a local branch, first recording a tombstone so
+// 'so restore <branch>' can bring it back (see WriteTombstone). Branches
+// checked out in another worktree are refused with ErrCheckedOutElsewhere.
+// PR base overrides naming the branch move to its parent.
 func DeleteBranch(branchName string) error {
-	_, err := RunGitCommand("branch", "-D", branchName)
+	worktree, err := WorktreeOf(branchName)
+	if err != nil {
+		return err
+	}
+	if worktree != "" {
+		return newKindError(ErrCheckedOutElsewhere, nil, "'%s' is checked out in the worktree at '%s'", branchName, worktree)
+	}
+	if err := WriteTombstone(branchName); err != nil {
+		return fmt.Errorf("failed to record '%s' before deleting it: %w", branchName, err)
+	}
+	parent, _ := GetGitConfig(BranchConfigKey(branchName, "socle-parent"))
+	defer invalidateBranchConfigCache() // Deleting the branch drops its config section
+	_, err = RunGitCommand("branch", "-D", branchName)
 	if err != nil {
 		return fmt.Errorf("failed to delete branch '%s': %w", branchName, err)
 	}
+	if err := moveWorktreeBranchMetadata(branchName, ""); err != nil {
+		return err
+	}
+	return retargetPRBaseOverrides(branchName, strings.TrimSpace(parent))
+}
+
+// RemoteBranchExists asks the remote whether it still has branchName.
+func RemoteBranchExists(branchName, remoteName string) (bool, error) {
+	_, err := RunGitCommand("ls-remote", "--exit-code", "--heads", remoteName, "refs/heads/"+branchName)
+	if err != nil {
+		if exitCode(err) == 2 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to look up '%s' on remote '%s': %w", branchName, remoteName, err)
+	}
+	return true, nil
+}
+
+// DeleteRemoteBranch deletes branchName on the remote and its
+// remote-tracking branch.
+func DeleteRemoteBranch(branchName, remoteName string) error {
+	if _, err := RunGitCommand("push", "--quiet", remoteName, "--delete", branchName); err != nil {
+		return fmt.Errorf("failed to delete '%s' on remote '%s': %w", branchName, remoteName, err)
+	}
+	return nil
+}
+
+// TrackRemoteBranch makes <remote>/<branch> the upstream of branch after a
+// push, and drops the remote-tracking branch of oldName, the name the
+// branch was pushed under before a rename.
+func TrackRemoteBranch(branchName, oldName, remoteName string) error {
+	if _, err := RunGitCommand("update-ref", "-d", fmt.Sprintf("refs/remotes/%s/%s", remoteName, oldName)); err != nil {
+		return fmt.Errorf("failed to drop remote-tracking branch '%s/%s': %w", remoteName, oldName, err)
+	}
+	upstream := fmt.Sprintf("%s/%s", remoteName, branchName)
+	if _, err := RunGitCommand("update-ref", "refs/remotes/"+upstream, "refs/heads/"+branchName); err != nil {
+		return fmt.Errorf("failed to record remote-tracking branch '%s': %w", upstream, err)
+	}
+	if _, err := RunGitCommand("branch", "--set-upstream-to="+upstream, branchName); err != nil {
+		return fmt.Errorf("failed to set upstream of '%s' to '%s': %w", branchName, upstream, err)
+	}
 	return nil
 }
 
@@ -207,8 +357,7 @@ func ForceUpdateBranch(branchName, remoteName string) error {
 
 // SetBranchParent updates the tracking information for a branch to point to a new parent.
 func SetBranchParent(branchName, parentName string) error {
-	cmd := exec.Command("git", "branch", "--set-upstream-to="+parentName, branchName)
-	if err := cmd.Run(); err != nil {
+	if _, err := RunGitCommand("branch", "--set-upstream-to="+parentName, branchName); err != nil {
 		return fmt.Errorf("failed to set parent for branch '%s' to '%s': %w", branchName, parentName, err)
 	}
 	return nil
@@ -216,18 +365,148 @@ func SetBranchParent(branchName, parentName string) error {
 
 // SwitchBranch switches to the specified branch
 func SwitchBranch(branch string) error {
-	cmd := exec.Command("git", "switch", branch)
-	return cmd.Run()
+	_, err := RunGitCommand("switch", branch)
+	return err
 }
 
 // UpdateBranchParent refreshes the Socle parent metadata for a branch without
 // touching Git's upstream configuration (to preserve remote tracking).
 func UpdateBranchParent(branchName, parentName string) error {
-	parentConfigKey := fmt.Sprintf("branch.%s.socle-parent", branchName)
-	cmd := exec.Command("git", "config", "--local", parentConfigKey, parentName)
-	if err := cmd.Run(); err != nil {
+	parentConfigKey := BranchConfigKey(branchName, "socle-parent")
+	scope, err := metadataScopeOption()
+	if err != nil {
+		return err
+	}
+	defer invalidateBranchConfigCache()
+	if _, err := RunGitCommand("config", scope, parentConfigKey, parentName); err != nil {
 		return fmt.Errorf("failed to set parent configuration for branch '%s' to '%s': %w", branchName, parentName, err)
 	}
 
 	return nil
 }
+
+// CanFastForward reports whether branchName can be fast-forwarded to its
+// remote-tracking branch, the check FastForwardBranch performs first.
+func CanFastForward(branchName, remoteName string) (bool, error) {
+	return IsAncestor(branchName, fmt.Sprintf("%s/%s", remoteName, branchName))
+}
+
+// ResolveTrunkRef returns <remote>/<branch> when it exists and branch otherwise.
+func ResolveTrunkRef(branchName, remoteName string) string {
+	remoteRef := fmt.Sprintf("%s/%s", remoteName, branchName)
+	if _, err := RunGitCommand("rev-parse", "--verify", "--quiet", remoteRef); err == nil {
+		return remoteRef
+	}
+	return branchName
+}
+
+// FetchRemoteBranches updates <remote>/<branch> for each branch in a single
+// fetch, without touching local branches.
+func FetchRemoteBranches(remoteName string, branches []string) error {
+	args := []string{"fetch", "--quiet", remoteName}
+	for _, b := range branches {
+		args = append(args, fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", b, remoteName, b))
+	}
+	if _, err := RunGitCommand(args...); err != nil {
+		return fmt.Errorf("failed to fetch %s from '%s': %w", strings.Join(branches, ", "), remoteName, err)
+	}
+	return nil
+}
+
+// RemoteBranchTips asks remoteName for the tip of every branch it has, without
+// fetching anything or touching remote-tracking branches.
+func RemoteBranchTips(remoteName string) (map[string]string, error) {
+	output, err := RunGitCommand("ls-remote", "--heads", remoteName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the branches of '%s': %w", remoteName, err)
+	}
+	tips := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		oid, ref, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			tips[branch] = oid
+		}
+	}
+	return tips, nil
+}
+
+// GetRemoteBranchCommit returns the commit <remote>/<branch> points at.
+func GetRemoteBranchCommit(branchName, remoteName string) (string, error) {
+	ref := fmt.Sprintf("refs/remotes/%s/%s", remoteName, branchName)
+	output, err := RunGitCommand("rev-parse", "--verify", "--quiet", ref)
+	if err != nil {
+		if exitCode(err) == 1 {
+			return "", newKindError(ErrRefNotFound, err, "'%s/%s' does not exist", remoteName, branchName)
+		}
+		return "", fmt.Errorf("failed to get commit hash for '%s/%s': %w", remoteName, branchName, err)
+	}
+	return output, nil
+}
+
+// TrunkDivergence is how a local base branch relates to its remote-tracking
+// branch, which is only as current as the last fetch.
+type TrunkDivergence struct {
+	Remote      string        // <remote>/<branch>
+	Behind      int           // Commits on the remote the local branch lacks
+	Ahead       int           // Local commits the remote lacks
+	Lag         time.Duration // How much newer the remote tip's commit is
+	LastFetched time.Time     // Zero if the repository was never fetched
+}
+
+// GetTrunkDivergence compares branchName with <remote>/<branch> using one
+// rev-list count. Returns nil without error when there is no remote-tracking
+// branch to compare with.
+func GetTrunkDivergence(branchName, remoteName string) (*TrunkDivergence, error) {
+	localRef := "refs/heads/" + branchName
+	remoteRef := fmt.Sprintf("refs/remotes/%s/%s", remoteName, branchName)
+	out, err := RunGitCommand("for-each-ref", "--format=%(refname) %(committerdate:unix)", localRef, remoteRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s' and '%s': %w", localRef, remoteRef, err)
+	}
+	dates := make(map[string]int64)
+	for _, line := range strings.Split(out, "\n") {
+		if ref, date, ok := strings.Cut(line, " "); ok {
+			dates[ref], _ = strconv.ParseInt(date, 10, 64)
+		}
+	}
+	if _, ok := dates[remoteRef]; !ok {
+		return nil, nil
+	}
+	if _, ok := dates[localRef]; !ok {
+		return nil, nil
+	}
+
+	counts, err := RunGitCommand("rev-list", "--left-right", "--count", localRef+"..."+remoteRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count commits between '%s' and '%s/%s': %w", branchName, remoteName, branchName, err)
+	}
+	fields := strings.Fields(counts)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("unexpected rev-list output %q", counts)
+	}
+	d := &TrunkDivergence{Remote: remoteName + "/" + branchName}
+	d.Ahead, _ = strconv.Atoi(fields[0])
+	d.Behind, _ = strconv.Atoi(fields[1])
+	if lag := dates[remoteRef] - dates[localRef]; d.Behind > 0 && lag > 0 {
+		d.Lag = time.Duration(lag) * time.Second
+	}
+	d.LastFetched = LastFetchTime()
+	return d, nil
+}
+
+// LastFetchTime returns when the repository was last fetched, judged by
+// FETCH_HEAD, or the zero time if it never was.
+func LastFetchTime() time.Time {
+	path, err := RunGitCommand("rev-parse", "--git-path", "FETCH_HEAD")
+	if err != nil {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
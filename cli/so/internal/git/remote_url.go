@@ -0,0 +1,140 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// RemoteURL is a remote's URL reduced to what socle needs from it: where the
+// repository is hosted and its path there. The forms git accepts for the
+// same repository, such as git@github.com:org/repo.git,
+// ssh://git@github.com:22/org/repo and https://github.com/org/repo/, all
+// yield the same Host, Owner and Repo.
+type RemoteURL struct {
+	Scheme string // "ssh", "https", "http", "git" or "file"; scp-like URLs are "ssh"
+	Host   string // Lower-cased, without user or port; empty for local paths
+	Port   string // Empty unless the URL names one
+	Owner  string // Namespace of the repository, "group/subgroup" for nested GitLab groups
+	Repo   string // Without a trailing .git
+}
+
+// ParseRemoteURL normalizes a remote URL. It accepts scp-like syntax
+// ([user@]host:path), URLs with a scheme and an optional port, and local
+// paths. For a local path only the last directory before the repository
+// counts as the owner, since the rest says nothing about the repository.
+func ParseRemoteURL(raw string) (*RemoteURL, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return nil, fmt.Errorf("remote URL is empty")
+	}
+
+	u := &RemoteURL{}
+	var path string
+	switch {
+	case strings.Contains(trimmed, "://"):
+		parsed, err := url.Parse(trimmed)
+		if err != nil {
+			// ssh://git@host:org/repo mixes the two syntaxes; git accepts it,
+			// so read it as scp-like.
+			scheme, rest, _ := strings.Cut(trimmed, "://")
+			if host, p, ok := splitSCPLike(rest); ok {
+				u.Scheme, u.Host, path = strings.ToLower(scheme), host, p
+				break
+			}
+			return nil, fmt.Errorf("failed to parse remote URL '%s': %w", raw, err)
+		}
+		u.Scheme = strings.ToLower(strings.TrimPrefix(parsed.Scheme, "git+"))
+		u.Host = strings.ToLower(parsed.Hostname())
+		u.Port = parsed.Port()
+		path = parsed.Path
+	case isLocalPath(trimmed):
+		u.Scheme = "file"
+		// Resolve ../ so a relative remote still names its real directory.
+		if abs, err := filepath.Abs(trimmed); !strings.HasPrefix(trimmed, "~") && err == nil {
+			trimmed = abs
+		}
+		path = filepath.ToSlash(trimmed)
+	default:
+		host, p, ok := splitSCPLike(trimmed)
+		if !ok {
+			return nil, fmt.Errorf("could not extract owner/repo from URL: %s", raw)
+		}
+		u.Scheme, u.Host, path = "ssh", host, p
+	}
+	if u.Scheme == "file" {
+		u.Host = ""
+	}
+
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s != "" && s != "." {
+			segments = append(segments, s)
+		}
+	}
+	if n := len(segments); n > 0 {
+		segments[n-1] = strings.TrimSuffix(segments[n-1], ".git")
+		if segments[n-1] == "" {
+			segments = segments[:n-1]
+		}
+	}
+	if len(segments) < 2 {
+		return nil, fmt.Errorf("could not extract owner/repo from URL: %s", raw)
+	}
+
+	u.Repo = segments[len(segments)-1]
+	namespace := segments[:len(segments)-1]
+	if u.Host == "" {
+		namespace = namespace[len(namespace)-1:]
+	}
+	u.Owner = strings.Join(namespace, "/")
+	return u, nil
+}
+
+// splitSCPLike splits [user@]host:path. The path may start with a slash.
+func splitSCPLike(s string) (host, path string, ok bool) {
+	hostPart, path, found := strings.Cut(s, ":")
+	if !found || hostPart == "" || strings.Contains(hostPart, "/") {
+		return "", "", false
+	}
+	if at := strings.LastIndex(hostPart, "@"); at >= 0 {
+		hostPart = hostPart[at+1:]
+	}
+	return strings.ToLower(hostPart), path, hostPart != ""
+}
+
+// isLocalPath reports whether s names a repository on disk, the way git
+// decides it: a colon only makes an scp-like URL when no slash comes first.
+func isLocalPath(s string) bool {
+	if filepath.IsAbs(s) || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "~") {
+		return true
+	}
+	colon := strings.Index(s, ":")
+	slash := strings.Index(s, "/")
+	return colon < 0 || (slash >= 0 && slash < colon)
+}
+
+// FullName returns owner/repo.
+func (u *RemoteURL) FullName() string {
+	return u.Owner + "/" + u.Repo
+}
+
+// WebURL returns the repository's page, https://<host>/<owner>/<repo>.
+// Local paths, as used in tests and mirrors, are assumed to stand in for
+// github.com. A port is kept only for http(s) remotes; an SSH port says
+// nothing about the web server.
+func (u *RemoteURL) WebURL() string {
+	host := u.Host
+	if host == "" {
+		host = "github.com"
+	}
+	scheme := "https"
+	if u.Scheme == "http" {
+		scheme = "http"
+	}
+	if u.Port != "" && (u.Scheme == "http" || u.Scheme == "https") {
+		host += ":" + u.Port
+	}
+	return fmt.Sprintf("%s://%s/%s", scheme, host, u.FullName())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // GetRepoRoot (Keep the existing one)
@@ -25,12 +26,28 @@ var prTemplatePaths = []string{
 }
 
 // FindAndReadPRTemplate searches for a PR template file and reads its content.
+// socle.prTemplatePath, relative to the repository root unless absolute,
+// names a socle-specific template that is used instead.
 func FindAndReadPRTemplate() (string, error) {
 	repoRoot, err := GetRepoRoot()
 	if err != nil {
 		return "", fmt.Errorf("cannot find repo root to search for PR template: %w", err)
 	}
 
+	if path, err := GetSocleConfig("socle.prTemplatePath"); err == nil && strings.TrimSpace(path) != "" {
+		path = strings.TrimSpace(path)
+		absPath := path
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(repoRoot, path)
+		}
+		contentBytes, errRead := os.ReadFile(absPath)
+		if errRead != nil {
+			return "", fmt.Errorf("failed to read PR template '%s' (socle.prTemplatePath): %w", path, errRead)
+		}
+		fmt.Printf("Using PR template: %s\n", path)
+		return string(contentBytes), nil
+	}
+
 	for _, relPath := range prTemplatePaths {
 		absPath := filepath.Join(repoRoot, relPath)
 		_, err := os.Stat(absPath)
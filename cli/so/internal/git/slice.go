@@ -0,0 +1,96 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Commit is a commit OID with its subject line.
+type Commit struct {
+	OID     string
+	Subject string
+}
+
+// GetCommits returns the commits in parentRef..branchRef, oldest first.
+// Extra revisions (e.g. "^<ref>") narrow the range further.
+func GetCommits(parentRef, branchRef string, exclude ...string) ([]Commit, error) {
+	args := append([]string{"log", "--reverse", "--format=%H%x00%s", fmt.Sprintf("%s..%s", parentRef, branchRef)}, exclude...)
+	output, err := RunGitCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of '%s': %w", branchRef, err)
+	}
+	var commits []Commit
+	for _, line := range strings.Split(output, "\n") {
+		oid, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, Commit{OID: oid, Subject: subject})
+	}
+	return commits, nil
+}
+
+// GetSliceSource returns the branch a slice branch was generated from
+// (branch.<name>.socle-slice), or "" if branch is not a slice branch.
+func GetSliceSource(branch string) (string, error) {
+	source, err := GetGitConfig(BranchConfigKey(branch, "socle-slice"))
+	if err != nil {
+		if errors.Is(err, ErrConfigNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(source), nil
+}
+
+// SetSliceSource records that branch was generated by slicing source.
+func SetSliceSource(branch, source string) error {
+	return SetGitConfig(BranchConfigKey(branch, "socle-slice"), source)
+}
+
+// GetSliceBranches returns the slice branches generated from source, bottom
+// first, by walking down its socle-parent chain while branches belong to it.
+func GetSliceBranches(source string) ([]string, error) {
+	parents, err := GetAllSocleParents()
+	if err != nil {
+		return nil, err
+	}
+	var branches []string
+	for branch := parents[source]; branch != ""; branch = parents[branch] {
+		owner, err := GetSliceSource(branch)
+		if err != nil {
+			return nil, err
+		}
+		if owner != source {
+			break
+		}
+		branches = append([]string{branch}, branches...)
+	}
+	return branches, nil
+}
+
+// SetBranchTip points branch at oid without checking it out (`git branch -f`).
+func SetBranchTip(branch, oid string) error {
+	if _, err := RunGitCommand("branch", "--force", branch, oid); err != nil {
+		return fmt.Errorf("failed to move '%s' to %s: %w", branch, oid, err)
+	}
+	return nil
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// maxSlugLength keeps generated branch names readable.
+const maxSlugLength = 48
+
+// BranchSlug turns a commit subject into a branch name component, e.g.
+// "Fix: handle empty input!" -> "fix-handle-empty-input". Returns "" when
+// nothing usable is left.
+func BranchSlug(subject string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(subject), "-"), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
@@ -0,0 +1,212 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotRefPrefix is where snapshot refs live. Each ref points at a commit
+// whose tree holds snapshot.json and whose parents are the snapshotted branch
+// tips, so the captured commits stay reachable and can be pushed or fetched.
+const SnapshotRefPrefix = "refs/socle/snapshots/"
+
+const snapshotFileName = "snapshot.json"
+
+// Snapshot is a point-in-time record of every tracked branch tip plus all
+// socle metadata.
+type Snapshot struct {
+	Name      string            `json:"name"`
+	CreatedAt time.Time         `json:"created_at"`
+	Branches  map[string]string `json:"branches"` // branch -> commit OID
+	Metadata  SocleMetadata     `json:"metadata"`
+}
+
+// CreateSnapshot records all tracked branches and the socle metadata under
+// refs/socle/snapshots/<name>. Base branches are left out: they move on their
+// own as trunk is fetched, and restoring them would drop those commits. An
+// existing snapshot with the same name is an error.
+func CreateSnapshot(name string) (*Snapshot, error) {
+	ref := SnapshotRefPrefix + name
+	if _, err := RunGitCommand("check-ref-format", ref); err != nil {
+		return nil, fmt.Errorf("invalid snapshot name '%s'", name)
+	}
+	if _, err := RunGitCommand("rev-parse", "--verify", "--quiet", ref); err == nil {
+		return nil, fmt.Errorf("snapshot '%s' already exists", name)
+	}
+
+	meta, err := ReadSocleMetadata()
+	if err != nil {
+		return nil, err
+	}
+	parents, err := GetAllSocleParents()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Name:      name,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Branches:  make(map[string]string, len(parents)),
+		Metadata:  meta,
+	}
+	for branch := range parents {
+		if IsKnownBaseBranch(branch) {
+			continue
+		}
+		exists, err := BranchExists(branch)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue // Stale metadata; nothing to record
+		}
+		oid, err := GetCurrentBranchCommit(branch)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Branches[branch] = oid
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	blob, err := runGitCommand(bytes.NewReader(data), "hash-object", "-w", "--stdin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to store snapshot data: %w", err)
+	}
+	tree, err := runGitCommand(strings.NewReader(fmt.Sprintf("100644 blob %s\t%s\n", blob, snapshotFileName)), "mktree")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot tree: %w", err)
+	}
+
+	args := []string{"commit-tree", tree, "-m", "socle snapshot " + name}
+	seen := make(map[string]bool)
+	for _, branch := range sortedKeys(snapshot.Branches) {
+		oid := snapshot.Branches[branch]
+		if !seen[oid] {
+			seen[oid] = true
+			args = append(args, "-p", oid)
+		}
+	}
+	commit, err := RunGitCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot commit: %w", err)
+	}
+	if _, err := RunGitCommand("update-ref", ref, commit, ""); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot ref '%s': %w", ref, err)
+	}
+	return snapshot, nil
+}
+
+// ReadSnapshot loads the snapshot stored under refs/socle/snapshots/<name>. It
+// returns an error matching ErrRefNotFound when there is none.
+func ReadSnapshot(name string) (*Snapshot, error) {
+	ref := SnapshotRefPrefix + name
+	data, err := RunGitCommand("cat-file", "blob", ref+":"+snapshotFileName)
+	if err != nil {
+		return nil, newKindError(ErrRefNotFound, err, "snapshot '%s' not found", name)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot '%s': %w", name, err)
+	}
+	return &snapshot, nil
+}
+
+// ListSnapshots returns all snapshots, oldest first.
+func ListSnapshots() ([]*Snapshot, error) {
+	output, err := RunGitCommand("for-each-ref", "--format=%(refname)", SnapshotRefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	var snapshots []*Snapshot
+	for _, ref := range strings.Split(output, "\n") {
+		if ref == "" {
+			continue
+		}
+		snapshot, err := ReadSnapshot(strings.TrimPrefix(ref, SnapshotRefPrefix))
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt) })
+	return snapshots, nil
+}
+
+// RestoreSnapshot moves every snapshotted branch back to its recorded commit
+// (recreating deleted branches) and replaces the socle metadata with the
+// snapshot's. Base branches are never moved, including those recorded by
+// older snapshots. Nothing is changed if a branch to move is checked out in
+// another worktree. The checked-out branch is reset with `git reset --hard`,
+// so the caller must make sure the working tree is clean. Returns the
+// branches that moved.
+func RestoreSnapshot(snapshot *Snapshot) ([]string, error) {
+	currentBranch, err := GetCurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	var toMove []string
+	for _, branch := range sortedKeys(snapshot.Branches) {
+		if _, tracked := snapshot.Metadata[BranchConfigKey(branch, "socle-parent")]; !tracked || IsKnownBaseBranch(branch) {
+			continue
+		}
+		exists, err := BranchExists(branch)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			current, err := GetCurrentBranchCommit(branch)
+			if err != nil {
+				return nil, err
+			}
+			if current == snapshot.Branches[branch] {
+				continue
+			}
+		}
+		if branch != currentBranch {
+			path, err := WorktreeOf(branch)
+			if err != nil {
+				return nil, err
+			}
+			if path != "" {
+				return nil, fmt.Errorf("branch '%s' is checked out in worktree '%s'; switch that worktree to another branch before restoring", branch, path)
+			}
+		}
+		toMove = append(toMove, branch)
+	}
+
+	var moved []string
+	for _, branch := range toMove {
+		oid := snapshot.Branches[branch]
+		if branch == currentBranch {
+			_, err = RunGitCommand("reset", "--hard", oid)
+		} else {
+			_, err = RunGitCommand("branch", "--force", branch, oid)
+		}
+		if err != nil {
+			return moved, fmt.Errorf("failed to restore branch '%s' to %s: %w", branch, oid, err)
+		}
+		moved = append(moved, branch)
+	}
+
+	if err := RestoreSocleMetadata(snapshot.Metadata); err != nil {
+		return moved, fmt.Errorf("failed to restore metadata: %w", err)
+	}
+	return moved, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
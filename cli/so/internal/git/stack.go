@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 )
 
 // StackInfo holds all information about a branch stack
@@ -31,10 +32,23 @@ type StackInfo struct {
 //   CurrentStack then represents the lineage from the base to the current branch and navigation commands must
 //   treat it as the active linear stack without prompting for stack selection.
 // The navigation runners (up/top/bottom) implement this distinction; log command also follows these rules.
+// - GetStackInfo fails when a non-base branch has multiple children. GetNavigationStackInfo tolerates such
+//   forks: FullStack follows CurrentStack through forks below the current branch and ends at the first fork
+//   at or above it, leaving the choice between the fork's children to the caller (see ChildMap).
 
 // GetStackInfo retrieves comprehensive information about the current branch stack.
 // It returns all stack-related information in a single StackInfo struct.
 func GetStackInfo() (*StackInfo, error) {
+	return getStackInfo(false)
+}
+
+// GetNavigationStackInfo is GetStackInfo for stacks that fork on non-base
+// branches, as used by the navigation commands.
+func GetNavigationStackInfo() (*StackInfo, error) {
+	return getStackInfo(true)
+}
+
+func getStackInfo(allowForks bool) (*StackInfo, error) {
 	// 1. Get Current Branch
 	currentBranch, err := GetCurrentBranch()
 	if err != nil {
@@ -51,7 +65,7 @@ func GetStackInfo() (*StackInfo, error) {
 	childMap := BuildChildMap(parentMap)
 
 	// 3. Check if we are actually on a known base branch
-	knownBases := map[string]bool{"main": true, "master": true, "develop": true}
+	knownBases := KnownBaseBranchSet()
 	var baseBranch string
 	var currentStack []string
 
@@ -60,18 +74,21 @@ func GetStackInfo() (*StackInfo, error) {
 		currentStack = []string{baseBranch} // Stack is just the base itself
 	} else {
 		// 4. Check if current branch is tracked
-		baseConfigKey := fmt.Sprintf("branch.%s.socle-base", currentBranch)
+		baseConfigKey := BranchConfigKey(currentBranch, "socle-base")
 		baseBranchNameFromConfig, errBase := GetGitConfig(baseConfigKey)
 		isBaseNotFound := errors.Is(errBase, ErrConfigNotFound)
 
 		if isBaseNotFound {
-			return nil, fmt.Errorf("current branch '%s' is not tracked by socle (missing socle-base config) and is not a known base branch.\nRun 'so track' on this branch first", currentBranch)
+			return nil, newKindError(ErrNotTracked, errBase, "current branch '%s' is not tracked by socle (missing socle-base config) and is not a known base branch.\nRun 'so track' on this branch first", currentBranch)
 		}
 		if errBase != nil {
 			return nil, fmt.Errorf("failed to read tracking base for '%s': %w", currentBranch, errBase)
 		}
 
 		baseBranch = baseBranchNameFromConfig
+		if exists, errExists := BranchExists(baseBranch); errExists == nil && !exists {
+			return nil, fmt.Errorf("%w: '%s' is the base of '%s'. If the default branch was renamed, run 'so migrate-base'", ErrBaseBranchMissing, baseBranch, currentBranch)
+		}
 
 		// 5. Build the stack by walking up the parents using the parentMap
 		currentStack = []string{currentBranch}
@@ -80,7 +97,7 @@ func GetStackInfo() (*StackInfo, error) {
 		for currentInLoop != baseBranch {
 			parent, hasParent := parentMap[currentInLoop]
 			if !hasParent {
-				return nil, fmt.Errorf("tracking information broken: parent not found for branch '%s', which is not the base '%s'. Cannot determine stack", currentInLoop, baseBranch)
+				return nil, fmt.Errorf("tracking information broken: parent not found for branch '%s', which is not the base '%s'. Cannot determine stack. If '%s' was deleted, run 'so untrack --orphans' to repair the tracking of its children", currentInLoop, baseBranch, currentInLoop)
 			}
 
 			// Prepend the found parent to the stack slice
@@ -144,6 +161,9 @@ func GetStackInfo() (*StackInfo, error) {
 						break
 					}
 					if len(childList) > 1 {
+						if allowForks {
+							break // The caller chooses between the children
+						}
 						return nil, fmt.Errorf("non-base branch '%s' has multiple children %v, violating linear lineage assumption", walker, childList)
 					}
 					next := childList[0]
@@ -159,9 +179,17 @@ func GetStackInfo() (*StackInfo, error) {
 				}
 				// Finished lineage reconstruction.
 				break
+			} else if allowForks {
+				// Follow the lineage of the current branch through forks below it;
+				// a fork at or above it ends the stack.
+				idx := slices.Index(currentStack, current)
+				if idx == -1 || idx == len(currentStack)-1 {
+					break
+				}
+				children = currentStack[idx+1 : idx+2]
 			} else {
 				// Non-base branch with multiple children - violates linear stack assumption
-				return nil, fmt.Errorf("non-base branch '%s' has multiple children %v, which violates linear stack structure. Only base branches (%v) can have multiple children", current, children, []string{"main", "master", "develop"})
+				return nil, fmt.Errorf("non-base branch '%s' has multiple children %v, which violates linear stack structure. Only base branches (%v) can have multiple children", current, children, KnownBaseBranches())
 			}
 		}
 		nextChild := children[0]
@@ -204,8 +232,17 @@ func GetAvailableStacksFromBase(baseBranch string) ([][]string, error) {
 		return nil, fmt.Errorf("no stacks found starting from base branch '%s'", baseBranch)
 	}
 
+	ignore, err := LoadBranchIgnoreRules()
+	if err != nil {
+		return nil, err
+	}
+
 	var stacks [][]string
 	for _, child := range children {
+		if pattern, ignored := ignore.Match(child); ignored {
+			slog.Debug("Skipping stack of ignored branch", "branch", child, "pattern", pattern)
+			continue
+		}
 		stack, err := buildLinearStackFromChild(baseBranch, child, childMap, make(map[string]bool))
 		if err != nil {
 			slog.Warn("Failed to build stack from child", "base", baseBranch, "child", child, "error", err)
@@ -254,8 +291,7 @@ func buildLinearStackFromChild(baseBranch, child string, childMap map[string][]s
 	return stack, nil
 }
 
-// IsKnownBaseBranch checks if a branch is a known base branch
+// IsKnownBaseBranch checks if a branch is a known base branch (see KnownBaseBranches)
 func IsKnownBaseBranch(branchName string) bool {
-	knownBases := map[string]bool{"main": true, "master": true, "develop": true}
-	return knownBases[branchName]
+	return KnownBaseBranchSet()[branchName]
 }
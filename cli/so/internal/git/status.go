@@ -6,6 +6,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
 )
 
 // StageInteractively runs `git add -p`.
@@ -75,6 +78,25 @@ func CommitChanges(message string) error {
 	return nil
 }
 
+// AmendCommit folds the staged changes into the commit at HEAD, keeping its
+// message.
+func AmendCommit() error {
+	_, err := RunGitCommand("commit", "--amend", "--no-edit")
+	if err != nil {
+		return fmt.Errorf("failed to amend commit: %w", err)
+	}
+	return nil
+}
+
+// ResetSoft moves the current branch to ref, keeping the index and working
+// tree, so the changes of the commits after ref end up staged.
+func ResetSoft(ref string) error {
+	if _, err := RunGitCommand("reset", "--soft", ref); err != nil {
+		return fmt.Errorf("failed to reset to '%s': %w", ref, err)
+	}
+	return nil
+}
+
 // IsRebaseInProgress checks if a rebase operation is currently paused.
 func IsRebaseInProgress() bool {
 	// Keep the existing implementation using os.Stat on .git/rebase-*
@@ -112,7 +134,7 @@ func RebaseUpdateRefs(baseBranch string) error {
 }
 
 // ErrRebaseConflict indicates a git rebase operation stopped due to conflicts.
-var ErrRebaseConflict = errors.New("rebase conflict detected")
+var ErrRebaseConflict = socleerr.New(socleerr.Conflict, "rebase conflict detected")
 
 // RebaseCurrentBranchOnto performs `git rebase <newBaseOID>` on the currently checked-out branch.
 // It specifically checks for conflicts upon failure using IsRebaseInProgress.
@@ -138,10 +160,18 @@ func RebaseCurrentBranchOnto(newBaseOID string) error {
 
 // HasDiff checks if there are differences between two refs (e.g., parent..branch).
 // Uses `git diff --quiet <ref1>..<ref2>`. Exits 0 if no changes, 1 if changes.
+// Textconv, external diff drivers and rename detection are disabled since they
+// cannot change the answer but may read blobs. In sparse-safe mode (see
+// IsSparseSafe) only the tree IDs are compared, so no blob is ever needed.
 func HasDiff(ref1, ref2 string) (bool, error) {
+	if IsSparseSafe() {
+		equal, err := treesEqual(ref1, ref2)
+		return !equal, err
+	}
+
 	diffRange := fmt.Sprintf("%s..%s", ref1, ref2)
 	// --quiet makes it exit 0 if no diff, 1 if diff.
-	_, err := RunGitCommand("diff", "--quiet", diffRange)
+	_, err := RunGitCommand("diff", "--quiet", "--no-textconv", "--no-ext-diff", "--no-renames", diffRange)
 
 	if err == nil {
 		return false, nil // Exit code 0 means no differences
@@ -160,8 +190,9 @@ func HasDiff(ref1, ref2 string) (bool, error) {
 	return false, fmt.Errorf("failed to check diff for range '%s': %w", diffRange, err)
 }
 
+// NeedsRestack reports whether childBranchName is no longer based on the current
+// tip of parentBranchName. It is an ancestry check only, so it never reads blobs.
 func NeedsRestack(parentBranchName, childBranchName string) (needsRestack bool, err error) {
-	// 1. Get the current commit hash (OID) of the parent branch
 	parentOID, err := GetCurrentBranchCommit(parentBranchName)
 	if err != nil {
 		// If we can't get the parent's commit, we can't determine status reliably
@@ -171,24 +202,112 @@ func NeedsRestack(parentBranchName, childBranchName string) (needsRestack bool,
 		return false, fmt.Errorf("internal error: got empty commit OID for parent '%s'", parentBranchName)
 	}
 
-	// 2. Get the merge-base between the parent and the child branch
-	mergeBase, err := GetMergeBase(parentBranchName, childBranchName)
+	// The child is up to date exactly when the parent's tip is one of its ancestors,
+	// which is equivalent to merge-base(parent, child) == parent tip.
+	basedOnParent, err := IsAncestor(parentOID, childBranchName)
 	if err != nil {
-		// If merge-base fails (e.g., unrelated histories, though unlikely in a stack),
-		// consider it as needing restack? Or return error?
-		// Let's return an error for now, as it indicates a broken state.
-		// The error from GetMergeBase should be informative.
-		return false, fmt.Errorf("failed to find merge base between '%s' and '%s': %w", parentBranchName, childBranchName, err)
+		return false, err
 	}
-	if mergeBase == "" { // Should not happen if GetMergeBase works, but safeguard
-		return false, fmt.Errorf("internal error: got empty merge base between '%s' and '%s'", parentBranchName, childBranchName)
+	return !basedOnParent, nil
+}
+
+// BranchDiff returns the patch of what branch changes since it forked from
+// parent (parent...branch).
+func BranchDiff(parent, branch string) (string, error) {
+	diffRange := fmt.Sprintf("%s...%s", parent, branch)
+	output, err := RunGitCommand("diff", "--no-ext-diff", "--no-textconv", diffRange)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for '%s': %w", diffRange, err)
 	}
+	return output, nil
+}
 
-	// 3. Compare the merge-base with the parent's current OID
-	// If the merge-base is *not* the same as the parent's current tip,
-	// it means the parent has moved forward since the child was based on it,
-	// OR the child was based on something else entirely. Either way, a rebase is needed.
-	needsRestack = (mergeBase != parentOID)
+// WorkingTreeDiff returns the patch of everything `git add .` would stage:
+// staged and unstaged changes to tracked files against HEAD, and untracked
+// files that are not ignored as new files. Before the first commit the
+// tracked files are diffed against the empty tree.
+func WorkingTreeDiff() (string, error) {
+	base, err := RunGitCommand("rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		if base, err = RunGitCommand("hash-object", "-t", "tree", os.DevNull); err != nil {
+			return "", fmt.Errorf("failed to find the empty tree: %w", err)
+		}
+	}
+	output, err := RunGitCommand("diff", "--no-ext-diff", "--no-textconv", base)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff the working tree: %w", err)
+	}
+	var patch strings.Builder
+	if output != "" {
+		patch.WriteString(output + "\n")
+	}
 
-	return needsRestack, nil
+	untracked, err := RunGitCommand("ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return "", fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path == "" {
+			continue
+		}
+		// --no-index exits with 1 when the files differ, which a new file always does.
+		err := streamGitCommand(func(line string) bool {
+			patch.WriteString(line + "\n")
+			return true
+		}, "diff", "--no-index", "--no-ext-diff", "--no-textconv", "--", os.DevNull, path)
+		if err != nil && exitCode(err) != 1 {
+			return "", fmt.Errorf("failed to diff untracked file '%s': %w", path, err)
+		}
+	}
+	return strings.TrimSpace(patch.String()), nil
+}
+
+// FileChange is a path with uncommitted changes. Status is 'A' for a new
+// file (untracked ones included), 'D' for a deleted one and 'M' otherwise.
+type FileChange struct {
+	Path   string
+	Status byte
+}
+
+// UncommittedChanges lists the files `git add .` would stage: changes to
+// tracked files against HEAD, and untracked files that are not ignored.
+func UncommittedChanges() ([]FileChange, error) {
+	tracked, err := RunGitCommand("diff", "--name-status", "--no-renames", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	untracked, err := RunGitCommand("ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	var changes []FileChange
+	for _, line := range strings.Split(tracked, "\n") {
+		status, path, ok := strings.Cut(line, "\t")
+		if !ok || status == "" {
+			continue
+		}
+		change := FileChange{Path: path, Status: 'M'}
+		if status[0] == 'A' || status[0] == 'D' {
+			change.Status = status[0]
+		}
+		changes = append(changes, change)
+	}
+	for _, path := range strings.Split(untracked, "\n") {
+		if path != "" {
+			changes = append(changes, FileChange{Path: path, Status: 'A'})
+		}
+	}
+	return changes, nil
+}
+
+// DiffStat returns `git diff --stat` for what branch changes since it forked
+// from parent (parent...branch), as a reviewer of the branch's PR sees it.
+func DiffStat(parent, branch string) (string, error) {
+	diffRange := fmt.Sprintf("%s...%s", parent, branch)
+	output, err := RunGitCommand("diff", "--stat", "--no-ext-diff", "--no-textconv", diffRange)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diffstat for '%s': %w", diffRange, err)
+	}
+	return output, nil
 }
@@ -0,0 +1,176 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TombstoneRefPrefix is where the tombstones of deleted branches live. Like a
+// snapshot ref, each points at a commit whose tree holds tombstone.json and
+// whose parent is the deleted branch's tip, which keeps the tip reachable.
+const TombstoneRefPrefix = "refs/socle/tombstones/"
+
+// tombstoneRefEscaper flattens a branch name into one ref component, so the
+// tombstones of 'foo' and 'foo/bar' do not clash as a file and a directory.
+var tombstoneRefEscaper = strings.NewReplacer("%", "%25", "/", "%2F")
+
+// tombstoneRef returns the ref holding the tombstone of branch.
+func tombstoneRef(branch string) string {
+	return TombstoneRefPrefix + tombstoneRefEscaper.Replace(branch)
+}
+
+const tombstoneFileName = "tombstone.json"
+
+// DefaultTombstoneDays is how long tombstones are kept when
+// socle.tombstoneDays is unset.
+const DefaultTombstoneDays = 14
+
+// Tombstone records a branch socle deleted, so 'so restore <branch>' can
+// bring it back with its metadata.
+type Tombstone struct {
+	Branch    string        `json:"branch"`
+	DeletedAt time.Time     `json:"deleted_at"`
+	OID       string        `json:"oid"`
+	Metadata  SocleMetadata `json:"metadata"` // The branch's socle-* keys
+}
+
+// TombstoneRetention returns how long tombstones are kept, from
+// socle.tombstoneDays. Zero means branches are deleted without one.
+func TombstoneRetention() (time.Duration, error) {
+	days := DefaultTombstoneDays
+	if val, err := GetSocleConfig("socle.tombstoneDays"); err == nil {
+		parsed, errParse := strconv.ParseUint(strings.TrimSpace(val), 10, 32)
+		if errParse != nil {
+			return 0, fmt.Errorf("invalid value '%s' for socle.tombstoneDays: expected a number of days", val)
+		}
+		days = int(parsed)
+	} else if !errors.Is(err, ErrConfigNotFound) {
+		return 0, err
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// WriteTombstone records branch's tip and socle metadata before it is
+// deleted, replacing an earlier tombstone of the same name, and prunes
+// expired tombstones. It does nothing when retention is disabled.
+func WriteTombstone(branch string) error {
+	retention, err := TombstoneRetention()
+	if err != nil {
+		return err
+	}
+	if retention == 0 {
+		return nil
+	}
+	if err := PruneTombstones(retention); err != nil {
+		return err
+	}
+
+	oid, err := GetCurrentBranchCommit(branch)
+	if err != nil {
+		return err
+	}
+	meta, err := ReadSocleMetadata()
+	if err != nil {
+		return err
+	}
+	tombstone := Tombstone{
+		Branch:    branch,
+		DeletedAt: time.Now().UTC().Truncate(time.Second),
+		OID:       oid,
+		Metadata:  SocleMetadata{},
+	}
+	prefix := BranchConfigKey(branch, "socle-")
+	for key, values := range meta {
+		if strings.HasPrefix(key, prefix) {
+			tombstone.Metadata[key] = values
+		}
+	}
+
+	data, err := json.MarshalIndent(tombstone, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tombstone for '%s': %w", branch, err)
+	}
+	blob, err := runGitCommand(bytes.NewReader(data), "hash-object", "-w", "--stdin")
+	if err != nil {
+		return fmt.Errorf("failed to store tombstone for '%s': %w", branch, err)
+	}
+	tree, err := runGitCommand(strings.NewReader(fmt.Sprintf("100644 blob %s\t%s\n", blob, tombstoneFileName)), "mktree")
+	if err != nil {
+		return fmt.Errorf("failed to create tombstone tree for '%s': %w", branch, err)
+	}
+	commit, err := RunGitCommand("commit-tree", tree, "-p", oid, "-m", "socle tombstone "+branch)
+	if err != nil {
+		return fmt.Errorf("failed to create tombstone commit for '%s': %w", branch, err)
+	}
+	if _, err := RunGitCommand("update-ref", tombstoneRef(branch), commit); err != nil {
+		return fmt.Errorf("failed to write tombstone ref for '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// ReadTombstone loads the tombstone of a deleted branch. It returns an error
+// matching ErrRefNotFound when there is none.
+func ReadTombstone(branch string) (*Tombstone, error) {
+	data, err := RunGitCommand("cat-file", "blob", tombstoneRef(branch)+":"+tombstoneFileName)
+	if err != nil {
+		return nil, newKindError(ErrRefNotFound, err, "no deleted branch '%s' to restore", branch)
+	}
+	var tombstone Tombstone
+	if err := json.Unmarshal([]byte(data), &tombstone); err != nil {
+		return nil, fmt.Errorf("failed to decode tombstone of '%s': %w", branch, err)
+	}
+	return &tombstone, nil
+}
+
+// PruneTombstones deletes tombstones older than retention.
+func PruneTombstones(retention time.Duration) error {
+	output, err := RunGitCommand("for-each-ref", "--format=%(committerdate:unix) %(refname)", TombstoneRefPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list tombstones: %w", err)
+	}
+	cutoff := time.Now().Add(-retention).Unix()
+	for _, line := range strings.Split(output, "\n") {
+		date, ref, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if created, err := strconv.ParseInt(date, 10, 64); err != nil || created >= cutoff {
+			continue
+		}
+		if _, err := RunGitCommand("update-ref", "-d", ref); err != nil {
+			return fmt.Errorf("failed to delete expired tombstone '%s': %w", ref, err)
+		}
+	}
+	return nil
+}
+
+// RestoreTombstone recreates the deleted branch at its recorded tip with its
+// socle metadata and removes the tombstone. The branch must not exist.
+func RestoreTombstone(tombstone *Tombstone) error {
+	exists, err := BranchExists(tombstone.Branch)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("branch '%s' already exists; delete or rename it before restoring", tombstone.Branch)
+	}
+	if _, err := RunGitCommand("branch", tombstone.Branch, tombstone.OID); err != nil {
+		return fmt.Errorf("failed to recreate branch '%s' at %s: %w", tombstone.Branch, tombstone.OID, err)
+	}
+	for key, values := range tombstone.Metadata {
+		for _, value := range values {
+			if err := SetGitConfig(key, value); err != nil {
+				return fmt.Errorf("failed to restore '%s': %w", key, err)
+			}
+		}
+	}
+	if _, err := RunGitCommand("update-ref", "-d", tombstoneRef(tombstone.Branch)); err != nil {
+		return fmt.Errorf("failed to remove the tombstone of '%s': %w", tombstone.Branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Trailer is a single "Key: value" line in the trailer block of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return fmt.Sprintf("%s: %s", t.Key, t.Value)
+}
+
+// Trailer keys socle maintains on stacked commits.
+const (
+	TrailerStackedOn = "Stacked-on"
+	TrailerPR        = "PR"
+)
+
+// IsCommitTrailersEnabled reports whether socle.commitTrailers is set to a true value.
+func IsCommitTrailersEnabled() (bool, error) {
+	return GetSocleConfigBool("socle.commitTrailers", false)
+}
+
+// BranchHasTrailers reports whether every commit in parentRef..branchRef already
+// carries the given trailers with exactly these values.
+func BranchHasTrailers(parentRef, branchRef string, trailers []Trailer) (bool, error) {
+	output, err := RunGitCommand("log", "--format=%H%x00%(trailers:only,unfold)%x1e", fmt.Sprintf("%s..%s", parentRef, branchRef))
+	if err != nil {
+		return false, fmt.Errorf("failed to read commit trailers of '%s': %w", branchRef, err)
+	}
+	for _, record := range strings.Split(output, "\x1e") {
+		_, block, ok := strings.Cut(record, "\x00")
+		if !ok {
+			continue // Trailing separator
+		}
+		present := make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSpace(block), "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			present[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+		for _, t := range trailers {
+			if present[strings.ToLower(t.Key)] != t.Value {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// trailerAmendCommand builds the shell command run by `git rebase --exec` for each commit.
+func trailerAmendCommand(trailers []Trailer) string {
+	parts := []string{"git -c trailer.ifexists=replace commit --amend --no-edit --no-verify --allow-empty"}
+	for _, t := range trailers {
+		parts = append(parts, "--trailer "+shellQuote(t.String()))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote wraps s in single quotes for POSIX sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// OtherWorktreeBranches maps each branch checked out in a worktree other than
+// the current one to that worktree's path. Git refuses to check out, rebase or
+// delete such branches from here.
+func OtherWorktreeBranches() (map[string]string, error) {
+	output, err := RunGitCommand("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	here, err := RunGitCommand("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the current worktree: %w", err)
+	}
+	here = canonicalPath(here)
+
+	branches := make(map[string]string)
+	path := ""
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = strings.TrimPrefix(line, "worktree ")
+		case strings.HasPrefix(line, "branch refs/heads/"):
+			if canonicalPath(path) != here {
+				branches[strings.TrimPrefix(line, "branch refs/heads/")] = path
+			}
+		}
+	}
+	return branches, nil
+}
+
+// WorktreeOf returns the path of the other worktree branch is checked out
+// in, or "" if there is none.
+func WorktreeOf(branch string) (string, error) {
+	branches, err := OtherWorktreeBranches()
+	if err != nil {
+		return "", err
+	}
+	return branches[branch], nil
+}
+
+// RemoveWorktree removes the worktree at path. Git refuses to remove a
+// worktree with uncommitted changes.
+func RemoveWorktree(path string) error {
+	if _, err := RunGitCommand("worktree", "remove", path); err != nil {
+		return fmt.Errorf("failed to remove worktree '%s': %w", path, err)
+	}
+	return nil
+}
+
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,107 @@
+// Package interrupt turns Ctrl+C into a cancelled context, so a long-running
+// command stops between steps instead of in the middle of one.
+//
+// The first SIGINT or SIGTERM cancels the context; the command notices at its
+// next step, leaves the repository in a state it can resume from and returns
+// an error from Check naming how to resume. A second signal exits at once,
+// after running the cleanups registered with OnForcedExit.
+//
+// Git commands that may prompt, such as a rebase, share the terminal and get
+// the signal too; a rebase stopped that way is left in progress and resumed
+// with 'so continue', like one stopped at a conflict.
+package interrupt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/benekuehn/socle/cli/so/internal/socleerr"
+)
+
+var (
+	mu       sync.Mutex
+	cleanups = map[int]func(){}
+	nextID   int
+)
+
+// NotifyContext returns a copy of parent that is cancelled on the first
+// SIGINT or SIGTERM. A notice goes to w when that happens. stop releases the
+// signals again.
+func NotifyContext(parent context.Context, w io.Writer) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		_, _ = fmt.Fprintln(w, "\nInterrupted; stopping after the current step. Press Ctrl+C again to quit right away.")
+		cancel()
+
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		runCleanups()
+		os.Exit(socleerr.Interrupted.ExitCode())
+	}()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			cancel()
+		})
+	}
+}
+
+// OnForcedExit registers fn to run when a second signal ends the process
+// before the command could stop by itself, e.g. to save what is needed to
+// resume. The returned func unregisters fn.
+func OnForcedExit(fn func()) (remove func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	id := nextID
+	nextID++
+	cleanups[id] = fn
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(cleanups, id)
+	}
+}
+
+func runCleanups() {
+	mu.Lock()
+	defer mu.Unlock()
+	for id, fn := range cleanups {
+		fn()
+		delete(cleanups, id)
+	}
+}
+
+// Requested reports whether ctx was cancelled. A nil ctx never is.
+func Requested(ctx context.Context) bool {
+	return ctx != nil && ctx.Err() != nil
+}
+
+// Check returns nil while ctx is live. Once it was cancelled it returns an
+// Interrupted error whose message ends in hint, which tells the user how to
+// pick up where the command stopped.
+func Check(ctx context.Context, hint string) error {
+	if !Requested(ctx) {
+		return nil
+	}
+	return socleerr.New(socleerr.Interrupted, "interrupted; %s", hint)
+}
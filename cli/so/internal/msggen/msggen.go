@@ -0,0 +1,61 @@
+// Package msggen runs the team's own message generator, the command named by
+// socle.messageGenerator, to draft commit messages and PR titles and bodies.
+// socle only pipes a diff in and reads text out; what produces the text is up
+// to the command.
+package msggen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+)
+
+// Kinds of message, passed to the command as SOCLE_MESSAGE_KIND.
+const (
+	KindCommit = "commit" // A commit message: subject, blank line, body
+	KindPR     = "pr"     // A PR title on the first line, the body after it
+)
+
+// Command returns the configured generator, or "" when none is set.
+func Command() string {
+	command, err := git.GetSocleConfig("socle.messageGenerator")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(command)
+}
+
+// Generate runs command through the shell with diff on stdin and
+// SOCLE_MESSAGE_KIND, SOCLE_BRANCH and SOCLE_PARENT set, and returns what it
+// printed. A failing command's stderr is part of the error.
+func Generate(command, kind, diff, branch, parent string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = strings.NewReader(diff)
+	cmd.Env = append(os.Environ(), "SOCLE_MESSAGE_KIND="+kind, "SOCLE_BRANCH="+branch, "SOCLE_PARENT="+parent)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return "", fmt.Errorf("message generator %q failed: %w: %s", command, err, detail)
+		}
+		return "", fmt.Errorf("message generator %q failed: %w", command, err)
+	}
+	message := strings.TrimSpace(stdout.String())
+	if message == "" {
+		return "", errors.New("message generator printed nothing")
+	}
+	return message, nil
+}
+
+// SplitTitleBody splits generated text into its first line and the rest.
+func SplitTitleBody(message string) (title, body string) {
+	title, body, _ = strings.Cut(message, "\n")
+	return strings.TrimSpace(title), strings.TrimSpace(body)
+}
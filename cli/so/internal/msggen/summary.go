@@ -0,0 +1,116 @@
+package msggen
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/benekuehn/socle/cli/so/internal/git"
+)
+
+// maxSummaryParts is how many names a summary lists before it counts the rest.
+const maxSummaryParts = 3
+
+// Summarize drafts a commit subject from the files changes touches, e.g.
+// "update auth handlers and tests" for internal/auth/handlers.go and its
+// test. It names what changed below the directory all the changes share,
+// with tests folded into one "tests", and starts with "add" or "remove" when
+// every file is new or deleted. It returns "" for no changes.
+func Summarize(changes []git.FileChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var code, tests []string
+	verb := ""
+	for _, change := range changes {
+		if isTestPath(change.Path) {
+			tests = append(tests, change.Path)
+		} else {
+			code = append(code, change.Path)
+		}
+		switch {
+		case verb == "":
+			verb = verbFor(change.Status)
+		case verb != verbFor(change.Status):
+			verb = "update"
+		}
+	}
+	if len(code) == 0 {
+		code, tests = tests, nil
+	}
+
+	dir := commonDir(code)
+	var names []string
+	for _, p := range code {
+		name := strings.TrimPrefix(p, dir)
+		name, _, _ = strings.Cut(strings.TrimPrefix(name, "/"), "/")
+		if stem := strings.TrimSuffix(name, path.Ext(name)); stem != "" {
+			name = stem // Not for dotfiles like .gitignore
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	if len(names) > maxSummaryParts {
+		names = append(names[:maxSummaryParts-1], fmt.Sprintf("%d more", len(names)-maxSummaryParts+1))
+	}
+	if len(tests) > 0 {
+		names = append(names, "tests")
+	}
+
+	subject := joinNames(names)
+	if dir != "" {
+		subject = path.Base(dir) + " " + subject
+	}
+	return verb + " " + subject
+}
+
+func verbFor(status byte) string {
+	switch status {
+	case 'A':
+		return "add"
+	case 'D':
+		return "remove"
+	default:
+		return "update"
+	}
+}
+
+// isTestPath reports whether p looks like a test by the usual conventions.
+func isTestPath(p string) bool {
+	for _, dir := range strings.Split(path.Dir(p), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" {
+			return true
+		}
+	}
+	base := path.Base(p)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	return strings.HasSuffix(stem, "_test") || strings.HasSuffix(stem, ".test") ||
+		strings.HasSuffix(stem, ".spec") || strings.HasPrefix(base, "test_")
+}
+
+// commonDir returns the deepest directory holding all paths, "" for the
+// repository root.
+func commonDir(paths []string) string {
+	dir := path.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for dir != "." && !strings.HasPrefix(p, dir+"/") {
+			dir = path.Dir(dir)
+		}
+	}
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// joinNames joins names as "a", "a and b" or "a, b and c".
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
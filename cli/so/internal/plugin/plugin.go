@@ -0,0 +1,69 @@
+// Package plugin finds external subcommands: executables named so-<name> on
+// PATH, which socle runs as 'so <name>', the way git and gh do. Plugins get
+// the repository and the current stack through SOCLE_* environment variables,
+// so teams can extend socle without forking it.
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Prefix is what a plugin's executable name starts with.
+const Prefix = "so-"
+
+// Plugin is an executable so-<name> found on PATH.
+type Plugin struct {
+	Name string // The subcommand, without the prefix
+	Path string
+}
+
+// Lookup finds the plugin for the subcommand name, as the shell would.
+func Lookup(name string) (Plugin, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return Plugin{}, false
+	}
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return Plugin{}, false
+	}
+	return Plugin{Name: name, Path: path}, true
+}
+
+// Discover lists the plugins on PATH, sorted by name. Where several
+// directories hold the same plugin, the first one wins, as in Lookup.
+func Discover() []Plugin {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // Missing or unreadable PATH entries are common
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), Prefix)
+			if !ok || name == "" || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode().Perm()&0o111 != 0
+}
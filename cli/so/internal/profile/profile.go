@@ -0,0 +1,148 @@
+// Package profile collects lightweight timing data (git subprocesses, GitHub
+// API calls, rendering) so `--profile` can report where a command spent its time.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Categories reported by --profile.
+const (
+	CategoryGit    = "git"
+	CategoryGitHub = "github"
+	CategoryRender = "render"
+)
+
+type stat struct {
+	count int
+	total time.Duration
+}
+
+var (
+	mu    sync.Mutex
+	stats = make(map[string]map[string]*stat) // category -> name -> stat
+)
+
+// Record adds one observation of name in category.
+func Record(category, name string, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	byName, ok := stats[category]
+	if !ok {
+		byName = make(map[string]*stat)
+		stats[category] = byName
+	}
+	s, ok := byName[name]
+	if !ok {
+		s = &stat{}
+		byName[name] = s
+	}
+	s.count++
+	s.total += d
+}
+
+// Start begins timing name in category; call the returned func when done.
+//
+//	defer profile.Start(profile.CategoryRender, "log")()
+func Start(category, name string) func() {
+	started := time.Now()
+	return func() { Record(category, name, time.Since(started)) }
+}
+
+// Reset clears all recorded observations.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	stats = make(map[string]map[string]*stat)
+}
+
+// GitVerb returns the git subcommand from a git argument list, skipping
+// global options such as `-c key=value`.
+func GitVerb(args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "-c" || arg == "-C" {
+			i++ // Skip the option's value
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return "(none)"
+}
+
+// Transport wraps an http.RoundTripper and records every request's latency
+// under CategoryGitHub, keyed by method and normalized path.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer Start(CategoryGitHub, req.Method+" "+normalizeAPIPath(req.URL.Path))()
+	return t.Base.RoundTrip(req)
+}
+
+var (
+	repoPathRegex   = regexp.MustCompile(`^/repos/[^/]+/[^/]+`)
+	numericSegRegex = regexp.MustCompile(`/\d+(/|$)`)
+)
+
+// normalizeAPIPath collapses owner/repo and numeric IDs so calls group by endpoint.
+func normalizeAPIPath(path string) string {
+	path = repoPathRegex.ReplaceAllString(path, "/repos/{owner}/{repo}")
+	for numericSegRegex.MatchString(path) {
+		path = numericSegRegex.ReplaceAllString(path, "/{n}$1")
+	}
+	return path
+}
+
+// Report writes a per-category breakdown, slowest entries first.
+func Report(w io.Writer, total time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, _ = fmt.Fprintf(w, "\nProfile (total %s):\n", round(total))
+	categories := []string{CategoryGit, CategoryGitHub, CategoryRender}
+	for category := range stats {
+		if category != CategoryGit && category != CategoryGitHub && category != CategoryRender {
+			categories = append(categories, category)
+		}
+	}
+
+	for _, category := range categories {
+		byName := stats[category]
+		count, sum := 0, time.Duration(0)
+		names := make([]string, 0, len(byName))
+		for name, s := range byName {
+			count += s.count
+			sum += s.total
+			names = append(names, name)
+		}
+		_, _ = fmt.Fprintf(w, "  %-8s %4d calls %10s\n", category, count, round(sum))
+		sort.Slice(names, func(i, j int) bool {
+			if byName[names[i]].total != byName[names[j]].total {
+				return byName[names[i]].total > byName[names[j]].total
+			}
+			return names[i] < names[j]
+		})
+		for _, name := range names {
+			s := byName[name]
+			_, _ = fmt.Fprintf(w, "    %-40s %4d %10s\n", name, s.count, round(s.total))
+		}
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,124 @@
+// Package secrets looks for credentials in the lines a branch adds, so
+// `so submit` can stop before pushing them to a remote and a public PR.
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rule is a pattern for one kind of secret.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultRules match credentials with a distinctive shape, so they rarely
+// fire on ordinary code. Generic "password = ..." assignments are left to
+// dedicated scanners run through a hook.
+var DefaultRules = []Rule{
+	{Name: "private key", Pattern: regexp.MustCompile(`-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{Name: "AWS access key ID", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "GitHub token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`)},
+	{Name: "GitLab token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20}\b`)},
+	{Name: "Slack token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{Name: "Google API key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{Name: "Stripe live key", Pattern: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{Name: "OpenAI API key", Pattern: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}T3BlbkFJ[A-Za-z0-9_-]{20,}\b`)},
+}
+
+// Finding is a line that matched a rule.
+type Finding struct {
+	File  string
+	Line  int // In the branch's version of File
+	Rule  string
+	Match string
+}
+
+// Redacted returns the start of the match, enough to find it again without
+// repeating the secret in logs.
+func (f Finding) Redacted() string {
+	if len(f.Match) <= 8 {
+		return strings.Repeat("*", len(f.Match))
+	}
+	return f.Match[:6] + "…"
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// hunkCount reads a line count from a hunk header, which omits it for
+// hunks of a single line.
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// ScanDiff checks the lines added in a unified diff against rules. Lines are
+// only read as content while the current hunk header says more are due, so an
+// added line that starts with "++ " is not mistaken for a file header.
+func ScanDiff(diff string, rules []Rule) []Finding {
+	var findings []Finding
+	file, line := "", 0
+	oldLeft, newLeft := 0, 0
+	for _, text := range strings.Split(diff, "\n") {
+		if oldLeft == 0 && newLeft == 0 {
+			switch {
+			case strings.HasPrefix(text, "+++ "):
+				file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+			case strings.HasPrefix(text, "@@"):
+				if m := hunkHeader.FindStringSubmatch(text); m != nil {
+					oldLeft, newLeft = hunkCount(m[1]), hunkCount(m[3])
+					line, _ = strconv.Atoi(m[2])
+				}
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "+"):
+			for _, rule := range rules {
+				if match := rule.Pattern.FindString(text[1:]); match != "" {
+					findings = append(findings, Finding{File: file, Line: line, Rule: rule.Name, Match: match})
+				}
+			}
+			line++
+			newLeft--
+		case strings.HasPrefix(text, "-"):
+			oldLeft--
+		case strings.HasPrefix(text, " "):
+			line++
+			oldLeft--
+			newLeft--
+		}
+	}
+	return findings
+}
+
+// RunHook runs command through the shell with diff on stdin and SOCLE_BRANCH
+// and SOCLE_PARENT set. A non-zero exit means the hook found something; its
+// output is returned as the report.
+func RunHook(command, diff, branch, parent string) (report string, found bool, err error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = strings.NewReader(diff)
+	cmd.Env = append(os.Environ(), "SOCLE_BRANCH="+branch, "SOCLE_PARENT="+parent)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	runErr := cmd.Run()
+	report = strings.TrimSpace(out.String())
+	if runErr == nil {
+		return report, false, nil
+	}
+	if _, ok := runErr.(*exec.ExitError); ok {
+		return report, true, nil
+	}
+	return "", false, fmt.Errorf("failed to run secret scan command %q: %w", command, runErr)
+}
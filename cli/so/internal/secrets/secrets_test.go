@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanDiff(t *testing.T) {
+	key := "AKIA" + "ABCDEFGHIJKLMNOP"
+
+	t.Run("An added line that looks like a file header is scanned", func(t *testing.T) {
+		diff := "diff --git a/notes.md b/notes.md\n" +
+			"--- a/notes.md\n" +
+			"+++ b/notes.md\n" +
+			"@@ -1,2 +1,3 @@\n" +
+			" intro\n" +
+			"+++ " + key + "\n" +
+			" outro\n"
+
+		findings := ScanDiff(diff, DefaultRules)
+		if assert.Len(t, findings, 1) {
+			assert.Equal(t, "notes.md", findings[0].File)
+			assert.Equal(t, 2, findings[0].Line)
+			assert.Equal(t, key, findings[0].Match)
+		}
+	})
+
+	t.Run("Line numbers follow each file's hunks", func(t *testing.T) {
+		diff := "diff --git a/a.txt b/a.txt\n" +
+			"--- a/a.txt\n" +
+			"+++ b/a.txt\n" +
+			"@@ -4 +4 @@\n" +
+			"-old\n" +
+			"+" + key + "\n" +
+			"diff --git a/b.txt b/b.txt\n" +
+			"--- a/b.txt\n" +
+			"+++ b/b.txt\n" +
+			"@@ -10,0 +11,2 @@\n" +
+			"+fine\n" +
+			"+" + key + "\n"
+
+		findings := ScanDiff(diff, DefaultRules)
+		if assert.Len(t, findings, 2) {
+			assert.Equal(t, Finding{File: "a.txt", Line: 4, Rule: "AWS access key ID", Match: key}, findings[0])
+			assert.Equal(t, Finding{File: "b.txt", Line: 12, Rule: "AWS access key ID", Match: key}, findings[1])
+		}
+	})
+}
@@ -0,0 +1,92 @@
+// Package socleerr classifies the errors so fails with, so the root command
+// can exit with a status scripts can tell apart instead of always 1.
+package socleerr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind is the category of an error.
+type Kind int
+
+const (
+	Unknown       Kind = iota // Any other failure
+	NotTracked                // The branch has no socle metadata
+	Conflict                  // A rebase or cherry-pick stopped on a conflict
+	AuthFailure               // No GitHub credentials, or GitHub rejected them
+	APIError                  // Any other GitHub API or network failure
+	DirtyWorktree             // Uncommitted changes are in the way
+	Interrupted               // Ctrl+C stopped the command between two steps
+)
+
+// ExitCode returns the exit status so uses for errors of kind k. Unknown
+// errors keep the plain 1.
+func (k Kind) ExitCode() int {
+	switch k {
+	case NotTracked:
+		return 3
+	case Conflict:
+		return 4
+	case AuthFailure:
+		return 5
+	case APIError:
+		return 6
+	case DirtyWorktree:
+		return 7
+	case Interrupted:
+		return 130 // What shells report for a command killed by SIGINT
+	default:
+		return 1
+	}
+}
+
+func (k Kind) String() string {
+	switch k {
+	case NotTracked:
+		return "not-tracked"
+	case Conflict:
+		return "conflict"
+	case AuthFailure:
+		return "auth-failure"
+	case APIError:
+		return "api-error"
+	case DirtyWorktree:
+		return "dirty-worktree"
+	case Interrupted:
+		return "interrupted"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is an error of a known Kind. Its message is that of Err.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// New returns an error of kind reading like fmt.Errorf(format, args...).
+func New(kind Kind, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap returns err classified as kind, or nil if err is nil.
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KindOf returns the kind of the first Error in err's chain, or Unknown.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Unknown
+}
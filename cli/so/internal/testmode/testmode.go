@@ -0,0 +1,124 @@
+// Package testmode is socle's isolated test backend. With SOCLE_TEST_MODE=1
+// the GitHub API is an in-process fake, prompts take their answers from a
+// script instead of the terminal, and git ignores the user's global and
+// system config. Packagers use it to run smoke tests against a throwaway
+// repository without a network, a token or a TTY.
+package testmode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Environment variables that control test mode.
+const (
+	EnvEnabled  = "SOCLE_TEST_MODE"    // "1" or "true" turns test mode on
+	EnvAnswers  = "SOCLE_TEST_ANSWERS" // File with one prompt answer per line
+	EnvAPIState = "SOCLE_TEST_API"     // JSON file the fake GitHub API loads and saves
+)
+
+// apiStateFile is where the fake API keeps its state when SOCLE_TEST_API is
+// not set, inside the repository's git directory.
+const apiStateFile = "socle-test-api.json"
+
+// ErrNoAnswer is returned when a prompt runs but the answers file has no
+// lines left.
+var ErrNoAnswer = errors.New("test mode: no scripted answer left for prompt")
+
+// Enabled reports whether SOCLE_TEST_MODE is on.
+func Enabled() bool {
+	on, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(EnvEnabled)))
+	return err == nil && on
+}
+
+// gitEnv pins everything git would otherwise read from the user's machine.
+// Author and committer are only defaults, so a test can still set its own.
+var gitEnv = []struct {
+	key, value string
+	override   bool
+}{
+	{"GIT_CONFIG_NOSYSTEM", "1", true},
+	{"GIT_CONFIG_GLOBAL", os.DevNull, true},
+	{"GIT_TERMINAL_PROMPT", "0", true},
+	{"GIT_AUTHOR_NAME", "Socle Test", false},
+	{"GIT_AUTHOR_EMAIL", "test@socle.invalid", false},
+	{"GIT_COMMITTER_NAME", "Socle Test", false},
+	{"GIT_COMMITTER_EMAIL", "test@socle.invalid", false},
+}
+
+// IsolateGit sets the environment every git process socle starts inherits.
+func IsolateGit() error {
+	for _, e := range gitEnv {
+		if !e.override && os.Getenv(e.key) != "" {
+			continue
+		}
+		if err := os.Setenv(e.key, e.value); err != nil {
+			return fmt.Errorf("test mode: failed to set %s: %w", e.key, err)
+		}
+	}
+	return nil
+}
+
+// CheckRepo refuses repositories outside the temporary directory, so a
+// smoke test pointed at the wrong directory cannot rewrite real branches.
+func CheckRepo(repoRoot string) error {
+	tmp, err := filepath.EvalSymlinks(os.TempDir())
+	if err != nil {
+		tmp = os.TempDir()
+	}
+	root, err := filepath.EvalSymlinks(repoRoot)
+	if err != nil {
+		root = repoRoot
+	}
+	if rel, err := filepath.Rel(tmp, root); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("test mode only runs in repositories under %s, not %s", tmp, repoRoot)
+	}
+	return nil
+}
+
+// APIStatePath returns the file the fake API persists to: SOCLE_TEST_API,
+// or a file in gitDir.
+func APIStatePath(gitDir string) string {
+	if path := os.Getenv(EnvAPIState); path != "" {
+		return path
+	}
+	return filepath.Join(gitDir, apiStateFile)
+}
+
+// NextAnswer removes the first line from the answers file and returns it.
+// Consuming the file keeps the script in step across separate processes.
+func NextAnswer() (string, error) {
+	path := os.Getenv(EnvAnswers)
+	if path == "" {
+		return "", fmt.Errorf("%w: %s is not set", ErrNoAnswer, EnvAnswers)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("test mode: failed to read answers: %w", err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) == 0 {
+		return "", ErrNoAnswer
+	}
+
+	// Keep empty lines: they are answers that accept the default.
+	rest := strings.Join(lines[1:], "\n")
+	if len(lines) > 1 {
+		rest += "\n"
+	}
+	if err := os.WriteFile(path, []byte(rest), 0o644); err != nil {
+		return "", fmt.Errorf("test mode: failed to update answers: %w", err)
+	}
+	// Multi-line answers, e.g. for editor prompts, are written with \n.
+	return strings.ReplaceAll(lines[0], `\n`, "\n"), nil
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/benekuehn/socle/cli/so/internal/testmode"
+)
+
+// AskOne runs a survey prompt. In test mode it takes the answer from the
+// next line of the scripted answers file instead of the terminal.
+func AskOne(prompt survey.Prompt, response any, opts ...survey.AskOpt) error {
+	if !testmode.Enabled() {
+		return survey.AskOne(prompt, response, opts...)
+	}
+	answer, err := testmode.NextAnswer()
+	if err != nil {
+		return err
+	}
+	return setScriptedAnswer(prompt, response, answer)
+}
+
+// setScriptedAnswer stores answer in response the way survey would have.
+// Select answers name the option, or give its zero-based index; multi-select
+// answers list them separated by commas. An empty answer takes the prompt's
+// default, like pressing enter.
+func setScriptedAnswer(prompt survey.Prompt, response any, answer string) error {
+	var options []string
+	switch p := prompt.(type) {
+	case *survey.Select:
+		options = p.Options
+		if answer == "" {
+			// The cursor starts on the default, or on the first option.
+			answer = "0"
+			if d, ok := p.Default.(string); ok && d != "" {
+				answer = d
+			}
+		}
+	case *survey.MultiSelect:
+		options = p.Options
+		if answer == "" {
+			// Enter submits the preselected options, by name or by index.
+			var picked []string
+			switch d := p.Default.(type) {
+			case []string:
+				for _, name := range d {
+					if i := slices.Index(options, name); i >= 0 {
+						picked = append(picked, strconv.Itoa(i))
+					}
+				}
+			case []int:
+				for _, i := range d {
+					picked = append(picked, strconv.Itoa(i))
+				}
+			}
+			answer = strings.Join(picked, ",")
+		}
+	case *survey.Input:
+		if answer == "" {
+			answer = p.Default
+		}
+	case *survey.Editor:
+		if answer == "" {
+			answer = p.Default
+		}
+	case *survey.Confirm:
+		if answer == "" {
+			answer = strconv.FormatBool(p.Default)
+		}
+	}
+	option := func(a string) (int, error) {
+		if i := slices.Index(options, a); i >= 0 {
+			return i, nil
+		}
+		if i, err := strconv.Atoi(a); err == nil && i >= 0 && i < len(options) {
+			return i, nil
+		}
+		return 0, fmt.Errorf("test mode: scripted answer %q is not one of %v", a, options)
+	}
+
+	switch r := response.(type) {
+	case *string:
+		if options == nil {
+			*r = answer
+			return nil
+		}
+		i, err := option(answer)
+		if err != nil {
+			return err
+		}
+		*r = options[i]
+	case *int:
+		i, err := option(answer)
+		if err != nil {
+			return err
+		}
+		*r = i
+	case *bool:
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes", "true":
+			*r = true
+		case "n", "no", "false":
+			*r = false
+		default:
+			return fmt.Errorf("test mode: scripted answer %q is not yes or no", answer)
+		}
+	case *[]string:
+		*r = nil
+		for _, a := range strings.Split(answer, ",") {
+			if a = strings.TrimSpace(a); a == "" {
+				continue
+			}
+			i, err := option(a)
+			if err != nil {
+				return err
+			}
+			*r = append(*r, options[i])
+		}
+	default:
+		return fmt.Errorf("test mode: cannot script an answer into %T", response)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptedMultiSelect(t *testing.T) {
+	options := []string{"feature-a", "feature-b", "feature-c"}
+
+	t.Run("An empty answer takes the default", func(t *testing.T) {
+		for _, def := range []any{[]string{"feature-a", "feature-c"}, []int{0, 2}} {
+			var picked []string
+			prompt := &survey.MultiSelect{Message: "Delete?", Options: options, Default: def}
+			require.NoError(t, setScriptedAnswer(prompt, &picked, ""))
+			assert.Equal(t, []string{"feature-a", "feature-c"}, picked)
+		}
+	})
+
+	t.Run("An answer names options or gives their index", func(t *testing.T) {
+		var picked []string
+		prompt := &survey.MultiSelect{Message: "Delete?", Options: options, Default: options}
+		require.NoError(t, setScriptedAnswer(prompt, &picked, "feature-b, 2"))
+		assert.Equal(t, []string{"feature-b", "feature-c"}, picked)
+	})
+
+	t.Run("Without a default nothing is picked", func(t *testing.T) {
+		picked := []string{"stale"}
+		prompt := &survey.MultiSelect{Message: "Delete?", Options: options}
+		require.NoError(t, setScriptedAnswer(prompt, &picked, ""))
+		assert.Empty(t, picked)
+	})
+}
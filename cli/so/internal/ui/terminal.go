@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"io"
+	"os"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+// TerminalWidth returns the width in columns of the terminal w writes to,
+// or 0 when w is not a terminal (e.g. piped into a file or another tool).
+func TerminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(f.Fd()) {
+		return 0
+	}
+	width, _, err := term.GetSize(f.Fd())
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// Truncate shortens s to at most width columns, ending it with an ellipsis
+// when anything was cut. Escape sequences, e.g. hyperlinks, are kept intact.
+func Truncate(s string, width int) string {
+	return ansi.Truncate(s, max(width, 0), "…")
+}
+
+// Width returns the number of columns s takes up, ignoring escape sequences.
+func Width(s string) int {
+	return ansi.StringWidth(s)
+}
@@ -0,0 +1,49 @@
+// Package workpool runs independent tasks, such as the git and GitHub lookups
+// for every branch of a stack, a bounded number at a time.
+package workpool
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultWorkers is how many tasks run at once unless a caller needs fewer:
+// enough to overlap GitHub round trips, few enough that a 50-branch stack
+// does not start 50 git processes or requests together.
+const DefaultWorkers = 8
+
+// Run calls fn for every index in [0, n), with at most workers calls running
+// at once. fn records its own result, e.g. in a slice indexed like the tasks.
+//
+// Once ctx is cancelled no further task starts; Run waits for the running
+// ones and returns ctx.Err(). It returns nil when every task ran.
+func Run(ctx context.Context, n, workers int, fn func(i int)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+		if ctx.Err() != nil { // Both cases were ready; do not start another task
+			<-sem
+			wg.Wait()
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+	return nil
+}